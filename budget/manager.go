@@ -0,0 +1,318 @@
+// Package budget enforces spending ceilings across queries and clients, for
+// applications serving many users or tenants.
+//
+// A Manager accumulates the cost of queries per user, tenant and session,
+// refuses new queries once a daily, monthly or total ceiling is reached, and
+// calls back when spending crosses a fraction of a ceiling:
+//
+//	manager := budget.NewManager().
+//	    Limit(budget.ScopeTenant, budget.Monthly, 500).
+//	    Limit(budget.ScopeUser, budget.Daily, 5).
+//	    LimitFor(budget.ScopeUser, "alice", budget.Daily, 20).
+//	    OnThreshold(0.8, func(e budget.ThresholdEvent) { notify(e) })
+//
+//	opts := types.NewClaudeAgentOptions().
+//	    WithBudget(manager.For(budget.Account{Tenant: "acme", User: "bob"}))
+//
+// One Manager is shared by every client of a process. Spending is kept in
+// memory, so it starts from zero when the process restarts.
+package budget
+
+import (
+	"sync"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Scope is what a ceiling applies to.
+type Scope string
+
+const (
+	ScopeUser    Scope = "user"
+	ScopeTenant  Scope = "tenant"
+	ScopeSession Scope = "session"
+)
+
+// Period is the window spending is accumulated over.
+type Period string
+
+const (
+	Daily   Period = "daily"   // Resets at midnight
+	Monthly Period = "monthly" // Resets on the first of the month
+	Total   Period = "total"   // Never resets
+)
+
+// periods are the windows spending is accumulated over.
+var periods = []Period{Daily, Monthly, Total}
+
+// Account identifies who a query is spending for. Empty fields are not
+// charged, nor checked against ceilings.
+type Account struct {
+	User    string
+	Tenant  string
+	Session string // Learned from the first result when empty, see For
+}
+
+// id returns the account's user, tenant or session.
+func (a Account) id(scope Scope) string {
+	switch scope {
+	case ScopeUser:
+		return a.User
+	case ScopeTenant:
+		return a.Tenant
+	case ScopeSession:
+		return a.Session
+	}
+	return ""
+}
+
+// ThresholdEvent reports that spending crossed a fraction of a ceiling.
+type ThresholdEvent struct {
+	Scope    Scope
+	ID       string // The user, tenant or session
+	Period   Period
+	Fraction float64 // The threshold crossed, such as 0.8
+	LimitUSD float64
+	SpentUSD float64
+}
+
+// ThresholdFunc is called when spending crosses a threshold.
+type ThresholdFunc func(event ThresholdEvent)
+
+// Manager accumulates spending and enforces ceilings. It is safe for
+// concurrent use.
+type Manager struct {
+	mu         sync.Mutex
+	limits     map[limitKey]float64 // Ceilings in USD; an empty id applies to every account
+	thresholds []threshold
+	location   *time.Location
+	now        func() time.Time
+	spending   map[spendingKey]*spending
+}
+
+// limitKey identifies a ceiling.
+type limitKey struct {
+	scope  Scope
+	id     string
+	period Period
+}
+
+// threshold is a callback for spending crossing a fraction of a ceiling.
+type threshold struct {
+	fraction float64
+	fn       ThresholdFunc
+}
+
+// spendingKey identifies the spending of a user, tenant or session.
+type spendingKey struct {
+	scope  Scope
+	id     string
+	period Period
+}
+
+// spending is the spending of a user, tenant or session in the current
+// window of a period.
+type spending struct {
+	window string
+	usd    float64
+	fired  map[int]bool // Indexes of the thresholds crossed in the window
+}
+
+// NewManager creates a Manager without ceilings, whose days and months
+// start in UTC.
+func NewManager() *Manager {
+	return &Manager{
+		limits:   make(map[limitKey]float64),
+		location: time.UTC,
+		now:      time.Now,
+		spending: make(map[spendingKey]*spending),
+	}
+}
+
+// Limit sets the ceiling of every user, tenant or session over a period.
+func (m *Manager) Limit(scope Scope, period Period, maxUSD float64) *Manager {
+	return m.LimitFor(scope, "", period, maxUSD)
+}
+
+// LimitFor sets the ceiling of one user, tenant or session over a period,
+// replacing the one set with Limit for it.
+func (m *Manager) LimitFor(scope Scope, id string, period Period, maxUSD float64) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limits[limitKey{scope: scope, id: id, period: period}] = maxUSD
+	return m
+}
+
+// OnThreshold calls fn when spending crosses fraction of a ceiling, such as
+// 0.8 for 80%, once per window.
+func (m *Manager) OnThreshold(fraction float64, fn ThresholdFunc) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.thresholds = append(m.thresholds, threshold{fraction: fraction, fn: fn})
+	return m
+}
+
+// WithLocation sets the time zone days and months start in.
+func (m *Manager) WithLocation(location *time.Location) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.location = location
+	return m
+}
+
+// Check returns a *types.BudgetExceededError if the account has reached a
+// ceiling.
+func (m *Manager) Check(account Account) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	for _, scope := range []Scope{ScopeTenant, ScopeUser, ScopeSession} {
+		id := account.id(scope)
+		if id == "" {
+			continue
+		}
+		for _, period := range periods {
+			limit, ok := m.limit(scope, id, period)
+			if !ok {
+				continue
+			}
+			if spent := m.spent(scope, id, period, now); spent >= limit {
+				return &types.BudgetExceededError{
+					Scope: string(scope), ID: id, Period: string(period), LimitUSD: limit, SpentUSD: spent,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Charge adds the cost of a query to the spending of the account's user,
+// tenant and session, calling the threshold callbacks for the thresholds it
+// crosses.
+func (m *Manager) Charge(account Account, costUSD float64) {
+	m.mu.Lock()
+	var events []ThresholdEvent
+	var callbacks []ThresholdFunc
+	now := m.now()
+	for _, scope := range []Scope{ScopeTenant, ScopeUser, ScopeSession} {
+		id := account.id(scope)
+		if id == "" {
+			continue
+		}
+		for _, period := range periods {
+			s := m.current(scope, id, period, now)
+			s.usd += costUSD
+			limit, ok := m.limit(scope, id, period)
+			if !ok || limit <= 0 {
+				continue
+			}
+			for i, t := range m.thresholds {
+				if s.fired[i] || s.usd < t.fraction*limit {
+					continue
+				}
+				s.fired[i] = true
+				events = append(events, ThresholdEvent{
+					Scope: scope, ID: id, Period: period, Fraction: t.fraction, LimitUSD: limit, SpentUSD: s.usd,
+				})
+				callbacks = append(callbacks, t.fn)
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	for i, fn := range callbacks {
+		fn(events[i])
+	}
+}
+
+// Spent returns the spending of a user, tenant or session in the current
+// window of a period.
+func (m *Manager) Spent(scope Scope, id string, period Period) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.spent(scope, id, period, m.now())
+}
+
+// For returns the budget of an account, to set with
+// ClaudeAgentOptions.WithBudget. Without a session in account, the budget
+// charges the session of the client's results.
+func (m *Manager) For(account Account) types.Budget {
+	return &accountBudget{manager: m, account: account}
+}
+
+// limit returns the ceiling of a user, tenant or session. The caller holds
+// m.mu.
+func (m *Manager) limit(scope Scope, id string, period Period) (float64, bool) {
+	if limit, ok := m.limits[limitKey{scope: scope, id: id, period: period}]; ok {
+		return limit, true
+	}
+	limit, ok := m.limits[limitKey{scope: scope, period: period}]
+	return limit, ok
+}
+
+// spent returns the spending in the window of a period at now. The caller
+// holds m.mu.
+func (m *Manager) spent(scope Scope, id string, period Period, now time.Time) float64 {
+	s, ok := m.spending[spendingKey{scope: scope, id: id, period: period}]
+	if !ok || s.window != m.window(period, now) {
+		return 0
+	}
+	return s.usd
+}
+
+// current returns the spending in the window of a period at now, starting
+// it if the window changed. The caller holds m.mu.
+func (m *Manager) current(scope Scope, id string, period Period, now time.Time) *spending {
+	key := spendingKey{scope: scope, id: id, period: period}
+	window := m.window(period, now)
+	s, ok := m.spending[key]
+	if !ok || s.window != window {
+		s = &spending{window: window, fired: make(map[int]bool)}
+		m.spending[key] = s
+	}
+	return s
+}
+
+// window names the window of a period at t.
+func (m *Manager) window(period Period, t time.Time) string {
+	switch period {
+	case Daily:
+		return t.In(m.location).Format("2006-01-02")
+	case Monthly:
+		return t.In(m.location).Format("2006-01")
+	}
+	return ""
+}
+
+// accountBudget is the types.Budget of an account.
+type accountBudget struct {
+	manager *Manager
+	mu      sync.Mutex
+	account Account
+}
+
+// Allow refuses queries once the account has reached a ceiling.
+func (b *accountBudget) Allow() error {
+	return b.manager.Check(b.snapshot())
+}
+
+// Charge charges the account, learning its session from the first result
+// if it has none.
+func (b *accountBudget) Charge(sessionID string, costUSD float64) {
+	b.mu.Lock()
+	if b.account.Session == "" {
+		b.account.Session = sessionID
+	}
+	account := b.account
+	b.mu.Unlock()
+	b.manager.Charge(account, costUSD)
+}
+
+// snapshot returns the account.
+func (b *accountBudget) snapshot() Account {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.account
+}
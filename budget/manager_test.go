@@ -0,0 +1,101 @@
+package budget
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// TestManagerCheck tests that accounts are refused once a ceiling of their
+// user, tenant or session is reached, with per-ID ceilings taking
+// precedence.
+func TestManagerCheck(t *testing.T) {
+	m := NewManager().
+		Limit(ScopeUser, Daily, 5).
+		LimitFor(ScopeUser, "alice", Daily, 20).
+		Limit(ScopeTenant, Monthly, 30)
+
+	alice := Account{User: "alice", Tenant: "acme"}
+	bob := Account{User: "bob", Tenant: "acme"}
+	m.Charge(alice, 6)
+	m.Charge(bob, 5)
+
+	if err := m.Check(alice); err != nil {
+		t.Errorf("Check(alice) = %v, want nil under her own ceiling", err)
+	}
+	var exceeded *types.BudgetExceededError
+	if err := m.Check(bob); !errors.As(err, &exceeded) {
+		t.Fatalf("Check(bob) = %v, want a BudgetExceededError", err)
+	}
+	if exceeded.Scope != "user" || exceeded.ID != "bob" || exceeded.Period != "daily" || exceeded.LimitUSD != 5 || exceeded.SpentUSD != 5 {
+		t.Errorf("error = %+v", exceeded)
+	}
+
+	m.Charge(alice, 19)
+	if err := m.Check(Account{User: "carol", Tenant: "acme"}); !errors.As(err, &exceeded) || exceeded.Scope != "tenant" {
+		t.Errorf("Check(carol) = %v, want the tenant ceiling exceeded", err)
+	}
+	if got := m.Spent(ScopeTenant, "acme", Monthly); got != 30 {
+		t.Errorf("tenant spending = %v, want 30", got)
+	}
+	if err := m.Check(Account{User: "dave", Tenant: "other"}); err != nil {
+		t.Errorf("Check(dave) = %v, want nil", err)
+	}
+}
+
+// TestManagerThresholds tests that threshold callbacks fire once per window,
+// and that daily spending resets at midnight.
+func TestManagerThresholds(t *testing.T) {
+	now := time.Date(2025, 3, 1, 23, 0, 0, 0, time.UTC)
+	var events []ThresholdEvent
+	m := NewManager().
+		Limit(ScopeUser, Daily, 10).
+		OnThreshold(0.8, func(e ThresholdEvent) { events = append(events, e) })
+	m.now = func() time.Time { return now }
+
+	alice := Account{User: "alice"}
+	m.Charge(alice, 5)
+	m.Charge(alice, 3.5)
+	m.Charge(alice, 1)
+	if len(events) != 1 {
+		t.Fatalf("events = %+v, want one", events)
+	}
+	if e := events[0]; e.Scope != ScopeUser || e.ID != "alice" || e.Period != Daily || e.Fraction != 0.8 || e.SpentUSD != 8.5 {
+		t.Errorf("event = %+v", e)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if got := m.Spent(ScopeUser, "alice", Daily); got != 0 {
+		t.Errorf("spending the next day = %v, want 0", got)
+	}
+	if got := m.Spent(ScopeUser, "alice", Monthly); got != 9.5 {
+		t.Errorf("monthly spending = %v, want 9.5", got)
+	}
+	m.Charge(alice, 9)
+	if len(events) != 2 {
+		t.Errorf("events = %d, want the threshold crossed again the next day", len(events))
+	}
+}
+
+// TestManagerFor tests that an account's budget learns its session from the
+// first result it is charged.
+func TestManagerFor(t *testing.T) {
+	m := NewManager().Limit(ScopeSession, Total, 1)
+	b := m.For(Account{User: "alice"})
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() = %v, want nil", err)
+	}
+	b.Charge("session-1", 1.5)
+	if got := m.Spent(ScopeSession, "session-1", Total); got != 1.5 {
+		t.Errorf("session spending = %v, want 1.5", got)
+	}
+	if err := b.Allow(); err == nil {
+		t.Error("Allow() = nil, want the session ceiling exceeded")
+	}
+	if err := m.For(Account{User: "alice"}).Allow(); err != nil {
+		t.Errorf("Allow() for a new session = %v, want nil", err)
+	}
+}
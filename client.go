@@ -136,10 +136,11 @@ type Client struct {
 	query     *internal.Query
 	logger    *log.Logger
 
-	mu        sync.Mutex
-	connected bool
-	ctx       context.Context
-	cancel    context.CancelFunc
+	mu          sync.Mutex
+	connected   bool
+	connectedAt time.Time
+	ctx         context.Context
+	cancel      context.CancelFunc
 }
 
 // NewClient creates a new interactive client with the given options.
@@ -158,25 +159,32 @@ func NewClient(ctx context.Context, options *types.ClaudeAgentOptions) (*Client,
 	if options == nil {
 		options = types.NewClaudeAgentOptions()
 	}
+	// Snapshot the options, so the caller may reuse or change them
+	options = options.Clone()
 
 	// Validate permission callback configuration
-	if options.CanUseTool != nil && options.PermissionPromptToolName != nil {
+	if usesPermissionCallback(options) && options.PermissionPromptToolName != nil {
 		return nil, fmt.Errorf("can_use_tool callback cannot be used with permission_prompt_tool_name")
 	}
 
-	// If CanUseTool is provided, automatically set PermissionPromptToolName to "stdio"
-	if options.CanUseTool != nil && options.PermissionPromptToolName == nil {
+	// Validate options
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	// If a permission callback is provided, automatically set PermissionPromptToolName to "stdio"
+	if usesPermissionCallback(options) && options.PermissionPromptToolName == nil {
 		stdio := "stdio"
 		options.PermissionPromptToolName = &stdio
 	}
 
-	// Find CLI path
+	// Find CLI path, unless replaying a wire dump
 	cliPath := ""
 	if options.CLIPath != nil {
 		cliPath = *options.CLIPath
-	} else {
+	} else if options.Replay == nil {
 		var err error
-		cliPath, err = transport.FindCLI()
+		cliPath, err = transport.FindCLIIn(options.CLISearchPaths)
 		if err != nil {
 			return nil, err
 		}
@@ -200,7 +208,7 @@ func NewClient(ctx context.Context, options *types.ClaudeAgentOptions) (*Client,
 	clientCtx, cancel := context.WithCancel(ctx)
 
 	// Create logger
-	logger := log.NewLogger(options.Verbose)
+	logger := newLogger(options)
 
 	// Determine resume session ID from options
 	resumeID := ""
@@ -208,8 +216,14 @@ func NewClient(ctx context.Context, options *types.ClaudeAgentOptions) (*Client,
 		resumeID = *options.Resume
 	}
 
-	// Create subprocess transport with optional resume and options
-	transportInst := transport.NewSubprocessCLITransport(cliPath, cwd, env, logger, resumeID, options)
+	// Create subprocess transport with optional resume and options, or
+	// replay a wire dump
+	var transportInst transport.Transport
+	if options.Replay != nil {
+		transportInst = transport.NewReplayTransport(*options.Replay, logger, options)
+	} else {
+		transportInst = transport.NewSubprocessCLITransport(cliPath, cwd, env, logger, resumeID, options)
+	}
 
 	return &Client{
 		options:   options,
@@ -221,6 +235,36 @@ func NewClient(ctx context.Context, options *types.ClaudeAgentOptions) (*Client,
 	}, nil
 }
 
+// newLogger returns the SDK logger of the options: their slog.Logger if
+// set, or stderr with their verbosity. It redacts the credentials set with
+// WithAPIKey and WithOAuthToken.
+func newLogger(options *types.ClaudeAgentOptions) *log.Logger {
+	logger := log.NewLogger(options.Verbose)
+	if options.Logger != nil {
+		logger = log.NewSlogLogger(options.Logger)
+	}
+	logger.SetRedactor(options.RedactCredentials)
+	if options.SessionID != nil {
+		logger.SetSessionID(*options.SessionID)
+	}
+	return logger
+}
+
+// usesPermissionCallback reports whether the options answer permission
+// requests in the SDK: through CanUseTool, a plan review callback, or an
+// agent-specific CanUseTool.
+func usesPermissionCallback(options *types.ClaudeAgentOptions) bool {
+	if options.CanUseTool != nil || options.OnPlanReady != nil {
+		return true
+	}
+	for _, permissions := range options.AgentPermissions {
+		if permissions.CanUseTool != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // Connect establishes a connection to Claude Code CLI in streaming mode.
 //
 // This must be called before sending any queries. The connection uses streaming mode
@@ -300,7 +344,10 @@ func (c *Client) Connect(ctx context.Context) error {
 	c.logger.Debug("Control protocol initialized")
 
 	c.connected = true
+	c.connectedAt = time.Now()
+	registerDebugSession(c)
 	c.logger.Info("Successfully connected to Claude")
+	c.options.NotifyLifecycle(&types.ConnectedEvent{CorrelationID: types.CorrelationIDFrom(ctx)})
 	return nil
 }
 
@@ -344,6 +391,12 @@ func (c *Client) Query(ctx context.Context, prompt string) error {
 		return fmt.Errorf("prompt cannot be empty")
 	}
 
+	if c.options.Budget != nil {
+		if err := c.options.Budget.Allow(); err != nil {
+			return err
+		}
+	}
+
 	// Build query message
 	queryMsg := map[string]interface{}{
 		"type": "user",
@@ -364,6 +417,7 @@ func (c *Client) Query(ctx context.Context, prompt string) error {
 	if err := c.transport.Write(ctx, string(data)); err != nil {
 		return err
 	}
+	c.query.StartTurn(ctx)
 
 	return nil
 }
@@ -378,6 +432,13 @@ func (c *Client) Query(ctx context.Context, prompt string) error {
 //   - Text blocks: map[string]interface{}{"type": "text", "text": "..."}
 //   - Image blocks: map[string]interface{}{"type": "image", "source": {...}}
 //
+// or typed blocks in a []types.ContentBlock:
+//
+//	content := []types.ContentBlock{
+//	    types.TextBlock{Type: "text", Text: "What's in this image?"},
+//	    types.NewBase64ImageBlock(png, "image/png"),
+//	}
+//
 // Example usage:
 //
 //	content := []interface{}{
@@ -415,6 +476,12 @@ func (c *Client) QueryWithContent(ctx context.Context, content interface{}) erro
 		return fmt.Errorf("content cannot be nil")
 	}
 
+	if c.options.Budget != nil {
+		if err := c.options.Budget.Allow(); err != nil {
+			return err
+		}
+	}
+
 	// Build query message with structured content
 	queryMsg := map[string]interface{}{
 		"type": "user",
@@ -435,6 +502,7 @@ func (c *Client) QueryWithContent(ctx context.Context, content interface{}) erro
 	if err := c.transport.Write(ctx, string(data)); err != nil {
 		return err
 	}
+	c.query.StartTurn(ctx)
 
 	return nil
 }
@@ -559,6 +627,7 @@ func (c *Client) Close(ctx context.Context) error {
 	}
 
 	c.connected = false
+	unregisterDebugSession(c)
 	c.logger.Debug("Connection closed")
 
 	// Return first error if any
@@ -631,6 +700,14 @@ func (c *Client) Interrupt(ctx context.Context) error {
 		return err
 	}
 
+	// Stop SDK MCP tools that are still running
+	c.mu.Lock()
+	query := c.query
+	c.mu.Unlock()
+	if query != nil {
+		query.CancelToolCalls()
+	}
+
 	return nil
 }
 
@@ -649,6 +726,9 @@ func (c *Client) RewindFiles(ctx context.Context, userMessageID string) error {
 	if !connected || query == nil {
 		return types.NewCLIConnectionError("not connected - call Connect() first")
 	}
+	if err := c.CLIVersion().Require(types.CapabilityFileCheckpointing); err != nil {
+		return err
+	}
 
 	_, err := query.SendControlRequest(ctx, map[string]interface{}{
 		"subtype":         "rewind_files",
@@ -656,3 +736,97 @@ func (c *Client) RewindFiles(ctx context.Context, userMessageID string) error {
 	})
 	return err
 }
+
+// Compact asks the CLI to compact the conversation context now, replacing
+// the messages so far with a summary, rather than waiting for it to fill the
+// context window. Instructions, which may be empty, tell it what the summary
+// should keep. The CLI answers with a *types.CompactionEvent and a
+// ResultMessage, received with ReceiveResponse like those of Query.
+//
+// Example:
+//
+//	if err := client.Compact(ctx, "keep the list of failing tests"); err != nil {
+//	    log.Fatal(err)
+//	}
+//	for msg := range client.ReceiveResponse(ctx) {
+//	    if c, ok := msg.(*types.CompactionEvent); ok {
+//	        log.Printf("compacted %d tokens", c.OriginalTokens)
+//	    }
+//	}
+func (c *Client) Compact(ctx context.Context, instructions string) error {
+	return c.Query(ctx, types.CompactPrompt(instructions))
+}
+
+// PermissionStats returns how many tool calls were allowed, denied or asked
+// about in this session, by the CanUseTool callback and by PreToolUse hooks.
+// It returns empty stats before Connect.
+func (c *Client) PermissionStats() types.PermissionStats {
+	c.mu.Lock()
+	query := c.query
+	c.mu.Unlock()
+
+	if query == nil {
+		return types.NewPermissionStatsRecorder().Stats()
+	}
+	return query.PermissionStats()
+}
+
+// UsageReport returns the token usage of this session broken down by
+// assistant turn, model and tool, along with the cost reported by the latest
+// result. It returns an empty report before Connect.
+//
+// Example:
+//
+//	report := client.UsageReport()
+//	for _, turn := range report.Turns {
+//	    fmt.Printf("turn %d (%s): %d tokens\n", turn.Turn, turn.Model, turn.Usage.Total())
+//	}
+func (c *Client) UsageReport() types.UsageReport {
+	c.mu.Lock()
+	query := c.query
+	c.mu.Unlock()
+
+	if query == nil {
+		return types.NewUsageRecorder().Report()
+	}
+	return query.UsageReport()
+}
+
+// TokensUsed returns the tokens used by this session so far: the input,
+// output and cache tokens of its assistant turns, as in UsageReport().Total.
+// It returns zero usage before Connect. Use WithTokenAlert to be told when
+// the usage reaches a threshold.
+func (c *Client) TokensUsed() types.TokenUsage {
+	c.mu.Lock()
+	query := c.query
+	c.mu.Unlock()
+
+	if query == nil {
+		return types.TokenUsage{}
+	}
+	return query.TokensUsed()
+}
+
+// CLIVersion returns the version of the Claude CLI running the session, or
+// the zero version if it is unknown, such as when
+// CLAUDE_AGENT_SDK_SKIP_VERSION_CHECK is set.
+func (c *Client) CLIVersion() types.CLIVersion {
+	if versioned, ok := c.transport.(interface{ CLIVersion() types.CLIVersion }); ok {
+		return versioned.CLIVersion()
+	}
+	return types.CLIVersion{}
+}
+
+// Supports reports whether the Claude CLI supports a capability. Connect
+// already fails with an *types.UnsupportedFeatureError for options the CLI
+// does not support; Supports lets applications check before using a feature
+// or fall back to another.
+//
+// Example:
+//
+//	if !client.Supports(types.CapabilityFileCheckpointing) {
+//	    log.Printf("Claude CLI %s cannot rewind files", client.CLIVersion())
+//	}
+func (c *Client) Supports(capability types.Capability) bool {
+	return c.CLIVersion().Supports(capability)
+}
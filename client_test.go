@@ -70,6 +70,28 @@ func TestNewClient_ConflictingPermissionOptions(t *testing.T) {
 	}
 }
 
+func TestNewClient_PlanModeUsesStdioPermissions(t *testing.T) {
+	ctx := context.Background()
+	opts := types.NewClaudeAgentOptions().
+		WithCLIPath("/bin/echo").
+		WithPlanMode(func(ctx context.Context, plan string) bool { return true }, types.PermissionModeBypassPermissions)
+
+	client, err := NewClient(ctx, opts)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer func() {
+		_ = client.Close(ctx)
+	}()
+
+	if client.options.PermissionPromptToolName == nil || *client.options.PermissionPromptToolName != "stdio" {
+		t.Errorf("expected plan mode to route permission requests over stdio, got %v", client.options.PermissionPromptToolName)
+	}
+	if opts.PermissionPromptToolName != nil {
+		t.Errorf("NewClient changed the caller's options: PermissionPromptToolName = %v", *opts.PermissionPromptToolName)
+	}
+}
+
 func TestClient_ConnectBeforeQuery(t *testing.T) {
 	ctx := context.Background()
 	opts := types.NewClaudeAgentOptions().WithCLIPath("/bin/echo")
@@ -370,3 +392,61 @@ func BenchmarkClient_Create(b *testing.B) {
 		}
 	}
 }
+
+// replayDump is a wire dump of an initialize request and one query.
+const replayDump = `{"time":"2026-01-02T10:00:00Z","direction":"outbound","message":{"type":"control_request","request_id":"req_42","request":{"subtype":"initialize"}}}
+{"time":"2026-01-02T10:00:00Z","direction":"inbound","message":{"type":"control_response","response":{"subtype":"success","request_id":"req_42","response":{}}}}
+{"time":"2026-01-02T10:00:01Z","direction":"outbound","message":{"type":"user","message":{"role":"user","content":"hi"}}}
+{"time":"2026-01-02T10:00:02Z","direction":"inbound","message":{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"hello"}]}}}
+{"time":"2026-01-02T10:00:03Z","direction":"inbound","message":{"type":"result","subtype":"success","session_id":"s1","num_turns":1,"total_cost_usd":0.25}}
+`
+
+// connectReplay returns a client connected to a replay of replayDump,
+// without the CLI.
+func connectReplay(ctx context.Context, t *testing.T, opts *types.ClaudeAgentOptions) *Client {
+	t.Helper()
+	path := t.TempDir() + "/wire.jsonl"
+	if err := os.WriteFile(path, []byte(replayDump), 0600); err != nil {
+		t.Fatalf("Failed to write wire dump: %v", err)
+	}
+
+	client, err := NewClient(ctx, opts.WithReplay(path, types.ReplayFast).WithCLISearchPaths(t.TempDir()))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = client.Close(context.Background())
+	})
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	return client
+}
+
+// TestClient_Replay tests that a client replays a wire dump without the CLI.
+func TestClient_Replay(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := connectReplay(ctx, t, types.NewClaudeAgentOptions())
+	if err := client.Query(ctx, "hi"); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	var text string
+	var result *types.ResultMessage
+	for msg := range client.ReceiveResponse(ctx) {
+		switch m := msg.(type) {
+		case *types.AssistantMessage:
+			for _, block := range m.Content {
+				if textBlock, ok := block.(*types.TextBlock); ok {
+					text += textBlock.Text
+				}
+			}
+		case *types.ResultMessage:
+			result = m
+		}
+	}
+	if text != "hello" || result == nil || result.SessionID != "s1" {
+		t.Errorf("replayed text = %q, result = %+v, want the recorded response", text, result)
+	}
+}
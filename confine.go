@@ -0,0 +1,269 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/M1n9X/claude-agent-sdk-go/internal/pathutil"
+	"github.com/M1n9X/claude-agent-sdk-go/permissions"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// confinedFileTools are the built-in tools whose file path inputs are confined.
+var confinedFileTools = map[string]bool{
+	"Write":        true,
+	"Edit":         true,
+	"MultiEdit":    true,
+	"NotebookEdit": true,
+}
+
+// confinedPathKeys are the tool input parameters that hold the file being written.
+var confinedPathKeys = []string{"file_path", "notebook_path", "path"}
+
+// confinedDevices are paths outside the root that shell commands may still use.
+var confinedDevices = map[string]bool{
+	"/dev/null":   true,
+	"/dev/stdin":  true,
+	"/dev/stdout": true,
+	"/dev/stderr": true,
+}
+
+// Confinement restricts file writes and shell commands to a root directory.
+//
+// File tools (Write, Edit, MultiEdit, NotebookEdit) may only write beneath the
+// root, symlinks resolved; relative paths are rewritten to absolute paths
+// under the working directory. Bash commands are denied when they reference a
+// path outside the root (an absolute path, a ~ path, or one escaping through
+// ".."), or cannot be parsed, and run from the root when the session's
+// working directory lies elsewhere. Other tools, such as Read and Grep, are
+// not restricted.
+//
+// Confinement inspects command text and is a guard against mistakes, not a
+// security boundary: a command can reach other paths in ways no static check
+// sees (variables, scripts, network tools). Use OS-level sandboxing where
+// isolation matters.
+type Confinement struct {
+	root  string
+	roots []string
+}
+
+// ConfineToDir returns a Confinement for root. Install it with Apply:
+//
+//	opts := claude.ConfineToDir("/path/to/project").Apply(types.NewClaudeAgentOptions())
+func ConfineToDir(root string) *Confinement {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	root = filepath.Clean(root)
+	return &Confinement{root: root, roots: pathutil.Roots(root)}
+}
+
+// Root returns the absolute root directory.
+func (c *Confinement) Root() string {
+	return c.root
+}
+
+// Apply installs the confinement on opts: a PreToolUse hook that rewrites or
+// denies tool uses and, if opts has a CanUseTool callback, wraps it to deny
+// violations before delegating to it. Without a callback, the tool uses the
+// hook lets through are left to the CLI's permission mode. The working
+// directory defaults to the root.
+func (c *Confinement) Apply(opts *types.ClaudeAgentOptions) *types.ClaudeAgentOptions {
+	if opts == nil {
+		opts = types.NewClaudeAgentOptions()
+	}
+	if opts.CWD == nil {
+		opts.WithCWD(c.root)
+	}
+	if opts.CanUseTool != nil {
+		opts.WithCanUseTool(c.CanUseTool(opts.CanUseTool))
+	}
+	return opts.WithHook(types.HookEventPreToolUse, c.Hook())
+}
+
+// Hook returns a PreToolUse hook matcher that enforces the confinement.
+// Hooks run in every permission mode, including bypassPermissions.
+func (c *Confinement) Hook() types.HookMatcher {
+	pattern := "Write|Edit|MultiEdit|NotebookEdit|Bash"
+	return types.HookMatcher{
+		Matcher: &pattern,
+		Hooks: []types.HookCallbackFunc{
+			func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+				raw, _ := input.(map[string]interface{})
+				toolName, _ := raw["tool_name"].(string)
+				toolInput, _ := raw["tool_input"].(map[string]interface{})
+				cwd, _ := raw["cwd"].(string)
+
+				rewritten, err := c.Check(toolName, toolInput, cwd)
+				if err != nil {
+					return types.DenyToolUse(err.Error()), nil
+				}
+				if rewritten != nil {
+					return types.UpdateToolInput(rewritten), nil
+				}
+				return map[string]interface{}{}, nil
+			},
+		},
+	}
+}
+
+// CanUseTool returns a permission callback that denies tool uses violating
+// the confinement and passes the rest, possibly rewritten, to next. A nil
+// next denies them, since staying within the root does not make a tool use
+// safe to approve.
+func (c *Confinement) CanUseTool(next types.CanUseToolFunc) types.CanUseToolFunc {
+	return func(ctx context.Context, toolName string, input map[string]interface{}, permCtx types.ToolPermissionContext) (interface{}, error) {
+		rewritten, err := c.Check(toolName, input, "")
+		if err != nil {
+			return types.PermissionResultDeny{Behavior: "deny", Message: err.Error(), Source: types.PermissionSourceRule}, nil
+		}
+		if rewritten != nil {
+			input = rewritten
+		}
+		if next != nil {
+			return next(ctx, toolName, input, permCtx)
+		}
+		return types.PermissionResultDeny{Behavior: "deny", Message: fmt.Sprintf("%s requires approval", toolName), Source: types.PermissionSourceRule}, nil
+	}
+}
+
+// Check evaluates a tool use against the confinement. It returns an error
+// describing a violation, or the rewritten tool input if the input had to be
+// changed (nil otherwise). cwd is the session's working directory; the root
+// is used when it is empty.
+func (c *Confinement) Check(toolName string, input map[string]interface{}, cwd string) (map[string]interface{}, error) {
+	if cwd == "" {
+		cwd = c.root
+	}
+
+	switch {
+	case confinedFileTools[toolName]:
+		return c.checkFileTool(toolName, input, cwd)
+	case toolName == "Bash":
+		return c.checkBash(input, cwd)
+	}
+	return nil, nil
+}
+
+// checkFileTool confines the file path parameters of a file tool.
+func (c *Confinement) checkFileTool(toolName string, input map[string]interface{}, cwd string) (map[string]interface{}, error) {
+	var rewritten map[string]interface{}
+	for _, key := range confinedPathKeys {
+		path, ok := input[key].(string)
+		if !ok || path == "" {
+			continue
+		}
+
+		resolved := c.resolve(path, cwd)
+		if !c.contains(resolved) {
+			return nil, fmt.Errorf("%s may not write outside %s: %s", toolName, c.root, path)
+		}
+		if resolved = filepath.Clean(resolved); resolved != path {
+			if rewritten == nil {
+				rewritten = copyToolInput(input)
+			}
+			rewritten[key] = resolved
+		}
+	}
+	return rewritten, nil
+}
+
+// checkBash confines the paths referenced by a Bash command.
+func (c *Confinement) checkBash(input map[string]interface{}, cwd string) (map[string]interface{}, error) {
+	command, _ := input["command"].(string)
+	if command == "" {
+		return nil, nil
+	}
+
+	// Relative paths resolve against the root when the session is elsewhere
+	inRoot := c.contains(filepath.Clean(cwd))
+	if !inRoot {
+		cwd = c.root
+	}
+
+	commands, err := permissions.ParseCommand(command)
+	if err != nil {
+		return nil, fmt.Errorf("Bash command cannot be analyzed: %v", err)
+	}
+	for _, cmd := range commands {
+		if filepath.Base(cmd.Name) == "cd" && len(cmd.Args) == 0 {
+			return nil, fmt.Errorf("Bash may not leave %s: %s", c.root, command)
+		}
+		for _, word := range commandWords(cmd) {
+			for _, path := range pathCandidates(word) {
+				if confinedDevices[path] {
+					continue
+				}
+				if !c.contains(c.resolve(path, cwd)) {
+					return nil, fmt.Errorf("Bash may not access paths outside %s: %s", c.root, path)
+				}
+			}
+		}
+	}
+
+	if inRoot {
+		return nil, nil
+	}
+	rewritten := copyToolInput(input)
+	rewritten["command"] = "cd " + shellQuote(c.root) + " && " + command
+	return rewritten, nil
+}
+
+// resolve returns the absolute form of path, expanding ~ and joining relative
+// paths to cwd without cleaning them, see pathutil.Join.
+func (c *Confinement) resolve(path, cwd string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return path
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	return pathutil.Join(cwd, path)
+}
+
+// contains reports whether path is the root or lies beneath it, symlinks
+// resolved.
+func (c *Confinement) contains(path string) bool {
+	return pathutil.Within(c.roots, path)
+}
+
+// commandWords returns the words of a simple command that may hold paths:
+// its name, arguments, assignments and redirection targets.
+func commandWords(cmd permissions.SimpleCommand) []string {
+	words := append([]string{cmd.Name}, cmd.Args...)
+	words = append(words, cmd.Assignments...)
+	for _, redirect := range cmd.Redirects {
+		words = append(words, redirect.Target)
+	}
+	return words
+}
+
+// pathCandidates returns the parts of a shell word that may be paths: the
+// word itself and the value of NAME=value or --flag=value forms. Words that
+// are not paths resolve to missing files under the working directory, so
+// they pass.
+func pathCandidates(word string) []string {
+	candidates := []string{word}
+	if value := word[strings.IndexByte(word, '=')+1:]; value != word && value != "" {
+		candidates = append(candidates, value)
+	}
+	return candidates
+}
+
+// shellQuote quotes s for use as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// copyToolInput returns a shallow copy of a tool input.
+func copyToolInput(input map[string]interface{}) map[string]interface{} {
+	clone := make(map[string]interface{}, len(input))
+	for k, v := range input {
+		clone[k] = v
+	}
+	return clone
+}
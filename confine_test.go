@@ -0,0 +1,151 @@
+package claude
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// TestConfinementCheck tests path confinement for file tools and Bash commands.
+func TestConfinementCheck(t *testing.T) {
+	root := t.TempDir()
+	c := ConfineToDir(root)
+
+	tests := []struct {
+		name    string
+		tool    string
+		input   map[string]interface{}
+		cwd     string
+		wantErr bool
+		want    map[string]interface{}
+	}{
+		{"write inside", "Write", map[string]interface{}{"file_path": filepath.Join(root, "a.txt")}, "", false, nil},
+		{"write outside", "Write", map[string]interface{}{"file_path": "/etc/passwd"}, "", true, nil},
+		{"write escaping", "Edit", map[string]interface{}{"file_path": filepath.Join(root, "../x")}, "", true, nil},
+		{"relative write", "Write", map[string]interface{}{"file_path": "sub/a.txt"}, "", false, map[string]interface{}{"file_path": filepath.Join(root, "sub/a.txt")}},
+		{"read unrestricted", "Read", map[string]interface{}{"file_path": "/etc/passwd"}, "", false, nil},
+		{"bash inside", "Bash", map[string]interface{}{"command": "go test ./... > out.txt 2>/dev/null"}, "", false, nil},
+		{"bash absolute", "Bash", map[string]interface{}{"command": "cat 'x' && rm -rf /var/data"}, "", true, nil},
+		{"bash parent", "Bash", map[string]interface{}{"command": "cp a.txt ../b.txt"}, "", true, nil},
+		{"bash home", "Bash", map[string]interface{}{"command": "ls ~/.ssh"}, "", true, nil},
+		{"bash assignment", "Bash", map[string]interface{}{"command": "make OUT=/tmp/elsewhere"}, "", true, nil},
+		{"bash bare cd", "Bash", map[string]interface{}{"command": "cd; ls"}, "", true, nil},
+		{"bash redirect outside", "Bash", map[string]interface{}{"command": "echo x >/tmp/out"}, "", true, nil},
+		{"bash unparsable", "Bash", map[string]interface{}{"command": "echo 'unterminated"}, "", true, nil},
+		{"bash outside cwd", "Bash", map[string]interface{}{"command": "ls"}, "/", false, map[string]interface{}{"command": "cd " + shellQuote(root) + " && ls"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Check(tt.tool, tt.input, tt.cwd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want == nil && got != nil {
+				t.Errorf("expected no rewrite, got %v", got)
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Errorf("expected %s=%v, got %v", key, value, got[key])
+				}
+			}
+		})
+	}
+}
+
+// TestConfineToDirApply tests that Apply installs the hook and permission callback.
+func TestConfineToDirApply(t *testing.T) {
+	root := t.TempDir()
+	var delegated map[string]interface{}
+	opts := types.NewClaudeAgentOptions().
+		WithCanUseTool(func(ctx context.Context, toolName string, input map[string]interface{}, permCtx types.ToolPermissionContext) (interface{}, error) {
+			delegated = input
+			return types.PermissionResultAllow{Behavior: "allow"}, nil
+		})
+
+	opts = ConfineToDir(root).Apply(opts)
+
+	if opts.CWD == nil || *opts.CWD != ConfineToDir(root).Root() {
+		t.Errorf("expected working directory to default to root, got %v", opts.CWD)
+	}
+	if len(opts.Hooks[types.HookEventPreToolUse]) != 1 {
+		t.Fatalf("expected a PreToolUse hook to be installed")
+	}
+
+	ctx := context.Background()
+	result, err := opts.CanUseTool(ctx, "Write", map[string]interface{}{"file_path": "/etc/passwd"}, types.ToolPermissionContext{})
+	if err != nil {
+		t.Fatalf("CanUseTool failed: %v", err)
+	}
+	if deny, ok := result.(types.PermissionResultDeny); !ok || deny.Source != types.PermissionSourceRule {
+		t.Errorf("expected rule-based deny, got %+v", result)
+	}
+	if delegated != nil {
+		t.Error("expected violations not to reach the existing callback")
+	}
+
+	if _, err := opts.CanUseTool(ctx, "Write", map[string]interface{}{"file_path": "a.txt"}, types.ToolPermissionContext{}); err != nil {
+		t.Fatalf("CanUseTool failed: %v", err)
+	}
+	if delegated["file_path"] != filepath.Join(ConfineToDir(root).Root(), "a.txt") {
+		t.Errorf("expected rewritten input to be delegated, got %v", delegated)
+	}
+
+	hook := opts.Hooks[types.HookEventPreToolUse][0].Hooks[0]
+	output, err := hook(ctx, map[string]interface{}{
+		"tool_name":  "Bash",
+		"tool_input": map[string]interface{}{"command": "rm -rf /"},
+	}, nil, types.HookContext{})
+	if err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+	normalized, _ := types.NormalizeHookOutput(output)
+	specific, _ := normalized["hookSpecificOutput"].(map[string]interface{})
+	if specific["permissionDecision"] != "deny" {
+		t.Errorf("expected hook to deny, got %v", normalized)
+	}
+}
+
+// TestConfinementSymlinks tests that symlinks inside the root cannot be used to
+// write outside it.
+func TestConfinementSymlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "missing"), filepath.Join(root, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+	c := ConfineToDir(root)
+
+	for _, path := range []string{"link/a.txt", filepath.Join(root, "link", "a.txt"), "link/../a.txt", "dangling"} {
+		if _, err := c.Check("Write", map[string]interface{}{"file_path": path}, ""); err == nil {
+			t.Errorf("expected write through %q to be denied", path)
+		}
+	}
+	if _, err := c.Check("Bash", map[string]interface{}{"command": "touch link/a.txt"}, ""); err == nil {
+		t.Error("expected Bash through the symlink to be denied")
+	}
+}
+
+// TestConfineToDirWithoutCallback tests that Apply installs no permission
+// callback of its own, and that CanUseTool denies without one.
+func TestConfineToDirWithoutCallback(t *testing.T) {
+	c := ConfineToDir(t.TempDir())
+	opts := c.Apply(nil)
+	if opts.CanUseTool != nil {
+		t.Error("expected no permission callback to be installed")
+	}
+
+	result, err := c.CanUseTool(nil)(context.Background(), "Write", map[string]interface{}{"file_path": "a.txt"}, types.ToolPermissionContext{})
+	if err != nil {
+		t.Fatalf("CanUseTool failed: %v", err)
+	}
+	if _, ok := result.(types.PermissionResultDeny); !ok {
+		t.Errorf("expected deny without a callback, got %+v", result)
+	}
+}
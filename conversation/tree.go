@@ -0,0 +1,278 @@
+// Package conversation reconstructs the structure of a conversation from its
+// messages: the main thread, the tool calls Claude made, and the branches of
+// subagents started by those calls. Visualization and debugging tools can
+// walk the tree or encode it as JSON.
+//
+// Example:
+//
+//	tree := conversation.Build(messages)
+//	tree.Walk(func(n *conversation.Node, depth int) bool {
+//	    fmt.Printf("%s%s\n", strings.Repeat("  ", depth), n.Label())
+//	    return true
+//	})
+package conversation
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// NodeKind is the kind of a Node.
+type NodeKind string
+
+const (
+	KindRoot      NodeKind = "root"      // The conversation itself
+	KindUser      NodeKind = "user"      // A UserMessage
+	KindAssistant NodeKind = "assistant" // An AssistantMessage
+	KindToolCall  NodeKind = "tool_call" // A tool use block of an assistant message
+	KindSystem    NodeKind = "system"    // A SystemMessage or CompactionEvent
+	KindResult    NodeKind = "result"    // A ResultMessage
+	KindOther     NodeKind = "other"     // A message of another type
+)
+
+// Node is a message or tool call in a conversation tree.
+//
+// The root's children are the messages of the main thread, in order. An
+// assistant message's children are its tool calls. A tool call's children
+// are the messages of the subagent it started, if any, which have the call's
+// ID as their ParentToolUseID.
+type Node struct {
+	Kind NodeKind `json:"kind"`
+	// ID is the message UUID, or the tool use ID of a tool call. It may be
+	// empty for messages without a UUID.
+	ID       string        `json:"id,omitempty"`
+	Message  types.Message `json:"message,omitempty"` // Nil for the root and tool calls
+	Children []*Node       `json:"children,omitempty"`
+	Parent   *Node         `json:"-"`
+
+	// Tool call nodes only.
+	ToolName string                 `json:"tool_name,omitempty"`
+	Input    map[string]interface{} `json:"input,omitempty"`
+	// Result is the ToolResultBlock or ServerToolResultBlock answering the
+	// call, or nil while it is pending.
+	Result types.ContentBlock `json:"result,omitempty"`
+}
+
+// IsSubagent reports whether the node is a tool call that started a
+// subagent.
+func (n *Node) IsSubagent() bool {
+	return n.Kind == KindToolCall && len(n.Children) > 0
+}
+
+// Depth returns the number of ancestors of the node.
+func (n *Node) Depth() int {
+	depth := 0
+	for p := n.Parent; p != nil; p = p.Parent {
+		depth++
+	}
+	return depth
+}
+
+// Label returns a one-line description of the node, such as
+// "assistant: I'll check the tests" or "tool Bash (toolu_01) ✓".
+func (n *Node) Label() string {
+	switch n.Kind {
+	case KindRoot:
+		return "conversation"
+	case KindToolCall:
+		status := "…"
+		switch result := n.Result.(type) {
+		case *types.ToolResultBlock:
+			status = "✓"
+			if result.IsError != nil && *result.IsError {
+				status = "✗"
+			}
+		case *types.ServerToolResultBlock:
+			status = "✓"
+			if _, failed := result.ErrorCode(); failed {
+				status = "✗"
+			}
+		}
+		return fmt.Sprintf("tool %s (%s) %s", n.ToolName, n.ID, status)
+	case KindResult:
+		if result, ok := n.Message.(*types.ResultMessage); ok {
+			return "result: " + result.Subtype
+		}
+	case KindSystem:
+		if event, ok := n.Message.(*types.CompactionEvent); ok {
+			return fmt.Sprintf("system: compacted %d tokens", event.OriginalTokens)
+		}
+		if system, ok := n.Message.(*types.SystemMessage); ok {
+			return "system: " + system.Subtype
+		}
+	}
+	if text := truncate(types.TextOf(n.Message), 60); text != "" {
+		return fmt.Sprintf("%s: %s", n.Kind, text)
+	}
+	return string(n.Kind)
+}
+
+// truncate shortens s to at most n runes on one line.
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+	return s
+}
+
+// Tree is a conversation reconstructed by Build.
+type Tree struct {
+	Root *Node
+
+	toolCalls map[string]*Node
+}
+
+// Build reconstructs the tree of a conversation from its messages in the
+// order received. Stream events are skipped. A message whose parent tool
+// call is not among the messages, such as one of a subagent started before
+// recording began, is attached to a placeholder tool call node with an
+// empty tool name.
+func Build(messages []types.Message) *Tree {
+	t := &Tree{Root: &Node{Kind: KindRoot}, toolCalls: make(map[string]*Node)}
+	for _, msg := range messages {
+		t.Add(msg)
+	}
+	return t
+}
+
+// Add adds a message to a tree created by Build, so that a tree can grow
+// with a live conversation. It is not safe for concurrent use.
+func (t *Tree) Add(msg types.Message) {
+	var node *Node
+	var parentToolUseID *string
+	switch m := msg.(type) {
+	case *types.UserMessage:
+		node = &Node{Kind: KindUser, ID: deref(m.UUID), Message: m}
+		parentToolUseID = m.ParentToolUseID
+		for _, result := range types.ToolResults(m) {
+			t.toolCall(result.ToolUseID).Result = result
+		}
+	case *types.AssistantMessage:
+		node = &Node{Kind: KindAssistant, ID: deref(m.UUID), Message: m}
+		parentToolUseID = m.ParentToolUseID
+	case *types.ResultMessage:
+		node = &Node{Kind: KindResult, Message: m}
+	case *types.CompactionEvent:
+		node = &Node{Kind: KindSystem, ID: m.UUID, Message: m}
+	case *types.SystemMessage:
+		node = &Node{Kind: KindSystem, Message: m}
+	case *types.StreamEvent:
+		return
+	default:
+		node = &Node{Kind: KindOther, Message: msg}
+	}
+
+	parent := t.Root
+	if parentToolUseID != nil && *parentToolUseID != "" {
+		parent = t.toolCall(*parentToolUseID)
+	}
+	appendChild(parent, node)
+
+	if m, ok := msg.(*types.AssistantMessage); ok {
+		for _, block := range m.Content {
+			switch b := block.(type) {
+			case *types.ToolUseBlock:
+				t.addToolCall(node, b.ID, b.Name, b.Input)
+			case *types.ServerToolUseBlock:
+				t.addToolCall(node, b.ID, b.Name, b.Input)
+			case *types.ServerToolResultBlock:
+				t.toolCall(b.ToolUseID).Result = b
+			}
+		}
+	}
+}
+
+// addToolCall adds a tool call to an assistant message node, filling in the
+// placeholder if the call was referenced before it was seen.
+func (t *Tree) addToolCall(assistant *Node, id, name string, input map[string]interface{}) {
+	call := t.toolCall(id)
+	call.ToolName = name
+	call.Input = input
+	if call.Parent != assistant {
+		appendChild(assistant, call)
+	}
+}
+
+// toolCall returns the node of a tool call, creating a placeholder attached
+// to the root if the call has not been seen.
+func (t *Tree) toolCall(id string) *Node {
+	if call, ok := t.toolCalls[id]; ok {
+		return call
+	}
+	call := &Node{Kind: KindToolCall, ID: id}
+	t.toolCalls[id] = call
+	appendChild(t.Root, call)
+	return call
+}
+
+// appendChild moves child to the end of parent's children.
+func appendChild(parent, child *Node) {
+	if old := child.Parent; old != nil {
+		for i, c := range old.Children {
+			if c == child {
+				old.Children = append(old.Children[:i], old.Children[i+1:]...)
+				break
+			}
+		}
+	}
+	child.Parent = parent
+	parent.Children = append(parent.Children, child)
+}
+
+// deref returns the string s points to, or "".
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// ToolCall returns the node of the tool call with the given tool use ID.
+func (t *Tree) ToolCall(id string) (*Node, bool) {
+	call, ok := t.toolCalls[id]
+	return call, ok
+}
+
+// Subagents returns the tool calls that started subagents, in the order of
+// the tree.
+func (t *Tree) Subagents() []*Node {
+	var subagents []*Node
+	t.Walk(func(n *Node, depth int) bool {
+		if n.IsSubagent() {
+			subagents = append(subagents, n)
+		}
+		return true
+	})
+	return subagents
+}
+
+// Walk calls fn for each node in depth-first order, starting with the root
+// at depth 0. If fn returns false, the node's children are skipped.
+func (t *Tree) Walk(fn func(n *Node, depth int) bool) {
+	walk(t.Root, 0, fn)
+}
+
+func walk(n *Node, depth int, fn func(n *Node, depth int) bool) {
+	if !fn(n, depth) {
+		return
+	}
+	for _, child := range n.Children {
+		walk(child, depth+1, fn)
+	}
+}
+
+// WriteOutline writes the tree as an indented outline of node labels.
+func (t *Tree) WriteOutline(w io.Writer) error {
+	var err error
+	t.Walk(func(n *Node, depth int) bool {
+		if err == nil {
+			_, err = fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", depth), n.Label())
+		}
+		return err == nil
+	})
+	return err
+}
@@ -0,0 +1,88 @@
+package conversation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// parse parses CLI message lines.
+func parse(t *testing.T, lines ...string) []types.Message {
+	t.Helper()
+	var messages []types.Message
+	for _, line := range lines {
+		msg, err := types.UnmarshalMessage([]byte(line))
+		if err != nil {
+			t.Fatalf("UnmarshalMessage(%s) error = %v", line, err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+// TestBuild tests the main thread, tool calls and subagent branches
+func TestBuild(t *testing.T) {
+	messages := parse(t,
+		`{"type":"user","message":{"role":"user","content":"Review the code"},"uuid":"u1"}`,
+		`{"type":"assistant","message":{"role":"assistant","model":"m","content":[{"type":"tool_use","id":"task1","name":"Task","input":{"prompt":"review"}}]},"uuid":"a1"}`,
+		`{"type":"assistant","message":{"role":"assistant","model":"m","content":[{"type":"tool_use","id":"read1","name":"Read","input":{}}]},"parent_tool_use_id":"task1","uuid":"a2"}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"read1","content":"code"}]},"parent_tool_use_id":"task1","uuid":"u2"}`,
+		`{"type":"stream_event","event":{"type":"message_stop"},"uuid":"e1","session_id":"s"}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task1","content":"looks good","is_error":false}]},"uuid":"u3"}`,
+		`{"type":"assistant","message":{"role":"assistant","model":"m","content":[{"type":"text","text":"The code looks good."}]},"uuid":"a3"}`,
+		`{"type":"result","subtype":"success","session_id":"s"}`,
+	)
+	tree := Build(messages)
+
+	var outline strings.Builder
+	if err := tree.WriteOutline(&outline); err != nil {
+		t.Fatalf("WriteOutline() error = %v", err)
+	}
+	want := `conversation
+  user: Review the code
+  assistant
+    tool Task (task1) ✓
+      assistant
+        tool Read (read1) ✓
+      user
+  user
+  assistant: The code looks good.
+  result: success
+`
+	if outline.String() != want {
+		t.Errorf("WriteOutline() =\n%s\nwant\n%s", outline.String(), want)
+	}
+
+	task, ok := tree.ToolCall("task1")
+	if !ok || task.Depth() != 2 || task.Parent.ID != "a1" {
+		t.Fatalf("ToolCall(task1) = %+v, want call of a1 at depth 2", task)
+	}
+	if subagents := tree.Subagents(); len(subagents) != 1 || subagents[0] != task {
+		t.Errorf("Subagents() = %v, want [task1]", subagents)
+	}
+	if read, _ := tree.ToolCall("read1"); read.IsSubagent() || read.Result == nil {
+		t.Errorf("ToolCall(read1) = %+v, want answered call without subagent", read)
+	}
+
+	if _, err := json.Marshal(tree.Root); err != nil {
+		t.Errorf("Marshal(Root) error = %v", err)
+	}
+}
+
+// TestBuildOutOfOrder tests messages that reference tool calls not seen yet
+func TestBuildOutOfOrder(t *testing.T) {
+	tree := Build(parse(t,
+		`{"type":"assistant","message":{"role":"assistant","model":"m","content":[{"type":"text","text":"sub"}]},"parent_tool_use_id":"task1"}`,
+	))
+	task, ok := tree.ToolCall("task1")
+	if !ok || task.Parent != tree.Root || task.ToolName != "" || !task.IsSubagent() {
+		t.Fatalf("placeholder = %+v, want nameless subagent call under the root", task)
+	}
+
+	tree.Add(parse(t, `{"type":"assistant","message":{"role":"assistant","model":"m","content":[{"type":"tool_use","id":"task1","name":"Task","input":{}}]}}`)[0])
+	if task.ToolName != "Task" || task.Parent.Kind != KindAssistant || len(tree.Root.Children) != 1 {
+		t.Errorf("after Add, call = %+v with root children %d, want it moved under the assistant", task, len(tree.Root.Children))
+	}
+}
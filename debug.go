@@ -0,0 +1,204 @@
+package claude
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// DebugSession is the state of a connected Client, as shown by the debug
+// server.
+type DebugSession struct {
+	ID             int64                `json:"id"` // Numbers the clients of the process
+	SessionID      string               `json:"session_id,omitempty"`
+	State          string               `json:"state"` // "idle", "running" or "disconnected"
+	ConnectedAt    time.Time            `json:"connected_at"`
+	Model          string               `json:"model,omitempty"`
+	Queues         types.QueueDepths    `json:"queues"`
+	Tokens         types.TokenUsage     `json:"tokens"`
+	CostUSD        float64              `json:"cost_usd"`
+	RecentMessages []types.DebugMessage `json:"recent_messages"` // Oldest first
+}
+
+// DebugTotals sums the sessions shown by the debug server.
+type DebugTotals struct {
+	Sessions int              `json:"sessions"`
+	Running  int              `json:"running"`
+	Tokens   types.TokenUsage `json:"tokens"`
+	CostUSD  float64          `json:"cost_usd"`
+}
+
+// debugRegistry holds the connected clients, for the debug server.
+var debugRegistry = struct {
+	mu      sync.Mutex
+	nextID  int64
+	clients map[*Client]int64
+}{clients: make(map[*Client]int64)}
+
+// registerDebugSession adds a connected client to the debug server.
+func registerDebugSession(c *Client) {
+	debugRegistry.mu.Lock()
+	defer debugRegistry.mu.Unlock()
+	debugRegistry.nextID++
+	debugRegistry.clients[c] = debugRegistry.nextID
+}
+
+// unregisterDebugSession removes a closed client from the debug server.
+func unregisterDebugSession(c *Client) {
+	debugRegistry.mu.Lock()
+	defer debugRegistry.mu.Unlock()
+	delete(debugRegistry.clients, c)
+}
+
+// DebugSessions returns the state of the connected clients of the process,
+// in the order they connected.
+func DebugSessions() []DebugSession {
+	debugRegistry.mu.Lock()
+	clients := make(map[*Client]int64, len(debugRegistry.clients))
+	for c, id := range debugRegistry.clients {
+		clients[c] = id
+	}
+	debugRegistry.mu.Unlock()
+
+	sessions := make([]DebugSession, 0, len(clients))
+	for c, id := range clients {
+		if session, ok := c.debugSession(id); ok {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID < sessions[j].ID })
+	return sessions
+}
+
+// debugSession returns the state of the client, or false if it is no
+// longer connected.
+func (c *Client) debugSession(id int64) (DebugSession, bool) {
+	c.mu.Lock()
+	query, connectedAt := c.query, c.connectedAt
+	c.mu.Unlock()
+	if query == nil {
+		return DebugSession{}, false
+	}
+
+	state := query.DebugState()
+	session := DebugSession{
+		ID:             id,
+		SessionID:      state.SessionID,
+		State:          "idle",
+		ConnectedAt:    connectedAt,
+		Queues:         state.Queues,
+		Tokens:         query.TokensUsed(),
+		CostUSD:        state.CostUSD,
+		RecentMessages: state.RecentMessages,
+	}
+	if c.options.Model != nil {
+		session.Model = *c.options.Model
+	}
+	switch {
+	case !c.transport.IsReady():
+		session.State = "disconnected"
+	case state.Running:
+		session.State = "running"
+	}
+	return session, true
+}
+
+// debugTotals sums sessions.
+func debugTotals(sessions []DebugSession) DebugTotals {
+	totals := DebugTotals{Sessions: len(sessions)}
+	for _, s := range sessions {
+		if s.State == "running" {
+			totals.Running++
+		}
+		totals.Tokens.InputTokens += s.Tokens.InputTokens
+		totals.Tokens.OutputTokens += s.Tokens.OutputTokens
+		totals.Tokens.CacheCreationInputTokens += s.Tokens.CacheCreationInputTokens
+		totals.Tokens.CacheReadInputTokens += s.Tokens.CacheReadInputTokens
+		totals.CostUSD += s.CostUSD
+	}
+	return totals
+}
+
+// DebugHandler returns the handler of the debug server: an HTML page of the
+// connected clients at "/", and their state as JSON at "/json". Mount it
+// under a prefix with http.StripPrefix:
+//
+//	http.Handle("/debug/claude/", http.StripPrefix("/debug/claude", claude.DebugHandler()))
+//
+// The page shows summaries of recent messages, with the credentials of the
+// options redacted; serve it to operators only.
+func DebugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		sessions := DebugSessions()
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(struct {
+			Sessions []DebugSession `json:"sessions"`
+			Totals   DebugTotals    `json:"totals"`
+		}{sessions, debugTotals(sessions)})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		sessions := DebugSessions()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = debugPage.Execute(w, struct {
+			Sessions []DebugSession
+			Totals   DebugTotals
+		}{sessions, debugTotals(sessions)})
+	})
+	return mux
+}
+
+// ServeDebug serves DebugHandler on addr, like http.ListenAndServe, for
+// inspecting long-running agent services:
+//
+//	go func() { log.Println(claude.ServeDebug("localhost:6060")) }()
+func ServeDebug(addr string) error {
+	return http.ListenAndServe(addr, DebugHandler())
+}
+
+// debugPage renders the debug server's HTML page.
+var debugPage = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>Claude sessions</title>
+<meta http-equiv="refresh" content="5">
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>Claude sessions</h1>
+<p>{{.Totals.Sessions}} sessions, {{.Totals.Running}} running,
+{{.Totals.Tokens.InputTokens}} input and {{.Totals.Tokens.OutputTokens}} output tokens,
+${{printf "%.4f" .Totals.CostUSD}}. <a href="json">JSON</a></p>
+{{range .Sessions}}
+<h2>#{{.ID}} {{.SessionID}}</h2>
+<table>
+<tr><th>State</th><td>{{.State}}</td></tr>
+<tr><th>Connected</th><td>{{.ConnectedAt.Format "2006-01-02 15:04:05"}}</td></tr>
+<tr><th>Model</th><td>{{.Model}}</td></tr>
+<tr><th>Queues</th><td>{{.Queues.Messages}}/{{.Queues.MessageCapacity}} messages, {{.Queues.ControlRequests}} control requests, {{.Queues.Queries}} queries</td></tr>
+<tr><th>Tokens</th><td>{{.Tokens.InputTokens}} input, {{.Tokens.OutputTokens}} output</td></tr>
+<tr><th>Cost</th><td>${{printf "%.4f" .CostUSD}}</td></tr>
+</table>
+<table>
+<tr><th>Time</th><th>Type</th><th>Summary</th></tr>
+{{range .RecentMessages}}<tr><td>{{.Time.Format "15:04:05.000"}}</td><td>{{.Type}}</td><td>{{.Summary}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
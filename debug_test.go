@@ -0,0 +1,88 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// TestDebugHandler tests that the debug server shows connected clients with
+// their recent messages, as JSON and HTML, until they close.
+func TestDebugHandler(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := connectReplay(ctx, t, types.NewClaudeAgentOptions().WithModel("claude-sonnet-4-5"))
+	if err := client.Query(ctx, "hi"); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	for range client.ReceiveResponse(ctx) {
+	}
+
+	server := httptest.NewServer(DebugHandler())
+	defer server.Close()
+	get := func(path string) string {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	var state struct {
+		Sessions []DebugSession `json:"sessions"`
+		Totals   DebugTotals    `json:"totals"`
+	}
+	if err := json.Unmarshal([]byte(get("/json")), &state); err != nil {
+		t.Fatalf("GET /json: %v", err)
+	}
+	var session *DebugSession
+	for i := range state.Sessions {
+		if state.Sessions[i].SessionID == "s1" {
+			session = &state.Sessions[i]
+		}
+	}
+	if session == nil {
+		t.Fatalf("sessions = %+v, want the replayed session", state.Sessions)
+	}
+	if session.State != "idle" || session.Model != "claude-sonnet-4-5" || session.CostUSD != 0.25 || session.ConnectedAt.IsZero() {
+		t.Errorf("session = %+v, want an idle session with its model and cost", session)
+	}
+	if session.Queues.MessageCapacity == 0 || session.Queues.Queries != 0 {
+		t.Errorf("queues = %+v, want the message capacity and no pending query", session.Queues)
+	}
+	var kinds []string
+	for _, msg := range session.RecentMessages {
+		kinds = append(kinds, msg.Type)
+	}
+	if strings.Join(kinds, ",") != "assistant,result" || session.RecentMessages[0].Summary != "hello" {
+		t.Errorf("recent messages = %+v, want the assistant message and the result", session.RecentMessages)
+	}
+	if state.Totals.Sessions < 1 || state.Totals.CostUSD < 0.25 {
+		t.Errorf("totals = %+v, want the session counted", state.Totals)
+	}
+
+	if page := get("/"); !strings.Contains(page, "hello") || !strings.Contains(page, "success after 1 turns") {
+		t.Errorf("GET / = %s, want the recent messages", page)
+	}
+
+	if err := client.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	for _, s := range DebugSessions() {
+		if s.SessionID == "s1" {
+			t.Errorf("DebugSessions() = %+v, want the closed client removed", s)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package claude
+
+import (
+	"github.com/M1n9X/claude-agent-sdk-go/internal/transport"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// DiscoverCLI returns every Claude CLI binary in the places NewClient and
+// Query would search when options has no CLIPath, in search order and with
+// its version. The places are options.CLISearchPaths if set, else the paths
+// in the CLAUDE_AGENT_SDK_CLI_PATHS environment variable, else PATH and the
+// usual install locations. options may be nil.
+//
+// Example:
+//
+//	for _, cli := range claude.DiscoverCLI(nil) {
+//	    fmt.Println(cli.Path, cli.Version)
+//	}
+func DiscoverCLI(options *types.ClaudeAgentOptions) []types.CLIInstallation {
+	if options == nil {
+		return transport.DiscoverCLI()
+	}
+	return transport.DiscoverCLI(options.CLISearchPaths...)
+}
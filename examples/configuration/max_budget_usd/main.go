@@ -48,7 +48,7 @@ func main() {
 					if resultMsg.TotalCostUSD != nil {
 						fmt.Printf("\nTotal cost: $%.6f\n", *resultMsg.TotalCostUSD)
 					}
-					// Note: Go SDK doesn't have StopReason in ResultMessage like Python SDK
+					fmt.Printf("Stop reason: %s\n", resultMsg.StopReason())
 				}
 			}
 		}
@@ -83,7 +83,9 @@ func main() {
 					if resultMsg.TotalCostUSD != nil {
 						fmt.Printf("\nTotal cost: $%.6f\n", *resultMsg.TotalCostUSD)
 					}
-					// Note: Go SDK doesn't have BudgetUsedUSD or StopReason in ResultMessage like Python SDK
+					if resultMsg.StopReason() == types.StopReasonBudgetExceeded {
+						fmt.Println("Budget exceeded before the analysis finished")
+					}
 				}
 			}
 		}
@@ -63,6 +63,13 @@ func main() {
 		// Stop: Called when the agent stops
 		WithHook(types.HookEventStop, types.HookMatcher{
 			Hooks: []types.HookCallbackFunc{stopHook},
+		}).
+		// SubagentStart/SubagentStop: Called around nested Task executions
+		WithHook(types.HookEventSubagentStart, types.HookMatcher{
+			Hooks: []types.HookCallbackFunc{subagentStartHook},
+		}).
+		WithHook(types.HookEventSubagentStop, types.HookMatcher{
+			Hooks: []types.HookCallbackFunc{subagentStopHook},
 		})
 
 	fmt.Println("Comprehensive Hooks Example")
@@ -79,6 +86,8 @@ func main() {
 	fmt.Println("9. PostCompact - After context compaction")
 	fmt.Println("10. OnError - When an error occurs")
 	fmt.Println("11. Stop - When the agent stops")
+	fmt.Println("12. SubagentStart - When a subagent starts")
+	fmt.Println("13. SubagentStop - When a subagent stops")
 	fmt.Println()
 
 	// Send query
@@ -130,15 +139,12 @@ func preToolUseHook(ctx context.Context, input interface{}, toolUseID *string, h
 
 	// You can modify the tool input or deny execution here
 	// For example, to deny execution:
-	// return map[string]interface{}{
-	//     "hookSpecificOutput": map[string]interface{}{
-	//         "hookEventName": "PreToolUse",
-	//         "permissionDecision": "deny",
-	//         "permissionDecisionReason": "Tool blocked by hook",
-	//     },
-	// }, nil
+	// return types.DenyToolUse("Tool blocked by hook"), nil
+	//
+	// Or to run the tool with a rewritten input:
+	// return types.AllowToolUseWithInput(newInput), nil
 
-	// Allow execution
+	// Make no decision, so the normal permission flow applies
 	return &types.SyncHookJSONOutput{}, nil
 }
 
@@ -262,10 +268,11 @@ func onErrorHook(ctx context.Context, input interface{}, toolUseID *string, hook
 
 	// You can implement error recovery logic here
 	// For example, to retry:
-	// return map[string]interface{}{
-	//     "hookSpecificOutput": map[string]interface{}{
-	//         "hookEventName": "OnError",
-	//         "recoveryAction": "retry",
+	// retry := "retry"
+	// return &types.SyncHookJSONOutput{
+	//     HookSpecificOutput: &types.OnErrorHookSpecificOutput{
+	//         HookEventName:  string(types.HookEventOnError),
+	//         RecoveryAction: &retry,
 	//     },
 	// }, nil
 
@@ -282,3 +289,35 @@ func stopHook(ctx context.Context, input interface{}, toolUseID *string, hookCtx
 
 	return &types.SyncHookJSONOutput{}, nil
 }
+
+// subagentStartHook is called when a subagent is launched by the Task tool
+func subagentStartHook(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+	parsed, err := types.ParseHookInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if start, ok := parsed.(*types.SubagentStartHookInput); ok {
+		fmt.Printf("\n🤖 [SubagentStart Hook] Agent %q started", start.AgentType)
+		if start.ParentToolUseID != nil {
+			fmt.Printf(" (parent tool use: %s)", *start.ParentToolUseID)
+		}
+		fmt.Println()
+	}
+
+	return &types.SyncHookJSONOutput{}, nil
+}
+
+// subagentStopHook is called when a subagent finishes
+func subagentStopHook(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+	parsed, err := types.ParseHookInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if stop, ok := parsed.(*types.SubagentStopHookInput); ok {
+		fmt.Printf("\n🤖 [SubagentStop Hook] Agent %q stopped\n", stop.AgentType)
+	}
+
+	return &types.SyncHookJSONOutput{}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 
 	claude "github.com/M1n9X/claude-agent-sdk-go"
 	"github.com/M1n9X/claude-agent-sdk-go/types"
@@ -48,10 +49,21 @@ func main() {
 		EnumParam("operation", "The operation to perform", true, []interface{}{"add", "subtract", "multiply", "divide"}).
 		Param("a", "number", "First number", true).
 		Param("b", "number", "Second number", true).
-		Handle(func(ctx context.Context, args map[string]interface{}) (*types.ToolResult, error) {
-			operation := args["operation"].(string)
-			a := args["a"].(float64)
-			b := args["b"].(float64)
+		Handle(func(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+			// types.Args reports bad input from Claude instead of panicking
+			args := types.Args(input)
+			operation, err := args.GetString("operation")
+			if err != nil {
+				return types.NewErrorMcpToolResult(err.Error()), nil
+			}
+			a, err := args.GetFloat("a")
+			if err != nil {
+				return types.NewErrorMcpToolResult(err.Error()), nil
+			}
+			b, err := args.GetFloat("b")
+			if err != nil {
+				return types.NewErrorMcpToolResult(err.Error()), nil
+			}
 
 			var result float64
 			var resultText string
@@ -152,9 +164,16 @@ func main() {
 				},
 			},
 		},
-		Handler: func(ctx context.Context, args map[string]interface{}) (*types.ToolResult, error) {
-			name := args["name"].(string)
-			age := int(args["age"].(float64))
+		Handler: func(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+			args := types.Args(input)
+			name, err := args.GetString("name")
+			if err != nil {
+				return types.NewErrorMcpToolResult(err.Error()), nil
+			}
+			age, err := args.GetInt("age")
+			if err != nil {
+				return types.NewErrorMcpToolResult(err.Error()), nil
+			}
 
 			result := fmt.Sprintf("Created user profile:\nName: %s\nAge: %d\n", name, age)
 
@@ -163,15 +182,12 @@ func main() {
 					address["street"], address["city"], address["country"])
 			}
 
-			if hobbies, ok := args["hobbies"].([]interface{}); ok {
-				result += "Hobbies: "
-				for i, hobby := range hobbies {
-					if i > 0 {
-						result += ", "
-					}
-					result += hobby.(string)
+			if args.Has("hobbies") {
+				hobbies, err := args.GetStringSlice("hobbies")
+				if err != nil {
+					return types.NewErrorMcpToolResult(err.Error()), nil
 				}
-				result += "\n"
+				result += "Hobbies: " + strings.Join(hobbies, ", ") + "\n"
 			}
 
 			return types.NewMcpToolResult(
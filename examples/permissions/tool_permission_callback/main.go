@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"log"
@@ -9,6 +8,7 @@ import (
 	"strings"
 
 	claude "github.com/M1n9X/claude-agent-sdk-go"
+	"github.com/M1n9X/claude-agent-sdk-go/permissionui"
 	"github.com/M1n9X/claude-agent-sdk-go/types"
 )
 
@@ -85,10 +85,13 @@ func mainExample(ctx context.Context) error {
 	return nil
 }
 
+// prompt asks the user about tool uses on the terminal, showing diffs for
+// file edits and a preview for Bash commands.
+var prompt = permissionui.New(os.Stdin, os.Stdout).CanUseTool()
+
 // myPermissionCallback controls tool permissions based on tool type and input.
 func myPermissionCallback(ctx context.Context, toolName string, input map[string]interface{}, permCtx types.ToolPermissionContext) (interface{}, error) {
 	fmt.Printf("\n🔧 Tool Permission Request: %s\n", toolName)
-	fmt.Printf("   Input: %+v\n", input)
 
 	// Always allow read operations
 	if toolName == "Read" || toolName == "Glob" || toolName == "Grep" {
@@ -98,10 +101,9 @@ func myPermissionCallback(ctx context.Context, toolName string, input map[string
 		}, nil
 	}
 
-	// Handle write operations with user approval
+	// Deny write operations to system directories
 	if toolName == "Write" || toolName == "Edit" || toolName == "MultiEdit" {
 		if filePath, ok := input["file_path"].(string); ok {
-			// Deny write operations to system directories
 			if strings.HasPrefix(filePath, "/etc/") || strings.HasPrefix(filePath, "/usr/") {
 				fmt.Printf("   ❌ Denying write to system directory: %s\n", filePath)
 				return &types.PermissionResultDeny{
@@ -110,69 +112,14 @@ func myPermissionCallback(ctx context.Context, toolName string, input map[string
 					Interrupt: false,
 				}, nil
 			}
-
-			// For other write operations, ask the user
-			fmt.Printf("   ❓ Write operation requested: %s\n", filePath)
-			fmt.Print("   Allow this write operation? (y/N): ")
-			reader := bufio.NewReader(os.Stdin)
-			userInput, _ := reader.ReadString('\n')
-			userInput = strings.TrimSpace(strings.ToLower(userInput))
-
-			if userInput == "y" || userInput == "yes" {
-				// Check if we need to redirect to safe directory
-				if !strings.HasPrefix(filePath, "/tmp/") && !strings.HasPrefix(filePath, "./") {
-					safePath := fmt.Sprintf("./safe_output/%s", filePath[strings.LastIndex(filePath, "/")+1:])
-					fmt.Printf("   ⚠️  Redirecting write from %s to %s\n", filePath, safePath)
-					// Create a copy of the input with the modified path
-					modifiedInput := make(map[string]interface{})
-					for k, v := range input {
-						modifiedInput[k] = v
-					}
-					modifiedInput["file_path"] = safePath
-					return &types.PermissionResultAllow{
-						Behavior:     "allow",
-						UpdatedInput: &modifiedInput,
-					}, nil
-				}
-				// Otherwise, allow the write as-is
-				return &types.PermissionResultAllow{
-					Behavior: "allow",
-				}, nil
-			} else {
-				return &types.PermissionResultDeny{
-					Behavior:  "deny",
-					Message:   "User denied write permission",
-					Interrupt: false,
-				}, nil
-			}
-		} else {
-			// If no file_path, ask the user
-			fmt.Printf("   ❓ Write operation requested (no file path specified)\n")
-			fmt.Print("   Allow this write operation? (y/N): ")
-			reader := bufio.NewReader(os.Stdin)
-			userInput, _ := reader.ReadString('\n')
-			userInput = strings.TrimSpace(strings.ToLower(userInput))
-
-			if userInput == "y" || userInput == "yes" {
-				return &types.PermissionResultAllow{
-					Behavior: "allow",
-				}, nil
-			} else {
-				return &types.PermissionResultDeny{
-					Behavior:  "deny",
-					Message:   "User denied write permission",
-					Interrupt: false,
-				}, nil
-			}
 		}
 	}
 
-	// Handle bash commands with user approval
+	// Deny dangerous bash commands
 	if toolName == "Bash" {
 		if command, ok := input["command"].(string); ok {
 			dangerousCommands := []string{"rm -rf", "sudo", "chmod 777", "dd if=", "mkfs"}
 
-			// Check for dangerous commands
 			for _, dangerous := range dangerousCommands {
 				if strings.Contains(command, dangerous) {
 					fmt.Printf("   ❌ Denying dangerous command: %s\n", command)
@@ -183,66 +130,9 @@ func myPermissionCallback(ctx context.Context, toolName string, input map[string
 					}, nil
 				}
 			}
-
-			// For all bash commands (even safe ones), ask the user
-			fmt.Printf("   ❓ Bash command requested: %s\n", command)
-			fmt.Print("   Allow this bash command? (y/N): ")
-			reader := bufio.NewReader(os.Stdin)
-			userInput, _ := reader.ReadString('\n')
-			userInput = strings.TrimSpace(strings.ToLower(userInput))
-
-			if userInput == "y" || userInput == "yes" {
-				fmt.Printf("   ✅ Allowing bash command: %s\n", command)
-				return &types.PermissionResultAllow{
-					Behavior: "allow",
-				}, nil
-			} else {
-				return &types.PermissionResultDeny{
-					Behavior:  "deny",
-					Message:   "User denied bash command permission",
-					Interrupt: false,
-				}, nil
-			}
-		} else {
-			// If no command specified, ask the user
-			fmt.Printf("   ❓ Bash command requested (no command specified)\n")
-			fmt.Print("   Allow this bash command? (y/N): ")
-			reader := bufio.NewReader(os.Stdin)
-			userInput, _ := reader.ReadString('\n')
-			userInput = strings.TrimSpace(strings.ToLower(userInput))
-
-			if userInput == "y" || userInput == "yes" {
-				return &types.PermissionResultAllow{
-					Behavior: "allow",
-				}, nil
-			} else {
-				return &types.PermissionResultDeny{
-					Behavior:  "deny",
-					Message:   "User denied bash command permission",
-					Interrupt: false,
-				}, nil
-			}
 		}
 	}
 
-	// For all other tools, ask the user
-	fmt.Printf("   ❓ Unknown/Other tool: %s\n", toolName)
-	fmt.Printf("      Input: %+v\n", input)
-
-	fmt.Print("   Allow this tool? (y/N): ")
-	reader := bufio.NewReader(os.Stdin)
-	userInput, _ := reader.ReadString('\n')
-	userInput = strings.TrimSpace(strings.ToLower(userInput))
-
-	if userInput == "y" || userInput == "yes" {
-		return &types.PermissionResultAllow{
-			Behavior: "allow",
-		}, nil
-	} else {
-		return &types.PermissionResultDeny{
-			Behavior:  "deny",
-			Message:   "User denied permission",
-			Interrupt: false,
-		}, nil
-	}
+	// For everything else, ask the user. Choosing [e]dit modifies the input.
+	return prompt(ctx, toolName, input, permCtx)
 }
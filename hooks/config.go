@@ -0,0 +1,132 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Config describes hook wiring by registered name, in the same shape as the
+// hooks section of Claude Code settings:
+//
+//	{
+//	  "hooks": {
+//	    "PreToolUse": [
+//	      {"matcher": "Bash", "hooks": ["redact-secrets", "audit"]}
+//	    ]
+//	  }
+//	}
+//
+// LoadConfig reads JSON. The struct also carries yaml tags, so YAML files can
+// be decoded with any YAML library and passed to Apply.
+type Config struct {
+	Hooks map[types.HookEvent][]MatcherConfig `json:"hooks" yaml:"hooks"`
+}
+
+// MatcherConfig configures the named hooks run for one matcher.
+// Command and TimeoutSeconds configure an external command hook; see types.HookMatcher.
+type MatcherConfig struct {
+	Matcher        string   `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+	Hooks          []string `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	Command        string   `json:"command,omitempty" yaml:"command,omitempty"`
+	TimeoutSeconds int      `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Async          bool     `json:"async,omitempty" yaml:"async,omitempty"`
+	AsyncTimeout   *int     `json:"asyncTimeout,omitempty" yaml:"asyncTimeout,omitempty"` // Milliseconds
+}
+
+// ParseConfig decodes a JSON hook configuration.
+func ParseConfig(data []byte) (*Config, error) {
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parse hook config: %w", err)
+	}
+	return &config, nil
+}
+
+// LoadConfig reads a JSON hook configuration file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read hook config: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+// Resolve turns a configuration into hook matchers, looking up each name in the
+// registry. Unknown events and names are reported as errors.
+func (r *Registry) Resolve(config *Config) (map[types.HookEvent][]types.HookMatcher, error) {
+	resolved := make(map[types.HookEvent][]types.HookMatcher, len(config.Hooks))
+
+	// Resolve events in sorted order so the reported error is deterministic
+	events := make([]string, 0, len(config.Hooks))
+	for event := range config.Hooks {
+		events = append(events, string(event))
+	}
+	sort.Strings(events)
+
+	for _, name := range events {
+		event := types.HookEvent(name)
+		if !event.IsValid() {
+			return nil, fmt.Errorf("hook config: unknown hook event %q", event)
+		}
+
+		for _, matcherConfig := range config.Hooks[event] {
+			matcher := types.HookMatcher{
+				Async:          matcherConfig.Async,
+				AsyncTimeout:   matcherConfig.AsyncTimeout,
+				Command:        matcherConfig.Command,
+				TimeoutSeconds: matcherConfig.TimeoutSeconds,
+			}
+			if matcherConfig.Matcher != "" {
+				pattern := matcherConfig.Matcher
+				matcher.Matcher = &pattern
+			}
+
+			for _, hookName := range matcherConfig.Hooks {
+				hook, ok := r.Lookup(hookName)
+				if !ok {
+					return nil, fmt.Errorf("hook config: %s: unknown hook %q (registered: %s)", event, hookName, strings.Join(r.Names(), ", "))
+				}
+				matcher.Hooks = append(matcher.Hooks, hook)
+			}
+			resolved[event] = append(resolved[event], matcher)
+		}
+	}
+
+	return resolved, nil
+}
+
+// Apply resolves a configuration and adds the resulting hooks to opts.
+// Nothing is added if any name fails to resolve.
+func (r *Registry) Apply(opts *types.ClaudeAgentOptions, config *Config) error {
+	resolved, err := r.Resolve(config)
+	if err != nil {
+		return err
+	}
+	for event, matchers := range resolved {
+		for _, matcher := range matchers {
+			opts.WithHook(event, matcher)
+		}
+	}
+	return nil
+}
+
+// Apply resolves a configuration against the default registry and adds the
+// resulting hooks to opts.
+func Apply(opts *types.ClaudeAgentOptions, config *Config) error {
+	return defaultRegistry.Apply(opts, config)
+}
+
+// ApplyFile loads a JSON hook configuration file and applies it to opts using
+// the default registry.
+func ApplyFile(opts *types.ClaudeAgentOptions, path string) error {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	return Apply(opts, config)
+}
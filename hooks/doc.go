@@ -0,0 +1,23 @@
+// Package hooks provides ready-made hook matchers for common policies.
+//
+// Each constructor returns a types.HookMatcher that is registered with a single
+// WithHook call on the options:
+//
+//	opts := types.NewClaudeAgentOptions().
+//	    WithHook(types.HookEventPreToolUse, hooks.Logging(slog.Default())).
+//	    WithHook(types.HookEventPreToolUse, hooks.Redact()).
+//	    WithHook(types.HookEventPreToolUse, hooks.RateLimit(10, time.Minute)).
+//	    WithHook(types.HookEventPreToolUse, hooks.PathGuard("/path/to/project"))
+//
+// The returned matchers are plain values, so their Matcher pattern can be
+// narrowed before registration, and their callbacks can be composed with
+// other hooks through types.HookChain.
+//
+// Hooks can also be registered by name and wired from a configuration file,
+// so that hook policy can change without touching option-building code:
+//
+//	hooks.Register("audit", auditHook)
+//	if err := hooks.ApplyFile(opts, "hooks.json"); err != nil {
+//	    log.Fatal(err)
+//	}
+package hooks
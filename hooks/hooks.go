@@ -0,0 +1,37 @@
+package hooks
+
+import (
+	"encoding/json"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// toolUse holds the fields of a tool hook input used by the built-in hooks.
+type toolUse struct {
+	HookEventName string                 `json:"hook_event_name"`
+	SessionID     string                 `json:"session_id"`
+	CWD           string                 `json:"cwd"`
+	ToolName      string                 `json:"tool_name"`
+	ToolInput     map[string]interface{} `json:"tool_input"`
+}
+
+// parseToolUse extracts tool fields from a hook input.
+// The CLI delivers inputs as JSON objects; typed inputs are accepted as well.
+func parseToolUse(input interface{}) toolUse {
+	var use toolUse
+	data, err := json.Marshal(input)
+	if err != nil {
+		return use
+	}
+	_ = json.Unmarshal(data, &use)
+	return use
+}
+
+// matcher wraps a single callback in a HookMatcher with an optional tool pattern.
+func matcher(pattern string, hook types.HookCallbackFunc) types.HookMatcher {
+	m := types.HookMatcher{Hooks: []types.HookCallbackFunc{hook}}
+	if pattern != "" {
+		m.Matcher = &pattern
+	}
+	return m
+}
@@ -0,0 +1,354 @@
+// Package hooktest provides utilities for testing hooks without a live CLI.
+//
+// A CLI simulates the Claude Code side of the control protocol: it performs the
+// initialize handshake with the SDK, then sends hook_callback control requests
+// for the hooks whose matchers apply, exactly as the CLI would. Hooks therefore
+// go through the same matching, output normalization and validation as in
+// production.
+//
+//	func TestGuardHook(t *testing.T) {
+//	    opts := types.NewClaudeAgentOptions().
+//	        WithHook(types.HookEventPreToolUse, hooks.PathGuard("/repo"))
+//
+//	    cli, err := hooktest.New(opts)
+//	    if err != nil {
+//	        t.Fatal(err)
+//	    }
+//	    defer cli.Close()
+//
+//	    results, err := cli.PreToolUse(ctx, "Write", map[string]interface{}{"file_path": "/etc/passwd"})
+//	    if err != nil {
+//	        t.Fatal(err)
+//	    }
+//	    if results.PermissionDecision() != "deny" {
+//	        t.Errorf("expected write outside /repo to be denied")
+//	    }
+//	}
+package hooktest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"sync/atomic"
+
+	"github.com/M1n9X/claude-agent-sdk-go/internal"
+	"github.com/M1n9X/claude-agent-sdk-go/internal/log"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// SessionID is the session ID used in canned hook inputs.
+const SessionID = "hooktest-session"
+
+// registration is a matcher registered by the SDK during initialize.
+type registration struct {
+	matcher     string
+	callbackIDs []string
+}
+
+// CLI simulates the CLI side of the hook control protocol.
+type CLI struct {
+	query     *internal.Query
+	transport *fakeTransport
+	nextID    int64
+}
+
+// New starts a simulated CLI session for the hooks in opts and completes the
+// initialize handshake.
+func New(opts *types.ClaudeAgentOptions) (*CLI, error) {
+	if opts == nil {
+		opts = types.NewClaudeAgentOptions()
+	}
+
+	ctx := context.Background()
+	transport := newFakeTransport()
+	logger := log.NewLogger(opts.Verbose)
+	logger.SetRedactor(opts.RedactCredentials)
+	query := internal.NewQuery(ctx, transport, opts, logger, true)
+
+	if err := query.Start(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := query.Initialize(ctx); err != nil {
+		_ = query.Stop(ctx)
+		return nil, err
+	}
+
+	return &CLI{query: query, transport: transport}, nil
+}
+
+// Close stops the simulated session.
+func (c *CLI) Close() error {
+	err := c.query.Stop(context.Background())
+	_ = c.transport.Close(context.Background())
+	return err
+}
+
+// CallbackIDs returns the hook callback IDs the SDK registered for an event.
+func (c *CLI) CallbackIDs(event types.HookEvent) []string {
+	c.transport.mu.Lock()
+	defer c.transport.mu.Unlock()
+
+	var ids []string
+	for _, reg := range c.transport.hooks[event] {
+		ids = append(ids, reg.callbackIDs...)
+	}
+	return ids
+}
+
+// Fire sends hook_callback requests for every registered hook whose matcher
+// applies to the input, in registration order, and collects the responses.
+// The tool_name of tool events is matched against each matcher as a regular
+// expression; other events run all hooks.
+func (c *CLI) Fire(ctx context.Context, event types.HookEvent, input map[string]interface{}) (Results, error) {
+	c.transport.mu.Lock()
+	registrations := append([]registration(nil), c.transport.hooks[event]...)
+	c.transport.mu.Unlock()
+
+	toolName, _ := input["tool_name"].(string)
+	toolUseID := fmt.Sprintf("toolu_hooktest_%d", atomic.AddInt64(&c.nextID, 1))
+
+	var results Results
+	for _, reg := range registrations {
+		matches, err := matcherApplies(reg.matcher, toolName)
+		if err != nil {
+			return results, err
+		}
+		if !matches {
+			continue
+		}
+
+		for _, callbackID := range reg.callbackIDs {
+			output, err := c.transport.request(ctx, map[string]interface{}{
+				"subtype":     "hook_callback",
+				"callback_id": callbackID,
+				"input":       input,
+				"tool_use_id": toolUseID,
+			})
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+			results = append(results, Result{CallbackID: callbackID, Output: output, Err: err})
+		}
+	}
+	return results, nil
+}
+
+// PreToolUse fires a PreToolUse event with a canned input.
+func (c *CLI) PreToolUse(ctx context.Context, toolName string, toolInput map[string]interface{}) (Results, error) {
+	return c.Fire(ctx, types.HookEventPreToolUse, PreToolUseInput(toolName, toolInput))
+}
+
+// PostToolUse fires a PostToolUse event with a canned input.
+func (c *CLI) PostToolUse(ctx context.Context, toolName string, toolInput map[string]interface{}, toolResponse interface{}) (Results, error) {
+	return c.Fire(ctx, types.HookEventPostToolUse, PostToolUseInput(toolName, toolInput, toolResponse))
+}
+
+// PreToolUseInput returns a canned PreToolUse hook input.
+func PreToolUseInput(toolName string, toolInput map[string]interface{}) map[string]interface{} {
+	input := baseInput(types.HookEventPreToolUse)
+	input["tool_name"] = toolName
+	input["tool_input"] = toolInput
+	return input
+}
+
+// PostToolUseInput returns a canned PostToolUse hook input.
+func PostToolUseInput(toolName string, toolInput map[string]interface{}, toolResponse interface{}) map[string]interface{} {
+	input := baseInput(types.HookEventPostToolUse)
+	input["tool_name"] = toolName
+	input["tool_input"] = toolInput
+	input["tool_response"] = toolResponse
+	return input
+}
+
+// UserPromptSubmitInput returns a canned UserPromptSubmit hook input.
+func UserPromptSubmitInput(prompt string) map[string]interface{} {
+	input := baseInput(types.HookEventUserPromptSubmit)
+	input["prompt"] = prompt
+	return input
+}
+
+// baseInput returns the fields common to all hook inputs.
+func baseInput(event types.HookEvent) map[string]interface{} {
+	cwd, _ := os.Getwd()
+	return map[string]interface{}{
+		"session_id":      SessionID,
+		"transcript_path": "",
+		"cwd":             cwd,
+		"hook_event_name": string(event),
+	}
+}
+
+// matcherApplies reports whether a CLI matcher selects the tool.
+func matcherApplies(matcher, toolName string) (bool, error) {
+	if matcher == "" || matcher == "*" || toolName == "" {
+		return true, nil
+	}
+	re, err := regexp.Compile("^(?:" + matcher + ")$")
+	if err != nil {
+		return false, fmt.Errorf("invalid hook matcher %q: %w", matcher, err)
+	}
+	return re.MatchString(toolName), nil
+}
+
+// decodeRegistrations extracts the hooks section of an initialize request.
+func decodeRegistrations(raw interface{}) map[types.HookEvent][]registration {
+	hooks := make(map[types.HookEvent][]registration)
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return hooks
+	}
+
+	var config map[string][]struct {
+		Matcher         string   `json:"matcher"`
+		HookCallbackIDs []string `json:"hookCallbackIds"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return hooks
+	}
+
+	for event, matchers := range config {
+		for _, m := range matchers {
+			hooks[types.HookEvent(event)] = append(hooks[types.HookEvent(event)], registration{
+				matcher:     m.Matcher,
+				callbackIDs: m.HookCallbackIDs,
+			})
+		}
+	}
+	return hooks
+}
+
+// fakeTransport plays the CLI end of the control protocol in memory.
+type fakeTransport struct {
+	mu       sync.Mutex
+	messages chan types.Message
+	closed   bool
+	hooks    map[types.HookEvent][]registration
+	pending  map[string]chan Result
+	nextID   int64
+}
+
+func newFakeTransport() *fakeTransport {
+	return &fakeTransport{
+		messages: make(chan types.Message, 64),
+		hooks:    make(map[types.HookEvent][]registration),
+		pending:  make(map[string]chan Result),
+	}
+}
+
+func (f *fakeTransport) Connect(ctx context.Context) error { return nil }
+
+func (f *fakeTransport) Close(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.closed {
+		f.closed = true
+		close(f.messages)
+	}
+	return nil
+}
+
+func (f *fakeTransport) ReadMessages(ctx context.Context) <-chan types.Message {
+	return f.messages
+}
+
+func (f *fakeTransport) OnError(err error) {}
+
+func (f *fakeTransport) IsReady() bool { return true }
+
+func (f *fakeTransport) GetError() error { return nil }
+
+// Write receives a line from the SDK and answers it as the CLI would.
+func (f *fakeTransport) Write(ctx context.Context, data string) error {
+	var msg struct {
+		Type      string                 `json:"type"`
+		RequestID string                 `json:"request_id"`
+		Request   map[string]interface{} `json:"request"`
+		Response  map[string]interface{} `json:"response"`
+	}
+	if err := json.Unmarshal([]byte(data), &msg); err != nil {
+		return fmt.Errorf("hooktest: invalid message from SDK: %w", err)
+	}
+
+	switch msg.Type {
+	case "control_request":
+		f.handleRequest(msg.RequestID, msg.Request)
+	case "control_response":
+		f.handleResponse(msg.Response)
+	}
+	return nil
+}
+
+// handleRequest answers an SDK-initiated control request.
+func (f *fakeTransport) handleRequest(requestID string, request map[string]interface{}) {
+	if request["subtype"] == "initialize" {
+		f.mu.Lock()
+		f.hooks = decodeRegistrations(request["hooks"])
+		f.mu.Unlock()
+	}
+
+	f.send(&types.SystemMessage{
+		Type: "control_response",
+		Response: map[string]interface{}{
+			"subtype":    "success",
+			"request_id": requestID,
+			"response":   map[string]interface{}{},
+		},
+	})
+}
+
+// handleResponse delivers the SDK's answer to a hook_callback request.
+func (f *fakeTransport) handleResponse(response map[string]interface{}) {
+	requestID, _ := response["request_id"].(string)
+
+	f.mu.Lock()
+	ch, ok := f.pending[requestID]
+	delete(f.pending, requestID)
+	f.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	var result Result
+	if response["subtype"] == "error" {
+		errMsg, _ := response["error"].(string)
+		result.Err = types.NewControlProtocolError(errMsg)
+	} else {
+		result.Output, _ = response["response"].(map[string]interface{})
+	}
+	ch <- result
+}
+
+// request sends a CLI-initiated control request to the SDK and waits for the answer.
+func (f *fakeTransport) request(ctx context.Context, request map[string]interface{}) (map[string]interface{}, error) {
+	requestID := fmt.Sprintf("hooktest-%d", atomic.AddInt64(&f.nextID, 1))
+	ch := make(chan Result, 1)
+
+	f.mu.Lock()
+	f.pending[requestID] = ch
+	f.mu.Unlock()
+
+	f.send(&types.SystemMessage{Type: "control_request", RequestID: requestID, Request: request})
+
+	select {
+	case result := <-ch:
+		return result.Output, result.Err
+	case <-ctx.Done():
+		f.mu.Lock()
+		delete(f.pending, requestID)
+		f.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// send queues a message for the SDK.
+func (f *fakeTransport) send(msg types.Message) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.closed {
+		f.messages <- msg
+	}
+}
@@ -0,0 +1,248 @@
+package hooktest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/hooks"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// TestCLIPreToolUse tests firing PreToolUse hooks through the simulated control protocol.
+func TestCLIPreToolUse(t *testing.T) {
+	root := t.TempDir()
+	opts := types.NewClaudeAgentOptions().
+		WithHook(types.HookEventPreToolUse, hooks.PathGuard(root))
+
+	cli, err := New(opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer cli.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if len(cli.CallbackIDs(types.HookEventPreToolUse)) == 0 {
+		t.Fatal("expected PreToolUse callbacks to be registered")
+	}
+
+	results, err := cli.PreToolUse(ctx, "Write", map[string]interface{}{"file_path": "/etc/passwd"})
+	if err != nil {
+		t.Fatalf("PreToolUse failed: %v", err)
+	}
+	if err := results.Err(); err != nil {
+		t.Fatalf("hook returned error: %v", err)
+	}
+	if results.PermissionDecision() != "deny" || !results.Blocked() {
+		t.Errorf("expected write outside root to be denied, got %v", results)
+	}
+
+	results, err = cli.PreToolUse(ctx, "Read", map[string]interface{}{"file_path": "/etc/passwd"})
+	if err != nil {
+		t.Fatalf("PreToolUse failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected Read not to match the PathGuard matcher, got %v", results)
+	}
+}
+
+// TestCLISpecifierMatcher tests that tool pattern specifiers are applied to fired inputs.
+func TestCLISpecifierMatcher(t *testing.T) {
+	matcher := "Bash(git *)"
+	opts := types.NewClaudeAgentOptions().WithHook(types.HookEventPreToolUse, types.HookMatcher{
+		Matcher: &matcher,
+		Hooks: []types.HookCallbackFunc{
+			func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+				return types.DenyToolUse("git is disabled"), nil
+			},
+		},
+	})
+
+	cli, err := New(opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer cli.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results, err := cli.PreToolUse(ctx, "Bash", map[string]interface{}{"command": "git push"})
+	if err != nil {
+		t.Fatalf("PreToolUse failed: %v", err)
+	}
+	if results.PermissionDecision() != "deny" {
+		t.Errorf("expected git command to be denied, got %v", results)
+	}
+
+	results, err = cli.PreToolUse(ctx, "Bash", map[string]interface{}{"command": "ls"})
+	if err != nil {
+		t.Fatalf("PreToolUse failed: %v", err)
+	}
+	if results.PermissionDecision() != "" {
+		t.Errorf("expected non-git command to pass, got %v", results)
+	}
+}
+
+// TestCLIPostToolUse tests PostToolUse inputs, updated input and hook errors.
+func TestCLIPostToolUse(t *testing.T) {
+	var seen map[string]interface{}
+	opts := types.NewClaudeAgentOptions().WithHook(types.HookEventPostToolUse, types.HookMatcher{
+		Hooks: []types.HookCallbackFunc{
+			func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+				seen, _ = input.(map[string]interface{})
+				return types.AddContext(types.HookEventPostToolUse, "checked"), nil
+			},
+			func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+				return nil, types.NewControlProtocolError("boom")
+			},
+		},
+	})
+
+	cli, err := New(opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer cli.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results, err := cli.PostToolUse(ctx, "Bash", map[string]interface{}{"command": "ls"}, "file.txt")
+	if err != nil {
+		t.Fatalf("PostToolUse failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if seen["tool_response"] != "file.txt" || seen["session_id"] != SessionID {
+		t.Errorf("unexpected hook input: %v", seen)
+	}
+	if results[0].Err != nil {
+		t.Errorf("unexpected error from first hook: %v", results[0].Err)
+	}
+	if results.Err() == nil {
+		t.Error("expected error from second hook")
+	}
+}
+
+// TestMatcherApplies tests CLI-style matcher selection.
+func TestMatcherApplies(t *testing.T) {
+	tests := []struct {
+		matcher string
+		tool    string
+		want    bool
+	}{
+		{"", "Bash", true},
+		{"*", "Bash", true},
+		{"Bash", "Bash", true},
+		{"Write|Edit", "Edit", true},
+		{"Write|Edit", "Read", false},
+		{"Bash", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := matcherApplies(tt.matcher, tt.tool)
+		if err != nil || got != tt.want {
+			t.Errorf("matcherApplies(%q, %q) = %v, %v; want %v", tt.matcher, tt.tool, got, err, tt.want)
+		}
+	}
+
+	if _, err := matcherApplies("(", "Bash"); err == nil {
+		t.Error("expected error for invalid matcher")
+	}
+}
+
+// TestCLIInPlaceInputEdit tests that tool input edits made in place reach the CLI as updatedInput.
+func TestCLIInPlaceInputEdit(t *testing.T) {
+	opts := types.NewClaudeAgentOptions().WithHook(types.HookEventPreToolUse, types.HookMatcher{
+		Hooks: []types.HookCallbackFunc{
+			func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+				toolInput := input.(map[string]interface{})["tool_input"].(map[string]interface{})
+				toolInput["command"] = "ls -la"
+				return types.PreToolAllow(), nil
+			},
+		},
+	})
+
+	cli, err := New(opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer cli.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results, err := cli.PreToolUse(ctx, "Bash", map[string]interface{}{"command": "ls"})
+	if err != nil {
+		t.Fatalf("PreToolUse failed: %v", err)
+	}
+	if results.PermissionDecision() != "allow" || results.UpdatedInput()["command"] != "ls -la" {
+		t.Errorf("expected edited input to be propagated, got %v", results)
+	}
+}
+
+// TestCLIHookExecutionSerial tests that serial execution combines matchers into one callback.
+func TestCLIHookExecutionSerial(t *testing.T) {
+	bash := "Bash"
+	write := "Write"
+	var saw string
+	var writeRan bool
+	opts := types.NewClaudeAgentOptions().
+		WithHookExecution(types.HookEventPreToolUse, types.HookExecutionSerial).
+		WithHook(types.HookEventPreToolUse, types.HookMatcher{
+			Matcher: &bash,
+			Hooks: []types.HookCallbackFunc{
+				func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+					return types.UpdateToolInput(map[string]interface{}{"command": "ls -la"}), nil
+				},
+			},
+		}).
+		WithHook(types.HookEventPreToolUse, types.HookMatcher{
+			Matcher: &write,
+			Hooks: []types.HookCallbackFunc{
+				func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+					writeRan = true
+					return types.PreToolDeny("no writes"), nil
+				},
+			},
+		}).
+		WithHook(types.HookEventPreToolUse, types.HookMatcher{
+			Hooks: []types.HookCallbackFunc{
+				func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+					saw, _ = input.(map[string]interface{})["tool_input"].(map[string]interface{})["command"].(string)
+					return types.PreToolAsk("confirm"), nil
+				},
+			},
+		})
+
+	cli, err := New(opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer cli.Close()
+
+	if ids := cli.CallbackIDs(types.HookEventPreToolUse); len(ids) != 1 {
+		t.Fatalf("expected a single combined callback, got %v", ids)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results, err := cli.PreToolUse(ctx, "Bash", map[string]interface{}{"command": "ls"})
+	if err != nil {
+		t.Fatalf("PreToolUse failed: %v", err)
+	}
+	if writeRan {
+		t.Error("expected the Write matcher not to run for Bash")
+	}
+	if saw != "ls -la" {
+		t.Errorf("expected later hook to see rewritten input, got %q", saw)
+	}
+	if results.PermissionDecision() != "ask" || results.UpdatedInput()["command"] != "ls -la" {
+		t.Errorf("unexpected merged output: %v", results)
+	}
+}
@@ -0,0 +1,65 @@
+package hooktest
+
+// Result is the SDK's response to a single hook_callback request.
+type Result struct {
+	CallbackID string
+	Output     map[string]interface{}
+	Err        error
+}
+
+// Results holds the responses of all hooks fired for one event.
+type Results []Result
+
+// permissionDecisionRank orders permission decisions from least to most restrictive.
+var permissionDecisionRank = map[string]int{"allow": 1, "ask": 2, "deny": 3}
+
+// Err returns the first hook error, if any.
+func (r Results) Err() error {
+	for _, result := range r {
+		if result.Err != nil {
+			return result.Err
+		}
+	}
+	return nil
+}
+
+// PermissionDecision returns the most restrictive permissionDecision across
+// the hooks ("deny" > "ask" > "allow"), or "" if none made one.
+func (r Results) PermissionDecision() string {
+	decision := ""
+	for _, result := range r {
+		d, _ := result.specific()["permissionDecision"].(string)
+		if permissionDecisionRank[d] > permissionDecisionRank[decision] {
+			decision = d
+		}
+	}
+	return decision
+}
+
+// UpdatedInput returns the tool input rewritten by the last hook that set one.
+func (r Results) UpdatedInput() map[string]interface{} {
+	var updated map[string]interface{}
+	for _, result := range r {
+		if input, ok := result.specific()["updatedInput"].(map[string]interface{}); ok {
+			updated = input
+		}
+	}
+	return updated
+}
+
+// Blocked reports whether any hook blocked the action with decision "block",
+// stopped the session with continue=false, or denied the tool use.
+func (r Results) Blocked() bool {
+	for _, result := range r {
+		if result.Output["decision"] == "block" || result.Output["continue"] == false {
+			return true
+		}
+	}
+	return r.PermissionDecision() == "deny"
+}
+
+// specific returns the hookSpecificOutput of a result.
+func (r Result) specific() map[string]interface{} {
+	specific, _ := r.Output["hookSpecificOutput"].(map[string]interface{})
+	return specific
+}
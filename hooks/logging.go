@@ -0,0 +1,48 @@
+package hooks
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Logging returns a hook matcher that records every tool use as a structured log entry.
+//
+// Each entry carries the hook event, session ID, tool name, tool use ID and the
+// names of the input parameters. Parameter values are not logged so that
+// secrets in tool inputs do not end up in logs. The hook never changes the
+// outcome of the tool use. A nil logger uses slog.Default() as it is when
+// the hook runs, so later calls to slog.SetDefault take effect.
+//
+// Register it on HookEventPreToolUse to log requested calls, or on
+// HookEventPostToolUse to log completed ones.
+func Logging(logger *slog.Logger) types.HookMatcher {
+	return matcher("", func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+		logger := logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		use := parseToolUse(input)
+
+		params := make([]string, 0, len(use.ToolInput))
+		for name := range use.ToolInput {
+			params = append(params, name)
+		}
+		slices.Sort(params)
+
+		attrs := []slog.Attr{
+			slog.String("event", use.HookEventName),
+			slog.String("session_id", use.SessionID),
+			slog.String("tool_name", use.ToolName),
+			slog.Any("params", params),
+		}
+		if toolUseID != nil {
+			attrs = append(attrs, slog.String("tool_use_id", *toolUseID))
+		}
+		logger.LogAttrs(ctx, slog.LevelInfo, "tool use", attrs...)
+
+		return map[string]interface{}{}, nil
+	})
+}
@@ -0,0 +1,104 @@
+package hooks
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// HookStats aggregates the executions of a single hook callback.
+type HookStats struct {
+	CallbackID    string
+	Event         types.HookEvent
+	Matcher       *string
+	Calls         int
+	Errors        int
+	Timeouts      int
+	TotalDuration time.Duration
+	MaxDuration   time.Duration
+	Decisions     map[string]int // Keyed by types.HookOutputDecision; "" counts no decision
+}
+
+// AverageDuration returns the mean execution time.
+func (s HookStats) AverageDuration() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Calls)
+}
+
+// Metrics collects hook execution records into per-callback statistics.
+// Register its Record method with WithHookMetrics:
+//
+//	metrics := hooks.NewMetrics()
+//	opts.WithHookMetrics(metrics.Record)
+//	...
+//	for _, s := range metrics.Snapshot() {
+//	    log.Printf("%s hook %s: %d calls, avg %v", s.Event, s.CallbackID, s.Calls, s.AverageDuration())
+//	}
+type Metrics struct {
+	mu    sync.Mutex
+	stats map[string]*HookStats
+}
+
+// NewMetrics creates an empty metrics collector.
+func NewMetrics() *Metrics {
+	return &Metrics{stats: make(map[string]*HookStats)}
+}
+
+// Record adds a hook execution. It is safe for concurrent use.
+func (m *Metrics) Record(execution types.HookExecution) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.stats[execution.CallbackID]
+	if !ok {
+		stats = &HookStats{
+			CallbackID: execution.CallbackID,
+			Event:      execution.Event,
+			Matcher:    execution.Matcher,
+			Decisions:  make(map[string]int),
+		}
+		m.stats[execution.CallbackID] = stats
+	}
+
+	stats.Calls++
+	stats.TotalDuration += execution.Duration
+	if execution.Duration > stats.MaxDuration {
+		stats.MaxDuration = execution.Duration
+	}
+	switch {
+	case execution.TimedOut:
+		stats.Timeouts++
+	case execution.Err != nil:
+		stats.Errors++
+	default:
+		stats.Decisions[execution.Decision]++
+	}
+}
+
+// Snapshot returns a copy of the statistics, slowest hooks (by maximum duration) first.
+func (m *Metrics) Snapshot() []HookStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make([]HookStats, 0, len(m.stats))
+	for _, stats := range m.stats {
+		clone := *stats
+		clone.Decisions = make(map[string]int, len(stats.Decisions))
+		for decision, count := range stats.Decisions {
+			clone.Decisions[decision] = count
+		}
+		snapshot = append(snapshot, clone)
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].MaxDuration != snapshot[j].MaxDuration {
+			return snapshot[i].MaxDuration > snapshot[j].MaxDuration
+		}
+		return snapshot[i].CallbackID < snapshot[j].CallbackID
+	})
+	return snapshot
+}
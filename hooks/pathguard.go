@@ -0,0 +1,42 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/M1n9X/claude-agent-sdk-go/internal/pathutil"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// WriteToolsPattern matches the built-in tools that modify files.
+const WriteToolsPattern = "Write|Edit|MultiEdit|NotebookEdit"
+
+// pathKeys are the tool input parameters that hold the file being written.
+var pathKeys = []string{"file_path", "notebook_path", "path"}
+
+// PathGuard returns a PreToolUse hook matcher that denies file writes outside root.
+//
+// It applies to the tools in WriteToolsPattern. Relative paths are resolved
+// against the session's working directory, and paths are cleaned before the
+// check, so "root/../elsewhere" is rejected. Root may be given through a
+// symlink; symlinks inside root are resolved, so a link pointing elsewhere
+// cannot be written through.
+func PathGuard(root string) types.HookMatcher {
+	roots := pathutil.Roots(root)
+
+	return matcher(WriteToolsPattern, func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+		use := parseToolUse(input)
+
+		for _, key := range pathKeys {
+			path, ok := use.ToolInput[key].(string)
+			if !ok || path == "" {
+				continue
+			}
+			path = pathutil.Join(use.CWD, path)
+			if !pathutil.Within(roots, path) {
+				return types.DenyToolUse(fmt.Sprintf("%s may not write outside %s: %s", use.ToolName, roots[0], path)), nil
+			}
+		}
+		return map[string]interface{}{}, nil
+	})
+}
@@ -0,0 +1,61 @@
+package hooks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// rateLimiter gives each tool a limit of its own in a types.ToolRateLimiter,
+// set when the tool is first used.
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	limiter *types.ToolRateLimiter
+	tools   map[string]bool // Tools with a limit
+}
+
+// RateLimit returns a PreToolUse hook matcher that allows at most limit uses of
+// each tool within a sliding window, denying further uses until the window
+// frees up. Each tool name is counted separately; denied uses are not counted.
+// It is types.ToolRateLimiter with a limit per tool. RateLimit panics if the
+// limit or window is not positive, as reported by ToolRateLimiter.Validate.
+//
+// Narrow the matcher's Matcher pattern to rate-limit only specific tools.
+func RateLimit(limit int, window time.Duration) types.HookMatcher {
+	if err := types.NewToolRateLimiter().SetLimit("*", limit, window).Validate(); err != nil {
+		panic("hooks.RateLimit: " + err.Error())
+	}
+	limiter := &rateLimiter{
+		limit:   limit,
+		window:  window,
+		limiter: types.NewToolRateLimiter(),
+		tools:   make(map[string]bool),
+	}
+	return matcher("", limiter.hook)
+}
+
+// hook is the hook callback for the rate limiter.
+func (r *rateLimiter) hook(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+	use := parseToolUse(input)
+
+	r.mu.Lock()
+	if !r.tools[use.ToolName] {
+		r.limiter.SetLimit(use.ToolName, r.limit, r.window)
+		r.tools[use.ToolName] = true
+	}
+	r.mu.Unlock()
+
+	reason, err := r.limiter.Acquire(ctx, use.ToolName, use.ToolInput)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		return types.DenyToolUse(reason), nil
+	}
+	return map[string]interface{}{}, nil
+}
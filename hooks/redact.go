@@ -0,0 +1,36 @@
+package hooks
+
+import (
+	"context"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Redact returns a PreToolUse hook matcher that replaces secrets in tool inputs
+// with their types.RedactionPlaceholder before the tool runs.
+//
+// String values are scanned recursively through nested objects and arrays by a
+// types.Redactor. When no detectors are given, types.DefaultSecretDetectors is
+// used. Inputs without matches are left untouched, and the hook never makes a
+// permission decision.
+func Redact(detectors ...types.SecretDetector) types.HookMatcher {
+	if len(detectors) == 0 {
+		detectors = types.DefaultSecretDetectors()
+	}
+
+	return matcher("", func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+		use := parseToolUse(input)
+		if use.ToolInput == nil {
+			return map[string]interface{}{}, nil
+		}
+
+		// A Redactor per call, so that its report does not grow for the session
+		redactor := types.NewRedactor(detectors...)
+		redacted := redactor.RedactToolInput(use.ToolInput)
+		if redactor.Report().Total == 0 {
+			return map[string]interface{}{}, nil
+		}
+
+		return types.UpdateToolInput(redacted), nil
+	})
+}
@@ -0,0 +1,91 @@
+package hooks
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Built-in hook names registered in the default registry.
+const (
+	NameLogToolUse    = "log-tool-use"
+	NameRedactSecrets = "redact-secrets"
+)
+
+// Registry maps names to hook callbacks so that hook wiring can be described
+// in configuration rather than code.
+type Registry struct {
+	mu    sync.RWMutex
+	hooks map[string]types.HookCallbackFunc
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{hooks: make(map[string]types.HookCallbackFunc)}
+}
+
+// defaultRegistry is used by the package-level functions. It comes with the
+// built-in hooks that need no parameters.
+var defaultRegistry = func() *Registry {
+	r := NewRegistry()
+	_ = r.Register(NameLogToolUse, Logging(nil).Hooks[0])
+	_ = r.Register(NameRedactSecrets, Redact().Hooks[0])
+	return r
+}()
+
+// Register adds a named hook. Registering a name twice is an error.
+func (r *Registry) Register(name string, hook types.HookCallbackFunc) error {
+	if name == "" {
+		return fmt.Errorf("hook name cannot be empty")
+	}
+	if hook == nil {
+		return fmt.Errorf("hook %q: callback cannot be nil", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.hooks[name]; exists {
+		return fmt.Errorf("hook %q is already registered", name)
+	}
+	r.hooks[name] = hook
+	return nil
+}
+
+// Lookup returns the hook registered under name.
+func (r *Registry) Lookup(name string) (types.HookCallbackFunc, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	hook, ok := r.hooks[name]
+	return hook, ok
+}
+
+// Names returns the registered hook names in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.hooks))
+	for name := range r.hooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Register adds a named hook to the default registry.
+func Register(name string, hook types.HookCallbackFunc) error {
+	return defaultRegistry.Register(name, hook)
+}
+
+// Lookup returns a hook from the default registry.
+func Lookup(name string) (types.HookCallbackFunc, bool) {
+	return defaultRegistry.Lookup(name)
+}
+
+// DefaultRegistry returns the registry used by the package-level functions.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
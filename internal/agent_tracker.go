@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"sync"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// agentToolNames lists the tools that start a subagent.
+var agentToolNames = map[string]bool{"Task": true, "Agent": true}
+
+// agentTracker maps tool uses to the subagent that made them, using the
+// subagent_type of Task tool uses and the parent_tool_use_id of subagent
+// messages.
+//
+// It relies on the assistant message holding a tool use being routed before
+// the hook and permission requests for it are handled: the CLI writes the
+// message before running the tool, routeMessage observes messages in the
+// order they are read, and control requests read after it are handled later.
+type agentTracker struct {
+	mu       sync.Mutex
+	tasks    map[string]string       // Task tool use ID -> agent name
+	toolUses map[string]toolUseAgent // Tool use ID -> who made it
+}
+
+// toolUseAgent is who made a tool use.
+type toolUseAgent struct {
+	subagent bool   // Made by a subagent rather than the main agent
+	agent    string // Name of the subagent, if its Task tool use was observed
+}
+
+// newAgentTracker creates an empty tracker.
+func newAgentTracker() *agentTracker {
+	return &agentTracker{
+		tasks:    make(map[string]string),
+		toolUses: make(map[string]toolUseAgent),
+	}
+}
+
+// observe records the Task and subagent tool uses in an assistant message.
+func (t *agentTracker) observe(msg types.Message) {
+	assistant, ok := msg.(*types.AssistantMessage)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var agent toolUseAgent
+	if assistant.ParentToolUseID != nil {
+		agent = toolUseAgent{subagent: true, agent: t.tasks[*assistant.ParentToolUseID]}
+	}
+	for _, block := range assistant.Content {
+		toolUse, ok := block.(*types.ToolUseBlock)
+		if !ok {
+			continue
+		}
+		if agentToolNames[toolUse.Name] {
+			if subagent, _ := toolUse.Input["subagent_type"].(string); subagent != "" {
+				t.tasks[toolUse.ID] = subagent
+			}
+		}
+		t.toolUses[toolUse.ID] = agent
+	}
+}
+
+// agentForTask returns the agent started by a Task tool use.
+func (t *agentTracker) agentForTask(taskID *string) string {
+	if taskID == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tasks[*taskID]
+}
+
+// agentForToolUse returns who made a tool use, and false if the tool use
+// was not observed.
+func (t *agentTracker) agentForToolUse(toolUseID *string) (toolUseAgent, bool) {
+	if toolUseID == nil {
+		return toolUseAgent{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	agent, ok := t.toolUses[*toolUseID]
+	return agent, ok
+}
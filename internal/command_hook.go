@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// commandHookExitBlock is the exit code a hook command uses to block the action,
+// matching the convention of Claude Code settings hooks.
+const commandHookExitBlock = 2
+
+// commandHookEnvPassthrough lists the environment variables a hook command inherits.
+// Everything else, including API keys, is withheld unless set in CommandEnv.
+var commandHookEnvPassthrough = []string{"PATH", "HOME", "TMPDIR", "LANG", "SYSTEMROOT"}
+
+// newCommandHook returns a hook callback that runs an external command.
+//
+// The hook input is written to the command's stdin as JSON. On exit status 0,
+// stdout is parsed as the hook output if it is a JSON object and ignored
+// otherwise. Exit status 2 blocks the action with stderr as the reason: a
+// PreToolUse hook denies the tool use, other events return decision "block".
+// Any other exit status, or exceeding the timeout, is returned as an error.
+func newCommandHook(command string, timeout time.Duration, env map[string]string) types.HookCallbackFunc {
+	return func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+		payload, err := json.Marshal(input)
+		if err != nil {
+			return nil, fmt.Errorf("hook command %q: marshal input: %w", command, err)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		cmd := shellCommand(ctx, command)
+		cmd.Stdin = bytes.NewReader(payload)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		raw, _ := input.(map[string]interface{})
+		cwd, _ := raw["cwd"].(string)
+		if cwd != "" {
+			cmd.Dir = cwd
+		}
+		cmd.Env = commandHookEnv(cwd, env)
+		// Don't wait on grandchildren that keep stdout open after a timeout kill
+		cmd.WaitDelay = time.Second
+
+		err = cmd.Run()
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("hook command %q timed out after %v", command, timeout)
+		}
+
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+			return parseCommandHookOutput(stdout.Bytes()), nil
+		case errors.As(err, &exitErr) && exitErr.ExitCode() == commandHookExitBlock:
+			reason := strings.TrimSpace(stderr.String())
+			if event, _ := raw["hook_event_name"].(string); event == string(types.HookEventPreToolUse) {
+				return types.DenyToolUse(reason), nil
+			}
+			return types.Block(reason), nil
+		case errors.As(err, &exitErr):
+			return nil, fmt.Errorf("hook command %q exited with status %d: %s", command, exitErr.ExitCode(), strings.TrimSpace(stderr.String()))
+		default:
+			return nil, fmt.Errorf("hook command %q: %w", command, err)
+		}
+	}
+}
+
+// shellCommand runs command through the platform shell, as settings hooks do.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// commandHookEnv builds the restricted environment for a hook command.
+func commandHookEnv(cwd string, extra map[string]string) []string {
+	env := make([]string, 0, len(commandHookEnvPassthrough)+len(extra)+1)
+	for _, key := range commandHookEnvPassthrough {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	if cwd != "" {
+		env = append(env, "CLAUDE_PROJECT_DIR="+cwd)
+	}
+	for key, value := range extra {
+		env = append(env, key+"="+value)
+	}
+	return env
+}
+
+// parseCommandHookOutput decodes stdout as a hook output, ignoring non-JSON output.
+func parseCommandHookOutput(stdout []byte) map[string]interface{} {
+	var output map[string]interface{}
+	if err := json.Unmarshal(bytes.TrimSpace(stdout), &output); err != nil || output == nil {
+		return map[string]interface{}{}
+	}
+	return output
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// runCommandHook runs a command hook with a PreToolUse input.
+func runCommandHook(t *testing.T, command string, timeout time.Duration, env map[string]string) (map[string]interface{}, error) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("command hook tests use POSIX shell syntax")
+	}
+
+	hook := newCommandHook(command, timeout, env)
+	input := map[string]interface{}{
+		"hook_event_name": "PreToolUse",
+		"cwd":             t.TempDir(),
+		"tool_name":       "Bash",
+		"tool_input":      map[string]interface{}{"command": "ls"},
+	}
+	output, err := hook(context.Background(), input, nil, types.HookContext{})
+	if err != nil {
+		return nil, err
+	}
+	return types.NormalizeHookOutput(output)
+}
+
+// TestCommandHookOutput tests that JSON on stdout is used as the hook output.
+func TestCommandHookOutput(t *testing.T) {
+	output, err := runCommandHook(t, `grep -q '"tool_name":"Bash"' && echo '{"systemMessage":"checked"}'`, 5*time.Second, nil)
+	if err != nil {
+		t.Fatalf("command hook failed: %v", err)
+	}
+	if output["systemMessage"] != "checked" {
+		t.Errorf("expected systemMessage from stdout, got %v", output)
+	}
+
+	output, err = runCommandHook(t, `echo not json`, 5*time.Second, nil)
+	if err != nil || len(output) != 0 {
+		t.Errorf("expected non-JSON stdout to be ignored, got %v (err %v)", output, err)
+	}
+}
+
+// TestCommandHookBlock tests that exit status 2 denies a PreToolUse with stderr as the reason.
+func TestCommandHookBlock(t *testing.T) {
+	output, err := runCommandHook(t, `echo "not on my watch" >&2; exit 2`, 5*time.Second, nil)
+	if err != nil {
+		t.Fatalf("command hook failed: %v", err)
+	}
+	specific, _ := output["hookSpecificOutput"].(map[string]interface{})
+	if specific["permissionDecision"] != "deny" || specific["permissionDecisionReason"] != "not on my watch" {
+		t.Errorf("expected deny with stderr reason, got %v", output)
+	}
+}
+
+// TestCommandHookErrors tests failing and slow commands.
+func TestCommandHookErrors(t *testing.T) {
+	if _, err := runCommandHook(t, `echo boom >&2; exit 1`, 5*time.Second, nil); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected error with stderr, got %v", err)
+	}
+	if _, err := runCommandHook(t, `sleep 5`, 100*time.Millisecond, nil); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+// TestCommandHookEnv tests that the command runs with a restricted environment.
+func TestCommandHookEnv(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "secret")
+
+	output, err := runCommandHook(t, `printf '{"systemMessage":"%s|%s"}' "$ANTHROPIC_API_KEY" "$GUARD_MODE"`, 5*time.Second, map[string]string{"GUARD_MODE": "strict"})
+	if err != nil {
+		t.Fatalf("command hook failed: %v", err)
+	}
+	if output["systemMessage"] != "|strict" {
+		t.Errorf("expected only CommandEnv variables to be visible, got %v", output["systemMessage"])
+	}
+}
+
+// TestResolveHookMatcherCommand tests that a matcher's Command is registered as a callback.
+func TestResolveHookMatcherCommand(t *testing.T) {
+	_, callbacks, err := resolveHookMatcher(types.HookMatcher{Command: "true", TimeoutSeconds: 5})
+	if err != nil {
+		t.Fatalf("resolveHookMatcher failed: %v", err)
+	}
+	if len(callbacks) != 1 {
+		t.Errorf("expected command to be registered as one callback, got %d", len(callbacks))
+	}
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// startCorrelation records the correlation ID in ctx for a query whose
+// prompt was just sent. It applies once the queries sent before it have
+// their result.
+func (q *Query) startCorrelation(ctx context.Context) {
+	id := types.CorrelationIDFrom(ctx)
+	q.correlationMu.Lock()
+	defer q.correlationMu.Unlock()
+	q.correlationIDs = append(q.correlationIDs, id)
+	if len(q.correlationIDs) == 1 {
+		q.logger.SetCorrelationID(id)
+	}
+}
+
+// endCorrelation ends the correlation ID of the current query, which has
+// its result, and applies the next query's.
+func (q *Query) endCorrelation() {
+	q.correlationMu.Lock()
+	defer q.correlationMu.Unlock()
+	if len(q.correlationIDs) == 0 {
+		return
+	}
+	q.correlationIDs = q.correlationIDs[1:]
+	next := ""
+	if len(q.correlationIDs) > 0 {
+		next = q.correlationIDs[0]
+	}
+	q.logger.SetCorrelationID(next)
+}
+
+// CorrelationID returns the correlation ID of the current query, or "".
+func (q *Query) CorrelationID() string {
+	q.correlationMu.Lock()
+	defer q.correlationMu.Unlock()
+	if len(q.correlationIDs) == 0 {
+		return ""
+	}
+	return q.correlationIDs[0]
+}
+
+// correlationContext returns ctx with the correlation ID of the current
+// query, if any.
+func (q *Query) correlationContext(ctx context.Context) context.Context {
+	if id := q.CorrelationID(); id != "" {
+		return types.ContextWithCorrelationID(ctx, id)
+	}
+	return ctx
+}
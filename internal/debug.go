@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+const (
+	// debugRecentMessages is how many messages DebugState keeps.
+	debugRecentMessages = 50
+
+	// debugSummaryLength is the number of characters summaries are truncated
+	// to.
+	debugSummaryLength = 200
+)
+
+// DebugState is what the debug server shows of a session.
+type DebugState struct {
+	SessionID      string
+	Running        bool // A query is awaiting its result
+	Queues         types.QueueDepths
+	CostUSD        float64 // Session cost reported by the latest result
+	RecentMessages []types.DebugMessage
+}
+
+// DebugState returns the state of the session, its backlogs and its most
+// recent messages.
+func (q *Query) DebugState() DebugState {
+	q.mu.Lock()
+	controlRequests := len(q.requestMap)
+	q.mu.Unlock()
+
+	q.timingMu.Lock()
+	queries := len(q.timings)
+	q.timingMu.Unlock()
+
+	q.debugMu.Lock()
+	defer q.debugMu.Unlock()
+	return DebugState{
+		SessionID: q.debugSessionID,
+		Running:   queries > 0,
+		Queues: types.QueueDepths{
+			Messages:        len(q.messagesChan),
+			MessageCapacity: cap(q.messagesChan),
+			ControlRequests: controlRequests,
+			Queries:         queries,
+		},
+		CostUSD:        q.debugCostUSD,
+		RecentMessages: append([]types.DebugMessage(nil), q.recent...),
+	}
+}
+
+// recordRecent keeps a summary of a message for DebugState. Stream events
+// are too many to be kept.
+func (q *Query) recordRecent(msg types.Message) {
+	if _, ok := msg.(*types.StreamEvent); ok {
+		return
+	}
+	summary := debugSummary(msg)
+	if q.redact != nil {
+		summary = q.redact(summary)
+	}
+	entry := types.DebugMessage{Time: time.Now(), Type: msg.GetMessageType(), Summary: summary}
+
+	q.debugMu.Lock()
+	defer q.debugMu.Unlock()
+	if len(q.recent) == debugRecentMessages {
+		copy(q.recent, q.recent[1:])
+		q.recent = q.recent[:len(q.recent)-1]
+	}
+	q.recent = append(q.recent, entry)
+	if result, ok := msg.(*types.ResultMessage); ok {
+		q.debugSessionID = result.SessionID
+		if result.TotalCostUSD != nil {
+			q.debugCostUSD = *result.TotalCostUSD
+		}
+	}
+}
+
+// debugSummary summarizes a message: its text and tool uses, or the outcome
+// of a result.
+func debugSummary(msg types.Message) string {
+	var parts []string
+	switch m := msg.(type) {
+	case *types.ResultMessage:
+		parts = append(parts, fmt.Sprintf("%s after %d turns", m.Subtype, m.NumTurns))
+		if m.TotalCostUSD != nil {
+			parts = append(parts, fmt.Sprintf("$%.4f", *m.TotalCostUSD))
+		}
+	case *types.SystemMessage:
+		parts = append(parts, m.Subtype)
+	default:
+		for _, use := range types.ToolUses(msg) {
+			parts = append(parts, "tool_use "+use.Name)
+		}
+		for _, result := range types.ToolResults(msg) {
+			parts = append(parts, "tool_result "+result.ToolUseID)
+		}
+		if text := strings.TrimSpace(types.TextOf(msg)); text != "" {
+			parts = append(parts, text)
+		}
+	}
+
+	summary := []rune(strings.Join(parts, "; "))
+	if len(summary) > debugSummaryLength {
+		return string(summary[:debugSummaryLength]) + "…"
+	}
+	return string(summary)
+}
@@ -1,43 +1,180 @@
 package log
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
+	"sync"
 )
 
 // Logger provides simple logging for the SDK.
-// It writes to stderr with an [SDK] prefix to distinguish from CLI output.
+// It writes to stderr with an [SDK] prefix to distinguish from CLI output,
+// or to a *slog.Logger given with NewSlogLogger.
 type Logger struct {
 	verbose bool
+	slog    *slog.Logger  // Receives every message when set, filtered by its handler
+	attrs   []interface{} // Fields added with With, as slog key-value pairs
+	session *sessionState // Shared by the loggers derived with With
+}
+
+// sessionState holds the session ID logged with every message once known,
+// the correlation ID of the running query, and the function redacting
+// messages and string fields.
+type sessionState struct {
+	mu            sync.Mutex
+	id            string
+	correlationID string
+	redact        func(string) string
 }
 
 // NewLogger creates a new logger instance.
 func NewLogger(verbose bool) *Logger {
 	return &Logger{
 		verbose: verbose,
+		session: &sessionState{},
+	}
+}
+
+// NewSlogLogger creates a logger writing to l, whose handler decides which
+// levels are logged. A nil l uses slog.Default().
+func NewSlogLogger(l *slog.Logger) *Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &Logger{
+		slog:    l,
+		session: &sessionState{},
+	}
+}
+
+// With returns a logger adding fields, given as slog key-value pairs such
+// as "request_id", id, to every message.
+func (l *Logger) With(args ...interface{}) *Logger {
+	derived := *l
+	derived.attrs = append(append([]interface{}(nil), l.attrs...), args...)
+	return &derived
+}
+
+// SetSessionID adds the session_id field to the messages of this logger and
+// of every logger derived from the same NewLogger or NewSlogLogger. An empty
+// sessionID is ignored.
+func (l *Logger) SetSessionID(sessionID string) {
+	if l.session == nil || sessionID == "" {
+		return
+	}
+	l.session.mu.Lock()
+	defer l.session.mu.Unlock()
+	l.session.id = sessionID
+}
+
+// SetCorrelationID adds the correlation_id field to the messages of this
+// logger and of every logger derived from the same NewLogger or
+// NewSlogLogger, until it is set again. An empty correlationID removes the
+// field.
+func (l *Logger) SetCorrelationID(correlationID string) {
+	if l.session == nil {
+		return
+	}
+	l.session.mu.Lock()
+	defer l.session.mu.Unlock()
+	l.session.correlationID = correlationID
+}
+
+// SetRedactor makes this logger and every logger derived from the same
+// NewLogger or NewSlogLogger pass messages, string fields and error fields
+// through redact before writing them. A nil redact disables redaction.
+func (l *Logger) SetRedactor(redact func(string) string) {
+	if l.session == nil {
+		return
 	}
+	l.session.mu.Lock()
+	defer l.session.mu.Unlock()
+	l.session.redact = redact
+}
+
+// CorrelationID returns the correlation ID set with SetCorrelationID.
+func (l *Logger) CorrelationID() string {
+	if l.session == nil {
+		return ""
+	}
+	l.session.mu.Lock()
+	defer l.session.mu.Unlock()
+	return l.session.correlationID
 }
 
 // Debug logs a debug message (only when verbose mode is enabled).
 func (l *Logger) Debug(format string, args ...interface{}) {
-	if l.verbose {
-		fmt.Fprintf(os.Stderr, "[SDK DEBUG] "+format+"\n", args...)
-	}
+	l.log(slog.LevelDebug, "[SDK DEBUG] ", format, args...)
 }
 
 // Info logs an informational message (only when verbose mode is enabled).
 func (l *Logger) Info(format string, args ...interface{}) {
-	if l.verbose {
-		fmt.Fprintf(os.Stderr, "[SDK INFO] "+format+"\n", args...)
-	}
+	l.log(slog.LevelInfo, "[SDK INFO] ", format, args...)
 }
 
 // Warning logs a warning message (always displayed).
 func (l *Logger) Warning(format string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, "[SDK WARNING] "+format+"\n", args...)
+	l.log(slog.LevelWarn, "[SDK WARNING] ", format, args...)
 }
 
 // Error logs an error message (always displayed).
 func (l *Logger) Error(format string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, "[SDK ERROR] "+format+"\n", args...)
+	l.log(slog.LevelError, "[SDK ERROR] ", format, args...)
+}
+
+// log writes a message at a level, with the logger's fields.
+func (l *Logger) log(level slog.Level, prefix, format string, args ...interface{}) {
+	if l.slog == nil && level < slog.LevelWarn && !l.verbose {
+		return
+	}
+	ctx := context.Background()
+	if l.slog != nil && !l.slog.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := l.attrs
+	var redact func(string) string
+	if l.session != nil {
+		l.session.mu.Lock()
+		if l.session.correlationID != "" {
+			attrs = append([]interface{}{"correlation_id", l.session.correlationID}, attrs...)
+		}
+		if l.session.id != "" {
+			attrs = append([]interface{}{"session_id", l.session.id}, attrs...)
+		}
+		redact = l.session.redact
+		l.session.mu.Unlock()
+	}
+
+	message := fmt.Sprintf(format, args...)
+	if redact != nil {
+		message = redact(message)
+		attrs = redactAttrs(attrs, redact)
+	}
+	if l.slog != nil {
+		l.slog.Log(ctx, level, message, attrs...)
+		return
+	}
+	var fields strings.Builder
+	for i := 0; i+1 < len(attrs); i += 2 {
+		fmt.Fprintf(&fields, " %v=%v", attrs[i], attrs[i+1])
+	}
+	fmt.Fprintf(os.Stderr, "%s%s%s\n", prefix, message, fields.String())
+}
+
+// redactAttrs returns a copy of the slog key-value pairs attrs with string
+// and error values passed through redact.
+func redactAttrs(attrs []interface{}, redact func(string) string) []interface{} {
+	redacted := append([]interface{}(nil), attrs...)
+	for i := 1; i < len(redacted); i += 2 {
+		switch v := redacted[i].(type) {
+		case string:
+			redacted[i] = redact(v)
+		case error:
+			redacted[i] = redact(v.Error())
+		}
+	}
+	return redacted
 }
@@ -0,0 +1,78 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// TestSlogLogger tests that messages reach a slog.Logger with their level and fields
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	logger.Debug("dropped by the handler")
+	logger.SetSessionID("s1")
+	logger.SetCorrelationID("c1")
+	logger.With("request_id", "r1").With("tool", "Bash").Warning("tool %s failed", "Bash")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("logged %d records, want 1:\n%s", len(lines), buf.String())
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("invalid record: %v", err)
+	}
+	for key, want := range map[string]string{
+		"level": "WARN", "msg": "tool Bash failed", "session_id": "s1", "correlation_id": "c1", "request_id": "r1", "tool": "Bash",
+	} {
+		if record[key] != want {
+			t.Errorf("record[%q] = %v, want %q", key, record[key], want)
+		}
+	}
+}
+
+// TestLoggerWith tests that With leaves the original logger's fields unchanged
+func TestLoggerWith(t *testing.T) {
+	base := NewLogger(false).With("a", 1)
+	derived := base.With("b", 2)
+	if len(base.attrs) != 2 || len(derived.attrs) != 4 {
+		t.Errorf("attrs = %v and %v, want 2 and 4 values", base.attrs, derived.attrs)
+	}
+	derived.SetSessionID("s1")
+	if base.session.id != "s1" {
+		t.Error("session ID not shared with the logger derived from")
+	}
+	derived.SetCorrelationID("c1")
+	if base.CorrelationID() != "c1" {
+		t.Error("correlation ID not shared with the logger derived from")
+	}
+}
+
+// TestLoggerRedactor tests that messages and string and error fields are redacted
+func TestLoggerRedactor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	logger.SetRedactor(func(s string) string { return strings.ReplaceAll(s, "sk-secret", "[REDACTED]") })
+
+	logger.With("env", "KEY=sk-secret", "err", errors.New("bad key sk-secret"), "n", 1).Warning("using sk-secret")
+
+	if strings.Contains(buf.String(), "sk-secret") {
+		t.Fatalf("secret logged: %s", buf.String())
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid record: %v", err)
+	}
+	for key, want := range map[string]interface{}{
+		"msg": "using [REDACTED]", "env": "KEY=[REDACTED]", "err": "bad key [REDACTED]", "n": float64(1),
+	} {
+		if record[key] != want {
+			t.Errorf("record[%q] = %v, want %v", key, record[key], want)
+		}
+	}
+}
@@ -4,9 +4,12 @@ package mcp
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/M1n9X/claude-agent-sdk-go/types"
 )
@@ -18,17 +21,35 @@ type SdkMCPServer struct {
 	version  string
 	tools    []types.McpTool
 	toolsMap map[string]types.McpTool // name -> tool for fast lookup
-	mu       sync.RWMutex             // protects tools and toolsMap
+
+	middleware []types.ToolMiddleware // wraps every tool call
+
+	resources    []types.McpResource
+	resourcesMap map[string]types.McpResource // uri -> resource
+
+	notificationHandlers map[int]func(map[string]interface{}) // receive list_changed notifications
+	nextHandlerID        int
+
+	stats     *types.ToolStatsRecorder // aggregates every tool call
+	observers []types.ToolCallObserver // receive every tool call
+
+	mu sync.RWMutex // protects tools, toolsMap, progress, middleware, resources, notificationHandlers and observers
 }
 
+// ErrorCodeResourceNotFound is the MCP error code for an unknown resource URI.
+const ErrorCodeResourceNotFound = -32002
+
 // NewSdkMCPServer creates a new SDK MCP server instance.
 // The name and version identify the server, and tools are the initial set of tools.
 func NewSdkMCPServer(name, version string, tools []types.McpTool) *SdkMCPServer {
 	server := &SdkMCPServer{
-		name:     name,
-		version:  version,
-		tools:    tools,
-		toolsMap: make(map[string]types.McpTool),
+		name:                 name,
+		version:              version,
+		tools:                append([]types.McpTool(nil), tools...),
+		toolsMap:             make(map[string]types.McpTool),
+		resourcesMap:         make(map[string]types.McpResource),
+		notificationHandlers: make(map[int]func(map[string]interface{})),
+		stats:                types.NewToolStatsRecorder(),
 	}
 
 	// Index tools by name for fast lookup
@@ -54,32 +75,34 @@ func (s *SdkMCPServer) Tools() []types.McpTool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return s.tools
+	return append([]types.McpTool(nil), s.tools...)
 }
 
-// AddTool adds a new tool to the server.
+// AddTool adds a new tool to the server and notifies clients that the tool
+// list changed.
 // Returns an error if a tool with the same name already exists.
 func (s *SdkMCPServer) AddTool(tool types.McpTool) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	if _, exists := s.toolsMap[tool.Name()]; exists {
+		s.mu.Unlock()
 		return fmt.Errorf("tool already exists: %s", tool.Name())
 	}
 
 	s.tools = append(s.tools, tool)
 	s.toolsMap[tool.Name()] = tool
+	s.mu.Unlock()
 
+	s.notify("notifications/tools/list_changed")
 	return nil
 }
 
-// RemoveTool removes a tool from the server.
+// RemoveTool removes a tool from the server and notifies clients that the
+// tool list changed. Calls already running finish normally.
 // Returns an error if the tool doesn't exist.
 func (s *SdkMCPServer) RemoveTool(name string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	if _, exists := s.toolsMap[name]; !exists {
+		s.mu.Unlock()
 		return fmt.Errorf("tool not found: %s", name)
 	}
 
@@ -89,17 +112,173 @@ func (s *SdkMCPServer) RemoveTool(name string) error {
 	// Remove from slice
 	for i, t := range s.tools {
 		if t.Name() == name {
-			s.tools = append(s.tools[:i], s.tools[i+1:]...)
+			s.tools = append(s.tools[:i:i], s.tools[i+1:]...)
 			break
 		}
 	}
+	s.mu.Unlock()
 
+	s.notify("notifications/tools/list_changed")
 	return nil
 }
 
+// AddNotificationHandler registers handler to receive the notifications the
+// server sends when its tool or resource list changes, and returns a
+// function that unregisters it. Each client connection registers its own
+// handler, so a server shared by several sessions notifies all of them.
+func (s *SdkMCPServer) AddNotificationHandler(handler func(notification map[string]interface{})) (remove func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextHandlerID
+	s.nextHandlerID++
+	s.notificationHandlers[id] = handler
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.notificationHandlers, id)
+	}
+}
+
+// notify sends a notification without params to every notification handler.
+func (s *SdkMCPServer) notify(method string) {
+	s.mu.RLock()
+	handlers := make([]func(map[string]interface{}), 0, len(s.notificationHandlers))
+	for _, handler := range s.notificationHandlers {
+		handlers = append(handlers, handler)
+	}
+	s.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  method,
+		})
+	}
+}
+
+// Resources returns all registered resources.
+func (s *SdkMCPServer) Resources() []types.McpResource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]types.McpResource(nil), s.resources...)
+}
+
+// AddResource adds a resource to the server and notifies clients that the
+// resource list changed.
+// Returns an error if the resource is invalid or its URI already exists.
+func (s *SdkMCPServer) AddResource(resource types.McpResource) error {
+	if err := resource.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if _, exists := s.resourcesMap[resource.URI]; exists {
+		s.mu.Unlock()
+		return fmt.Errorf("resource already exists: %s", resource.URI)
+	}
+
+	s.resources = append(s.resources, resource)
+	s.resourcesMap[resource.URI] = resource
+	s.mu.Unlock()
+
+	s.notify("notifications/resources/list_changed")
+	return nil
+}
+
+// RemoveResource removes a resource from the server and notifies clients
+// that the resource list changed.
+// Returns an error if the resource doesn't exist.
+func (s *SdkMCPServer) RemoveResource(uri string) error {
+	s.mu.Lock()
+	if _, exists := s.resourcesMap[uri]; !exists {
+		s.mu.Unlock()
+		return fmt.Errorf("resource not found: %s", uri)
+	}
+
+	delete(s.resourcesMap, uri)
+	for i, r := range s.resources {
+		if r.URI == uri {
+			s.resources = append(s.resources[:i:i], s.resources[i+1:]...)
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	s.notify("notifications/resources/list_changed")
+	return nil
+}
+
+// progressHandlerKey is the context key of a per-request progress handler.
+type progressHandlerKey struct{}
+
+// ContextWithProgressHandler returns a context whose tool calls report
+// progress to handler. The handler is per request rather than per server,
+// since one server can be shared by several clients and each must receive
+// the progress of its own calls.
+func ContextWithProgressHandler(ctx context.Context, handler func(types.ToolProgress)) context.Context {
+	return context.WithValue(ctx, progressHandlerKey{}, handler)
+}
+
+// panicHandlerKey is the context key of the handler for tool panics.
+type panicHandlerKey struct{}
+
+// ContextWithPanicHandler returns a context whose tool calls report
+// recovered handler panics to handler.
+func ContextWithPanicHandler(ctx context.Context, handler types.ToolPanicFunc) context.Context {
+	return context.WithValue(ctx, panicHandlerKey{}, handler)
+}
+
+// Use adds middleware that wraps every tool call. The first middleware
+// added is the outermost.
+func (s *SdkMCPServer) Use(middleware ...types.ToolMiddleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middleware = append(s.middleware, middleware...)
+}
+
+// Stats returns the call statistics of the server's tools, sorted by tool name.
+func (s *SdkMCPServer) Stats() []types.ToolStats {
+	return s.stats.Stats()
+}
+
+// ResetStats clears the call statistics.
+func (s *SdkMCPServer) ResetStats() {
+	s.stats.Reset()
+}
+
+// OnToolCall registers observer to receive a record of each completed tool call.
+func (s *SdkMCPServer) OnToolCall(observer types.ToolCallObserver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observers = append(s.observers, observer)
+}
+
+// recordCall adds a completed tool call to the statistics and passes it to
+// the observers.
+func (s *SdkMCPServer) recordCall(record types.ToolCallRecord) {
+	s.stats.Record(record)
+
+	s.mu.RLock()
+	observers := s.observers
+	s.mu.RUnlock()
+	for _, observer := range observers {
+		if observer != nil {
+			observer(record)
+		}
+	}
+}
+
 // HandleMessage processes an MCP JSON-RPC message and returns a response.
 // This is the main entry point for handling MCP protocol messages.
 func (s *SdkMCPServer) HandleMessage(msg map[string]interface{}) (map[string]interface{}, error) {
+	return s.HandleMessageContext(context.Background(), msg)
+}
+
+// HandleMessageContext is like HandleMessage, but tool handlers and resource
+// readers receive ctx, so canceling it stops the call.
+func (s *SdkMCPServer) HandleMessageContext(ctx context.Context, msg map[string]interface{}) (map[string]interface{}, error) {
 	method, ok := msg["method"].(string)
 	if !ok {
 		return nil, fmt.Errorf("missing or invalid method field")
@@ -111,7 +290,11 @@ func (s *SdkMCPServer) HandleMessage(msg map[string]interface{}) (map[string]int
 	case "tools/list":
 		return s.handleToolsList(msg)
 	case "tools/call":
-		return s.handleToolsCall(msg)
+		return s.handleToolsCall(ctx, msg)
+	case "resources/list":
+		return s.handleResourcesList(msg)
+	case "resources/read":
+		return s.handleResourcesRead(ctx, msg)
 	default:
 		id := msg["id"]
 		resp := NewErrorResponse(id, ErrorCodeMethodNotFound, fmt.Sprintf("method not found: %s", method))
@@ -127,8 +310,12 @@ func (s *SdkMCPServer) handleInitialize(msg map[string]interface{}) (map[string]
 		"protocolVersion": "0.1.0",
 		"capabilities": map[string]interface{}{
 			"tools": map[string]interface{}{
-				"listChanged": false,
+				"listChanged": true,
+			},
+			"resources": map[string]interface{}{
+				"listChanged": true,
 			},
+			"logging": map[string]interface{}{},
 		},
 		"serverInfo": map[string]interface{}{
 			"name":    s.name,
@@ -164,7 +351,7 @@ func (s *SdkMCPServer) handleToolsList(msg map[string]interface{}) (map[string]i
 }
 
 // handleToolsCall handles a tools/call request.
-func (s *SdkMCPServer) handleToolsCall(msg map[string]interface{}) (map[string]interface{}, error) {
+func (s *SdkMCPServer) handleToolsCall(ctx context.Context, msg map[string]interface{}) (map[string]interface{}, error) {
 	id := msg["id"]
 
 	params, ok := msg["params"].(map[string]interface{})
@@ -179,7 +366,11 @@ func (s *SdkMCPServer) handleToolsCall(msg map[string]interface{}) (map[string]i
 		return responseToMap(errResp), nil
 	}
 
+	s.mu.RLock()
 	tool, exists := s.toolsMap[name]
+	middleware := s.middleware
+	s.mu.RUnlock()
+	progress, _ := ctx.Value(progressHandlerKey{}).(func(types.ToolProgress))
 	if !exists {
 		errResp := NewErrorResponse(id, ErrorCodeMethodNotFound, fmt.Sprintf("tool not found: %s", name))
 		return responseToMap(errResp), nil
@@ -191,9 +382,48 @@ func (s *SdkMCPServer) handleToolsCall(msg map[string]interface{}) (map[string]i
 		return responseToMap(errResp), nil
 	}
 
-	// Execute the tool
-	ctx := context.Background()
-	result, err := tool.Execute(ctx, input)
+	// Execute the tool through the middleware, passing its progress to the handler
+	ctx = types.ContextWithToolCall(ctx, types.ToolCallInfo{Server: s.name, Tool: name})
+	if progress != nil {
+		var token interface{}
+		if meta, ok := params["_meta"].(map[string]interface{}); ok {
+			token = meta["progressToken"]
+		}
+		ctx = types.ContextWithToolProgress(ctx, func(p types.ToolProgress) {
+			p.Server = s.name
+			p.Tool = name
+			p.Token = token
+			progress(p)
+		})
+	}
+	execute := types.ToolFunc(tool.Execute)
+	if len(middleware) > 0 {
+		execute = types.ChainToolMiddleware(middleware...)(execute)
+	}
+	start := time.Now()
+	result, err := executeTool(ctx, name, execute, input)
+	s.recordCall(types.ToolCallRecord{
+		Time:     start,
+		Server:   s.name,
+		Tool:     name,
+		Duration: time.Since(start),
+		Err:      err,
+		IsError:  result != nil && result.IsError,
+	})
+	var panicErr *types.ToolPanicError
+	if errors.As(err, &panicErr) {
+		if handler, ok := ctx.Value(panicHandlerKey{}).(types.ToolPanicFunc); ok && handler != nil {
+			handler(types.ToolPanic{
+				Time:   time.Now(),
+				Server: s.name,
+				Tool:   name,
+				Value:  panicErr.Value,
+				Stack:  panicErr.Stack,
+			})
+		}
+		resp := NewSuccessResponse(id, types.ToolPanicResult(name))
+		return responseToMap(resp), nil
+	}
 	if err != nil {
 		errResp := NewErrorResponse(id, ErrorCodeInternalError, fmt.Sprintf("tool execution failed: %v", err))
 		return responseToMap(errResp), nil
@@ -203,6 +433,147 @@ func (s *SdkMCPServer) handleToolsCall(msg map[string]interface{}) (map[string]i
 	return responseToMap(resp), nil
 }
 
+// handleResourcesList handles the resources/list request.
+func (s *SdkMCPServer) handleResourcesList(msg map[string]interface{}) (map[string]interface{}, error) {
+	id := msg["id"]
+
+	resources := s.Resources()
+	resourceList := make([]map[string]interface{}, len(resources))
+
+	for i, resource := range resources {
+		entry := map[string]interface{}{
+			"uri":  resource.URI,
+			"name": resource.Name,
+		}
+		if resource.Description != "" {
+			entry["description"] = resource.Description
+		}
+		if resource.MimeType != "" {
+			entry["mimeType"] = resource.MimeType
+		}
+		resourceList[i] = entry
+	}
+
+	result := map[string]interface{}{
+		"resources": resourceList,
+	}
+
+	resp := NewSuccessResponse(id, result)
+	return responseToMap(resp), nil
+}
+
+// handleResourcesRead handles a resources/read request.
+func (s *SdkMCPServer) handleResourcesRead(ctx context.Context, msg map[string]interface{}) (map[string]interface{}, error) {
+	id := msg["id"]
+
+	params, ok := msg["params"].(map[string]interface{})
+	if !ok {
+		errResp := NewErrorResponse(id, ErrorCodeInvalidParams, "missing or invalid params")
+		return responseToMap(errResp), nil
+	}
+
+	uri, ok := params["uri"].(string)
+	if !ok {
+		errResp := NewErrorResponse(id, ErrorCodeInvalidParams, "missing or invalid resource uri")
+		return responseToMap(errResp), nil
+	}
+
+	s.mu.RLock()
+	resource, exists := s.resourcesMap[uri]
+	s.mu.RUnlock()
+	if !exists {
+		errResp := NewErrorResponse(id, ErrorCodeResourceNotFound, fmt.Sprintf("resource not found: %s", uri))
+		return responseToMap(errResp), nil
+	}
+
+	data, err := resource.Read(ctx, uri)
+	if err != nil {
+		errResp := NewErrorResponse(id, ErrorCodeInternalError, fmt.Sprintf("resource read failed: %v", err))
+		return responseToMap(errResp), nil
+	}
+
+	content := map[string]interface{}{"uri": uri}
+	if resource.MimeType != "" {
+		content["mimeType"] = resource.MimeType
+	}
+	if resource.IsText() {
+		content["text"] = string(data)
+	} else {
+		content["blob"] = base64.StdEncoding.EncodeToString(data)
+	}
+
+	result := map[string]interface{}{
+		"contents": []interface{}{content},
+	}
+
+	resp := NewSuccessResponse(id, result)
+	return responseToMap(resp), nil
+}
+
+// executeTool runs execute, returning early with the context's error when
+// ctx is canceled so a handler that ignores cancellation cannot block the
+// session. A panic is returned as a *types.ToolPanicError.
+func executeTool(ctx context.Context, name string, execute types.ToolFunc, input map[string]interface{}) (*types.ToolResult, error) {
+	type outcome struct {
+		result *types.ToolResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{nil, types.NewToolPanicError(name, r)}
+			}
+		}()
+		result, err := execute(ctx, input)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.result, out.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("tool call canceled: %w", ctx.Err())
+	}
+}
+
+// ProgressNotification returns the JSON-RPC notification for a tool progress
+// update, or nil when there is nothing to send. Progress is sent as
+// notifications/progress, which requires the progress token of the call;
+// partial content is sent as a notifications/message log entry.
+func ProgressNotification(p types.ToolProgress) map[string]interface{} {
+	if len(p.Content) > 0 {
+		return map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "notifications/message",
+			"params": map[string]interface{}{
+				"level":  "info",
+				"logger": p.Tool,
+				"data":   map[string]interface{}{"content": p.Content},
+			},
+		}
+	}
+	if p.Token == nil {
+		return nil
+	}
+
+	params := map[string]interface{}{
+		"progressToken": p.Token,
+		"progress":      p.Progress,
+	}
+	if p.Total > 0 {
+		params["total"] = p.Total
+	}
+	if p.Message != "" {
+		params["message"] = p.Message
+	}
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "notifications/progress",
+		"params":  params,
+	}
+}
+
 // responseToMap converts a Response to a map for JSON serialization.
 func responseToMap(resp *Response) map[string]interface{} {
 	result := map[string]interface{}{
@@ -243,6 +614,40 @@ func responseToMap(resp *Response) map[string]interface{} {
 	return result
 }
 
+// ServerFromConfig converts a ToolServerConfig into a concrete MCP server
+// implementation. A config holding tools is replaced by the server built from
+// them, so later calls return the same server.
+func ServerFromConfig(config *types.ToolServerConfig) (types.MCPServer, error) {
+	if config == nil {
+		return nil, fmt.Errorf("nil SDK MCP server configuration")
+	}
+
+	switch instance := config.Instance.(type) {
+	case nil:
+		return nil, fmt.Errorf("SDK MCP server %s has no instance", config.Name)
+	case types.MCPServer:
+		return instance, nil
+	case []types.McpTool:
+		server := NewSdkMCPServer(config.Name, config.Version, instance)
+		server.Use(config.Middleware...)
+		for _, observer := range config.Observers {
+			server.OnToolCall(observer)
+		}
+		for _, resource := range config.Resources {
+			if err := server.AddResource(resource); err != nil {
+				return nil, fmt.Errorf("SDK MCP server %s: %w", config.Name, err)
+			}
+		}
+		config.Instance = server
+		return server, nil
+	default:
+		if server, ok := instance.(types.MCPServer); ok {
+			return server, nil
+		}
+		return nil, fmt.Errorf("unsupported SDK MCP server instance type %T", instance)
+	}
+}
+
 // ToolServerConfig represents SDK MCP server configuration.
 // This is used to configure an in-process MCP server in ClaudeAgentOptions.
 type ToolServerConfig struct {
@@ -0,0 +1,84 @@
+// Package pathutil checks whether paths lie beneath a root directory, for
+// the SDK's guards on file writes.
+package pathutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Roots returns the absolute form of root and, if different, its
+// symlink-resolved form.
+func Roots(root string) []string {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	roots := []string{filepath.Clean(root)}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil && resolved != roots[0] {
+		roots = append(roots, resolved)
+	}
+	return roots
+}
+
+// Join returns path if it is absolute and path joined to dir otherwise.
+// Unlike filepath.Join it does not clean the result, since "link/.." names
+// the parent of the link's target, not dir.
+func Join(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return strings.TrimSuffix(dir, string(filepath.Separator)) + string(filepath.Separator) + path
+}
+
+// Within reports whether the absolute path lies beneath any of roots, as
+// returned by Roots. The symlinks of the part of path that exists are
+// resolved first, along with the ".." elements following them, so a link
+// inside a root that points elsewhere does not count as inside, nor does a
+// path whose symlinks cannot be resolved, such as a dangling link. Build
+// relative paths with Join rather than filepath.Join, which cleans them.
+func Within(roots []string, path string) bool {
+	resolved, err := Resolve(path)
+	if err != nil {
+		return false
+	}
+	for _, root := range roots {
+		if within(root, resolved) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the absolute path with the symlinks of its longest
+// existing prefix resolved, so that paths of files yet to be created resolve
+// through the directories they will be created in.
+func Resolve(path string) (string, error) {
+	existing, rest := path, ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		// Not filepath.Dir, which cleans "link/.." away
+		i := strings.LastIndexByte(strings.TrimRight(existing, string(filepath.Separator)), filepath.Separator)
+		if i <= 0 {
+			return filepath.Clean(path), nil
+		}
+		rest = filepath.Join(existing[i+1:], rest)
+		existing = existing[:i]
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolved, rest), nil
+}
+
+// within reports whether path is root or lies beneath it.
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/M1n9X/claude-agent-sdk-go/internal/log"
 	"github.com/M1n9X/claude-agent-sdk-go/internal/mcp"
@@ -29,12 +30,61 @@ type Query struct {
 	requestMap         map[string]chan responseResult
 	nextRequestID      int64
 	hookCallbacks      map[string]types.HookCallbackFunc
+	asyncHookTimeouts  map[string]time.Duration
+	hookCallbackInfo   map[string]hookCallbackInfo
 	nextHookCallbackID int64
 
 	// Callbacks
-	canUseTool types.CanUseToolFunc
-	hooks      map[types.HookEvent][]types.HookMatcher
-	mcpServers map[string]types.MCPServer
+	canUseTool      types.CanUseToolFunc
+	hooks           map[types.HookEvent][]types.HookMatcher
+	hookMetrics     types.HookMetricsFunc
+	hookExecution   map[types.HookEvent]types.HookExecutionMode
+	permissionAudit types.PermissionAuditSink
+	mcpServers      map[string]types.MCPServer
+	mcpUnsubscribe  []func() // unregisters the SDK MCP server notification handlers
+	toolProgress    types.ToolProgressFunc
+	toolPanic       types.ToolPanicFunc
+	caller          interface{}                  // identity passed to SDK MCP tool handlers
+	toolCalls       map[int64]context.CancelFunc // cancels in-flight SDK MCP requests
+	nextToolCallID  int64
+	agents          *agentTracker
+	redactor        *types.Redactor
+	permissionStats *types.PermissionStatsRecorder
+	statsInUsage    bool
+	usage           *types.UsageRecorder
+	retryPolicy     types.RetryPolicy
+	errorsInARow    int // Assistant errors since the last successful assistant message
+	outputSchema    map[string]interface{}
+	outputRetries   int // Fix turns allowed per query for structured output
+	outputAttempts  int // Fix turns sent for the current query
+	metrics         types.Metrics
+	lastCostUSD     float64      // Session cost reported by the latest result
+	budget          types.Budget // Charged the cost of each query
+	tokenAlerts     []types.TokenAlert
+	tokenAlerted    []bool // Whether each token alert was called
+	lifecycle       types.LifecycleListener
+
+	// Tracing
+	tracer    types.Tracer
+	spanMu    sync.Mutex
+	turnSpans []types.Span          // Spans of the queries awaiting their result, oldest first
+	toolSpans map[string]types.Span // Spans of the tool uses awaiting their result, by tool use ID
+
+	// Latency breakdown
+	timingMu      sync.Mutex
+	timings       []*queryTiming // Timings of the queries awaiting their result, oldest first
+	spawnReported bool           // The CLI's spawn time was set on a result
+
+	// Correlation IDs of the queries awaiting their result, oldest first
+	correlationMu  sync.Mutex
+	correlationIDs []string
+
+	// Debug state, see DebugState
+	debugMu        sync.Mutex
+	recent         []types.DebugMessage // The latest messages, oldest first
+	debugSessionID string
+	debugCostUSD   float64
+	redact         func(string) string // Redacts credentials from summaries
 
 	// Message handling
 	messagesChan     chan types.Message
@@ -46,6 +96,12 @@ type Query struct {
 	isStreamingMode  bool
 }
 
+// hookCallbackInfo records where a hook callback was registered, for metrics.
+type hookCallbackInfo struct {
+	event   types.HookEvent
+	matcher *string
+}
+
 // responseResult wraps the response or error from a control request.
 type responseResult struct {
 	response map[string]interface{}
@@ -63,27 +119,121 @@ func NewQuery(ctx context.Context, transport transport.Transport, opts *types.Cl
 	}
 
 	q := &Query{
-		transport:       transport,
-		ctx:             queryCtx,
-		cancel:          cancel,
-		logger:          logger,
-		requestMap:      make(map[string]chan responseResult),
-		hookCallbacks:   make(map[string]types.HookCallbackFunc),
-		messagesChan:    make(chan types.Message, capacity),
-		stopChan:        make(chan struct{}),
-		readLoopDone:    make(chan struct{}),
-		isStreamingMode: isStreamingMode,
-		mcpServers:      make(map[string]types.MCPServer),
+		transport:         transport,
+		ctx:               queryCtx,
+		cancel:            cancel,
+		logger:            logger,
+		requestMap:        make(map[string]chan responseResult),
+		hookCallbacks:     make(map[string]types.HookCallbackFunc),
+		asyncHookTimeouts: make(map[string]time.Duration),
+		hookCallbackInfo:  make(map[string]hookCallbackInfo),
+		messagesChan:      make(chan types.Message, capacity),
+		stopChan:          make(chan struct{}),
+		readLoopDone:      make(chan struct{}),
+		isStreamingMode:   isStreamingMode,
+		mcpServers:        make(map[string]types.MCPServer),
+		toolCalls:         make(map[int64]context.CancelFunc),
+		agents:            newAgentTracker(),
+		permissionStats:   types.NewPermissionStatsRecorder(),
+		usage:             types.NewUsageRecorder(),
+		retryPolicy:       types.DefaultRetryPolicy,
+		metrics:           types.NopMetrics{},
+		tracer:            types.NopTracer{},
+		toolSpans:         make(map[string]types.Span),
 	}
 
 	if opts != nil {
 		q.canUseTool = opts.CanUseTool
+		if opts.OnPlanReady != nil {
+			q.canUseTool = types.PlanModeCanUseTool(opts.OnPlanReady, opts.PlanExecution, opts.CanUseTool)
+		}
 		q.hooks = opts.Hooks
+		if opts.DryRun != nil {
+			q.hooks = withPreToolHook(q.hooks, opts.DryRun.Hook())
+		}
+		if opts.ToolRateLimiter != nil {
+			q.hooks = withPreToolHook(q.hooks, opts.ToolRateLimiter.Hook())
+		}
+		if len(opts.AgentPermissions) > 0 {
+			q.canUseTool = types.AgentScopedCanUseTool(opts.AgentPermissions, q.canUseTool)
+			q.hooks = withPreToolHook(q.hooks, q.agentPermissionsHook(opts.AgentPermissions))
+		}
+		q.hookMetrics = opts.HookMetrics
+		q.hookExecution = opts.HookExecution
+		q.permissionAudit = opts.PermissionAudit
+		q.redactor = opts.Redactor
+		q.statsInUsage = opts.PermissionStatsInUsage
+		if opts.RetryPolicy != nil {
+			q.retryPolicy = *opts.RetryPolicy
+		}
+		q.outputSchema = opts.OutputSchema()
+		q.outputRetries = opts.StructuredOutputRetries
+		q.toolProgress = opts.ToolProgress
+		q.toolPanic = opts.ToolPanic
+		q.caller = opts.Caller
+		if opts.Metrics != nil {
+			q.metrics = opts.Metrics
+		}
+		q.budget = opts.Budget
+		q.tokenAlerts = opts.TokenAlerts
+		q.tokenAlerted = make([]bool, len(opts.TokenAlerts))
+		q.lifecycle = opts.LifecycleListener
+		q.redact = opts.RedactCredentials
+		if opts.Tracer != nil {
+			q.tracer = opts.Tracer
+		}
 	}
 
 	return q
 }
 
+// withPreToolHook returns a copy of hooks with matcher added to the
+// PreToolUse hooks, leaving the caller's map unchanged.
+func withPreToolHook(hooks map[types.HookEvent][]types.HookMatcher, matcher types.HookMatcher) map[types.HookEvent][]types.HookMatcher {
+	combined := make(map[types.HookEvent][]types.HookMatcher, len(hooks)+1)
+	for event, matchers := range hooks {
+		combined[event] = matchers
+	}
+	preToolUse := hooks[types.HookEventPreToolUse]
+	combined[types.HookEventPreToolUse] = append(preToolUse[:len(preToolUse):len(preToolUse)], matcher)
+	return combined
+}
+
+// agentPermissionsHook returns a PreToolUse hook matcher that denies the
+// tool uses a subagent's AgentPermissions do not allow, those of subagents
+// whose name is unknown and those that were not observed, which
+// agentTracker's ordering assumption rules out. Subagents without an entry
+// in agents are unrestricted.
+func (q *Query) agentPermissionsHook(agents map[string]types.AgentPermissions) types.HookMatcher {
+	return types.HookMatcher{
+		Hooks: []types.HookCallbackFunc{
+			func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+				raw, _ := input.(map[string]interface{})
+				toolName, _ := raw["tool_name"].(string)
+				toolInput, _ := raw["tool_input"].(map[string]interface{})
+
+				use, ok := q.agents.agentForToolUse(toolUseID)
+				switch {
+				case !ok:
+					return types.DenyToolUse(fmt.Sprintf("cannot tell which agent is using %s", toolName)), nil
+				case !use.subagent:
+					return map[string]interface{}{}, nil
+				case use.agent == "":
+					return types.DenyToolUse(fmt.Sprintf("cannot tell which subagent is using %s", toolName)), nil
+				}
+				permissions, ok := agents[use.agent]
+				if !ok {
+					return map[string]interface{}{}, nil
+				}
+				if reason := permissions.Check(use.agent, toolName, toolInput); reason != "" {
+					return types.DenyToolUse(reason), nil
+				}
+				return map[string]interface{}{}, nil
+			},
+		},
+	}
+}
+
 // Initialize sends initialization control request if in streaming mode.
 func (q *Query) Initialize(ctx context.Context) (map[string]interface{}, error) {
 	if !q.isStreamingMode {
@@ -104,22 +254,53 @@ func (q *Query) Initialize(ctx context.Context) (map[string]interface{}, error)
 				continue
 			}
 
+			mode := q.hookExecution[event]
+			if !mode.IsValid() {
+				return nil, fmt.Errorf("invalid hook execution mode %q for %s", mode, event)
+			}
+
 			eventHooks := make([]map[string]interface{}, 0, len(matchers))
+			var composed []types.HookCallbackFunc
 			for _, matcher := range matchers {
-				callbackIDs := make([]string, 0, len(matcher.Hooks))
-				for _, callback := range matcher.Hooks {
+				pattern, callbacks, err := resolveHookMatcher(matcher)
+				if err != nil {
+					return nil, err
+				}
+
+				// Synchronous hooks are combined into one callback and matched by the SDK
+				if mode != types.HookExecutionDefault && !matcher.Async {
+					for _, callback := range callbacks {
+						composed = append(composed, matchedHook(pattern, callback))
+					}
+					continue
+				}
+
+				callbackIDs := make([]string, 0, len(callbacks))
+				for _, callback := range callbacks {
 					callbackID := q.registerHookCallback(callback)
+					q.registerHookCallbackInfo(callbackID, event, matcher.Matcher)
+					if matcher.Async {
+						q.registerAsyncHook(callbackID, matcher.AsyncTimeout)
+					}
 					callbackIDs = append(callbackIDs, callbackID)
 				}
 
 				hookConfig := map[string]interface{}{
 					"hookCallbackIds": callbackIDs,
 				}
-				if matcher.Matcher != nil {
-					hookConfig["matcher"] = *matcher.Matcher
+				if pattern != nil {
+					hookConfig["matcher"] = *pattern
 				}
 				eventHooks = append(eventHooks, hookConfig)
 			}
+
+			if len(composed) > 0 {
+				callbackID := q.registerHookCallback(types.ComposeHooks(mode, composed))
+				q.registerHookCallbackInfo(callbackID, event, nil)
+				eventHooks = append(eventHooks, map[string]interface{}{
+					"hookCallbackIds": []string{callbackID},
+				})
+			}
 			hooksConfig[string(event)] = eventHooks
 		}
 	}
@@ -173,6 +354,16 @@ func (q *Query) Stop(ctx context.Context) error {
 
 	// Cancel context to stop all operations
 	q.cancel()
+	q.endSpans()
+
+	// SDK MCP servers may outlive the session; stop forwarding their notifications
+	q.mu.Lock()
+	unsubscribe := q.mcpUnsubscribe
+	q.mcpUnsubscribe = nil
+	q.mu.Unlock()
+	for _, remove := range unsubscribe {
+		remove()
+	}
 
 	// Wait for read loop to complete
 	select {
@@ -250,6 +441,95 @@ func (q *Query) routeMessage(msg types.Message) error {
 	}
 
 	// Regular message - send to consumer
+	switch m := msg.(type) {
+	case *types.ResultMessage:
+		q.logger.SetSessionID(m.SessionID)
+	case *types.StreamEvent:
+		q.logger.SetSessionID(m.SessionID)
+	case *types.SystemMessage:
+		if m.IsInit() {
+			sessionID, _ := m.Data["session_id"].(string)
+			q.lifecycle.Notify(&types.InitReceivedEvent{SessionID: sessionID, Data: m.Data, CorrelationID: q.CorrelationID()})
+		}
+	}
+	q.agents.observe(msg)
+	q.usage.Record(msg)
+	q.checkTokenAlerts(msg)
+	q.traceMessage(msg)
+	q.timeMessage(msg)
+	q.recordRecent(msg)
+	q.recordMetrics(msg)
+	if result, ok := msg.(*types.ResultMessage); ok && q.statsInUsage {
+		if result.Usage == nil {
+			result.Usage = make(map[string]interface{})
+		}
+		result.Usage[types.PermissionUsageKey] = q.permissionStats.Stats().UsageMap()
+	}
+	if result, ok := msg.(*types.ResultMessage); ok && q.retryStructuredOutput(result) {
+		return nil
+	}
+	if result, ok := msg.(*types.ResultMessage); ok {
+		result.CorrelationID = q.CorrelationID()
+		q.endCorrelation()
+	}
+	if err := q.deliver(msg); err != nil {
+		return err
+	}
+	if event := q.assistantError(msg); event != nil {
+		return q.deliver(event)
+	}
+	return nil
+}
+
+// recordMetrics reports the tool uses and results among messages to the
+// metrics, and charges the cost of results to the budget.
+func (q *Query) recordMetrics(msg types.Message) {
+	switch m := msg.(type) {
+	case *types.AssistantMessage:
+		for _, use := range types.ToolUses(m) {
+			q.metrics.ToolCalled(use.Name)
+		}
+	case *types.ResultMessage:
+		metrics := types.NewQueryMetrics(m, q.lastCostUSD)
+		metrics.CorrelationID = q.CorrelationID()
+		q.metrics.QueryCompleted(metrics)
+		if q.budget != nil {
+			q.budget.Charge(metrics.SessionID, metrics.CostUSD)
+		}
+		if m.TotalCostUSD != nil {
+			q.lastCostUSD = *m.TotalCostUSD
+		}
+	}
+}
+
+// checkTokenAlerts calls the token alerts whose threshold the session's
+// tokens reached with an assistant message.
+func (q *Query) checkTokenAlerts(msg types.Message) {
+	if _, ok := msg.(*types.AssistantMessage); !ok || len(q.tokenAlerts) == 0 {
+		return
+	}
+	used := q.usage.Total()
+	for i, alert := range q.tokenAlerts {
+		if q.tokenAlerted[i] || used.Total() < alert.Threshold {
+			continue
+		}
+		q.tokenAlerted[i] = true
+		q.callTokenAlert(alert, used)
+	}
+}
+
+// callTokenAlert calls a token alert, recovering from panics.
+func (q *Query) callTokenAlert(alert types.TokenAlert, used types.TokenUsage) {
+	defer func() {
+		if r := recover(); r != nil {
+			q.logger.Warning("Token alert at %d tokens panicked: %v", alert.Threshold, r)
+		}
+	}()
+	alert.Func(used)
+}
+
+// deliver sends a message to the consumer.
+func (q *Query) deliver(msg types.Message) error {
 	select {
 	case q.messagesChan <- msg:
 		return nil
@@ -258,6 +538,53 @@ func (q *Query) routeMessage(msg types.Message) error {
 	}
 }
 
+// assistantError returns the AssistantErrorEvent to follow an assistant
+// message whose Error is set, counting errors in a row for the retry policy.
+func (q *Query) assistantError(msg types.Message) *types.AssistantErrorEvent {
+	assistant, ok := msg.(*types.AssistantMessage)
+	if !ok || assistant.ParentToolUseID != nil {
+		return nil
+	}
+	if assistant.Error == nil {
+		q.errorsInARow = 0
+		return nil
+	}
+	q.errorsInARow++
+	return types.NewAssistantErrorEvent(assistant, q.errorsInARow, q.retryPolicy)
+}
+
+// retryStructuredOutput validates the structured output of a result against
+// the output schema. If it does not match and fix turns remain, it asks
+// Claude to fix it and reports true, so that the result is not delivered.
+func (q *Query) retryStructuredOutput(result *types.ResultMessage) bool {
+	errs := types.ValidateStructuredOutput(result, q.outputSchema)
+	if len(errs) == 0 || !q.isStreamingMode || q.outputAttempts >= q.outputRetries {
+		q.outputAttempts = 0
+		return false
+	}
+	q.outputAttempts++
+	q.logger.Debug("Structured output does not match schema (%v), asking for a fix (%d/%d)", errs, q.outputAttempts, q.outputRetries)
+
+	data, err := json.Marshal(map[string]interface{}{
+		"type": "user",
+		"message": map[string]interface{}{
+			"role":    "user",
+			"content": types.StructuredOutputFixPrompt(errs),
+		},
+		"parent_tool_use_id": nil,
+		"session_id":         "default",
+	})
+	if err == nil {
+		err = q.transport.Write(q.ctx, string(data))
+	}
+	if err != nil {
+		q.logger.Warning("Failed to ask for a structured output fix: %v", err)
+		q.outputAttempts = 0
+		return false
+	}
+	return true
+}
+
 // handleControlResponse handles a control response message.
 func (q *Query) handleControlResponse(msg *types.SystemMessage) error {
 	// Parse response - use msg.Response for control_response messages
@@ -326,28 +653,36 @@ func (q *Query) handleControlRequest(msg *types.SystemMessage) {
 		requestID = fmt.Sprintf("cli-request-%d", atomic.AddInt64(&q.nextRequestID, 1))
 		q.logger.Debug("handleControlRequest: generated requestID=%s for CLI-initiated request", requestID)
 	}
+	logger := q.logger.With("request_id", requestID)
 
 	if requestData == nil {
-		q.logger.Error("handleControlRequest: invalid control request format: requestData is nil")
+		logger.Error("handleControlRequest: invalid control request format: requestData is nil")
 		q.sendErrorResponse(requestID, "invalid control request format")
 		return
 	}
 
 	subtype, _ := requestData["subtype"].(string)
-	q.logger.Debug("handleControlRequest: subtype=%s", subtype)
+	logger.Debug("handleControlRequest: subtype=%s", subtype)
+
+	ctx, span := q.tracer.Start(q.correlationContext(q.turnContext(q.ctx)), spanControlRequest,
+		types.SpanAttribute{Key: attrRequestID, Value: requestID},
+		types.SpanAttribute{Key: attrSubtype, Value: subtype},
+	)
 
 	var response map[string]interface{}
 	var err error
+	defer func() { span.End(err) }()
 
 	switch subtype {
 	case "can_use_tool":
-		response, err = q.handlePermissionRequest(requestData)
+		response, err = q.handlePermissionRequestContext(ctx, requestData)
 	case "hook_callback":
-		response, err = q.handleHookCallback(requestData)
+		response, err = q.handleHookCallbackContext(ctx, requestData)
 	case "mcp_message":
-		response, err = q.handleMCPMessage(requestData)
+		response, err = q.handleMCPMessageContext(ctx, requestData)
 	case "interrupt":
-		// Handle interrupt - just acknowledge for now
+		// Stop running SDK MCP tools and acknowledge
+		q.CancelToolCalls()
 		response = make(map[string]interface{})
 	case "set_permission_mode":
 		// Handle permission mode change - acknowledge for now
@@ -366,6 +701,12 @@ func (q *Query) handleControlRequest(msg *types.SystemMessage) {
 
 // handlePermissionRequest handles a permission request for tool use.
 func (q *Query) handlePermissionRequest(requestData map[string]interface{}) (map[string]interface{}, error) {
+	return q.handlePermissionRequestContext(q.ctx, requestData)
+}
+
+// handlePermissionRequestContext handles a permission request for tool use,
+// passing ctx to the CanUseTool callback.
+func (q *Query) handlePermissionRequestContext(ctx context.Context, requestData map[string]interface{}) (map[string]interface{}, error) {
 	q.logger.Debug("handlePermissionRequest: entered, requestData=%+v", requestData)
 
 	if q.canUseTool == nil {
@@ -379,10 +720,11 @@ func (q *Query) handlePermissionRequest(requestData map[string]interface{}) (map
 	input, _ := requestData["input"].(map[string]interface{})
 	suggestions, _ := requestData["permission_suggestions"].([]interface{})
 
-	q.logger.Debug("handlePermissionRequest: toolName=%s, input=%+v", toolName, input)
+	logger := q.logger.With("tool", toolName)
+	logger.Debug("handlePermissionRequest: toolName=%s, input=%+v", toolName, input)
 
 	if toolName == "" || input == nil {
-		q.logger.Error("handlePermissionRequest: missing tool_name or input")
+		logger.Error("handlePermissionRequest: missing tool_name or input")
 		return nil, types.NewControlProtocolError("missing tool_name or input in permission request")
 	}
 
@@ -400,16 +742,34 @@ func (q *Query) handlePermissionRequest(requestData map[string]interface{}) (map
 		}
 	}
 
-	ctx := types.ToolPermissionContext{
-		Suggestions: permissionUpdates,
+	permissionCtx := types.ToolPermissionContext{
+		Suggestions:     permissionUpdates,
+		BlockedPath:     optionalString(requestData["blocked_path"]),
+		ParentToolUseID: optionalString(requestData["parent_tool_use_id"]),
+	}
+	permissionCtx.AgentName = q.agents.agentForTask(permissionCtx.ParentToolUseID)
+
+	record := types.PermissionAuditRecord{
+		Time:      time.Now(),
+		ToolName:  toolName,
+		InputHash: types.HashToolInput(input),
+		Source:    types.PermissionSourceCallback,
 	}
 
 	// Call permission callback
-	q.logger.Debug("handlePermissionRequest: CALLING canUseTool callback for tool=%s", toolName)
-	result, err := q.canUseTool(q.ctx, toolName, input, ctx)
-	q.logger.Debug("handlePermissionRequest: canUseTool callback returned: result=%+v, err=%v", result, err)
+	logger.Debug("handlePermissionRequest: CALLING canUseTool callback for tool=%s", toolName)
+	callbackInput := input
+	if q.redactor != nil {
+		callbackInput = q.redactor.RedactToolInput(input)
+	}
+	result, err := q.canUseTool(ctx, toolName, callbackInput, permissionCtx)
+	record.Duration = time.Since(record.Time)
+	logger.Debug("handlePermissionRequest: canUseTool callback returned: result=%+v, err=%v", result, err)
 	if err != nil {
-		q.logger.Error("handlePermissionRequest: canUseTool callback returned error: %v", err)
+		logger.Error("handlePermissionRequest: canUseTool callback returned error: %v", err)
+		record.Decision = "error"
+		record.Error = err.Error()
+		q.auditPermission(record)
 		return nil, err
 	}
 
@@ -418,6 +778,7 @@ func (q *Query) handlePermissionRequest(requestData map[string]interface{}) (map
 
 	switch r := result.(type) {
 	case types.PermissionResultAllow:
+		record.Source = r.Source
 		response["behavior"] = "allow"
 		if r.UpdatedInput != nil {
 			response["updatedInput"] = *r.UpdatedInput
@@ -429,6 +790,7 @@ func (q *Query) handlePermissionRequest(requestData map[string]interface{}) (map
 		}
 
 	case *types.PermissionResultAllow:
+		record.Source = r.Source
 		response["behavior"] = "allow"
 		if r.UpdatedInput != nil {
 			response["updatedInput"] = *r.UpdatedInput
@@ -440,6 +802,7 @@ func (q *Query) handlePermissionRequest(requestData map[string]interface{}) (map
 		}
 
 	case types.PermissionResultDeny:
+		record.Source = r.Source
 		response["behavior"] = "deny"
 		if r.Message != "" {
 			response["message"] = r.Message
@@ -449,6 +812,7 @@ func (q *Query) handlePermissionRequest(requestData map[string]interface{}) (map
 		}
 
 	case *types.PermissionResultDeny:
+		record.Source = r.Source
 		response["behavior"] = "deny"
 		if r.Message != "" {
 			response["message"] = r.Message
@@ -458,17 +822,84 @@ func (q *Query) handlePermissionRequest(requestData map[string]interface{}) (map
 		}
 
 	default:
-		return nil, types.NewControlProtocolError("permission callback returned invalid type")
+		err := types.NewControlProtocolError("permission callback returned invalid type")
+		record.Decision = "error"
+		record.Error = err.Error()
+		q.auditPermission(record)
+		return nil, err
+	}
+
+	record.Decision, _ = response["behavior"].(string)
+	record.Message, _ = response["message"].(string)
+	record.Interrupt, _ = response["interrupt"].(bool)
+	record.UpdatedPermissions, _ = response["updatedPermissions"].([]types.PermissionUpdate)
+	if updated, ok := response["updatedInput"].(map[string]interface{}); ok {
+		record.InputUpdated = types.HashToolInput(updated) != record.InputHash
 	}
+	q.auditPermission(record)
 
 	return response, nil
 }
 
+// auditPermission counts a permission decision and sends it to the audit sink, if one is configured.
+func (q *Query) auditPermission(record types.PermissionAuditRecord) {
+	if record.Source == "" {
+		record.Source = types.PermissionSourceCallback
+	}
+	_ = q.permissionStats.RecordPermission(record)
+	if q.permissionAudit == nil {
+		return
+	}
+
+	// A panicking sink must not take down permission handling
+	defer func() {
+		if r := recover(); r != nil {
+			q.logger.Warning("Permission audit sink panicked: %v", r)
+		}
+	}()
+	if err := q.permissionAudit.RecordPermission(record); err != nil {
+		q.logger.Warning("Permission audit sink failed: %v", err)
+	}
+}
+
+// auditHookPermission records the permission decision of a PreToolUse hook output, if it made one.
+func (q *Query) auditHookPermission(execution types.HookExecution, toolInput map[string]interface{}, output map[string]interface{}, started time.Time) {
+	if execution.Event != types.HookEventPreToolUse {
+		return
+	}
+	specific, _ := output["hookSpecificOutput"].(map[string]interface{})
+	decision, _ := specific["permissionDecision"].(string)
+	if decision == "" {
+		return
+	}
+
+	record := types.PermissionAuditRecord{
+		Time:      started,
+		ToolName:  execution.ToolName,
+		InputHash: types.HashToolInput(toolInput),
+		Decision:  decision,
+		Source:    types.PermissionSourceHook,
+		Duration:  execution.Duration,
+	}
+	if execution.ToolUseID != nil {
+		record.ToolUseID = *execution.ToolUseID
+	}
+	record.Message, _ = specific["permissionDecisionReason"].(string)
+	_, record.InputUpdated = specific["updatedInput"]
+	q.auditPermission(record)
+}
+
 // handleHookCallback handles a hook callback request.
 func (q *Query) handleHookCallback(requestData map[string]interface{}) (map[string]interface{}, error) {
+	return q.handleHookCallbackContext(q.ctx, requestData)
+}
+
+// handleHookCallbackContext handles a hook callback request, passing a child
+// of ctx to the callback.
+func (q *Query) handleHookCallbackContext(ctx context.Context, requestData map[string]interface{}) (map[string]interface{}, error) {
 	callbackID, _ := requestData["callback_id"].(string)
 	input := requestData["input"]
-	toolUseID, _ := requestData["tool_use_id"].(*string)
+	toolUseID := parseToolUseID(requestData["tool_use_id"])
 
 	if callbackID == "" {
 		return nil, types.NewControlProtocolError("missing callback_id in hook callback request")
@@ -477,33 +908,212 @@ func (q *Query) handleHookCallback(requestData map[string]interface{}) (map[stri
 	// Find callback
 	q.mu.Lock()
 	callback, exists := q.hookCallbacks[callbackID]
+	asyncTimeout, isAsync := q.asyncHookTimeouts[callbackID]
+	info := q.hookCallbackInfo[callbackID]
 	q.mu.Unlock()
 
 	if !exists {
 		return nil, types.NewControlProtocolError("no hook callback found for ID: " + callbackID)
 	}
 
+	execution := types.HookExecution{
+		CallbackID: callbackID,
+		Event:      info.event,
+		Matcher:    info.matcher,
+		ToolName:   hookToolName(input),
+		ToolUseID:  toolUseID,
+		Async:      isAsync,
+
+		CorrelationID: types.CorrelationIDFrom(ctx),
+	}
+
+	// Async hooks are acknowledged immediately and completed in the background
+	if isAsync {
+		go q.runAsyncHook(ctx, execution, callback, input, asyncTimeout)
+		return map[string]interface{}{
+			"async":        true,
+			"asyncTimeout": int(asyncTimeout / time.Millisecond),
+		}, nil
+	}
+
 	// Build hook context
 	hookCtx := types.HookContext{}
 
+	// Snapshot the tool input so in-place edits by the hook can be propagated
+	var originalToolInput map[string]interface{}
+	if info.event == types.HookEventPreToolUse {
+		originalToolInput = cloneToolInput(input)
+	}
+
 	// Call hook callback
-	hookOutput, err := callback(q.ctx, input, toolUseID, hookCtx)
+	ctx, span := q.startHookSpan(ctx, execution)
+	start := time.Now()
+	hookOutput, err := callback(ctx, input, toolUseID, hookCtx)
+	execution.Duration = time.Since(start)
+
+	// Convert hook output to response
+	var output map[string]interface{}
+	if err == nil {
+		output, err = q.normalizeHookOutput(execution, hookOutput)
+	}
+	if err == nil && originalToolInput != nil {
+		output = types.PropagateUpdatedInput(output, originalToolInput, hookToolInput(input))
+	}
+	execution.Err = err
+	execution.Decision = types.HookOutputDecision(output)
+	span.End(err)
+	q.recordHookExecution(execution)
+	q.auditHookPermission(execution, originalToolInput, output, start)
+
 	if err != nil {
 		return nil, err
 	}
+	return output, nil
+}
 
-	// Convert hook output to response
-	// The callback should return a map[string]interface{} representing the hook output
-	response, ok := hookOutput.(map[string]interface{})
-	if !ok {
-		return nil, types.NewControlProtocolError("hook callback must return map[string]interface{}")
+// hookToolInput returns the tool_input map of a raw hook input, if any.
+func hookToolInput(input interface{}) map[string]interface{} {
+	inputMap, _ := input.(map[string]interface{})
+	toolInput, _ := inputMap["tool_input"].(map[string]interface{})
+	return toolInput
+}
+
+// cloneToolInput returns a deep copy of the tool_input map of a raw hook input.
+func cloneToolInput(input interface{}) map[string]interface{} {
+	toolInput := hookToolInput(input)
+	if toolInput == nil {
+		return nil
 	}
+	data, err := json.Marshal(toolInput)
+	if err != nil {
+		return nil
+	}
+	var clone map[string]interface{}
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil
+	}
+	return clone
+}
 
-	return response, nil
+// runAsyncHook executes an async hook in the background. The CLI has already
+// been acknowledged and the control protocol has no request for delivering a
+// later result, so the hook's output is only validated and recorded; errors
+// and hooks that exceed the declared timeout are logged. The span of the hook
+// is a child of the span in parent.
+func (q *Query) runAsyncHook(parent context.Context, execution types.HookExecution, callback types.HookCallbackFunc, input interface{}, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	ctx, span := q.startHookSpan(ctx, execution)
+
+	callbackID := execution.CallbackID
+	toolUseID := execution.ToolUseID
+	start := time.Now()
+
+	type hookResult struct {
+		output interface{}
+		err    error
+	}
+
+	// Run the callback separately so a hook that ignores ctx cannot hold up delivery
+	done := make(chan hookResult, 1)
+	go func() {
+		output, err := callback(ctx, input, toolUseID, types.HookContext{})
+		done <- hookResult{output: output, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		execution.Duration = time.Since(start)
+		execution.TimedOut = true
+		execution.Err = ctx.Err()
+		span.End(execution.Err)
+		q.recordHookExecution(execution)
+		q.logger.With("hook", callbackID).Warning("Async hook %s did not complete within %v", callbackID, timeout)
+	case result := <-done:
+		execution.Duration = time.Since(start)
+		if result.err != nil {
+			execution.Err = result.err
+			q.logger.With("hook", callbackID).Warning("Async hook %s failed: %v", callbackID, result.err)
+		} else if output, err := q.normalizeHookOutput(execution, result.output); err != nil {
+			execution.Err = err
+		} else {
+			execution.Decision = types.HookOutputDecision(output)
+		}
+		span.End(execution.Err)
+		q.recordHookExecution(execution)
+	}
+}
+
+// normalizeHookOutput converts a hook's return value into protocol form and
+// validates it against the event the hook is registered for. Validation
+// failures are logged, since the CLI would otherwise silently ignore them.
+func (q *Query) normalizeHookOutput(execution types.HookExecution, hookOutput interface{}) (map[string]interface{}, error) {
+	output, err := types.NormalizeHookOutput(hookOutput)
+	if err == nil {
+		err = types.ValidateHookOutput(execution.Event, output)
+	}
+	if err != nil {
+		q.logger.With("hook", execution.CallbackID).Warning("Hook %s (%s) returned invalid output: %v", execution.CallbackID, execution.Event, err)
+		return nil, err
+	}
+	return output, nil
+}
+
+// recordHookExecution adds a hook execution to the timings of the current
+// query, and reports it to the metrics callback, if any.
+func (q *Query) recordHookExecution(execution types.HookExecution) {
+	q.addHookTime(execution)
+	if q.hookMetrics == nil {
+		return
+	}
+
+	// A panicking metrics callback must not take down hook handling
+	defer func() {
+		if r := recover(); r != nil {
+			q.logger.Warning("Hook metrics callback panicked: %v", r)
+		}
+	}()
+	q.hookMetrics(execution)
+}
+
+// hookToolName extracts the tool name from a hook input, if it has one.
+func hookToolName(input interface{}) string {
+	if raw, ok := input.(map[string]interface{}); ok {
+		name, _ := raw["tool_name"].(string)
+		return name
+	}
+	return ""
+}
+
+// optionalString returns a pointer to a non-empty string value, or nil.
+func optionalString(value interface{}) *string {
+	if s, ok := value.(string); ok && s != "" {
+		return &s
+	}
+	return nil
+}
+
+// parseToolUseID extracts the optional tool_use_id from a hook callback request.
+func parseToolUseID(value interface{}) *string {
+	switch id := value.(type) {
+	case string:
+		if id != "" {
+			return &id
+		}
+	case *string:
+		return id
+	}
+	return nil
 }
 
 // handleMCPMessage handles an MCP message request.
 func (q *Query) handleMCPMessage(requestData map[string]interface{}) (map[string]interface{}, error) {
+	return q.handleMCPMessageContext(q.ctx, requestData)
+}
+
+// handleMCPMessageContext handles an MCP message request, passing a child of
+// ctx to the tool handler.
+func (q *Query) handleMCPMessageContext(ctx context.Context, requestData map[string]interface{}) (map[string]interface{}, error) {
 	serverName, _ := requestData["server_name"].(string)
 	message, _ := requestData["message"].(map[string]interface{})
 
@@ -531,8 +1141,39 @@ func (q *Query) handleMCPMessage(requestData map[string]interface{}) (map[string
 		}, nil
 	}
 
-	// Route message to MCP server
-	mcpResponse, err := server.HandleMessage(message)
+	// Route message to MCP server, canceled on interrupt or stop
+	ctx, done := q.trackToolCall(ctx)
+	defer done()
+	var span types.Span
+	if message["method"] == "tools/call" {
+		ctx, span = q.startSDKToolSpan(ctx, serverName, message)
+	}
+	ctx = mcp.ContextWithProgressHandler(ctx, func(p types.ToolProgress) {
+		p.Server = serverName
+		q.handleToolProgress(p)
+	})
+	ctx = mcp.ContextWithPanicHandler(ctx, func(p types.ToolPanic) {
+		p.Server = serverName
+		if q.toolPanic != nil {
+			q.toolPanic(p)
+			return
+		}
+		q.logger.With("tool", p.Tool).Error("SDK MCP tool %s panicked: %v\n%s", p.Tool, p.Value, p.Stack)
+	})
+	if q.caller != nil {
+		ctx = types.ContextWithCaller(ctx, q.caller)
+	}
+	var mcpResponse map[string]interface{}
+	var err error
+	start := time.Now()
+	if handler, ok := server.(contextMessageHandler); ok {
+		mcpResponse, err = handler.HandleMessageContext(ctx, message)
+	} else {
+		mcpResponse, err = server.HandleMessage(message)
+	}
+	if message["method"] == "tools/call" {
+		q.recordSDKToolCall(message, mcpResponse, err, span, time.Since(start))
+	}
 	if err != nil {
 		// Return JSONRPC error response
 		messageID := message["id"]
@@ -548,11 +1189,66 @@ func (q *Query) handleMCPMessage(requestData map[string]interface{}) (map[string
 		}, nil
 	}
 
+	if q.redactor != nil && message["method"] == "tools/call" {
+		if result, ok := mcpResponse["result"]; ok {
+			mcpResponse["result"] = q.redactor.RedactValue(types.RedactionToolResult, result)
+		}
+	}
+
 	return map[string]interface{}{
 		"mcp_response": mcpResponse,
 	}, nil
 }
 
+// recordSDKToolCall reports an SDK MCP tool call to the metrics and ends its
+// span, as failed if the server returned an error or the result is an error.
+func (q *Query) recordSDKToolCall(message, response map[string]interface{}, err error, span types.Span, duration time.Duration) {
+	params, _ := message["params"].(map[string]interface{})
+	tool, _ := params["name"].(string)
+	if err == nil {
+		if result, ok := response["result"].(map[string]interface{}); ok && result["isError"] == true {
+			err = fmt.Errorf("tool %s returned an error result", tool)
+		} else if rpcErr, ok := response["error"].(map[string]interface{}); ok {
+			err = fmt.Errorf("tool %s failed: %v", tool, rpcErr["message"])
+		}
+	}
+	q.metrics.SDKToolCompleted(tool, duration, err)
+	span.End(err)
+}
+
+// contextMessageHandler is an MCP server whose calls can be canceled.
+type contextMessageHandler interface {
+	HandleMessageContext(ctx context.Context, message map[string]interface{}) (map[string]interface{}, error)
+}
+
+// trackToolCall returns a context for an SDK MCP request, derived from ctx,
+// that is canceled by CancelToolCalls or when the query stops, and a function
+// to release it.
+func (q *Query) trackToolCall(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	q.mu.Lock()
+	q.nextToolCallID++
+	id := q.nextToolCallID
+	q.toolCalls[id] = cancel
+	q.mu.Unlock()
+
+	return ctx, func() {
+		q.mu.Lock()
+		delete(q.toolCalls, id)
+		q.mu.Unlock()
+		cancel()
+	}
+}
+
+// CancelToolCalls cancels the contexts of the SDK MCP tool calls in progress.
+func (q *Query) CancelToolCalls() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, cancel := range q.toolCalls {
+		cancel()
+	}
+}
+
 // sendControlRequest sends a control request to CLI and waits for response.
 func (q *Query) sendControlRequest(ctx context.Context, request map[string]interface{}) (map[string]interface{}, error) {
 	if !q.isStreamingMode {
@@ -659,6 +1355,13 @@ func (q *Query) generateRequestID() string {
 	return fmt.Sprintf("req_%d", id)
 }
 
+// registerHookCallbackInfo records the event and matcher a hook callback was registered for.
+func (q *Query) registerHookCallbackInfo(callbackID string, event types.HookEvent, matcher *string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.hookCallbackInfo[callbackID] = hookCallbackInfo{event: event, matcher: matcher}
+}
+
 // registerHookCallback registers a hook callback and returns its ID.
 func (q *Query) registerHookCallback(callback types.HookCallbackFunc) string {
 	q.mu.Lock()
@@ -670,11 +1373,98 @@ func (q *Query) registerHookCallback(callback types.HookCallbackFunc) string {
 	return callbackID
 }
 
+// registerAsyncHook marks a registered hook callback as async with the given timeout in milliseconds.
+func (q *Query) registerAsyncHook(callbackID string, timeoutMs *int) {
+	timeout := types.DefaultAsyncHookTimeoutMs
+	if timeoutMs != nil && *timeoutMs > 0 {
+		timeout = *timeoutMs
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.asyncHookTimeouts[callbackID] = time.Duration(timeout) * time.Millisecond
+}
+
+// PermissionStats returns the counts of the permission decisions made so far.
+func (q *Query) PermissionStats() types.PermissionStats {
+	return q.permissionStats.Stats()
+}
+
+// UsageReport returns the token usage of the session so far.
+func (q *Query) UsageReport() types.UsageReport {
+	return q.usage.Report()
+}
+
+// TokensUsed returns the tokens used by the session so far.
+func (q *Query) TokensUsed() types.TokenUsage {
+	return q.usage.Total()
+}
+
 // AddMCPServer adds an MCP server for handling MCP messages.
 func (q *Query) AddMCPServer(name string, server types.MCPServer) {
+	var unsubscribe func()
+	if notifier, ok := server.(mcpNotifier); ok {
+		unsubscribe = notifier.AddNotificationHandler(func(notification map[string]interface{}) {
+			q.sendMCPNotification(name, notification)
+		})
+	}
+
 	q.mu.Lock()
 	defer q.mu.Unlock()
 	q.mcpServers[name] = server
+	if unsubscribe != nil {
+		q.mcpUnsubscribe = append(q.mcpUnsubscribe, unsubscribe)
+	}
+}
+
+// mcpNotifier is an MCP server that notifies clients when its tool or
+// resource list changes.
+type mcpNotifier interface {
+	AddNotificationHandler(handler func(notification map[string]interface{})) (remove func())
+}
+
+// handleToolProgress passes tool progress to the ToolProgress callback and
+// forwards it to the CLI as an MCP notification.
+func (q *Query) handleToolProgress(p types.ToolProgress) {
+	if q.toolProgress != nil {
+		q.toolProgress(p)
+	}
+
+	if notification := mcp.ProgressNotification(p); notification != nil {
+		q.sendMCPNotification(p.Server, notification)
+	}
+}
+
+// sendMCPNotification forwards a notification from an SDK MCP server to the
+// CLI. Notifications need streaming mode, where the CLI reads control
+// requests.
+func (q *Query) sendMCPNotification(server string, notification map[string]interface{}) {
+	if !q.isStreamingMode {
+		return
+	}
+	select {
+	case <-q.stopChan:
+		return
+	default:
+	}
+	request := map[string]interface{}{
+		"type":       "control_request",
+		"request_id": q.generateRequestID(),
+		"request": map[string]interface{}{
+			"subtype":     "mcp_message",
+			"server_name": server,
+			"message":     notification,
+		},
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		q.logger.Error("sendMCPNotification: failed to marshal notification: %v", err)
+		return
+	}
+	// Notifications have no response, so the request is not tracked.
+	if err := q.transport.Write(q.ctx, string(data)); err != nil {
+		q.logger.Error("sendMCPNotification: failed to write: %v", err)
+	}
 }
 
 // ConfigureMCPServers registers SDK MCP servers defined in options with the query handler.
@@ -699,7 +1489,7 @@ func (q *Query) ConfigureMCPServers(opts *types.ClaudeAgentOptions) error {
 			continue
 		}
 
-		server, err := instantiateSdkServer(toolConfig)
+		server, err := mcp.ServerFromConfig(toolConfig)
 		if err != nil {
 			return fmt.Errorf("configure MCP server %s: %w", name, err)
 		}
@@ -710,38 +1500,71 @@ func (q *Query) ConfigureMCPServers(opts *types.ClaudeAgentOptions) error {
 	return nil
 }
 
-// instantiateSdkServer converts a ToolServerConfig into a concrete MCP server implementation.
-func instantiateSdkServer(config *types.ToolServerConfig) (types.MCPServer, error) {
-	if config == nil {
-		return nil, fmt.Errorf("nil SDK MCP server configuration")
+// resolveHookMatcher returns the CLI matcher and callbacks for a HookMatcher.
+// A Command is added as an extra callback. Tool pattern matchers such as
+// "mcp__calc__*" or "Bash(git *)" are translated into a CLI matcher regex.
+// Specifiers cannot be expressed to the CLI, so the callbacks are wrapped to
+// skip tool uses the specifier excludes. Plain names and regular expressions
+// are passed through unchanged.
+func resolveHookMatcher(matcher types.HookMatcher) (*string, []types.HookCallbackFunc, error) {
+	if matcher.Command != "" {
+		timeout := time.Duration(types.DefaultHookCommandTimeoutSeconds) * time.Second
+		if matcher.TimeoutSeconds > 0 {
+			timeout = time.Duration(matcher.TimeoutSeconds) * time.Second
+		}
+		hooks := make([]types.HookCallbackFunc, 0, len(matcher.Hooks)+1)
+		hooks = append(hooks, matcher.Hooks...)
+		matcher.Hooks = append(hooks, newCommandHook(matcher.Command, timeout, matcher.CommandEnv))
 	}
 
-	switch instance := config.Instance.(type) {
-	case nil:
-		return nil, fmt.Errorf("SDK MCP server %s has no instance", config.Name)
-	case types.MCPServer:
-		return instance, nil
-	case []types.McpTool:
-		server := mcp.NewSdkMCPServer(config.Name, config.Version, instance)
-		config.Instance = server
-		return server, nil
-	default:
-		if server, ok := instance.(types.MCPServer); ok {
-			return server, nil
+	if matcher.Matcher == nil || !types.IsToolPattern(*matcher.Matcher) {
+		return matcher.Matcher, matcher.Hooks, nil
+	}
+
+	toolMatcher, err := types.ParseToolMatcher(*matcher.Matcher)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid hook matcher %q: %w", *matcher.Matcher, err)
+	}
+	pattern := toolMatcher.Regex()
+
+	if !toolMatcher.HasSpecifiers() {
+		return &pattern, matcher.Hooks, nil
+	}
+
+	callbacks := make([]types.HookCallbackFunc, len(matcher.Hooks))
+	for i, callback := range matcher.Hooks {
+		callback := callback
+		callbacks[i] = func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+			if !toolMatcher.MatchesHookInput(input) {
+				return map[string]interface{}{}, nil
+			}
+			return callback(ctx, input, toolUseID, hookCtx)
+		}
+	}
+	return &pattern, callbacks, nil
+}
+
+// matchedHook wraps a callback so it only runs for hook inputs whose tool name
+// matches pattern, mirroring the CLI's matcher selection. Inputs without a
+// tool name always match.
+func matchedHook(pattern *string, callback types.HookCallbackFunc) types.HookCallbackFunc {
+	return func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+		if toolName := hookToolName(input); toolName != "" && !matchesToolName(toolName, pattern) {
+			return nil, nil
 		}
-		return nil, fmt.Errorf("unsupported SDK MCP server instance type %T", instance)
+		return callback(ctx, input, toolUseID, hookCtx)
 	}
 }
 
 // matchesToolName checks if a tool name matches a matcher pattern.
-// nolint:unused
+// The pattern must match the whole tool name.
 func matchesToolName(toolName string, pattern *string) bool {
-	if pattern == nil || *pattern == "" {
+	if pattern == nil || *pattern == "" || *pattern == "*" {
 		return true // No pattern means match all
 	}
 
 	// Use regex for pattern matching
-	regex, err := regexp.Compile(*pattern)
+	regex, err := regexp.Compile("^(?:" + *pattern + ")$")
 	if err != nil {
 		return false
 	}
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// queryTiming measures the latency breakdown of a query.
+type queryTiming struct {
+	start      time.Time
+	firstToken time.Duration
+	phaseStart time.Time       // Start of the current model or tool phase
+	inTools    bool            // Whether tools are running
+	pending    map[string]bool // IDs of the tool uses awaiting their result
+	model      time.Duration   // Model time of the current turn
+	turns      []types.TurnTimings
+	hooks      time.Duration
+}
+
+// startTiming starts measuring the timings of a query whose prompt was just
+// sent.
+func (q *Query) startTiming() {
+	now := time.Now()
+	q.timingMu.Lock()
+	defer q.timingMu.Unlock()
+	q.timings = append(q.timings, &queryTiming{start: now, phaseStart: now, pending: make(map[string]bool)})
+}
+
+// timeMessage advances the timings of the current query with a message,
+// setting them on its ResultMessage. Messages of subagents are part of the
+// Task tool running them.
+func (q *Query) timeMessage(msg types.Message) {
+	now := time.Now()
+	q.timingMu.Lock()
+	defer q.timingMu.Unlock()
+	if len(q.timings) == 0 {
+		return
+	}
+	timing := q.timings[0]
+
+	switch m := msg.(type) {
+	case *types.StreamEvent:
+		if m.ParentToolUseID == nil && m.Event["type"] == "content_block_delta" {
+			timing.markFirstToken(now)
+		}
+	case *types.AssistantMessage:
+		if m.ParentToolUseID != nil {
+			return
+		}
+		timing.markFirstToken(now)
+		uses := types.ToolUses(m)
+		if len(uses) == 0 {
+			return
+		}
+		if !timing.inTools {
+			timing.model = now.Sub(timing.phaseStart)
+			timing.phaseStart = now
+			timing.inTools = true
+		}
+		for _, use := range uses {
+			timing.pending[use.ID] = true
+		}
+	case *types.UserMessage:
+		if m.ParentToolUseID != nil || !timing.inTools {
+			return
+		}
+		for _, result := range types.ToolResults(m) {
+			delete(timing.pending, result.ToolUseID)
+		}
+		if len(timing.pending) == 0 {
+			timing.endTurn(now.Sub(timing.phaseStart))
+			timing.phaseStart = now
+			timing.inTools = false
+		}
+	case *types.ResultMessage:
+		q.timings = q.timings[1:]
+		if timing.inTools {
+			timing.endTurn(now.Sub(timing.phaseStart))
+		} else {
+			timing.model = now.Sub(timing.phaseStart)
+			timing.endTurn(0)
+		}
+		m.Timings = timing.result(now)
+		if !q.spawnReported {
+			q.spawnReported = true
+			if spawned, ok := q.transport.(interface{ SpawnDuration() time.Duration }); ok {
+				m.Timings.Spawn = spawned.SpawnDuration()
+			}
+		}
+	}
+}
+
+// addHookTime adds the duration of a hook callback the CLI waited for to
+// the current query.
+func (q *Query) addHookTime(execution types.HookExecution) {
+	if execution.Async {
+		return
+	}
+	q.timingMu.Lock()
+	defer q.timingMu.Unlock()
+	if len(q.timings) > 0 {
+		q.timings[0].hooks += execution.Duration
+	}
+}
+
+// markFirstToken records the time to the first token, if not yet known.
+func (t *queryTiming) markFirstToken(now time.Time) {
+	if t.firstToken == 0 {
+		t.firstToken = now.Sub(t.start)
+	}
+}
+
+// endTurn ends the current turn, whose tools ran for tools.
+func (t *queryTiming) endTurn(tools time.Duration) {
+	t.turns = append(t.turns, types.TurnTimings{Model: t.model, Tools: tools})
+	t.model = 0
+}
+
+// result returns the timings of the query, whose result arrived at now.
+func (t *queryTiming) result(now time.Time) *types.Timings {
+	timings := &types.Timings{
+		FirstToken: t.firstToken,
+		Total:      now.Sub(t.start),
+		Hooks:      t.hooks,
+		Turns:      t.turns,
+	}
+	for _, turn := range t.turns {
+		timings.Model += turn.Model
+		timings.Tools += turn.Tools
+	}
+	return timings
+}
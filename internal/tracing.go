@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"context"
+	"errors"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Errors ending spans without a Go error.
+var (
+	errToolResult   = errors.New("tool returned an error result")
+	errQueryStopped = errors.New("query stopped before its result")
+)
+
+// Span names. Tool and hook names are attributes, so that the names stay few.
+const (
+	spanQuery          = "claude.query"           // A query, from sending the prompt to its result
+	spanTool           = "claude.tool"            // A tool use, from Claude's request to its result
+	spanControlRequest = "claude.control_request" // A control request from the CLI
+	spanHook           = "claude.hook"            // A hook callback
+	spanSDKTool        = "claude.sdk_tool"        // A call to a tool of an SDK MCP server
+)
+
+// Span attributes, following the OpenTelemetry GenAI conventions where they
+// have one.
+const (
+	attrSessionID     = "claude.session_id"
+	attrNumTurns      = "claude.num_turns"
+	attrStopReason    = "claude.stop_reason"
+	attrCostUSD       = "claude.cost_usd"
+	attrInputTokens   = "gen_ai.usage.input_tokens"
+	attrOutputTokens  = "gen_ai.usage.output_tokens"
+	attrToolName      = "gen_ai.tool.name"
+	attrToolCallID    = "gen_ai.tool.call.id"
+	attrRequestID     = "claude.control.request_id"
+	attrSubtype       = "claude.control.subtype"
+	attrHookEvent     = "claude.hook.event"
+	attrHookID        = "claude.hook.callback_id"
+	attrHookAsync     = "claude.hook.async"
+	attrMCPServerName = "claude.mcp.server"
+	attrCorrelationID = "claude.correlation_id"
+)
+
+// StartTurn starts the span of a query whose prompt was just sent, as a child
+// of the span in ctx, if any. The span ends with the query's ResultMessage,
+// and is the parent of the spans of its tool uses and control requests.
+// Queries sent before the previous ones completed get spans of their own,
+// ended in order. It also starts measuring the query's Timings, and
+// records the correlation ID in ctx, if any.
+func (q *Query) StartTurn(ctx context.Context) {
+	q.startTiming()
+	q.startCorrelation(ctx)
+	var attrs []types.SpanAttribute
+	if id := types.CorrelationIDFrom(ctx); id != "" {
+		attrs = append(attrs, types.SpanAttribute{Key: attrCorrelationID, Value: id})
+	}
+	_, span := q.tracer.Start(ctx, spanQuery, attrs...)
+	q.spanMu.Lock()
+	defer q.spanMu.Unlock()
+	q.turnSpans = append(q.turnSpans, span)
+}
+
+// turnContext returns ctx with the span of the current query, if any.
+func (q *Query) turnContext(ctx context.Context) context.Context {
+	q.spanMu.Lock()
+	defer q.spanMu.Unlock()
+	if len(q.turnSpans) == 0 {
+		return ctx
+	}
+	return q.tracer.ContextWithSpan(ctx, q.turnSpans[0])
+}
+
+// traceMessage starts the spans of the tool uses Claude requests, and ends
+// them with their results. A ResultMessage ends the span of its query.
+func (q *Query) traceMessage(msg types.Message) {
+	switch m := msg.(type) {
+	case *types.AssistantMessage:
+		for _, use := range types.ToolUses(m) {
+			q.startToolSpan(use, m.ParentToolUseID)
+		}
+	case *types.UserMessage:
+		for _, result := range types.ToolResults(m) {
+			q.endToolSpan(result)
+		}
+	case *types.ResultMessage:
+		q.endTurn(m)
+	}
+}
+
+// startToolSpan starts the span of a tool use, as a child of the span of the
+// Task tool use running it for subagents, or of the current query.
+func (q *Query) startToolSpan(use *types.ToolUseBlock, parentToolUseID *string) {
+	q.spanMu.Lock()
+	defer q.spanMu.Unlock()
+
+	ctx := q.ctx
+	if parent, ok := q.toolSpans[stringValue(parentToolUseID)]; ok {
+		ctx = q.tracer.ContextWithSpan(ctx, parent)
+	} else if len(q.turnSpans) > 0 {
+		ctx = q.tracer.ContextWithSpan(ctx, q.turnSpans[0])
+	}
+	_, span := q.tracer.Start(ctx, spanTool,
+		types.SpanAttribute{Key: attrToolName, Value: use.Name},
+		types.SpanAttribute{Key: attrToolCallID, Value: use.ID},
+	)
+	q.toolSpans[use.ID] = span
+}
+
+// endToolSpan ends the span of the tool use a result is for.
+func (q *Query) endToolSpan(result *types.ToolResultBlock) {
+	q.spanMu.Lock()
+	span, ok := q.toolSpans[result.ToolUseID]
+	delete(q.toolSpans, result.ToolUseID)
+	q.spanMu.Unlock()
+
+	if !ok {
+		return
+	}
+	var err error
+	if result.IsError != nil && *result.IsError {
+		err = errToolResult
+	}
+	span.End(err)
+}
+
+// endTurn ends the span of the current query with the measurements of its
+// result, and the spans of tool uses left without a result.
+func (q *Query) endTurn(result *types.ResultMessage) {
+	q.spanMu.Lock()
+	defer q.spanMu.Unlock()
+
+	for id, span := range q.toolSpans {
+		span.End(nil)
+		delete(q.toolSpans, id)
+	}
+	if len(q.turnSpans) == 0 {
+		return
+	}
+	span := q.turnSpans[0]
+	q.turnSpans = q.turnSpans[1:]
+
+	m := types.NewQueryMetrics(result, q.lastCostUSD)
+	span.SetAttributes(
+		types.SpanAttribute{Key: attrSessionID, Value: m.SessionID},
+		types.SpanAttribute{Key: attrNumTurns, Value: m.Turns},
+		types.SpanAttribute{Key: attrStopReason, Value: string(m.StopReason)},
+		types.SpanAttribute{Key: attrCostUSD, Value: m.CostUSD},
+		types.SpanAttribute{Key: attrInputTokens, Value: m.Usage.InputTokens},
+		types.SpanAttribute{Key: attrOutputTokens, Value: m.Usage.OutputTokens},
+	)
+	var err error
+	if m.IsError {
+		err = errors.New(string(m.StopReason))
+	}
+	span.End(err)
+}
+
+// endSpans ends the spans still open when the query stops.
+func (q *Query) endSpans() {
+	q.spanMu.Lock()
+	defer q.spanMu.Unlock()
+
+	for id, span := range q.toolSpans {
+		span.End(nil)
+		delete(q.toolSpans, id)
+	}
+	for _, span := range q.turnSpans {
+		span.End(errQueryStopped)
+	}
+	q.turnSpans = nil
+}
+
+// startHookSpan starts the span of a hook callback, as a child of the span in
+// ctx.
+func (q *Query) startHookSpan(ctx context.Context, execution types.HookExecution) (context.Context, types.Span) {
+	attrs := []types.SpanAttribute{
+		{Key: attrHookEvent, Value: string(execution.Event)},
+		{Key: attrHookID, Value: execution.CallbackID},
+		{Key: attrHookAsync, Value: execution.Async},
+	}
+	if execution.ToolName != "" {
+		attrs = append(attrs, types.SpanAttribute{Key: attrToolName, Value: execution.ToolName})
+	}
+	if execution.ToolUseID != nil {
+		attrs = append(attrs, types.SpanAttribute{Key: attrToolCallID, Value: *execution.ToolUseID})
+	}
+	return q.tracer.Start(ctx, spanHook, attrs...)
+}
+
+// startSDKToolSpan starts the span of a tools/call request to an SDK MCP
+// server, as a child of the span in ctx. The tool handler receives the
+// returned context, so that the calls it makes join the trace.
+func (q *Query) startSDKToolSpan(ctx context.Context, server string, message map[string]interface{}) (context.Context, types.Span) {
+	params, _ := message["params"].(map[string]interface{})
+	tool, _ := params["name"].(string)
+	return q.tracer.Start(ctx, spanSDKTool,
+		types.SpanAttribute{Key: attrToolName, Value: types.McpToolName(server, tool)},
+		types.SpanAttribute{Key: attrMCPServerName, Value: server},
+	)
+}
+
+// stringValue returns the value of an optional string, or "".
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -10,51 +10,76 @@ import (
 	"github.com/M1n9X/claude-agent-sdk-go/types"
 )
 
-// FindCLI searches for Claude Code CLI binary in standard locations.
-// It checks in this order:
-//  1. PATH via exec.LookPath("claude")
-//  2. Default Claude installation location (new in CLI 2.0+):
-//     - ~/.claude/local/claude
-//  3. Common npm/yarn global install locations:
-//     - ~/.npm-global/bin/claude
-//     - /usr/local/bin/claude
-//     - ~/.local/bin/claude
-//     - ~/node_modules/.bin/claude
-//     - ~/.yarn/bin/claude
+// CLISearchPathsEnv is the environment variable listing where to look for
+// the CLI when neither CLIPath nor CLISearchPaths is set: paths separated by
+// the OS path list separator (":" on Unix), in the form CLISearchPaths takes.
+const CLISearchPathsEnv = "CLAUDE_AGENT_SDK_CLI_PATHS"
+
+// PathSearchEntry is the search path standing for the directories of the
+// PATH environment variable.
+const PathSearchEntry = "$PATH"
+
+// DefaultCLISearchPaths are the places searched for the CLI by default, in
+// order:
+//  1. PATH
+//  2. Default Claude installation location (new in CLI 2.0+)
+//  3. Common npm/yarn global install locations
+var DefaultCLISearchPaths = []string{
+	PathSearchEntry,
+	"~/.claude/local/claude", // Default location (CLI 2.0+)
+	"~/.npm-global/bin/claude",
+	"/usr/local/bin/claude",
+	"~/.local/bin/claude",
+	"~/node_modules/.bin/claude",
+	"~/.yarn/bin/claude",
+}
+
+// CLISearchPaths returns where to look for the CLI: paths if there are any,
+// else the paths listed in CLAUDE_AGENT_SDK_CLI_PATHS, else
+// DefaultCLISearchPaths.
+func CLISearchPaths(paths []string) []string {
+	if len(paths) > 0 {
+		return paths
+	}
+	if env := os.Getenv(CLISearchPathsEnv); env != "" {
+		var fromEnv []string
+		for _, path := range filepath.SplitList(env) {
+			if path != "" {
+				fromEnv = append(fromEnv, path)
+			}
+		}
+		if len(fromEnv) > 0 {
+			return fromEnv
+		}
+	}
+	return DefaultCLISearchPaths
+}
+
+// FindCLI searches for Claude Code CLI binary in the locations listed in
+// CLAUDE_AGENT_SDK_CLI_PATHS or, if it is not set, DefaultCLISearchPaths.
 //
 // After finding the CLI, it checks the version to ensure it meets minimum requirements
 // (unless CLAUDE_AGENT_SDK_SKIP_VERSION_CHECK is set).
 //
 // Returns the path to the CLI binary or a CLINotFoundError if not found.
 func FindCLI() (string, error) {
-	// First, try to find in PATH
-	if cliPath, err := exec.LookPath("claude"); err == nil {
+	return FindCLIIn(nil)
+}
+
+// FindCLIIn is FindCLI searching the given paths, in order, as
+// ClaudeAgentOptions.CLISearchPaths does. No paths searches the locations
+// FindCLI does.
+func FindCLIIn(paths []string) (string, error) {
+	for _, path := range CLISearchPaths(paths) {
+		candidates := searchPath(path, false)
+		if len(candidates) == 0 {
+			continue
+		}
 		// Check version before returning
-		if err := CheckCLIVersion(cliPath); err != nil {
+		if err := CheckCLIVersion(candidates[0]); err != nil {
 			return "", err
 		}
-		return cliPath, nil
-	}
-
-	// Try common install locations
-	locations := []string{
-		"~/.claude/local/claude", // Default location (CLI 2.0+)
-		"~/.npm-global/bin/claude",
-		"/usr/local/bin/claude",
-		"~/.local/bin/claude",
-		"~/node_modules/.bin/claude",
-		"~/.yarn/bin/claude",
-	}
-
-	for _, location := range locations {
-		expandedPath := expandHome(location)
-		if _, err := os.Stat(expandedPath); err == nil {
-			// Check version before returning
-			if err := CheckCLIVersion(expandedPath); err != nil {
-				return "", err
-			}
-			return expandedPath, nil
-		}
+		return candidates[0], nil
 	}
 
 	// Not found anywhere
@@ -68,6 +93,72 @@ func FindCLI() (string, error) {
 	)
 }
 
+// DiscoverCLI returns every CLI binary in the given search paths, or in the
+// locations FindCLI searches if there are none, in search order and with
+// their versions, for choosing among several installations such as nvm,
+// Homebrew and corporate ones. A binary reachable from several paths, such
+// as through a symlink, is listed once. Versions are determined even when
+// CLAUDE_AGENT_SDK_SKIP_VERSION_CHECK is set.
+func DiscoverCLI(paths ...string) []types.CLIInstallation {
+	var installations []types.CLIInstallation
+	seen := make(map[string]bool)
+	for _, path := range CLISearchPaths(paths) {
+		for _, candidate := range searchPath(path, true) {
+			key := candidate
+			if resolved, err := filepath.EvalSymlinks(candidate); err == nil {
+				key = resolved
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			installation := types.CLIInstallation{Path: candidate}
+			if version, err := GetCLIVersion(candidate); err != nil {
+				installation.Err = err
+			} else {
+				installation.Version = types.CLIVersion{Major: version.Major, Minor: version.Minor, Patch: version.Patch}
+			}
+			installations = append(installations, installation)
+		}
+	}
+	return installations
+}
+
+// searchPath returns the CLI binaries a search path holds: the binary
+// itself, a "claude" binary in a directory, or those in the PATH
+// directories, only the first of which unless all is set.
+func searchPath(path string, all bool) []string {
+	if path == PathSearchEntry {
+		if !all {
+			if cliPath, err := exec.LookPath("claude"); err == nil {
+				return []string{cliPath}
+			}
+			return nil
+		}
+		var found []string
+		for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+			if dir != "" {
+				found = append(found, searchPath(dir, false)...)
+			}
+		}
+		return found
+	}
+
+	expandedPath := expandHome(path)
+	info, err := os.Stat(expandedPath)
+	if err != nil {
+		return nil
+	}
+	if info.IsDir() {
+		expandedPath = filepath.Join(expandedPath, "claude")
+		if info, err = os.Stat(expandedPath); err != nil || info.IsDir() {
+			return nil
+		}
+	}
+	return []string{expandedPath}
+}
+
 // expandHome expands the ~ prefix in a path to the user's home directory.
 // If the path does not start with ~, it is returned unchanged.
 // If the home directory cannot be determined, the path is returned unchanged.
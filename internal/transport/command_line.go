@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"github.com/M1n9X/claude-agent-sdk-go/internal/log"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+func init() {
+	types.RegisterCommandLine(CommandLine)
+}
+
+// CommandLine returns the flags and the environment variables that Connect
+// would pass to the CLI for the options, without starting it or writing
+// the MCP configuration file. Values are not redacted.
+func CommandLine(options *types.ClaudeAgentOptions) (args, env []string) {
+	resumeID := ""
+	if options != nil && options.Resume != nil {
+		resumeID = *options.Resume
+	}
+	var customEnv map[string]string
+	if options != nil {
+		customEnv = options.Env
+	}
+	t := NewSubprocessCLITransport("claude", "", customEnv, log.NewLogger(false), resumeID, options)
+	t.describing = true
+	return t.buildCommandArgs(), t.buildEnv()
+}
@@ -0,0 +1,94 @@
+package transport
+
+import (
+	"regexp"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// diagnosticPattern is a known kind of stderr line of the CLI.
+type diagnosticPattern struct {
+	kind     types.DiagnosticKind
+	severity types.DiagnosticSeverity
+	pattern  *regexp.Regexp
+	server   int // The submatch naming the MCP server, or 0
+}
+
+// diagnosticPatterns are the known stderr lines, most specific first: the
+// first match wins.
+var diagnosticPatterns = []diagnosticPattern{
+	{
+		kind:     types.DiagnosticAuthFailure,
+		severity: types.DiagnosticError,
+		pattern:  regexp.MustCompile(`(?i)invalid (x-)?api[ _-]?key|authentication_error|authentication failed|\b401\b.*unauthori[sz]ed|oauth token (has )?expired|not logged in|please run /login`),
+	},
+	{
+		kind:     types.DiagnosticNodeError,
+		severity: types.DiagnosticFatal,
+		pattern:  regexp.MustCompile(`(?i)FATAL ERROR:|heap out of memory|node(\.js)? (version )?v?\d+(\.\d+)*.*\b(is not supported|unsupported|or (newer|higher|later) is required)`),
+	},
+	{
+		kind:     types.DiagnosticNodeError,
+		severity: types.DiagnosticWarning,
+		pattern:  regexp.MustCompile(`^\s*\(node:\d+\) \w*Warning:`),
+	},
+	{
+		kind:     types.DiagnosticNodeError,
+		severity: types.DiagnosticError,
+		pattern:  regexp.MustCompile(`^\s*(node:internal|Uncaught\b|(Syntax|Type|Reference|Range)Error:|Error: Cannot find module|\[?UnhandledPromiseRejection)`),
+	},
+	{
+		kind:     types.DiagnosticMCPStartupFailure,
+		severity: types.DiagnosticError,
+		pattern:  regexp.MustCompile(`(?i)failed to (?:start|connect to) MCP server\s+["']?([\w.@/-]+)`),
+		server:   1,
+	},
+	{
+		kind:     types.DiagnosticMCPStartupFailure,
+		severity: types.DiagnosticError,
+		pattern:  regexp.MustCompile(`(?i)MCP server\s+["']?([\w.@/-]+?)["']?:?\s.*\b(failed|error|timed out|connection closed)\b`),
+		server:   1,
+	},
+	{
+		kind:     types.DiagnosticVersionWarning,
+		severity: types.DiagnosticInfo,
+		pattern:  regexp.MustCompile(`(?i)\bupdate available\b|\bnew version\b.*\bavailable\b`),
+	},
+	{
+		kind:     types.DiagnosticVersionWarning,
+		severity: types.DiagnosticWarning,
+		pattern:  regexp.MustCompile(`(?i)\bversion\b.*\b(outdated|deprecated|unsupported|not supported|mismatch|incompatible)\b`),
+	},
+	{
+		kind:     types.DiagnosticCLIError,
+		severity: types.DiagnosticFatal,
+		pattern:  regexp.MustCompile(`(?i)^\s*fatal\b`),
+	},
+	{
+		kind:     types.DiagnosticCLIError,
+		severity: types.DiagnosticError,
+		pattern:  regexp.MustCompile(`(?i)^\s*error\b`),
+	},
+	{
+		kind:     types.DiagnosticCLIWarning,
+		severity: types.DiagnosticWarning,
+		pattern:  regexp.MustCompile(`(?i)^\s*warn(ing)?\b`),
+	},
+}
+
+// parseDiagnostic returns the diagnostic of a known stderr line, or false.
+// Session-not-found lines are handled by parseStderrError.
+func parseDiagnostic(line string) (types.Diagnostic, bool) {
+	for _, p := range diagnosticPatterns {
+		match := p.pattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		diagnostic := types.Diagnostic{Kind: p.kind, Severity: p.severity, Line: line}
+		if p.server > 0 {
+			diagnostic.MCPServer = match[p.server]
+		}
+		return diagnostic, true
+	}
+	return types.Diagnostic{}, false
+}
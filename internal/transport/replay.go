@@ -0,0 +1,310 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/internal/log"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// ReplayTransport implements Transport by replaying a wire dump recorded
+// with ClaudeAgentOptions.WithWireDump. Inbound lines are delivered as if the
+// CLI sent them; at each outbound line, the replay waits for the SDK to
+// write its counterpart.
+type ReplayTransport struct {
+	replay  types.Replay
+	logger  *log.Logger
+	options *types.ClaudeAgentOptions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{} // Closed when the replay ends
+
+	// Message streaming
+	messages chan types.Message
+
+	mu         sync.Mutex
+	records    []types.WireRecord
+	outbound   []types.WireRecord // The outbound records, in order
+	written    int                // Lines written by the SDK
+	writes     chan struct{}      // Signalled when the SDK writes a line
+	requestIDs map[string]string  // Recorded IDs of the SDK's control requests, to the IDs it sends now
+	err        error
+	ready      bool
+}
+
+// NewReplayTransport creates a transport replaying a wire dump.
+func NewReplayTransport(replay types.Replay, logger *log.Logger, options *types.ClaudeAgentOptions) *ReplayTransport {
+	capacity := 10
+	if options != nil && options.MessageChannelCapacity != nil {
+		capacity = *options.MessageChannelCapacity
+	}
+	return &ReplayTransport{
+		replay:     replay,
+		logger:     logger,
+		options:    options,
+		messages:   make(chan types.Message, capacity),
+		writes:     make(chan struct{}, 1),
+		requestIDs: make(map[string]string),
+	}
+}
+
+// Connect reads the wire dump and starts replaying it.
+func (t *ReplayTransport) Connect(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done != nil {
+		return nil // Already connected
+	}
+
+	records, err := t.readRecords()
+	if err != nil {
+		return err
+	}
+	t.records = records
+	for _, record := range records {
+		if record.Direction == types.WireOutbound {
+			t.outbound = append(t.outbound, record)
+		}
+	}
+	t.logger.Debug("Replaying %d lines from %s", len(records), t.replay.Path)
+
+	t.ctx, t.cancel = context.WithCancel(ctx)
+	t.done = make(chan struct{})
+	go t.play(t.ctx)
+
+	t.ready = true
+	return nil
+}
+
+// readRecords reads the records of the wire dump.
+func (t *ReplayTransport) readRecords() ([]types.WireRecord, error) {
+	file, err := os.Open(t.replay.Path)
+	if err != nil {
+		return nil, types.NewCLIConnectionErrorWithCause("failed to open wire dump", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	// Records wrap the lines of the CLI with their time and direction
+	bufferSize := DefaultMaxBufferSize
+	if t.options != nil && t.options.MaxBufferSize != nil && *t.options.MaxBufferSize > 0 {
+		bufferSize = *t.options.MaxBufferSize
+	}
+	reader := NewJSONLineReaderWithSize(file, 2*bufferSize)
+
+	var records []types.WireRecord
+	for n := 1; ; n++ {
+		line, err := reader.ReadLine()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, types.NewCLIConnectionErrorWithCause("failed to read wire dump", err)
+		}
+		if len(line) == 0 {
+			continue
+		}
+		var record types.WireRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, types.NewCLIConnectionErrorWithCause(fmt.Sprintf("invalid record on line %d of wire dump", n), err)
+		}
+		records = append(records, record)
+	}
+}
+
+// play delivers the inbound records, waiting for the SDK's writes at the
+// outbound ones. It closes the messages channel when the recording ends.
+func (t *ReplayTransport) play(ctx context.Context) {
+	defer close(t.done)
+	defer close(t.messages)
+
+	var previous time.Time // Time of the previous record, for the original timing
+	outbound := 0
+	for _, record := range t.records {
+		switch record.Direction {
+		case types.WireOutbound:
+			outbound++
+			if !t.waitWritten(ctx, outbound) {
+				return
+			}
+
+		case types.WireInbound:
+			if t.replay.Timing == types.ReplayOriginalTiming && !previous.IsZero() {
+				if !sleep(ctx, record.Time.Sub(previous)) {
+					return
+				}
+			}
+			if record.Message == nil {
+				t.logger.Debug("Skipping recorded line that is not JSON: %s", record.Raw)
+				break
+			}
+			msg, err := types.UnmarshalMessage(t.mapRequestID(record.Message))
+			if err != nil {
+				t.logger.Warning("Failed to parse recorded message: %v", err)
+				t.OnError(err)
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case t.messages <- msg:
+			}
+		}
+		previous = record.Time
+	}
+	t.logger.Debug("Replay finished")
+}
+
+// waitWritten waits for the SDK to have written n lines. It returns false
+// if ctx is cancelled first.
+func (t *ReplayTransport) waitWritten(ctx context.Context, n int) bool {
+	for {
+		t.mu.Lock()
+		written := t.written
+		t.mu.Unlock()
+		if written >= n {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-t.writes:
+		}
+	}
+}
+
+// sleep waits for d, returning false if ctx is cancelled first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// controlLine holds the request IDs of control requests and responses.
+type controlLine struct {
+	Type      string `json:"type"`
+	RequestID string `json:"request_id"`
+	Response  struct {
+		RequestID string `json:"request_id"`
+	} `json:"response"`
+}
+
+// mapRequestID returns a recorded control response to a control request of
+// the SDK with the ID the SDK sent now.
+func (t *ReplayTransport) mapRequestID(message json.RawMessage) []byte {
+	var line controlLine
+	if json.Unmarshal(message, &line) != nil || line.Type != "control_response" {
+		return message
+	}
+	t.mu.Lock()
+	id, ok := t.requestIDs[line.Response.RequestID]
+	t.mu.Unlock()
+	if !ok {
+		return message
+	}
+
+	var value map[string]interface{}
+	if json.Unmarshal(message, &value) != nil {
+		return message
+	}
+	if response, ok := value["response"].(map[string]interface{}); ok {
+		response["request_id"] = id
+	}
+	mapped, err := json.Marshal(value)
+	if err != nil {
+		return message
+	}
+	return mapped
+}
+
+// Write counts a line written by the SDK, letting the replay past the
+// outbound record it stands for.
+func (t *ReplayTransport) Write(ctx context.Context, data string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.ready {
+		return types.NewCLIConnectionError("transport is not ready for writing")
+	}
+
+	// Answer the SDK's control requests with the responses to the recorded ones
+	if t.written < len(t.outbound) {
+		var sent, recorded controlLine
+		if json.Unmarshal([]byte(data), &sent) == nil && sent.Type == "control_request" &&
+			json.Unmarshal(t.outbound[t.written].Message, &recorded) == nil && recorded.Type == "control_request" {
+			t.requestIDs[recorded.RequestID] = sent.RequestID
+		}
+	}
+	t.written++
+
+	select {
+	case t.writes <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// ReadMessages returns a channel of the recorded messages of the CLI.
+// The channel is closed when the recording ends.
+func (t *ReplayTransport) ReadMessages(ctx context.Context) <-chan types.Message {
+	return t.messages
+}
+
+// OnError stores the first error of the replay.
+func (t *ReplayTransport) OnError(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+// IsReady returns true if the transport is replaying.
+func (t *ReplayTransport) IsReady() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.ready
+}
+
+// GetError returns the first error of the replay.
+func (t *ReplayTransport) GetError() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.err
+}
+
+// Close stops the replay.
+func (t *ReplayTransport) Close(ctx context.Context) error {
+	t.mu.Lock()
+	t.ready = false
+	cancel, done := t.cancel, t.done
+	t.cancel = nil
+	t.mu.Unlock()
+
+	if cancel == nil {
+		return nil // Not connected
+	}
+	cancel()
+	<-done
+	return nil
+}
@@ -0,0 +1,92 @@
+package transport
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/internal/log"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// replayDump is a wire dump of an initialize request and one query.
+const replayDump = `{"time":"2026-01-02T10:00:00Z","direction":"outbound","message":{"type":"control_request","request_id":"req_1","request":{"subtype":"initialize"}}}
+{"time":"2026-01-02T10:00:00.05Z","direction":"inbound","message":{"type":"control_response","response":{"subtype":"success","request_id":"req_1","response":{}}}}
+{"time":"2026-01-02T10:00:01Z","direction":"outbound","message":{"type":"user","message":{"role":"user","content":"hi"}}}
+{"time":"2026-01-02T10:00:01.1Z","direction":"inbound","raw":"not json"}
+{"time":"2026-01-02T10:00:01.1Z","direction":"inbound","message":{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"hello"}]}}}
+{"time":"2026-01-02T10:00:01.2Z","direction":"inbound","message":{"type":"result","subtype":"success","session_id":"s1","num_turns":1}}
+`
+
+// writeReplayDump writes replayDump to a file.
+func writeReplayDump(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "wire.jsonl")
+	if err := os.WriteFile(path, []byte(replayDump), 0600); err != nil {
+		t.Fatalf("Failed to write wire dump: %v", err)
+	}
+	return path
+}
+
+// TestReplayTransport tests that recorded messages wait for the SDK's
+// writes, and that control responses carry the IDs of the SDK's requests.
+func TestReplayTransport(t *testing.T) {
+	replay := types.Replay{Path: writeReplayDump(t), Timing: types.ReplayOriginalTiming}
+	transport := NewReplayTransport(replay, log.NewLogger(false), nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := transport.Connect(ctx); err != nil {
+		t.Fatalf("Connect() unexpected error: %v", err)
+	}
+	defer func() {
+		_ = transport.Close(ctx)
+	}()
+	messages := transport.ReadMessages(ctx)
+
+	select {
+	case msg := <-messages:
+		t.Fatalf("received %T before the SDK wrote the recorded request", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	start := time.Now()
+	if err := transport.Write(ctx, `{"type":"control_request","request_id":"req_7","request":{"subtype":"initialize"}}`); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+	response, ok := (<-messages).(*types.SystemMessage)
+	if !ok || response.Response["request_id"] != "req_7" {
+		t.Fatalf("response = %+v, want the recorded control response for req_7", response)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("control response after %v, want the recorded 50ms delay", elapsed)
+	}
+
+	if err := transport.Write(ctx, `{"type":"user","message":{"role":"user","content":"hi"}}`); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+	var got []string
+	for msg := range messages {
+		got = append(got, msg.GetMessageType())
+	}
+	if strings.Join(got, ",") != "assistant,result" {
+		t.Errorf("messages = %v, want assistant and result", got)
+	}
+}
+
+// TestReplayTransportInvalidDump tests that a file that is not a wire dump
+// fails to connect.
+func TestReplayTransportInvalidDump(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wire.jsonl")
+	if err := os.WriteFile(path, []byte("not a record\n"), 0600); err != nil {
+		t.Fatalf("Failed to write wire dump: %v", err)
+	}
+	for _, replay := range []types.Replay{{Path: path}, {Path: path + ".missing"}} {
+		transport := NewReplayTransport(replay, log.NewLogger(false), nil)
+		if err := transport.Connect(context.Background()); !types.IsCLIConnectionError(err) {
+			t.Errorf("Connect(%s) error = %v, want a CLIConnectionError", replay.Path, err)
+		}
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/M1n9X/claude-agent-sdk-go/internal/log"
 	"github.com/M1n9X/claude-agent-sdk-go/types"
@@ -46,6 +48,9 @@ type SubprocessCLITransport struct {
 	// Writer for stdin
 	writer *JSONLineWriter
 
+	// Records the lines exchanged with the CLI, if WireDump is set
+	dump *wireDump
+
 	// MCP configuration file paths (will be cleaned up on Close)
 	mcpConfigFiles []string
 
@@ -53,6 +58,23 @@ type SubprocessCLITransport struct {
 	mu    sync.Mutex
 	err   error
 	ready bool
+
+	// Process exit, detected once the stdout and stderr readers are done
+	readers sync.WaitGroup
+	exited  chan struct{} // Closed when the process has exited
+	exitErr error         // The error of cmd.Wait, set before exited is closed
+	closing atomic.Bool   // Close was called
+
+	// Time from starting the process to its first output
+	started time.Time
+	spawn   atomic.Int64 // Nanoseconds; 0 until the first output
+
+	// CLI version, detected on first use
+	versionOnce sync.Once
+	version     types.CLIVersion
+
+	// Building a command line for CommandLine, without writing files
+	describing bool
 }
 
 // NewSubprocessCLITransport creates a new transport instance.
@@ -97,6 +119,17 @@ func (t *SubprocessCLITransport) Connect(ctx context.Context) error {
 		return nil // Already connected
 	}
 
+	// Refuse options the CLI does not support, rather than passing flags it rejects
+	if required := t.options.RequiredCapabilities(); len(required) > 0 || (t.options != nil && t.options.MinCLIVersion != nil) {
+		version := t.CLIVersion()
+		if !version.IsZero() {
+			t.logger.Debug("Claude CLI %s supports %v", version, version.Capabilities())
+		}
+		if err := types.CheckCapabilities(t.options, version); err != nil {
+			return err
+		}
+	}
+
 	t.logger.Debug("Starting Claude CLI subprocess: %s", t.cliPath)
 
 	// Create cancellable context
@@ -116,43 +149,8 @@ func (t *SubprocessCLITransport) Connect(ctx context.Context) error {
 		t.cmd.Dir = t.cwd
 	}
 
-	// Set up environment variables
-	// Start with current environment
-	t.cmd.Env = os.Environ()
-
-	// Add SDK-specific variables
-	t.cmd.Env = append(t.cmd.Env, "CLAUDE_CODE_ENTRYPOINT=agent")
-	t.cmd.Env = append(t.cmd.Env, fmt.Sprintf("CLAUDE_AGENT_SDK_VERSION=%s", SDKVersion))
-
-	// Add model environment variable if specified in options (ANTHROPIC_MODEL)
-	// This is critical - both CLI flag and env var should be set for maximum compatibility
-	if t.options != nil && t.options.Model != nil {
-		t.cmd.Env = append(t.cmd.Env, fmt.Sprintf("ANTHROPIC_MODEL=%s", *t.options.Model))
-		t.logger.Debug("Setting ANTHROPIC_MODEL environment variable: %s", *t.options.Model)
-	} else {
-		t.logger.Debug("ANTHROPIC_MODEL not set (using CLI default)")
-	}
-
-	// Add base URL environment variable if specified in options (ANTHROPIC_BASE_URL)
-	// If not set, Claude CLI will use default Anthropic API endpoint
-	if t.options != nil && t.options.BaseURL != nil {
-		t.cmd.Env = append(t.cmd.Env, fmt.Sprintf("ANTHROPIC_BASE_URL=%s", *t.options.BaseURL))
-		t.logger.Debug("Setting ANTHROPIC_BASE_URL environment variable: %s", *t.options.BaseURL)
-	} else {
-		t.logger.Debug("ANTHROPIC_BASE_URL not set (using default Anthropic API)")
-	}
-
-	// Add custom environment variables (these can override the above if needed)
-	for key, value := range t.env {
-		t.cmd.Env = append(t.cmd.Env, fmt.Sprintf("%s=%s", key, value))
-		t.logger.Debug("Setting custom environment variable: %s=%s", key, value)
-	}
-
-	// Enable file checkpointing support when requested
-	if t.options != nil && t.options.EnableFileCheckpointing {
-		t.cmd.Env = append(t.cmd.Env, "CLAUDE_CODE_ENABLE_SDK_FILE_CHECKPOINTING=true")
-		t.logger.Debug("Enabling file checkpointing via CLAUDE_CODE_ENABLE_SDK_FILE_CHECKPOINTING")
-	}
+	// Set up environment variables: the current environment and the SDK's own
+	t.cmd.Env = append(os.Environ(), t.buildEnv()...)
 
 	// Set up pipes
 	var err error
@@ -172,21 +170,41 @@ func (t *SubprocessCLITransport) Connect(ctx context.Context) error {
 		return types.NewCLIConnectionErrorWithCause("failed to create stderr pipe", err)
 	}
 
+	if t.options != nil && t.options.WireDump != "" {
+		if t.dump, err = openWireDump(t.options, t.logger); err != nil {
+			return types.NewCLIConnectionErrorWithCause("failed to open wire dump file", err)
+		}
+	}
+
 	// Start the process
+	t.started = time.Now()
 	if err := t.cmd.Start(); err != nil {
 		t.logger.Error("Failed to start subprocess: %v", err)
+		t.dump.close()
 		return types.NewCLIConnectionErrorWithCause("failed to start subprocess", err)
 	}
 	t.logger.Debug("CLI subprocess started successfully (PID: %d)", t.cmd.Process.Pid)
+	t.options.NotifyLifecycle(&types.CLISpawnedEvent{PID: t.cmd.Process.Pid, Path: t.cliPath, CorrelationID: t.logger.CorrelationID()})
 
 	// Create JSON line writer for stdin
 	t.writer = NewJSONLineWriter(t.stdin)
 
 	// Launch message reader loop in goroutine
-	go t.messageReaderLoop(t.ctx)
+	t.readers.Add(2)
+	go func() {
+		defer t.readers.Done()
+		t.messageReaderLoop(t.ctx)
+	}()
 
 	// Launch stderr reader for debugging
-	go t.readStderr(t.ctx)
+	go func() {
+		defer t.readers.Done()
+		t.readStderr(t.ctx)
+	}()
+
+	// Wait for the process to exit
+	t.exited = make(chan struct{})
+	go t.waitProcess(t.ctx, t.cmd)
 
 	// Mark as ready
 	t.ready = true
@@ -195,6 +213,31 @@ func (t *SubprocessCLITransport) Connect(ctx context.Context) error {
 	return nil
 }
 
+// CLIVersion returns the version of the CLI, running "claude --version" on
+// first use. It returns the zero version if the version cannot be
+// determined or CLAUDE_AGENT_SDK_SKIP_VERSION_CHECK is set.
+func (t *SubprocessCLITransport) CLIVersion() types.CLIVersion {
+	t.versionOnce.Do(func() {
+		if os.Getenv("CLAUDE_AGENT_SDK_SKIP_VERSION_CHECK") != "" {
+			return
+		}
+		version, err := GetCLIVersion(t.cliPath)
+		if err != nil {
+			t.logger.Debug("Could not determine CLI version: %v", err)
+			return
+		}
+		t.version = types.CLIVersion{Major: version.Major, Minor: version.Minor, Patch: version.Patch}
+		t.logger.Debug("Claude CLI version: %s", t.version)
+	})
+	return t.version
+}
+
+// SpawnDuration returns the time from starting the CLI process to its first
+// output, or 0 until the CLI has written a line.
+func (t *SubprocessCLITransport) SpawnDuration() time.Duration {
+	return time.Duration(t.spawn.Load())
+}
+
 // messageReaderLoop reads JSON lines from stdout and parses them into messages.
 // It runs in a goroutine and sends messages to the messages channel.
 // It respects context cancellation and closes the messages channel when done.
@@ -226,7 +269,7 @@ func (t *SubprocessCLITransport) messageReaderLoop(ctx context.Context) {
 			// Store error and return
 			t.OnError(types.NewJSONDecodeErrorWithCause(
 				"failed to read JSON line from subprocess",
-				string(line),
+				t.options.RedactCredentials(string(line)),
 				err,
 			))
 			return
@@ -236,13 +279,23 @@ func (t *SubprocessCLITransport) messageReaderLoop(ctx context.Context) {
 		if len(line) == 0 {
 			continue
 		}
+		if t.spawn.Load() == 0 {
+			t.spawn.Store(int64(time.Since(t.started)))
+		}
+		t.dump.record(types.WireInbound, line)
 
 		// Parse JSON into message
-		msg, err := types.UnmarshalMessage(line)
+		msg, err := t.parseMessage(line)
 		if err != nil {
+			if t.options != nil && t.options.StrictParsing {
+				t.logger.Error("Failed to parse message from CLI in strict mode: %v", err)
+				t.OnError(err)
+				return
+			}
 			t.logger.Warning("Failed to parse message from CLI: %v", err)
 			// Store parse error but continue reading
 			t.OnError(err)
+			t.options.NotifyLifecycle(&types.ParseErrorSkippedEvent{Err: err, CorrelationID: t.logger.CorrelationID()})
 			continue
 		}
 
@@ -258,6 +311,33 @@ func (t *SubprocessCLITransport) messageReaderLoop(ctx context.Context) {
 	}
 }
 
+// parseMessage parses a JSON line from the CLI, pooling stream events if
+// enabled, reporting data the SDK does not understand to the parse issue
+// handler and, in strict mode, failing on it.
+func (t *SubprocessCLITransport) parseMessage(line []byte) (types.Message, error) {
+	if t.options == nil {
+		return types.UnmarshalMessage(line)
+	}
+	unmarshal := types.UnmarshalMessage
+	if t.options.MessagePooling {
+		unmarshal = types.UnmarshalMessagePooled
+	}
+	if !t.options.StrictParsing && t.options.OnParseIssue == nil {
+		return unmarshal(line)
+	}
+
+	issues := types.FindParseIssues(line)
+	if t.options.OnParseIssue != nil {
+		for _, issue := range issues {
+			t.options.OnParseIssue(issue)
+		}
+	}
+	if t.options.StrictParsing && len(issues) > 0 {
+		return nil, types.NewMessageParseErrorWithCause("unexpected data in message", issues[0].MessageType, issues)
+	}
+	return unmarshal(line)
+}
+
 // Write sends a JSON message to the subprocess stdin.
 // The data should be a complete JSON string (newline will be added automatically).
 func (t *SubprocessCLITransport) Write(ctx context.Context, data string) error {
@@ -273,12 +353,14 @@ func (t *SubprocessCLITransport) Write(ctx context.Context, data string) error {
 	}
 
 	t.logger.Debug("Sending message to CLI stdin")
+	t.dump.record(types.WireOutbound, []byte(data))
 
 	// Write JSON line (includes newline and flush)
 	if err := t.writer.WriteLine(data); err != nil {
 		t.ready = false
 		t.err = types.NewCLIConnectionErrorWithCause("failed to write to subprocess stdin", err)
 		t.logger.Error("Failed to write to CLI stdin: %v", err)
+		t.reportError(t.err)
 		return t.err
 	}
 
@@ -291,6 +373,80 @@ func (t *SubprocessCLITransport) ReadMessages(ctx context.Context) <-chan types.
 	return t.messages
 }
 
+// buildEnv returns the environment variables the SDK sets for the CLI, as
+// "KEY=value" entries added to the current environment.
+func (t *SubprocessCLITransport) buildEnv() []string {
+	var env []string
+
+	// Add SDK-specific variables
+	env = append(env, "CLAUDE_CODE_ENTRYPOINT=agent")
+	env = append(env, fmt.Sprintf("CLAUDE_AGENT_SDK_VERSION=%s", SDKVersion))
+
+	// Add model environment variable if specified in options (ANTHROPIC_MODEL)
+	// This is critical - both CLI flag and env var should be set for maximum compatibility
+	if t.options != nil && t.options.Model != nil {
+		env = append(env, fmt.Sprintf("ANTHROPIC_MODEL=%s", *t.options.Model))
+		t.logger.Debug("Setting ANTHROPIC_MODEL environment variable: %s", *t.options.Model)
+	} else {
+		t.logger.Debug("ANTHROPIC_MODEL not set (using CLI default)")
+	}
+
+	// Add base URL environment variable if specified in options (ANTHROPIC_BASE_URL)
+	// If not set, Claude CLI will use default Anthropic API endpoint
+	if t.options != nil && t.options.BaseURL != nil {
+		env = append(env, fmt.Sprintf("ANTHROPIC_BASE_URL=%s", *t.options.BaseURL))
+		t.logger.Debug("Setting ANTHROPIC_BASE_URL environment variable: %s", *t.options.BaseURL)
+	} else {
+		t.logger.Debug("ANTHROPIC_BASE_URL not set (using default Anthropic API)")
+	}
+
+	// Select Amazon Bedrock or Google Vertex AI if specified in options
+	if t.options != nil && t.options.Provider != nil {
+		for key, value := range t.options.Provider.Env() {
+			env = append(env, fmt.Sprintf("%s=%s", key, value))
+		}
+		t.logger.Debug("Using cloud provider %s in %s", t.options.Provider.Provider, t.options.Provider.Region)
+	}
+
+	// Apply the context compaction options
+	if t.options != nil {
+		for key, value := range t.options.CompactionEnv() {
+			env = append(env, fmt.Sprintf("%s=%s", key, value))
+			t.logger.Debug("Setting compaction environment variable: %s=%s", key, value)
+		}
+	}
+
+	// Route the CLI's traffic through a proxy if specified in options
+	if t.options != nil && t.options.Proxy != nil {
+		for _, key := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+			env = append(env, fmt.Sprintf("%s=%s", key, *t.options.Proxy))
+		}
+		t.logger.Debug("Setting HTTP(S)_PROXY environment variables: %s", *t.options.Proxy)
+	}
+
+	// Add credentials if specified in options
+	if t.options != nil {
+		for key, value := range t.options.CredentialEnv() {
+			env = append(env, fmt.Sprintf("%s=%s", key, value))
+			t.logger.Debug("Setting credential environment variable: %s", key)
+		}
+	}
+
+	// Add custom environment variables (these can override the above if needed)
+	for key, value := range t.env {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+		t.logger.Debug("Setting custom environment variable: %s=%s", key, types.RedactEnvValue(key, value))
+	}
+
+	// Enable file checkpointing support when requested
+	if t.options != nil && t.options.EnableFileCheckpointing {
+		env = append(env, "CLAUDE_CODE_ENABLE_SDK_FILE_CHECKPOINTING=true")
+		t.logger.Debug("Enabling file checkpointing via CLAUDE_CODE_ENABLE_SDK_FILE_CHECKPOINTING")
+	}
+
+	return env
+}
+
 // buildCommandArgs builds the command line arguments for the CLI subprocess.
 // This is extracted into a separate method to allow for testing.
 func (t *SubprocessCLITransport) buildCommandArgs() []string {
@@ -397,6 +553,12 @@ func (t *SubprocessCLITransport) buildCommandArgs() []string {
 		t.logger.Debug("Forking resumed session to new session ID")
 	}
 
+	// Add --session-id flag if a session ID was chosen
+	if opts != nil && opts.SessionID != nil {
+		args = append(args, "--session-id", *opts.SessionID)
+		t.logger.Debug("Setting session ID: %s", *opts.SessionID)
+	}
+
 	// Add permission bypass flags if enabled
 	if opts != nil {
 		// Must set allow flag first (acts as safety switch)
@@ -413,13 +575,13 @@ func (t *SubprocessCLITransport) buildCommandArgs() []string {
 	}
 
 	// Allowed and disallowed tools
-	if opts != nil && len(opts.AllowedTools) > 0 {
-		args = append(args, "--allowedTools", strings.Join(opts.AllowedTools, ","))
-		t.logger.Debug("Setting allowed tools: %v", opts.AllowedTools)
+	if allowed := opts.EffectiveAllowedTools(); len(allowed) > 0 {
+		args = append(args, "--allowedTools", strings.Join(cliToolRules(allowed), ","))
+		t.logger.Debug("Setting allowed tools: %v", allowed)
 	}
 
 	if opts != nil && len(opts.DisallowedTools) > 0 {
-		args = append(args, "--disallowedTools", strings.Join(opts.DisallowedTools, ","))
+		args = append(args, "--disallowedTools", strings.Join(cliToolRules(opts.DisallowedTools), ","))
 		t.logger.Debug("Setting disallowed tools: %v", opts.DisallowedTools)
 	}
 
@@ -568,6 +730,15 @@ func (t *SubprocessCLITransport) buildCommandArgs() []string {
 	return args
 }
 
+// cliToolRules rewrites tool rules into the form understood by the CLI.
+func cliToolRules(rules []string) []string {
+	converted := make([]string, len(rules))
+	for i, rule := range rules {
+		converted[i] = types.CLIToolRule(rule)
+	}
+	return converted
+}
+
 // generateMcpConfigFile generates a temporary MCP configuration file for external MCP servers.
 // SDK MCP servers are handled in-process and do not need a config file.
 func (t *SubprocessCLITransport) generateMcpConfigFile() string {
@@ -634,6 +805,11 @@ func (t *SubprocessCLITransport) generateMcpConfigFile() string {
 		return ""
 	}
 
+	// CommandLine only shows where the file would be
+	if t.describing {
+		return filepath.Join(os.TempDir(), "claude-mcp-*.json")
+	}
+
 	// Create temp file
 	tmpFile, err := os.CreateTemp("", "claude-mcp-*.json")
 	if err != nil {
@@ -667,6 +843,8 @@ func (t *SubprocessCLITransport) Close(ctx context.Context) error {
 
 	t.logger.Debug("Closing CLI subprocess...")
 	t.ready = false
+	t.closing.Store(true)
+	defer t.dump.close() // Once the process has exited
 
 	// Cancel the context to stop goroutines
 	if t.cancel != nil {
@@ -686,23 +864,23 @@ func (t *SubprocessCLITransport) Close(ctx context.Context) error {
 	}
 	t.mcpConfigFiles = nil
 
-	// Wait for process to exit (with context timeout)
-	done := make(chan error, 1)
-	go func() {
-		done <- t.cmd.Wait()
-	}()
+	if t.exited == nil {
+		return nil // The process did not start
+	}
 
+	// Wait for process to exit (with context timeout)
 	select {
 	case <-ctx.Done():
 		// Timeout - kill the process
 		if t.cmd.Process != nil {
 			_ = t.cmd.Process.Kill()
 		}
-		<-done // Wait for Wait() to return
+		<-t.exited // Wait for Wait() to return
 		return types.NewProcessError("subprocess did not exit gracefully, killed")
 
-	case err := <-done:
+	case <-t.exited:
 		// Process exited
+		err := t.exitErr
 		// During normal shutdown, the subprocess may exit with non-zero codes
 		// which is expected behavior when stdin is closed, so we don't treat
 		// these as errors during the Close operation
@@ -721,9 +899,37 @@ func (t *SubprocessCLITransport) Close(ctx context.Context) error {
 	}
 }
 
+// waitProcess waits for the process to exit and reports it to the lifecycle
+// listener. cmd.Wait closes the pipes, so it is called once the readers
+// have drained them, or once ctx is cancelled and the process killed.
+func (t *SubprocessCLITransport) waitProcess(ctx context.Context, cmd *exec.Cmd) {
+	readersDone := make(chan struct{})
+	go func() {
+		t.readers.Wait()
+		close(readersDone)
+	}()
+	select {
+	case <-readersDone:
+	case <-ctx.Done():
+	}
+
+	err := cmd.Wait()
+	t.exitErr = err
+	close(t.exited)
+
+	event := &types.ProcessExitedEvent{PID: cmd.Process.Pid, Err: err, Closed: t.closing.Load(), CorrelationID: t.logger.CorrelationID()}
+	if cmd.ProcessState != nil {
+		event.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	t.logger.Debug("CLI subprocess exited with code %d", event.ExitCode)
+	t.options.NotifyLifecycle(event)
+}
+
 // OnError stores an error that occurred during transport operation.
 // This allows errors from the reading loop to be retrieved later.
 func (t *SubprocessCLITransport) OnError(err error) {
+	t.reportError(err)
+
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -732,6 +938,13 @@ func (t *SubprocessCLITransport) OnError(err error) {
 	}
 }
 
+// reportError reports an error to the metrics of the options, if any.
+func (t *SubprocessCLITransport) reportError(err error) {
+	if t.options != nil && t.options.Metrics != nil {
+		t.options.Metrics.TransportError(err)
+	}
+}
+
 // IsReady returns true if the transport is ready for communication.
 func (t *SubprocessCLITransport) IsReady() bool {
 	t.mu.Lock()
@@ -794,7 +1007,7 @@ func (t *SubprocessCLITransport) readStderr(ctx context.Context) {
 
 		// Log stderr output to file
 		if len(line) > 0 {
-			stderrText := string(line)
+			stderrText := t.options.RedactCredentials(string(line))
 			_, _ = fmt.Fprintf(logFile, "[Claude CLI stderr]: %s\n", stderrText)
 			_ = logFile.Sync() // Flush to disk immediately
 
@@ -805,7 +1018,10 @@ func (t *SubprocessCLITransport) readStderr(ctx context.Context) {
 }
 
 // parseStderrError parses stderr text for known error patterns and stores typed errors.
+// Known lines are reported as diagnostics, and errors as lifecycle events.
 func (t *SubprocessCLITransport) parseStderrError(stderrText string) {
+	correlationID := t.logger.CorrelationID()
+
 	// Check for "No conversation found with session ID:" error
 	if matched, sessionID := extractSessionNotFoundError(stderrText); matched {
 		// Create typed error
@@ -819,6 +1035,26 @@ func (t *SubprocessCLITransport) parseStderrError(stderrText string) {
 
 		// Log it
 		t.logger.Error("Claude session not found: %s", sessionID)
+		t.options.NotifyDiagnostic(types.Diagnostic{
+			Kind:          types.DiagnosticSessionNotFound,
+			Severity:      types.DiagnosticError,
+			Line:          stderrText,
+			Err:           err,
+			CorrelationID: correlationID,
+		})
+		t.options.NotifyLifecycle(&types.StderrErrorEvent{Line: stderrText, Err: err, CorrelationID: correlationID})
+		return
+	}
+
+	diagnostic, ok := parseDiagnostic(stderrText)
+	if !ok {
+		return
+	}
+	diagnostic.CorrelationID = correlationID
+	t.logger.Debug("CLI %s diagnostic (%s): %s", diagnostic.Severity, diagnostic.Kind, stderrText)
+	t.options.NotifyDiagnostic(diagnostic)
+	if diagnostic.Severity == types.DiagnosticError || diagnostic.Severity == types.DiagnosticFatal {
+		t.options.NotifyLifecycle(&types.StderrErrorEvent{Line: stderrText, CorrelationID: correlationID})
 	}
 }
 
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -92,6 +94,78 @@ func TestFindCLI(t *testing.T) {
 	}
 }
 
+// TestFindCLIIn tests that configured and environment search paths replace
+// the default locations, in order.
+func TestFindCLIIn(t *testing.T) {
+	t.Setenv("CLAUDE_AGENT_SDK_SKIP_VERSION_CHECK", "1")
+
+	first, second, empty := t.TempDir(), t.TempDir(), t.TempDir()
+	for _, dir := range []string{first, second} {
+		if err := os.WriteFile(filepath.Join(dir, "claude"), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatalf("Failed to create mock binary: %v", err)
+		}
+	}
+
+	path, err := FindCLIIn([]string{empty, filepath.Join(second, "claude"), first})
+	if err != nil || path != filepath.Join(second, "claude") {
+		t.Errorf("FindCLIIn() = %q, %v, want the binary of the first path holding one", path, err)
+	}
+
+	t.Setenv(CLISearchPathsEnv, empty+string(os.PathListSeparator)+first)
+	if path, err := FindCLI(); err != nil || path != filepath.Join(first, "claude") {
+		t.Errorf("FindCLI() = %q, %v, want the binary in %s", path, err, CLISearchPathsEnv)
+	}
+	if path, err := FindCLIIn([]string{second}); err != nil || path != filepath.Join(second, "claude") {
+		t.Errorf("FindCLIIn() = %q, %v, want configured paths to take precedence over %s", path, err, CLISearchPathsEnv)
+	}
+
+	if _, err := FindCLIIn([]string{empty}); !types.IsCLINotFoundError(err) {
+		t.Errorf("FindCLIIn() error = %v, want a CLINotFoundError", err)
+	}
+}
+
+// TestDiscoverCLI tests that every CLI in the search paths is listed once,
+// with its version.
+func TestDiscoverCLI(t *testing.T) {
+	current, old, broken, links := t.TempDir(), t.TempDir(), t.TempDir(), t.TempDir()
+	scripts := map[string]string{
+		current: "#!/bin/sh\necho '2.0.45 (Claude Code)'\n",
+		old:     "#!/bin/sh\necho '1.0.3 (Claude Code)'\n",
+		broken:  "#!/bin/sh\nexit 1\n",
+	}
+	for dir, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, "claude"), []byte(script), 0755); err != nil {
+			t.Fatalf("Failed to create mock binary: %v", err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(current, "claude"), filepath.Join(links, "claude")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	t.Setenv("PATH", old+string(os.PathListSeparator)+links)
+
+	installations := DiscoverCLI(current, PathSearchEntry, broken)
+	if len(installations) != 3 {
+		t.Fatalf("DiscoverCLI() = %+v, want 3 installations", installations)
+	}
+	want := []struct {
+		path    string
+		version string
+	}{
+		{filepath.Join(current, "claude"), "2.0.45"},
+		{filepath.Join(old, "claude"), "1.0.3"},
+		{filepath.Join(broken, "claude"), "unknown"},
+	}
+	for i, w := range want {
+		got := installations[i]
+		if got.Path != w.path || got.Version.String() != w.version {
+			t.Errorf("installation %d = %s %s, want %s %s", i, got.Path, got.Version, w.path, w.version)
+		}
+	}
+	if installations[2].Err == nil {
+		t.Error("expected an error for the CLI whose version cannot be determined")
+	}
+}
+
 // TestExpandHome tests home directory expansion
 func TestExpandHome(t *testing.T) {
 	tests := []struct {
@@ -365,6 +439,161 @@ func TestSubprocessCLITransportClose(t *testing.T) {
 	}
 }
 
+// TestLifecycleEvents tests that the lifecycle listener is told of the
+// process start, skipped lines, stderr errors and the exit code.
+func TestLifecycleEvents(t *testing.T) {
+	t.Setenv("CLAUDE_AGENT_SDK_SKIP_VERSION_CHECK", "1")
+	t.Setenv("HOME", t.TempDir())
+
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	script := "#!/bin/sh\n" +
+		"echo '{\"type\":\"result\",\"num_turns\":\"many\"}'\n" +
+		"echo 'Error: something broke' >&2\n" +
+		"exit 3\n"
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to create mock binary: %v", err)
+	}
+
+	var mu sync.Mutex
+	var events []types.LifecycleEvent
+	exited := make(chan struct{})
+	options := types.NewClaudeAgentOptions().WithLifecycleListener(func(event types.LifecycleEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+		if _, ok := event.(*types.ProcessExitedEvent); ok {
+			close(exited)
+		}
+	})
+
+	transport := NewSubprocessCLITransport(cliPath, "", nil, log.NewLogger(false), "", options)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := transport.Connect(ctx); err != nil {
+		t.Fatalf("Connect() unexpected error: %v", err)
+	}
+	for range transport.ReadMessages(ctx) {
+	}
+	select {
+	case <-exited:
+	case <-ctx.Done():
+		t.Fatal("No ProcessExitedEvent before the timeout")
+	}
+	if err := transport.Close(ctx); err != nil {
+		t.Errorf("Close() unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var spawned *types.CLISpawnedEvent
+	var skipped *types.ParseErrorSkippedEvent
+	var stderr *types.StderrErrorEvent
+	var exit *types.ProcessExitedEvent
+	for _, event := range events {
+		switch e := event.(type) {
+		case *types.CLISpawnedEvent:
+			spawned = e
+		case *types.ParseErrorSkippedEvent:
+			skipped = e
+		case *types.StderrErrorEvent:
+			stderr = e
+		case *types.ProcessExitedEvent:
+			exit = e
+		}
+	}
+	if spawned == nil || spawned.PID == 0 || spawned.Path != cliPath {
+		t.Errorf("CLISpawnedEvent = %+v, want the PID and path of the CLI", spawned)
+	}
+	if skipped == nil || skipped.Err == nil {
+		t.Errorf("ParseErrorSkippedEvent = %+v, want the parse error", skipped)
+	}
+	if stderr == nil || stderr.Line != "Error: something broke" {
+		t.Errorf("StderrErrorEvent = %+v, want the error line", stderr)
+	}
+	if exit == nil || exit.PID != spawned.PID || exit.ExitCode != 3 || exit.Err == nil || exit.Closed {
+		t.Errorf("ProcessExitedEvent = %+v, want exit code 3 without Close", exit)
+	}
+	if _, ok := events[0].(*types.CLISpawnedEvent); !ok {
+		t.Errorf("first event = %T, want *types.CLISpawnedEvent", events[0])
+	}
+}
+
+// TestWireDump tests that the lines exchanged with the CLI are appended to
+// the wire dump with their direction and without secrets.
+func TestWireDump(t *testing.T) {
+	t.Setenv("CLAUDE_AGENT_SDK_SKIP_VERSION_CHECK", "1")
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	cliPath := filepath.Join(dir, "claude")
+	script := "#!/bin/sh\n" +
+		"read line\n" +
+		"echo '{\"type\":\"system\",\"subtype\":\"info\",\"data\":{\"note\":\"key sk-ant-REDACTED\",\"big\":12345678901234567890}}'\n" +
+		"echo 'not json my-api-key-value'\n"
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to create mock binary: %v", err)
+	}
+
+	dumpPath := filepath.Join(dir, "wire.jsonl")
+	options := types.NewClaudeAgentOptions().WithWireDump(dumpPath).WithAPIKey("my-api-key-value").WithRedaction()
+	transport := NewSubprocessCLITransport(cliPath, "", nil, log.NewLogger(false), "", options)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := transport.Connect(ctx); err != nil {
+		t.Fatalf("Connect() unexpected error: %v", err)
+	}
+	if err := transport.Write(ctx, `{"type":"user","message":{"role":"user","content":"hi"}}`); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+	for range transport.ReadMessages(ctx) {
+	}
+	if transport.SpawnDuration() <= 0 {
+		t.Errorf("SpawnDuration() = %v, want the time to the first output", transport.SpawnDuration())
+	}
+	if err := transport.Close(ctx); err != nil {
+		t.Errorf("Close() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(dumpPath)
+	if err != nil {
+		t.Fatalf("Failed to read wire dump: %v", err)
+	}
+	if info, err := os.Stat(dumpPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("wire dump mode = %v, want 0600", info.Mode().Perm())
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("wire dump has %d lines, want 3:\n%s", len(lines), data)
+	}
+	var records []types.WireRecord
+	for _, line := range lines {
+		var record types.WireRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("wire dump line %q is not a WireRecord: %v", line, err)
+		}
+		if record.Time.IsZero() {
+			t.Errorf("wire dump line %q has no time", line)
+		}
+		records = append(records, record)
+	}
+
+	if records[0].Direction != types.WireOutbound || !strings.Contains(string(records[0].Message), `"content":"hi"`) {
+		t.Errorf("first record = %+v, want the outbound user message", records[0])
+	}
+	if records[1].Direction != types.WireInbound || !strings.Contains(string(records[1].Message), "12345678901234567890") {
+		t.Errorf("second record = %s, want the inbound message with its numbers intact", records[1].Message)
+	}
+	if records[2].Direction != types.WireInbound || records[2].Raw != "not json "+types.RedactedCredential {
+		t.Errorf("third record = %+v, want the line that is not JSON with the API key redacted", records[2])
+	}
+	if strings.Contains(string(data), "sk-ant-api03") || strings.Contains(string(data), "my-api-key-value") {
+		t.Errorf("wire dump contains secrets:\n%s", data)
+	}
+	if report := options.Redactor.Report(); report.Total != 0 {
+		t.Errorf("wire dump redactions added to the WithRedaction report: %+v", report)
+	}
+}
+
 // TestMessageReaderLoop tests message reading and parsing
 func TestMessageReaderLoop(t *testing.T) {
 	// Create a mock JSON stream
@@ -423,6 +652,67 @@ func TestMessageReaderLoop(t *testing.T) {
 	}
 }
 
+// TestMessageReaderLoopStrictParsing tests parse issue reporting and strict mode
+func TestMessageReaderLoopStrictParsing(t *testing.T) {
+	jsonStream := `{"type":"user","content":"hello"}` + "\n" +
+		`{"type":"assistant","content":[{"type":"text","text":"hi"}],"model":"claude-3","mood":"happy"}` + "\n" +
+		`{"type":"system","subtype":"info","data":{}}` + "\n"
+
+	readAll := func(options *types.ClaudeAgentOptions) (*SubprocessCLITransport, []types.Message) {
+		pr, pw := io.Pipe()
+		go func() {
+			defer func() {
+				_ = pw.Close()
+			}()
+			_, _ = pw.Write([]byte(jsonStream))
+		}()
+
+		transport := &SubprocessCLITransport{
+			messages: make(chan types.Message, 10),
+			ready:    true,
+			logger:   log.NewLogger(false),
+			options:  options,
+			stdout:   pr,
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		transport.ctx = ctx
+		go transport.messageReaderLoop(ctx)
+
+		var messages []types.Message
+		for msg := range transport.messages {
+			messages = append(messages, msg)
+		}
+		return transport, messages
+	}
+
+	var issues []types.ParseIssue
+	handler := func(issue types.ParseIssue) { issues = append(issues, issue) }
+
+	transport, messages := readAll(types.NewClaudeAgentOptions().WithParseIssueHandler(handler))
+	if len(messages) != 3 {
+		t.Errorf("with handler: parsed %d messages, want 3", len(messages))
+	}
+	if len(issues) != 1 || issues[0].Path != "mood" {
+		t.Errorf("with handler: issues = %v, want unknown field mood", issues)
+	}
+	if err := transport.GetError(); err != nil {
+		t.Errorf("with handler: GetError() = %v, want nil", err)
+	}
+
+	issues = nil
+	transport, messages = readAll(types.NewClaudeAgentOptions().WithStrictParsing(true).WithParseIssueHandler(handler))
+	if len(messages) != 1 {
+		t.Errorf("strict: parsed %d messages, want 1 before the stream ends", len(messages))
+	}
+	if len(issues) != 1 {
+		t.Errorf("strict: issues = %v, want 1", issues)
+	}
+	if err := transport.GetError(); !types.IsMessageParseError(err) {
+		t.Errorf("strict: GetError() = %v, want MessageParseError", err)
+	}
+}
+
 // TestSubprocessEnvironment tests environment variable setup
 func TestSubprocessEnvironment(t *testing.T) {
 	echoPath, err := FindMockCLI()
@@ -671,6 +961,85 @@ func TestParseStderrError(t *testing.T) {
 	}
 }
 
+// TestParseDiagnostic tests the parsing of known stderr lines into
+// diagnostics
+func TestParseDiagnostic(t *testing.T) {
+	tests := []struct {
+		line         string
+		wantKind     types.DiagnosticKind
+		wantSeverity types.DiagnosticSeverity
+		wantServer   string
+	}{
+		{"Invalid API key · Please run /login", types.DiagnosticAuthFailure, types.DiagnosticError, ""},
+		{"API Error: 401 {\"type\":\"error\",\"error\":{\"type\":\"authentication_error\"}}", types.DiagnosticAuthFailure, types.DiagnosticError, ""},
+		{"OAuth token has expired", types.DiagnosticAuthFailure, types.DiagnosticError, ""},
+		{"FATAL ERROR: Reached heap limit Allocation failed - JavaScript heap out of memory", types.DiagnosticNodeError, types.DiagnosticFatal, ""},
+		{"Node.js version v16.20.0 is not supported", types.DiagnosticNodeError, types.DiagnosticFatal, ""},
+		{"(node:4242) ExperimentalWarning: Fetch is experimental", types.DiagnosticNodeError, types.DiagnosticWarning, ""},
+		{"TypeError: Cannot read properties of undefined (reading 'map')", types.DiagnosticNodeError, types.DiagnosticError, ""},
+		{"Error: Cannot find module '/usr/lib/node_modules/cli.js'", types.DiagnosticNodeError, types.DiagnosticError, ""},
+		{"Failed to start MCP server github: spawn npx ENOENT", types.DiagnosticMCPStartupFailure, types.DiagnosticError, "github"},
+		{"MCP server \"postgres\": Connection failed: timed out after 30000ms", types.DiagnosticMCPStartupFailure, types.DiagnosticError, "postgres"},
+		{"Update available! Run: npm install -g @anthropic-ai/claude-code", types.DiagnosticVersionWarning, types.DiagnosticInfo, ""},
+		{"Warning: Claude Code version 1.0.2 is outdated", types.DiagnosticVersionWarning, types.DiagnosticWarning, ""},
+		{"fatal: not a git repository", types.DiagnosticCLIError, types.DiagnosticFatal, ""},
+		{"Error: something broke", types.DiagnosticCLIError, types.DiagnosticError, ""},
+		{"Warning: settings file is invalid", types.DiagnosticCLIWarning, types.DiagnosticWarning, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			diagnostic, ok := parseDiagnostic(tt.line)
+			if !ok {
+				t.Fatal("parseDiagnostic() = false, want a diagnostic")
+			}
+			if diagnostic.Kind != tt.wantKind || diagnostic.Severity != tt.wantSeverity || diagnostic.MCPServer != tt.wantServer {
+				t.Errorf("parseDiagnostic() = %s/%s/%q, want %s/%s/%q",
+					diagnostic.Kind, diagnostic.Severity, diagnostic.MCPServer, tt.wantKind, tt.wantSeverity, tt.wantServer)
+			}
+			if diagnostic.Line != tt.line {
+				t.Errorf("Line = %q, want %q", diagnostic.Line, tt.line)
+			}
+		})
+	}
+
+	if diagnostic, ok := parseDiagnostic("Reading CLAUDE.md"); ok {
+		t.Errorf("parseDiagnostic() = %+v for an ordinary line, want false", diagnostic)
+	}
+}
+
+// TestDiagnosticHandler tests that parsed stderr lines reach the
+// diagnostic handler, with errors also reported as lifecycle events
+func TestDiagnosticHandler(t *testing.T) {
+	var diagnostics []types.Diagnostic
+	var events []types.LifecycleEvent
+	options := types.NewClaudeAgentOptions().
+		WithDiagnosticHandler(func(d types.Diagnostic) { diagnostics = append(diagnostics, d) }).
+		WithLifecycleListener(func(event types.LifecycleEvent) { events = append(events, event) })
+	transport := &SubprocessCLITransport{
+		logger:   log.NewLogger(false),
+		messages: make(chan types.Message, 10),
+		options:  options,
+	}
+
+	transport.parseStderrError("No conversation found with session ID: 8587b432-e504-42c8-b9a7-e3fd0b4b2c60")
+	transport.parseStderrError("Update available! Run: claude update")
+	transport.parseStderrError("Reading CLAUDE.md")
+
+	if len(diagnostics) != 2 {
+		t.Fatalf("got %d diagnostics, want 2: %+v", len(diagnostics), diagnostics)
+	}
+	if diagnostics[0].Kind != types.DiagnosticSessionNotFound || !types.IsSessionNotFoundError(diagnostics[0].Err) {
+		t.Errorf("diagnostics[0] = %+v, want the session not found error", diagnostics[0])
+	}
+	if diagnostics[1].Kind != types.DiagnosticVersionWarning || diagnostics[1].Severity != types.DiagnosticInfo {
+		t.Errorf("diagnostics[1] = %+v, want an info version warning", diagnostics[1])
+	}
+	if len(events) != 1 {
+		t.Errorf("got %d lifecycle events, want 1 for the error", len(events))
+	}
+}
+
 // TestForkSessionFlag tests that --fork-session flag is passed when ForkSession is true
 func TestForkSessionFlag(t *testing.T) {
 	tests := []struct {
@@ -837,6 +1206,21 @@ func TestBuildCommandArgs_ToolsPreset(t *testing.T) {
 	}
 }
 
+// TestBuildCommandArgs_ToolPatterns verifies tool patterns are rewritten for the CLI.
+func TestBuildCommandArgs_ToolPatterns(t *testing.T) {
+	opts := types.NewClaudeAgentOptions().
+		WithAllowedTools("mcp__calc__*", "Bash(git *)")
+
+	logger := log.NewLogger(false)
+	transport := NewSubprocessCLITransport("/bin/echo", "", nil, logger, "", opts)
+
+	args := transport.buildCommandArgs()
+
+	if val, ok := flagValue(args, "--allowedTools"); !ok || val != "mcp__calc,Bash(git *)" {
+		t.Fatalf("expected allowedTools flag with value %q, got %q (present=%v)", "mcp__calc,Bash(git *)", val, ok)
+	}
+}
+
 // TestBuildCommandArgs_ToolsAndLimits verifies tool, limit, and extra flags are passed.
 func TestBuildCommandArgs_ToolsAndLimits(t *testing.T) {
 	settings := "/tmp/settings.json"
@@ -950,6 +1334,21 @@ func TestBuildCommandArgs_Plugins(t *testing.T) {
 	}
 }
 
+// TestBuildCommandArgs_SessionID verifies a chosen session ID is passed to the CLI.
+func TestBuildCommandArgs_SessionID(t *testing.T) {
+	sessionID := "0b9f3c1e-6a2d-4c4f-9a51-2f1d7e3b8c44"
+	opts := types.NewClaudeAgentOptions().WithSessionID(sessionID)
+
+	logger := log.NewLogger(false)
+	transport := NewSubprocessCLITransport("/bin/echo", "", nil, logger, "", opts)
+
+	args := transport.buildCommandArgs()
+
+	if !containsFlagWithValue(args, "--session-id", sessionID) {
+		t.Fatalf("expected --session-id %s in args %v", sessionID, args)
+	}
+}
+
 // TestBuildCommandArgs_Agents verifies agent definitions are serialized for CLI.
 func TestBuildCommandArgs_Agents(t *testing.T) {
 	model := "sonnet"
@@ -1081,3 +1480,133 @@ func TestMCPServersStringPath(t *testing.T) {
 		t.Fatalf("expected config path %s, got %s", configPath, args[idx+1])
 	}
 }
+
+// TestConnectUnsupportedFeature tests that Connect refuses options the CLI is too old for
+func TestConnectUnsupportedFeature(t *testing.T) {
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(cliPath, []byte("#!/bin/sh\necho '2.0.10 (Claude Code)'\n"), 0755); err != nil {
+		t.Fatalf("Failed to create mock binary: %v", err)
+	}
+
+	opts := types.NewClaudeAgentOptions().WithBetas(types.SdkBetaContext1M)
+	transport := NewSubprocessCLITransport(cliPath, "", nil, log.NewLogger(false), "", opts)
+	err := transport.Connect(context.Background())
+	if !types.IsUnsupportedFeatureError(err) {
+		t.Fatalf("Connect() error = %v, want UnsupportedFeatureError", err)
+	}
+	if got := transport.CLIVersion().String(); got != "2.0.10" {
+		t.Errorf("CLIVersion() = %s, want 2.0.10", got)
+	}
+	if transport.cmd != nil {
+		t.Error("Connect() started the CLI despite the unsupported feature")
+	}
+}
+
+// TestConnectProxyEnv tests that WithProxy sets the proxy variables of the CLI
+func TestConnectProxyEnv(t *testing.T) {
+	t.Setenv("CLAUDE_AGENT_SDK_SKIP_VERSION_CHECK", "1")
+	dir := t.TempDir()
+	cliPath := filepath.Join(dir, "claude")
+	envPath := filepath.Join(dir, "env")
+	if err := os.WriteFile(cliPath, []byte("#!/bin/sh\nenv > \"$ENV_OUT.tmp\" && mv \"$ENV_OUT.tmp\" \"$ENV_OUT\"\n"), 0755); err != nil {
+		t.Fatalf("Failed to create mock binary: %v", err)
+	}
+
+	opts := types.NewClaudeAgentOptions().WithProxy("http://proxy.example:3128")
+	transport := NewSubprocessCLITransport(cliPath, "", map[string]string{"ENV_OUT": envPath}, log.NewLogger(false), "", opts)
+	if err := transport.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer transport.Close(context.Background())
+
+	var env []byte
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if data, err := os.ReadFile(envPath); err == nil {
+			env = data
+			break
+		}
+	}
+	for _, want := range []string{"HTTP_PROXY=http://proxy.example:3128", "HTTPS_PROXY=http://proxy.example:3128"} {
+		if !strings.Contains(string(env), want) {
+			t.Errorf("CLI environment lacks %s", want)
+		}
+	}
+}
+
+// TestConnectAPIKeyRedacted tests that the API key reaches the CLI but is redacted from its stderr
+func TestConnectAPIKeyRedacted(t *testing.T) {
+	t.Setenv("CLAUDE_AGENT_SDK_SKIP_VERSION_CHECK", "1")
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	script := "#!/bin/sh\necho \"No conversation found with session ID: $ANTHROPIC_API_KEY\" >&2\nsleep 1\n"
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to create mock binary: %v", err)
+	}
+
+	opts := types.NewClaudeAgentOptions().WithAPIKey("sk-ant-api03-secret")
+	transport := NewSubprocessCLITransport(cliPath, "", nil, log.NewLogger(false), "", opts)
+	if err := transport.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer transport.Close(context.Background())
+
+	var err error
+	for deadline := time.Now().Add(5 * time.Second); err == nil && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		err = transport.GetError()
+	}
+	var notFound *types.SessionNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("GetError() = %v, want SessionNotFoundError", err)
+	}
+	if notFound.SessionID != types.RedactedCredential {
+		t.Errorf("SessionID = %q, want the API key redacted", notFound.SessionID)
+	}
+}
+
+// TestCommandLine tests that the described command line matches the options without writing files
+func TestCommandLine(t *testing.T) {
+	opts := types.NewClaudeAgentOptions().
+		WithModel("claude-sonnet-4-5").
+		WithAutoCompact(60).
+		WithMcpServers(map[string]interface{}{
+			"files": types.McpStdioServerConfig{Command: "mcp-files"},
+		})
+	before, _ := filepath.Glob(filepath.Join(os.TempDir(), "claude-mcp-*.json"))
+
+	args, env := CommandLine(opts)
+
+	if after, _ := filepath.Glob(filepath.Join(os.TempDir(), "claude-mcp-*.json")); len(after) != len(before) {
+		t.Errorf("CommandLine wrote an MCP config file")
+	}
+	joined := strings.Join(args, " ")
+	for _, want := range []string{"--model claude-sonnet-4-5", "--mcp-servers"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("args %v lack %s", args, want)
+		}
+	}
+	for _, want := range []string{"ANTHROPIC_MODEL=claude-sonnet-4-5", "CLAUDE_AUTOCOMPACT_PCT_OVERRIDE=60"} {
+		if !strings.Contains(strings.Join(env, "\n"), want) {
+			t.Errorf("env %v lacks %s", env, want)
+		}
+	}
+	if report := opts.Describe(); strings.Join(report.Args, " ") != joined {
+		t.Errorf("Describe() args = %v, want %v", report.Args, args)
+	}
+}
+
+// TestConnectMinCLIVersion tests that Connect refuses a CLI older than WithMinCLIVersion
+func TestConnectMinCLIVersion(t *testing.T) {
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(cliPath, []byte("#!/bin/sh\necho '1.0.120 (Claude Code)'\n"), 0755); err != nil {
+		t.Fatalf("Failed to create mock binary: %v", err)
+	}
+
+	opts := types.NewClaudeAgentOptions().WithMinCLIVersion("2.x")
+	transport := NewSubprocessCLITransport(cliPath, "", nil, log.NewLogger(false), "", opts)
+	err := transport.Connect(context.Background())
+	if !types.IsUnsupportedFeatureError(err) {
+		t.Fatalf("Connect() error = %v, want UnsupportedFeatureError", err)
+	}
+	if transport.cmd != nil {
+		t.Error("Connect() started the CLI older than the minimum version")
+	}
+}
@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/internal/log"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// wireDump appends the lines exchanged with the CLI to a file, see
+// ClaudeAgentOptions.WithWireDump.
+type wireDump struct {
+	options  *types.ClaudeAgentOptions
+	redactor *types.Redactor
+	logger   *log.Logger
+
+	mu     sync.Mutex
+	file   *os.File
+	failed bool // A write failed and was logged
+}
+
+// openWireDump opens the wire dump file of options for appending.
+func openWireDump(options *types.ClaudeAgentOptions, logger *log.Logger) (*wireDump, error) {
+	file, err := os.OpenFile(options.WireDump, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	// The dump has its own Redactor, so that wire traffic does not fill the
+	// report of the one set with WithRedaction.
+	var detectors []types.SecretDetector
+	if options.Redactor != nil {
+		detectors = options.Redactor.Detectors
+	}
+	redactor := types.NewRedactor(detectors...)
+	return &wireDump{options: options, redactor: redactor, logger: logger, file: file}, nil
+}
+
+// record appends a line with its secrets redacted. A nil dump records
+// nothing.
+func (d *wireDump) record(direction types.WireDirection, line []byte) {
+	if d == nil {
+		return
+	}
+	record := types.WireRecord{Time: time.Now().UTC(), Direction: direction}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber() // Keep large integers exact
+	if decoder.Decode(&value) == nil {
+		redacted, err := json.Marshal(d.redactor.RedactValue(types.RedactionWireDump, value))
+		if err == nil {
+			record.Message = json.RawMessage(d.options.RedactCredentials(string(redacted)))
+		}
+	}
+	data, err := json.Marshal(record)
+	if err != nil || record.Message == nil {
+		// Not JSON, or a credential replacement broke it: keep the text
+		record.Message = nil
+		record.Raw = d.options.RedactCredentials(d.redactor.RedactString(types.RedactionWireDump, string(line)))
+		data, _ = json.Marshal(record)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file == nil {
+		return
+	}
+	if _, err := d.file.Write(append(data, '\n')); err != nil && !d.failed {
+		d.failed = true
+		d.logger.Warning("Failed to write wire dump: %v", err)
+	}
+}
+
+// close closes the file. A nil dump is ignored.
+func (d *wireDump) close() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file != nil {
+		_ = d.file.Close()
+		d.file = nil
+	}
+}
@@ -0,0 +1,287 @@
+// Package mcpclient connects to external MCP servers over stdio or the
+// streamable HTTP transport, so their tools can be called from Go or
+// curated and re-exposed to Claude as an SDK MCP server. Proxying lets an
+// application decide which third-party tools Claude sees, under which
+// names, and wrap every call with its own middleware.
+//
+// Example:
+//
+//	client, err := mcpclient.ConnectStdio(ctx, exec.Command("npx", "-y", "@modelcontextprotocol/server-filesystem", "/data"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer client.Close()
+//
+//	server, err := client.ProxyServer(ctx, "fs", "1.0.0", &mcpclient.ProxyOptions{
+//	    Filter: mcpclient.AllowTools("read_file", "list_directory"),
+//	})
+//	opts := types.NewClaudeAgentOptions().
+//	    WithMcpServers(map[string]interface{}{"fs": server}).
+//	    WithAutoAllowSdkTools(true)
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/M1n9X/claude-agent-sdk-go/internal/transport"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// ProtocolVersion is the MCP protocol version requested at initialization.
+const ProtocolVersion = "2025-06-18"
+
+// ServerInfo describes the server a client is connected to.
+type ServerInfo struct {
+	Name            string
+	Version         string
+	ProtocolVersion string                 // Version the server agreed to
+	Capabilities    map[string]interface{} // Capabilities the server declared
+}
+
+// RPCError is a JSON-RPC error returned by the server.
+type RPCError struct {
+	Code    int
+	Message string
+	Data    interface{}
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("MCP error %d: %s", e.Code, e.Message)
+}
+
+// conn sends JSON-RPC messages to a server.
+type conn interface {
+	// request sends a request and waits for its response.
+	request(ctx context.Context, msg map[string]interface{}) (map[string]interface{}, error)
+	// notify sends a notification.
+	notify(ctx context.Context, msg map[string]interface{}) error
+	// setProtocolVersion records the version agreed at initialization.
+	setProtocolVersion(version string)
+	close() error
+}
+
+// Client is a connection to an external MCP server. It is safe for
+// concurrent use.
+type Client struct {
+	conn   conn
+	nextID atomic.Int64
+	info   ServerInfo
+}
+
+// connect initializes an MCP session over c.
+func connect(ctx context.Context, c conn) (*Client, error) {
+	client := &Client{conn: c}
+	result, err := client.call(ctx, "initialize", map[string]interface{}{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo": map[string]interface{}{
+			"name":    "claude-agent-sdk-go",
+			"version": transport.SDKVersion,
+		},
+	})
+	if err != nil {
+		_ = c.close()
+		return nil, fmt.Errorf("initialize MCP session: %w", err)
+	}
+
+	client.info.ProtocolVersion, _ = result["protocolVersion"].(string)
+	client.info.Capabilities, _ = result["capabilities"].(map[string]interface{})
+	if serverInfo, ok := result["serverInfo"].(map[string]interface{}); ok {
+		client.info.Name, _ = serverInfo["name"].(string)
+		client.info.Version, _ = serverInfo["version"].(string)
+	}
+	c.setProtocolVersion(client.info.ProtocolVersion)
+
+	if err := c.notify(ctx, notification("notifications/initialized", nil)); err != nil {
+		_ = c.close()
+		return nil, fmt.Errorf("initialize MCP session: %w", err)
+	}
+	return client, nil
+}
+
+// ServerInfo returns the server's name, version and capabilities.
+func (c *Client) ServerInfo() ServerInfo {
+	return c.info
+}
+
+// Close ends the session and releases the connection.
+func (c *Client) Close() error {
+	return c.conn.close()
+}
+
+// Ping checks that the server is responsive.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.call(ctx, "ping", nil)
+	return err
+}
+
+// ListTools returns every tool the server offers.
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var tools []Tool
+	var cursor string
+	for {
+		var params map[string]interface{}
+		if cursor != "" {
+			params = map[string]interface{}{"cursor": cursor}
+		}
+		result, err := c.call(ctx, "tools/list", params)
+		if err != nil {
+			return nil, fmt.Errorf("list MCP tools: %w", err)
+		}
+		list, _ := result["tools"].([]interface{})
+		for _, entry := range list {
+			raw, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			tool := Tool{}
+			tool.Name, _ = raw["name"].(string)
+			tool.Description, _ = raw["description"].(string)
+			tool.InputSchema, _ = raw["inputSchema"].(map[string]interface{})
+			if tool.Name != "" {
+				tools = append(tools, tool)
+			}
+		}
+		cursor, _ = result["nextCursor"].(string)
+		if cursor == "" {
+			return tools, nil
+		}
+	}
+}
+
+// CallTool calls a tool on the server. A tool that fails reports it with
+// IsError in the result; errors are returned for protocol failures, such as
+// an unknown tool.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]interface{}) (*types.ToolResult, error) {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	result, err := c.call(ctx, "tools/call", map[string]interface{}{
+		"name":      name,
+		"arguments": args,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("call MCP tool %s: %w", name, err)
+	}
+
+	toolResult := &types.ToolResult{}
+	toolResult.IsError, _ = result["isError"].(bool)
+	content, _ := result["content"].([]interface{})
+	for _, entry := range content {
+		block, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		toolResult.Content = append(toolResult.Content, contentBlock(block))
+	}
+	return toolResult, nil
+}
+
+// contentBlock converts an MCP content block to the matching SDK type.
+// Blocks of other types, or with fields the SDK type lacks, such as
+// annotations, are kept as a RawContentBlock.
+func contentBlock(block map[string]interface{}) types.ContentBlock {
+	var typed types.ContentBlock
+	switch block["type"] {
+	case "text":
+		typed = decodeBlock[types.TextBlock](block, "text")
+	case "image":
+		typed = decodeBlock[types.ImageBlock](block, "data", "mimeType")
+	case "resource_link":
+		typed = decodeBlock[types.ResourceLinkBlock](block, "uri", "name", "description", "mimeType")
+	}
+	if typed == nil {
+		return types.RawContentBlock(block)
+	}
+	return typed
+}
+
+// decodeBlock decodes block into T when it has no fields besides type and
+// fields. It returns nil otherwise.
+func decodeBlock[T types.ContentBlock](block map[string]interface{}, fields ...string) types.ContentBlock {
+	allowed := map[string]bool{"type": true}
+	for _, field := range fields {
+		allowed[field] = true
+	}
+	for key := range block {
+		if !allowed[key] {
+			return nil
+		}
+	}
+	data, err := json.Marshal(block)
+	if err != nil {
+		return nil
+	}
+	var typed T
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return nil
+	}
+	return typed
+}
+
+// call sends a request and returns its result. When ctx is canceled first,
+// the server is told to stop working on it.
+func (c *Client) call(ctx context.Context, method string, params map[string]interface{}) (map[string]interface{}, error) {
+	id := c.nextID.Add(1)
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+	}
+	if params != nil {
+		msg["params"] = params
+	}
+
+	response, err := c.conn.request(ctx, msg)
+	if err != nil {
+		if ctx.Err() != nil && method != "initialize" {
+			_ = c.conn.notify(context.Background(), notification("notifications/cancelled", map[string]interface{}{
+				"requestId": id,
+				"reason":    ctx.Err().Error(),
+			}))
+		}
+		return nil, err
+	}
+
+	if rpcErr, ok := response["error"].(map[string]interface{}); ok {
+		e := &RPCError{Data: rpcErr["data"]}
+		if code, ok := rpcErr["code"].(float64); ok {
+			e.Code = int(code)
+		}
+		e.Message, _ = rpcErr["message"].(string)
+		return nil, e
+	}
+	result, _ := response["result"].(map[string]interface{})
+	if result == nil {
+		result = map[string]interface{}{}
+	}
+	return result, nil
+}
+
+// notification returns a JSON-RPC notification.
+func notification(method string, params map[string]interface{}) map[string]interface{} {
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+	}
+	if params != nil {
+		msg["params"] = params
+	}
+	return msg
+}
+
+// responseID returns the request ID of a response as sent by this client,
+// which numbers its requests.
+func responseID(msg map[string]interface{}) (int64, bool) {
+	switch id := msg["id"].(type) {
+	case float64:
+		return int64(id), true
+	case json.Number:
+		n, err := id.Int64()
+		return n, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,257 @@
+package mcpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/mcpserve"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// newTestServer returns a server with an echo tool, a failing tool, a slow
+// tool that waits for cancellation and a tool returning an image.
+func newTestServer(t *testing.T) *types.ToolServerConfig {
+	t.Helper()
+	build := func(b *types.ToolBuilder) types.McpTool {
+		tool, err := b.Build()
+		if err != nil {
+			t.Fatalf("failed to build tool: %v", err)
+		}
+		return tool
+	}
+	echo := build(types.NewTool("echo").
+		Description("Echo a message").
+		StringParam("message", "Message to echo", true).
+		Handler(func(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+			types.ReportToolProgress(ctx, 1, 1, "echoing")
+			return types.NewMcpToolResult(types.TextBlock{Type: "text", Text: input["message"].(string)}), nil
+		}))
+	fail := build(types.NewTool("fail").
+		Description("Always fail").
+		Handler(func(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+			return &types.ToolResult{Content: []types.ContentBlock{types.TextBlock{Type: "text", Text: "boom"}}, IsError: true}, nil
+		}))
+	slow := build(types.NewTool("slow").
+		Description("Wait until canceled").
+		Handler(func(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}))
+	chart := build(types.NewTool("chart").
+		Description("Draw a chart").
+		Handler(func(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+			return types.NewMcpToolResult(
+				types.NewImageBlock([]byte("\x89PNG\r\n\x1a\n"), "image/png"),
+				types.NewResourceLinkBlock("file:///tmp/chart.png", "chart.png", "image/png"),
+			), nil
+		}))
+	return types.CreateToolServer("remote", "2.0.0", []types.McpTool{echo, fail, slow, chart})
+}
+
+// connectStdio connects to a test server served over in-memory pipes.
+func connectStdio(t *testing.T) *Client {
+	t.Helper()
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- mcpserve.ServeStdio(context.Background(), newTestServer(t), serverReader, serverWriter)
+		serverWriter.Close()
+	}()
+
+	client, err := ConnectStdioStreams(context.Background(), clientReader, clientWriter)
+	if err != nil {
+		t.Fatalf("ConnectStdioStreams() error: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		if err := <-done; err != nil {
+			t.Errorf("ServeStdio() error: %v", err)
+		}
+	})
+	return client
+}
+
+// connectHTTP connects to a test server served over HTTP.
+func connectHTTP(t *testing.T) *Client {
+	t.Helper()
+	handler, err := mcpserve.NewHTTPHandler(newTestServer(t), &mcpserve.HTTPOptions{
+		Middleware: []func(http.Handler) http.Handler{mcpserve.BearerAuth("secret")},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPHandler() error: %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	if _, err := ConnectHTTP(context.Background(), srv.URL+mcpserve.DefaultHTTPPath, nil); err == nil {
+		t.Fatal("expected error without a token")
+	}
+	client, err := ConnectHTTP(context.Background(), srv.URL+mcpserve.DefaultHTTPPath, &HTTPOptions{
+		Header: http.Header{"Authorization": {"Bearer secret"}},
+	})
+	if err != nil {
+		t.Fatalf("ConnectHTTP() error: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := client.Close(); err != nil {
+			t.Errorf("Close() error: %v", err)
+		}
+	})
+	return client
+}
+
+// TestClient tests listing and calling tools over both transports.
+func TestClient(t *testing.T) {
+	transports := map[string]func(t *testing.T) *Client{
+		"stdio": connectStdio,
+		"http":  connectHTTP,
+	}
+	for name, connect := range transports {
+		t.Run(name, func(t *testing.T) {
+			client := connect(t)
+			ctx := context.Background()
+
+			info := client.ServerInfo()
+			if info.Name != "remote" || info.Version != "2.0.0" || info.ProtocolVersion != ProtocolVersion {
+				t.Errorf("unexpected server info: %+v", info)
+			}
+			if err := client.Ping(ctx); err != nil {
+				t.Errorf("Ping() error: %v", err)
+			}
+
+			tools, err := client.ListTools(ctx)
+			if err != nil {
+				t.Fatalf("ListTools() error: %v", err)
+			}
+			if len(tools) != 4 || tools[0].Name != "echo" || tools[0].Description != "Echo a message" || tools[0].InputSchema == nil {
+				t.Fatalf("unexpected tools: %+v", tools)
+			}
+
+			result, err := client.CallTool(ctx, "echo", map[string]interface{}{"message": "hi"})
+			if err != nil {
+				t.Fatalf("CallTool() error: %v", err)
+			}
+			if text, ok := result.Content[0].(types.TextBlock); !ok || text.Text != "hi" || result.IsError {
+				t.Errorf("unexpected result: %+v", result)
+			}
+
+			result, err = client.CallTool(ctx, "fail", nil)
+			if err != nil || !result.IsError {
+				t.Errorf("expected an error result, got %+v, %v", result, err)
+			}
+
+			result, err = client.CallTool(ctx, "chart", nil)
+			if err != nil {
+				t.Fatalf("CallTool() error: %v", err)
+			}
+			if image, ok := result.Content[0].(types.ImageBlock); !ok || image.MimeType != "image/png" || image.Data != "iVBORw0KGgo=" {
+				t.Errorf("unexpected image: %+v", result.Content[0])
+			}
+			if link, ok := result.Content[1].(types.ResourceLinkBlock); !ok || link.URI != "file:///tmp/chart.png" {
+				t.Errorf("unexpected resource link: %+v", result.Content[1])
+			}
+
+			var rpcErr *RPCError
+			if _, err := client.CallTool(ctx, "missing", nil); !errors.As(err, &rpcErr) {
+				t.Errorf("expected an RPCError for an unknown tool, got %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+			if _, err := client.CallTool(ctx, "slow", nil); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected deadline exceeded, got %v", err)
+			}
+		})
+	}
+}
+
+// TestProxyServer tests filtering, renaming and wrapping proxied tools.
+func TestProxyServer(t *testing.T) {
+	client := connectStdio(t)
+	ctx := context.Background()
+
+	var calls []string
+	audit := func(next types.ToolFunc) types.ToolFunc {
+		return func(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+			call, _ := types.ToolCallFromContext(ctx)
+			calls = append(calls, call.Tool)
+			return next(ctx, input)
+		}
+	}
+	server, err := client.ProxyServer(ctx, "curated", "1.0.0", &ProxyOptions{
+		Filter:     DenyTools("slow", "chart"),
+		Rename:     PrefixNames("remote_"),
+		Describe:   func(tool Tool) string { return strings.ToUpper(tool.Description) },
+		Middleware: []types.ToolMiddleware{audit},
+	})
+	if err != nil {
+		t.Fatalf("ProxyServer() error: %v", err)
+	}
+
+	names := server.AllowedToolNames()
+	if len(names) != 2 || names[0] != "mcp__curated__remote_echo" || names[1] != "mcp__curated__remote_fail" {
+		t.Fatalf("unexpected tool names: %v", names)
+	}
+	tools := server.Tools()
+	if tools[0].Description() != "ECHO A MESSAGE" {
+		t.Errorf("unexpected description: %q", tools[0].Description())
+	}
+
+	ctx = types.ContextWithToolCall(ctx, types.ToolCallInfo{Server: "curated", Tool: tools[0].Name()})
+	result, err := tools[0].Execute(ctx, map[string]interface{}{"message": "proxied"})
+	if err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+	if text, ok := result.Content[0].(types.TextBlock); !ok || text.Text != "proxied" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(calls) != 1 || calls[0] != "remote_echo" {
+		t.Errorf("expected middleware to see remote_echo, got %v", calls)
+	}
+
+	if _, err := client.ProxyTools(ctx, &ProxyOptions{Rename: func(Tool) string { return "same" }}); err == nil {
+		t.Error("expected error for duplicate names")
+	}
+	if _, err := client.ProxyServer(ctx, "curated", "1.0.0", &ProxyOptions{Rename: PrefixNames("bad.")}); err == nil {
+		t.Error("expected error for an invalid tool name")
+	}
+	if tools, err := client.ProxyTools(ctx, &ProxyOptions{Filter: AllowTools("echo")}); err != nil || len(tools) != 1 {
+		t.Errorf("expected only echo, got %d tools, %v", len(tools), err)
+	}
+}
+
+// TestConnectHTTPProxy tests that requests go through the proxy set in HTTPOptions.
+func TestConnectHTTPProxy(t *testing.T) {
+	handler, err := mcpserve.NewHTTPHandler(newTestServer(t), nil)
+	if err != nil {
+		t.Fatalf("NewHTTPHandler() error: %v", err)
+	}
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute URL of the target
+		proxied = append(proxied, r.URL.String())
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+
+	target := "http://mcp.internal.example" + mcpserve.DefaultHTTPPath
+	client, err := ConnectHTTP(context.Background(), target, &HTTPOptions{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("ConnectHTTP() error: %v", err)
+	}
+	defer client.Close()
+	if len(proxied) == 0 || proxied[0] != target {
+		t.Errorf("proxy received %v, want requests for %s", proxied, target)
+	}
+
+	if _, err := ConnectHTTP(context.Background(), target, &HTTPOptions{Proxy: "ftp://proxy"}); err == nil {
+		t.Error("ConnectHTTP() accepted an ftp proxy")
+	}
+}
@@ -0,0 +1,232 @@
+package mcpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+const (
+	// sessionIDHeader carries the session ID assigned at initialization.
+	sessionIDHeader = "Mcp-Session-Id"
+
+	// protocolVersionHeader carries the protocol version after initialization.
+	protocolVersionHeader = "MCP-Protocol-Version"
+)
+
+// HTTPOptions configures a connection to an HTTP MCP server.
+type HTTPOptions struct {
+	// Header is sent with every request, for example an Authorization header.
+	Header http.Header
+
+	// HTTPClient sends the requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// Proxy is the URL of a proxy for the requests, such as the one set with
+	// ClaudeAgentOptions.WithProxy. It is ignored when HTTPClient is set;
+	// otherwise the proxy environment variables apply.
+	Proxy string
+}
+
+// ConnectHTTP connects to an MCP server over the streamable HTTP transport
+// at url. Close ends the session.
+//
+// Example:
+//
+//	client, err := mcpclient.ConnectHTTP(ctx, "https://tools.example.com/mcp", &mcpclient.HTTPOptions{
+//	    Header: http.Header{"Authorization": {"Bearer " + token}},
+//	})
+func ConnectHTTP(ctx context.Context, url string, opts *HTTPOptions) (*Client, error) {
+	if opts == nil {
+		opts = &HTTPOptions{}
+	}
+	c := &httpConn{
+		url:    url,
+		header: opts.Header,
+		client: opts.HTTPClient,
+	}
+	if c.client == nil && opts.Proxy != "" {
+		proxyURL, err := types.ParseProxyURL(opts.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		c.client = &http.Client{Transport: transport}
+	}
+	if c.client == nil {
+		c.client = http.DefaultClient
+	}
+	return connect(ctx, c)
+}
+
+// httpConn implements the streamable HTTP transport.
+type httpConn struct {
+	url    string
+	header http.Header
+	client *http.Client
+
+	mu              sync.Mutex
+	sessionID       string
+	protocolVersion string
+}
+
+func (c *httpConn) request(ctx context.Context, msg map[string]interface{}) (map[string]interface{}, error) {
+	resp, err := c.post(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if session := resp.Header.Get(sessionIDHeader); session != "" {
+		c.mu.Lock()
+		c.sessionID = session
+		c.mu.Unlock()
+	}
+
+	id, _ := msg["id"].(int64)
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		return readEventStream(resp.Body, id)
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode MCP response: %w", err)
+	}
+	return response, nil
+}
+
+func (c *httpConn) notify(ctx context.Context, msg map[string]interface{}) error {
+	resp, err := c.post(ctx, msg)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+func (c *httpConn) setProtocolVersion(version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.protocolVersion = version
+}
+
+// close ends the session. Servers that do not support ending sessions
+// answer 405, which is not an error.
+func (c *httpConn) close() error {
+	c.mu.Lock()
+	session := c.sessionID
+	c.sessionID = ""
+	c.mu.Unlock()
+	if session == "" {
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, c.url, nil)
+	if err != nil {
+		return err
+	}
+	c.setHeaders(req, session)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("end MCP session: %w", err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusMethodNotAllowed {
+		return fmt.Errorf("end MCP session: %s", resp.Status)
+	}
+	return nil
+}
+
+// post sends a message and returns a successful response.
+func (c *httpConn) post(ctx context.Context, msg map[string]interface{}) (*http.Response, error) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("encode MCP message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	session := c.sessionID
+	c.mu.Unlock()
+	c.setHeaders(req, session)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send MCP message: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("send MCP message: %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
+
+// setHeaders adds the configured, session and protocol version headers.
+func (c *httpConn) setHeaders(req *http.Request, session string) {
+	for key, values := range c.header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if session != "" {
+		req.Header.Set(sessionIDHeader, session)
+	}
+	c.mu.Lock()
+	version := c.protocolVersion
+	c.mu.Unlock()
+	if version != "" {
+		req.Header.Set(protocolVersionHeader, version)
+	}
+}
+
+// readEventStream reads server-sent events until the response to the
+// request with the given ID arrives. Notifications sent before it are
+// ignored.
+func readEventStream(r io.Reader, id int64) (map[string]interface{}, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10<<20)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data.WriteString(strings.TrimPrefix(value, " "))
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+
+		var msg map[string]interface{}
+		err := json.Unmarshal([]byte(data.String()), &msg)
+		data.Reset()
+		if err != nil {
+			continue
+		}
+		if got, ok := responseID(msg); ok && got == id {
+			if _, isRequest := msg["method"]; !isRequest {
+				return msg, nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read MCP event stream: %w", err)
+	}
+	return nil, fmt.Errorf("MCP event stream ended without a response")
+}
@@ -0,0 +1,126 @@
+package mcpclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Tool describes a tool offered by an MCP server.
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema map[string]interface{}
+}
+
+// ProxyOptions selects and adapts the tools re-exposed by ProxyTools and
+// ProxyServer.
+type ProxyOptions struct {
+	// Filter reports whether to expose a tool. Nil exposes every tool.
+	Filter func(tool Tool) bool
+
+	// Rename returns the name a tool is exposed under. Nil keeps the
+	// server's names.
+	Rename func(tool Tool) string
+
+	// Describe returns the description a tool is exposed with, for example
+	// to add usage guidance. Nil keeps the server's descriptions.
+	Describe func(tool Tool) string
+
+	// Middleware wraps every call to a proxied tool, outermost first.
+	Middleware []types.ToolMiddleware
+}
+
+// AllowTools returns a filter that exposes only the named tools.
+func AllowTools(names ...string) func(tool Tool) bool {
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		allowed[name] = true
+	}
+	return func(tool Tool) bool { return allowed[tool.Name] }
+}
+
+// DenyTools returns a filter that hides the named tools.
+func DenyTools(names ...string) func(tool Tool) bool {
+	denied := make(map[string]bool, len(names))
+	for _, name := range names {
+		denied[name] = true
+	}
+	return func(tool Tool) bool { return !denied[tool.Name] }
+}
+
+// PrefixNames returns a rename function that adds prefix to every tool name.
+func PrefixNames(prefix string) func(tool Tool) string {
+	return func(tool Tool) string { return prefix + tool.Name }
+}
+
+// ProxyTools lists the server's tools and returns the selected ones as SDK
+// tools that forward each call to the server. It returns an error when two
+// tools would be exposed under the same name.
+func (c *Client) ProxyTools(ctx context.Context, opts *ProxyOptions) ([]types.McpTool, error) {
+	if opts == nil {
+		opts = &ProxyOptions{}
+	}
+	remote, err := c.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tools []types.McpTool
+	exposed := make(map[string]string)
+	for _, tool := range remote {
+		if opts.Filter != nil && !opts.Filter(tool) {
+			continue
+		}
+		proxy := &proxyTool{client: c, remote: tool.Name, name: tool.Name, description: tool.Description, schema: tool.InputSchema}
+		if opts.Rename != nil {
+			proxy.name = opts.Rename(tool)
+		}
+		if opts.Describe != nil {
+			proxy.description = opts.Describe(tool)
+		}
+		if proxy.schema == nil {
+			proxy.schema = map[string]interface{}{"type": "object"}
+		}
+		if other, ok := exposed[proxy.name]; ok {
+			return nil, fmt.Errorf("MCP tools %s and %s are both exposed as %s", other, tool.Name, proxy.name)
+		}
+		exposed[proxy.name] = tool.Name
+		tools = append(tools, types.WrapTool(proxy, opts.Middleware...))
+	}
+	return tools, nil
+}
+
+// ProxyServer returns an SDK MCP server named name that exposes the
+// server's selected tools to Claude, for use with WithMcpServers. The
+// client must stay open while the server is in use.
+func (c *Client) ProxyServer(ctx context.Context, name, version string, opts *ProxyOptions) (*types.ToolServerConfig, error) {
+	tools, err := c.ProxyTools(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range tools {
+		if err := types.ValidateMcpToolName(name, tool.Name()); err != nil {
+			return nil, err
+		}
+	}
+	return types.CreateToolServer(name, version, tools), nil
+}
+
+// proxyTool is an SDK tool that forwards calls to a tool on an MCP server.
+type proxyTool struct {
+	client      *Client
+	remote      string // Name on the server
+	name        string // Name exposed to Claude
+	description string
+	schema      map[string]interface{}
+}
+
+func (t *proxyTool) Name() string                        { return t.name }
+func (t *proxyTool) Description() string                 { return t.description }
+func (t *proxyTool) InputSchema() map[string]interface{} { return t.schema }
+
+func (t *proxyTool) Execute(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+	return t.client.CallTool(ctx, t.remote, input)
+}
@@ -0,0 +1,205 @@
+package mcpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// stdioShutdownTimeout is how long Close waits for a server process to exit
+// after its standard input is closed before killing it.
+const stdioShutdownTimeout = 5 * time.Second
+
+// ConnectStdio starts cmd as an MCP server and connects to it over its
+// standard input and output. Set cmd.Env, cmd.Dir and cmd.Stderr before
+// calling; the server's log output is discarded unless cmd.Stderr is set.
+// Close stops the process.
+func ConnectStdio(ctx context.Context, cmd *exec.Cmd) (*Client, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start MCP server: %w", err)
+	}
+
+	stop := func() error {
+		_ = stdin.Close()
+		exited := make(chan error, 1)
+		go func() { exited <- cmd.Wait() }()
+		select {
+		case <-exited:
+			return nil
+		case <-time.After(stdioShutdownTimeout):
+			_ = cmd.Process.Kill()
+			<-exited
+			return nil
+		}
+	}
+	return connect(ctx, newStdioConn(stdout, stdin, stop))
+}
+
+// ConnectStdioStreams connects to an MCP server over a pair of streams
+// carrying newline-delimited JSON-RPC messages: requests are written to w
+// and the server's messages are read from r. Close closes w when it is an
+// io.Closer.
+func ConnectStdioStreams(ctx context.Context, r io.Reader, w io.Writer) (*Client, error) {
+	stop := func() error {
+		if closer, ok := w.(io.Closer); ok {
+			return closer.Close()
+		}
+		return nil
+	}
+	return connect(ctx, newStdioConn(r, w, stop))
+}
+
+// stdioConn implements the MCP stdio transport.
+type stdioConn struct {
+	w       io.Writer
+	writeMu sync.Mutex
+	stop    func() error
+
+	mu      sync.Mutex
+	pending map[int64]chan map[string]interface{}
+	err     error         // Why the server stopped responding
+	done    chan struct{} // Closed when the server stops responding
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// newStdioConn returns a connection reading messages from r and writing
+// them to w. stop is called on close.
+func newStdioConn(r io.Reader, w io.Writer, stop func() error) *stdioConn {
+	c := &stdioConn{
+		w:       w,
+		stop:    stop,
+		pending: make(map[int64]chan map[string]interface{}),
+		done:    make(chan struct{}),
+	}
+	go c.read(r)
+	return c
+}
+
+// read dispatches the messages from the server until r ends.
+func (c *stdioConn) read(r io.Reader) {
+	reader := bufio.NewReader(r)
+	var err error
+	for {
+		var line []byte
+		line, err = reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var msg map[string]interface{}
+			if jsonErr := json.Unmarshal(line, &msg); jsonErr == nil {
+				c.dispatch(msg)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		err = errors.New("MCP server closed the connection")
+	}
+
+	c.mu.Lock()
+	c.err = err
+	c.mu.Unlock()
+	close(c.done)
+}
+
+// dispatch delivers a response to its waiting request and answers requests
+// from the server. Notifications are ignored.
+func (c *stdioConn) dispatch(msg map[string]interface{}) {
+	if method, ok := msg["method"].(string); ok {
+		id, isRequest := msg["id"]
+		if !isRequest {
+			return
+		}
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+		if method == "ping" {
+			response["result"] = map[string]interface{}{}
+		} else {
+			response["error"] = map[string]interface{}{"code": -32601, "message": "method not found: " + method}
+		}
+		_ = c.write(response)
+		return
+	}
+
+	id, ok := responseID(msg)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	ch, ok := c.pending[id]
+	delete(c.pending, id)
+	c.mu.Unlock()
+	if ok {
+		ch <- msg
+	}
+}
+
+func (c *stdioConn) request(ctx context.Context, msg map[string]interface{}) (map[string]interface{}, error) {
+	id, _ := msg["id"].(int64)
+	ch := make(chan map[string]interface{}, 1)
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.write(msg); err != nil {
+		return nil, err
+	}
+	select {
+	case response := <-ch:
+		return response, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return nil, c.err
+	}
+}
+
+func (c *stdioConn) notify(ctx context.Context, msg map[string]interface{}) error {
+	return c.write(msg)
+}
+
+func (c *stdioConn) setProtocolVersion(string) {}
+
+func (c *stdioConn) close() error {
+	c.closeOnce.Do(func() {
+		c.closeErr = c.stop()
+	})
+	return c.closeErr
+}
+
+// write sends a message as one line.
+func (c *stdioConn) write(msg map[string]interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encode MCP message: %w", err)
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := c.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write MCP message: %w", err)
+	}
+	return nil
+}
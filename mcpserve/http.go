@@ -0,0 +1,333 @@
+package mcpserve
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/M1n9X/claude-agent-sdk-go/internal/mcp"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+const (
+	// DefaultHTTPPath is the endpoint path used when HTTPOptions.Path is empty.
+	DefaultHTTPPath = "/mcp"
+
+	// SessionIDHeader carries the session ID assigned at initialization.
+	SessionIDHeader = "Mcp-Session-Id"
+
+	// maxRequestBytes limits the size of a POST body.
+	maxRequestBytes = 10 << 20
+)
+
+// HTTPOptions configures an HTTP MCP server.
+type HTTPOptions struct {
+	// Path is the MCP endpoint. Defaults to DefaultHTTPPath.
+	Path string
+
+	// Middleware wraps the endpoint, outermost first, for example with
+	// BearerAuth to require a token.
+	Middleware []func(http.Handler) http.Handler
+
+	// AllowedOrigins lists the browser origins allowed to connect. Requests
+	// with any other Origin header are rejected to prevent DNS rebinding
+	// attacks; requests without one, as sent by most MCP clients, are allowed.
+	AllowedOrigins []string
+}
+
+// HTTP serves server at addr over the MCP streamable HTTP transport. It
+// blocks like http.ListenAndServe. Use NewHTTPHandler to mount the endpoint
+// on an existing server or to control shutdown.
+//
+// Example:
+//
+//	err := mcpserve.HTTP(server, ":8080", &mcpserve.HTTPOptions{
+//	    Middleware: []func(http.Handler) http.Handler{mcpserve.BearerAuth(os.Getenv("MCP_TOKEN"))},
+//	})
+func HTTP(server *types.ToolServerConfig, addr string, opts *HTTPOptions) error {
+	handler, err := NewHTTPHandler(server, opts)
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return httpServer.ListenAndServe()
+}
+
+// NewHTTPHandler returns an http.Handler serving server over the MCP
+// streamable HTTP transport at opts.Path.
+//
+// Clients POST JSON-RPC messages to the endpoint. Requests are answered with
+// a JSON body, or with a server-sent event stream carrying progress
+// notifications followed by the response when the client accepts
+// text/event-stream. Each initialize request starts a session whose ID is
+// returned in the Mcp-Session-Id header; later requests must send it, and
+// DELETE ends the session.
+func NewHTTPHandler(server *types.ToolServerConfig, opts *HTTPOptions) (http.Handler, error) {
+	if opts == nil {
+		opts = &HTTPOptions{}
+	}
+	if _, err := mcp.ServerFromConfig(server); err != nil {
+		return nil, err
+	}
+
+	path := opts.Path
+	if path == "" {
+		path = DefaultHTTPPath
+	}
+	endpoint := &httpEndpoint{
+		config:   server,
+		origins:  opts.AllowedOrigins,
+		sessions: make(map[string]*session),
+	}
+
+	var handler http.Handler = endpoint
+	for i := len(opts.Middleware) - 1; i >= 0; i-- {
+		handler = opts.Middleware[i](handler)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+	return mux, nil
+}
+
+// BearerAuth returns middleware that requires an "Authorization: Bearer
+// <token>" header matching one of tokens.
+func BearerAuth(tokens ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if ok && got != "" {
+				for _, token := range tokens {
+					if token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+						next.ServeHTTP(w, r)
+						return
+					}
+				}
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		})
+	}
+}
+
+// httpEndpoint implements the streamable HTTP transport.
+type httpEndpoint struct {
+	config  *types.ToolServerConfig
+	origins []string
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+func (e *httpEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" && !e.allowedOrigin(origin) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		e.handlePost(w, r)
+	case http.MethodDelete:
+		e.handleDelete(w, r)
+	default:
+		// Server-initiated streams over GET are not offered.
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// allowedOrigin reports whether a browser origin may connect.
+func (e *httpEndpoint) allowedOrigin(origin string) bool {
+	for _, allowed := range e.origins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePost handles one JSON-RPC message or a batch of them.
+func (e *httpEndpoint) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	messages, batch, err := decodeMessages(body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(nil, mcp.ErrorCodeParseError, fmt.Sprintf("parse error: %v", err)))
+		return
+	}
+
+	s, sessionID, status, problem := e.sessionFor(r, messages)
+	if s == nil {
+		http.Error(w, problem, status)
+		return
+	}
+	if sessionID != "" {
+		w.Header().Set(SessionIDHeader, sessionID)
+	}
+
+	hasRequests := false
+	for _, msg := range messages {
+		if _, ok := msg["id"]; ok {
+			if _, ok := msg["method"]; ok {
+				hasRequests = true
+			}
+		}
+	}
+	if !hasRequests {
+		// Notifications and client responses are only acknowledged.
+		for _, msg := range messages {
+			s.handle(r.Context(), msg, func(map[string]interface{}) {})
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		e.streamResponses(w, r, s, messages)
+		return
+	}
+
+	var responses []interface{}
+	for _, msg := range messages {
+		if response := s.handle(r.Context(), msg, func(map[string]interface{}) {}); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if batch {
+		writeJSON(w, http.StatusOK, responses)
+		return
+	}
+	writeJSON(w, http.StatusOK, responses[0])
+}
+
+// streamResponses answers requests with a server-sent event stream that
+// carries progress notifications before each response.
+func (e *httpEndpoint) streamResponses(w http.ResponseWriter, r *http.Request, s *session, messages []map[string]interface{}) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	var mu sync.Mutex
+	closed := false
+	send := func(msg interface{}) {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			// A canceled tool may still report progress after the stream ends.
+			return
+		}
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	defer func() {
+		mu.Lock()
+		closed = true
+		mu.Unlock()
+	}()
+
+	for _, msg := range messages {
+		if response := s.handle(r.Context(), msg, func(n map[string]interface{}) { send(n) }); response != nil {
+			send(response)
+		}
+	}
+}
+
+// sessionFor returns the session for a POST. An initialize request starts a
+// new session and returns its ID; other requests must name an existing one.
+// When no session is returned, status and problem describe the error.
+func (e *httpEndpoint) sessionFor(r *http.Request, messages []map[string]interface{}) (s *session, newID string, status int, problem string) {
+	for _, msg := range messages {
+		if msg["method"] == "initialize" {
+			if len(messages) > 1 {
+				return nil, "", http.StatusBadRequest, "initialize must not be batched"
+			}
+			s, err := newSession(e.config)
+			if err != nil {
+				return nil, "", http.StatusInternalServerError, err.Error()
+			}
+			id := uuid.NewString()
+			e.mu.Lock()
+			e.sessions[id] = s
+			e.mu.Unlock()
+			return s, id, 0, ""
+		}
+	}
+
+	id := r.Header.Get(SessionIDHeader)
+	if id == "" {
+		return nil, "", http.StatusBadRequest, "missing " + SessionIDHeader + " header"
+	}
+	e.mu.Lock()
+	s, ok := e.sessions[id]
+	e.mu.Unlock()
+	if !ok {
+		return nil, "", http.StatusNotFound, "unknown session"
+	}
+	return s, "", 0, ""
+}
+
+// handleDelete ends a session.
+func (e *httpEndpoint) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(SessionIDHeader)
+	e.mu.Lock()
+	s, ok := e.sessions[id]
+	delete(e.sessions, id)
+	e.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+	s.close()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeMessages decodes a JSON-RPC message or a batch of messages.
+func decodeMessages(body []byte) (messages []map[string]interface{}, batch bool, err error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &messages); err != nil {
+			return nil, true, err
+		}
+		if len(messages) == 0 {
+			return nil, true, fmt.Errorf("empty batch")
+		}
+		return messages, true, nil
+	}
+	var msg map[string]interface{}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, false, err
+	}
+	return []map[string]interface{}{msg}, false, nil
+}
+
+// writeJSON writes v as a JSON response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
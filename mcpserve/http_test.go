@@ -0,0 +1,169 @@
+package mcpserve
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// post sends a JSON-RPC body to the test server.
+func post(t *testing.T, url, sessionID, accept, body string, headers ...string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	if sessionID != "" {
+		req.Header.Set(SessionIDHeader, sessionID)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+// readBody reads and closes a response body.
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	return string(data)
+}
+
+// TestHTTPHandler tests a session over the streamable HTTP transport.
+func TestHTTPHandler(t *testing.T) {
+	handler, err := NewHTTPHandler(newTestServer(t), nil)
+	if err != nil {
+		t.Fatalf("NewHTTPHandler failed: %v", err)
+	}
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	url := ts.URL + DefaultHTTPPath
+
+	resp := post(t, url, "", "application/json, text/event-stream", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`)
+	sessionID := resp.Header.Get(SessionIDHeader)
+	body := readBody(t, resp)
+	if sessionID == "" {
+		t.Fatal("expected a session ID")
+	}
+	if !strings.Contains(body, `"protocolVersion":"2025-03-26"`) {
+		t.Errorf("unexpected initialize response: %s", body)
+	}
+
+	resp = post(t, url, sessionID, "application/json", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	readBody(t, resp)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("expected 202 for a notification, got %d", resp.StatusCode)
+	}
+
+	resp = post(t, url, sessionID, "application/json", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	var list map[string]interface{}
+	if err := json.Unmarshal([]byte(readBody(t, resp)), &list); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	if tools := list["result"].(map[string]interface{})["tools"].([]interface{}); len(tools) != 2 {
+		t.Errorf("expected 2 tools, got %d", len(tools))
+	}
+
+	resp = post(t, url, sessionID, "application/json, text/event-stream",
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"message":"hi"},"_meta":{"progressToken":7}}}`)
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected an event stream, got %s", ct)
+	}
+	body = readBody(t, resp)
+	progress := strings.Index(body, "notifications/progress")
+	result := strings.Index(body, `"text":"hi"`)
+	if progress < 0 || result < 0 || progress > result {
+		t.Errorf("expected progress before the result, got %s", body)
+	}
+
+	resp = post(t, url, sessionID, "application/json", `[{"jsonrpc":"2.0","id":4,"method":"ping"},{"jsonrpc":"2.0","id":5,"method":"ping"}]`)
+	var batch []interface{}
+	if err := json.Unmarshal([]byte(readBody(t, resp)), &batch); err != nil || len(batch) != 2 {
+		t.Errorf("expected 2 batch responses, got %v (%v)", batch, err)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, url, nil)
+	req.Header.Set(SessionIDHeader, sessionID)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	readBody(t, resp)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204 for DELETE, got %d", resp.StatusCode)
+	}
+
+	resp = post(t, url, sessionID, "application/json", `{"jsonrpc":"2.0","id":6,"method":"tools/list"}`)
+	readBody(t, resp)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for an ended session, got %d", resp.StatusCode)
+	}
+}
+
+// TestHTTPHandlerRejections tests session, method, origin and auth checks.
+func TestHTTPHandlerRejections(t *testing.T) {
+	handler, err := NewHTTPHandler(newTestServer(t), &HTTPOptions{
+		Path:       "/tools",
+		Middleware: []func(http.Handler) http.Handler{BearerAuth("s3cret")},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPHandler failed: %v", err)
+	}
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	url := ts.URL + "/tools"
+	auth := []string{"Authorization", "Bearer s3cret"}
+
+	tests := []struct {
+		name    string
+		body    string
+		headers []string
+		status  int
+	}{
+		{"no token", `{"jsonrpc":"2.0","id":1,"method":"initialize"}`, nil, http.StatusUnauthorized},
+		{"wrong token", `{"jsonrpc":"2.0","id":1,"method":"initialize"}`, []string{"Authorization", "Bearer nope"}, http.StatusUnauthorized},
+		{"missing session", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`, auth, http.StatusBadRequest},
+		{"unknown session", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`, append([]string{SessionIDHeader, "nope"}, auth...), http.StatusNotFound},
+		{"foreign origin", `{"jsonrpc":"2.0","id":1,"method":"initialize"}`, append([]string{"Origin", "https://evil.example"}, auth...), http.StatusForbidden},
+		{"invalid json", `{`, auth, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := post(t, url, "", "application/json", tt.body, tt.headers...)
+			readBody(t, resp)
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	readBody(t, resp)
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET, got %d", resp.StatusCode)
+	}
+
+	resp = post(t, url, "", "application/json", `{"jsonrpc":"2.0","id":1,"method":"initialize"}`, auth...)
+	readBody(t, resp)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(SessionIDHeader) == "" {
+		t.Errorf("expected an authorized initialize to start a session, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,175 @@
+// Package mcpserve exposes SDK MCP servers to external MCP clients, such as
+// Claude Desktop or other MCP hosts, so tools built with this SDK can be
+// reused outside an agent session. Stdio serves a single client over
+// standard input and output; HTTP shares the tools across machines over the
+// streamable HTTP transport.
+//
+// Example:
+//
+//	server := types.CreateToolServer("my-tools", "1.0.0", tools)
+//	if err := mcpserve.Stdio(server); err != nil {
+//	    log.Fatal(err)
+//	}
+package mcpserve
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/M1n9X/claude-agent-sdk-go/internal/mcp"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// LatestProtocolVersion is the MCP protocol version offered to clients that
+// request a version this package does not support.
+const LatestProtocolVersion = "2025-06-18"
+
+// supportedProtocolVersions lists the MCP protocol versions clients may request.
+var supportedProtocolVersions = map[string]bool{
+	"2024-11-05": true,
+	"2025-03-26": true,
+	"2025-06-18": true,
+}
+
+// contextMessageHandler is an MCP server whose calls can be canceled.
+type contextMessageHandler interface {
+	HandleMessageContext(ctx context.Context, message map[string]interface{}) (map[string]interface{}, error)
+}
+
+// notifier is an MCP server that notifies clients when its tool or
+// resource list changes.
+type notifier interface {
+	AddNotificationHandler(handler func(notification map[string]interface{})) (remove func())
+}
+
+// session dispatches the JSON-RPC messages of one client connection to a server.
+type session struct {
+	server types.MCPServer
+
+	mu       sync.Mutex
+	inFlight map[string]context.CancelFunc // JSON-RPC request ID -> cancel
+}
+
+// newSession returns a session serving config.
+func newSession(config *types.ToolServerConfig) (*session, error) {
+	server, err := mcp.ServerFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &session{server: server, inFlight: make(map[string]context.CancelFunc)}, nil
+}
+
+// handle processes a JSON-RPC message and returns the response, or nil for
+// notifications, which have no response. Progress reported by the tool a
+// request calls is sent to the client through notify.
+func (s *session) handle(ctx context.Context, msg map[string]interface{}, notify func(notification map[string]interface{})) map[string]interface{} {
+	method, _ := msg["method"].(string)
+	id, isRequest := msg["id"]
+	if !isRequest {
+		if method == "notifications/cancelled" {
+			params, _ := msg["params"].(map[string]interface{})
+			s.cancel(params["requestId"])
+		}
+		return nil
+	}
+
+	switch method {
+	case "ping":
+		return map[string]interface{}{"jsonrpc": "2.0", "id": id, "result": map[string]interface{}{}}
+	case "":
+		return errorResponse(id, mcp.ErrorCodeInvalidRequest, "missing or invalid method field")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	ctx = mcp.ContextWithProgressHandler(ctx, func(p types.ToolProgress) {
+		if notification := mcp.ProgressNotification(p); notification != nil {
+			notify(notification)
+		}
+	})
+	ctx = mcp.ContextWithPanicHandler(ctx, func(p types.ToolPanic) {
+		fmt.Fprintf(os.Stderr, "mcpserve: tool %s panicked: %v\n%s", p.Tool, p.Value, p.Stack)
+	})
+	key := requestKey(id)
+	s.mu.Lock()
+	s.inFlight[key] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.inFlight, key)
+		s.mu.Unlock()
+		cancel()
+	}()
+
+	var response map[string]interface{}
+	var err error
+	if handler, ok := s.server.(contextMessageHandler); ok {
+		response, err = handler.HandleMessageContext(ctx, msg)
+	} else {
+		response, err = s.server.HandleMessage(msg)
+	}
+	if err != nil {
+		return errorResponse(id, mcp.ErrorCodeInternalError, err.Error())
+	}
+
+	if method == "initialize" {
+		negotiateProtocolVersion(msg, response)
+	}
+	return response
+}
+
+// cancel cancels the in-flight request with the given ID.
+func (s *session) cancel(id interface{}) {
+	if id == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.inFlight[requestKey(id)]; ok {
+		cancel()
+	}
+}
+
+// close cancels every in-flight request.
+func (s *session) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, cancel := range s.inFlight {
+		cancel()
+	}
+}
+
+// requestKey returns a map key for a JSON-RPC request ID, which may be a
+// string or a number.
+func requestKey(id interface{}) string {
+	return fmt.Sprintf("%T:%v", id, id)
+}
+
+// negotiateProtocolVersion answers initialize with the client's protocol
+// version when it is supported, and LatestProtocolVersion otherwise.
+func negotiateProtocolVersion(request, response map[string]interface{}) {
+	result, ok := response["result"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	version := LatestProtocolVersion
+	if params, ok := request["params"].(map[string]interface{}); ok {
+		if requested, _ := params["protocolVersion"].(string); supportedProtocolVersions[requested] {
+			version = requested
+		}
+	}
+	result["protocolVersion"] = version
+}
+
+// errorResponse returns a JSON-RPC error response.
+func errorResponse(id interface{}, code int, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	}
+}
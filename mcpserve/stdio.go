@@ -0,0 +1,104 @@
+package mcpserve
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/M1n9X/claude-agent-sdk-go/internal/mcp"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Stdio serves server over standard input and output until standard input
+// is closed. Nothing else may write to standard output while it runs; log
+// to standard error instead.
+func Stdio(server *types.ToolServerConfig) error {
+	return ServeStdio(context.Background(), server, os.Stdin, os.Stdout)
+}
+
+// ServeStdio serves server over the MCP stdio transport: newline-delimited
+// JSON-RPC messages read from r, with responses and notifications written
+// to w. Requests are handled concurrently. It returns when r reaches EOF or
+// ctx is canceled, after the requests in progress have finished.
+func ServeStdio(ctx context.Context, server *types.ToolServerConfig, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var writeMu sync.Mutex
+	write := func(msg map[string]interface{}) {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mcpserve: failed to encode message: %v\n", err)
+			return
+		}
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			fmt.Fprintf(os.Stderr, "mcpserve: failed to write message: %v\n", err)
+		}
+	}
+
+	s, err := newSession(server)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	if n, ok := s.server.(notifier); ok {
+		defer n.AddNotificationHandler(write)()
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.close()
+			return ctx.Err()
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read MCP message: %w", err)
+		case line := <-lines:
+			var msg map[string]interface{}
+			if err := json.Unmarshal(line, &msg); err != nil {
+				write(errorResponse(nil, mcp.ErrorCodeParseError, fmt.Sprintf("parse error: %v", err)))
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if response := s.handle(ctx, msg, write); response != nil {
+					write(response)
+				}
+			}()
+		}
+	}
+}
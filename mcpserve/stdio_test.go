@@ -0,0 +1,170 @@
+package mcpserve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// newTestServer returns a server with an echo tool and a slow tool that waits for cancellation.
+func newTestServer(t *testing.T) *types.ToolServerConfig {
+	t.Helper()
+	echo, err := types.NewTool("echo").
+		Description("Echo a message").
+		StringParam("message", "Message to echo", true).
+		Handler(func(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+			types.ReportToolProgress(ctx, 1, 1, "echoing")
+			return types.NewMcpToolResult(types.TextBlock{Type: "text", Text: input["message"].(string)}), nil
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build tool: %v", err)
+	}
+	slow, err := types.NewTool("slow").
+		Description("Wait until canceled").
+		Handler(func(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build tool: %v", err)
+	}
+	return types.CreateToolServer("test", "1.0.0", []types.McpTool{echo, slow})
+}
+
+// decodeLines decodes newline-delimited JSON messages, keyed by request ID.
+func decodeLines(t *testing.T, data string) (map[float64]map[string]interface{}, []map[string]interface{}) {
+	t.Helper()
+	responses := make(map[float64]map[string]interface{})
+	var notifications []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		var msg map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("invalid output line %q: %v", line, err)
+		}
+		if id, ok := msg["id"].(float64); ok {
+			responses[id] = msg
+		} else if _, ok := msg["method"]; ok {
+			notifications = append(notifications, msg)
+		} else {
+			responses[-1] = msg
+		}
+	}
+	return responses, notifications
+}
+
+// TestServeStdio tests a client session over the stdio transport.
+func TestServeStdio(t *testing.T) {
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"message":"hi"},"_meta":{"progressToken":"p1"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"ping"}`,
+		``,
+		`not json`,
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	if err := ServeStdio(context.Background(), newTestServer(t), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("ServeStdio failed: %v", err)
+	}
+
+	responses, notifications := decodeLines(t, out.String())
+	if len(responses) != 5 {
+		t.Fatalf("expected 5 responses, got %d: %s", len(responses), out.String())
+	}
+
+	result := responses[1]["result"].(map[string]interface{})
+	if result["protocolVersion"] != "2024-11-05" {
+		t.Errorf("expected the requested protocol version, got %v", result["protocolVersion"])
+	}
+	tools := responses[2]["result"].(map[string]interface{})["tools"].([]interface{})
+	if len(tools) != 2 {
+		t.Errorf("expected 2 tools, got %d", len(tools))
+	}
+	if data, _ := json.Marshal(responses[3]); !strings.Contains(string(data), `"text":"hi"`) {
+		t.Errorf("unexpected tools/call response: %s", data)
+	}
+	if _, ok := responses[4]["result"]; !ok {
+		t.Errorf("expected a ping result, got %v", responses[4])
+	}
+	if code := responses[-1]["error"].(map[string]interface{})["code"]; code != float64(-32700) {
+		t.Errorf("expected a parse error, got %v", responses[-1])
+	}
+
+	if len(notifications) != 1 || notifications[0]["method"] != "notifications/progress" {
+		t.Errorf("expected one progress notification, got %v", notifications)
+	}
+}
+
+// TestServeStdioUnsupportedVersion tests that unknown protocol versions get the latest version.
+func TestServeStdioUnsupportedVersion(t *testing.T) {
+	input := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}` + "\n"
+	var out bytes.Buffer
+	if err := ServeStdio(context.Background(), newTestServer(t), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("ServeStdio failed: %v", err)
+	}
+	responses, _ := decodeLines(t, out.String())
+	if version := responses[1]["result"].(map[string]interface{})["protocolVersion"]; version != LatestProtocolVersion {
+		t.Errorf("expected %s, got %v", LatestProtocolVersion, version)
+	}
+}
+
+// TestServeStdioCancel tests that notifications/cancelled stops a running tool call.
+func TestServeStdioCancel(t *testing.T) {
+	r, w := io.Pipe()
+	var out safeBuffer
+	done := make(chan error, 1)
+	go func() {
+		done <- ServeStdio(context.Background(), newTestServer(t), r, &out)
+	}()
+
+	write := func(line string) {
+		if _, err := w.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	write(`{"jsonrpc":"2.0","id":"call-1","method":"tools/call","params":{"name":"slow","arguments":{}}}`)
+	time.Sleep(20 * time.Millisecond)
+	write(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"call-1"}}`)
+	w.Close()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ServeStdio failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the canceled call to finish")
+	}
+	if !strings.Contains(out.String(), "canceled") {
+		t.Errorf("expected a canceled error response, got %s", out.String())
+	}
+}
+
+// safeBuffer is a bytes.Buffer safe for concurrent use.
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
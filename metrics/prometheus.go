@@ -0,0 +1,338 @@
+// Package metrics exports the SDK's measurements to Prometheus, without
+// depending on the Prometheus client library.
+//
+// A Prometheus implements types.Metrics and serves its metrics in the
+// Prometheus text format:
+//
+//	prom := metrics.NewPrometheus("claude_agent")
+//	opts := types.NewClaudeAgentOptions().WithMetrics(prom)
+//	http.Handle("/metrics", prom)
+//
+// One Prometheus can be shared by every client of a process.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Histogram bucket upper bounds of the metrics.
+var (
+	QueryDurationBuckets = []float64{1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800}              // Seconds
+	QueryTurnsBuckets    = []float64{1, 2, 3, 5, 8, 13, 21, 34, 55}                           // Turns
+	ToolDurationBuckets  = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10} // Seconds
+)
+
+// Prometheus collects the SDK's metrics for Prometheus:
+//
+//   - <namespace>_queries_total{stop_reason,is_error}: completed queries
+//   - <namespace>_query_duration_seconds: query durations
+//   - <namespace>_query_turns: turns per query
+//   - <namespace>_tokens_total{type}: input, output, cache_read and
+//     cache_creation tokens
+//   - <namespace>_cost_usd_total: cost of the queries
+//   - <namespace>_tool_calls_total{tool}: tool uses requested by Claude
+//   - <namespace>_sdk_tool_duration_seconds{tool}: SDK MCP tool call durations
+//   - <namespace>_sdk_tool_errors_total{tool}: failed SDK MCP tool calls
+//   - <namespace>_transport_errors_total{type}: CLI transport errors, by Go
+//     error type
+//
+// The statistics of the sources added with AddToolStats are exported too.
+//
+// It is safe for concurrent use.
+type Prometheus struct {
+	namespace string
+
+	mu         sync.Mutex
+	counters   map[string]*counter
+	histograms map[string]*histogram
+	toolStats  []ToolStatsSource
+}
+
+// ToolStatsSource provides the per-tool statistics of SDK MCP servers, such
+// as a *types.ToolServerConfig or a *types.ToolStatsRecorder.
+type ToolStatsSource interface {
+	Stats() []types.ToolStats
+}
+
+// counter is a counter metric with its values by label set.
+type counter struct {
+	help   string
+	values map[string]float64 // Keyed by formatted labels
+}
+
+// histogram is a histogram metric with its observations by label set.
+type histogram struct {
+	help    string
+	buckets []float64
+	series  map[string]*histogramSeries // Keyed by formatted labels
+}
+
+// histogramSeries is the observations of a histogram for one label set.
+type histogramSeries struct {
+	counts []uint64 // Per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheus creates a collector whose metric names start with
+// namespace, such as "claude_agent".
+func NewPrometheus(namespace string) *Prometheus {
+	p := &Prometheus{
+		namespace:  namespace,
+		counters:   make(map[string]*counter),
+		histograms: make(map[string]*histogram),
+	}
+	p.addCounter("queries_total", "Completed queries.")
+	p.addCounter("tokens_total", "Tokens used by completed queries.")
+	p.addCounter("cost_usd_total", "Cost of completed queries in USD.")
+	p.addCounter("tool_calls_total", "Tool uses requested by Claude.")
+	p.addCounter("sdk_tool_errors_total", "Failed SDK MCP tool calls.")
+	p.addCounter("transport_errors_total", "Errors reading from or writing to the Claude CLI.")
+	p.addHistogram("query_duration_seconds", "Duration of completed queries.", QueryDurationBuckets)
+	p.addHistogram("query_turns", "Turns of completed queries.", QueryTurnsBuckets)
+	p.addHistogram("sdk_tool_duration_seconds", "Duration of SDK MCP tool calls.", ToolDurationBuckets)
+	return p
+}
+
+var _ types.Metrics = (*Prometheus)(nil)
+
+// QueryCompleted counts a query with its tokens and cost.
+func (p *Prometheus) QueryCompleted(m types.QueryMetrics) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.add("queries_total", 1, "stop_reason", string(m.StopReason), "is_error", strconv.FormatBool(m.IsError))
+	p.observe("query_duration_seconds", m.Duration.Seconds())
+	p.observe("query_turns", float64(m.Turns))
+	p.add("tokens_total", float64(m.Usage.InputTokens), "type", "input")
+	p.add("tokens_total", float64(m.Usage.OutputTokens), "type", "output")
+	p.add("tokens_total", float64(m.Usage.CacheReadInputTokens), "type", "cache_read")
+	p.add("tokens_total", float64(m.Usage.CacheCreationInputTokens), "type", "cache_creation")
+	p.add("cost_usd_total", m.CostUSD)
+}
+
+// ToolCalled counts a tool use.
+func (p *Prometheus) ToolCalled(tool string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.add("tool_calls_total", 1, "tool", tool)
+}
+
+// SDKToolCompleted records the duration of an SDK MCP tool call, and counts
+// it if it failed.
+func (p *Prometheus) SDKToolCompleted(tool string, duration time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.observe("sdk_tool_duration_seconds", duration.Seconds(), "tool", tool)
+	if err != nil {
+		p.add("sdk_tool_errors_total", 1, "tool", tool)
+	}
+}
+
+// TransportError counts a transport error by its type, such as
+// "CLIConnectionError".
+func (p *Prometheus) TransportError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.add("transport_errors_total", 1, "type", errorType(err))
+}
+
+// AddToolStats exports the statistics of source, read each time the metrics
+// are written:
+//
+//   - <namespace>_mcp_tool_calls_total{server,tool}: completed calls
+//   - <namespace>_mcp_tool_errors_total{server,tool}: calls that returned an
+//     error or an error result
+//   - <namespace>_mcp_tool_panics_total{server,tool}: calls whose handler
+//     panicked
+//   - <namespace>_mcp_tool_duration_seconds{server,tool}: summary of the call
+//     durations, without quantiles
+//   - <namespace>_mcp_tool_duration_seconds_max{server,tool}: the longest call
+func (p *Prometheus) AddToolStats(source ToolStatsSource) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.toolStats = append(p.toolStats, source)
+}
+
+// errorType returns the name of the Go type of err, without its package.
+func errorType(err error) string {
+	name := fmt.Sprintf("%T", err)
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = p.WriteText(w)
+}
+
+// WriteText writes the metrics in the Prometheus text format, sorted by name
+// and labels.
+func (p *Prometheus) WriteText(w io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var b strings.Builder
+	for _, name := range sortedNames(p.counters) {
+		c := p.counters[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", name, c.help, name)
+		for _, labels := range sortedNames(c.values) {
+			fmt.Fprintf(&b, "%s%s %s\n", name, braced(labels), formatFloat(c.values[labels]))
+		}
+	}
+	for _, name := range sortedNames(p.histograms) {
+		h := p.histograms[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s histogram\n", name, h.help, name)
+		for _, labels := range sortedNames(h.series) {
+			s := h.series[labels]
+			var cumulative uint64
+			for i, bound := range h.buckets {
+				cumulative += s.counts[i]
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", name, braced(joinLabels(labels, `le="`+formatFloat(bound)+`"`)), cumulative)
+			}
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", name, braced(joinLabels(labels, `le="+Inf"`)), s.count)
+			fmt.Fprintf(&b, "%s_sum%s %s\n", name, braced(labels), formatFloat(s.sum))
+			fmt.Fprintf(&b, "%s_count%s %d\n", name, braced(labels), s.count)
+		}
+	}
+	p.writeToolStats(&b)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeToolStats writes the statistics of the tool stats sources. The
+// caller holds p.mu.
+func (p *Prometheus) writeToolStats(b *strings.Builder) {
+	if len(p.toolStats) == 0 {
+		return
+	}
+	var stats []types.ToolStats
+	for _, source := range p.toolStats {
+		stats = append(stats, source.Stats()...)
+	}
+
+	families := []struct {
+		name, kind, help string
+		value            func(s types.ToolStats) float64
+	}{
+		{"mcp_tool_calls_total", "counter", "Completed SDK MCP tool calls.",
+			func(s types.ToolStats) float64 { return float64(s.Calls) }},
+		{"mcp_tool_errors_total", "counter", "SDK MCP tool calls that returned an error or an error result.",
+			func(s types.ToolStats) float64 { return float64(s.Errors) }},
+		{"mcp_tool_panics_total", "counter", "SDK MCP tool calls whose handler panicked.",
+			func(s types.ToolStats) float64 { return float64(s.Panics) }},
+		{"mcp_tool_duration_seconds_max", "gauge", "Longest SDK MCP tool call.",
+			func(s types.ToolStats) float64 { return s.MaxDuration.Seconds() }},
+	}
+	for _, family := range families {
+		name := p.namespace + "_" + family.name
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, family.help, name, family.kind)
+		for _, s := range stats {
+			fmt.Fprintf(b, "%s%s %s\n", name, toolStatsLabels(s), formatFloat(family.value(s)))
+		}
+	}
+
+	name := p.namespace + "_mcp_tool_duration_seconds"
+	fmt.Fprintf(b, "# HELP %s Duration of SDK MCP tool calls.\n# TYPE %s summary\n", name, name)
+	for _, s := range stats {
+		fmt.Fprintf(b, "%s_sum%s %s\n", name, toolStatsLabels(s), formatFloat(s.TotalDuration.Seconds()))
+		fmt.Fprintf(b, "%s_count%s %d\n", name, toolStatsLabels(s), s.Calls)
+	}
+}
+
+// toolStatsLabels returns the braced labels identifying the tool of s.
+func toolStatsLabels(s types.ToolStats) string {
+	return braced(formatLabels([]string{"server", s.Server, "tool", s.Tool}))
+}
+
+// addCounter declares a counter.
+func (p *Prometheus) addCounter(name, help string) {
+	p.counters[p.namespace+"_"+name] = &counter{help: help, values: make(map[string]float64)}
+}
+
+// addHistogram declares a histogram.
+func (p *Prometheus) addHistogram(name, help string, buckets []float64) {
+	p.histograms[p.namespace+"_"+name] = &histogram{help: help, buckets: buckets, series: make(map[string]*histogramSeries)}
+}
+
+// add adds to a counter with labels given as name-value pairs. The caller
+// holds p.mu.
+func (p *Prometheus) add(name string, value float64, labels ...string) {
+	p.counters[p.namespace+"_"+name].values[formatLabels(labels)] += value
+}
+
+// observe adds an observation to a histogram with labels given as
+// name-value pairs. The caller holds p.mu.
+func (p *Prometheus) observe(name string, value float64, labels ...string) {
+	h := p.histograms[p.namespace+"_"+name]
+	key := formatLabels(labels)
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+			break
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+// formatLabels formats name-value pairs as `a="x",b="y"`.
+func formatLabels(labels []string) string {
+	parts := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		parts = append(parts, labels[i]+`="`+labelEscaper.Replace(labels[i+1])+`"`)
+	}
+	return strings.Join(parts, ",")
+}
+
+// labelEscaper escapes label values as the text format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// joinLabels joins two formatted label lists.
+func joinLabels(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "," + b
+}
+
+// braced returns formatted labels in braces, or nothing for no labels.
+func braced(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+// formatFloat formats a sample value.
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedNames returns the keys of a map, sorted.
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
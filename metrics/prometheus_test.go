@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// TestPrometheus tests the text format of the collected metrics
+func TestPrometheus(t *testing.T) {
+	prom := NewPrometheus("agent")
+	prom.QueryCompleted(types.QueryMetrics{
+		Duration:   4 * time.Second,
+		Turns:      3,
+		StopReason: types.StopReasonCompleted,
+		Usage:      types.TokenUsage{InputTokens: 100, OutputTokens: 20},
+		CostUSD:    0.25,
+	})
+	prom.ToolCalled("Bash")
+	prom.ToolCalled("Bash")
+	prom.SDKToolCompleted("add", 30*time.Millisecond, nil)
+	prom.SDKToolCompleted("add", 2*time.Second, errors.New("failed"))
+	prom.TransportError(types.NewCLIConnectionError("broken pipe"))
+
+	rec := httptest.NewRecorder()
+	prom.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE agent_queries_total counter\n",
+		`agent_queries_total{stop_reason="completed",is_error="false"} 1`,
+		`agent_tokens_total{type="input"} 100`,
+		`agent_tokens_total{type="output"} 20`,
+		"agent_cost_usd_total 0.25",
+		`agent_tool_calls_total{tool="Bash"} 2`,
+		`agent_sdk_tool_errors_total{tool="add"} 1`,
+		`agent_transport_errors_total{type="CLIConnectionError"} 1`,
+		"# TYPE agent_query_duration_seconds histogram\n",
+		`agent_query_duration_seconds_bucket{le="2.5"} 0`,
+		`agent_query_duration_seconds_bucket{le="5"} 1`,
+		`agent_query_duration_seconds_bucket{le="+Inf"} 1`,
+		"agent_query_duration_seconds_sum 4",
+		`agent_sdk_tool_duration_seconds_bucket{tool="add",le="0.05"} 1`,
+		`agent_sdk_tool_duration_seconds_bucket{tool="add",le="2.5"} 2`,
+		`agent_sdk_tool_duration_seconds_count{tool="add"} 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics lack %q:\n%s", want, body)
+		}
+	}
+}
+
+// TestPrometheusLabelEscaping tests that label values are escaped
+func TestPrometheusLabelEscaping(t *testing.T) {
+	prom := NewPrometheus("agent")
+	prom.ToolCalled("mcp__x__\"q\"\n")
+	var b strings.Builder
+	if err := prom.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	if want := `agent_tool_calls_total{tool="mcp__x__\"q\"\n"} 1`; !strings.Contains(b.String(), want) {
+		t.Errorf("metrics lack %q:\n%s", want, b.String())
+	}
+}
+
+// TestPrometheusToolStats tests the export of SDK MCP tool statistics
+func TestPrometheusToolStats(t *testing.T) {
+	recorder := types.NewToolStatsRecorder()
+	recorder.Record(types.ToolCallRecord{Server: `my"server`, Tool: "search", Duration: 500 * time.Millisecond})
+	recorder.Record(types.ToolCallRecord{Server: `my"server`, Tool: "search", Duration: 1500 * time.Millisecond, IsError: true})
+
+	prom := NewPrometheus("agent")
+	prom.AddToolStats(recorder)
+	var b strings.Builder
+	if err := prom.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# TYPE agent_mcp_tool_calls_total counter\n",
+		`agent_mcp_tool_calls_total{server="my\"server",tool="search"} 2` + "\n",
+		`agent_mcp_tool_errors_total{server="my\"server",tool="search"} 1` + "\n",
+		`agent_mcp_tool_panics_total{server="my\"server",tool="search"} 0` + "\n",
+		`agent_mcp_tool_duration_seconds_max{server="my\"server",tool="search"} 1.5` + "\n",
+		"# TYPE agent_mcp_tool_duration_seconds summary\n",
+		`agent_mcp_tool_duration_seconds_sum{server="my\"server",tool="search"} 2` + "\n",
+		`agent_mcp_tool_duration_seconds_count{server="my\"server",tool="search"} 2` + "\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics lack %q:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package oteltracing adapts OpenTelemetry to the SDK's types.Tracer, so
+// that the SDK's spans are exported with the rest of an application's
+// traces. It is a module of its own, so that the SDK does not depend on
+// OpenTelemetry.
+//
+//	opts := types.NewClaudeAgentOptions().WithTracer(oteltracing.New(tracerProvider))
+//
+// Span attributes follow the OpenTelemetry GenAI conventions where they have
+// one, such as gen_ai.tool.name and gen_ai.usage.output_tokens.
+package oteltracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// instrumentationName names the tracer of the SDK's spans.
+const instrumentationName = "github.com/M1n9X/claude-agent-sdk-go"
+
+// Tracer creates the SDK's spans with an OpenTelemetry tracer.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// New creates a Tracer with the tracer provider tp, or the global provider
+// if tp is nil.
+func New(tp trace.TracerProvider) *Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &Tracer{tracer: tp.Tracer(instrumentationName)}
+}
+
+// Start starts an OpenTelemetry span as a child of the span in ctx.
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...types.SpanAttribute) (context.Context, types.Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithAttributes(attributes(attrs)...))
+	return ctx, &Span{span: span}
+}
+
+// ContextWithSpan returns ctx with span, if it was started by a Tracer.
+func (t *Tracer) ContextWithSpan(ctx context.Context, span types.Span) context.Context {
+	if s, ok := span.(*Span); ok {
+		return trace.ContextWithSpan(ctx, s.span)
+	}
+	return ctx
+}
+
+// Span is an OpenTelemetry span started by a Tracer.
+type Span struct {
+	span trace.Span
+}
+
+// SetAttributes sets attributes of the span.
+func (s *Span) SetAttributes(attrs ...types.SpanAttribute) {
+	s.span.SetAttributes(attributes(attrs)...)
+}
+
+// End ends the span, recording err and an error status if err is not nil.
+func (s *Span) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// attributes converts the SDK's span attributes to OpenTelemetry ones.
+func attributes(attrs []types.SpanAttribute) []attribute.KeyValue {
+	converted := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		key := attribute.Key(attr.Key)
+		switch v := attr.Value.(type) {
+		case string:
+			converted = append(converted, key.String(v))
+		case int:
+			converted = append(converted, key.Int(v))
+		case int64:
+			converted = append(converted, key.Int64(v))
+		case float64:
+			converted = append(converted, key.Float64(v))
+		case bool:
+			converted = append(converted, key.Bool(v))
+		default:
+			converted = append(converted, key.String(fmt.Sprint(v)))
+		}
+	}
+	return converted
+}
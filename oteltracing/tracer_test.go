@@ -0,0 +1,64 @@
+package oteltracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// TestTracer tests that spans are started as children, with their
+// attributes and error status
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := New(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	_, query := tracer.Start(context.Background(), "claude.query", types.SpanAttribute{Key: "claude.correlation_id", Value: "req-1"})
+	ctx := tracer.ContextWithSpan(context.Background(), query)
+	_, tool := tracer.Start(ctx, "claude.tool", types.SpanAttribute{Key: "gen_ai.tool.name", Value: "Read"})
+	tool.End(errors.New("tool returned an error result"))
+	query.SetAttributes(
+		types.SpanAttribute{Key: "gen_ai.usage.output_tokens", Value: 7},
+		types.SpanAttribute{Key: "claude.cost_usd", Value: 0.25},
+		types.SpanAttribute{Key: "claude.hook.async", Value: true},
+	)
+	query.End(nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended %d spans, want 2", len(spans))
+	}
+	toolSpan, querySpan := spans[0], spans[1]
+	if toolSpan.Parent().SpanID() != querySpan.SpanContext().SpanID() {
+		t.Errorf("tool span parent = %v, want the query span", toolSpan.Parent().SpanID())
+	}
+	if toolSpan.Status().Code != codes.Error || len(toolSpan.Events()) != 1 {
+		t.Errorf("tool span status = %v, events = %d, want the recorded error", toolSpan.Status(), len(toolSpan.Events()))
+	}
+	if querySpan.Status().Code == codes.Error {
+		t.Errorf("query span status = %v, want no error", querySpan.Status())
+	}
+
+	want := map[string]string{
+		"claude.correlation_id":      "req-1",
+		"gen_ai.usage.output_tokens": "7",
+		"claude.cost_usd":            "0.25",
+		"claude.hook.async":          "true",
+	}
+	for _, attr := range querySpan.Attributes() {
+		if value, ok := want[string(attr.Key)]; ok {
+			if got := attr.Value.Emit(); got != value {
+				t.Errorf("%s = %q, want %q", attr.Key, got, value)
+			}
+			delete(want, string(attr.Key))
+		}
+	}
+	if len(want) != 0 {
+		t.Errorf("missing query attributes %v", want)
+	}
+}
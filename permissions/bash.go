@@ -0,0 +1,529 @@
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// BashRule is a permission rule for one executable.
+type BashRule struct {
+	// Executable is a glob matched against the command name without its
+	// directory, so "rm" also matches "/bin/rm".
+	Executable string
+
+	// Args is a glob matched against the arguments joined by single spaces.
+	// "*" matches any sequence of characters, including spaces. An empty
+	// pattern matches any arguments.
+	Args string
+
+	Behavior types.PermissionBehavior
+	Reason   string
+}
+
+// BashDecision is the outcome of evaluating a command against a BashPolicy.
+type BashDecision struct {
+	// Behavior is "allow", "deny" or "ask", or empty when no rule matched
+	// and the policy has no default.
+	Behavior types.PermissionBehavior
+	Reason   string
+
+	// Command is the simple command that determined the decision.
+	Command string
+}
+
+// wrapper describes the command line of a command that runs its arguments
+// as another command, so that the options of the wrapper are not taken for
+// the command it runs.
+type wrapper struct {
+	values     []string // Options taking a value, such as "-u" of sudo
+	flags      []string // Options without a value
+	positional int      // Arguments before the command, such as the duration of timeout
+	script     []string // Options whose value is a command line, such as "-S" of env
+}
+
+// wrapperCommands run their arguments as another command. Options they do
+// not list make the command they run unknown.
+var wrapperCommands = map[string]wrapper{
+	"sudo": {
+		values: []string{"-u", "-g", "-C", "-p", "-r", "-t", "-U", "-D", "-R", "-T",
+			"--user", "--group", "--close-from", "--prompt", "--role", "--type", "--other-user",
+			"--chdir", "--chroot", "--command-timeout", "--host"},
+		flags: []string{"-A", "-b", "-E", "-e", "-H", "-h", "-i", "-K", "-k", "-l", "-n", "-P", "-S", "-s", "-V", "-v",
+			"--askpass", "--background", "--preserve-env", "--edit", "--set-home", "--help", "--login",
+			"--remove-timestamp", "--reset-timestamp", "--list", "--non-interactive", "--preserve-groups",
+			"--stdin", "--shell", "--version", "--validate"},
+	},
+	"doas": {values: []string{"-u", "-C"}, flags: []string{"-L", "-n", "-s"}},
+	"env": {
+		values: []string{"-u", "-C", "--unset", "--chdir"},
+		flags:  []string{"-", "-i", "-0", "-v", "--ignore-environment", "--null", "--debug"},
+		script: []string{"-S", "--split-string"},
+	},
+	"nohup":   {},
+	"time":    {values: []string{"-f", "-o", "--format", "--output"}, flags: []string{"-p", "-a", "-v", "-q", "--portability", "--append", "--verbose", "--quiet"}},
+	"command": {flags: []string{"-p", "-v", "-V"}},
+	"exec":    {values: []string{"-a"}, flags: []string{"-c", "-l"}},
+	"xargs": {
+		values: []string{"-I", "-n", "-P", "-L", "-s", "-d", "-E", "-a",
+			"--max-args", "--max-procs", "--max-lines", "--max-chars", "--delimiter", "--eof", "--arg-file", "--process-slot-var"},
+		flags: []string{"-0", "-r", "-t", "-p", "-x", "-e", "-i", "-l", "-o",
+			"--null", "--no-run-if-empty", "--verbose", "--interactive", "--exit", "--open-tty", "--replace"},
+	},
+	"nice":    {values: []string{"-n", "--adjustment"}},
+	"timeout": {values: []string{"-s", "-k", "--signal", "--kill-after"}, flags: []string{"-v", "--verbose", "--preserve-status", "--foreground"}, positional: 1},
+	"stdbuf":  {values: []string{"-i", "-o", "-e", "--input", "--output", "--error"}},
+	"ionice":  {values: []string{"-c", "-n", "-u", "--class", "--classdata", "--uid"}, flags: []string{"-t", "--ignore"}},
+	"chroot":  {values: []string{"--userspec", "--groups"}, flags: []string{"--skip-chdir"}, positional: 1},
+}
+
+// findExecActions are the actions of find that run a command, ended by ";"
+// or "+".
+var findExecActions = map[string]bool{
+	"-exec": true, "-execdir": true, "-ok": true, "-okdir": true,
+}
+
+// shellCommands run a command string given with -c.
+var shellCommands = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true,
+}
+
+// BashPolicy evaluates Bash tool commands against per-executable rules.
+//
+// Commands are parsed with ParseCommand, so every executable in a pipeline,
+// list or substitution is checked, as are commands run through wrappers such
+// as sudo, env, timeout or xargs, through "sh -c" and through the -exec
+// actions of find. For each command, deny rules take precedence over ask
+// rules, which take precedence over allow rules; commands no rule matches
+// get the default behavior. The decision for the whole line is the most
+// restrictive one, and a line is only allowed when every command in it is.
+// Lines that cannot be parsed are denied, and commands run by a wrapper
+// whose options are not understood are asked about.
+type BashPolicy struct {
+	rules           []compiledBashRule
+	defaultBehavior types.PermissionBehavior
+}
+
+// compiledBashRule is a BashRule with its globs compiled.
+type compiledBashRule struct {
+	BashRule
+	executable *regexp.Regexp
+	args       *regexp.Regexp
+}
+
+// NewBashPolicy creates a policy without rules or default behavior.
+func NewBashPolicy() *BashPolicy {
+	return &BashPolicy{}
+}
+
+// Allow adds a rule allowing executable with arguments matching args.
+func (p *BashPolicy) Allow(executable, args string) *BashPolicy {
+	return p.Add(BashRule{Executable: executable, Args: args, Behavior: types.PermissionBehaviorAllow})
+}
+
+// Ask adds a rule that asks the user before running executable with arguments matching args.
+func (p *BashPolicy) Ask(executable, args, reason string) *BashPolicy {
+	return p.Add(BashRule{Executable: executable, Args: args, Behavior: types.PermissionBehaviorAsk, Reason: reason})
+}
+
+// Deny adds a rule denying executable with arguments matching args.
+func (p *BashPolicy) Deny(executable, args, reason string) *BashPolicy {
+	return p.Add(BashRule{Executable: executable, Args: args, Behavior: types.PermissionBehaviorDeny, Reason: reason})
+}
+
+// Add adds a rule.
+func (p *BashPolicy) Add(rule BashRule) *BashPolicy {
+	compiled := compiledBashRule{BashRule: rule, executable: globRegexp(rule.Executable)}
+	if rule.Args != "" {
+		compiled.args = globRegexp(rule.Args)
+	}
+	p.rules = append(p.rules, compiled)
+	return p
+}
+
+// Default sets the behavior for commands no rule matches.
+func (p *BashPolicy) Default(behavior types.PermissionBehavior) *BashPolicy {
+	p.defaultBehavior = behavior
+	return p
+}
+
+// Evaluate returns the policy decision for a command line.
+func (p *BashPolicy) Evaluate(command string) BashDecision {
+	commands, err := ParseCommand(command)
+	if err != nil {
+		return BashDecision{
+			Behavior: types.PermissionBehaviorDeny,
+			Reason:   fmt.Sprintf("cannot analyze command: %v", err),
+			Command:  command,
+		}
+	}
+
+	// An undecided command keeps the line from being allowed as a whole
+	var decision BashDecision
+	first := true
+	expanded, unknown := expandCommands(commands)
+	decide := func(d BashDecision) {
+		if first || behaviorRank(d.Behavior) > behaviorRank(decision.Behavior) {
+			decision = d
+			first = false
+		}
+	}
+	for _, cmd := range expanded {
+		if cmd.Name == "" {
+			continue // Assignments and redirections only
+		}
+		decide(p.evaluateCommand(cmd))
+	}
+	for _, cmd := range unknown {
+		decide(BashDecision{
+			Behavior: types.PermissionBehaviorAsk,
+			Reason:   fmt.Sprintf("cannot analyze the command run by %s", filepath.Base(cmd.Name)),
+			Command:  cmd.String(),
+		})
+	}
+	return decision
+}
+
+// evaluateCommand returns the decision for a single command.
+func (p *BashPolicy) evaluateCommand(cmd SimpleCommand) BashDecision {
+	name := filepath.Base(cmd.Name)
+	args := strings.Join(cmd.Args, " ")
+
+	var decision BashDecision
+	matched := false
+	for _, rule := range p.rules {
+		if !rule.executable.MatchString(name) || (rule.args != nil && !rule.args.MatchString(args)) {
+			continue
+		}
+		if !matched || behaviorRank(rule.Behavior) > behaviorRank(decision.Behavior) {
+			decision = BashDecision{Behavior: rule.Behavior, Reason: rule.Reason, Command: cmd.String()}
+			matched = true
+		}
+	}
+	if !matched && p.defaultBehavior != "" {
+		decision = BashDecision{
+			Behavior: p.defaultBehavior,
+			Reason:   fmt.Sprintf("no rule for %s", name),
+			Command:  cmd.String(),
+		}
+	}
+	return decision
+}
+
+// Hook returns a PreToolUse hook matcher for the Bash tool that applies the
+// policy's decision. Commands with no decision are left to the CLI.
+func (p *BashPolicy) Hook() types.HookMatcher {
+	pattern := "Bash"
+	return types.HookMatcher{
+		Matcher: &pattern,
+		Hooks: []types.HookCallbackFunc{
+			func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
+				raw, _ := input.(map[string]interface{})
+				toolInput, _ := raw["tool_input"].(map[string]interface{})
+				command, _ := toolInput["command"].(string)
+
+				decision := p.Evaluate(command)
+				switch decision.Behavior {
+				case types.PermissionBehaviorAllow:
+					return types.AllowToolUse(decision.Reason), nil
+				case types.PermissionBehaviorAsk:
+					return types.AskToolUse(decision.Reason), nil
+				case types.PermissionBehaviorDeny:
+					return types.DenyToolUse(decision.describe()), nil
+				}
+				return map[string]interface{}{}, nil
+			},
+		},
+	}
+}
+
+// CanUseTool returns a permission callback that applies the policy to Bash
+// commands. Denied commands are rejected and allowed ones approved; commands
+// needing approval, and all other tools, are passed to next. A nil next
+// denies them.
+func (p *BashPolicy) CanUseTool(next types.CanUseToolFunc) types.CanUseToolFunc {
+	return func(ctx context.Context, toolName string, input map[string]interface{}, permCtx types.ToolPermissionContext) (interface{}, error) {
+		if toolName == "Bash" {
+			command, _ := input["command"].(string)
+			decision := p.Evaluate(command)
+			switch decision.Behavior {
+			case types.PermissionBehaviorDeny:
+				return types.PermissionResultDeny{Behavior: "deny", Message: decision.describe(), Source: types.PermissionSourceRule}, nil
+			case types.PermissionBehaviorAllow:
+				return types.PermissionResultAllow{Behavior: "allow", Source: types.PermissionSourceRule}, nil
+			}
+		}
+		if next != nil {
+			return next(ctx, toolName, input, permCtx)
+		}
+		return types.PermissionResultDeny{Behavior: "deny", Message: fmt.Sprintf("%s requires approval", toolName), Source: types.PermissionSourceRule}, nil
+	}
+}
+
+// describe returns a message explaining a decision.
+func (d BashDecision) describe() string {
+	if d.Reason == "" {
+		return fmt.Sprintf("command not allowed: %s", d.Command)
+	}
+	return fmt.Sprintf("%s: %s", d.Reason, d.Command)
+}
+
+// expandCommands adds the commands run by wrappers, "sh -c" and find to a
+// command list. It also returns the commands running another command that
+// could not be determined.
+func expandCommands(commands []SimpleCommand) (expanded, unknown []SimpleCommand) {
+	expanded = make([]SimpleCommand, 0, len(commands))
+	add := func(inner []SimpleCommand) {
+		innerExpanded, innerUnknown := expandCommands(inner)
+		expanded = append(expanded, innerExpanded...)
+		unknown = append(unknown, innerUnknown...)
+	}
+	for _, cmd := range commands {
+		expanded = append(expanded, cmd)
+		name := filepath.Base(cmd.Name)
+
+		if w, ok := wrapperCommands[name]; ok {
+			inner, ok := w.command(cmd.Args)
+			if !ok {
+				unknown = append(unknown, cmd)
+				continue
+			}
+			add(inner)
+			continue
+		}
+		switch {
+		case shellCommands[name]:
+			script, ok := shellScript(cmd.Args)
+			if !ok {
+				unknown = append(unknown, cmd)
+				continue
+			}
+			if script == "" {
+				continue
+			}
+			inner, err := ParseCommand(script)
+			if err != nil {
+				unknown = append(unknown, cmd)
+				continue
+			}
+			add(inner)
+		case name == "find":
+			inner, ok := findCommands(cmd.Args)
+			if !ok {
+				unknown = append(unknown, cmd)
+				continue
+			}
+			add(inner)
+		}
+	}
+	return expanded, unknown
+}
+
+// command returns the command a wrapper runs with args, skipping its
+// options, their values, its positional arguments and variable assignments.
+// It returns no command when args run none, and false when they cannot be
+// analyzed: an unknown option, or an option missing its value.
+func (w wrapper) command(args []string) ([]SimpleCommand, bool) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return w.commandAt(args, i+1)
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg, "=")
+			switch {
+			case contains(w.script, name):
+				if !hasValue {
+					if i+1 >= len(args) {
+						return nil, false
+					}
+					i++
+					value = args[i]
+				}
+				return scriptCommand(value, args[i+1:])
+			case contains(w.values, name):
+				if !hasValue {
+					if i+1 >= len(args) {
+						return nil, false
+					}
+					i++
+				}
+			case !contains(w.flags, name):
+				return nil, false
+			}
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			if isNumber(arg[1:]) {
+				continue // Old-style priority, as in "nice -5"
+			}
+			// A cluster of short options, the last of which may take a value
+			for j := 1; j < len(arg); j++ {
+				option := "-" + arg[j:j+1]
+				rest := arg[j+1:]
+				if contains(w.script, option) || contains(w.values, option) {
+					value := rest
+					if rest == "" {
+						if i+1 >= len(args) {
+							return nil, false
+						}
+						i++
+						value = args[i]
+					}
+					if contains(w.script, option) {
+						return scriptCommand(value, args[i+1:])
+					}
+					break
+				}
+				if !contains(w.flags, option) {
+					return nil, false
+				}
+			}
+		case arg == "-":
+			if !contains(w.flags, arg) {
+				return nil, false
+			}
+		case isAssignment(arg):
+			continue
+		default:
+			return w.commandAt(args, i)
+		}
+	}
+	return nil, true
+}
+
+// commandAt returns the command starting at args[i] after the positional
+// arguments of the wrapper.
+func (w wrapper) commandAt(args []string, i int) ([]SimpleCommand, bool) {
+	i += w.positional
+	if i >= len(args) {
+		return nil, true
+	}
+	return []SimpleCommand{{Name: args[i], Args: args[i+1:]}}, true
+}
+
+// shellScript returns the command string a shell runs with args: the first
+// operand when a -c flag is given, alone or clustered as in "-xc". It
+// returns an empty string when no -c flag is given, and false when -c is
+// given without a command string.
+func shellScript(args []string) (string, bool) {
+	command := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			if command && i+1 < len(args) {
+				return args[i+1], true
+			}
+			return "", !command
+		case arg == "--rcfile" || arg == "--init-file":
+			i++
+		case strings.HasPrefix(arg, "--"):
+		case len(arg) > 1 && (arg[0] == '-' || arg[0] == '+'):
+			for _, flag := range arg[1:] {
+				switch flag {
+				case 'c':
+					command = command || arg[0] == '-'
+				case 'o', 'O':
+					i++ // The option name is the next argument
+				}
+			}
+		default:
+			if command {
+				return arg, true
+			}
+			return "", true
+		}
+	}
+	return "", !command
+}
+
+// scriptCommand returns the command of a command line given as an option
+// value, as by "env -S", followed by the remaining arguments.
+func scriptCommand(script string, args []string) ([]SimpleCommand, bool) {
+	commands, err := ParseCommand(script)
+	if err != nil || len(commands) != 1 {
+		return nil, false
+	}
+	cmd := commands[0]
+	cmd.Args = append(cmd.Args, args...)
+	return []SimpleCommand{cmd}, true
+}
+
+// findCommands returns the commands run by the -exec actions of find. It
+// returns false when an action is not ended by ";" or "+".
+func findCommands(args []string) ([]SimpleCommand, bool) {
+	var commands []SimpleCommand
+	for i := 0; i < len(args); i++ {
+		if !findExecActions[args[i]] {
+			continue
+		}
+		end := i + 1
+		for end < len(args) && args[end] != ";" && args[end] != "+" {
+			end++
+		}
+		if end >= len(args) || end == i+1 {
+			return nil, false
+		}
+		commands = append(commands, SimpleCommand{Name: args[i+1], Args: args[i+2 : end]})
+		i = end
+	}
+	return commands, true
+}
+
+// contains reports whether list contains s.
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// isNumber reports whether s is a non-empty string of digits.
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// behaviorRank orders behaviors from least to most restrictive. No decision
+// ranks above allow, so a line is only allowed if all of its commands are.
+func behaviorRank(behavior types.PermissionBehavior) int {
+	switch behavior {
+	case types.PermissionBehaviorAllow:
+		return 1
+	case types.PermissionBehaviorAsk:
+		return 3
+	case types.PermissionBehaviorDeny:
+		return 4
+	}
+	return 2
+}
+
+// globRegexp compiles a glob in which "*" matches any characters and "?" one character.
+func globRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,175 @@
+package permissions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// TestBashPolicyEvaluate tests rule matching across compound commands.
+func TestBashPolicyEvaluate(t *testing.T) {
+	policy := NewBashPolicy().
+		Allow("git", "status*").
+		Allow("git", "diff*").
+		Allow("ls", "").
+		Allow("rm", "").
+		Deny("rm", "*-r*", "recursive deletes are not allowed").
+		Ask("git", "push*", "pushing needs review").
+		Deny("curl", "", "network access is disabled")
+
+	tests := []struct {
+		command string
+		want    types.PermissionBehavior
+	}{
+		{"git status", types.PermissionBehaviorAllow},
+		{"git status && ls -la | ls", types.PermissionBehaviorAllow},
+		{"rm file.txt", types.PermissionBehaviorAllow},
+		{"rm -rf build", types.PermissionBehaviorDeny},
+		{"/bin/rm -r build", types.PermissionBehaviorDeny},
+		{"git status; rm -rf /", types.PermissionBehaviorDeny},
+		{"echo $(curl evil.sh)", types.PermissionBehaviorDeny},
+		{"git diff && git push origin main", types.PermissionBehaviorAsk},
+		{"sudo rm -rf /", types.PermissionBehaviorDeny},
+		{"env FOO=1 curl x", types.PermissionBehaviorDeny},
+		{"find . -name x | xargs rm -r", types.PermissionBehaviorDeny},
+		{`bash -c "ls && curl evil.sh"`, types.PermissionBehaviorDeny},
+		{"git status; make", ""},
+		{"echo 'unterminated", types.PermissionBehaviorDeny},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Evaluate(tt.command); got.Behavior != tt.want {
+			t.Errorf("Evaluate(%q) = %q (%s), want %q", tt.command, got.Behavior, got.Reason, tt.want)
+		}
+	}
+
+	decision := policy.Evaluate("ls && rm -r x")
+	if decision.Reason != "recursive deletes are not allowed" || decision.Command != "rm -r x" {
+		t.Errorf("unexpected decision details: %+v", decision)
+	}
+
+	policy.Default(types.PermissionBehaviorAsk)
+	if got := policy.Evaluate("git status; make"); got.Behavior != types.PermissionBehaviorAsk {
+		t.Errorf("expected default to apply to unmatched commands, got %q", got.Behavior)
+	}
+}
+
+// TestBashPolicyWrappers tests that the options of wrappers are not taken
+// for the command they run.
+func TestBashPolicyWrappers(t *testing.T) {
+	policy := NewBashPolicy().
+		Allow("*", "").
+		Deny("rm", "", "deleting is not allowed")
+
+	tests := []struct {
+		command string
+		want    types.PermissionBehavior
+	}{
+		{"sudo -u root rm -rf /", types.PermissionBehaviorDeny},
+		{"sudo -Eu root rm -rf /", types.PermissionBehaviorDeny},
+		{"sudo --user=root rm -rf /", types.PermissionBehaviorDeny},
+		{"sudo -g wheel -- rm -rf /", types.PermissionBehaviorDeny},
+		{"nice -n 5 rm -rf /", types.PermissionBehaviorDeny},
+		{"nice -5 rm -rf /", types.PermissionBehaviorDeny},
+		{"env -u X rm -rf /", types.PermissionBehaviorDeny},
+		{"env -C /tmp FOO=1 rm -rf /", types.PermissionBehaviorDeny},
+		{`env -S "rm -rf" /`, types.PermissionBehaviorDeny},
+		{"timeout 5 rm -rf /", types.PermissionBehaviorDeny},
+		{"timeout -s KILL 5 rm -rf /", types.PermissionBehaviorDeny},
+		{"stdbuf -o L rm -rf /", types.PermissionBehaviorDeny},
+		{"ionice -c 3 rm -rf /", types.PermissionBehaviorDeny},
+		{"chroot /srv rm -rf /", types.PermissionBehaviorDeny},
+		{"doas -u root rm -rf /", types.PermissionBehaviorDeny},
+		{"xargs -I {} rm {}", types.PermissionBehaviorDeny},
+		{"xargs -n 1 -P 4 rm", types.PermissionBehaviorDeny},
+		{`find . -exec rm {} \;`, types.PermissionBehaviorDeny},
+		{"find . -name '*.o' -execdir rm {} +", types.PermissionBehaviorDeny},
+		{`find . -ok rm {} \;`, types.PermissionBehaviorDeny},
+		{"sudo -u root ls", types.PermissionBehaviorAllow},
+		{"timeout 5 ls", types.PermissionBehaviorAllow},
+		{`find . -exec ls {} \;`, types.PermissionBehaviorAllow},
+		{"sudo --unknown-option root ls", types.PermissionBehaviorAsk},
+		{"sudo -u", types.PermissionBehaviorAsk},
+		{"find . -exec ls {}", types.PermissionBehaviorAsk},
+		{`sh -c "echo 'unterminated"`, types.PermissionBehaviorAsk},
+		{"sudo -X rm -rf /", types.PermissionBehaviorAsk},
+		{"echo $(( $(rm -rf x) + 1 ))", types.PermissionBehaviorDeny},
+		{"echo ${X:-$(rm -rf x)}", types.PermissionBehaviorDeny},
+		{"echo ${X:-`rm -rf x`}", types.PermissionBehaviorDeny},
+		{"bash -xc 'rm x'", types.PermissionBehaviorDeny},
+		{"bash -o pipefail -c 'rm x'", types.PermissionBehaviorDeny},
+		{"function f { rm x; }; f", types.PermissionBehaviorDeny},
+		{"coproc rm x", types.PermissionBehaviorDeny},
+		{"coproc worker { rm x; }", types.PermissionBehaviorDeny},
+		{"bash -xc 'ls'", types.PermissionBehaviorAllow},
+		{"bash -c", types.PermissionBehaviorAsk},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Evaluate(tt.command); got.Behavior != tt.want {
+			t.Errorf("Evaluate(%q) = %q (%s), want %q", tt.command, got.Behavior, got.Reason, tt.want)
+		}
+	}
+}
+
+// TestBashPolicyHook tests the PreToolUse hook produced by a policy.
+func TestBashPolicyHook(t *testing.T) {
+	hook := NewBashPolicy().Deny("rm", "", "no deletes").Hook()
+	if hook.Matcher == nil || *hook.Matcher != "Bash" {
+		t.Fatalf("expected Bash matcher, got %v", hook.Matcher)
+	}
+
+	run := func(command string) map[string]interface{} {
+		output, err := hook.Hooks[0](context.Background(), map[string]interface{}{
+			"tool_name":  "Bash",
+			"tool_input": map[string]interface{}{"command": command},
+		}, nil, types.HookContext{})
+		if err != nil {
+			t.Fatalf("hook failed: %v", err)
+		}
+		normalized, err := types.NormalizeHookOutput(output)
+		if err != nil {
+			t.Fatalf("NormalizeHookOutput failed: %v", err)
+		}
+		specific, _ := normalized["hookSpecificOutput"].(map[string]interface{})
+		return specific
+	}
+
+	if specific := run("ls && rm x"); specific["permissionDecision"] != "deny" || specific["permissionDecisionReason"] != "no deletes: rm x" {
+		t.Errorf("expected deny, got %v", specific)
+	}
+	if specific := run("ls"); specific != nil {
+		t.Errorf("expected no decision for unmatched command, got %v", specific)
+	}
+}
+
+// TestBashPolicyCanUseTool tests the permission callback produced by a policy.
+func TestBashPolicyCanUseTool(t *testing.T) {
+	var delegated []string
+	next := func(ctx context.Context, toolName string, input map[string]interface{}, permCtx types.ToolPermissionContext) (interface{}, error) {
+		delegated = append(delegated, toolName)
+		return types.PermissionResultAllow{Behavior: "allow"}, nil
+	}
+	canUseTool := NewBashPolicy().Allow("ls", "").Deny("rm", "", "").CanUseTool(next)
+
+	call := func(toolName, command string) interface{} {
+		result, err := canUseTool(context.Background(), toolName, map[string]interface{}{"command": command}, types.ToolPermissionContext{})
+		if err != nil {
+			t.Fatalf("CanUseTool failed: %v", err)
+		}
+		return result
+	}
+
+	if deny, ok := call("Bash", "rm x").(types.PermissionResultDeny); !ok || deny.Source != types.PermissionSourceRule {
+		t.Errorf("expected rule-based deny, got %+v", deny)
+	}
+	if _, ok := call("Bash", "ls").(types.PermissionResultAllow); !ok {
+		t.Error("expected allow for ls")
+	}
+	call("Bash", "make")
+	call("Read", "")
+	if len(delegated) != 2 || delegated[0] != "Bash" || delegated[1] != "Read" {
+		t.Errorf("expected undecided commands and other tools to be delegated, got %v", delegated)
+	}
+}
@@ -0,0 +1,17 @@
+// Package permissions provides reusable building blocks for tool permission
+// decisions.
+//
+// Policies can be installed both as a PreToolUse hook, which runs in every
+// permission mode, and as a CanUseTool callback, which runs when the CLI asks
+// for permission:
+//
+//	policy := permissions.NewBashPolicy().
+//	    Allow("git", "status*").
+//	    Allow("go", "test *").
+//	    Deny("rm", "*-r*", "recursive deletes are not allowed").
+//	    Default(types.PermissionBehaviorAsk)
+//
+//	opts := types.NewClaudeAgentOptions().
+//	    WithHook(types.HookEventPreToolUse, policy.Hook()).
+//	    WithCanUseTool(policy.CanUseTool(askUser))
+package permissions
@@ -0,0 +1,172 @@
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Approval is a user's answer to a permission prompt.
+type Approval string
+
+const (
+	// ApproveOnce allows this tool use only.
+	ApproveOnce Approval = "approve_once"
+	// ApproveAlways allows this tool use and identical ones from now on.
+	ApproveAlways Approval = "approve_always"
+	// DenyOnce denies this tool use only.
+	DenyOnce Approval = "deny_once"
+	// DenyAlways denies this tool use and identical ones from now on.
+	DenyAlways Approval = "deny_always"
+)
+
+// PromptFunc asks the user whether a tool use may run.
+type PromptFunc func(ctx context.Context, toolName string, input map[string]interface{}, permCtx types.ToolPermissionContext) (Approval, error)
+
+// DecisionMemory remembers "always" answers so the user is asked about each
+// distinct tool use only once.
+//
+// Decisions are keyed like the rules written for them: by tool name and the
+// Bash command with whitespace collapsed, or the file_path or notebook_path,
+// so approving a Write to a path approves later writes to it whatever their
+// content. Other inputs are compared by their JSON encoding.
+// For ApproveAlways the allow result also carries PermissionUpdate rules
+// saved to the memory's destination, so the CLI stops asking too: the CLI's
+// own suggestions when it sent any, otherwise a rule for the exact Bash
+// command or file path. Inputs that no rule can express exactly, and
+// DenyAlways answers, are only remembered by the memory itself.
+//
+// It is safe for concurrent use.
+type DecisionMemory struct {
+	mu          sync.Mutex
+	destination types.PermissionUpdateDestination
+	decisions   map[string]Approval
+}
+
+// NewDecisionMemory creates an empty memory whose PermissionUpdates are saved to destination.
+func NewDecisionMemory(destination types.PermissionUpdateDestination) *DecisionMemory {
+	return &DecisionMemory{
+		destination: destination,
+		decisions:   make(map[string]Approval),
+	}
+}
+
+// CanUseTool returns a permission callback that answers remembered tool uses
+// and asks prompt about the rest.
+func (m *DecisionMemory) CanUseTool(prompt PromptFunc) types.CanUseToolFunc {
+	return func(ctx context.Context, toolName string, input map[string]interface{}, permCtx types.ToolPermissionContext) (interface{}, error) {
+		if approval, ok := m.Lookup(toolName, input); ok {
+			return m.result(toolName, input, approval, nil, types.PermissionSourceMemory), nil
+		}
+
+		approval, err := prompt(ctx, toolName, input, permCtx)
+		if err != nil {
+			return nil, err
+		}
+		switch approval {
+		case ApproveOnce, ApproveAlways, DenyOnce, DenyAlways:
+		default:
+			return nil, fmt.Errorf("invalid approval %q", approval)
+		}
+
+		m.Remember(toolName, input, approval)
+		return m.result(toolName, input, approval, permCtx.Suggestions, types.PermissionSourceCallback), nil
+	}
+}
+
+// Remember records an answer. Only ApproveAlways and DenyAlways are kept.
+func (m *DecisionMemory) Remember(toolName string, input map[string]interface{}, approval Approval) {
+	if approval != ApproveAlways && approval != DenyAlways {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.decisions[decisionKey(toolName, input)] = approval
+}
+
+// Lookup returns the remembered answer for a tool use.
+func (m *DecisionMemory) Lookup(toolName string, input map[string]interface{}) (Approval, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	approval, ok := m.decisions[decisionKey(toolName, input)]
+	return approval, ok
+}
+
+// Forget removes the remembered answer for a tool use.
+func (m *DecisionMemory) Forget(toolName string, input map[string]interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.decisions, decisionKey(toolName, input))
+}
+
+// Reset removes all remembered answers.
+func (m *DecisionMemory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.decisions = make(map[string]Approval)
+}
+
+// result converts an approval into a permission result.
+func (m *DecisionMemory) result(toolName string, input map[string]interface{}, approval Approval, suggestions []types.PermissionUpdate, source types.PermissionDecisionSource) interface{} {
+	switch approval {
+	case ApproveOnce:
+		return types.PermissionResultAllow{Behavior: "allow", Source: source}
+	case ApproveAlways:
+		result := types.PermissionResultAllow{Behavior: "allow", Source: source}
+		if source != types.PermissionSourceMemory {
+			result.UpdatedPermissions = m.permissionUpdates(toolName, input, suggestions)
+		}
+		return result
+	case DenyAlways:
+		return types.PermissionResultDeny{Behavior: "deny", Message: fmt.Sprintf("%s was denied by the user", toolName), Source: source}
+	default:
+		return types.PermissionResultDeny{Behavior: "deny", Message: "denied by the user", Source: source}
+	}
+}
+
+// permissionUpdates returns the rules that persist an ApproveAlways answer.
+func (m *DecisionMemory) permissionUpdates(toolName string, input map[string]interface{}, suggestions []types.PermissionUpdate) []types.PermissionUpdate {
+	if len(suggestions) > 0 {
+		request := types.ToolPermissionRequest{ToolName: toolName, Input: input, Suggestions: suggestions}
+		return request.AllowAndRemember(m.destination).UpdatedPermissions
+	}
+
+	content, ok := ruleContent(toolName, input)
+	if !ok {
+		return nil
+	}
+	rule := types.PermissionRuleValue{ToolName: toolName, RuleContent: &content}
+	return []types.PermissionUpdate{types.AddRulesUpdate(m.destination, types.PermissionBehaviorAllow, rule)}
+}
+
+// ruleContent returns the rule specifier matching exactly this tool use, if one exists.
+func ruleContent(toolName string, input map[string]interface{}) (string, bool) {
+	if toolName == "Bash" {
+		command := normalizeCommand(input)
+		return command, command != ""
+	}
+	for _, key := range []string{"file_path", "notebook_path"} {
+		if path, ok := input[key].(string); ok && path != "" {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// decisionKey identifies a tool use by tool name and the specifier of its
+// rule, or its normalized input if no rule expresses it.
+func decisionKey(toolName string, input map[string]interface{}) string {
+	if content, ok := ruleContent(toolName, input); ok {
+		return toolName + "\x00" + content
+	}
+	return toolName + "\x00" + types.HashToolInput(input)
+}
+
+// normalizeCommand returns the Bash command with runs of whitespace collapsed.
+func normalizeCommand(input map[string]interface{}) string {
+	command, _ := input["command"].(string)
+	return strings.Join(strings.Fields(command), " ")
+}
@@ -0,0 +1,134 @@
+package permissions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// TestDecisionMemory tests that "always" answers are remembered and persisted.
+func TestDecisionMemory(t *testing.T) {
+	memory := NewDecisionMemory(types.DestinationSession)
+
+	prompts := 0
+	answers := map[string]Approval{
+		"git status": ApproveAlways,
+		"make":       ApproveOnce,
+		"rm -rf /":   DenyAlways,
+	}
+	canUseTool := memory.CanUseTool(func(ctx context.Context, toolName string, input map[string]interface{}, permCtx types.ToolPermissionContext) (Approval, error) {
+		prompts++
+		return answers[input["command"].(string)], nil
+	})
+
+	call := func(command string) interface{} {
+		result, err := canUseTool(context.Background(), "Bash", map[string]interface{}{"command": command}, types.ToolPermissionContext{})
+		if err != nil {
+			t.Fatalf("CanUseTool failed: %v", err)
+		}
+		return result
+	}
+
+	allow, ok := call("git status").(types.PermissionResultAllow)
+	if !ok || len(allow.UpdatedPermissions) != 1 {
+		t.Fatalf("expected allow with a permission update, got %+v", allow)
+	}
+	update := allow.UpdatedPermissions[0]
+	if update.Type != "addRules" || *update.Destination != types.DestinationSession || *update.Behavior != types.PermissionBehaviorAllow ||
+		update.Rules[0].ToolName != "Bash" || *update.Rules[0].RuleContent != "git status" {
+		t.Errorf("unexpected permission update: %+v", update)
+	}
+
+	// Whitespace differences map to the same decision
+	if remembered, ok := call("git   status").(types.PermissionResultAllow); !ok || remembered.Source != types.PermissionSourceMemory || len(remembered.UpdatedPermissions) != 0 {
+		t.Errorf("expected remembered allow without updates, got %+v", remembered)
+	}
+
+	call("make")
+	call("make")
+
+	if _, ok := call("rm -rf /").(types.PermissionResultDeny); !ok {
+		t.Error("expected deny")
+	}
+	if deny, ok := call("rm -rf /").(types.PermissionResultDeny); !ok || deny.Source != types.PermissionSourceMemory {
+		t.Errorf("expected remembered deny, got %+v", deny)
+	}
+
+	if prompts != 4 {
+		t.Errorf("expected 4 prompts (git status, make twice, rm), got %d", prompts)
+	}
+
+	memory.Forget("Bash", map[string]interface{}{"command": "git status"})
+	if _, ok := memory.Lookup("Bash", map[string]interface{}{"command": "git status"}); ok {
+		t.Error("expected forgotten decision to be removed")
+	}
+	memory.Reset()
+	if _, ok := memory.Lookup("Bash", map[string]interface{}{"command": "rm -rf /"}); ok {
+		t.Error("expected Reset to clear all decisions")
+	}
+}
+
+// TestDecisionMemoryFilePath tests that file tool decisions are keyed by
+// path, like the rule written for them.
+func TestDecisionMemoryFilePath(t *testing.T) {
+	memory := NewDecisionMemory(types.DestinationSession)
+	prompts := 0
+	canUseTool := memory.CanUseTool(func(ctx context.Context, toolName string, input map[string]interface{}, permCtx types.ToolPermissionContext) (Approval, error) {
+		prompts++
+		return ApproveAlways, nil
+	})
+
+	write := func(content string) types.PermissionResultAllow {
+		input := map[string]interface{}{"file_path": "/repo/a.go", "content": content}
+		result, err := canUseTool(context.Background(), "Write", input, types.ToolPermissionContext{})
+		if err != nil {
+			t.Fatalf("CanUseTool failed: %v", err)
+		}
+		return result.(types.PermissionResultAllow)
+	}
+
+	if allow := write("v1"); len(allow.UpdatedPermissions) != 1 || *allow.UpdatedPermissions[0].Rules[0].RuleContent != "/repo/a.go" {
+		t.Errorf("expected a rule for the path, got %+v", allow.UpdatedPermissions)
+	}
+	if allow := write("v2"); allow.Source != types.PermissionSourceMemory {
+		t.Errorf("expected a write with other content to be remembered, got %+v", allow)
+	}
+	if prompts != 1 {
+		t.Errorf("expected 1 prompt, got %d", prompts)
+	}
+}
+
+// TestDecisionMemorySuggestions tests that CLI suggestions are persisted to the memory's destination.
+func TestDecisionMemorySuggestions(t *testing.T) {
+	memory := NewDecisionMemory(types.DestinationProjectSettings)
+	canUseTool := memory.CanUseTool(func(ctx context.Context, toolName string, input map[string]interface{}, permCtx types.ToolPermissionContext) (Approval, error) {
+		return ApproveAlways, nil
+	})
+
+	session := types.DestinationSession
+	suggestion := types.PermissionUpdate{
+		Type:        "addRules",
+		Rules:       []types.PermissionRuleValue{{ToolName: "mcp__db__query"}},
+		Destination: &session,
+	}
+	result, err := canUseTool(context.Background(), "mcp__db__query", map[string]interface{}{"sql": "select 1"},
+		types.ToolPermissionContext{Suggestions: []types.PermissionUpdate{suggestion}})
+	if err != nil {
+		t.Fatalf("CanUseTool failed: %v", err)
+	}
+
+	allow := result.(types.PermissionResultAllow)
+	if len(allow.UpdatedPermissions) != 1 || *allow.UpdatedPermissions[0].Destination != types.DestinationProjectSettings {
+		t.Errorf("expected suggestion saved to project settings, got %+v", allow.UpdatedPermissions)
+	}
+	if *suggestion.Destination != types.DestinationSession {
+		t.Error("expected the caller's suggestion not to be modified")
+	}
+
+	// Without suggestions, inputs that no rule expresses are only remembered
+	result, _ = canUseTool(context.Background(), "mcp__db__query", map[string]interface{}{"sql": "select 2"}, types.ToolPermissionContext{})
+	if allow := result.(types.PermissionResultAllow); len(allow.UpdatedPermissions) != 0 {
+		t.Errorf("expected no permission update, got %+v", allow.UpdatedPermissions)
+	}
+}
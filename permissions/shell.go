@@ -0,0 +1,494 @@
+package permissions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SimpleCommand is a single command of a shell command line: an executable
+// with its arguments, preceded by variable assignments and followed by
+// redirections.
+type SimpleCommand struct {
+	Name        string
+	Args        []string
+	Assignments []string // NAME=value words before the command name
+	Redirects   []Redirect
+}
+
+// Redirect is an I/O redirection such as "> out.txt" or "2>&1".
+type Redirect struct {
+	Op     string // ">", ">>", "<", "<<", "<<<", ">&", "<&", "&>", "&>>" or "<>", with an optional fd prefix
+	Target string
+}
+
+// String returns the command as a space-separated line.
+func (c SimpleCommand) String() string {
+	words := append(append([]string{}, c.Assignments...), c.Name)
+	return strings.Join(append(words, c.Args...), " ")
+}
+
+// reservedWords are shell keywords that may precede a command.
+var reservedWords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"do": true, "done": true, "while": true, "until": true,
+	"!": true, "{": true, "}": true,
+}
+
+// ParseCommand parses a shell command line into the simple commands it runs.
+//
+// Lists (";", "&&", "||", "&", newlines), pipelines, subshells, command and
+// process substitutions ("$(...)", backticks, "<(...)") and the bodies of
+// if/while/until constructs, function definitions and coprocesses are all
+// flattened into the result, so every executable the line may run is
+// reported; this includes substitutions nested in arithmetic and parameter
+// expansions such as "${X:-$(cmd)}". Quotes and backslash escapes are
+// removed from words; parameter expansions such as "$HOME" are kept as
+// written. Here-document bodies are skipped. for/select headers and case
+// statements are not interpreted; an unsupported construct or an unterminated
+// quote or substitution yields an error.
+func ParseCommand(command string) ([]SimpleCommand, error) {
+	p := &shellParser{src: []rune(command)}
+	if err := p.parseList(0); err != nil {
+		return nil, err
+	}
+	return p.commands, nil
+}
+
+// shellParser is a recursive descent parser over a command line.
+type shellParser struct {
+	src      []rune
+	pos      int
+	commands []SimpleCommand
+	heredocs []heredoc
+}
+
+// heredoc is a pending here-document whose body starts at the next newline.
+type heredoc struct {
+	delimiter string
+	stripTabs bool
+}
+
+func (p *shellParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *shellParser) peek(offset int) rune {
+	if p.pos+offset >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos+offset]
+}
+
+// parseList parses commands until end of input or the terminator rune.
+func (p *shellParser) parseList(terminator rune) error {
+	var current SimpleCommand
+	words := 0
+
+	finish := func() {
+		if words > 0 || len(current.Redirects) > 0 {
+			p.addCommand(current)
+		}
+		current = SimpleCommand{}
+		words = 0
+	}
+
+	for {
+		p.skipBlanks()
+		if p.eof() {
+			if terminator != 0 {
+				return fmt.Errorf("unterminated %q in command", openerFor(terminator))
+			}
+			finish()
+			return nil
+		}
+
+		r := p.peek(0)
+		switch {
+		case r == terminator:
+			p.pos++
+			finish()
+			return nil
+		case r == '#' && words == 0:
+			for !p.eof() && p.peek(0) != '\n' {
+				p.pos++
+			}
+		case r == '\n':
+			p.pos++
+			finish()
+			if err := p.skipHeredocBodies(); err != nil {
+				return err
+			}
+		case r == ';' || r == '|' || r == '&' && p.peek(1) != '>':
+			p.pos++
+			if next := p.peek(0); next == r || (r == '|' && next == '&') {
+				p.pos++
+			}
+			finish()
+		case r == '(' && words == 0:
+			p.pos++
+			if err := p.parseList(')'); err != nil {
+				return err
+			}
+		case r == '(' && p.peek(1) == ')':
+			// Function definition: name() { body; }
+			p.pos += 2
+			current = SimpleCommand{}
+			words = 0
+		case r == '(' || r == ')':
+			return fmt.Errorf("unexpected %q in command", r)
+		case p.atRedirect():
+			redirect, err := p.parseRedirect()
+			if err != nil {
+				return err
+			}
+			if redirect != nil {
+				current.Redirects = append(current.Redirects, *redirect)
+			}
+		default:
+			word, err := p.parseWord()
+			if err != nil {
+				return err
+			}
+			if words == 0 && current.Name == "" && isAssignment(word) {
+				current.Assignments = append(current.Assignments, word)
+				continue
+			}
+			if current.Name == "" {
+				current.Name = word
+			} else {
+				current.Args = append(current.Args, word)
+			}
+			words++
+		}
+	}
+}
+
+// addCommand records a finished command, dropping leading shell keywords,
+// the name of a "function name { ...; }" definition and the name of a
+// "coproc name { ...; }" coprocess.
+func (p *shellParser) addCommand(cmd SimpleCommand) {
+	for cmd.Name != "" {
+		skip := 0
+		switch {
+		case reservedWords[cmd.Name]:
+			skip = 1
+		case cmd.Name == "function":
+			skip = 2
+		case cmd.Name == "coproc" && len(cmd.Args) > 1 && cmd.Args[1] == "{":
+			skip = 2
+		case cmd.Name == "coproc":
+			skip = 1
+		}
+		if skip == 0 {
+			break
+		}
+		words := append([]string{cmd.Name}, cmd.Args...)
+		if skip >= len(words) {
+			cmd.Name, cmd.Args = "", nil
+			break
+		}
+		cmd.Name, cmd.Args = words[skip], words[skip+1:]
+	}
+	if cmd.Name == "for" || cmd.Name == "select" {
+		return // The header's words are data, not commands
+	}
+	if cmd.Name == "" && len(cmd.Assignments) == 0 && len(cmd.Redirects) == 0 {
+		return
+	}
+	p.commands = append(p.commands, cmd)
+}
+
+// skipBlanks skips spaces, tabs and line continuations.
+func (p *shellParser) skipBlanks() {
+	for !p.eof() {
+		switch {
+		case p.peek(0) == ' ' || p.peek(0) == '\t':
+			p.pos++
+		case p.peek(0) == '\\' && p.peek(1) == '\n':
+			p.pos += 2
+		default:
+			return
+		}
+	}
+}
+
+// atRedirect reports whether a redirection operator starts at the current position.
+func (p *shellParser) atRedirect() bool {
+	i := 0
+	for isDigit(p.peek(i)) {
+		i++
+	}
+	r := p.peek(i)
+	if r == '&' {
+		return i == 0 && p.peek(1) == '>'
+	}
+	if (r == '<' || r == '>') && p.peek(i+1) == '(' {
+		return false // Process substitution is part of a word
+	}
+	return r == '<' || r == '>'
+}
+
+// parseRedirect parses a redirection operator and its target word.
+// Here-document operators register a pending body and return the delimiter as target.
+func (p *shellParser) parseRedirect() (*Redirect, error) {
+	start := p.pos
+	for isDigit(p.peek(0)) {
+		p.pos++
+	}
+
+	switch {
+	case p.peek(0) == '&' && p.peek(1) == '>' && p.peek(2) == '>':
+		p.pos += 3
+	case p.peek(0) == '&' && p.peek(1) == '>':
+		p.pos += 2
+	case p.peek(0) == '<' && p.peek(1) == '<' && p.peek(2) == '<':
+		p.pos += 3
+	case p.peek(0) == '<' && p.peek(1) == '<':
+		p.pos += 2
+		if p.peek(0) == '-' {
+			p.pos++
+		}
+	case p.peek(1) == '>' || p.peek(1) == '&' || p.peek(1) == '|' || (p.peek(0) == '<' && p.peek(1) == '>'):
+		p.pos += 2
+	default:
+		p.pos++
+	}
+	op := string(p.src[start:p.pos])
+
+	p.skipBlanks()
+	if p.eof() || strings.ContainsRune(";|&<>()\n", p.peek(0)) {
+		return nil, fmt.Errorf("missing target for redirection %q", op)
+	}
+	target, err := p.parseWord()
+	if err != nil {
+		return nil, err
+	}
+
+	bare := strings.TrimLeft(op, "0123456789")
+	if bare == "<<" || bare == "<<-" {
+		p.heredocs = append(p.heredocs, heredoc{delimiter: target, stripTabs: bare == "<<-"})
+	}
+	return &Redirect{Op: op, Target: target}, nil
+}
+
+// skipHeredocBodies skips the bodies of pending here-documents after a newline.
+func (p *shellParser) skipHeredocBodies() error {
+	for _, doc := range p.heredocs {
+		for {
+			if p.eof() {
+				return fmt.Errorf("unterminated here-document %q", doc.delimiter)
+			}
+			end := p.pos
+			for end < len(p.src) && p.src[end] != '\n' {
+				end++
+			}
+			line := string(p.src[p.pos:end])
+			p.pos = end
+			if p.pos < len(p.src) {
+				p.pos++
+			}
+			if doc.stripTabs {
+				line = strings.TrimLeft(line, "\t")
+			}
+			if line == doc.delimiter {
+				break
+			}
+		}
+	}
+	p.heredocs = nil
+	return nil
+}
+
+// parseWord parses a single word, removing quotes and escapes and collecting
+// the commands of any substitutions it contains.
+func (p *shellParser) parseWord() (string, error) {
+	var word strings.Builder
+
+	for !p.eof() {
+		r := p.peek(0)
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || strings.ContainsRune(";|&()", r):
+			return word.String(), nil
+		case (r == '<' || r == '>') && p.peek(1) == '(':
+			start := p.pos
+			p.pos += 2
+			if err := p.parseList(')'); err != nil {
+				return "", err
+			}
+			word.WriteString(string(p.src[start:p.pos]))
+		case r == '<' || r == '>':
+			return word.String(), nil
+		case r == '\\':
+			p.pos++
+			if p.eof() {
+				return word.String(), nil
+			}
+			if p.peek(0) != '\n' {
+				word.WriteRune(p.peek(0))
+			}
+			p.pos++
+		case r == '\'':
+			end := p.pos + 1
+			for end < len(p.src) && p.src[end] != '\'' {
+				end++
+			}
+			if end >= len(p.src) {
+				return "", fmt.Errorf("unterminated single quote in command")
+			}
+			word.WriteString(string(p.src[p.pos+1 : end]))
+			p.pos = end + 1
+		case r == '"':
+			if err := p.parseDoubleQuoted(&word); err != nil {
+				return "", err
+			}
+		case r == '$' || r == '`':
+			if err := p.parseExpansion(&word); err != nil {
+				return "", err
+			}
+		default:
+			word.WriteRune(r)
+			p.pos++
+		}
+	}
+	return word.String(), nil
+}
+
+// parseDoubleQuoted parses a double-quoted string, in which substitutions still run.
+func (p *shellParser) parseDoubleQuoted(word *strings.Builder) error {
+	p.pos++ // Opening quote
+	for {
+		if p.eof() {
+			return fmt.Errorf("unterminated double quote in command")
+		}
+		r := p.peek(0)
+		switch {
+		case r == '"':
+			p.pos++
+			return nil
+		case r == '\\' && strings.ContainsRune("\"\\$`\n", p.peek(1)):
+			if p.peek(1) != '\n' {
+				word.WriteRune(p.peek(1))
+			}
+			p.pos += 2
+		case r == '$' || r == '`':
+			if err := p.parseExpansion(word); err != nil {
+				return err
+			}
+		default:
+			word.WriteRune(r)
+			p.pos++
+		}
+	}
+}
+
+// parseExpansion parses "$(...)", "$((...))", "${...}", "$name" or a backtick
+// substitution, writing its source text to word.
+func (p *shellParser) parseExpansion(word *strings.Builder) error {
+	start := p.pos
+
+	switch {
+	case p.peek(0) == '`':
+		end := p.pos + 1
+		for end < len(p.src) && p.src[end] != '`' {
+			if p.src[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.src) {
+			return fmt.Errorf("unterminated backtick substitution in command")
+		}
+		inner, err := ParseCommand(strings.ReplaceAll(string(p.src[p.pos+1:end]), "\\`", "`"))
+		if err != nil {
+			return err
+		}
+		p.commands = append(p.commands, inner...)
+		p.pos = end + 1
+	case p.peek(1) == '(' && p.peek(2) == '(':
+		if err := p.skipBalanced('(', ')', 2, "arithmetic expansion"); err != nil {
+			return err
+		}
+	case p.peek(1) == '(':
+		p.pos += 2
+		if err := p.parseList(')'); err != nil {
+			return err
+		}
+	case p.peek(1) == '{':
+		if err := p.skipBalanced('{', '}', 1, "parameter expansion"); err != nil {
+			return err
+		}
+	default:
+		p.pos++
+	}
+
+	word.WriteString(string(p.src[start:p.pos]))
+	return nil
+}
+
+// skipBalanced skips past the closing runes matching depth openers following
+// a '$'. Quotes and nested expansions inside are parsed, so the commands of
+// substitutions such as "${X:-$(cmd)}" or "$(( $(cmd) + 1 ))" are collected.
+func (p *shellParser) skipBalanced(open, close rune, depth int, construct string) error {
+	p.pos += 1 + depth
+	var discard strings.Builder
+	for depth > 0 {
+		if p.eof() {
+			return fmt.Errorf("unterminated %s in command", construct)
+		}
+		switch r := p.peek(0); r {
+		case open:
+			depth++
+		case close:
+			depth--
+		case '\\':
+			p.pos++
+		case '\'':
+			end := p.pos + 1
+			for end < len(p.src) && p.src[end] != '\'' {
+				end++
+			}
+			if end >= len(p.src) {
+				return fmt.Errorf("unterminated single quote in command")
+			}
+			p.pos = end
+		case '"':
+			if err := p.parseDoubleQuoted(&discard); err != nil {
+				return err
+			}
+			continue
+		case '$', '`':
+			if err := p.parseExpansion(&discard); err != nil {
+				return err
+			}
+			continue
+		}
+		p.pos++
+	}
+	return nil
+}
+
+// isAssignment reports whether a word has the form NAME=value.
+func isAssignment(word string) bool {
+	eq := strings.IndexByte(word, '=')
+	if eq <= 0 {
+		return false
+	}
+	for i, r := range word[:eq] {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && isDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// openerFor returns the opening rune that a terminator closes.
+func openerFor(terminator rune) rune {
+	if terminator == ')' {
+		return '('
+	}
+	return terminator
+}
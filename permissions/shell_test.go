@@ -0,0 +1,100 @@
+package permissions
+
+import (
+	"reflect"
+	"testing"
+)
+
+// commandNames returns the names of parsed commands.
+func commandNames(commands []SimpleCommand) []string {
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.Name
+	}
+	return names
+}
+
+// TestParseCommand tests flattening of shell command lines into simple commands.
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		names   []string
+	}{
+		{"ls -la", []string{"ls"}},
+		{"git add . && git commit -m 'a; b' || echo failed", []string{"git", "git", "echo"}},
+		{"cat file | grep x | wc -l; echo done &", []string{"cat", "grep", "wc", "echo"}},
+		{"echo $(rm -rf /tmp/x) `whoami`", []string{"rm", "whoami", "echo"}},
+		{`echo "today is $(date)"`, []string{"date", "echo"}},
+		{"(cd sub && make)", []string{"cd", "make"}},
+		{"diff <(sort a) <(sort b)", []string{"sort", "sort", "diff"}},
+		{"if test -f x; then rm x; fi", []string{"test", "rm"}},
+		{"for f in *.go; do gofmt -l $f; done", []string{"gofmt"}},
+		{"FOO=1 BAR=2 make build", []string{"make"}},
+		{"echo $((1 + 2)) ${HOME}", []string{"echo"}},
+		{"cat <<EOF\nrm -rf /\nEOF\necho after", []string{"cat", "echo"}},
+		{"# just a comment", []string{}},
+		{"ls \\\n  -la", []string{"ls"}},
+		{"echo $(( $(wc -l < f) + 1 ))", []string{"wc", "echo"}},
+		{`echo ${X:-$(date)} ${Y:-"$(whoami)"} ${Z:-'$(id)'}`, []string{"date", "whoami", "echo"}},
+		{"echo ${X:-`date`}", []string{"date", "echo"}},
+		{"function f { rm x; }; f", []string{"rm", "f"}},
+		{"function f() { rm x; }", []string{"rm"}},
+		{"f() { rm x; }", []string{"rm"}},
+		{"coproc rm x", []string{"rm"}},
+		{"coproc worker { rm x; }", []string{"rm"}},
+	}
+
+	for _, tt := range tests {
+		commands, err := ParseCommand(tt.command)
+		if err != nil {
+			t.Errorf("ParseCommand(%q) failed: %v", tt.command, err)
+			continue
+		}
+		if got := commandNames(commands); !reflect.DeepEqual(got, tt.names) {
+			t.Errorf("ParseCommand(%q) = %v, want %v", tt.command, got, tt.names)
+		}
+	}
+}
+
+// TestParseCommandWords tests quote removal, assignments and redirections.
+func TestParseCommandWords(t *testing.T) {
+	commands, err := ParseCommand(`FOO=bar git commit -m "fix \"quoted\" bug" 'it''s' > out.log 2>&1`)
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+	if len(commands) != 1 {
+		t.Fatalf("expected 1 command, got %d", len(commands))
+	}
+
+	cmd := commands[0]
+	if !reflect.DeepEqual(cmd.Assignments, []string{"FOO=bar"}) {
+		t.Errorf("unexpected assignments: %v", cmd.Assignments)
+	}
+	if want := []string{"commit", "-m", `fix "quoted" bug`, "its"}; !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("unexpected args: %q, want %q", cmd.Args, want)
+	}
+	if want := []Redirect{{Op: ">", Target: "out.log"}, {Op: "2>&", Target: "1"}}; !reflect.DeepEqual(cmd.Redirects, want) {
+		t.Errorf("unexpected redirects: %v, want %v", cmd.Redirects, want)
+	}
+}
+
+// TestParseCommandErrors tests that malformed command lines are rejected.
+func TestParseCommandErrors(t *testing.T) {
+	for _, command := range []string{
+		"echo 'unterminated",
+		`echo "unterminated`,
+		"echo $(ls",
+		"echo `ls",
+		"echo ${X:-$(ls}",
+		"echo $(( 1 + 2 )",
+		"(ls",
+		"ls )",
+		"echo >",
+		"case $x in a) ls;; esac",
+		"cat <<EOF\nno end",
+	} {
+		if _, err := ParseCommand(command); err == nil {
+			t.Errorf("expected error for %q", command)
+		}
+	}
+}
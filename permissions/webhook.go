@@ -0,0 +1,204 @@
+package permissions
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Headers set on webhook permission requests.
+const (
+	WebhookSignatureHeader = "X-Claude-Signature" // "sha256=" followed by the hex HMAC
+	WebhookTimestampHeader = "X-Claude-Timestamp" // Unix seconds
+	WebhookRequestIDHeader = "X-Claude-Request-Id"
+)
+
+// DefaultWebhookTimeout is how long a webhook has to answer a permission request.
+const DefaultWebhookTimeout = 2 * time.Minute
+
+// WebhookRequest is the JSON body POSTed to a permission webhook.
+type WebhookRequest struct {
+	ID              string                   `json:"id"`
+	Time            time.Time                `json:"time"`
+	ToolName        string                   `json:"tool_name"`
+	Input           map[string]interface{}   `json:"input"`
+	Suggestions     []types.PermissionUpdate `json:"suggestions,omitempty"`
+	BlockedPath     *string                  `json:"blocked_path,omitempty"`
+	ParentToolUseID *string                  `json:"parent_tool_use_id,omitempty"`
+}
+
+// WebhookResponse is the JSON body a permission webhook answers with.
+//
+// Behavior is "allow" or "deny". UpdatedInput replaces the tool input of an
+// allowed call; Message and Interrupt apply to a denied one.
+type WebhookResponse struct {
+	Behavior     types.PermissionBehavior `json:"behavior"`
+	Message      string                   `json:"message,omitempty"`
+	Interrupt    bool                     `json:"interrupt,omitempty"`
+	UpdatedInput map[string]interface{}   `json:"updated_input,omitempty"`
+}
+
+// Webhook asks a remote service, such as a Slack bot or an approval
+// dashboard, whether a tool use may run.
+//
+// Each permission request is POSTed as a WebhookRequest and the call waits
+// for a WebhookResponse. When Secret is set the body is signed with
+// HMAC-SHA256 over "<timestamp>.<body>"; receivers check it with
+// VerifyWebhookSignature. If the webhook fails, answers with a non-2xx
+// status, or does not answer within Timeout, Default decides.
+type Webhook struct {
+	URL     string
+	Secret  []byte
+	Timeout time.Duration // Defaults to DefaultWebhookTimeout
+	// Default is the behavior used when the webhook gives no answer:
+	// PermissionBehaviorAllow or PermissionBehaviorDeny (the default).
+	Default types.PermissionBehavior
+	Header  http.Header  // Extra headers, such as Authorization
+	Client  *http.Client // Defaults to http.DefaultClient
+}
+
+// NewWebhook creates a Webhook that POSTs to url, signing requests with secret
+// when it is not empty.
+func NewWebhook(url string, secret []byte) *Webhook {
+	return &Webhook{URL: url, Secret: secret}
+}
+
+// CanUseTool returns a permission callback that asks the webhook.
+func (w *Webhook) CanUseTool() types.CanUseToolFunc {
+	return func(ctx context.Context, toolName string, input map[string]interface{}, permCtx types.ToolPermissionContext) (interface{}, error) {
+		request := WebhookRequest{
+			ID:              uuid.NewString(),
+			Time:            time.Now().UTC(),
+			ToolName:        toolName,
+			Input:           input,
+			Suggestions:     permCtx.Suggestions,
+			BlockedPath:     permCtx.BlockedPath,
+			ParentToolUseID: permCtx.ParentToolUseID,
+		}
+
+		response, err := w.Ask(ctx, request)
+		if err != nil {
+			return w.fallback(toolName, err), nil
+		}
+		if response.Behavior == types.PermissionBehaviorAllow {
+			result := types.PermissionResultAllow{Behavior: "allow"}
+			if response.UpdatedInput != nil {
+				result.UpdatedInput = &response.UpdatedInput
+			}
+			return result, nil
+		}
+		message := response.Message
+		if message == "" {
+			message = fmt.Sprintf("%s was denied by the approval webhook", toolName)
+		}
+		return types.PermissionResultDeny{Behavior: "deny", Message: message, Interrupt: response.Interrupt}, nil
+	}
+}
+
+// Ask POSTs request to the webhook and returns its answer.
+func (w *Webhook) Ask(ctx context.Context, request WebhookRequest) (*WebhookResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("encode webhook request: %w", err)
+	}
+
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create webhook request: %w", err)
+	}
+	for key, values := range w.Header {
+		for _, value := range values {
+			httpRequest.Header.Add(key, value)
+		}
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.Header.Set(WebhookRequestIDHeader, request.ID)
+	if len(w.Secret) > 0 {
+		timestamp := strconv.FormatInt(request.Time.Unix(), 10)
+		httpRequest.Header.Set(WebhookTimestampHeader, timestamp)
+		httpRequest.Header.Set(WebhookSignatureHeader, SignWebhook(w.Secret, timestamp, body))
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	httpResponse, err := client.Do(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode < 200 || httpResponse.StatusCode > 299 {
+		return nil, fmt.Errorf("webhook returned status %d", httpResponse.StatusCode)
+	}
+	var response WebhookResponse
+	if err := json.NewDecoder(io.LimitReader(httpResponse.Body, 1<<20)).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode webhook response: %w", err)
+	}
+	if response.Behavior != types.PermissionBehaviorAllow && response.Behavior != types.PermissionBehaviorDeny {
+		return nil, fmt.Errorf("webhook returned invalid behavior %q", response.Behavior)
+	}
+	return &response, nil
+}
+
+// fallback returns the Default decision for a webhook that gave no answer.
+func (w *Webhook) fallback(toolName string, err error) interface{} {
+	if w.Default == types.PermissionBehaviorAllow {
+		return types.PermissionResultAllow{Behavior: "allow"}
+	}
+	reason := "did not answer"
+	if !errors.Is(err, context.DeadlineExceeded) {
+		reason = "failed: " + err.Error()
+	}
+	return types.PermissionResultDeny{
+		Behavior: "deny",
+		Message:  fmt.Sprintf("%s was denied because the approval webhook %s", toolName, reason),
+	}
+}
+
+// SignWebhook returns the signature header value for a webhook body sent at timestamp.
+func SignWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature is valid for a webhook
+// body sent at timestamp, and the timestamp is within maxAge of now.
+// A maxAge of zero skips the age check.
+func VerifyWebhookSignature(secret []byte, timestamp string, body []byte, signature string, maxAge time.Duration) bool {
+	if maxAge > 0 {
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return false
+		}
+		age := time.Since(time.Unix(seconds, 0))
+		if age > maxAge || age < -maxAge {
+			return false
+		}
+	}
+	return hmac.Equal([]byte(signature), []byte(SignWebhook(secret, timestamp, body)))
+}
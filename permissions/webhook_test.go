@@ -0,0 +1,122 @@
+package permissions
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// TestWebhookCanUseTool tests signed webhook requests and their decisions.
+func TestWebhookCanUseTool(t *testing.T) {
+	secret := []byte("s3cret")
+	var received WebhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyWebhookSignature(secret, r.Header.Get(WebhookTimestampHeader), body, r.Header.Get(WebhookSignatureHeader), time.Minute) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_ = json.Unmarshal(body, &received)
+
+		command, _ := received.Input["command"].(string)
+		response := WebhookResponse{Behavior: types.PermissionBehaviorAllow}
+		if strings.HasPrefix(command, "rm") {
+			response = WebhookResponse{Behavior: types.PermissionBehaviorDeny, Message: "rejected in Slack", Interrupt: true}
+		} else if command == "ls" {
+			response.UpdatedInput = map[string]interface{}{"command": "ls -la"}
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, secret)
+	webhook.Header = http.Header{"Authorization": {"Bearer token"}}
+	canUseTool := webhook.CanUseTool()
+	blocked := "/etc"
+
+	result, err := canUseTool(context.Background(), "Bash", map[string]interface{}{"command": "ls"}, types.ToolPermissionContext{BlockedPath: &blocked})
+	if err != nil {
+		t.Fatalf("CanUseTool failed: %v", err)
+	}
+	allow, ok := result.(types.PermissionResultAllow)
+	if !ok || allow.UpdatedInput == nil || (*allow.UpdatedInput)["command"] != "ls -la" {
+		t.Errorf("expected allow with updated input, got %#v", result)
+	}
+	if received.ID == "" || received.ToolName != "Bash" || received.BlockedPath == nil || *received.BlockedPath != "/etc" {
+		t.Errorf("unexpected webhook request: %+v", received)
+	}
+
+	result, _ = canUseTool(context.Background(), "Bash", map[string]interface{}{"command": "rm -rf build"}, types.ToolPermissionContext{})
+	deny, ok := result.(types.PermissionResultDeny)
+	if !ok || deny.Message != "rejected in Slack" || !deny.Interrupt {
+		t.Errorf("expected deny from webhook, got %#v", result)
+	}
+
+	webhook.Secret = []byte("wrong")
+	result, _ = canUseTool(context.Background(), "Bash", map[string]interface{}{"command": "ls"}, types.ToolPermissionContext{})
+	if deny, ok := result.(types.PermissionResultDeny); !ok || !strings.Contains(deny.Message, "status 401") {
+		t.Errorf("expected deny for rejected signature, got %#v", result)
+	}
+}
+
+// TestWebhookTimeout tests that the default behavior applies when the webhook does not answer.
+func TestWebhookTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	webhook := NewWebhook(server.URL, nil)
+	webhook.Timeout = 20 * time.Millisecond
+
+	result, err := webhook.CanUseTool()(context.Background(), "Write", map[string]interface{}{}, types.ToolPermissionContext{})
+	if err != nil {
+		t.Fatalf("CanUseTool failed: %v", err)
+	}
+	if deny, ok := result.(types.PermissionResultDeny); !ok || !strings.Contains(deny.Message, "did not answer") {
+		t.Errorf("expected deny on timeout, got %#v", result)
+	}
+
+	webhook.Default = types.PermissionBehaviorAllow
+	result, _ = webhook.CanUseTool()(context.Background(), "Write", map[string]interface{}{}, types.ToolPermissionContext{})
+	if _, ok := result.(types.PermissionResultAllow); !ok {
+		t.Errorf("expected allow default on timeout, got %#v", result)
+	}
+}
+
+// TestVerifyWebhookSignature tests signature and timestamp checks.
+func TestVerifyWebhookSignature(t *testing.T) {
+	secret := []byte("key")
+	body := []byte(`{"tool_name":"Bash"}`)
+	now := time.Now().Unix()
+	timestamp := strconv.FormatInt(now, 10)
+	signature := SignWebhook(secret, timestamp, body)
+
+	if !VerifyWebhookSignature(secret, timestamp, body, signature, time.Minute) {
+		t.Error("expected valid signature")
+	}
+	if VerifyWebhookSignature(secret, timestamp, []byte(`{"tool_name":"Write"}`), signature, time.Minute) {
+		t.Error("expected tampered body to fail")
+	}
+	old := strconv.FormatInt(now-3600, 10)
+	if VerifyWebhookSignature(secret, old, body, SignWebhook(secret, old, body), time.Minute) {
+		t.Error("expected stale timestamp to fail")
+	}
+	if !VerifyWebhookSignature(secret, old, body, SignWebhook(secret, old, body), 0) {
+		t.Error("expected zero maxAge to skip the age check")
+	}
+}
@@ -0,0 +1,79 @@
+package permissionui
+
+import "strings"
+
+// maxDiffCells bounds the work of the line diff; larger inputs are shown as
+// a full replacement.
+const maxDiffCells = 1 << 20
+
+// diffLine is one line of a line diff.
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// lineDiff returns the line diff turning before into after.
+func lineDiff(before, after string) []diffLine {
+	a := splitLines(before)
+	b := splitLines(after)
+
+	if len(a)*len(b) > maxDiffCells {
+		diff := make([]diffLine, 0, len(a)+len(b))
+		for _, line := range a {
+			diff = append(diff, diffLine{'-', line})
+		}
+		for _, line := range b {
+			diff = append(diff, diffLine{'+', line})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, diffLine{'-', a[i]})
+			i++
+		default:
+			diff = append(diff, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, diffLine{'+', b[j]})
+	}
+	return diff
+}
+
+// splitLines splits text into lines without their terminators.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
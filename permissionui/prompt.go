@@ -0,0 +1,324 @@
+// Package permissionui provides an interactive terminal prompt for tool
+// permission requests.
+//
+// The prompt shows what a tool is about to do: the command for Bash, and a
+// line diff for Edit, MultiEdit and Write. The user can allow, deny, or edit
+// the input before allowing it:
+//
+//	opts := types.NewClaudeAgentOptions().
+//	    WithCanUseTool(permissionui.New(os.Stdin, os.Stdout).CanUseTool())
+//
+// Combined with permissions.DecisionMemory, answering "always" stops further
+// prompts for the same tool use:
+//
+//	memory := permissions.NewDecisionMemory(types.DestinationSession)
+//	opts.WithCanUseTool(memory.CanUseTool(permissionui.New(os.Stdin, os.Stdout).Approval))
+package permissionui
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/M1n9X/claude-agent-sdk-go/permissions"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// DefaultMaxPreviewLines limits how many diff or content lines are shown.
+const DefaultMaxPreviewLines = 40
+
+// Decision is the user's answer to a prompt.
+type Decision string
+
+const (
+	// Allow runs the tool with its original input.
+	Allow Decision = "allow"
+	// AllowAlways runs the tool and asks to remember the answer.
+	AllowAlways Decision = "allow_always"
+	// Deny rejects the tool use.
+	Deny Decision = "deny"
+	// Modify runs the tool with the input entered by the user.
+	Modify Decision = "modify"
+)
+
+// Response is the outcome of a prompt.
+type Response struct {
+	Decision Decision
+	// Input is the edited tool input when Decision is Modify.
+	Input map[string]interface{}
+}
+
+// Prompter asks for tool permissions on a terminal.
+// Prompts are serialized, so a Prompter is safe for concurrent use.
+type Prompter struct {
+	// MaxPreviewLines limits the lines shown per preview. Zero means DefaultMaxPreviewLines.
+	MaxPreviewLines int
+	// Color enables ANSI colors in diffs.
+	Color bool
+
+	out   io.Writer
+	mu    sync.Mutex
+	once  sync.Once
+	in    io.Reader
+	lines chan string
+	err   error
+}
+
+// New creates a Prompter that reads answers from in and writes prompts to out.
+func New(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: in, out: out}
+}
+
+var (
+	defaultPrompter     *Prompter
+	defaultPrompterOnce sync.Once
+)
+
+// Prompt asks about a tool use on the process's standard input and output.
+func Prompt(ctx context.Context, toolName string, input map[string]interface{}) (Response, error) {
+	defaultPrompterOnce.Do(func() {
+		defaultPrompter = New(os.Stdin, os.Stdout)
+	})
+	return defaultPrompter.Prompt(ctx, toolName, input)
+}
+
+// Prompt shows the pending tool use and waits for an answer. Unrecognized
+// answers are asked again; an empty answer denies. It returns ctx.Err() if
+// ctx ends first, and io.EOF when the input is closed.
+func (p *Prompter) Prompt(ctx context.Context, toolName string, input map[string]interface{}) (Response, error) {
+	return p.prompt(ctx, toolName, input, true)
+}
+
+// prompt implements Prompt, offering the edit option only if editable.
+func (p *Prompter) prompt(ctx context.Context, toolName string, input map[string]interface{}, editable bool) (Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.render(toolName, input)
+
+	question := "Allow? [y]es / [a]lways / [n]o: "
+	if editable {
+		question = "Allow? [y]es / [a]lways / [n]o / [e]dit: "
+	}
+	for {
+		fmt.Fprint(p.out, question)
+		answer, err := p.readLine(ctx)
+		if err != nil {
+			return Response{}, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return Response{Decision: Allow}, nil
+		case "a", "always":
+			return Response{Decision: AllowAlways}, nil
+		case "", "n", "no":
+			return Response{Decision: Deny}, nil
+		case "e", "edit":
+			if !editable {
+				continue
+			}
+			edited, err := p.edit(ctx, toolName, input)
+			if err != nil {
+				return Response{}, err
+			}
+			if edited != nil {
+				return Response{Decision: Modify, Input: edited}, nil
+			}
+		}
+	}
+}
+
+// CanUseTool returns a permission callback that prompts for every request.
+// AllowAlways answers allow the tool use and apply the CLI's permission
+// suggestions, if it sent any.
+func (p *Prompter) CanUseTool() types.CanUseToolFunc {
+	return func(ctx context.Context, toolName string, input map[string]interface{}, permCtx types.ToolPermissionContext) (interface{}, error) {
+		response, err := p.Prompt(ctx, toolName, input)
+		if err != nil {
+			return nil, err
+		}
+
+		switch response.Decision {
+		case Allow:
+			return types.PermissionResultAllow{Behavior: "allow"}, nil
+		case AllowAlways:
+			return types.PermissionResultAllow{Behavior: "allow", UpdatedPermissions: permCtx.Suggestions}, nil
+		case Modify:
+			return types.PermissionResultAllow{Behavior: "allow", UpdatedInput: &response.Input}, nil
+		default:
+			return types.PermissionResultDeny{Behavior: "deny", Message: "denied by the user"}, nil
+		}
+	}
+}
+
+// Approval is a permissions.PromptFunc for use with permissions.DecisionMemory.
+// Edited inputs are not supported there, so the edit option is not offered.
+func (p *Prompter) Approval(ctx context.Context, toolName string, input map[string]interface{}, permCtx types.ToolPermissionContext) (permissions.Approval, error) {
+	response, err := p.prompt(ctx, toolName, input, false)
+	if err != nil {
+		return "", err
+	}
+	switch response.Decision {
+	case Allow:
+		return permissions.ApproveOnce, nil
+	case AllowAlways:
+		return permissions.ApproveAlways, nil
+	default:
+		return permissions.DenyOnce, nil
+	}
+}
+
+// edit asks for a replacement input: a new command for Bash, a JSON object
+// otherwise. It returns nil if the user enters nothing.
+func (p *Prompter) edit(ctx context.Context, toolName string, input map[string]interface{}) (map[string]interface{}, error) {
+	if toolName == "Bash" {
+		fmt.Fprint(p.out, "New command: ")
+		line, err := p.readLine(ctx)
+		if err != nil || strings.TrimSpace(line) == "" {
+			return nil, err
+		}
+		edited := copyInput(input)
+		edited["command"] = strings.TrimSpace(line)
+		return edited, nil
+	}
+
+	for {
+		fmt.Fprint(p.out, "New input (JSON object): ")
+		line, err := p.readLine(ctx)
+		if err != nil || strings.TrimSpace(line) == "" {
+			return nil, err
+		}
+		var edited map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &edited); err != nil || edited == nil {
+			fmt.Fprintln(p.out, "Invalid JSON object.")
+			continue
+		}
+		return edited, nil
+	}
+}
+
+// readLine returns the next input line, or an error when ctx ends or the input closes.
+func (p *Prompter) readLine(ctx context.Context) (string, error) {
+	p.once.Do(func() {
+		p.lines = make(chan string)
+		go func() {
+			reader := bufio.NewReader(p.in)
+			for {
+				line, err := reader.ReadString('\n')
+				if line != "" || err == nil {
+					p.lines <- strings.TrimRight(line, "\r\n")
+				}
+				if err != nil {
+					p.err = err
+					close(p.lines)
+					return
+				}
+			}
+		}()
+	})
+
+	select {
+	case line, ok := <-p.lines:
+		if !ok {
+			return "", p.err
+		}
+		return line, nil
+	case <-ctx.Done():
+		fmt.Fprintln(p.out)
+		return "", ctx.Err()
+	}
+}
+
+// render writes a preview of the tool use.
+func (p *Prompter) render(toolName string, input map[string]interface{}) {
+	fmt.Fprintf(p.out, "\n%s wants to run:\n", toolName)
+
+	switch toolName {
+	case "Bash":
+		command, _ := input["command"].(string)
+		if description, ok := input["description"].(string); ok && description != "" {
+			fmt.Fprintf(p.out, "  # %s\n", description)
+		}
+		for _, line := range splitLines(command) {
+			fmt.Fprintf(p.out, "  $ %s\n", line)
+		}
+	case "Edit":
+		path, _ := input["file_path"].(string)
+		before, _ := input["old_string"].(string)
+		after, _ := input["new_string"].(string)
+		fmt.Fprintf(p.out, "  %s\n", path)
+		p.renderDiff(lineDiff(before, after))
+	case "MultiEdit":
+		path, _ := input["file_path"].(string)
+		fmt.Fprintf(p.out, "  %s\n", path)
+		edits, _ := input["edits"].([]interface{})
+		for i, e := range edits {
+			edit, _ := e.(map[string]interface{})
+			before, _ := edit["old_string"].(string)
+			after, _ := edit["new_string"].(string)
+			fmt.Fprintf(p.out, "  edit %d of %d:\n", i+1, len(edits))
+			p.renderDiff(lineDiff(before, after))
+		}
+	case "Write":
+		path, _ := input["file_path"].(string)
+		content, _ := input["content"].(string)
+		existing, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(p.out, "  %s (new file)\n", path)
+		} else {
+			fmt.Fprintf(p.out, "  %s (overwrite)\n", path)
+		}
+		p.renderDiff(lineDiff(string(existing), content))
+	default:
+		keys := make([]string, 0, len(input))
+		for key := range input {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, _ := json.Marshal(input[key])
+			fmt.Fprintf(p.out, "  %s: %s\n", key, value)
+		}
+	}
+}
+
+// renderDiff writes diff lines, truncated to the preview limit.
+func (p *Prompter) renderDiff(diff []diffLine) {
+	limit := p.MaxPreviewLines
+	if limit <= 0 {
+		limit = DefaultMaxPreviewLines
+	}
+
+	for i, line := range diff {
+		if i == limit {
+			fmt.Fprintf(p.out, "  ... %d more lines\n", len(diff)-limit)
+			return
+		}
+		text := fmt.Sprintf("  %c %s", line.op, line.text)
+		if p.Color {
+			switch line.op {
+			case '-':
+				text = "\x1b[31m" + text + "\x1b[0m"
+			case '+':
+				text = "\x1b[32m" + text + "\x1b[0m"
+			}
+		}
+		fmt.Fprintln(p.out, text)
+	}
+}
+
+// copyInput returns a shallow copy of a tool input.
+func copyInput(input map[string]interface{}) map[string]interface{} {
+	clone := make(map[string]interface{}, len(input))
+	for k, v := range input {
+		clone[k] = v
+	}
+	return clone
+}
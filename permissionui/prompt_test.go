@@ -0,0 +1,174 @@
+package permissionui
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/permissions"
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// TestPromptDecisions tests how answers map to decisions.
+func TestPromptDecisions(t *testing.T) {
+	tests := []struct {
+		answers string
+		want    Decision
+	}{
+		{"y\n", Allow},
+		{"YES\n", Allow},
+		{"a\n", AllowAlways},
+		{"n\n", Deny},
+		{"\n", Deny},
+		{"maybe\ny\n", Allow},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		response, err := New(strings.NewReader(tt.answers), &out).Prompt(context.Background(), "Bash", map[string]interface{}{"command": "ls"})
+		if err != nil {
+			t.Fatalf("Prompt(%q) failed: %v", tt.answers, err)
+		}
+		if response.Decision != tt.want {
+			t.Errorf("Prompt(%q) = %q, want %q", tt.answers, response.Decision, tt.want)
+		}
+	}
+}
+
+// TestPromptEdit tests editing Bash commands and JSON inputs.
+func TestPromptEdit(t *testing.T) {
+	var out bytes.Buffer
+	response, err := New(strings.NewReader("e\nls -la\n"), &out).Prompt(context.Background(), "Bash", map[string]interface{}{"command": "ls", "timeout": 5})
+	if err != nil {
+		t.Fatalf("Prompt failed: %v", err)
+	}
+	if response.Decision != Modify || response.Input["command"] != "ls -la" || response.Input["timeout"] != 5 {
+		t.Errorf("unexpected edit response: %+v", response)
+	}
+
+	response, err = New(strings.NewReader("e\nnot json\n{\"url\": \"https://example.com\"}\n"), &out).Prompt(context.Background(), "WebFetch", map[string]interface{}{"url": "http://example.com"})
+	if err != nil {
+		t.Fatalf("Prompt failed: %v", err)
+	}
+	if response.Decision != Modify || response.Input["url"] != "https://example.com" {
+		t.Errorf("unexpected edit response: %+v", response)
+	}
+	if !strings.Contains(out.String(), "Invalid JSON object.") {
+		t.Error("expected invalid JSON to be reported")
+	}
+}
+
+// TestPromptRender tests the previews for Bash, Edit and Write.
+func TestPromptRender(t *testing.T) {
+	var out bytes.Buffer
+	prompter := New(strings.NewReader("n\nn\nn\n"), &out)
+	ctx := context.Background()
+
+	if _, err := prompter.Prompt(ctx, "Bash", map[string]interface{}{"command": "go test ./...", "description": "Run tests"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prompter.Prompt(ctx, "Edit", map[string]interface{}{
+		"file_path":  "main.go",
+		"old_string": "a\nb\nc",
+		"new_string": "a\nB\nc",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("one\ntwo\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prompter.Prompt(ctx, "Write", map[string]interface{}{"file_path": path, "content": "one\nthree\n"}); err != nil {
+		t.Fatal(err)
+	}
+
+	output := out.String()
+	for _, want := range []string{"  # Run tests", "  $ go test ./...", "    a", "  - b", "  + B", "(overwrite)", "  - two", "  + three"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q:\n%s", want, output)
+		}
+	}
+}
+
+// TestPromptTruncatesPreview tests the preview line limit.
+func TestPromptTruncatesPreview(t *testing.T) {
+	var out bytes.Buffer
+	prompter := New(strings.NewReader("n\n"), &out)
+	prompter.MaxPreviewLines = 2
+
+	content := strings.Repeat("line\n", 5)
+	if _, err := prompter.Prompt(context.Background(), "Write", map[string]interface{}{"file_path": filepath.Join(t.TempDir(), "new.txt"), "content": content}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "... 3 more lines") || !strings.Contains(out.String(), "(new file)") {
+		t.Errorf("expected truncated new-file preview:\n%s", out.String())
+	}
+}
+
+// TestPromptCancellation tests that prompts end with the context or the input.
+func TestPromptCancellation(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := New(reader, io.Discard).Prompt(ctx, "Bash", map[string]interface{}{"command": "ls"}); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	if _, err := New(strings.NewReader(""), io.Discard).Prompt(context.Background(), "Bash", nil); err != io.EOF {
+		t.Errorf("expected EOF for closed input, got %v", err)
+	}
+}
+
+// TestCanUseToolAndApproval tests the permission callback and DecisionMemory adapter.
+func TestCanUseToolAndApproval(t *testing.T) {
+	ctx := context.Background()
+	input := map[string]interface{}{"command": "ls"}
+
+	canUseTool := New(strings.NewReader("e\nls -la\nn\n"), io.Discard).CanUseTool()
+	result, err := canUseTool(ctx, "Bash", input, types.ToolPermissionContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allow, ok := result.(types.PermissionResultAllow); !ok || (*allow.UpdatedInput)["command"] != "ls -la" {
+		t.Errorf("expected allow with edited input, got %+v", result)
+	}
+	result, _ = canUseTool(ctx, "Bash", input, types.ToolPermissionContext{})
+	if _, ok := result.(types.PermissionResultDeny); !ok {
+		t.Errorf("expected deny, got %+v", result)
+	}
+
+	approval, err := New(strings.NewReader("a\n"), io.Discard).Approval(ctx, "Bash", input, types.ToolPermissionContext{})
+	if err != nil || approval != permissions.ApproveAlways {
+		t.Errorf("expected ApproveAlways, got %q (err %v)", approval, err)
+	}
+
+	// Edits cannot be passed on, so the option is not offered
+	var out strings.Builder
+	approval, err = New(strings.NewReader("e\nrm -rf /\nn\n"), &out).Approval(ctx, "Bash", input, types.ToolPermissionContext{})
+	if err != nil || approval != permissions.DenyOnce {
+		t.Errorf("expected DenyOnce, got %q (err %v)", approval, err)
+	}
+	if strings.Contains(out.String(), "[e]dit") {
+		t.Errorf("expected no edit option, got %q", out.String())
+	}
+}
+
+// TestLineDiff tests the line diff used for previews.
+func TestLineDiff(t *testing.T) {
+	diff := lineDiff("a\nb\nc\n", "a\nc\nd\n")
+	var got []string
+	for _, line := range diff {
+		got = append(got, string(line.op)+line.text)
+	}
+	if want := " a,-b, c,+d"; strings.Join(got, ",") != want {
+		t.Errorf("lineDiff = %q, want %q", strings.Join(got, ","), want)
+	}
+}
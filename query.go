@@ -6,7 +6,6 @@ import (
 	"fmt"
 
 	"github.com/M1n9X/claude-agent-sdk-go/internal"
-	"github.com/M1n9X/claude-agent-sdk-go/internal/log"
 	"github.com/M1n9X/claude-agent-sdk-go/internal/transport"
 	"github.com/M1n9X/claude-agent-sdk-go/types"
 )
@@ -68,19 +67,32 @@ func Query(ctx context.Context, prompt string, options *types.ClaudeAgentOptions
 	if options == nil {
 		options = types.NewClaudeAgentOptions()
 	}
+	// Snapshot the options, so the caller may reuse or change them
+	options = options.Clone()
 
 	// Validate prompt
 	if prompt == "" {
 		return nil, fmt.Errorf("prompt cannot be empty")
 	}
 
-	// Find Claude CLI path
+	// Validate options
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	if options.Budget != nil {
+		if err := options.Budget.Allow(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Find Claude CLI path, unless replaying a wire dump
 	cliPath := ""
 	if options.CLIPath != nil {
 		cliPath = *options.CLIPath
-	} else {
+	} else if options.Replay == nil {
 		var err error
-		cliPath, err = transport.FindCLI()
+		cliPath, err = transport.FindCLIIn(options.CLISearchPaths)
 		if err != nil {
 			return nil, err
 		}
@@ -101,8 +113,8 @@ func Query(ctx context.Context, prompt string, options *types.ClaudeAgentOptions
 	}
 
 	// Create logger with verbosity from options
-	verbose := options != nil && options.Verbose
-	logger := log.NewLogger(verbose)
+	logger := newLogger(options)
+	logger.SetCorrelationID(types.CorrelationIDFrom(ctx))
 
 	// Determine resume session ID from options
 	resumeID := ""
@@ -110,8 +122,14 @@ func Query(ctx context.Context, prompt string, options *types.ClaudeAgentOptions
 		resumeID = *options.Resume
 	}
 
-	// Create subprocess transport with optional resume and options
-	transportInst := transport.NewSubprocessCLITransport(cliPath, cwd, env, logger, resumeID, options)
+	// Create subprocess transport with optional resume and options, or
+	// replay a wire dump
+	var transportInst transport.Transport
+	if options.Replay != nil {
+		transportInst = transport.NewReplayTransport(*options.Replay, logger, options)
+	} else {
+		transportInst = transport.NewSubprocessCLITransport(cliPath, cwd, env, logger, resumeID, options)
+	}
 
 	// Connect to CLI
 	if err := transportInst.Connect(ctx); err != nil {
@@ -130,6 +148,7 @@ func Query(ctx context.Context, prompt string, options *types.ClaudeAgentOptions
 		_ = transportInst.Close(ctx)
 		return nil, err
 	}
+	options.NotifyLifecycle(&types.ConnectedEvent{CorrelationID: types.CorrelationIDFrom(ctx)})
 
 	// Use resume ID as session ID, or default if not resuming
 	sessionID := "default-session"
@@ -162,6 +181,7 @@ func Query(ctx context.Context, prompt string, options *types.ClaudeAgentOptions
 		_ = transportInst.Close(ctx)
 		return nil, err
 	}
+	queryHandler.StartTurn(ctx)
 
 	// Create output channel for user
 	outputChan := make(chan types.Message, 10)
@@ -0,0 +1,210 @@
+// Package schema generates JSON schemas from Go types.
+//
+// The same struct can describe a tool's input (types.ToolFromFunc) and the
+// structured output of a query (types.ClaudeAgentOptions.WithStructuredOutput),
+// so one Go type drives both ends of a workflow.
+//
+// Fields are named by their json tag, and fields skipped by encoding/json are
+// left out. Struct tags add detail:
+//
+//   - desc:"..." sets the description
+//   - enum:"a,b,c" restricts the value to a comma-separated list
+//
+// A field is required unless it is a pointer or tagged omitempty or omitzero.
+//
+// Example:
+//
+//	type Review struct {
+//	    Verdict  string   `json:"verdict" enum:"approve,reject" desc:"Overall decision"`
+//	    Comments []string `json:"comments,omitempty" desc:"Line comments"`
+//	}
+//
+//	s, err := schema.FromStruct(Review{})
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FromStruct returns the JSON schema of v, which must be a struct or a pointer to a struct.
+func FromStruct(v interface{}) (map[string]interface{}, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema: expected a struct, got %v", reflect.TypeOf(v))
+	}
+	return FromType(t), nil
+}
+
+// For returns the JSON schema of struct type T.
+func For[T any]() (map[string]interface{}, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema: expected a struct, got %s", t)
+	}
+	return FromType(t), nil
+}
+
+// FromType returns the JSON schema of any Go type. Types with no JSON
+// equivalent, such as channels and functions, produce an empty schema.
+func FromType(t reflect.Type) map[string]interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+	g := generator{visiting: make(map[reflect.Type]bool)}
+	return g.typeSchema(t)
+}
+
+// timeType is the reflect.Type of time.Time, which is encoded as a string.
+var timeType = reflect.TypeOf(time.Time{})
+
+// generator tracks the structs being generated so recursive types terminate.
+type generator struct {
+	visiting map[reflect.Type]bool
+}
+
+// typeSchema returns the JSON schema of a Go type.
+func (g generator) typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes byte slices as base64 strings.
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if g.visiting[t] {
+			// A recursive reference: any object is accepted below this point.
+			return map[string]interface{}{"type": "object"}
+		}
+		g.visiting[t] = true
+		defer delete(g.visiting, t)
+		return g.structSchema(t)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema returns the JSON schema of an object with the fields of struct type t.
+func (g generator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	g.addFields(t, properties, &required)
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// addFields adds the fields of struct type t to properties, flattening
+// untagged embedded structs as encoding/json does.
+func (g generator) addFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, tagged, omitempty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+		if field.Anonymous && !tagged {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(embedded, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		prop := g.typeSchema(field.Type)
+		if desc := field.Tag.Get("desc"); desc != "" {
+			prop["description"] = desc
+		}
+		if enum, ok := field.Tag.Lookup("enum"); ok {
+			prop["enum"] = enumValues(field.Type, enum)
+		}
+		if _, exists := properties[name]; !exists && !omitempty && field.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+		properties[name] = prop
+	}
+}
+
+// enumValues parses a comma-separated enum tag into values of the field's
+// JSON type. Numbers are float64, as they are when JSON input is decoded.
+func enumValues(t reflect.Type, tag string) []interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var values []interface{}
+	for _, item := range strings.Split(tag, ",") {
+		item = strings.TrimSpace(item)
+		var value interface{} = item
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if n, err := strconv.ParseFloat(item, 64); err == nil {
+				value = n
+			}
+		case reflect.Bool:
+			if b, err := strconv.ParseBool(item); err == nil {
+				value = b
+			}
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// jsonFieldName returns the JSON name of a struct field, whether the json tag
+// names it, whether it is tagged omitempty, and whether encoding/json skips it.
+func jsonFieldName(field reflect.StructField) (name string, tagged, omitempty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false, true
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	tagged = name != ""
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" || option == "omitzero" {
+			omitempty = true
+		}
+	}
+	return name, tagged, omitempty, false
+}
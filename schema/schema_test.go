@@ -0,0 +1,143 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type address struct {
+	Street string `json:"street"`
+	City   string `json:"city" desc:"City name"`
+}
+
+type Base struct {
+	ID string `json:"id"`
+}
+
+type review struct {
+	Base
+	Verdict  string            `json:"verdict" enum:"approve, reject" desc:"Overall decision"`
+	Score    int               `json:"score" enum:"1,2,3"`
+	Comments []string          `json:"comments,omitempty"`
+	Labels   map[string]int    `json:"labels,omitempty"`
+	Address  *address          `json:"address"`
+	Homes    []address         `json:"homes"`
+	Due      time.Time         `json:"due"`
+	Raw      []byte            `json:"raw,omitempty"`
+	Extra    interface{}       `json:"extra,omitempty"`
+	Ignored  string            `json:"-"`
+	Meta     map[string]string `json:",omitempty"`
+}
+
+// TestFromStruct tests the schema generated from a struct.
+func TestFromStruct(t *testing.T) {
+	s, err := FromStruct(review{})
+	if err != nil {
+		t.Fatalf("FromStruct failed: %v", err)
+	}
+	if s["type"] != "object" {
+		t.Fatalf("expected object, got %v", s["type"])
+	}
+	props := s["properties"].(map[string]interface{})
+
+	wantProps := []string{"id", "verdict", "score", "comments", "labels", "address", "homes", "due", "raw", "extra", "Meta"}
+	if len(props) != len(wantProps) {
+		t.Errorf("expected %d properties, got %d: %v", len(wantProps), len(props), props)
+	}
+	for _, name := range wantProps {
+		if _, ok := props[name]; !ok {
+			t.Errorf("missing property %q", name)
+		}
+	}
+
+	wantRequired := []string{"id", "verdict", "score", "homes", "due"}
+	if !reflect.DeepEqual(s["required"], wantRequired) {
+		t.Errorf("expected required %v, got %v", wantRequired, s["required"])
+	}
+
+	verdict := props["verdict"].(map[string]interface{})
+	if verdict["description"] != "Overall decision" {
+		t.Errorf("unexpected description: %v", verdict["description"])
+	}
+	if !reflect.DeepEqual(verdict["enum"], []interface{}{"approve", "reject"}) {
+		t.Errorf("unexpected string enum: %v", verdict["enum"])
+	}
+	score := props["score"].(map[string]interface{})
+	if score["type"] != "integer" || !reflect.DeepEqual(score["enum"], []interface{}{1.0, 2.0, 3.0}) {
+		t.Errorf("unexpected integer enum: %v", score)
+	}
+
+	addr := props["address"].(map[string]interface{})
+	if addr["type"] != "object" {
+		t.Fatalf("expected nested object, got %v", addr)
+	}
+	city := addr["properties"].(map[string]interface{})["city"].(map[string]interface{})
+	if city["description"] != "City name" {
+		t.Errorf("unexpected nested description: %v", city)
+	}
+
+	homes := props["homes"].(map[string]interface{})
+	if homes["type"] != "array" || homes["items"].(map[string]interface{})["type"] != "object" {
+		t.Errorf("unexpected slice schema: %v", homes)
+	}
+	labels := props["labels"].(map[string]interface{})
+	if labels["type"] != "object" || labels["additionalProperties"].(map[string]interface{})["type"] != "integer" {
+		t.Errorf("unexpected map schema: %v", labels)
+	}
+	due := props["due"].(map[string]interface{})
+	if due["type"] != "string" || due["format"] != "date-time" {
+		t.Errorf("unexpected time schema: %v", due)
+	}
+	raw := props["raw"].(map[string]interface{})
+	if raw["type"] != "string" {
+		t.Errorf("expected []byte as string, got %v", raw)
+	}
+	if len(props["extra"].(map[string]interface{})) != 0 {
+		t.Errorf("expected empty schema for interface, got %v", props["extra"])
+	}
+}
+
+type node struct {
+	Name     string  `json:"name"`
+	Children []*node `json:"children,omitempty"`
+}
+
+// TestFromStructRecursive tests that recursive types terminate.
+func TestFromStructRecursive(t *testing.T) {
+	s, err := For[node]()
+	if err != nil {
+		t.Fatalf("For failed: %v", err)
+	}
+	children := s["properties"].(map[string]interface{})["children"].(map[string]interface{})
+	items := children["items"].(map[string]interface{})
+	if items["type"] != "object" || items["properties"] != nil {
+		t.Errorf("expected an open object for the recursive reference, got %v", items)
+	}
+}
+
+// TestFromStructRejectsNonStruct tests the error for non-struct values.
+func TestFromStructRejectsNonStruct(t *testing.T) {
+	if _, err := FromStruct("text"); err == nil {
+		t.Error("expected error for a string")
+	}
+	if _, err := FromStruct(nil); err == nil {
+		t.Error("expected error for nil")
+	}
+	if _, err := For[[]int](); err == nil {
+		t.Error("expected error for a slice")
+	}
+	if _, err := FromStruct(&address{}); err != nil {
+		t.Errorf("expected pointer to struct to be accepted: %v", err)
+	}
+}
+
+// TestFromType tests schemas of non-struct types.
+func TestFromType(t *testing.T) {
+	if s := FromType(reflect.TypeOf([]string{})); s["type"] != "array" {
+		t.Errorf("expected array, got %v", s)
+	}
+	if s := FromType(nil); len(s) != 0 {
+		t.Errorf("expected empty schema for nil, got %v", s)
+	}
+}
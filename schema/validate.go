@@ -0,0 +1,288 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError is a value that does not conform to its schema.
+type ValidationError struct {
+	Path    string // Location of the value, such as "$.items[2].name"
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors lists every nonconforming value found by Validate.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate checks a JSON value, as decoded by encoding/json into
+// interface{}, against a JSON schema. It supports the keywords this package
+// generates and the common ones of hand-written schemas: type, enum, const,
+// properties, required, additionalProperties, items, minItems, maxItems,
+// minLength, maxLength, minimum, maximum, anyOf and oneOf. Other keywords are
+// ignored. The error is a ValidationErrors listing every problem found.
+func Validate(s map[string]interface{}, value interface{}) error {
+	var errs ValidationErrors
+	validate(s, value, "$", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validate adds the problems of value at path to errs.
+func validate(s map[string]interface{}, value interface{}, path string, errs *ValidationErrors) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types := schemaTypes(s["type"]); len(types) > 0 && !matchesAnyType(value, types) {
+		fail("expected %s, got %s", strings.Join(types, " or "), jsonType(value))
+		return
+	}
+	if enum, ok := s["enum"]; ok {
+		if values := toSlice(enum); !containsValue(values, value) {
+			fail("must be one of %s, got %s", compactJSON(values), compactJSON(value))
+		}
+	}
+	if constant, ok := s["const"]; ok && !equalJSON(constant, value) {
+		fail("must be %s, got %s", compactJSON(constant), compactJSON(value))
+	}
+	if options := toSchemas(s["anyOf"]); len(options) > 0 && countMatches(options, value) == 0 {
+		fail("does not match any of the allowed schemas")
+	}
+	if options := toSchemas(s["oneOf"]); len(options) > 0 {
+		if n := countMatches(options, value); n != 1 {
+			fail("must match exactly one of the allowed schemas, matches %d", n)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		validateObject(s, v, path, errs)
+	case []interface{}:
+		if n, ok := toNumber(s["minItems"]); ok && float64(len(v)) < n {
+			fail("must have at least %v items, got %d", n, len(v))
+		}
+		if n, ok := toNumber(s["maxItems"]); ok && float64(len(v)) > n {
+			fail("must have at most %v items, got %d", n, len(v))
+		}
+		if items, ok := toSchema(s["items"]); ok {
+			for i, item := range v {
+				validate(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if n, ok := toNumber(s["minLength"]); ok && float64(length) < n {
+			fail("must be at least %v characters, got %d", n, length)
+		}
+		if n, ok := toNumber(s["maxLength"]); ok && float64(length) > n {
+			fail("must be at most %v characters, got %d", n, length)
+		}
+	case float64:
+		if n, ok := toNumber(s["minimum"]); ok && v < n {
+			fail("must be at least %v, got %v", n, v)
+		}
+		if n, ok := toNumber(s["maximum"]); ok && v > n {
+			fail("must be at most %v, got %v", n, v)
+		}
+	}
+}
+
+// validateObject adds the problems of the properties of object v to errs.
+func validateObject(s map[string]interface{}, v map[string]interface{}, path string, errs *ValidationErrors) {
+	for _, name := range toStrings(s["required"]) {
+		if _, ok := v[name]; !ok {
+			*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf("missing required property %q", name)})
+		}
+	}
+
+	properties, _ := toSchema(s["properties"])
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propertyPath := path + "." + name
+		if property, ok := toSchema(properties[name]); ok {
+			validate(property, v[name], propertyPath, errs)
+			continue
+		}
+		switch additional := s["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*errs = append(*errs, ValidationError{Path: propertyPath, Message: "is not an allowed property"})
+			}
+		case map[string]interface{}:
+			validate(additional, v[name], propertyPath, errs)
+		}
+	}
+}
+
+// countMatches returns how many of the schemas value conforms to.
+func countMatches(schemas []map[string]interface{}, value interface{}) int {
+	n := 0
+	for _, s := range schemas {
+		if Validate(s, value) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// schemaTypes returns the types allowed by a type keyword.
+func schemaTypes(t interface{}) []string {
+	if name, ok := t.(string); ok {
+		return []string{name}
+	}
+	return toStrings(t)
+}
+
+// matchesAnyType reports whether value has one of the JSON schema types.
+func matchesAnyType(value interface{}, types []string) bool {
+	actual := jsonType(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON schema type of a decoded JSON value. Whole
+// numbers are "integer".
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// containsValue reports whether values holds a value equal to v in JSON.
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, candidate := range values {
+		if equalJSON(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalJSON reports whether a and b have the same JSON encoding, so that a
+// schema written with Go values matches decoded JSON.
+func equalJSON(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	return compactJSON(a) == compactJSON(b)
+}
+
+// compactJSON returns the JSON encoding of v for messages.
+func compactJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// toSchema returns v as a schema. Schemas written in Go may use
+// map[string]string for simple properties.
+func toSchema(v interface{}) (map[string]interface{}, bool) {
+	switch s := v.(type) {
+	case map[string]interface{}:
+		return s, true
+	case map[string]string:
+		converted := make(map[string]interface{}, len(s))
+		for key, value := range s {
+			converted[key] = value
+		}
+		return converted, true
+	}
+	return nil, false
+}
+
+// toSchemas returns v as a list of schemas.
+func toSchemas(v interface{}) []map[string]interface{} {
+	var schemas []map[string]interface{}
+	for _, item := range toSlice(v) {
+		if s, ok := toSchema(item); ok {
+			schemas = append(schemas, s)
+		}
+	}
+	return schemas
+}
+
+// toSlice returns a slice of any element type as []interface{}.
+func toSlice(v interface{}) []interface{} {
+	if values, ok := v.([]interface{}); ok {
+		return values
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		return nil
+	}
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values
+}
+
+// toStrings returns the strings of a []string or []interface{}.
+func toStrings(v interface{}) []string {
+	var strs []string
+	for _, item := range toSlice(v) {
+		if s, ok := item.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
+
+// toNumber returns a numeric schema keyword as a float64.
+func toNumber(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestValidate tests validating JSON values against schemas.
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema map[string]interface{}
+		value  interface{}
+		errors []string
+	}{
+		{
+			name:   "nullable type",
+			schema: map[string]interface{}{"type": []interface{}{"string", "null"}},
+			value:  nil,
+		},
+		{
+			name:   "number accepts integer",
+			schema: map[string]interface{}{"type": "number", "maximum": 10},
+			value:  float64(11),
+			errors: []string{"$: must be at most 10, got 11"},
+		},
+		{
+			name:   "string length",
+			schema: map[string]interface{}{"type": "string", "minLength": 2, "maxLength": 3},
+			value:  "héllo",
+			errors: []string{"$: must be at most 3 characters, got 5"},
+		},
+		{
+			name: "nested object",
+			schema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"items": map[string]interface{}{
+						"type":     "array",
+						"minItems": 1,
+						"items": map[string]interface{}{
+							"type":       "object",
+							"properties": map[string]interface{}{"id": map[string]string{"type": "integer"}},
+							"required":   []interface{}{"id"},
+						},
+					},
+				},
+			},
+			value: map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"id": float64(1)},
+				map[string]interface{}{"id": "two"},
+				map[string]interface{}{},
+			}},
+			errors: []string{"$.items[1].id: expected integer, got string", `$.items[2]: missing required property "id"`},
+		},
+		{
+			name:   "const and oneOf",
+			schema: map[string]interface{}{"oneOf": []interface{}{map[string]interface{}{"const": "a"}, map[string]interface{}{"type": "string"}}},
+			value:  "a",
+			errors: []string{"$: must match exactly one of the allowed schemas, matches 2"},
+		},
+		{
+			name:   "anyOf",
+			schema: map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"type": "string"}, map[string]interface{}{"type": "boolean"}}},
+			value:  float64(1),
+			errors: []string{"$: does not match any of the allowed schemas"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.schema, tt.value)
+			if tt.errors == nil {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+			if len(errs) != len(tt.errors) {
+				t.Fatalf("expected %d errors, got %v", len(tt.errors), errs)
+			}
+			for i, want := range tt.errors {
+				if errs[i].Error() != want {
+					t.Errorf("error %d = %q, want %q", i, errs[i].Error(), want)
+				}
+			}
+		})
+	}
+}
+
+// TestValidateGeneratedSchema tests validating against a schema generated from a struct.
+func TestValidateGeneratedSchema(t *testing.T) {
+	type task struct {
+		Title    string   `json:"title"`
+		Priority string   `json:"priority" enum:"low,high"`
+		Estimate *int     `json:"estimate"`
+		Tags     []string `json:"tags,omitempty"`
+	}
+	s, err := For[task]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(s, map[string]interface{}{"title": "Fix", "priority": "high"}); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+	if err := Validate(s, map[string]interface{}{"title": "Fix", "priority": "urgent", "tags": []interface{}{"a"}}); err == nil {
+		t.Error("expected an enum error")
+	}
+}
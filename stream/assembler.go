@@ -0,0 +1,257 @@
+// Package stream builds the in-progress assistant message from the partial
+// message events sent with WithIncludePartialMessages, so a UI can render
+// text as it arrives and show a tool call while its input is still being
+// written.
+//
+// Example:
+//
+//	assembler := stream.NewAssembler()
+//	for msg := range messages {
+//	    event, ok := msg.(*types.StreamEvent)
+//	    if !ok {
+//	        continue
+//	    }
+//	    snapshot, err := assembler.Add(event)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    render(snapshot.Text())
+//	}
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Block is a content block of the message being assembled.
+type Block struct {
+	Index    int    // Position in the message content
+	Type     string // text, thinking, tool_use, ...
+	Complete bool   // The block's content_block_stop event arrived
+
+	Text      string // Text of a text block, or reasoning of a thinking block, so far
+	Signature string // Signature of a thinking block
+	Data      string // Encrypted reasoning of a redacted_thinking block
+
+	ToolUseID    string                 // ID of a tool_use block
+	ToolName     string                 // Name of the tool of a tool_use block
+	PartialInput string                 // JSON input of a tool_use block received so far
+	Input        map[string]interface{} // Parsed input, set once the block is complete
+}
+
+// Snapshot is the state of the message after an event.
+type Snapshot struct {
+	MessageID       string
+	Model           string
+	ParentToolUseID *string // Set for messages of a subagent
+	Blocks          []Block
+	StopReason      *string
+	Usage           map[string]interface{} // Latest token counts
+	Done            bool                   // The message_stop event arrived
+}
+
+// Text returns the text of the message's text blocks so far.
+func (s Snapshot) Text() string {
+	var b strings.Builder
+	for _, block := range s.Blocks {
+		if block.Type == "text" {
+			b.WriteString(block.Text)
+		}
+	}
+	return b.String()
+}
+
+// Thinking returns the reasoning of the message's thinking blocks so far,
+// kept apart from Text so a UI can show or hide it.
+func (s Snapshot) Thinking() string {
+	var b strings.Builder
+	for _, block := range s.Blocks {
+		if block.Type == "thinking" {
+			b.WriteString(block.Text)
+		}
+	}
+	return b.String()
+}
+
+// IsThinking reports whether Claude is reasoning: the block being streamed
+// is a thinking or redacted_thinking block.
+func (s Snapshot) IsThinking() bool {
+	current := s.Current()
+	return current != nil && (current.Type == "thinking" || current.Type == "redacted_thinking")
+}
+
+// RedactedThinking reports whether the message has reasoning that was
+// encrypted by safety systems and cannot be shown.
+func (s Snapshot) RedactedThinking() bool {
+	for _, block := range s.Blocks {
+		if block.Type == "redacted_thinking" {
+			return true
+		}
+	}
+	return false
+}
+
+// Current returns the block being streamed, or nil when every block so far
+// is complete.
+func (s Snapshot) Current() *Block {
+	for i := len(s.Blocks) - 1; i >= 0; i-- {
+		if !s.Blocks[i].Complete {
+			return &s.Blocks[i]
+		}
+	}
+	return nil
+}
+
+// Message returns the snapshot as an AssistantMessage. Text and thinking
+// blocks hold the text so far; a tool use block still being streamed has a
+// nil Input. Blocks of other types are left out.
+func (s Snapshot) Message() *types.AssistantMessage {
+	msg := &types.AssistantMessage{
+		Type:            "assistant",
+		Model:           s.Model,
+		ParentToolUseID: s.ParentToolUseID,
+	}
+	for _, block := range s.Blocks {
+		switch block.Type {
+		case "text":
+			msg.Content = append(msg.Content, &types.TextBlock{Type: "text", Text: block.Text})
+		case "thinking":
+			msg.Content = append(msg.Content, &types.ThinkingBlock{Type: "thinking", Thinking: block.Text, Signature: block.Signature})
+		case "redacted_thinking":
+			msg.Content = append(msg.Content, &types.RedactedThinkingBlock{Type: "redacted_thinking", Data: block.Data})
+		case "tool_use":
+			msg.Content = append(msg.Content, &types.ToolUseBlock{Type: "tool_use", ID: block.ToolUseID, Name: block.ToolName, Input: block.Input})
+		case "server_tool_use":
+			msg.Content = append(msg.Content, &types.ServerToolUseBlock{Type: "server_tool_use", ID: block.ToolUseID, Name: block.ToolName, Input: block.Input})
+		}
+	}
+	return msg
+}
+
+// Assembler builds a message from its stream events. Feed it the events of
+// one message stream at a time: a message_start event resets it. Subagent
+// messages, which have a ParentToolUseID, interleave with the main
+// conversation; use a separate Assembler for each ParentToolUseID.
+//
+// It is safe for concurrent use.
+type Assembler struct {
+	mu       sync.Mutex
+	snapshot Snapshot
+	byIndex  map[int]int // content block index -> position in snapshot.Blocks
+}
+
+// NewAssembler creates an empty assembler.
+func NewAssembler() *Assembler {
+	return &Assembler{byIndex: make(map[int]int)}
+}
+
+// Add applies an event and returns the resulting snapshot. Ping and unknown
+// events leave the snapshot unchanged. It returns an error for an event
+// that cannot be decoded, for an error event, and for a tool_use block
+// whose complete input is not valid JSON.
+func (a *Assembler) Add(event *types.StreamEvent) (Snapshot, error) {
+	decoded, err := event.Decode()
+	if err != nil {
+		return a.Snapshot(), err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch e := decoded.(type) {
+	case *types.MessageStartEvent:
+		a.snapshot = Snapshot{
+			MessageID:       e.Message.ID,
+			Model:           e.Message.Model,
+			ParentToolUseID: event.ParentToolUseID,
+			Usage:           e.Message.Usage,
+		}
+		a.byIndex = make(map[int]int)
+	case *types.ContentBlockStartEvent:
+		block := Block{
+			Index:     e.Index,
+			Type:      e.ContentBlock.Type,
+			Text:      e.ContentBlock.Text + e.ContentBlock.Thinking,
+			Signature: e.ContentBlock.Signature,
+			Data:      e.ContentBlock.Data,
+			ToolUseID: e.ContentBlock.ID,
+			ToolName:  e.ContentBlock.Name,
+		}
+		a.byIndex[e.Index] = len(a.snapshot.Blocks)
+		a.snapshot.Blocks = append(a.snapshot.Blocks, block)
+	case *types.ContentBlockDeltaEvent:
+		block := a.block(e.Index)
+		switch delta := e.Delta.(type) {
+		case *types.TextDelta:
+			block.Text += delta.Text
+		case *types.ThinkingDelta:
+			block.Text += delta.Thinking
+		case *types.SignatureDelta:
+			block.Signature += delta.Signature
+		case *types.InputJSONDelta:
+			block.PartialInput += delta.PartialJSON
+		}
+	case *types.ContentBlockStopEvent:
+		block := a.block(e.Index)
+		block.Complete = true
+		if block.Type == "tool_use" || block.Type == "server_tool_use" {
+			block.Input = map[string]interface{}{}
+			if strings.TrimSpace(block.PartialInput) != "" {
+				if err := json.Unmarshal([]byte(block.PartialInput), &block.Input); err != nil {
+					return a.copySnapshot(), fmt.Errorf("tool %s input is not valid JSON: %w", block.ToolName, err)
+				}
+			}
+		}
+	case *types.MessageDeltaEvent:
+		if e.Delta.StopReason != nil {
+			a.snapshot.StopReason = e.Delta.StopReason
+		}
+		if e.Usage != nil {
+			usage := make(map[string]interface{}, len(a.snapshot.Usage)+len(e.Usage))
+			for key, value := range a.snapshot.Usage {
+				usage[key] = value
+			}
+			for key, value := range e.Usage {
+				usage[key] = value
+			}
+			a.snapshot.Usage = usage
+		}
+	case *types.MessageStopEvent:
+		a.snapshot.Done = true
+	case *types.StreamErrorEvent:
+		return a.copySnapshot(), fmt.Errorf("stream error %s: %s", e.Error.Type, e.Error.Message)
+	}
+	return a.copySnapshot(), nil
+}
+
+// Snapshot returns the current state of the message.
+func (a *Assembler) Snapshot() Snapshot {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.copySnapshot()
+}
+
+// block returns the block at a content block index, adding it if its
+// content_block_start event was missed. The caller holds a.mu.
+func (a *Assembler) block(index int) *Block {
+	i, ok := a.byIndex[index]
+	if !ok {
+		i = len(a.snapshot.Blocks)
+		a.byIndex[index] = i
+		a.snapshot.Blocks = append(a.snapshot.Blocks, Block{Index: index})
+	}
+	return &a.snapshot.Blocks[i]
+}
+
+// copySnapshot returns a snapshot that later events do not change. The
+// caller holds a.mu.
+func (a *Assembler) copySnapshot() Snapshot {
+	snapshot := a.snapshot
+	snapshot.Blocks = append([]Block(nil), a.snapshot.Blocks...)
+	return snapshot
+}
@@ -0,0 +1,153 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// events returns stream events for the given API events.
+func events(t *testing.T, raw ...string) []*types.StreamEvent {
+	t.Helper()
+	var out []*types.StreamEvent
+	for _, event := range raw {
+		msg, err := types.UnmarshalMessage([]byte(`{"type":"stream_event","uuid":"u","session_id":"s","event":` + event + `}`))
+		if err != nil {
+			t.Fatalf("UnmarshalMessage failed: %v", err)
+		}
+		out = append(out, msg.(*types.StreamEvent))
+	}
+	return out
+}
+
+// TestAssembler tests building a message with text and a tool call.
+func TestAssembler(t *testing.T) {
+	stream := events(t,
+		`{"type":"message_start","message":{"id":"msg_1","model":"claude-sonnet-4-5","usage":{"input_tokens":10}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Let me "}}`,
+		`{"type":"ping"}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"check."}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"Bash","input":{}}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"command\": \"l"}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"s\"}"}}`,
+		`{"type":"content_block_stop","index":1}`,
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":25}}`,
+		`{"type":"message_stop"}`,
+	)
+
+	assembler := NewAssembler()
+	var snapshots []Snapshot
+	for _, event := range stream {
+		snapshot, err := assembler.Add(event)
+		if err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if text := snapshots[2].Text(); text != "Let me " {
+		t.Errorf("expected partial text, got %q", text)
+	}
+	partial := snapshots[7].Current()
+	if partial == nil || partial.ToolName != "Bash" || partial.PartialInput != `{"command": "l` || partial.Input != nil {
+		t.Errorf("expected the tool call in progress, got %+v", partial)
+	}
+	if snapshots[7].Blocks[1].PartialInput == snapshots[8].Blocks[1].PartialInput {
+		t.Error("expected earlier snapshots to be unchanged by later events")
+	}
+
+	final := snapshots[len(snapshots)-1]
+	if !final.Done || final.Current() != nil || final.MessageID != "msg_1" || *final.StopReason != "tool_use" {
+		t.Errorf("unexpected final snapshot: %+v", final)
+	}
+	if final.Usage["input_tokens"] != 10.0 || final.Usage["output_tokens"] != 25.0 {
+		t.Errorf("expected merged usage, got %v", final.Usage)
+	}
+
+	msg := final.Message()
+	if len(msg.Content) != 2 || msg.Model != "claude-sonnet-4-5" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	if text := msg.Content[0].(*types.TextBlock).Text; text != "Let me check." {
+		t.Errorf("unexpected text: %q", text)
+	}
+	if tool := msg.Content[1].(*types.ToolUseBlock); tool.ID != "toolu_1" || tool.Input["command"] != "ls" {
+		t.Errorf("unexpected tool use: %+v", tool)
+	}
+}
+
+// TestAssemblerErrors tests error events, invalid tool input and resetting
+// on a new message.
+func TestAssemblerErrors(t *testing.T) {
+	assembler := NewAssembler()
+	stream := events(t,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Hmm"}}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"Read"}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"path\":"}}`,
+		`{"type":"content_block_stop","index":1}`,
+	)
+	for _, event := range stream[:3] {
+		if _, err := assembler.Add(event); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if _, err := assembler.Add(stream[3]); err == nil || !strings.Contains(err.Error(), "not valid JSON") {
+		t.Errorf("expected an invalid input error, got %v", err)
+	}
+	if blocks := assembler.Snapshot().Blocks; len(blocks) != 2 || blocks[0].Text != "Hmm" {
+		t.Errorf("expected a block for a delta without a start event, got %+v", blocks)
+	}
+
+	if _, err := assembler.Add(events(t, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)[0]); err == nil {
+		t.Error("expected an error for an error event")
+	}
+
+	snapshot, _ := assembler.Add(events(t, `{"type":"message_start","message":{"id":"msg_2"}}`)[0])
+	if snapshot.MessageID != "msg_2" || len(snapshot.Blocks) != 0 {
+		t.Errorf("expected message_start to reset the assembler, got %+v", snapshot)
+	}
+}
+
+// TestAssemblerThinking tests keeping streamed reasoning apart from text.
+func TestAssemblerThinking(t *testing.T) {
+	stream := events(t,
+		`{"type":"message_start","message":{"id":"msg_1"}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"The user wants "}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"a greeting."}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig"}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"redacted_thinking","data":"encrypted"}}`,
+		`{"type":"content_block_stop","index":1}`,
+		`{"type":"content_block_start","index":2,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":2,"delta":{"type":"text_delta","text":"Hello!"}}`,
+	)
+	assembler := NewAssembler()
+	var snapshots []Snapshot
+	for _, event := range stream {
+		snapshot, err := assembler.Add(event)
+		if err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if s := snapshots[2]; !s.IsThinking() || s.Thinking() != "The user wants " || s.Text() != "" {
+		t.Errorf("expected reasoning in progress, got thinking %q text %q", s.Thinking(), s.Text())
+	}
+	final := snapshots[len(snapshots)-1]
+	if final.IsThinking() || final.Thinking() != "The user wants a greeting." || final.Text() != "Hello!" || !final.RedactedThinking() {
+		t.Errorf("unexpected final snapshot: thinking %q text %q", final.Thinking(), final.Text())
+	}
+
+	msg := final.Message()
+	if thinking := msg.Content[0].(*types.ThinkingBlock); thinking.Signature != "sig" {
+		t.Errorf("unexpected thinking block: %+v", thinking)
+	}
+	if redacted := msg.Content[1].(*types.RedactedThinkingBlock); redacted.Data != "encrypted" {
+		t.Errorf("unexpected redacted thinking block: %+v", redacted)
+	}
+}
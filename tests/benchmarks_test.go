@@ -125,6 +125,33 @@ func BenchmarkMessageParsing_Parallel(b *testing.B) {
 	})
 }
 
+// streamEventJSON is a typical partial message event.
+var streamEventJSON = []byte(`{"type":"stream_event","uuid":"9f4c","session_id":"sess_1","event":{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}},"parent_tool_use_id":null}`)
+
+// BenchmarkStreamEventParsing benchmarks parsing stream events with an
+// allocation per message.
+func BenchmarkStreamEventParsing(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := types.UnmarshalMessage(streamEventJSON); err != nil {
+			b.Fatalf("UnmarshalMessage() failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkStreamEventParsing_Pooled benchmarks parsing stream events with
+// pooled structs that are released after use.
+func BenchmarkStreamEventParsing_Pooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		msg, err := types.UnmarshalMessagePooled(streamEventJSON)
+		if err != nil {
+			b.Fatalf("UnmarshalMessagePooled() failed: %v", err)
+		}
+		msg.(*types.StreamEvent).Release()
+	}
+}
+
 // BenchmarkContentBlockParsing benchmarks content block parsing.
 func BenchmarkContentBlockParsing(b *testing.B) {
 	testBlocks := []string{
@@ -0,0 +1,123 @@
+package claude
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DefaultAPIBaseURL is the Anthropic API used when neither
+// TokenCounter.BaseURL nor ANTHROPIC_BASE_URL is set.
+const DefaultAPIBaseURL = "https://api.anthropic.com"
+
+// TokenCounter counts the input tokens of a prompt with the Anthropic API's
+// token counting endpoint, which is free and does not create a message. The
+// Claude CLI has no equivalent, so counting needs an API key even when the
+// CLI is logged in by other means.
+type TokenCounter struct {
+	APIKey     string       // Defaults to ANTHROPIC_API_KEY
+	BaseURL    string       // Defaults to ANTHROPIC_BASE_URL, then DefaultAPIBaseURL
+	HTTPClient *http.Client // Defaults to http.DefaultClient
+}
+
+// CountTokens returns the number of input tokens content takes as a user
+// message to model, using a TokenCounter configured from the environment.
+// Content is a string or content blocks, as accepted by
+// Client.QueryWithContent.
+//
+// Example:
+//
+//	n, err := claude.CountTokens(ctx, prompt, "claude-sonnet-4-5")
+//	if err == nil && n > budget {
+//	    prompt = summarize(prompt)
+//	}
+func CountTokens(ctx context.Context, content interface{}, model string) (int, error) {
+	return (&TokenCounter{}).CountTokens(ctx, content, model)
+}
+
+// CountTokens returns the number of input tokens content takes as a user
+// message to model.
+func (c *TokenCounter) CountTokens(ctx context.Context, content interface{}, model string) (int, error) {
+	if content == nil {
+		return 0, fmt.Errorf("content cannot be nil")
+	}
+	if model == "" {
+		return 0, fmt.Errorf("model is required to count tokens")
+	}
+	apiKey := c.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("ANTHROPIC_API_KEY")
+	}
+	if apiKey == "" {
+		return 0, fmt.Errorf("counting tokens requires an API key; set ANTHROPIC_API_KEY")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"model":    model,
+		"messages": []map[string]interface{}{{"role": "user", "content": content}},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("encode token count request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL()+"/v1/messages/count_tokens", bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("create token count request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", apiKey)
+	req.Header.Set("Anthropic-Version", "2023-06-01")
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("count tokens: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("read token count response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return 0, fmt.Errorf("count tokens: %s (%s): %s", resp.Status, apiErr.Error.Type, apiErr.Error.Message)
+		}
+		return 0, fmt.Errorf("count tokens: %s", resp.Status)
+	}
+
+	var result struct {
+		InputTokens int `json:"input_tokens"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return 0, fmt.Errorf("decode token count response: %w", err)
+	}
+	return result.InputTokens, nil
+}
+
+// baseURL returns the API base URL without a trailing slash.
+func (c *TokenCounter) baseURL() string {
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = os.Getenv("ANTHROPIC_BASE_URL")
+	}
+	if baseURL == "" {
+		baseURL = DefaultAPIBaseURL
+	}
+	return strings.TrimRight(baseURL, "/")
+}
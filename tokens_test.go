@@ -0,0 +1,80 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// TestTokenCounterCountTokens tests the token counting request and response
+func TestTokenCounterCountTokens(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages/count_tokens" {
+			t.Errorf("path = %q, want /v1/messages/count_tokens", r.URL.Path)
+		}
+		if key := r.Header.Get("X-Api-Key"); key != "test-key" {
+			t.Errorf("X-Api-Key = %q, want test-key", key)
+		}
+		if version := r.Header.Get("Anthropic-Version"); version == "" {
+			t.Error("Anthropic-Version header not set")
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"input_tokens":42}`))
+	}))
+	defer server.Close()
+
+	counter := &TokenCounter{APIKey: "test-key", BaseURL: server.URL + "/"}
+	blocks := []types.ContentBlock{&types.TextBlock{Type: "text", Text: "hello"}}
+	n, err := counter.CountTokens(context.Background(), blocks, "claude-sonnet-4-5")
+	if err != nil {
+		t.Fatalf("CountTokens() error = %v", err)
+	}
+	if n != 42 {
+		t.Errorf("CountTokens() = %d, want 42", n)
+	}
+
+	if got["model"] != "claude-sonnet-4-5" {
+		t.Errorf("model = %v, want claude-sonnet-4-5", got["model"])
+	}
+	messages, _ := got["messages"].([]interface{})
+	if len(messages) != 1 {
+		t.Fatalf("messages = %v, want one user message", got["messages"])
+	}
+	message := messages[0].(map[string]interface{})
+	content, _ := message["content"].([]interface{})
+	if message["role"] != "user" || len(content) != 1 || content[0].(map[string]interface{})["text"] != "hello" {
+		t.Errorf("message = %v, want user message with the text block", message)
+	}
+}
+
+// TestTokenCounterErrors tests API and configuration errors
+func TestTokenCounterErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"model: not found"}}`))
+	}))
+	defer server.Close()
+
+	counter := &TokenCounter{APIKey: "test-key", BaseURL: server.URL}
+	_, err := counter.CountTokens(context.Background(), "hello", "claude-nope")
+	if err == nil || !strings.Contains(err.Error(), "model: not found") {
+		t.Errorf("CountTokens() error = %v, want the API error message", err)
+	}
+
+	if _, err := counter.CountTokens(context.Background(), "hello", ""); err == nil {
+		t.Error("CountTokens() without model: expected error")
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	if _, err := CountTokens(context.Background(), "hello", "claude-sonnet-4-5"); err == nil || !strings.Contains(err.Error(), "ANTHROPIC_API_KEY") {
+		t.Errorf("CountTokens() without key error = %v, want API key error", err)
+	}
+}
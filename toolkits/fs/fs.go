@@ -0,0 +1,415 @@
+// Package fs provides file tools confined to a root directory: read_file,
+// write_file, list_directory and glob. Paths are resolved with os.Root, so
+// neither ".." components nor symbolic links can reach files outside the
+// root.
+//
+// Example:
+//
+//	server, err := fs.NewServer("files", "/srv/workspace", &fs.Options{ReadOnly: true})
+//	opts := types.NewClaudeAgentOptions().
+//	    WithMcpServers(map[string]interface{}{"files": server}).
+//	    WithAutoAllowSdkTools(true)
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+const (
+	// DefaultMaxFileSize is the largest file read_file returns when
+	// Options.MaxFileSize is zero.
+	DefaultMaxFileSize = 1 << 20
+
+	// DefaultMaxResults is the number of entries list_directory and glob
+	// return when Options.MaxResults is zero.
+	DefaultMaxResults = 1000
+)
+
+// Options configures the toolkit.
+type Options struct {
+	// ReadOnly leaves out the write_file tool.
+	ReadOnly bool
+
+	// MaxFileSize is the largest file, in bytes, read_file returns.
+	// Defaults to DefaultMaxFileSize.
+	MaxFileSize int64
+
+	// MaxResults caps the entries list_directory and glob return.
+	// Defaults to DefaultMaxResults.
+	MaxResults int
+}
+
+// New returns the file tools for the directory root. Tool paths are
+// relative to root; absolute paths are accepted when they lie beneath it.
+func New(root string, opts *Options) ([]types.McpTool, error) {
+	tk, err := newToolkit(root, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	builders := []*types.ToolBuilder{
+		types.NewTool("read_file").
+			Description(fmt.Sprintf("Read a text file. Paths are relative to %s.", tk.root)).
+			StringParam("path", "Path of the file", true).
+			Handler(tk.readFile),
+		types.NewTool("list_directory").
+			Description("List the files and directories in a directory. Directories end with /.").
+			StringParam("path", "Path of the directory; defaults to the root", false).
+			Handler(tk.listDirectory),
+		types.NewTool("glob").
+			Description("Find files whose paths match a pattern, such as *.go or src/**/*_test.go. ** matches any number of directories.").
+			StringParam("pattern", "Glob pattern, relative to the root", true).
+			Handler(tk.glob),
+	}
+	if !tk.readOnly {
+		builders = append(builders, types.NewTool("write_file").
+			Description("Write a text file, replacing it if it exists and creating missing parent directories.").
+			StringParam("path", "Path of the file", true).
+			StringParam("content", "Content to write", true).
+			Handler(tk.writeFile))
+	}
+
+	tools := make([]types.McpTool, 0, len(builders))
+	for _, builder := range builders {
+		tool, err := builder.Build()
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// NewServer returns an SDK MCP server named name serving the tools of New.
+func NewServer(name, root string, opts *Options) (*types.ToolServerConfig, error) {
+	tools, err := New(root, opts)
+	if err != nil {
+		return nil, err
+	}
+	return types.CreateToolServer(name, "1.0.0", tools), nil
+}
+
+// toolkit holds the configuration shared by the tools.
+type toolkit struct {
+	root        string
+	readOnly    bool
+	maxFileSize int64
+	maxResults  int
+}
+
+// newToolkit checks root and applies the defaults to opts.
+func newToolkit(root string, opts *Options) (*toolkit, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("file toolkit root: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("file toolkit root %s is not a directory", abs)
+	}
+
+	tk := &toolkit{
+		root:        abs,
+		readOnly:    opts.ReadOnly,
+		maxFileSize: opts.MaxFileSize,
+		maxResults:  opts.MaxResults,
+	}
+	if tk.maxFileSize <= 0 {
+		tk.maxFileSize = DefaultMaxFileSize
+	}
+	if tk.maxResults <= 0 {
+		tk.maxResults = DefaultMaxResults
+	}
+	return tk, nil
+}
+
+// open opens the root. It is opened for each call, so the tools hold no
+// file descriptors between calls.
+func (tk *toolkit) open() (*os.Root, error) {
+	return os.OpenRoot(tk.root)
+}
+
+// relative converts a tool path to a slash-separated path relative to the
+// root. Paths that leave the root lexically are rejected here; os.Root
+// rejects those that leave it through symbolic links.
+func (tk *toolkit) relative(p string) (string, error) {
+	if p == "" {
+		return ".", nil
+	}
+	rel := p
+	if filepath.IsAbs(p) {
+		var err error
+		if rel, err = filepath.Rel(tk.root, filepath.Clean(p)); err != nil {
+			return "", outsideRootError(p)
+		}
+	}
+	rel = filepath.ToSlash(filepath.Clean(rel))
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", outsideRootError(p)
+	}
+	return rel, nil
+}
+
+// outsideRootError reports a path outside the root.
+func outsideRootError(p string) error {
+	return fmt.Errorf("path %s is outside the allowed directory", p)
+}
+
+// toolError converts an error to an error result, hiding the root's
+// absolute path from path errors.
+func (tk *toolkit) toolError(action, p string, err error) *types.ToolResult {
+	var pathErr *iofs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	if strings.Contains(err.Error(), "escapes from parent") {
+		err = outsideRootError(p)
+	}
+	return types.NewErrorMcpToolResult(fmt.Sprintf("Failed to %s %s: %v", action, p, err))
+}
+
+// readFile returns the contents of the file at path.
+func (tk *toolkit) readFile(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+	p, err := types.Args(input).GetString("path")
+	if err != nil {
+		return types.NewErrorMcpToolResult(err.Error()), nil
+	}
+	rel, err := tk.relative(p)
+	if err != nil {
+		return types.NewErrorMcpToolResult(err.Error()), nil
+	}
+
+	root, err := tk.open()
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	file, err := root.Open(rel)
+	if err != nil {
+		return tk.toolError("read", p, err), nil
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return tk.toolError("read", p, err), nil
+	}
+	if info.IsDir() {
+		return types.NewErrorMcpToolResult(fmt.Sprintf("%s is a directory; use list_directory", p)), nil
+	}
+	if info.Size() > tk.maxFileSize {
+		return types.NewErrorMcpToolResult(fmt.Sprintf("%s is %d bytes, over the %d byte limit", p, info.Size(), tk.maxFileSize)), nil
+	}
+
+	data := make([]byte, info.Size())
+	if _, err := io.ReadFull(file, data); err != nil {
+		return tk.toolError("read", p, err), nil
+	}
+	if !utf8.Valid(data) {
+		return types.NewErrorMcpToolResult(fmt.Sprintf("%s is not a text file", p)), nil
+	}
+	return types.NewMcpToolResult(types.TextBlock{Type: "text", Text: string(data)}), nil
+}
+
+// writeFile writes content to the file at path.
+func (tk *toolkit) writeFile(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+	args := types.Args(input)
+	p, err := args.GetString("path")
+	if err != nil {
+		return types.NewErrorMcpToolResult(err.Error()), nil
+	}
+	content, err := args.GetString("content")
+	if err != nil {
+		return types.NewErrorMcpToolResult(err.Error()), nil
+	}
+	rel, err := tk.relative(p)
+	if err != nil {
+		return types.NewErrorMcpToolResult(err.Error()), nil
+	}
+	if rel == "." {
+		return types.NewErrorMcpToolResult("path must name a file"), nil
+	}
+
+	root, err := tk.open()
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	if err := mkdirAll(root, path.Dir(rel)); err != nil {
+		return tk.toolError("create the directory for", p, err), nil
+	}
+	file, err := root.OpenFile(rel, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return tk.toolError("write", p, err), nil
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return tk.toolError("write", p, err), nil
+	}
+	if err := file.Close(); err != nil {
+		return tk.toolError("write", p, err), nil
+	}
+	return types.NewMcpToolResult(types.TextBlock{
+		Type: "text",
+		Text: fmt.Sprintf("Wrote %d bytes to %s", len(content), p),
+	}), nil
+}
+
+// mkdirAll creates dir and its missing parents inside root.
+func mkdirAll(root *os.Root, dir string) error {
+	if dir == "." {
+		return nil
+	}
+	current := ""
+	for _, part := range strings.Split(dir, "/") {
+		current = path.Join(current, part)
+		if err := root.Mkdir(current, 0o755); err != nil && !errors.Is(err, iofs.ErrExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// listDirectory lists the entries of the directory at path.
+func (tk *toolkit) listDirectory(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+	args := types.Args(input)
+	p := "."
+	if args.Has("path") {
+		var err error
+		if p, err = args.GetString("path"); err != nil {
+			return types.NewErrorMcpToolResult(err.Error()), nil
+		}
+	}
+	rel, err := tk.relative(p)
+	if err != nil {
+		return types.NewErrorMcpToolResult(err.Error()), nil
+	}
+
+	root, err := tk.open()
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	entries, err := iofs.ReadDir(root.FS(), rel)
+	if err != nil {
+		return tk.toolError("list", p, err), nil
+	}
+	if len(entries) == 0 {
+		return types.NewMcpToolResult(types.TextBlock{Type: "text", Text: fmt.Sprintf("%s is empty", p)}), nil
+	}
+
+	var b strings.Builder
+	for i, entry := range entries {
+		if i == tk.maxResults {
+			fmt.Fprintf(&b, "... and %d more entries\n", len(entries)-i)
+			break
+		}
+		b.WriteString(entry.Name())
+		if entry.IsDir() {
+			b.WriteString("/")
+		}
+		b.WriteString("\n")
+	}
+	return types.NewMcpToolResult(types.TextBlock{Type: "text", Text: b.String()}), nil
+}
+
+// glob lists the files whose paths match pattern.
+func (tk *toolkit) glob(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+	pattern, err := types.Args(input).GetString("pattern")
+	if err != nil {
+		return types.NewErrorMcpToolResult(err.Error()), nil
+	}
+	rel, err := tk.relative(pattern)
+	if err != nil {
+		return types.NewErrorMcpToolResult(err.Error()), nil
+	}
+	if _, err := path.Match(strings.ReplaceAll(rel, "**", "*"), ""); err != nil {
+		return types.NewErrorMcpToolResult(fmt.Sprintf("Invalid pattern %s: %v", pattern, err)), nil
+	}
+
+	root, err := tk.open()
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	var matches []string
+	truncated := false
+	err = iofs.WalkDir(root.FS(), ".", func(p string, entry iofs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable directories
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if p == "." || entry.IsDir() || !matchGlob(rel, p) {
+			return nil
+		}
+		if len(matches) == tk.maxResults {
+			truncated = true
+			return iofs.SkipAll
+		}
+		matches = append(matches, p)
+		return nil
+	})
+	if err != nil {
+		return tk.toolError("search", pattern, err), nil
+	}
+	if len(matches) == 0 {
+		return types.NewMcpToolResult(types.TextBlock{Type: "text", Text: fmt.Sprintf("No files match %s", pattern)}), nil
+	}
+
+	text := strings.Join(matches, "\n") + "\n"
+	if truncated {
+		text += fmt.Sprintf("... more files match; showing the first %d\n", tk.maxResults)
+	}
+	return types.NewMcpToolResult(types.TextBlock{Type: "text", Text: text}), nil
+}
+
+// matchGlob reports whether the slash-separated name matches pattern. A
+// "**" segment matches any number of directories, including none; other
+// segments follow path.Match.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches path segments against pattern segments.
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
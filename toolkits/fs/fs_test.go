@@ -0,0 +1,162 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// newTestRoot returns a root directory with a few files and a symbolic link
+// to a directory outside it.
+func newTestRoot(t *testing.T) (root, outside string) {
+	t.Helper()
+	root = t.TempDir()
+	outside = t.TempDir()
+	files := map[string]string{
+		"README.md":             "# Project\n",
+		"src/main.go":           "package main\n",
+		"src/util/util.go":      "package util\n",
+		"src/util/util_test.go": "package util\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symbolic links not supported: %v", err)
+	}
+	return root, outside
+}
+
+// callTool runs the named tool and returns its text and error flag.
+func callTool(t *testing.T, tools []types.McpTool, name string, input map[string]interface{}) (string, bool) {
+	t.Helper()
+	for _, tool := range tools {
+		if tool.Name() == name {
+			result, err := tool.Execute(context.Background(), input)
+			if err != nil {
+				t.Fatalf("%s: Execute() error: %v", name, err)
+			}
+			return result.Content[0].(types.TextBlock).Text, result.IsError
+		}
+	}
+	t.Fatalf("tool %s not found", name)
+	return "", false
+}
+
+// TestFileTools tests reading, writing, listing and globbing inside the root.
+func TestFileTools(t *testing.T) {
+	root, _ := newTestRoot(t)
+	tools, err := New(root, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	if text, isError := callTool(t, tools, "read_file", map[string]interface{}{"path": "src/main.go"}); isError || text != "package main\n" {
+		t.Errorf("read_file = %q, %v", text, isError)
+	}
+	if text, isError := callTool(t, tools, "read_file", map[string]interface{}{"path": filepath.Join(root, "README.md")}); isError || text != "# Project\n" {
+		t.Errorf("read_file with an absolute path = %q, %v", text, isError)
+	}
+
+	if text, isError := callTool(t, tools, "write_file", map[string]interface{}{"path": "out/report/summary.txt", "content": "done"}); isError {
+		t.Fatalf("write_file error: %s", text)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "out/report/summary.txt")); err != nil || string(data) != "done" {
+		t.Errorf("expected the written file, got %q, %v", data, err)
+	}
+
+	if text, _ := callTool(t, tools, "list_directory", map[string]interface{}{}); text != "README.md\nescape\nout/\nsrc/\n" {
+		t.Errorf("unexpected listing %q", text)
+	}
+	if text, _ := callTool(t, tools, "glob", map[string]interface{}{"pattern": "src/**/*.go"}); text != "src/main.go\nsrc/util/util.go\nsrc/util/util_test.go\n" {
+		t.Errorf("unexpected glob matches %q", text)
+	}
+	if text, _ := callTool(t, tools, "glob", map[string]interface{}{"pattern": "**/*_test.go"}); text != "src/util/util_test.go\n" {
+		t.Errorf("unexpected glob matches %q", text)
+	}
+}
+
+// TestFileToolsConfinement tests that paths cannot leave the root.
+func TestFileToolsConfinement(t *testing.T) {
+	root, outside := newTestRoot(t)
+	tools, err := New(root, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	escapes := []struct {
+		tool  string
+		input map[string]interface{}
+	}{
+		{"read_file", map[string]interface{}{"path": "../" + filepath.Base(outside) + "/secret.txt"}},
+		{"read_file", map[string]interface{}{"path": filepath.Join(outside, "secret.txt")}},
+		{"read_file", map[string]interface{}{"path": "escape/secret.txt"}},
+		{"read_file", map[string]interface{}{"path": "src/../../secret.txt"}},
+		{"write_file", map[string]interface{}{"path": "escape/new.txt", "content": "x"}},
+		{"write_file", map[string]interface{}{"path": "escape/dir/new.txt", "content": "x"}},
+		{"list_directory", map[string]interface{}{"path": "escape"}},
+		{"glob", map[string]interface{}{"pattern": "../*"}},
+	}
+	for _, tt := range escapes {
+		text, isError := callTool(t, tools, tt.tool, tt.input)
+		if !isError || text == "secret" {
+			t.Errorf("%s(%v): expected an error, got %q", tt.tool, tt.input, text)
+		}
+	}
+	entries, _ := os.ReadDir(outside)
+	if len(entries) != 1 {
+		t.Errorf("expected nothing written outside the root, got %d entries", len(entries))
+	}
+
+	readOnly, err := New(root, &Options{ReadOnly: true, MaxFileSize: 4})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	for _, tool := range readOnly {
+		if tool.Name() == "write_file" {
+			t.Error("expected no write_file tool when read-only")
+		}
+	}
+	if text, isError := callTool(t, readOnly, "read_file", map[string]interface{}{"path": "README.md"}); !isError || !strings.Contains(text, "over the 4 byte limit") {
+		t.Errorf("expected a size error, got %q", text)
+	}
+
+	if _, err := New(filepath.Join(root, "README.md"), nil); err == nil {
+		t.Error("expected error for a root that is not a directory")
+	}
+}
+
+// TestMatchGlob tests glob patterns with ** segments.
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "src/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "a/b/c.go", true},
+		{"src/**", "src/a/b", true},
+		{"src/**/test/*.go", "src/test/a.go", true},
+		{"src/**/test/*.go", "src/x/y/test/a.go", true},
+		{"src/**/test/*.go", "lib/test/a.go", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
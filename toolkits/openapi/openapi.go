@@ -0,0 +1,610 @@
+// Package openapi turns an OpenAPI 3 document into tools, one per
+// operation, so an existing REST API can be exposed to Claude without
+// writing a tool for each endpoint. A tool's input schema is derived from
+// the operation's path, query and header parameters, with the JSON request
+// body under "body". Calling the tool sends the request and returns the
+// response body; responses with an error status are returned as error
+// results.
+//
+// Documents are read as JSON. Convert YAML documents to JSON first.
+//
+// Example:
+//
+//	spec, err := os.ReadFile("petstore.json")
+//	...
+//	server, err := openapi.NewServer("petstore", spec, &openapi.Options{
+//	    Auth:   openapi.BearerToken(os.Getenv("PETSTORE_TOKEN")),
+//	    Filter: openapi.ReadOnly,
+//	})
+//	opts := types.NewClaudeAgentOptions().
+//	    WithMcpServers(map[string]interface{}{"petstore": server})
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+const (
+	// DefaultTimeout limits each tool call when Options.Timeout is zero.
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultMaxResponseBytes caps the response body a tool returns when
+	// Options.MaxResponseBytes is zero.
+	DefaultMaxResponseBytes = 1 << 20
+
+	// maxNameLength is the longest tool name MCP clients accept.
+	maxNameLength = 64
+)
+
+// methods lists the operation keys of a path item, in the order tools are
+// created for each path.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Operation describes an API operation, for use in filters.
+type Operation struct {
+	ID          string // operationId, or a name derived from the method and path
+	Method      string // Upper-case HTTP method
+	Path        string // Path template, such as /pets/{petId}
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+}
+
+// Options configures the generated tools.
+type Options struct {
+	// BaseURL is the URL the operation paths are appended to. Defaults to
+	// the first server URL of the document.
+	BaseURL string
+
+	// HTTPClient sends the requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// Auth adds credentials to every request, for example with BearerToken
+	// or APIKeyHeader. Nil sends requests as built.
+	Auth func(req *http.Request) error
+
+	// Filter reports whether to create a tool for an operation. Nil creates
+	// tools for every operation that is not deprecated.
+	Filter func(op Operation) bool
+
+	// Timeout limits each tool call. Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// MaxResponseBytes caps the response body returned to Claude. Defaults
+	// to DefaultMaxResponseBytes.
+	MaxResponseBytes int64
+}
+
+// ReadOnly is a filter that keeps the GET and HEAD operations.
+func ReadOnly(op Operation) bool {
+	return op.Method == http.MethodGet || op.Method == http.MethodHead
+}
+
+// Tags returns a filter that keeps the operations with one of tags.
+func Tags(tags ...string) func(op Operation) bool {
+	allowed := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		allowed[tag] = true
+	}
+	return func(op Operation) bool {
+		for _, tag := range op.Tags {
+			if allowed[tag] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// BearerToken returns an Auth function that sends token in an
+// "Authorization: Bearer" header.
+func BearerToken(token string) func(req *http.Request) error {
+	return func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+// APIKeyHeader returns an Auth function that sends key in the named header.
+func APIKeyHeader(name, key string) func(req *http.Request) error {
+	return func(req *http.Request) error {
+		req.Header.Set(name, key)
+		return nil
+	}
+}
+
+// APIKeyQuery returns an Auth function that sends key in the named query
+// parameter.
+func APIKeyQuery(name, key string) func(req *http.Request) error {
+	return func(req *http.Request) error {
+		query := req.URL.Query()
+		query.Set(name, key)
+		req.URL.RawQuery = query.Encode()
+		return nil
+	}
+}
+
+// BasicAuth returns an Auth function that sends HTTP basic credentials.
+func BasicAuth(username, password string) func(req *http.Request) error {
+	return func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	}
+}
+
+// New returns a tool for each operation in spec that passes the filter.
+// It returns an error when the document is not OpenAPI 3, has no base URL,
+// or two operations would get the same tool name.
+func New(spec []byte, opts *Options) ([]types.McpTool, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("parse OpenAPI document: %w", err)
+	}
+	version, _ := doc["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, want 3.x", version)
+	}
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		if servers, ok := doc["servers"].([]interface{}); ok && len(servers) > 0 {
+			server, _ := servers[0].(map[string]interface{})
+			baseURL, _ = server["url"].(string)
+		}
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("OpenAPI document has no server URL; set Options.BaseURL")
+	}
+
+	client := opts.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	maxBytes := opts.MaxResponseBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxResponseBytes
+	}
+
+	r := &resolver{doc: doc}
+	paths, _ := doc["paths"].(map[string]interface{})
+	pathKeys := make([]string, 0, len(paths))
+	for path := range paths {
+		pathKeys = append(pathKeys, path)
+	}
+	sort.Strings(pathKeys)
+
+	var tools []types.McpTool
+	names := make(map[string]string) // tool name -> method and path
+	for _, path := range pathKeys {
+		item, _ := r.resolve(paths[path]).(map[string]interface{})
+		for _, method := range methods {
+			raw, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			op := operation(method, path, raw)
+			keep := !op.Deprecated
+			if opts.Filter != nil {
+				keep = opts.Filter(op)
+			}
+			if !keep {
+				continue
+			}
+
+			tool, err := newOperationTool(r, op, item, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", op.Method, path, err)
+			}
+			if other, ok := names[tool.name]; ok {
+				return nil, fmt.Errorf("operations %s and %s %s are both named %s", other, op.Method, path, tool.name)
+			}
+			names[tool.name] = op.Method + " " + path
+			tool.baseURL = strings.TrimSuffix(baseURL, "/")
+			tool.client = client
+			tool.auth = opts.Auth
+			tool.timeout = timeout
+			tool.maxBytes = maxBytes
+			tools = append(tools, tool)
+		}
+	}
+	return tools, nil
+}
+
+// NewServer returns an SDK MCP server named name serving the tools of New.
+// The server version is the document's info.version.
+func NewServer(name string, spec []byte, opts *Options) (*types.ToolServerConfig, error) {
+	tools, err := New(spec, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range tools {
+		if err := types.ValidateMcpToolName(name, tool.Name()); err != nil {
+			return nil, err
+		}
+	}
+	version := "1.0.0"
+	var doc struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+	}
+	if json.Unmarshal(spec, &doc) == nil && doc.Info.Version != "" {
+		version = doc.Info.Version
+	}
+	return types.CreateToolServer(name, version, tools), nil
+}
+
+// operation describes the operation raw at method and path.
+func operation(method, path string, raw map[string]interface{}) Operation {
+	op := Operation{Method: strings.ToUpper(method), Path: path}
+	op.ID, _ = raw["operationId"].(string)
+	op.Summary, _ = raw["summary"].(string)
+	op.Description, _ = raw["description"].(string)
+	op.Deprecated, _ = raw["deprecated"].(bool)
+	if tags, ok := raw["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			if s, ok := tag.(string); ok {
+				op.Tags = append(op.Tags, s)
+			}
+		}
+	}
+	if op.ID == "" {
+		op.ID = method + strings.ReplaceAll(path, "/", "_")
+	}
+	return op
+}
+
+// invalidNameChars matches runs of underscores and of the characters not
+// allowed in tool names.
+var invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9-]+`)
+
+// toolName converts an operation ID to a valid tool name.
+func toolName(id string) string {
+	name := strings.Trim(invalidNameChars.ReplaceAllString(id, "_"), "_")
+	if len(name) > maxNameLength {
+		name = name[:maxNameLength]
+	}
+	return name
+}
+
+// parameter is an operation parameter sent in the path, query or headers.
+type parameter struct {
+	name     string // Name in the request
+	property string // Name in the tool input
+	in       string // path, query or header
+}
+
+// operationTool is a tool that calls an API operation.
+type operationTool struct {
+	name        string
+	description string
+	schema      map[string]interface{}
+	method      string
+	path        string
+	params      []parameter
+	body        string // Input property holding the request body, if any
+
+	baseURL  string
+	client   *http.Client
+	auth     func(req *http.Request) error
+	timeout  time.Duration
+	maxBytes int64
+}
+
+// newOperationTool builds the tool for op. item is the path item holding
+// the parameters shared by its operations.
+func newOperationTool(r *resolver, op Operation, item, raw map[string]interface{}) (*operationTool, error) {
+	t := &operationTool{
+		name:   toolName(op.ID),
+		method: op.Method,
+		path:   op.Path,
+	}
+	if t.name == "" {
+		return nil, fmt.Errorf("cannot derive a tool name from %q", op.ID)
+	}
+
+	description := op.Summary
+	if op.Description != "" && op.Description != op.Summary {
+		description = strings.TrimSpace(description + "\n\n" + op.Description)
+	}
+	t.description = strings.TrimSpace(fmt.Sprintf("%s %s\n\n%s", op.Method, op.Path, description))
+
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	// Operation parameters override path item parameters with the same
+	// name and location.
+	declared := map[string]map[string]interface{}{}
+	var order []string
+	for _, list := range []interface{}{item["parameters"], raw["parameters"]} {
+		entries, _ := list.([]interface{})
+		for _, entry := range entries {
+			param, ok := r.resolve(entry).(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := param["name"].(string)
+			in, _ := param["in"].(string)
+			key := in + "\x00" + name
+			if _, seen := declared[key]; !seen {
+				order = append(order, key)
+			}
+			declared[key] = param
+		}
+	}
+	for _, key := range order {
+		param := declared[key]
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		if in == "cookie" || name == "" {
+			continue
+		}
+		if _, taken := properties[name]; taken {
+			return nil, fmt.Errorf("parameter %s is declared in more than one location", name)
+		}
+
+		schema, _ := r.schema(param["schema"]).(map[string]interface{})
+		if schema == nil {
+			schema = map[string]interface{}{"type": "string"}
+		}
+		if description, ok := param["description"].(string); ok && description != "" {
+			schema = withDescription(schema, description)
+		}
+		properties[name] = schema
+		if isRequired, _ := param["required"].(bool); isRequired || in == "path" {
+			required = append(required, name)
+		}
+		t.params = append(t.params, parameter{name: name, property: name, in: in})
+	}
+
+	if body, ok := r.resolve(raw["requestBody"]).(map[string]interface{}); ok {
+		content, _ := body["content"].(map[string]interface{})
+		media, ok := content["application/json"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("only application/json request bodies are supported")
+		}
+		t.body = "body"
+		if _, taken := properties[t.body]; taken {
+			t.body = "request_body"
+		}
+		schema, _ := r.schema(media["schema"]).(map[string]interface{})
+		if schema == nil {
+			schema = map[string]interface{}{}
+		}
+		if description, ok := body["description"].(string); ok && description != "" {
+			schema = withDescription(schema, description)
+		}
+		properties[t.body] = schema
+		if isRequired, _ := body["required"].(bool); isRequired {
+			required = append(required, t.body)
+		}
+	}
+
+	t.schema = map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		t.schema["required"] = required
+	}
+	return t, nil
+}
+
+// withDescription returns a copy of schema with description set.
+func withDescription(schema map[string]interface{}, description string) map[string]interface{} {
+	copied := make(map[string]interface{}, len(schema)+1)
+	for key, value := range schema {
+		copied[key] = value
+	}
+	copied["description"] = description
+	return copied
+}
+
+func (t *operationTool) Name() string                        { return t.name }
+func (t *operationTool) Description() string                 { return t.description }
+func (t *operationTool) InputSchema() map[string]interface{} { return t.schema }
+
+// Execute sends the operation's request built from input and returns the
+// response body.
+func (t *operationTool) Execute(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	req, err := t.request(ctx, input)
+	if err != nil {
+		return types.NewErrorMcpToolResult(err.Error()), nil
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return types.NewErrorMcpToolResult(fmt.Sprintf("request failed: %v", err)), nil
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, t.maxBytes+1))
+	if err != nil {
+		return types.NewErrorMcpToolResult(fmt.Sprintf("read response: %v", err)), nil
+	}
+	text := string(data)
+	if int64(len(data)) > t.maxBytes {
+		text = fmt.Sprintf("%s\n[response truncated at %d bytes]", data[:t.maxBytes], t.maxBytes)
+	}
+	if resp.StatusCode >= 400 {
+		return types.NewErrorMcpToolResult(fmt.Sprintf("HTTP %s\n%s", resp.Status, text)), nil
+	}
+	if text == "" {
+		text = "HTTP " + resp.Status
+	}
+	return types.NewMcpToolResult(types.TextBlock{Type: "text", Text: text}), nil
+}
+
+// request builds the HTTP request for input.
+func (t *operationTool) request(ctx context.Context, input map[string]interface{}) (*http.Request, error) {
+	path := t.path
+	query := url.Values{}
+	header := http.Header{}
+	for _, param := range t.params {
+		value, ok := input[param.property]
+		if !ok || value == nil {
+			if param.in == "path" {
+				return nil, fmt.Errorf("missing path parameter %s", param.name)
+			}
+			continue
+		}
+		values := stringValues(value)
+		switch param.in {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+param.name+"}", url.PathEscape(strings.Join(values, ",")))
+		case "query":
+			for _, v := range values {
+				query.Add(param.name, v)
+			}
+		case "header":
+			header.Set(param.name, strings.Join(values, ","))
+		}
+	}
+
+	var body io.Reader
+	if value, ok := input[t.body]; ok && t.body != "" {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("encode request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	target := t.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, t.method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if t.auth != nil {
+		if err := t.auth(req); err != nil {
+			return nil, fmt.Errorf("authenticate request: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// stringValues formats a parameter value for a URL or header. Arrays
+// become one value per element.
+func stringValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, element := range v {
+			values = append(values, stringValues(element)...)
+		}
+		return values
+	case float64, bool, int, int64:
+		return []string{fmt.Sprint(v)}
+	default:
+		data, _ := json.Marshal(v)
+		return []string{string(data)}
+	}
+}
+
+// resolver resolves local $ref pointers in the document.
+type resolver struct {
+	doc map[string]interface{}
+}
+
+// resolve follows v's $ref, if any, and returns the referenced value.
+func (r *resolver) resolve(v interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return v
+		}
+		v = r.lookup(ref)
+	}
+	return nil
+}
+
+// lookup returns the value at a local JSON pointer such as
+// #/components/schemas/Pet, or nil.
+func (r *resolver) lookup(ref string) interface{} {
+	pointer, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil
+	}
+	var current interface{} = r.doc
+	for _, token := range strings.Split(pointer, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[token]
+	}
+	return current
+}
+
+// schema returns a copy of v with every $ref inlined. A reference to a
+// schema that is already being inlined, as in recursive types, becomes an
+// unconstrained schema.
+func (r *resolver) schema(v interface{}) interface{} {
+	return r.inline(v, map[string]bool{})
+}
+
+func (r *resolver) inline(v interface{}, active map[string]bool) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := value["$ref"].(string); ok {
+			if active[ref] {
+				return map[string]interface{}{}
+			}
+			active[ref] = true
+			defer delete(active, ref)
+			return r.inline(r.lookup(ref), active)
+		}
+		copied := make(map[string]interface{}, len(value))
+		for key, element := range value {
+			copied[key] = r.inline(element, active)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, element := range value {
+			copied[i] = r.inline(element, active)
+		}
+		return copied
+	default:
+		return v
+	}
+}
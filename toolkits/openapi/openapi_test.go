@@ -0,0 +1,196 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+const petstore = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Petstore", "version": "2.1.0"},
+  "servers": [{"url": "https://petstore.example.com/v1"}],
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "summary": "List pets",
+        "tags": ["pets"],
+        "parameters": [
+          {"name": "limit", "in": "query", "description": "Maximum number of pets", "schema": {"type": "integer"}},
+          {"name": "tag", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}}
+        ]
+      },
+      "post": {
+        "operationId": "createPet",
+        "summary": "Create a pet",
+        "tags": ["pets"],
+        "requestBody": {"$ref": "#/components/requestBodies/NewPet"}
+      }
+    },
+    "/pets/{petId}": {
+      "parameters": [{"$ref": "#/components/parameters/PetId"}],
+      "get": {"operationId": "showPetById", "summary": "Show a pet"},
+      "delete": {"summary": "Delete a pet", "tags": ["admin"]}
+    },
+    "/legacy": {
+      "get": {"operationId": "legacy", "deprecated": true}
+    }
+  },
+  "components": {
+    "parameters": {
+      "PetId": {"name": "petId", "in": "path", "required": true, "schema": {"type": "string"}}
+    },
+    "requestBodies": {
+      "NewPet": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+    },
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "name": {"type": "string"},
+          "parent": {"$ref": "#/components/schemas/Pet"}
+        }
+      }
+    }
+  }
+}`
+
+// tools returns the tools of New keyed by name.
+func tools(t *testing.T, opts *Options) map[string]types.McpTool {
+	t.Helper()
+	list, err := New([]byte(petstore), opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	byName := make(map[string]types.McpTool, len(list))
+	for _, tool := range list {
+		byName[tool.Name()] = tool
+	}
+	return byName
+}
+
+// TestNewSchemas tests the tools and input schemas derived from operations.
+func TestNewSchemas(t *testing.T) {
+	byName := tools(t, nil)
+	for _, name := range []string{"listPets", "createPet", "showPetById", "delete_pets_petId"} {
+		if byName[name] == nil {
+			t.Errorf("expected tool %s, got %v", name, byName)
+		}
+	}
+	if byName["legacy"] != nil {
+		t.Error("expected deprecated operations to be skipped")
+	}
+
+	list := byName["listPets"]
+	if !strings.HasPrefix(list.Description(), "GET /pets\n\nList pets") {
+		t.Errorf("unexpected description: %q", list.Description())
+	}
+	limit := list.InputSchema()["properties"].(map[string]interface{})["limit"].(map[string]interface{})
+	if limit["type"] != "integer" || limit["description"] != "Maximum number of pets" {
+		t.Errorf("unexpected limit schema: %v", limit)
+	}
+
+	show := byName["showPetById"].InputSchema()
+	if required := show["required"].([]string); len(required) != 1 || required[0] != "petId" {
+		t.Errorf("expected the shared path parameter to be required, got %v", show["required"])
+	}
+
+	create := byName["createPet"].InputSchema()
+	body := create["properties"].(map[string]interface{})["body"].(map[string]interface{})
+	parent := body["properties"].(map[string]interface{})["parent"].(map[string]interface{})
+	if body["type"] != "object" || len(parent) != 0 {
+		t.Errorf("expected the body schema inlined with the recursive reference cut, got %v", body)
+	}
+	if _, err := json.Marshal(create); err != nil {
+		t.Errorf("schema does not encode: %v", err)
+	}
+}
+
+// TestNewFilters tests operation filters and document validation.
+func TestNewFilters(t *testing.T) {
+	if byName := tools(t, &Options{Filter: ReadOnly}); len(byName) != 3 || byName["createPet"] != nil {
+		t.Errorf("expected only GET operations, got %v", byName)
+	}
+	if byName := tools(t, &Options{Filter: Tags("admin")}); len(byName) != 1 || byName["delete_pets_petId"] == nil {
+		t.Errorf("expected only admin operations, got %v", byName)
+	}
+
+	if _, err := New([]byte(`{"swagger": "2.0"}`), nil); err == nil {
+		t.Error("expected error for a Swagger 2.0 document")
+	}
+	if _, err := New([]byte(`{"openapi": "3.1.0", "paths": {}}`), nil); err == nil {
+		t.Error("expected error for a document without a server URL")
+	}
+
+	server, err := NewServer("petstore", []byte(petstore), nil)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	if server.Version != "2.1.0" || len(server.Tools()) != 4 {
+		t.Errorf("unexpected server: version %s, %d tools", server.Version, len(server.Tools()))
+	}
+}
+
+// TestExecute tests that calls send the operation's request and return the
+// response.
+func TestExecute(t *testing.T) {
+	var got *http.Request
+	var gotBody string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		data, _ := io.ReadAll(r.Body)
+		gotBody = string(data)
+		if r.URL.Path == "/v1/pets/missing" {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"name":"Rex"}]`))
+	}))
+	defer api.Close()
+
+	byName := tools(t, &Options{BaseURL: api.URL + "/v1/", Auth: BearerToken("secret")})
+	ctx := context.Background()
+
+	result, err := byName["listPets"].Execute(ctx, map[string]interface{}{"limit": 2.0, "tag": []interface{}{"dog", "cat"}})
+	if err != nil || result.IsError {
+		t.Fatalf("Execute failed: %v %+v", err, result)
+	}
+	if text := result.Content[0].(types.TextBlock).Text; text != `[{"name":"Rex"}]` {
+		t.Errorf("unexpected result: %s", text)
+	}
+	if got.Method != http.MethodGet || got.URL.Path != "/v1/pets" || got.URL.RawQuery != "limit=2&tag=dog&tag=cat" {
+		t.Errorf("unexpected request: %s %s", got.Method, got.URL)
+	}
+	if got.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("expected the auth header, got %q", got.Header.Get("Authorization"))
+	}
+
+	if _, err := byName["createPet"].Execute(ctx, map[string]interface{}{"body": map[string]interface{}{"name": "Tom"}}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got.Method != http.MethodPost || gotBody != `{"name":"Tom"}` || got.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected request: %s %q %s", got.Method, gotBody, got.Header.Get("Content-Type"))
+	}
+
+	result, _ = byName["showPetById"].Execute(ctx, map[string]interface{}{"petId": "missing"})
+	if !result.IsError || !strings.Contains(result.Content[0].(types.TextBlock).Text, "HTTP 404") {
+		t.Errorf("expected an error result for a 404, got %+v", result)
+	}
+
+	result, _ = byName["showPetById"].Execute(ctx, map[string]interface{}{"petId": "a/b"})
+	if got.URL.EscapedPath() != "/v1/pets/a%2Fb" {
+		t.Errorf("expected the path parameter to be escaped, got %s", got.URL.EscapedPath())
+	}
+	if result.IsError {
+		t.Errorf("unexpected error result: %+v", result)
+	}
+}
@@ -0,0 +1,543 @@
+// Package sql turns a database/sql handle into read-only tools Claude can
+// use to explore and query a database: describe_schema lists the tables and
+// their columns, and query runs a single read-only statement and returns
+// its rows as JSON.
+//
+// Queries run in a read-only transaction that is always rolled back, which
+// is what keeps them from changing data. Before that, CheckStatement
+// rejects what it can see is not a plain read: a leading keyword that is
+// not allowlisted (SELECT, WITH, EXPLAIN, SHOW and DESCRIBE by default),
+// more than one statement, INSERT, UPDATE, DELETE or MERGE inside a WITH
+// query, EXPLAIN ANALYZE, which runs the statement it explains, and
+// dollar-quoted or backslash-escaped strings, which it cannot split
+// reliably. These checks read the SQL text and cannot see what functions a
+// query calls, so they are not a guarantee on their own: Options.SkipReadOnlyTx
+// removes the only real guard. Results are capped at MaxRows rows. For
+// defense in depth, also connect with a database user that can only read.
+//
+// Import the package under another name, such as sqltools, to use it
+// alongside database/sql.
+//
+// Example:
+//
+//	db, err := sql.Open("postgres", dsn)
+//	...
+//	server, err := sqltools.NewServer("db", db, &sqltools.Options{Dialect: sqltools.Postgres})
+//	opts := types.NewClaudeAgentOptions().
+//	    WithMcpServers(map[string]interface{}{"db": server}).
+//	    WithAutoAllowSdkTools(true)
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Dialect selects how the schema is read.
+type Dialect string
+
+const (
+	// Postgres reads the schema from information_schema.
+	Postgres Dialect = "postgres"
+
+	// MySQL reads the schema from information_schema.
+	MySQL Dialect = "mysql"
+
+	// SQLite reads the schema from sqlite_master.
+	SQLite Dialect = "sqlite"
+)
+
+const (
+	// DefaultMaxRows is the number of rows a query returns when
+	// Options.MaxRows is zero.
+	DefaultMaxRows = 100
+
+	// DefaultTimeout limits each tool call when Options.Timeout is zero.
+	DefaultTimeout = 30 * time.Second
+)
+
+// DefaultAllowedStatements are the leading keywords of the statements the
+// query tool runs when Options.AllowedStatements is empty.
+var DefaultAllowedStatements = []string{"SELECT", "WITH", "EXPLAIN", "SHOW", "DESCRIBE"}
+
+// Options configures the toolkit.
+type Options struct {
+	// Dialect selects how describe_schema reads the schema. Dialects other
+	// than SQLite use the standard information_schema views.
+	Dialect Dialect
+
+	// MaxRows caps the rows a query returns. Defaults to DefaultMaxRows.
+	MaxRows int
+
+	// AllowedStatements lists the leading keywords of the statements the
+	// query tool runs, case-insensitively. Defaults to
+	// DefaultAllowedStatements.
+	AllowedStatements []string
+
+	// Timeout limits each tool call. Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// SkipReadOnlyTx runs queries outside a read-only transaction, for
+	// drivers that do not support one. CheckStatement still applies, but
+	// only the transaction reliably keeps queries from writing, so use it
+	// only with a database user that can only read.
+	SkipReadOnlyTx bool
+}
+
+// New returns the query and describe_schema tools for db.
+func New(db *sql.DB, opts *Options) ([]types.McpTool, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database is required")
+	}
+	tk := newToolkit(db, opts)
+
+	query, err := types.NewTool("query").
+		Description(fmt.Sprintf("Run a single read-only SQL statement (%s) and return the rows as JSON. At most %d rows are returned; add WHERE, ORDER BY and LIMIT clauses to get the rows you need. Call describe_schema first to learn the tables and columns.",
+			strings.Join(tk.allowed, ", "), tk.maxRows)).
+		StringParam("sql", "The SQL statement to run", true).
+		Timeout(tk.timeout).
+		Handler(tk.query).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := types.NewTool("describe_schema").
+		Description("List the database tables with their columns and types. Pass a table name to describe only that table.").
+		StringParam("table", "Only describe this table", false).
+		Timeout(tk.timeout).
+		Handler(tk.describeSchema).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	return []types.McpTool{query, schema}, nil
+}
+
+// NewServer returns an SDK MCP server named name serving the tools of New.
+func NewServer(name string, db *sql.DB, opts *Options) (*types.ToolServerConfig, error) {
+	tools, err := New(db, opts)
+	if err != nil {
+		return nil, err
+	}
+	return types.CreateToolServer(name, "1.0.0", tools), nil
+}
+
+// toolkit holds the configuration shared by the tools.
+type toolkit struct {
+	db       *sql.DB
+	dialect  Dialect
+	maxRows  int
+	allowed  []string
+	timeout  time.Duration
+	readOnly bool
+}
+
+// newToolkit applies the defaults to opts.
+func newToolkit(db *sql.DB, opts *Options) *toolkit {
+	if opts == nil {
+		opts = &Options{}
+	}
+	tk := &toolkit{
+		db:       db,
+		dialect:  opts.Dialect,
+		maxRows:  opts.MaxRows,
+		timeout:  opts.Timeout,
+		readOnly: !opts.SkipReadOnlyTx,
+	}
+	if tk.maxRows <= 0 {
+		tk.maxRows = DefaultMaxRows
+	}
+	if tk.timeout <= 0 {
+		tk.timeout = DefaultTimeout
+	}
+	allowed := opts.AllowedStatements
+	if len(allowed) == 0 {
+		allowed = DefaultAllowedStatements
+	}
+	for _, keyword := range allowed {
+		tk.allowed = append(tk.allowed, strings.ToUpper(keyword))
+	}
+	return tk
+}
+
+// queryResult is the JSON returned by the query tool.
+type queryResult struct {
+	Columns   []string        `json:"columns"`
+	Rows      [][]interface{} `json:"rows"`
+	RowCount  int             `json:"row_count"`
+	Truncated bool            `json:"truncated,omitempty"` // More rows matched than were returned
+}
+
+// query runs the statement in the sql argument.
+func (tk *toolkit) query(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+	statement, err := types.Args(input).GetString("sql")
+	if err != nil {
+		return types.NewErrorMcpToolResult(err.Error()), nil
+	}
+	if err := CheckStatement(statement, tk.allowed); err != nil {
+		return types.NewErrorMcpToolResult(err.Error()), nil
+	}
+
+	result := queryResult{Rows: [][]interface{}{}}
+	err = tk.withTx(ctx, func(q querier) error {
+		rows, err := q.QueryContext(ctx, statement)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		if result.Columns, err = rows.Columns(); err != nil {
+			return err
+		}
+		for rows.Next() {
+			if len(result.Rows) == tk.maxRows {
+				result.Truncated = true
+				break
+			}
+			values := make([]interface{}, len(result.Columns))
+			pointers := make([]interface{}, len(values))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+			if err := rows.Scan(pointers...); err != nil {
+				return err
+			}
+			for i, value := range values {
+				if b, ok := value.([]byte); ok && utf8.Valid(b) {
+					values[i] = string(b)
+				}
+			}
+			result.Rows = append(result.Rows, values)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return types.NewErrorMcpToolResult(fmt.Sprintf("Query failed: %v", err)), nil
+	}
+	result.RowCount = len(result.Rows)
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode query result: %w", err)
+	}
+	return types.NewMcpToolResult(types.TextBlock{Type: "text", Text: string(data)}), nil
+}
+
+// column describes a table column.
+type column struct {
+	table    string
+	name     string
+	dataType string
+	notNull  bool
+}
+
+// describeSchema lists the tables and their columns.
+func (tk *toolkit) describeSchema(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+	args := types.Args(input)
+	var table string
+	if args.Has("table") {
+		var err error
+		if table, err = args.GetString("table"); err != nil {
+			return types.NewErrorMcpToolResult(err.Error()), nil
+		}
+	}
+
+	var columns []column
+	err := tk.withTx(ctx, func(q querier) error {
+		var err error
+		if tk.dialect == SQLite {
+			columns, err = sqliteColumns(ctx, q)
+		} else {
+			columns, err = informationSchemaColumns(ctx, q)
+		}
+		return err
+	})
+	if err != nil {
+		return types.NewErrorMcpToolResult(fmt.Sprintf("Failed to read the schema: %v", err)), nil
+	}
+
+	var b strings.Builder
+	current := ""
+	for _, col := range columns {
+		if table != "" && !strings.EqualFold(col.table, table) {
+			continue
+		}
+		if col.table != current {
+			if current != "" {
+				b.WriteString("\n")
+			}
+			current = col.table
+			b.WriteString(col.table + "\n")
+		}
+		fmt.Fprintf(&b, "  %s %s", col.name, col.dataType)
+		if col.notNull {
+			b.WriteString(" NOT NULL")
+		}
+		b.WriteString("\n")
+	}
+	if current == "" {
+		if table != "" {
+			return types.NewErrorMcpToolResult(fmt.Sprintf("Table %s not found", table)), nil
+		}
+		return types.NewMcpToolResult(types.TextBlock{Type: "text", Text: "The database has no tables."}), nil
+	}
+	return types.NewMcpToolResult(types.TextBlock{Type: "text", Text: b.String()}), nil
+}
+
+// informationSchemaColumns reads the columns of the user tables from
+// information_schema.
+func informationSchemaColumns(ctx context.Context, q querier) ([]column, error) {
+	rows, err := q.QueryContext(ctx, `SELECT table_name, column_name, data_type, is_nullable
+FROM information_schema.columns
+WHERE table_schema NOT IN ('pg_catalog', 'information_schema', 'mysql', 'performance_schema', 'sys')
+ORDER BY table_schema, table_name, ordinal_position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []column
+	for rows.Next() {
+		var col column
+		var nullable string
+		if err := rows.Scan(&col.table, &col.name, &col.dataType, &nullable); err != nil {
+			return nil, err
+		}
+		col.notNull = strings.EqualFold(nullable, "NO")
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+// sqliteColumns reads the columns of the tables and views from sqlite_master.
+func sqliteColumns(ctx context.Context, q querier) ([]column, error) {
+	rows, err := q.QueryContext(ctx, `SELECT m.name, p.name, p.type, p."notnull"
+FROM sqlite_master AS m JOIN pragma_table_info(m.name) AS p
+WHERE m.type IN ('table', 'view') AND m.name NOT LIKE 'sqlite_%'
+ORDER BY m.name, p.cid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []column
+	for rows.Next() {
+		var col column
+		var notNull int
+		if err := rows.Scan(&col.table, &col.name, &col.dataType, &notNull); err != nil {
+			return nil, err
+		}
+		col.notNull = notNull != 0
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+// querier runs queries on a database or transaction.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// withTx runs fn in a read-only transaction that is rolled back afterwards,
+// or directly on the database when read-only transactions are skipped.
+func (tk *toolkit) withTx(ctx context.Context, fn func(q querier) error) error {
+	if !tk.readOnly {
+		return fn(tk.db)
+	}
+	tx, err := tk.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("begin read-only transaction: %w", err)
+	}
+	defer tx.Rollback()
+	return fn(tx)
+}
+
+// dataModifyingKeywords are the statements a WITH query may run in a
+// data-modifying common table expression.
+var dataModifyingKeywords = []string{"INSERT", "UPDATE", "DELETE", "MERGE"}
+
+// CheckStatement returns an error unless query is a single statement whose
+// leading keyword is in allowed. Comments and a trailing semicolon are
+// ignored. WITH queries with a data-modifying common table expression,
+// EXPLAIN ANALYZE and strings that are dollar-quoted or contain a
+// backslash are rejected.
+func CheckStatement(query string, allowed []string) error {
+	if err := checkQuoting(query); err != nil {
+		return err
+	}
+	body, rest := splitStatement(query)
+	if strings.TrimSpace(stripComments(rest)) != "" {
+		return fmt.Errorf("only one SQL statement may be run at a time")
+	}
+
+	body = strings.TrimSpace(stripComments(body))
+	end := strings.IndexFunc(body, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end < 0 {
+		end = len(body)
+	}
+	keyword := strings.ToUpper(body[:end])
+	if keyword == "" {
+		return fmt.Errorf("the SQL statement is empty")
+	}
+	allowedKeyword := false
+	for _, allow := range allowed {
+		allowedKeyword = allowedKeyword || strings.EqualFold(keyword, allow)
+	}
+	if !allowedKeyword {
+		return fmt.Errorf("%s statements are not allowed; only %s statements can be run", keyword, strings.Join(allowed, ", "))
+	}
+
+	words := unquotedWords(body)
+	switch keyword {
+	case "WITH":
+		for _, modifying := range dataModifyingKeywords {
+			if slices.Contains(words, modifying) {
+				return fmt.Errorf("WITH queries may not contain %s statements", modifying)
+			}
+		}
+	case "EXPLAIN":
+		if slices.Contains(words, "ANALYZE") || slices.Contains(words, "ANALYSE") {
+			return fmt.Errorf("EXPLAIN ANALYZE is not allowed, since it runs the statement")
+		}
+	}
+	return nil
+}
+
+// checkQuoting returns an error for the string syntax splitStatement cannot
+// follow in every dialect: PostgreSQL dollar quotes such as $$...$$ or
+// $tag$...$tag$, and backslashes in quoted strings, which are escapes in
+// MySQL but not in standard SQL.
+func checkQuoting(query string) error {
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; c {
+		case '\'', '"', '`':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				return nil
+			}
+			if strings.ContainsRune(query[i+1:i+1+end], '\\') {
+				return fmt.Errorf("quoted strings may not contain backslashes")
+			}
+			i += end + 1
+		case '$':
+			j := i + 1
+			for j < len(query) && (query[j] == '_' || unicode.IsLetter(rune(query[j])) || j > i+1 && unicode.IsDigit(rune(query[j]))) {
+				j++
+			}
+			if j < len(query) && query[j] == '$' {
+				return fmt.Errorf("dollar-quoted strings are not allowed")
+			}
+		}
+	}
+	return nil
+}
+
+// unquotedWords returns the words of a statement without comments that are
+// outside quotes and identifiers, in upper case.
+func unquotedWords(statement string) []string {
+	var words []string
+	start := -1
+	for i := 0; i <= len(statement); i++ {
+		var c byte
+		if i < len(statement) {
+			c = statement[i]
+		}
+		if c == '_' || c < utf8.RuneSelf && (unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			words = append(words, strings.ToUpper(statement[start:i]))
+			start = -1
+		}
+		if c == '\'' || c == '"' || c == '`' {
+			end := strings.IndexByte(statement[i+1:], c)
+			if end < 0 {
+				break
+			}
+			i += end + 1
+		}
+	}
+	return words
+}
+
+// splitStatement splits query at the first semicolon outside quotes and
+// comments, returning the statement before it and the text after it.
+func splitStatement(query string) (statement, rest string) {
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; c {
+		case '\'', '"', '`':
+			if end := strings.IndexByte(query[i+1:], c); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(query)
+			}
+		case '-':
+			if strings.HasPrefix(query[i:], "--") {
+				if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+					i += end
+				} else {
+					i = len(query)
+				}
+			}
+		case '/':
+			if strings.HasPrefix(query[i:], "/*") {
+				if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+					i += end + 3
+				} else {
+					i = len(query)
+				}
+			}
+		case ';':
+			return query[:i], query[i+1:]
+		}
+	}
+	return query, ""
+}
+
+// stripComments removes SQL comments outside quotes.
+func stripComments(query string) string {
+	var b strings.Builder
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+end+2])
+			i += end + 1
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return b.String()
+			}
+			i += end - 1
+			b.WriteByte(' ')
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 3
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
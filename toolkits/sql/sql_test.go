@@ -0,0 +1,231 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// fakeDriver is a database/sql driver that answers queries from a table of
+// canned results and records the queries and transactions it sees.
+type fakeDriver struct {
+	mu       sync.Mutex
+	queries  []string
+	readOnly []bool
+	results  map[string]fakeRows
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return c, nil }
+func (c *fakeConn) Commit() error                             { return nil }
+func (c *fakeConn) Rollback() error                           { return nil }
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.readOnly = append(c.d.readOnly, opts.ReadOnly)
+	return c, nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.queries = append(c.d.queries, query)
+	for prefix, result := range c.d.results {
+		if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(query)), strings.ToUpper(prefix)) {
+			return &fakeRowsIter{fakeRows: result}, nil
+		}
+	}
+	return nil, errors.New("no such table")
+}
+
+type fakeRowsIter struct {
+	fakeRows
+	next int
+}
+
+func (r *fakeRowsIter) Columns() []string { return r.columns }
+func (r *fakeRowsIter) Close() error      { return nil }
+
+func (r *fakeRowsIter) Next(dest []driver.Value) error {
+	if r.next == len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+var registerOnce sync.Once
+var testDriver = &fakeDriver{}
+
+// openTestDB returns a database backed by the fake driver.
+func openTestDB(t *testing.T, results map[string]fakeRows) (*sql.DB, *fakeDriver) {
+	t.Helper()
+	registerOnce.Do(func() { sql.Register("sqltoolkit-fake", testDriver) })
+	testDriver.mu.Lock()
+	testDriver.queries, testDriver.readOnly, testDriver.results = nil, nil, results
+	testDriver.mu.Unlock()
+
+	db, err := sql.Open("sqltoolkit-fake", "")
+	if err != nil {
+		t.Fatalf("sql.Open() error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, testDriver
+}
+
+// callTool runs the named tool and returns its text and error flag.
+func callTool(t *testing.T, tools []types.McpTool, name string, input map[string]interface{}) (string, bool) {
+	t.Helper()
+	for _, tool := range tools {
+		if tool.Name() == name {
+			result, err := tool.Execute(context.Background(), input)
+			if err != nil {
+				t.Fatalf("%s: Execute() error: %v", name, err)
+			}
+			return result.Content[0].(types.TextBlock).Text, result.IsError
+		}
+	}
+	t.Fatalf("tool %s not found", name)
+	return "", false
+}
+
+// TestQueryTool tests running queries, row limits and statement checks.
+func TestQueryTool(t *testing.T) {
+	db, d := openTestDB(t, map[string]fakeRows{
+		"SELECT": {
+			columns: []string{"id", "name"},
+			rows:    [][]driver.Value{{int64(1), []byte("alice")}, {int64(2), []byte("bob")}, {int64(3), []byte("carol")}},
+		},
+	})
+	tools, err := New(db, &Options{MaxRows: 2})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	text, isError := callTool(t, tools, "query", map[string]interface{}{"sql": "select id, name from users;"})
+	if isError {
+		t.Fatalf("unexpected error: %s", text)
+	}
+	var result queryResult
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", text, err)
+	}
+	if result.RowCount != 2 || !result.Truncated || result.Rows[1][1] != "bob" || result.Columns[0] != "id" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(d.readOnly) != 1 || !d.readOnly[0] {
+		t.Errorf("expected a read-only transaction, got %v", d.readOnly)
+	}
+
+	for _, statement := range []string{
+		"DELETE FROM users",
+		"SELECT 1; DROP TABLE users",
+		"/* SELECT */ UPDATE users SET name = 'x'",
+		"-- comment only",
+	} {
+		text, isError := callTool(t, tools, "query", map[string]interface{}{"sql": statement})
+		if !isError {
+			t.Errorf("expected %q to be rejected, got %s", statement, text)
+		}
+	}
+	if len(d.queries) != 1 {
+		t.Errorf("expected rejected statements not to run, got %v", d.queries)
+	}
+
+	if text, isError := callTool(t, tools, "query", map[string]interface{}{"sql": "SHOW TABLES"}); !isError || !strings.Contains(text, "no such table") {
+		t.Errorf("expected the driver error, got %s", text)
+	}
+}
+
+// TestCheckStatement tests the statement allowlist.
+func TestCheckStatement(t *testing.T) {
+	allowed := DefaultAllowedStatements
+	valid := []string{
+		"SELECT 1",
+		"  -- leading comment\n  select * from t;",
+		"WITH x AS (SELECT 1) SELECT * FROM x",
+		"SELECT ';' AS semicolon, 'a--b' FROM t -- trailing; comment",
+		"/* header */ EXPLAIN SELECT 1; /* trailing */",
+		"WITH x AS (SELECT 'DELETE' AS action, \"update\" FROM t) SELECT * FROM x",
+		"SELECT * FROM t WHERE id = $1",
+	}
+	for _, query := range valid {
+		if err := CheckStatement(query, allowed); err != nil {
+			t.Errorf("CheckStatement(%q) error: %v", query, err)
+		}
+	}
+	invalid := []string{
+		"",
+		"INSERT INTO t VALUES (1)",
+		"SELECT 1; SELECT 2",
+		"SELECT ';'; DELETE FROM t",
+		"select/**/1; drop table t",
+		"WITH gone AS (DELETE FROM t RETURNING *) SELECT * FROM gone",
+		"with x as (update t set a = 1 returning a) select * from x",
+		"EXPLAIN ANALYZE DELETE FROM t",
+		"EXPLAIN (ANALYZE, BUFFERS) DELETE FROM t",
+		"SELECT $$; DELETE FROM t; $$",
+		"SELECT $tag$; DELETE FROM t; $tag$",
+		`SELECT '\''; DELETE FROM t; --'`,
+	}
+	for _, query := range invalid {
+		if err := CheckStatement(query, allowed); err == nil {
+			t.Errorf("expected CheckStatement(%q) to fail", query)
+		}
+	}
+}
+
+// TestDescribeSchemaTool tests describing the schema from information_schema.
+func TestDescribeSchemaTool(t *testing.T) {
+	db, d := openTestDB(t, map[string]fakeRows{
+		"SELECT table_name": {
+			columns: []string{"table_name", "column_name", "data_type", "is_nullable"},
+			rows: [][]driver.Value{
+				{"orders", "id", "integer", "NO"},
+				{"orders", "total", "numeric", "YES"},
+				{"users", "id", "integer", "NO"},
+			},
+		},
+	})
+	server, err := NewServer("db", db, &Options{Dialect: Postgres, SkipReadOnlyTx: true})
+	if err != nil {
+		t.Fatalf("NewServer() error: %v", err)
+	}
+	tools := server.Tools()
+
+	text, _ := callTool(t, tools, "describe_schema", map[string]interface{}{})
+	if want := "orders\n  id integer NOT NULL\n  total numeric\n\nusers\n  id integer NOT NULL\n"; text != want {
+		t.Errorf("got %q, want %q", text, want)
+	}
+	if len(d.readOnly) != 0 {
+		t.Errorf("expected no transaction, got %v", d.readOnly)
+	}
+
+	text, _ = callTool(t, tools, "describe_schema", map[string]interface{}{"table": "USERS"})
+	if text != "users\n  id integer NOT NULL\n" {
+		t.Errorf("unexpected table description %q", text)
+	}
+	if text, isError := callTool(t, tools, "describe_schema", map[string]interface{}{"table": "missing"}); !isError {
+		t.Errorf("expected an error for a missing table, got %q", text)
+	}
+}
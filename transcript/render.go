@@ -0,0 +1,337 @@
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// turn is a run of consecutive content from one speaker.
+type turn struct {
+	Role    string // User, Assistant or Subagent; empty for a result summary
+	Entries []entry
+}
+
+// entry is one item of a turn.
+type entry struct {
+	Kind string // text, thinking, tool, attachment, error or summary
+	Text string
+	Tool *toolCall
+}
+
+// toolCall is a tool use together with its result.
+type toolCall struct {
+	Name      string
+	Input     string // Indented JSON
+	Result    string
+	HasResult bool
+	IsError   bool
+}
+
+// turns converts the transcript to the form the renderers share.
+func (t *Transcript) turns() []turn {
+	messages := t.Messages()
+
+	results := make(map[string]types.ContentBlock)
+	for _, msg := range messages {
+		var blocks []types.ContentBlock
+		switch m := msg.(type) {
+		case *types.UserMessage:
+			blocks, _ = m.Content.([]types.ContentBlock)
+		case *types.AssistantMessage:
+			blocks = m.Content
+		}
+		for _, block := range blocks {
+			switch b := block.(type) {
+			case *types.ToolResultBlock:
+				results[b.ToolUseID] = b
+			case *types.ServerToolResultBlock:
+				results[b.ToolUseID] = b
+			}
+		}
+	}
+
+	var turns []turn
+	add := func(role string, entries ...entry) {
+		if len(entries) == 0 {
+			return
+		}
+		if role != "" && len(turns) > 0 && turns[len(turns)-1].Role == role {
+			turns[len(turns)-1].Entries = append(turns[len(turns)-1].Entries, entries...)
+			return
+		}
+		turns = append(turns, turn{Role: role, Entries: entries})
+	}
+
+	for _, msg := range messages {
+		switch m := msg.(type) {
+		case *types.UserMessage:
+			add("User", userEntries(m)...)
+		case *types.AssistantMessage:
+			role := "Assistant"
+			if m.ParentToolUseID != nil {
+				role = "Subagent"
+			}
+			add(role, t.assistantEntries(m, results)...)
+		case *types.ResultMessage:
+			add("", entry{Kind: "summary", Text: summary(m)})
+		}
+	}
+	return turns
+}
+
+// userEntries returns the entries of a user message. Tool results are left
+// out; they are shown with their tool calls.
+func userEntries(m *types.UserMessage) []entry {
+	if text, ok := m.Content.(string); ok {
+		return []entry{{Kind: "text", Text: text}}
+	}
+	blocks, _ := m.Content.([]types.ContentBlock)
+	var entries []entry
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *types.TextBlock:
+			entries = append(entries, entry{Kind: "text", Text: b.Text})
+		case *types.ToolResultBlock:
+		default:
+			entries = append(entries, entry{Kind: "attachment", Text: attachment(block)})
+		}
+	}
+	return entries
+}
+
+// assistantEntries returns the entries of an assistant message.
+func (t *Transcript) assistantEntries(m *types.AssistantMessage, results map[string]types.ContentBlock) []entry {
+	var entries []entry
+	for _, block := range m.Content {
+		switch b := block.(type) {
+		case *types.TextBlock:
+			entries = append(entries, entry{Kind: "text", Text: b.Text})
+		case *types.ThinkingBlock:
+			if t.ShowThinking {
+				entries = append(entries, entry{Kind: "thinking", Text: b.Thinking})
+			}
+		case *types.RedactedThinkingBlock:
+			if t.ShowThinking {
+				entries = append(entries, entry{Kind: "attachment", Text: "[redacted thinking]"})
+			}
+		case *types.ToolUseBlock:
+			entries = append(entries, entry{Kind: "tool", Tool: newToolCall(b.ID, b.Name, b.Input, results)})
+		case *types.ServerToolUseBlock:
+			entries = append(entries, entry{Kind: "tool", Tool: newToolCall(b.ID, b.Name, b.Input, results)})
+		}
+	}
+	if m.Error != nil {
+		entries = append(entries, entry{Kind: "error", Text: "Error: " + string(*m.Error)})
+	}
+	return entries
+}
+
+// newToolCall returns the call of tool name with its result, if any.
+func newToolCall(id, name string, input map[string]interface{}, results map[string]types.ContentBlock) *toolCall {
+	call := &toolCall{Name: name, Input: indentJSON(input)}
+	switch result := results[id].(type) {
+	case *types.ToolResultBlock:
+		call.HasResult = true
+		call.Result = resultText(result)
+		call.IsError = result.IsError != nil && *result.IsError
+	case *types.ServerToolResultBlock:
+		call.HasResult = true
+		call.Result = serverResultText(result)
+		_, failed := result.ErrorCode()
+		call.IsError = failed || (result.IsError != nil && *result.IsError)
+	}
+	return call
+}
+
+// serverResultText returns a readable form of a server tool result.
+func serverResultText(result *types.ServerToolResultBlock) string {
+	if pages, ok := result.WebSearchResults(); ok {
+		lines := make([]string, len(pages))
+		for i, page := range pages {
+			lines[i] = fmt.Sprintf("%s <%s>", page.Title, page.URL)
+		}
+		return strings.Join(lines, "\n")
+	}
+	if execution, ok := result.CodeExecutionResult(); ok {
+		text := execution.Stdout + execution.Stderr
+		if execution.ReturnCode != 0 {
+			text += fmt.Sprintf("\n(exit code %d)", execution.ReturnCode)
+		}
+		return text
+	}
+	if code, ok := result.ErrorCode(); ok {
+		return "Error: " + code
+	}
+	return indentJSON(result.Content)
+}
+
+// attachment describes a content block other than text.
+func attachment(block types.ContentBlock) string {
+	if doc, ok := block.(*types.DocumentBlock); ok && doc.Title != "" {
+		return fmt.Sprintf("[document: %s]", doc.Title)
+	}
+	return fmt.Sprintf("[%s]", block.GetType())
+}
+
+// resultText returns the text of a tool result, describing blocks other
+// than text.
+func resultText(result *types.ToolResultBlock) string {
+	blocks, ok := result.AsBlocks()
+	if !ok {
+		return ""
+	}
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if text, ok := block.(*types.TextBlock); ok {
+			parts = append(parts, text.Text)
+		} else {
+			parts = append(parts, attachment(block))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// indentJSON returns v as indented JSON.
+func indentJSON(v interface{}) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// summary describes the outcome and cost of a query.
+func summary(m *types.ResultMessage) string {
+	parts := []string{"Completed"}
+	if m.IsError {
+		parts[0] = fmt.Sprintf("Failed (%s)", m.Subtype)
+	}
+	if m.NumTurns > 0 {
+		parts[0] += fmt.Sprintf(" in %d turns", m.NumTurns)
+	}
+	if m.DurationMs > 0 {
+		parts = append(parts, (time.Duration(m.DurationMs) * time.Millisecond).Round(100*time.Millisecond).String())
+	}
+	if m.TotalCostUSD != nil {
+		parts = append(parts, fmt.Sprintf("$%.4f", *m.TotalCostUSD))
+	}
+	input, hasInput := m.Usage["input_tokens"].(float64)
+	output, hasOutput := m.Usage["output_tokens"].(float64)
+	if hasInput || hasOutput {
+		parts = append(parts, fmt.Sprintf("%d input / %d output tokens", int64(input), int64(output)))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// WriteMarkdown writes the transcript as Markdown. Tool calls are collapsed
+// into <details> sections, which GitHub and most Markdown viewers render.
+func (t *Transcript) WriteMarkdown(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if t.Title != "" {
+		fmt.Fprintf(bw, "# %s\n\n", t.Title)
+	}
+	for _, turn := range t.turns() {
+		if turn.Role != "" {
+			fmt.Fprintf(bw, "### %s\n\n", turn.Role)
+		}
+		for _, e := range turn.Entries {
+			switch e.Kind {
+			case "text":
+				fmt.Fprintf(bw, "%s\n\n", e.Text)
+			case "thinking":
+				fmt.Fprintf(bw, "<details>\n<summary>Thinking</summary>\n\n%s\n\n</details>\n\n", e.Text)
+			case "tool":
+				label := "Tool: " + e.Tool.Name
+				if e.Tool.IsError {
+					label += " (error)"
+				}
+				fmt.Fprintf(bw, "<details>\n<summary>%s</summary>\n\n", template.HTMLEscapeString(label))
+				fmt.Fprintf(bw, "**Input**\n\n%s\n\n", codeBlock(e.Tool.Input, "json"))
+				if e.Tool.HasResult {
+					fmt.Fprintf(bw, "**Result**\n\n%s\n\n", codeBlock(e.Tool.Result, ""))
+				}
+				fmt.Fprint(bw, "</details>\n\n")
+			case "attachment", "error":
+				fmt.Fprintf(bw, "*%s*\n\n", e.Text)
+			case "summary":
+				fmt.Fprintf(bw, "---\n\n*%s*\n\n", e.Text)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// codeBlock fences text, using a fence longer than any backtick run in it.
+func codeBlock(text, lang string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + lang + "\n" + strings.TrimSuffix(text, "\n") + "\n" + fence
+}
+
+// WriteHTML writes the transcript as a standalone HTML page with no
+// external resources. Tool calls are collapsed into <details> sections.
+func (t *Transcript) WriteHTML(w io.Writer) error {
+	title := t.Title
+	if title == "" {
+		title = "Transcript"
+	}
+	return htmlTemplate.Execute(w, struct {
+		Title string
+		Turns []turn
+	}{title, t.turns()})
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 860px; margin: 2rem auto; padding: 0 1rem; color: #1f2328; line-height: 1.5; }
+h2 { font-size: 0.85rem; text-transform: uppercase; letter-spacing: 0.05em; color: #59636e; margin: 0 0 0.5rem; }
+.turn { border-left: 3px solid #d1d9e0; padding: 0.25rem 0 0.25rem 1rem; margin: 1.5rem 0; }
+.turn.user { border-color: #0969da; }
+.turn.subagent { border-color: #8250df; }
+.text { white-space: pre-wrap; margin: 0.5rem 0; }
+.note { color: #59636e; font-style: italic; }
+.error { color: #d1242f; }
+details { background: #f6f8fa; border-radius: 6px; padding: 0.4rem 0.75rem; margin: 0.5rem 0; }
+details.failed summary { color: #d1242f; }
+summary { cursor: pointer; font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.9rem; }
+pre { white-space: pre-wrap; word-break: break-word; font-size: 0.85rem; background: #fff; border: 1px solid #d1d9e0; border-radius: 6px; padding: 0.5rem; }
+.summary { border-top: 1px solid #d1d9e0; padding-top: 0.75rem; color: #59636e; font-size: 0.9rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Turns}}{{if .Role}}<section class="turn {{if eq .Role "User"}}user{{else if eq .Role "Subagent"}}subagent{{else}}assistant{{end}}">
+<h2>{{.Role}}</h2>
+{{range .Entries}}{{template "entry" .}}{{end}}</section>
+{{else}}{{range .Entries}}<p class="summary">{{.Text}}</p>
+{{end}}{{end}}{{end}}</body>
+</html>
+{{define "entry"}}{{if eq .Kind "text"}}<div class="text">{{.Text}}</div>
+{{else if eq .Kind "thinking"}}<details><summary>Thinking</summary><div class="text note">{{.Text}}</div></details>
+{{else if eq .Kind "tool"}}<details{{if .Tool.IsError}} class="failed"{{end}}><summary>{{.Tool.Name}}{{if .Tool.IsError}} (error){{end}}</summary>
+<pre>{{.Tool.Input}}</pre>{{if .Tool.HasResult}}
+<pre>{{.Tool.Result}}</pre>{{end}}
+</details>
+{{else if eq .Kind "error"}}<p class="error">{{.Text}}</p>
+{{else}}<p class="note">{{.Text}}</p>
+{{end}}{{end}}`))
@@ -0,0 +1,111 @@
+// Package transcript renders a conversation as a shareable transcript: JSON
+// lines for tooling, Markdown for pull requests and issues, or a standalone
+// HTML page. Tool calls are collapsed together with their results, and the
+// result of each query is annotated with its cost, duration and tokens.
+//
+// Build a transcript from messages already received:
+//
+//	t := transcript.New(messages...)
+//	t.Title = "Refactor session"
+//	err := t.WriteMarkdown(os.Stdout)
+//
+// or record a live client while consuming its messages:
+//
+//	t := transcript.New()
+//	for msg := range t.Record(client.ReceiveResponse(ctx)) {
+//	    // Handle msg as usual
+//	}
+//	err := t.WriteHTML(file)
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Transcript is a recorded conversation. It is safe for concurrent use.
+type Transcript struct {
+	// Title heads the Markdown and HTML renderings. Set it before rendering.
+	Title string
+	// ShowThinking includes Claude's thinking blocks in the Markdown and HTML
+	// renderings. Set it before rendering.
+	ShowThinking bool
+
+	mu       sync.Mutex
+	messages []types.Message
+}
+
+// New creates a transcript holding messages.
+func New(messages ...types.Message) *Transcript {
+	return &Transcript{messages: append([]types.Message(nil), messages...)}
+}
+
+// Add appends a message to the transcript.
+func (t *Transcript) Add(msg types.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = append(t.messages, msg)
+}
+
+// Messages returns a copy of the recorded messages.
+func (t *Transcript) Messages() []types.Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]types.Message(nil), t.messages...)
+}
+
+// Record adds every message received from in to the transcript and passes
+// it on through the returned channel, which is closed when in is.
+func (t *Transcript) Record(in <-chan types.Message) <-chan types.Message {
+	out := make(chan types.Message)
+	go func() {
+		defer close(out)
+		for msg := range in {
+			t.Add(msg)
+			out <- msg
+		}
+	}()
+	return out
+}
+
+// WriteJSONL writes every message, including system messages and stream
+// events, as one JSON object per line. ReadJSONL reads it back.
+func (t *Transcript) WriteJSONL(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for i, msg := range t.Messages() {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("encode message %d: %w", i, err)
+		}
+		if _, err := bw.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadJSONL reads a transcript written by WriteJSONL.
+func ReadJSONL(r io.Reader) (*Transcript, error) {
+	t := New()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		msg, err := types.UnmarshalMessage(scanner.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("transcript line %d: %w", line, err)
+		}
+		t.messages = append(t.messages, msg)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read transcript: %w", err)
+	}
+	return t, nil
+}
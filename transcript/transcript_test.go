@@ -0,0 +1,160 @@
+package transcript
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// conversation returns the messages of a short session with one tool call.
+func conversation(t *testing.T) []types.Message {
+	t.Helper()
+	lines := []string{
+		`{"type":"system","subtype":"init","data":{"model":"claude-sonnet-4-5"}}`,
+		`{"type":"user","message":{"role":"user","content":"List the <src> files"}}`,
+		`{"type":"assistant","message":{"model":"claude-sonnet-4-5","content":[{"type":"thinking","thinking":"Use ls.","signature":"sig"},{"type":"text","text":"Let me check."}]}}`,
+		`{"type":"assistant","message":{"model":"claude-sonnet-4-5","content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"ls src"}}]}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"main.go\nutil.go"}]}}`,
+		`{"type":"assistant","message":{"model":"claude-sonnet-4-5","content":[{"type":"text","text":"There are two files."}]}}`,
+		`{"type":"result","subtype":"success","duration_ms":12345,"duration_api_ms":10000,"is_error":false,"num_turns":2,"session_id":"s","total_cost_usd":0.0123,"usage":{"input_tokens":1200,"output_tokens":85}}`,
+	}
+	var messages []types.Message
+	for _, line := range lines {
+		msg, err := types.UnmarshalMessage([]byte(line))
+		if err != nil {
+			t.Fatalf("UnmarshalMessage failed: %v", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+// TestWriteMarkdown tests rendering a transcript as Markdown.
+func TestWriteMarkdown(t *testing.T) {
+	tr := New(conversation(t)...)
+	tr.Title = "Session"
+
+	var buf bytes.Buffer
+	if err := tr.WriteMarkdown(&buf); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# Session\n",
+		"### User\n\nList the <src> files\n",
+		"### Assistant\n\nLet me check.\n\n<details>\n<summary>Tool: Bash</summary>",
+		"```json\n{\n  \"command\": \"ls src\"\n}\n```",
+		"**Result**\n\n```\nmain.go\nutil.go\n```",
+		"There are two files.",
+		"*Completed in 2 turns · 12.3s · $0.0123 · 1200 input / 85 output tokens*",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "### Assistant") != 1 || strings.Count(out, "### User") != 1 {
+		t.Errorf("expected consecutive messages merged and tool results attached to calls, got:\n%s", out)
+	}
+	if strings.Contains(out, "Use ls.") {
+		t.Error("expected thinking to be hidden by default")
+	}
+
+	tr.ShowThinking = true
+	buf.Reset()
+	if err := tr.WriteMarkdown(&buf); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Use ls.") {
+		t.Error("expected thinking with ShowThinking")
+	}
+}
+
+// TestWriteHTML tests rendering a transcript as an HTML page.
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(conversation(t)...).WriteHTML(&buf); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<title>Transcript</title>",
+		"List the &lt;src&gt; files",
+		"<summary>Bash</summary>",
+		"main.go\nutil.go",
+		`<p class="summary">Completed in 2 turns`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected HTML to contain %q", want)
+		}
+	}
+	if strings.Contains(out, "<src>") {
+		t.Error("expected message text to be escaped")
+	}
+}
+
+// TestJSONLRoundTrip tests writing and reading a transcript as JSON lines.
+func TestJSONLRoundTrip(t *testing.T) {
+	messages := conversation(t)
+	var buf bytes.Buffer
+	if err := New(messages...).WriteJSONL(&buf); err != nil {
+		t.Fatalf("WriteJSONL failed: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != len(messages) {
+		t.Errorf("expected %d lines, got %d", len(messages), lines)
+	}
+
+	read, err := ReadJSONL(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSONL failed: %v", err)
+	}
+	got := read.Messages()
+	if len(got) != len(messages) {
+		t.Fatalf("expected %d messages, got %d", len(messages), len(got))
+	}
+	for i := range messages {
+		if got[i].GetMessageType() != messages[i].GetMessageType() {
+			t.Errorf("message %d: got type %s, want %s", i, got[i].GetMessageType(), messages[i].GetMessageType())
+		}
+	}
+	if tool := got[3].(*types.AssistantMessage).Content[0].(*types.ToolUseBlock); tool.Input["command"] != "ls src" {
+		t.Errorf("unexpected tool use after round trip: %+v", tool)
+	}
+}
+
+// TestRecord tests recording messages passed through a channel.
+func TestRecord(t *testing.T) {
+	in := make(chan types.Message, 2)
+	in <- &types.UserMessage{Type: "user", Content: "hi"}
+	in <- &types.ResultMessage{Type: "result", Subtype: "success"}
+	close(in)
+
+	tr := New()
+	n := 0
+	for range tr.Record(in) {
+		n++
+	}
+	if n != 2 || len(tr.Messages()) != 2 {
+		t.Errorf("expected 2 messages passed through and recorded, got %d and %d", n, len(tr.Messages()))
+	}
+}
+
+// TestServerToolCalls tests rendering tool calls the API runs itself.
+func TestServerToolCalls(t *testing.T) {
+	msg, err := types.UnmarshalMessage([]byte(`{"type":"assistant","message":{"content":[
+		{"type":"server_tool_use","id":"srvtoolu_1","name":"web_search","input":{"query":"go"}},
+		{"type":"web_search_tool_result","tool_use_id":"srvtoolu_1","content":[{"type":"web_search_result","url":"https://go.dev","title":"Go"}]}
+	]}}`))
+	if err != nil {
+		t.Fatalf("UnmarshalMessage failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := New(msg).WriteMarkdown(&buf); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Tool: web_search") || !strings.Contains(out, "Go <https://go.dev>") {
+		t.Errorf("expected the search and its results, got:\n%s", out)
+	}
+}
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"context"
+	"fmt"
+)
+
+// AgentPermissions restricts the tools a subagent may use. The SDK enforces
+// the restrictions itself, whatever the agent's prompt says.
+//
+// Tool rules use the ToolPattern syntax, such as "Bash", "mcp__github__*" or
+// "Bash(go test *)". Disallowed rules win over allowed ones.
+type AgentPermissions struct {
+	// AllowedTools lists the tools the agent may use. Empty allows every tool
+	// not disallowed.
+	AllowedTools []string
+	// DisallowedTools lists the tools the agent may never use.
+	DisallowedTools []string
+	// CanUseTool decides the agent's permission requests instead of the
+	// session's CanUseTool callback, once the tool rules allow the call.
+	CanUseTool CanUseToolFunc
+}
+
+// Validate reports invalid tool rules.
+func (p AgentPermissions) Validate() error {
+	if err := ValidateToolRules(p.AllowedTools); err != nil {
+		return fmt.Errorf("allowed tools: %w", err)
+	}
+	if err := ValidateToolRules(p.DisallowedTools); err != nil {
+		return fmt.Errorf("disallowed tools: %w", err)
+	}
+	return nil
+}
+
+// Check returns why the agent may not use a tool, or an empty string if the
+// tool rules allow it.
+func (p AgentPermissions) Check(agentName, toolName string, input map[string]interface{}) string {
+	for _, rule := range p.DisallowedTools {
+		if matchToolRule(rule, toolName, input) {
+			return fmt.Sprintf("the %s agent may not use %s", agentName, toolName)
+		}
+	}
+	if len(p.AllowedTools) == 0 {
+		return ""
+	}
+	for _, rule := range p.AllowedTools {
+		if matchToolRule(rule, toolName, input) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("the %s agent is not allowed to use %s", agentName, toolName)
+}
+
+// AgentScopedCanUseTool returns a permission callback that applies the
+// AgentPermissions of the subagent named in the request's AgentName. Calls
+// denied by the agent's tool rules never reach a callback; allowed calls go
+// to the agent's CanUseTool, or to next. Requests from the main agent and
+// from agents without permissions go to next. Requests from a subagent
+// whose name is unknown, with a ParentToolUseID but no AgentName, are
+// denied, as are requests whose chosen callback is nil.
+func AgentScopedCanUseTool(agents map[string]AgentPermissions, next CanUseToolFunc) CanUseToolFunc {
+	return func(ctx context.Context, toolName string, input map[string]interface{}, permCtx ToolPermissionContext) (interface{}, error) {
+		if permCtx.ParentToolUseID != nil && permCtx.AgentName == "" {
+			return PermissionResultDeny{Behavior: "deny", Message: fmt.Sprintf("cannot tell which subagent is using %s", toolName), Source: PermissionSourceRule}, nil
+		}
+		callback := next
+		if permissions, ok := agents[permCtx.AgentName]; ok && permCtx.AgentName != "" {
+			if reason := permissions.Check(permCtx.AgentName, toolName, input); reason != "" {
+				return PermissionResultDeny{Behavior: "deny", Message: reason, Source: PermissionSourceRule}, nil
+			}
+			if permissions.CanUseTool != nil {
+				callback = permissions.CanUseTool
+			}
+		}
+		if callback == nil {
+			return PermissionResultDeny{Behavior: "deny", Message: fmt.Sprintf("%s requires permission", toolName)}, nil
+		}
+		return callback(ctx, toolName, input, permCtx)
+	}
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestAgentPermissionsCheck tests allowed and disallowed tool rules.
+func TestAgentPermissionsCheck(t *testing.T) {
+	permissions := AgentPermissions{
+		AllowedTools:    []string{"Read", "Bash(go test *)", "Bash(git *)"},
+		DisallowedTools: []string{"Bash(git push*)"},
+	}
+
+	tests := []struct {
+		toolName string
+		input    map[string]interface{}
+		allowed  bool
+	}{
+		{"Read", map[string]interface{}{"file_path": "main.go"}, true},
+		{"Bash", map[string]interface{}{"command": "go test ./..."}, true},
+		{"Bash", map[string]interface{}{"command": "git status"}, true},
+		{"Bash", map[string]interface{}{"command": "git push origin main"}, false},
+		{"Bash", map[string]interface{}{"command": "rm -rf /"}, false},
+		{"Write", map[string]interface{}{"file_path": "main.go"}, false},
+	}
+	for _, tt := range tests {
+		reason := permissions.Check("tester", tt.toolName, tt.input)
+		if (reason == "") != tt.allowed {
+			t.Errorf("Check(%s, %v) = %q, want allowed=%v", tt.toolName, tt.input, reason, tt.allowed)
+		}
+	}
+
+	if reason := (AgentPermissions{DisallowedTools: []string{"Bash"}}).Check("doc-writer", "Edit", nil); reason != "" {
+		t.Errorf("expected tools not disallowed to be allowed, got %q", reason)
+	}
+	if err := (AgentPermissions{AllowedTools: []string{"Bash("}}).Validate(); err == nil {
+		t.Error("expected invalid rule to fail validation")
+	}
+}
+
+// TestAgentScopedCanUseTool tests routing permission requests by agent name.
+func TestAgentScopedCanUseTool(t *testing.T) {
+	var called string
+	callback := func(name string) CanUseToolFunc {
+		return func(ctx context.Context, toolName string, input map[string]interface{}, permCtx ToolPermissionContext) (interface{}, error) {
+			called = name
+			return PermissionResultAllow{Behavior: "allow"}, nil
+		}
+	}
+	canUseTool := AgentScopedCanUseTool(map[string]AgentPermissions{
+		"tester":     {AllowedTools: []string{"Bash"}, CanUseTool: callback("tester")},
+		"doc-writer": {DisallowedTools: []string{"Bash"}},
+	}, callback("session"))
+	input := map[string]interface{}{"command": "go test ./..."}
+
+	if _, err := canUseTool(context.Background(), "Bash", input, ToolPermissionContext{AgentName: "tester"}); err != nil || called != "tester" {
+		t.Errorf("expected the tester callback, got %q, %v", called, err)
+	}
+
+	called = ""
+	result, _ := canUseTool(context.Background(), "Bash", input, ToolPermissionContext{AgentName: "doc-writer"})
+	deny, ok := result.(PermissionResultDeny)
+	if !ok || called != "" || !strings.Contains(deny.Message, "doc-writer") || deny.Source != PermissionSourceRule {
+		t.Errorf("expected doc-writer Bash to be denied without a callback, got %#v (called %q)", result, called)
+	}
+
+	for _, agent := range []string{"", "reviewer"} {
+		called = ""
+		if _, err := canUseTool(context.Background(), "Bash", input, ToolPermissionContext{AgentName: agent}); err != nil || called != "session" {
+			t.Errorf("expected agent %q to use the session callback, got %q, %v", agent, called, err)
+		}
+	}
+
+	called = ""
+	taskID := "toolu_task"
+	result, _ = canUseTool(context.Background(), "Bash", input, ToolPermissionContext{ParentToolUseID: &taskID})
+	if _, ok := result.(PermissionResultDeny); !ok || called != "" {
+		t.Errorf("expected an unknown subagent to be denied, got %#v (called %q)", result, called)
+	}
+
+	result, _ = AgentScopedCanUseTool(nil, nil)(context.Background(), "Bash", input, ToolPermissionContext{})
+	if _, ok := result.(PermissionResultDeny); !ok {
+		t.Errorf("expected deny without a callback, got %#v", result)
+	}
+}
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorAction is what an application should do about an AssistantErrorEvent.
+type ErrorAction string
+
+const (
+	ErrorActionRetry            ErrorAction = "retry"             // Send the query again after RetryAfter
+	ErrorActionCheckCredentials ErrorAction = "check_credentials" // Log in again or fix the API key
+	ErrorActionCheckBilling     ErrorAction = "check_billing"     // Add credits or raise the spending limit
+	ErrorActionFixRequest       ErrorAction = "fix_request"       // Change the prompt or options
+	ErrorActionGiveUp           ErrorAction = "give_up"           // Report the error; retrying will not help
+)
+
+// AssistantErrorEvent follows an AssistantMessage whose Error is set, such as
+// a rate limit or an authentication failure. The CLI delivers these errors as
+// an ordinary assistant message holding the error text; the event adds what
+// to do about it, as suggested by the session's RetryPolicy. Errors of
+// subagents reach Claude as tool results and have no event.
+//
+// Example:
+//
+//	if e, ok := msg.(*types.AssistantErrorEvent); ok {
+//	    if e.Action == types.ErrorActionRetry {
+//	        time.Sleep(e.RetryAfter)
+//	        err = client.Query(ctx, prompt)
+//	    } else {
+//	        log.Printf("Claude failed: %s (%s)", e.Text, e.Action)
+//	    }
+//	}
+type AssistantErrorEvent struct {
+	Type       string                `json:"type"` // "assistant_error"
+	Error      AssistantMessageError `json:"error"`
+	Text       string                `json:"text"` // The error text delivered as the message content
+	Action     ErrorAction           `json:"action"`
+	RetryAfter time.Duration         `json:"retry_after,omitempty"` // Suggested wait before retrying; 0 unless Action is ErrorActionRetry
+	Attempt    int                   `json:"attempt"`               // Errors in a row in the session, from 1
+	Message    *AssistantMessage     `json:"message"`
+}
+
+// GetMessageType returns the type of the message.
+func (m *AssistantErrorEvent) GetMessageType() string {
+	return m.Type
+}
+
+// ShouldDisplayToUser returns true for assistant errors.
+func (m *AssistantErrorEvent) ShouldDisplayToUser() bool {
+	return true
+}
+
+func (m *AssistantErrorEvent) isMessage() {}
+
+// Retryable reports whether sending the query again may succeed.
+func (m *AssistantErrorEvent) Retryable() bool {
+	return m.Action == ErrorActionRetry
+}
+
+// RetryPolicy decides whether and when to retry after an assistant error.
+// Rate limits and server errors are retried with exponential backoff until
+// MaxAttempts errors in a row; other errors are not.
+type RetryPolicy struct {
+	MaxAttempts  int           // Errors in a row after which to give up
+	InitialDelay time.Duration // Wait after the first error, doubled for each further one
+	MaxDelay     time.Duration // Longest wait
+}
+
+// DefaultRetryPolicy is used when no policy is set with WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  3,
+	InitialDelay: 5 * time.Second,
+	MaxDelay:     time.Minute,
+}
+
+// retryAfterPattern finds a wait in seconds in error texts, such as
+// "retry-after: 30" or "retry after 30 seconds".
+var retryAfterPattern = regexp.MustCompile(`(?i)retry[- ]after:?\s*(\d+)`)
+
+// Advise returns the action for an error with the given code and text, and
+// the wait before retrying, for the attempt-th error in a row. A wait found
+// in the text, such as "retry-after: 30", is honored up to MaxDelay.
+func (p RetryPolicy) Advise(code AssistantMessageError, text string, attempt int) (ErrorAction, time.Duration) {
+	switch code {
+	case AssistantMessageErrorAuthenticationFailed:
+		return ErrorActionCheckCredentials, 0
+	case AssistantMessageErrorBilling:
+		return ErrorActionCheckBilling, 0
+	case AssistantMessageErrorInvalidRequest:
+		return ErrorActionFixRequest, 0
+	case AssistantMessageErrorRateLimit, AssistantMessageErrorServer:
+		// Retried below
+	default:
+		return ErrorActionGiveUp, 0
+	}
+	if attempt > p.MaxAttempts {
+		return ErrorActionGiveUp, 0
+	}
+
+	delay := p.InitialDelay
+	for i := 1; i < attempt && (p.MaxDelay == 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if match := retryAfterPattern.FindStringSubmatch(text); match != nil {
+		if seconds, err := strconv.Atoi(match[1]); err == nil && time.Duration(seconds)*time.Second > delay {
+			delay = time.Duration(seconds) * time.Second
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return ErrorActionRetry, delay
+}
+
+// NewAssistantErrorEvent returns the event for an assistant message whose
+// Error is set, advised by policy for the attempt-th error in a row. It
+// returns nil for a message without error.
+func NewAssistantErrorEvent(msg *AssistantMessage, attempt int, policy RetryPolicy) *AssistantErrorEvent {
+	if msg == nil || msg.Error == nil {
+		return nil
+	}
+	text := strings.TrimSpace(TextOf(msg))
+	action, delay := policy.Advise(*msg.Error, text, attempt)
+	return &AssistantErrorEvent{
+		Type:       "assistant_error",
+		Error:      *msg.Error,
+		Text:       text,
+		Action:     action,
+		RetryAfter: delay,
+		Attempt:    attempt,
+		Message:    msg,
+	}
+}
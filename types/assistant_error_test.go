@@ -0,0 +1,74 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestRetryPolicyAdvise tests actions and backoff for assistant errors
+func TestRetryPolicyAdvise(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Second, MaxDelay: 3 * time.Second}
+	tests := []struct {
+		name       string
+		code       AssistantMessageError
+		text       string
+		attempt    int
+		wantAction ErrorAction
+		wantDelay  time.Duration
+	}{
+		{"rate limit", AssistantMessageErrorRateLimit, "API Error: 429", 1, ErrorActionRetry, time.Second},
+		{"backoff doubles", AssistantMessageErrorServer, "", 2, ErrorActionRetry, 2 * time.Second},
+		{"backoff capped", AssistantMessageErrorServer, "", 3, ErrorActionRetry, 3 * time.Second},
+		{"attempts exhausted", AssistantMessageErrorRateLimit, "", 4, ErrorActionGiveUp, 0},
+		{"retry-after honored", AssistantMessageErrorRateLimit, "rate limited, retry-after: 2", 1, ErrorActionRetry, 2 * time.Second},
+		{"retry-after capped", AssistantMessageErrorRateLimit, "Retry after 90 seconds", 1, ErrorActionRetry, 3 * time.Second},
+		{"authentication", AssistantMessageErrorAuthenticationFailed, "", 1, ErrorActionCheckCredentials, 0},
+		{"billing", AssistantMessageErrorBilling, "", 1, ErrorActionCheckBilling, 0},
+		{"invalid request", AssistantMessageErrorInvalidRequest, "", 1, ErrorActionFixRequest, 0},
+		{"unknown", AssistantMessageErrorUnknown, "", 1, ErrorActionGiveUp, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, delay := policy.Advise(tt.code, tt.text, tt.attempt)
+			if action != tt.wantAction || delay != tt.wantDelay {
+				t.Errorf("Advise() = %s, %v, want %s, %v", action, delay, tt.wantAction, tt.wantDelay)
+			}
+		})
+	}
+}
+
+// TestAssistantErrorEvent tests building and round-tripping the event
+func TestAssistantErrorEvent(t *testing.T) {
+	if event := NewAssistantErrorEvent(&AssistantMessage{Type: "assistant"}, 1, DefaultRetryPolicy); event != nil {
+		t.Errorf("NewAssistantErrorEvent() without error = %+v, want nil", event)
+	}
+
+	code := AssistantMessageErrorRateLimit
+	msg := &AssistantMessage{
+		Type:    "assistant",
+		Model:   "claude-sonnet-4-5",
+		Content: []ContentBlock{&TextBlock{Type: "text", Text: "API Error: Rate limit reached"}},
+		Error:   &code,
+	}
+	event := NewAssistantErrorEvent(msg, 1, DefaultRetryPolicy)
+	if event.Text != "API Error: Rate limit reached" || !event.Retryable() || event.RetryAfter != DefaultRetryPolicy.InitialDelay {
+		t.Errorf("NewAssistantErrorEvent() = %+v", event)
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	parsed, err := UnmarshalMessage(data)
+	if err != nil {
+		t.Fatalf("UnmarshalMessage() error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, event) {
+		t.Errorf("round trip = %+v, want %+v", parsed, event)
+	}
+	if issues := FindParseIssues(data); len(issues) != 0 {
+		t.Errorf("FindParseIssues() = %v, want none", issues)
+	}
+}
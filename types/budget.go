@@ -0,0 +1,33 @@
+package types
+
+import "fmt"
+
+// Budget decides whether queries may start, and is charged their cost. Set
+// it with WithBudget; the budget package has a Manager enforcing daily and
+// monthly ceilings per user, tenant and session across clients.
+//
+// The methods are called from the SDK's goroutines, so implementations must
+// be safe for concurrent use.
+type Budget interface {
+	// Allow is called before each query is sent, and returns an error, such
+	// as a *BudgetExceededError, to refuse it.
+	Allow() error
+	// Charge is called for each ResultMessage with the cost of the query:
+	// the increase of the session's total cost since the previous result.
+	Charge(sessionID string, costUSD float64)
+}
+
+// BudgetExceededError is returned for a query refused because the spending
+// of a user, tenant or session has reached a ceiling.
+type BudgetExceededError struct {
+	Scope    string // What the ceiling applies to, such as "user" or "tenant"
+	ID       string // The user, tenant or session
+	Period   string // Such as "daily" or "monthly"
+	LimitUSD float64
+	SpentUSD float64
+}
+
+// Error returns the error message, implementing the error interface.
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s budget of %s %q exceeded: spent $%.2f of $%.2f", e.Period, e.Scope, e.ID, e.SpentUSD, e.LimitUSD)
+}
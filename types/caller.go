@@ -0,0 +1,43 @@
+package types
+
+import "context"
+
+// callerKey is the context key of the caller identity of a session.
+type callerKey struct{}
+
+// ContextWithCaller returns a context that carries caller, the
+// application-level identity a session acts for, such as a user ID or a
+// set of token claims. Sessions created with WithCaller set it on the
+// context of every SDK MCP tool call.
+func ContextWithCaller(ctx context.Context, caller interface{}) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFrom returns the caller identity carried by ctx. Tool handlers use
+// it to authorize a call for the user the session acts for:
+//
+//	func handler(ctx context.Context, args map[string]interface{}) (*types.ToolResult, error) {
+//	    user, ok := types.CallerFrom(ctx)
+//	    if !ok {
+//	        return types.NewErrorMcpToolResult("no caller identity"), nil
+//	    }
+//	    ...
+//	}
+func CallerFrom(ctx context.Context) (interface{}, bool) {
+	caller := ctx.Value(callerKey{})
+	return caller, caller != nil
+}
+
+// CallerAs returns the caller identity carried by ctx as a T. It reports
+// false when there is no caller or it has another type.
+//
+// Example:
+//
+//	claims, ok := types.CallerAs[*Claims](ctx)
+//	if !ok || !claims.CanRead(tenant) {
+//	    return types.NewErrorMcpToolResult("access denied"), nil
+//	}
+func CallerAs[T any](ctx context.Context) (T, bool) {
+	caller, ok := ctx.Value(callerKey{}).(T)
+	return caller, ok
+}
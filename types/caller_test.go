@@ -0,0 +1,27 @@
+package types
+
+import (
+	"context"
+	"testing"
+)
+
+// TestCallerFrom tests reading the caller identity from a context.
+func TestCallerFrom(t *testing.T) {
+	type claims struct{ Tenant string }
+
+	if _, ok := CallerFrom(context.Background()); ok {
+		t.Error("expected no caller in an empty context")
+	}
+
+	ctx := ContextWithCaller(context.Background(), &claims{Tenant: "acme"})
+	caller, ok := CallerFrom(ctx)
+	if !ok || caller.(*claims).Tenant != "acme" {
+		t.Errorf("expected the caller, got %v", caller)
+	}
+	if c, ok := CallerAs[*claims](ctx); !ok || c.Tenant != "acme" {
+		t.Errorf("expected typed claims, got %v", c)
+	}
+	if _, ok := CallerAs[string](ctx); ok {
+		t.Error("expected CallerAs to reject another type")
+	}
+}
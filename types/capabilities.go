@@ -0,0 +1,257 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CLIVersion is the version of the Claude Code CLI, as printed by
+// "claude --version". The zero CLIVersion means the version is unknown.
+type CLIVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// cliVersionPattern finds the version in "claude --version" output, such as
+// "2.0.45 (Claude Code)".
+var cliVersionPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)`)
+
+// ParseCLIVersion parses a version such as "2.0.45" or "2.0.45 (Claude Code)".
+func ParseCLIVersion(s string) (CLIVersion, error) {
+	match := cliVersionPattern.FindStringSubmatch(s)
+	if match == nil {
+		return CLIVersion{}, fmt.Errorf("invalid CLI version %q", s)
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	patch, _ := strconv.Atoi(match[3])
+	return CLIVersion{Major: major, Minor: minor, Patch: patch}, nil
+}
+
+// ParseCLIVersionConstraint parses a minimum version such as "2", "2.x",
+// "2.0.x" or "2.0.45", where missing or "x" parts match any version: "2.x"
+// is the same as "2.0.0".
+func ParseCLIVersionConstraint(s string) (CLIVersion, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	parts := strings.Split(trimmed, ".")
+	if trimmed == "" || len(parts) > 3 {
+		return CLIVersion{}, fmt.Errorf("invalid CLI version constraint %q", s)
+	}
+	var numbers [3]int
+	wildcard := false
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			wildcard = true
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || wildcard {
+			return CLIVersion{}, fmt.Errorf("invalid CLI version constraint %q", s)
+		}
+		numbers[i] = n
+	}
+	if numbers == [3]int{} {
+		return CLIVersion{}, fmt.Errorf("invalid CLI version constraint %q: must be at least 0.0.1", s)
+	}
+	return CLIVersion{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// String returns the version as "major.minor.patch", or "unknown".
+func (v CLIVersion) String() string {
+	if v.IsZero() {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// IsZero reports whether the version is unknown.
+func (v CLIVersion) IsZero() bool {
+	return v == CLIVersion{}
+}
+
+// AtLeast reports whether v is the same as or newer than other.
+func (v CLIVersion) AtLeast(other CLIVersion) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor > other.Minor
+	}
+	return v.Patch >= other.Patch
+}
+
+// Supports reports whether the CLI supports a capability. An unknown
+// version is assumed to support everything, leaving it to the CLI to reject
+// what it does not know.
+func (v CLIVersion) Supports(c Capability) bool {
+	if v.IsZero() {
+		return true
+	}
+	required, ok := capabilityVersions[c]
+	return !ok || v.AtLeast(required)
+}
+
+// Require returns an *UnsupportedFeatureError if the CLI does not support a
+// capability.
+func (v CLIVersion) Require(c Capability) error {
+	if v.Supports(c) {
+		return nil
+	}
+	return &UnsupportedFeatureError{Capability: c, Version: v, Required: capabilityVersions[c]}
+}
+
+// Capability is an SDK feature that needs support from the CLI.
+type Capability string
+
+const (
+	CapabilityBetas              Capability = "betas"               // WithBetas (--betas)
+	CapabilityToolsFlag          Capability = "tools"               // WithTools and tool presets (--tools)
+	CapabilityStructuredOutput   Capability = "structured_output"   // JSON schema output formats (--json-schema)
+	CapabilityMaxBudget          Capability = "max_budget"          // WithMaxBudgetUSD (--max-budget-usd)
+	CapabilityPlugins            Capability = "plugins"             // WithPlugins (--plugin-dir)
+	CapabilityFileCheckpointing  Capability = "file_checkpointing"  // WithEnableFileCheckpointing and RewindFiles
+	CapabilitySubagentStartHooks Capability = "subagent_start_hook" // Hooks for HookEventSubagentStart
+)
+
+// capabilityVersions are the first CLI versions supporting each capability.
+// Capabilities missing here are supported by every CLI the SDK runs with.
+var capabilityVersions = map[Capability]CLIVersion{
+	CapabilityBetas:              {2, 0, 40},
+	CapabilityToolsFlag:          {2, 0, 40},
+	CapabilityStructuredOutput:   {2, 0, 45},
+	CapabilityMaxBudget:          {2, 0, 30},
+	CapabilityPlugins:            {2, 0, 12},
+	CapabilityFileCheckpointing:  {2, 0, 45},
+	CapabilitySubagentStartHooks: {2, 0, 43},
+}
+
+// Capabilities returns the capabilities the CLI supports, sorted by name.
+func (v CLIVersion) Capabilities() []Capability {
+	var supported []Capability
+	for c := range capabilityVersions {
+		if v.Supports(c) {
+			supported = append(supported, c)
+		}
+	}
+	sort.Slice(supported, func(i, j int) bool { return supported[i] < supported[j] })
+	return supported
+}
+
+// MinimumCLIVersion returns the first CLI version supporting a capability,
+// or the zero CLIVersion if every supported CLI has it.
+func MinimumCLIVersion(c Capability) CLIVersion {
+	return capabilityVersions[c]
+}
+
+// hookEventCapabilities are the capabilities needed by hook events added
+// after the first supported CLI.
+var hookEventCapabilities = map[HookEvent]Capability{
+	HookEventSubagentStart: CapabilitySubagentStartHooks,
+}
+
+// RequiredCapabilities returns the capabilities the options need from the
+// CLI, sorted by name.
+func (o *ClaudeAgentOptions) RequiredCapabilities() []Capability {
+	if o == nil {
+		return nil
+	}
+	var required []Capability
+	if len(o.Betas) > 0 {
+		required = append(required, CapabilityBetas)
+	}
+	if o.Tools != nil {
+		required = append(required, CapabilityToolsFlag)
+	}
+	if formatType, _ := o.OutputFormat["type"].(string); formatType == "json_schema" {
+		required = append(required, CapabilityStructuredOutput)
+	}
+	if o.MaxBudgetUSD != nil {
+		required = append(required, CapabilityMaxBudget)
+	}
+	if len(o.Plugins) > 0 {
+		required = append(required, CapabilityPlugins)
+	}
+	if o.EnableFileCheckpointing {
+		required = append(required, CapabilityFileCheckpointing)
+	}
+	for event, matchers := range o.Hooks {
+		if c, ok := hookEventCapabilities[event]; ok && len(matchers) > 0 {
+			required = append(required, c)
+		}
+	}
+	sort.Slice(required, func(i, j int) bool { return required[i] < required[j] })
+	return required
+}
+
+// ValidateMinCLIVersion checks the constraint set with WithMinCLIVersion,
+// if any.
+func (o *ClaudeAgentOptions) ValidateMinCLIVersion() error {
+	if o.MinCLIVersion == nil {
+		return nil
+	}
+	_, err := ParseCLIVersionConstraint(*o.MinCLIVersion)
+	return err
+}
+
+// CheckCapabilities returns an error if the CLI of the given version is
+// older than the options' WithMinCLIVersion or lacks a capability the
+// options need, joining an *UnsupportedFeatureError for each. For an unknown
+// version, capabilities are not checked, but WithMinCLIVersion fails since
+// it cannot be verified.
+func CheckCapabilities(o *ClaudeAgentOptions, v CLIVersion) error {
+	var errs []error
+	if o != nil && o.MinCLIVersion != nil {
+		if minimum, err := ParseCLIVersionConstraint(*o.MinCLIVersion); err != nil {
+			errs = append(errs, err)
+		} else if v.IsZero() || !v.AtLeast(minimum) {
+			errs = append(errs, &UnsupportedFeatureError{Version: v, Required: minimum})
+		}
+	}
+	for _, c := range o.RequiredCapabilities() {
+		if err := v.Require(c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UnsupportedFeatureError indicates that the installed CLI is too old for a
+// feature the SDK was asked to use, or for the version required with
+// WithMinCLIVersion.
+type UnsupportedFeatureError struct {
+	Capability Capability // Empty for WithMinCLIVersion
+	Version    CLIVersion // The installed CLI; zero if its version could not be detected
+	Required   CLIVersion // The first CLI supporting the feature
+}
+
+// Error returns the error message, implementing the error interface.
+func (e *UnsupportedFeatureError) Error() string {
+	if e.Capability == "" && e.Version.IsZero() {
+		return fmt.Sprintf("this application requires Claude CLI %s or newer, but the version of the installed CLI could not be detected; unset CLAUDE_AGENT_SDK_SKIP_VERSION_CHECK if it is set",
+			e.Required)
+	}
+	if e.Capability == "" {
+		return fmt.Sprintf("this application requires Claude CLI %s or newer, but %s is installed; update with: npm install -g @anthropic-ai/claude-code@latest",
+			e.Required, e.Version)
+	}
+	return fmt.Sprintf("%s requires Claude CLI %s or newer, but %s is installed; update with: npm install -g @anthropic-ai/claude-code@latest",
+		e.Capability, e.Required, e.Version)
+}
+
+// Is checks if the target error is an UnsupportedFeatureError.
+func (e *UnsupportedFeatureError) Is(target error) bool {
+	_, ok := target.(*UnsupportedFeatureError)
+	return ok
+}
+
+// IsUnsupportedFeatureError checks if an error is or wraps an
+// UnsupportedFeatureError.
+func IsUnsupportedFeatureError(err error) bool {
+	var e *UnsupportedFeatureError
+	return errors.As(err, &e)
+}
@@ -0,0 +1,128 @@
+package types
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseCLIVersion tests parsing of "claude --version" output
+func TestParseCLIVersion(t *testing.T) {
+	v, err := ParseCLIVersion("2.0.45 (Claude Code)")
+	if err != nil || v != (CLIVersion{2, 0, 45}) {
+		t.Errorf("ParseCLIVersion() = %v, %v, want 2.0.45", v, err)
+	}
+	if _, err := ParseCLIVersion("Claude Code"); err == nil {
+		t.Error("ParseCLIVersion() accepted output without version")
+	}
+	if got := (CLIVersion{}).String(); got != "unknown" {
+		t.Errorf("zero CLIVersion String() = %q, want unknown", got)
+	}
+}
+
+// TestCLIVersionSupports tests capability checks against CLI versions
+func TestCLIVersionSupports(t *testing.T) {
+	old := CLIVersion{2, 0, 10}
+	if old.Supports(CapabilityStructuredOutput) {
+		t.Error("2.0.10 supports structured output")
+	}
+	if !MinimumCLIVersion(CapabilityStructuredOutput).Supports(CapabilityStructuredOutput) {
+		t.Error("minimum version does not support structured output")
+	}
+	if !(CLIVersion{}).Supports(CapabilityStructuredOutput) {
+		t.Error("unknown version does not support structured output")
+	}
+	if !old.Supports(Capability("unlisted")) {
+		t.Error("unlisted capability not supported")
+	}
+
+	err := old.Require(CapabilityBetas)
+	var unsupported *UnsupportedFeatureError
+	if !errors.As(err, &unsupported) || unsupported.Required != MinimumCLIVersion(CapabilityBetas) {
+		t.Fatalf("Require() error = %v, want UnsupportedFeatureError", err)
+	}
+}
+
+// TestCheckCapabilities tests that options are checked against the CLI version
+func TestCheckCapabilities(t *testing.T) {
+	opts := NewClaudeAgentOptions().
+		WithBetas(SdkBetaContext1M).
+		WithEnableFileCheckpointing(true).
+		WithHooks(map[HookEvent][]HookMatcher{HookEventSubagentStart: {{}}})
+
+	want := []Capability{CapabilityBetas, CapabilityFileCheckpointing, CapabilitySubagentStartHooks}
+	got := opts.RequiredCapabilities()
+	if len(got) != len(want) {
+		t.Fatalf("RequiredCapabilities() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("RequiredCapabilities() = %v, want %v", got, want)
+		}
+	}
+
+	if err := CheckCapabilities(opts, CLIVersion{2, 1, 0}); err != nil {
+		t.Errorf("CheckCapabilities(2.1.0) error = %v", err)
+	}
+	if err := CheckCapabilities(opts, CLIVersion{}); err != nil {
+		t.Errorf("CheckCapabilities(unknown) error = %v", err)
+	}
+	err := CheckCapabilities(opts, CLIVersion{2, 0, 41})
+	if !IsUnsupportedFeatureError(err) {
+		t.Fatalf("CheckCapabilities(2.0.41) error = %v, want UnsupportedFeatureError", err)
+	}
+	if len(err.(interface{ Unwrap() []error }).Unwrap()) != 2 {
+		t.Errorf("CheckCapabilities(2.0.41) error = %v, want file checkpointing and subagent start hooks", err)
+	}
+}
+
+// TestMinCLIVersion tests parsing version constraints and checking them against the CLI
+func TestMinCLIVersion(t *testing.T) {
+	for input, want := range map[string]CLIVersion{
+		"2":      {2, 0, 0},
+		"2.x":    {2, 0, 0},
+		"v2.1.x": {2, 1, 0},
+		"2.0.45": {2, 0, 45},
+	} {
+		if got, err := ParseCLIVersionConstraint(input); err != nil || got != want {
+			t.Errorf("ParseCLIVersionConstraint(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "x", "2.x.5", "two", "2.0.0.1", "0.0.0"} {
+		if _, err := ParseCLIVersionConstraint(input); err == nil {
+			t.Errorf("ParseCLIVersionConstraint(%q) accepted", input)
+		}
+	}
+
+	opts := NewClaudeAgentOptions().WithMinCLIVersion("2.1.x")
+	if err := opts.ValidateMinCLIVersion(); err != nil {
+		t.Fatalf("ValidateMinCLIVersion() error = %v", err)
+	}
+	if err := CheckCapabilities(opts, CLIVersion{2, 1, 3}); err != nil {
+		t.Errorf("CheckCapabilities(2.1.3) error = %v", err)
+	}
+	if err := CheckCapabilities(opts, CLIVersion{}); !IsUnsupportedFeatureError(err) || !strings.Contains(err.Error(), "could not be detected") {
+		t.Errorf("CheckCapabilities(unknown) error = %v, want an undetectable version error", err)
+	}
+	err := CheckCapabilities(opts, CLIVersion{2, 0, 45})
+	var unsupported *UnsupportedFeatureError
+	if !errors.As(err, &unsupported) || unsupported.Required != (CLIVersion{2, 1, 0}) {
+		t.Fatalf("CheckCapabilities(2.0.45) error = %v, want UnsupportedFeatureError", err)
+	}
+	if want := "requires Claude CLI 2.1.0 or newer, but 2.0.45 is installed"; !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to contain %q", err, want)
+	}
+	if err := NewClaudeAgentOptions().WithMinCLIVersion("latest").ValidateMinCLIVersion(); err == nil {
+		t.Error("ValidateMinCLIVersion() accepted an invalid constraint")
+	}
+}
+
+// TestCLIVersionCapabilities tests listing the capabilities of a CLI version
+func TestCLIVersionCapabilities(t *testing.T) {
+	got := CLIVersion{2, 0, 30}.Capabilities()
+	want := []Capability{CapabilityMaxBudget, CapabilityPlugins}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Capabilities() = %v, want %v", got, want)
+	}
+}
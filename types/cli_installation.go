@@ -0,0 +1,8 @@
+package types
+
+// CLIInstallation is a Claude CLI binary found while searching for the CLI.
+type CLIInstallation struct {
+	Path    string
+	Version CLIVersion // Zero if Err is set
+	Err     error      // Why the version could not be determined, if it could not
+}
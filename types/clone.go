@@ -0,0 +1,71 @@
+package types
+
+import "reflect"
+
+// Clone returns a deep copy of the options: maps, slices and the values of
+// pointers such as Model and MaxTurns are copied, including those nested in
+// McpServers, OutputFormat, Agents, Plugins and Hooks, so that changing the
+// copy never changes o. Callbacks, SDK MCP server instances and the stateful
+// helpers pointed to by fields such as ToolRateLimiter, DryRun and Redactor
+// are shared, as are other pointers to structs. Clone of nil returns nil.
+//
+// NewClient and Query clone their options, so one options value can be
+// reused by many concurrent clients.
+func (o *ClaudeAgentOptions) Clone() *ClaudeAgentOptions {
+	if o == nil {
+		return nil
+	}
+	clone := deepCopy(reflect.ValueOf(*o)).Interface().(ClaudeAgentOptions)
+	return &clone
+}
+
+// deepCopy returns a copy of v sharing no maps, slices or pointers to
+// non-struct values with it.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return copied
+	case reflect.Ptr:
+		if v.IsNil() || v.Elem().Kind() == reflect.Struct {
+			return v
+		}
+		copied := reflect.New(v.Elem().Type())
+		copied.Elem().Set(deepCopy(v.Elem()))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(deepCopy(v.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := copied.Field(i); field.CanSet() {
+				field.Set(deepCopy(v.Field(i)))
+			}
+		}
+		return copied
+	default:
+		return v
+	}
+}
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"context"
+	"testing"
+)
+
+// TestClone tests that changing a clone leaves the original options unchanged
+func TestClone(t *testing.T) {
+	limiter := &ToolRateLimiter{}
+	hook := func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+		return nil, nil
+	}
+	opts := NewClaudeAgentOptions().
+		WithModel("model").
+		WithAllowedTools("Read").
+		WithEnv(map[string]string{"STAGE": "dev"}).
+		WithMcpServers(map[string]interface{}{"files": McpStdioServerConfig{Command: "files", Args: []string{"--ro"}}}).
+		WithJSONSchemaOutput(map[string]interface{}{"type": "object", "required": []interface{}{"answer"}}).
+		WithAgents(map[string]AgentDefinition{"reviewer": {Description: "Reviews", Tools: []string{"Read"}}}).
+		WithPlugins([]SdkPluginConfig{{Type: "local", Path: "/plugins/a"}}).
+		WithHook(HookEventPreToolUse, HookMatcher{Hooks: []HookCallbackFunc{hook}})
+	opts.ToolRateLimiter = limiter
+
+	clone := opts.Clone()
+	*clone.Model = "other"
+	clone.AllowedTools[0] = "Bash"
+	clone.Env["STAGE"] = "prod"
+	clone.McpServers.(map[string]interface{})["files"].(McpStdioServerConfig).Args[0] = "--rw"
+	clone.OutputFormat["schema"].(map[string]interface{})["required"].([]interface{})[0] = "other"
+	clone.Agents["reviewer"].Tools[0] = "Bash"
+	clone.Plugins[0].Path = "/plugins/b"
+	clone.Hooks[HookEventPreToolUse][0].Hooks = nil
+
+	if *opts.Model != "model" || opts.AllowedTools[0] != "Read" || opts.Env["STAGE"] != "dev" {
+		t.Errorf("Clone() shares scalars: model %s, tools %v, env %v", *opts.Model, opts.AllowedTools, opts.Env)
+	}
+	if opts.McpServers.(map[string]interface{})["files"].(McpStdioServerConfig).Args[0] != "--ro" {
+		t.Error("Clone() shares MCP server args")
+	}
+	if opts.OutputFormat["schema"].(map[string]interface{})["required"].([]interface{})[0] != "answer" {
+		t.Error("Clone() shares the output schema")
+	}
+	if opts.Agents["reviewer"].Tools[0] != "Read" || opts.Plugins[0].Path != "/plugins/a" {
+		t.Error("Clone() shares agents or plugins")
+	}
+	if len(opts.Hooks[HookEventPreToolUse][0].Hooks) != 1 {
+		t.Error("Clone() shares hook matchers")
+	}
+	if clone.ToolRateLimiter != limiter {
+		t.Error("Clone() copied the stateful rate limiter")
+	}
+	if (*ClaudeAgentOptions)(nil).Clone() != nil {
+		t.Error("Clone() of nil is not nil")
+	}
+}
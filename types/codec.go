@@ -0,0 +1,63 @@
+package types
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// Codec encodes and decodes JSON. Implementations must behave like
+// encoding/json, including calling the MarshalJSON and UnmarshalJSON
+// methods of the types they encode and decode.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// StdlibCodec is the Codec of encoding/json, used by default.
+type StdlibCodec struct{}
+
+// Marshal encodes v with json.Marshal.
+func (StdlibCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes data with json.Unmarshal.
+func (StdlibCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// codecBox holds the codec in an atomic.Value, which needs one concrete type.
+type codecBox struct{ Codec }
+
+var codec atomic.Value
+
+// SetCodec sets the codec that decodes messages, content blocks and stream
+// events, and encodes them back. Parsing the CLI's JSON lines dominates the
+// cost of long sessions with partial messages, so a faster codec such as
+// jsoniter or sonic can pay off; building with the jsoniter tag installs
+// JSONIterCodec. Set the codec before starting sessions. A nil codec
+// restores StdlibCodec.
+func SetCodec(c Codec) {
+	if c == nil {
+		c = StdlibCodec{}
+	}
+	codec.Store(codecBox{c})
+}
+
+// CurrentCodec returns the codec set with SetCodec.
+func CurrentCodec() Codec {
+	if box, ok := codec.Load().(codecBox); ok {
+		return box.Codec
+	}
+	return StdlibCodec{}
+}
+
+// jsonMarshal encodes v with the current codec.
+func jsonMarshal(v interface{}) ([]byte, error) {
+	return CurrentCodec().Marshal(v)
+}
+
+// jsonUnmarshal decodes data into v with the current codec.
+func jsonUnmarshal(data []byte, v interface{}) error {
+	return CurrentCodec().Unmarshal(data, v)
+}
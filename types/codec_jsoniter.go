@@ -0,0 +1,25 @@
+//go:build jsoniter
+
+package types
+
+import jsoniter "github.com/json-iterator/go"
+
+// JSONIterCodec is a Codec using jsoniter in its encoding/json compatible
+// configuration. Building with the jsoniter tag installs it by default.
+type JSONIterCodec struct{}
+
+var jsoniterAPI = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// Marshal encodes v with jsoniter.
+func (JSONIterCodec) Marshal(v interface{}) ([]byte, error) {
+	return jsoniterAPI.Marshal(v)
+}
+
+// Unmarshal decodes data with jsoniter.
+func (JSONIterCodec) Unmarshal(data []byte, v interface{}) error {
+	return jsoniterAPI.Unmarshal(data, v)
+}
+
+func init() {
+	SetCodec(JSONIterCodec{})
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// countingCodec counts the calls of the codec it wraps.
+type countingCodec struct {
+	StdlibCodec
+	unmarshals int
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshals++
+	return json.Unmarshal(data, v)
+}
+
+// TestSetCodec tests that messages are decoded with the codec set
+func TestSetCodec(t *testing.T) {
+	previous := CurrentCodec()
+	defer SetCodec(previous)
+
+	counting := &countingCodec{}
+	SetCodec(counting)
+	if CurrentCodec() != Codec(counting) {
+		t.Fatalf("CurrentCodec() = %T, want the codec set", CurrentCodec())
+	}
+	msg, err := UnmarshalMessage([]byte(`{"type":"assistant","message":{"role":"assistant","model":"m","content":[{"type":"text","text":"hi"}]}}`))
+	if err != nil {
+		t.Fatalf("UnmarshalMessage() error = %v", err)
+	}
+	if TextOf(msg) != "hi" || counting.unmarshals == 0 {
+		t.Errorf("UnmarshalMessage() = %#v with %d codec calls, want text hi decoded by the codec", msg, counting.unmarshals)
+	}
+
+	SetCodec(nil)
+	if _, ok := CurrentCodec().(StdlibCodec); !ok {
+		t.Errorf("CurrentCodec() after SetCodec(nil) = %T, want StdlibCodec", CurrentCodec())
+	}
+}
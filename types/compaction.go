@@ -0,0 +1,141 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SystemSubtypeCompactBoundary is the subtype of the system message the CLI
+// sends when it compacts the conversation context. UnmarshalMessage returns
+// it as a *CompactionEvent.
+const SystemSubtypeCompactBoundary = "compact_boundary"
+
+// Compaction triggers reported in CompactionEvent.Trigger.
+const (
+	CompactionTriggerManual = "manual" // Requested with /compact
+	CompactionTriggerAuto   = "auto"   // The context window was nearly full
+)
+
+// CompactionEvent reports that the CLI compacted the conversation context,
+// replacing earlier messages with a summary.
+//
+// Example:
+//
+//	if c, ok := msg.(*types.CompactionEvent); ok {
+//	    log.Printf("context compacted (%s) from %d tokens", c.Trigger, c.OriginalTokens)
+//	}
+type CompactionEvent struct {
+	Type            string // "system"
+	Subtype         string // SystemSubtypeCompactBoundary
+	Trigger         string // CompactionTriggerManual or CompactionTriggerAuto
+	OriginalTokens  int    // Context tokens before compaction
+	CompactedTokens int    // Context tokens after compaction; 0 when the CLI does not report it
+	Summary         string // The summary replacing the compacted messages, when the CLI reports it
+	SessionID       string
+	UUID            string
+}
+
+// GetMessageType returns the type of the message.
+func (m *CompactionEvent) GetMessageType() string {
+	return m.Type
+}
+
+// ShouldDisplayToUser returns true, so that applications can tell the user
+// that earlier context was summarized.
+func (m *CompactionEvent) ShouldDisplayToUser() bool {
+	return true
+}
+
+func (m *CompactionEvent) isMessage() {}
+
+// compactMessage is the CLI's JSON form of a CompactionEvent.
+type compactMessage struct {
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype"`
+	SessionID string `json:"session_id,omitempty"`
+	UUID      string `json:"uuid,omitempty"`
+	Metadata  struct {
+		Trigger    string `json:"trigger"`
+		PreTokens  int    `json:"pre_tokens"`
+		PostTokens int    `json:"post_tokens,omitempty"`
+		Summary    string `json:"summary,omitempty"`
+	} `json:"compact_metadata"`
+}
+
+// UnmarshalJSON decodes the CLI's compact_boundary system message.
+func (m *CompactionEvent) UnmarshalJSON(data []byte) error {
+	var raw compactMessage
+	if err := jsonUnmarshal(data, &raw); err != nil {
+		return err
+	}
+	*m = CompactionEvent{
+		Type:            raw.Type,
+		Subtype:         raw.Subtype,
+		Trigger:         raw.Metadata.Trigger,
+		OriginalTokens:  raw.Metadata.PreTokens,
+		CompactedTokens: raw.Metadata.PostTokens,
+		Summary:         raw.Metadata.Summary,
+		SessionID:       raw.SessionID,
+		UUID:            raw.UUID,
+	}
+	return nil
+}
+
+// MarshalJSON encodes the event in the CLI's format, so it can be parsed
+// back.
+func (m *CompactionEvent) MarshalJSON() ([]byte, error) {
+	raw := compactMessage{
+		Type:      messageType(m.Type, "system"),
+		Subtype:   SystemSubtypeCompactBoundary,
+		SessionID: m.SessionID,
+		UUID:      m.UUID,
+	}
+	raw.Metadata.Trigger = m.Trigger
+	raw.Metadata.PreTokens = m.OriginalTokens
+	raw.Metadata.PostTokens = m.CompactedTokens
+	raw.Metadata.Summary = m.Summary
+	return jsonMarshal(&raw)
+}
+
+// CompactionEnv returns the environment variables applying the options of
+// WithAutoCompact and WithDisableAutoCompact for the CLI.
+func (o *ClaudeAgentOptions) CompactionEnv() map[string]string {
+	env := make(map[string]string)
+	if o == nil {
+		return env
+	}
+	if o.AutoCompactThreshold != nil {
+		env["CLAUDE_AUTOCOMPACT_PCT_OVERRIDE"] = strconv.Itoa(*o.AutoCompactThreshold)
+	}
+	if o.DisableAutoCompact {
+		env["DISABLE_AUTO_COMPACT"] = "1"
+	}
+	return env
+}
+
+// ValidateCompaction checks the threshold set with WithAutoCompact, if any:
+// it must be a percentage from 1 to 100, and cannot be combined with
+// WithDisableAutoCompact.
+func (o *ClaudeAgentOptions) ValidateCompaction() error {
+	if o.AutoCompactThreshold == nil {
+		return nil
+	}
+	if threshold := *o.AutoCompactThreshold; threshold < 1 || threshold > 100 {
+		return fmt.Errorf("invalid auto-compact threshold %d%%: must be from 1 to 100", threshold)
+	}
+	if o.DisableAutoCompact {
+		return fmt.Errorf("auto-compact threshold %d%% conflicts with WithDisableAutoCompact", *o.AutoCompactThreshold)
+	}
+	return nil
+}
+
+// CompactPrompt returns the prompt asking the CLI to compact the
+// conversation context, keeping what instructions ask for in the summary.
+func CompactPrompt(instructions string) string {
+	instructions = strings.TrimSpace(instructions)
+	if instructions == "" {
+		return "/compact"
+	}
+	return "/compact " + instructions
+}
@@ -0,0 +1,96 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestCompactionEvent tests parsing and round-tripping compact_boundary messages
+func TestCompactionEvent(t *testing.T) {
+	data := `{"type":"system","subtype":"compact_boundary","session_id":"s1","uuid":"u1","compact_metadata":{"trigger":"auto","pre_tokens":155000,"post_tokens":12000}}`
+	msg, err := UnmarshalMessage([]byte(data))
+	if err != nil {
+		t.Fatalf("UnmarshalMessage() error = %v", err)
+	}
+	event, ok := msg.(*CompactionEvent)
+	if !ok {
+		t.Fatalf("UnmarshalMessage() = %T, want *CompactionEvent", msg)
+	}
+	want := &CompactionEvent{
+		Type:            "system",
+		Subtype:         SystemSubtypeCompactBoundary,
+		Trigger:         CompactionTriggerAuto,
+		OriginalTokens:  155000,
+		CompactedTokens: 12000,
+		SessionID:       "s1",
+		UUID:            "u1",
+	}
+	if !reflect.DeepEqual(event, want) {
+		t.Errorf("CompactionEvent = %+v, want %+v", event, want)
+	}
+	if event.GetMessageType() != "system" {
+		t.Errorf("GetMessageType() = %q, want system", event.GetMessageType())
+	}
+
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	again, err := UnmarshalMessage(encoded)
+	if err != nil {
+		t.Fatalf("UnmarshalMessage(marshaled) error = %v", err)
+	}
+	if !reflect.DeepEqual(again, want) {
+		t.Errorf("round trip = %+v, want %+v", again, want)
+	}
+
+	other, err := UnmarshalMessage([]byte(`{"type":"system","subtype":"info"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalMessage() error = %v", err)
+	}
+	if _, ok := other.(*SystemMessage); !ok {
+		t.Errorf("other system subtype = %T, want *SystemMessage", other)
+	}
+}
+
+// TestCompactionOptions tests the environment and validation of the auto-compact options
+func TestCompactionOptions(t *testing.T) {
+	opts := NewClaudeAgentOptions().WithAutoCompact(70)
+	if err := opts.ValidateCompaction(); err != nil {
+		t.Fatalf("ValidateCompaction() error = %v", err)
+	}
+	if got := opts.CompactionEnv(); !reflect.DeepEqual(got, map[string]string{"CLAUDE_AUTOCOMPACT_PCT_OVERRIDE": "70"}) {
+		t.Errorf("CompactionEnv() = %v", got)
+	}
+	if err := opts.WithDisableAutoCompact().ValidateCompaction(); err == nil {
+		t.Error("ValidateCompaction() accepted a threshold with auto-compact disabled")
+	}
+
+	disabled := NewClaudeAgentOptions().WithDisableAutoCompact()
+	if err := disabled.ValidateCompaction(); err != nil {
+		t.Fatalf("ValidateCompaction() error = %v", err)
+	}
+	if got := disabled.CompactionEnv(); !reflect.DeepEqual(got, map[string]string{"DISABLE_AUTO_COMPACT": "1"}) {
+		t.Errorf("CompactionEnv() = %v", got)
+	}
+
+	for _, threshold := range []int{0, -5, 101} {
+		if err := NewClaudeAgentOptions().WithAutoCompact(threshold).ValidateCompaction(); err == nil {
+			t.Errorf("ValidateCompaction() accepted threshold %d", threshold)
+		}
+	}
+	if got := NewClaudeAgentOptions().CompactionEnv(); len(got) != 0 {
+		t.Errorf("CompactionEnv() of default options = %v, want empty", got)
+	}
+}
+
+// TestCompactPrompt tests the /compact prompt with and without instructions
+func TestCompactPrompt(t *testing.T) {
+	if got := CompactPrompt("  "); got != "/compact" {
+		t.Errorf("CompactPrompt(blank) = %q", got)
+	}
+	if got := CompactPrompt(" keep the test plan\n"); got != "/compact keep the test plan" {
+		t.Errorf("CompactPrompt() = %q", got)
+	}
+}
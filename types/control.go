@@ -1,6 +1,11 @@
 package types
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 // PermissionMode represents the permission mode for Claude.
 type PermissionMode string
@@ -52,6 +57,9 @@ type PermissionResultAllow struct {
 	Behavior           string                  `json:"behavior"` // "allow"
 	UpdatedInput       *map[string]interface{} `json:"updated_input,omitempty"`
 	UpdatedPermissions []PermissionUpdate      `json:"updated_permissions,omitempty"`
+
+	// Source is recorded in the permission audit log. Defaults to PermissionSourceCallback.
+	Source PermissionDecisionSource `json:"-"`
 }
 
 // PermissionResultDeny represents a deny permission result.
@@ -59,12 +67,82 @@ type PermissionResultDeny struct {
 	Behavior  string `json:"behavior"` // "deny"
 	Message   string `json:"message,omitempty"`
 	Interrupt bool   `json:"interrupt,omitempty"`
+
+	// Source is recorded in the permission audit log. Defaults to PermissionSourceCallback.
+	Source PermissionDecisionSource `json:"-"`
 }
 
 // ToolPermissionContext provides context for tool permission callbacks.
 type ToolPermissionContext struct {
 	Signal      interface{}        `json:"signal,omitempty"` // Future: abort signal support
 	Suggestions []PermissionUpdate `json:"suggestions,omitempty"`
+
+	// BlockedPath is the path outside the allowed directories that triggered the request, if any.
+	BlockedPath *string `json:"blocked_path,omitempty"`
+	// ParentToolUseID identifies the Task tool use when a subagent makes the request.
+	ParentToolUseID *string `json:"parent_tool_use_id,omitempty"`
+	// AgentName is the subagent type of that Task tool use, if known.
+	AgentName string `json:"agent_name,omitempty"`
+}
+
+// PermissionResult is the result of a typed permission handler.
+// It is implemented by PermissionResultAllow and PermissionResultDeny.
+type PermissionResult interface {
+	isPermissionResult()
+}
+
+func (PermissionResultAllow) isPermissionResult() {}
+
+func (PermissionResultDeny) isPermissionResult() {}
+
+// ToolPermissionRequest describes a tool use the CLI asks permission for.
+type ToolPermissionRequest struct {
+	ToolName        string
+	Input           map[string]interface{}
+	Suggestions     []PermissionUpdate
+	BlockedPath     *string
+	ParentToolUseID *string
+	AgentName       string // Subagent making the request; empty for the main agent
+}
+
+// NewToolPermissionRequest builds a ToolPermissionRequest from the arguments
+// of a CanUseToolFunc.
+func NewToolPermissionRequest(toolName string, input map[string]interface{}, permCtx ToolPermissionContext) ToolPermissionRequest {
+	return ToolPermissionRequest{
+		ToolName:        toolName,
+		Input:           input,
+		Suggestions:     permCtx.Suggestions,
+		BlockedPath:     permCtx.BlockedPath,
+		ParentToolUseID: permCtx.ParentToolUseID,
+		AgentName:       permCtx.AgentName,
+	}
+}
+
+// PermissionHandlerFunc is a typed alternative to CanUseToolFunc.
+//
+// Example:
+//
+//	opts.WithPermissionHandler(func(ctx context.Context, req types.ToolPermissionRequest) (types.PermissionResult, error) {
+//	    if req.BlockedPath != nil {
+//	        return types.PermissionResultDeny{Behavior: "deny", Message: "outside the project"}, nil
+//	    }
+//	    return types.PermissionResultAllow{Behavior: "allow"}, nil
+//	})
+type PermissionHandlerFunc func(ctx context.Context, req ToolPermissionRequest) (PermissionResult, error)
+
+// CanUseTool adapts the handler to a CanUseToolFunc, so it can be combined
+// with helpers that wrap permission callbacks.
+func (f PermissionHandlerFunc) CanUseTool() CanUseToolFunc {
+	return func(ctx context.Context, toolName string, input map[string]interface{}, permCtx ToolPermissionContext) (interface{}, error) {
+		result, err := f(ctx, NewToolPermissionRequest(toolName, input, permCtx))
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			return nil, NewControlProtocolError("permission handler returned no result")
+		}
+		return result, nil
+	}
 }
 
 // HookEvent represents a hook event type.
@@ -79,12 +157,25 @@ const (
 	HookEventPreResponse      HookEvent = "PreResponse"
 	HookEventPostResponse     HookEvent = "PostResponse"
 	HookEventStop             HookEvent = "Stop"
+	HookEventSubagentStart    HookEvent = "SubagentStart"
 	HookEventSubagentStop     HookEvent = "SubagentStop"
 	HookEventPreCompact       HookEvent = "PreCompact"
 	HookEventPostCompact      HookEvent = "PostCompact"
 	HookEventOnError          HookEvent = "OnError"
 )
 
+// IsValid reports whether the event is one of the known hook events.
+func (e HookEvent) IsValid() bool {
+	switch e {
+	case HookEventPreToolUse, HookEventPostToolUse, HookEventUserPromptSubmit,
+		HookEventPrePrompt, HookEventPostPrompt, HookEventPreResponse, HookEventPostResponse,
+		HookEventStop, HookEventSubagentStart, HookEventSubagentStop,
+		HookEventPreCompact, HookEventPostCompact, HookEventOnError:
+		return true
+	}
+	return false
+}
+
 // BaseHookInput contains common fields for all hook inputs.
 type BaseHookInput struct {
 	SessionID      string  `json:"session_id"`
@@ -124,11 +215,25 @@ type StopHookInput struct {
 	StopHookActive bool   `json:"stop_hook_active"`
 }
 
+// SubagentStartHookInput represents input for SubagentStart hook events.
+// ParentToolUseID is the ID of the Task tool use that launched the subagent.
+type SubagentStartHookInput struct {
+	BaseHookInput
+	HookEventName   string  `json:"hook_event_name"` // "SubagentStart"
+	AgentID         string  `json:"agent_id,omitempty"`
+	AgentType       string  `json:"agent_type"` // Subagent type, such as "code-reviewer"
+	ParentToolUseID *string `json:"parent_tool_use_id,omitempty"`
+}
+
 // SubagentStopHookInput represents input for SubagentStop hook events.
 type SubagentStopHookInput struct {
 	BaseHookInput
-	HookEventName  string `json:"hook_event_name"` // "SubagentStop"
-	StopHookActive bool   `json:"stop_hook_active"`
+	HookEventName       string  `json:"hook_event_name"` // "SubagentStop"
+	StopHookActive      bool    `json:"stop_hook_active"`
+	AgentID             string  `json:"agent_id,omitempty"`
+	AgentType           string  `json:"agent_type,omitempty"`
+	ParentToolUseID     *string `json:"parent_tool_use_id,omitempty"`
+	AgentTranscriptPath string  `json:"agent_transcript_path,omitempty"`
 }
 
 // PreCompactHookInput represents input for PreCompact hook events.
@@ -226,6 +331,17 @@ func (h *UserPromptSubmitHookSpecificOutput) GetHookEventName() string {
 	return h.HookEventName
 }
 
+// SubagentStartHookSpecificOutput represents hook-specific output for SubagentStart events.
+type SubagentStartHookSpecificOutput struct {
+	HookEventName     string  `json:"hookEventName"` // "SubagentStart"
+	AdditionalContext *string `json:"additionalContext,omitempty"`
+}
+
+// GetHookEventName returns the hook event name.
+func (h *SubagentStartHookSpecificOutput) GetHookEventName() string {
+	return h.HookEventName
+}
+
 // PrePromptHookSpecificOutput represents hook-specific output for PrePrompt events.
 type PrePromptHookSpecificOutput struct {
 	HookEventName     string                    `json:"hookEventName"` // "PrePrompt"
@@ -294,6 +410,9 @@ func (h *OnErrorHookSpecificOutput) GetHookEventName() string {
 	return h.HookEventName
 }
 
+// DefaultAsyncHookTimeoutMs is the default time an async hook may run before its result is dropped.
+const DefaultAsyncHookTimeoutMs = 60000
+
 // AsyncHookJSONOutput represents async hook output that defers hook execution.
 type AsyncHookJSONOutput struct {
 	Async        bool `json:"async"`
@@ -316,11 +435,126 @@ type SyncHookJSONOutput struct {
 	HookSpecificOutput interface{} `json:"hookSpecificOutput,omitempty"`
 }
 
+// NormalizeHookOutput converts a hook callback's return value into the map form
+// used by the control protocol. Maps are returned as-is; typed outputs such as
+// SyncHookJSONOutput or AsyncHookJSONOutput are round-tripped through JSON.
+func NormalizeHookOutput(output interface{}) (map[string]interface{}, error) {
+	if m, ok := output.(map[string]interface{}); ok {
+		return m, nil
+	}
+
+	if output == nil {
+		return nil, NewControlProtocolError("hook callback must return map[string]interface{} or a hook output struct")
+	}
+
+	data, err := json.Marshal(output)
+	if err != nil {
+		return nil, NewControlProtocolErrorWithCause("failed to marshal hook output", err)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil || m == nil {
+		return nil, NewControlProtocolError(fmt.Sprintf("hook callback must return map[string]interface{} or a hook output struct, got %T", output))
+	}
+
+	return m, nil
+}
+
+// ParseHookInput decodes the raw input passed to a hook callback into the typed
+// input struct for its event, such as *PreToolUseHookInput or
+// *SubagentStartHookInput. Inputs that are already typed, and inputs for events
+// without a typed struct, are returned unchanged.
+func ParseHookInput(input interface{}) (interface{}, error) {
+	raw, ok := input.(map[string]interface{})
+	if !ok {
+		return input, nil
+	}
+
+	eventName, _ := raw["hook_event_name"].(string)
+	var typed interface{}
+	switch HookEvent(eventName) {
+	case HookEventPreToolUse:
+		typed = &PreToolUseHookInput{}
+	case HookEventPostToolUse:
+		typed = &PostToolUseHookInput{}
+	case HookEventUserPromptSubmit:
+		typed = &UserPromptSubmitHookInput{}
+	case HookEventStop:
+		typed = &StopHookInput{}
+	case HookEventSubagentStart:
+		typed = &SubagentStartHookInput{}
+	case HookEventSubagentStop:
+		typed = &SubagentStopHookInput{}
+	case HookEventPreCompact:
+		typed = &PreCompactHookInput{}
+	case HookEventPostCompact:
+		typed = &PostCompactHookInput{}
+	case HookEventPrePrompt:
+		typed = &PrePromptHookInput{}
+	case HookEventPostPrompt:
+		typed = &PostPromptHookInput{}
+	case HookEventPreResponse:
+		typed = &PreResponseHookInput{}
+	case HookEventPostResponse:
+		typed = &PostResponseHookInput{}
+	case HookEventOnError:
+		typed = &OnErrorHookInput{}
+	default:
+		return input, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, NewControlProtocolErrorWithCause("failed to marshal hook input", err)
+	}
+	if err := json.Unmarshal(data, typed); err != nil {
+		return nil, NewControlProtocolErrorWithCause("failed to decode "+eventName+" hook input", err)
+	}
+	return typed, nil
+}
+
 // HookContext provides context information for hook callbacks.
 type HookContext struct {
 	Signal interface{} `json:"signal,omitempty"` // Future: abort signal support
 }
 
+// HookExecution describes a single run of a hook callback.
+type HookExecution struct {
+	CallbackID string
+	Event      HookEvent
+	Matcher    *string
+	ToolName   string // Empty for events that are not about a tool
+	ToolUseID  *string
+	Duration   time.Duration
+	Decision   string // See HookOutputDecision; empty on error
+	Err        error
+	Async      bool
+	TimedOut   bool // Async hook exceeded its timeout
+
+	// CorrelationID is the correlation ID of the query running when the
+	// hook was called, see ContextWithCorrelationID
+	CorrelationID string
+}
+
+// HookOutputDecision summarizes the decision expressed by a normalized hook output:
+// the permissionDecision ("allow", "deny" or "ask") if present, otherwise
+// "block" for decision=block, "stop" for continue=false, or "" when the hook
+// made no decision.
+func HookOutputDecision(output map[string]interface{}) string {
+	if specific, ok := output["hookSpecificOutput"].(map[string]interface{}); ok {
+		if decision, _ := specific["permissionDecision"].(string); decision != "" {
+			return decision
+		}
+	}
+	if decision, _ := output["decision"].(string); decision == "block" {
+		return decision
+	}
+	if cont, ok := output["continue"].(bool); ok && !cont {
+		return "stop"
+	}
+	return ""
+}
+
 // SDKControlInterruptRequest represents an interrupt request.
 type SDKControlInterruptRequest struct {
 	Subtype string `json:"subtype"` // "interrupt"
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 )
@@ -85,6 +86,7 @@ func TestHookEventConstants(t *testing.T) {
 		HookEventPostToolUse,
 		HookEventUserPromptSubmit,
 		HookEventStop,
+		HookEventSubagentStart,
 		HookEventSubagentStop,
 		HookEventPreCompact,
 	}
@@ -93,6 +95,13 @@ func TestHookEventConstants(t *testing.T) {
 		if event == "" {
 			t.Error("hook event should not be empty")
 		}
+		if !event.IsValid() {
+			t.Errorf("expected %s to be a valid hook event", event)
+		}
+	}
+
+	if HookEvent("PreToolUze").IsValid() {
+		t.Error("expected unknown hook event to be invalid")
 	}
 }
 
@@ -126,7 +135,135 @@ func TestPreToolUseHookInput(t *testing.T) {
 	}
 }
 
+// TestNormalizeHookOutput tests conversion of typed hook outputs to protocol maps.
+func TestNormalizeHookOutput(t *testing.T) {
+	output, err := NormalizeHookOutput(SyncHookJSONOutput{Reason: stringPtr("blocked by policy")})
+	if err != nil {
+		t.Fatalf("NormalizeHookOutput failed: %v", err)
+	}
+	if output["reason"] != "blocked by policy" {
+		t.Errorf("expected reason to be preserved, got %v", output["reason"])
+	}
+
+	raw := map[string]interface{}{"continue": true}
+	output, err = NormalizeHookOutput(raw)
+	if err != nil || output["continue"] != true {
+		t.Errorf("expected map output to pass through, got %v (err %v)", output, err)
+	}
+
+	if _, err := NormalizeHookOutput("not an object"); !IsControlProtocolError(err) {
+		t.Errorf("expected ControlProtocolError for non-object output, got %v", err)
+	}
+	if _, err := NormalizeHookOutput(nil); err == nil {
+		t.Error("expected error for nil hook output")
+	}
+}
+
+// TestParseHookInput tests decoding raw hook inputs into typed structs.
+func TestParseHookInput(t *testing.T) {
+	parsed, err := ParseHookInput(map[string]interface{}{
+		"session_id":         "session-123",
+		"hook_event_name":    "SubagentStart",
+		"agent_type":         "code-reviewer",
+		"parent_tool_use_id": "toolu_123",
+	})
+	if err != nil {
+		t.Fatalf("ParseHookInput failed: %v", err)
+	}
+
+	start, ok := parsed.(*SubagentStartHookInput)
+	if !ok {
+		t.Fatalf("expected *SubagentStartHookInput, got %T", parsed)
+	}
+	if start.SessionID != "session-123" || start.AgentType != "code-reviewer" {
+		t.Errorf("unexpected input: %+v", start)
+	}
+	if start.ParentToolUseID == nil || *start.ParentToolUseID != "toolu_123" {
+		t.Errorf("expected parent tool use ID toolu_123, got %v", start.ParentToolUseID)
+	}
+
+	parsed, err = ParseHookInput(map[string]interface{}{
+		"hook_event_name":  "SubagentStop",
+		"stop_hook_active": true,
+		"agent_type":       "code-reviewer",
+	})
+	if err != nil {
+		t.Fatalf("ParseHookInput failed: %v", err)
+	}
+	if stop, ok := parsed.(*SubagentStopHookInput); !ok || !stop.StopHookActive || stop.AgentType != "code-reviewer" {
+		t.Errorf("unexpected SubagentStop input: %+v", parsed)
+	}
+
+	// Payloads as sent by the CLI
+	var rawStart map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"session_id":"8f14e45f","transcript_path":"/home/dev/.claude/projects/-repo/8f14e45f.jsonl","cwd":"/repo","permission_mode":"default","hook_event_name":"SubagentStart","agent_id":"a3f9c2e1","agent_type":"code-reviewer"}`), &rawStart); err != nil {
+		t.Fatal(err)
+	}
+	if parsed, err := ParseHookInput(rawStart); err != nil {
+		t.Errorf("ParseHookInput failed: %v", err)
+	} else if start, ok := parsed.(*SubagentStartHookInput); !ok || start.AgentType != "code-reviewer" || start.AgentID != "a3f9c2e1" {
+		t.Errorf("unexpected SubagentStart input from the CLI: %+v", parsed)
+	}
+	var rawStop map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"session_id":"8f14e45f","transcript_path":"/home/dev/.claude/projects/-repo/8f14e45f.jsonl","cwd":"/repo","permission_mode":"default","hook_event_name":"SubagentStop","stop_hook_active":false,"agent_id":"a3f9c2e1","agent_transcript_path":"/home/dev/.claude/projects/-repo/8f14e45f/subagents/agent-a3f9c2e1.jsonl","agent_type":"code-reviewer"}`), &rawStop); err != nil {
+		t.Fatal(err)
+	}
+	if parsed, err := ParseHookInput(rawStop); err != nil {
+		t.Errorf("ParseHookInput failed: %v", err)
+	} else if stop, ok := parsed.(*SubagentStopHookInput); !ok || stop.AgentType != "code-reviewer" || stop.AgentTranscriptPath == "" {
+		t.Errorf("unexpected SubagentStop input from the CLI: %+v", parsed)
+	}
+
+	unknown := map[string]interface{}{"hook_event_name": "Custom"}
+	if parsed, _ := ParseHookInput(unknown); parsed == nil {
+		t.Error("expected unknown events to be returned unchanged")
+	}
+	if _, err := ParseHookInput(map[string]interface{}{"hook_event_name": "PreToolUse", "tool_name": 42}); !IsControlProtocolError(err) {
+		t.Errorf("expected ControlProtocolError for malformed input, got %v", err)
+	}
+}
+
 // Helper function to create a string pointer.
 func stringPtr(s string) *string {
 	return &s
 }
+
+// TestPermissionHandlerFunc tests adapting a typed permission handler to CanUseToolFunc.
+func TestPermissionHandlerFunc(t *testing.T) {
+	var received ToolPermissionRequest
+	handler := PermissionHandlerFunc(func(ctx context.Context, req ToolPermissionRequest) (PermissionResult, error) {
+		received = req
+		if req.BlockedPath != nil {
+			return PermissionResultDeny{Behavior: "deny", Message: "blocked"}, nil
+		}
+		return &PermissionResultAllow{Behavior: "allow"}, nil
+	})
+
+	blocked := "/etc"
+	parent := "toolu_task"
+	result, err := handler.CanUseTool()(context.Background(), "Write", map[string]interface{}{"file_path": "/etc/x"}, ToolPermissionContext{
+		BlockedPath:     &blocked,
+		ParentToolUseID: &parent,
+	})
+	if err != nil {
+		t.Fatalf("CanUseTool failed: %v", err)
+	}
+	if _, ok := result.(PermissionResultDeny); !ok {
+		t.Errorf("expected deny result, got %T", result)
+	}
+	if received.ToolName != "Write" || received.Input["file_path"] != "/etc/x" || *received.ParentToolUseID != "toolu_task" {
+		t.Errorf("unexpected request: %+v", received)
+	}
+
+	result, _ = handler.CanUseTool()(context.Background(), "Read", map[string]interface{}{}, ToolPermissionContext{})
+	if _, ok := result.(*PermissionResultAllow); !ok {
+		t.Errorf("expected allow result, got %T", result)
+	}
+
+	var empty PermissionHandlerFunc = func(ctx context.Context, req ToolPermissionRequest) (PermissionResult, error) {
+		return nil, nil
+	}
+	if _, err := empty.CanUseTool()(context.Background(), "Read", nil, ToolPermissionContext{}); !IsControlProtocolError(err) {
+		t.Errorf("expected ControlProtocolError for nil result, got %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package types
+
+import "context"
+
+// correlationIDKey is the context key of the correlation ID of a query.
+type correlationIDKey struct{}
+
+// ContextWithCorrelationID returns a context that carries id, a
+// caller-supplied identifier stitching the activity of a query into the
+// caller's request traces. Pass it to Query or Client.Query: the SDK's log
+// lines get a correlation_id field while the query runs, and its lifecycle
+// events, metrics, hook executions and result carry the ID. Hooks, tool
+// handlers and permission callbacks receive it in their context:
+//
+//	ctx = types.ContextWithCorrelationID(ctx, r.Header.Get("X-Request-ID"))
+//	err := client.Query(ctx, prompt)
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFrom returns the correlation ID carried by ctx, or "".
+func CorrelationIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,49 @@
+package types
+
+import "strings"
+
+// RedactedCredential replaces credentials in text written by the SDK: the
+// values set with WithAPIKey and WithOAuthToken, secret environment
+// variables and proxy passwords. It is RedactionPlaceholder("credential").
+const RedactedCredential = "[REDACTED:credential]"
+
+// CredentialEnv returns the environment variables passing the credentials
+// set with WithAPIKey and WithOAuthToken to the CLI.
+func (o *ClaudeAgentOptions) CredentialEnv() map[string]string {
+	env := make(map[string]string, 2)
+	if o.APIKey != nil && *o.APIKey != "" {
+		env["ANTHROPIC_API_KEY"] = *o.APIKey
+	}
+	if o.OAuthToken != nil && *o.OAuthToken != "" {
+		env["CLAUDE_CODE_OAUTH_TOKEN"] = *o.OAuthToken
+	}
+	return env
+}
+
+// RedactCredentials returns s with the credentials set with WithAPIKey and
+// WithOAuthToken replaced by RedactedCredential.
+func (o *ClaudeAgentOptions) RedactCredentials(s string) string {
+	if o == nil {
+		return s
+	}
+	for _, secret := range o.CredentialEnv() {
+		s = strings.ReplaceAll(s, secret, RedactedCredential)
+	}
+	return s
+}
+
+// secretEnvMarkers mark the names of environment variables holding secrets.
+var secretEnvMarkers = []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "CREDENTIAL"}
+
+// RedactEnvValue returns the value of an environment variable for logging:
+// RedactedCredential if the name suggests a secret, such as
+// ANTHROPIC_API_KEY, and the value otherwise.
+func RedactEnvValue(key, value string) string {
+	upper := strings.ToUpper(key)
+	for _, marker := range secretEnvMarkers {
+		if strings.Contains(upper, marker) {
+			return RedactedCredential
+		}
+	}
+	return value
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestCredentials tests passing credentials to the CLI without exposing them
+func TestCredentials(t *testing.T) {
+	const key, token = "sk-ant-api03-secret", "sk-ant-oat01-secret"
+	opts := NewClaudeAgentOptions().WithAPIKey(key).WithOAuthToken(token)
+
+	env := opts.CredentialEnv()
+	if env["ANTHROPIC_API_KEY"] != key || env["CLAUDE_CODE_OAUTH_TOKEN"] != token {
+		t.Errorf("CredentialEnv() = %v", env)
+	}
+	if got := opts.RedactCredentials("auth failed for " + key + " and " + token); strings.Contains(got, "secret") {
+		t.Errorf("RedactCredentials() = %q, want credentials redacted", got)
+	}
+	data, err := json.Marshal(opts)
+	if err != nil || strings.Contains(string(data), "secret") {
+		t.Errorf("json.Marshal(opts) = %s, %v, want no credentials", data, err)
+	}
+	if len(NewClaudeAgentOptions().CredentialEnv()) != 0 {
+		t.Error("CredentialEnv() without credentials is not empty")
+	}
+}
+
+// TestRedactEnvValue tests masking environment variables that hold secrets
+func TestRedactEnvValue(t *testing.T) {
+	if got := RedactEnvValue("AWS_SECRET_ACCESS_KEY", "abc"); got != RedactedCredential {
+		t.Errorf("RedactEnvValue(AWS_SECRET_ACCESS_KEY) = %q", got)
+	}
+	if got := RedactEnvValue("STAGE", "prod"); got != "prod" {
+		t.Errorf("RedactEnvValue(STAGE) = %q, want prod", got)
+	}
+}
@@ -0,0 +1,18 @@
+package types
+
+import "time"
+
+// DebugMessage summarizes a message of a session, for the debug server.
+type DebugMessage struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Summary string    `json:"summary,omitempty"` // Text, tool names or the outcome of a result, truncated
+}
+
+// QueueDepths are the backlogs of a session, for the debug server.
+type QueueDepths struct {
+	Messages        int `json:"messages"`         // Messages received and not yet read by the application
+	MessageCapacity int `json:"message_capacity"` // Size of the message buffer, see WithMessageChannelCapacity
+	ControlRequests int `json:"control_requests"` // Control requests awaiting the CLI's response
+	Queries         int `json:"queries"`          // Queries sent and awaiting their result
+}
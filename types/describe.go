@@ -0,0 +1,298 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ConfigSource tells where the value of a setting in a ConfigReport comes
+// from.
+type ConfigSource string
+
+const (
+	ConfigSourceOption      ConfigSource = "option"      // Set in the options
+	ConfigSourceEnvironment ConfigSource = "environment" // Read by the CLI from an environment variable
+	ConfigSourceDefault     ConfigSource = "default"     // Neither set nor in the environment
+)
+
+// ConfigSetting is one setting of a ConfigReport.
+type ConfigSetting struct {
+	Name   string       `json:"name"` // The ClaudeAgentOptions field
+	Value  interface{}  `json:"value"`
+	Source ConfigSource `json:"source"`
+	EnvVar string       `json:"env_var,omitempty"` // The variable of ConfigSourceEnvironment
+}
+
+// ConfigReport is the effective configuration of options, as returned by
+// Describe, with credentials and secret environment values redacted. It
+// encodes to JSON for audit logs, and String formats it for bug reports.
+type ConfigReport struct {
+	Settings []ConfigSetting `json:"settings"`
+	Args     []string        `json:"args"` // CLI flags
+	Env      []string        `json:"env"`  // Environment variables set by the SDK for the CLI, as KEY=value
+}
+
+// CommandLineFunc returns the CLI flags and the "KEY=value" environment
+// variables the SDK would start the CLI with for options.
+type CommandLineFunc func(o *ClaudeAgentOptions) (args, env []string)
+
+var (
+	commandLineMu sync.RWMutex
+	commandLine   CommandLineFunc
+)
+
+// RegisterCommandLine sets the function Describe reports the CLI flags and
+// environment with. The SDK's transport registers itself when the claude
+// package is imported, so applications do not need to call it.
+func RegisterCommandLine(fn CommandLineFunc) {
+	commandLineMu.Lock()
+	defer commandLineMu.Unlock()
+	commandLine = fn
+}
+
+// settingEnvVars are the environment variables the CLI reads settings from
+// when the options leave them unset.
+var settingEnvVars = map[string][]string{
+	"Model":      {"ANTHROPIC_MODEL"},
+	"BaseURL":    {"ANTHROPIC_BASE_URL"},
+	"Proxy":      {"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"},
+	"Provider":   {"CLAUDE_CODE_USE_BEDROCK", "CLAUDE_CODE_USE_VERTEX"},
+	"APIKey":     {"ANTHROPIC_API_KEY"},
+	"OAuthToken": {"CLAUDE_CODE_OAUTH_TOKEN"},
+}
+
+// settingDefaults are the values of settings left unset and not in the
+// environment, where the SDK knows them.
+var settingDefaults = map[string]func() interface{}{
+	"PermissionMode": func() interface{} { return PermissionModeDefault },
+	"SystemPrompt":   func() interface{} { return "" }, // No preset
+	"CWD": func() interface{} {
+		cwd, _ := os.Getwd()
+		return cwd
+	},
+}
+
+// Describe returns the effective configuration of the options: every
+// setting with the value in effect and where it comes from, including
+// defaults and values the CLI reads from the environment, and the flags and
+// environment variables the CLI would be started with. Credentials and the
+// values of environment variables and extra flags named like secrets are
+// redacted, so the report can be attached to bug reports. Callbacks and
+// other values that cannot be shown are reported as "set".
+//
+// Example:
+//
+//	log.Printf("agent configuration:\n%s", opts.Describe())
+func (o *ClaudeAgentOptions) Describe() ConfigReport {
+	if o == nil {
+		o = &ClaudeAgentOptions{}
+	}
+	report := ConfigReport{}
+
+	v := reflect.ValueOf(o).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		field := v.Field(i)
+		if !field.IsZero() {
+			report.Settings = append(report.Settings, ConfigSetting{
+				Name: name, Value: o.describeValue(name, field), Source: ConfigSourceOption,
+			})
+			continue
+		}
+		if key, value, ok := o.lookupSettingEnv(name); ok {
+			report.Settings = append(report.Settings, ConfigSetting{
+				Name: name, Value: redactSettingValue(key, value), Source: ConfigSourceEnvironment, EnvVar: key,
+			})
+			continue
+		}
+		if def, ok := settingDefaults[name]; ok {
+			report.Settings = append(report.Settings, ConfigSetting{
+				Name: name, Value: def(), Source: ConfigSourceDefault,
+			})
+		}
+	}
+
+	commandLineMu.RLock()
+	fn := commandLine
+	commandLineMu.RUnlock()
+	if fn != nil {
+		args, env := fn(o)
+		report.Args = o.redactArgs(args)
+		report.Env = o.redactEnv(env)
+	}
+	return report
+}
+
+// lookupSettingEnv returns the environment variable a setting is read from,
+// looking in Env before the SDK's own environment, as the CLI inherits
+// both.
+func (o *ClaudeAgentOptions) lookupSettingEnv(name string) (key, value string, ok bool) {
+	for _, key := range settingEnvVars[name] {
+		if value, ok := o.Env[key]; ok && value != "" {
+			return key, value, true
+		}
+		if value := os.Getenv(key); value != "" {
+			return key, value, true
+		}
+	}
+	return "", "", false
+}
+
+// describeValue returns the value of a set options field for a report.
+func (o *ClaudeAgentOptions) describeValue(name string, v reflect.Value) interface{} {
+	switch name {
+	case "APIKey", "OAuthToken":
+		return RedactedCredential
+	case "Caller":
+		return "set"
+	case "Proxy":
+		return redactProxyPassword(*o.Proxy)
+	case "Env":
+		env := make(map[string]string, len(o.Env))
+		for key, value := range o.Env {
+			env[key] = o.RedactCredentials(RedactEnvValue(key, value))
+		}
+		return env
+	case "ExtraArgs":
+		extra := make(map[string]interface{}, len(o.ExtraArgs))
+		for flag, value := range o.ExtraArgs {
+			if value == nil {
+				extra[flag] = true
+			} else {
+				extra[flag] = o.RedactCredentials(RedactEnvValue(flag, *value))
+			}
+		}
+		return extra
+	case "TokenAlerts":
+		thresholds := make([]int, len(o.TokenAlerts))
+		for i, alert := range o.TokenAlerts {
+			thresholds[i] = alert.Threshold
+		}
+		return thresholds
+	case "McpServers":
+		if servers, ok := o.McpServers.(map[string]interface{}); ok {
+			return sortedKeys(reflect.ValueOf(servers))
+		}
+	}
+	return describeReflectValue(v)
+}
+
+// describeReflectValue returns a value for a report: the value itself for
+// data, the sorted keys of maps of structures such as Hooks and Agents, and
+// "set" for functions and stateful helpers such as ToolRateLimiter.
+func describeReflectValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Func:
+		return "set"
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			return "set"
+		}
+		return describeReflectValue(v.Elem())
+	case reflect.Ptr:
+		if v.Elem().Kind() != reflect.Struct {
+			return v.Elem().Interface()
+		}
+		switch v.Elem().Interface().(type) {
+		case ProviderConfig, ToolsPreset, SystemPromptPreset:
+			return v.Elem().Interface()
+		}
+		return "set"
+	case reflect.Map:
+		switch v.Type().Elem().Kind() {
+		case reflect.Slice, reflect.Struct, reflect.Ptr, reflect.Func:
+			return sortedKeys(v)
+		}
+	}
+	return v.Interface()
+}
+
+// sortedKeys returns the keys of a map as sorted strings.
+func sortedKeys(m reflect.Value) []string {
+	keys := make([]string, 0, m.Len())
+	for _, key := range m.MapKeys() {
+		keys = append(keys, fmt.Sprint(key.Interface()))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// redactArgs returns CLI flags with credentials, and the values of extra
+// flags named like secrets, redacted.
+func (o *ClaudeAgentOptions) redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = o.RedactCredentials(arg)
+		if i > 0 && strings.HasPrefix(args[i-1], "--") {
+			flag := strings.TrimPrefix(args[i-1], "--")
+			if value, ok := o.ExtraArgs[flag]; ok && value != nil && *value == arg {
+				redacted[i] = RedactEnvValue(flag, redacted[i])
+			}
+		}
+	}
+	return redacted
+}
+
+// redactEnv returns sorted "KEY=value" environment variables with secret
+// values redacted.
+func (o *ClaudeAgentOptions) redactEnv(env []string) []string {
+	redacted := make([]string, len(env))
+	for i, entry := range env {
+		key, value, _ := strings.Cut(entry, "=")
+		redacted[i] = key + "=" + o.RedactCredentials(redactSettingValue(key, value))
+	}
+	sort.Strings(redacted)
+	return redacted
+}
+
+// redactSettingValue returns the value of an environment variable for a
+// report, redacting secrets and the passwords of proxy URLs.
+func redactSettingValue(key, value string) string {
+	if strings.Contains(strings.ToUpper(key), "PROXY") {
+		return redactProxyPassword(value)
+	}
+	return RedactEnvValue(key, value)
+}
+
+// redactProxyPassword returns a proxy URL with its password, if any,
+// replaced by RedactedCredential.
+func redactProxyPassword(proxyURL string) string {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return proxyURL
+	}
+	password, ok := u.User.Password()
+	if !ok || password == "" {
+		return proxyURL
+	}
+	// Redacted writes the password as "xxxxx"
+	return strings.Replace(u.Redacted(), ":xxxxx@", ":"+RedactedCredential+"@", 1)
+}
+
+// String formats the report as indented text, one setting, flag or
+// environment variable per line.
+func (r ConfigReport) String() string {
+	var b strings.Builder
+	b.WriteString("Settings:\n")
+	for _, s := range r.Settings {
+		fmt.Fprintf(&b, "  %s: %v (%s", s.Name, s.Value, s.Source)
+		if s.EnvVar != "" {
+			fmt.Fprintf(&b, " %s", s.EnvVar)
+		}
+		b.WriteString(")\n")
+	}
+	b.WriteString("CLI flags:\n")
+	for _, arg := range r.Args {
+		fmt.Fprintf(&b, "  %s\n", arg)
+	}
+	b.WriteString("CLI environment:\n")
+	for _, entry := range r.Env {
+		fmt.Fprintf(&b, "  %s\n", entry)
+	}
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package types
+
+// DiagnosticSeverity is how serious a Diagnostic is.
+type DiagnosticSeverity string
+
+const (
+	DiagnosticInfo    DiagnosticSeverity = "info"    // Worth knowing, such as an available update
+	DiagnosticWarning DiagnosticSeverity = "warning" // The session works, possibly degraded
+	DiagnosticError   DiagnosticSeverity = "error"   // An operation failed
+	DiagnosticFatal   DiagnosticSeverity = "fatal"   // The CLI cannot continue
+)
+
+// DiagnosticKind is what a Diagnostic is about.
+type DiagnosticKind string
+
+const (
+	DiagnosticAuthFailure       DiagnosticKind = "auth_failure"        // Invalid or expired credentials
+	DiagnosticVersionWarning    DiagnosticKind = "version_warning"     // Outdated or unsupported versions
+	DiagnosticNodeError         DiagnosticKind = "node_error"          // Errors of the Node.js runtime running the CLI
+	DiagnosticMCPStartupFailure DiagnosticKind = "mcp_startup_failure" // An MCP server that failed to start or connect
+	DiagnosticSessionNotFound   DiagnosticKind = "session_not_found"   // The session to resume does not exist
+	DiagnosticCLIError          DiagnosticKind = "cli_error"           // Other errors
+	DiagnosticCLIWarning        DiagnosticKind = "cli_warning"         // Other warnings
+)
+
+// Diagnostic is a known pattern parsed from a line the CLI wrote to stderr,
+// reported to the DiagnosticHandler of the options.
+type Diagnostic struct {
+	Kind      DiagnosticKind
+	Severity  DiagnosticSeverity
+	Line      string // Redacted of credentials
+	MCPServer string // The server of a DiagnosticMCPStartupFailure, if the line names it
+	Err       error  // The typed error, such as *SessionNotFoundError, or nil
+
+	CorrelationID string // Of the query running when the line was written, see ContextWithCorrelationID
+}
+
+// DiagnosticHandler receives the diagnostics parsed from the CLI's stderr.
+// It is called from the SDK's goroutines and must not block or panic.
+type DiagnosticHandler func(diagnostic Diagnostic)
+
+// Notify reports a diagnostic to the handler, if not nil.
+func (h DiagnosticHandler) Notify(diagnostic Diagnostic) {
+	if h != nil {
+		h(diagnostic)
+	}
+}
+
+// NotifyDiagnostic reports a diagnostic to the DiagnosticHandler, if any.
+func (o *ClaudeAgentOptions) NotifyDiagnostic(diagnostic Diagnostic) {
+	if o != nil {
+		o.DiagnosticHandler.Notify(diagnostic)
+	}
+}
@@ -12,6 +12,8 @@
 //   - SystemMessage: System notifications and metadata
 //   - ResultMessage: Final result with cost/usage info
 //   - StreamEvent: Partial message updates during streaming
+//   - AssistantErrorEvent: Suggested action after an assistant error
+//   - CompactionEvent: Context compaction by the CLI
 //
 // Example:
 //
@@ -34,8 +36,12 @@
 //
 //   - TextBlock: Plain text content
 //   - ThinkingBlock: Claude's internal reasoning
+//   - RedactedThinkingBlock: Reasoning encrypted by safety systems
 //   - ToolUseBlock: Tool invocation requests
 //   - ToolResultBlock: Results from tool execution
+//   - ImageBlock: Images in messages and tool results
+//   - DocumentBlock: PDF and text attachments
+//   - ServerToolUseBlock, ServerToolResultBlock: Tools the API runs, such as web search
 //
 // # Error Types
 //
@@ -85,6 +91,7 @@
 //   - HookEventPostToolUse: After tool execution
 //   - HookEventUserPromptSubmit: When user submits a prompt
 //   - HookEventStop: When session stops
+//   - HookEventSubagentStart: When a subagent (Task tool) starts
 //   - HookEventSubagentStop: When a subagent stops
 //   - HookEventPreCompact: Before context compaction
 //
@@ -93,7 +100,11 @@
 //	opts.WithHook(types.HookEventPreToolUse, types.HookMatcher{
 //	    Hooks: []types.HookCallbackFunc{
 //	        func(ctx context.Context, input interface{}, toolUseID *string, hookCtx types.HookContext) (interface{}, error) {
-//	            preToolInput := input.(*types.PreToolUseHookInput)
+//	            parsed, err := types.ParseHookInput(input)
+//	            if err != nil {
+//	                return nil, err
+//	            }
+//	            preToolInput := parsed.(*types.PreToolUseHookInput)
 //	            log.Printf("Tool %s about to execute", preToolInput.ToolName)
 //	            return &types.SyncHookJSONOutput{}, nil
 //	        },
@@ -0,0 +1,112 @@
+package types
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Document source types of a DocumentSource.
+const (
+	DocumentSourceBase64 = "base64" // Base64-encoded PDF
+	DocumentSourceText   = "text"   // Plain text
+	DocumentSourceURL    = "url"    // PDF fetched by the API
+)
+
+// DocumentSource holds the contents of a document.
+type DocumentSource struct {
+	Type      string `json:"type"`                 // DocumentSourceBase64, DocumentSourceText or DocumentSourceURL
+	MediaType string `json:"media_type,omitempty"` // "application/pdf" or "text/plain"
+	Data      string `json:"data,omitempty"`       // Base64-encoded PDF, or the text of a text document
+	URL       string `json:"url,omitempty"`
+}
+
+// DocumentCitations configures citations of a document.
+type DocumentCitations struct {
+	Enabled bool `json:"enabled"`
+}
+
+// DocumentBlock is a document attached to a message, such as a PDF or a
+// text file.
+type DocumentBlock struct {
+	Type      string             `json:"type"` // Always "document"
+	Source    DocumentSource     `json:"source"`
+	Title     string             `json:"title,omitempty"`
+	Context   string             `json:"context,omitempty"` // Information about the document that is not part of it
+	Citations *DocumentCitations `json:"citations,omitempty"`
+}
+
+// GetType returns the type of the content block.
+func (b DocumentBlock) GetType() string {
+	return b.Type
+}
+
+func (b DocumentBlock) isContentBlock() {}
+
+// NewPDFDocumentBlock returns a document block holding a PDF.
+func NewPDFDocumentBlock(pdf []byte) DocumentBlock {
+	return DocumentBlock{
+		Type: "document",
+		Source: DocumentSource{
+			Type:      DocumentSourceBase64,
+			MediaType: "application/pdf",
+			Data:      base64.StdEncoding.EncodeToString(pdf),
+		},
+	}
+}
+
+// NewTextDocumentBlock returns a document block holding plain text.
+func NewTextDocumentBlock(text string) DocumentBlock {
+	return DocumentBlock{
+		Type: "document",
+		Source: DocumentSource{
+			Type:      DocumentSourceText,
+			MediaType: "text/plain",
+			Data:      text,
+		},
+	}
+}
+
+// NewURLDocumentBlock returns a document block referring to the PDF at url.
+func NewURLDocumentBlock(url string) DocumentBlock {
+	return DocumentBlock{
+		Type:   "document",
+		Source: DocumentSource{Type: DocumentSourceURL, URL: url},
+	}
+}
+
+// NewDocumentBlockFromFile returns a document block holding the file at
+// path, titled with the file's name. PDF files are attached as PDFs and
+// UTF-8 text files as text; other files return an error.
+//
+// Example:
+//
+//	doc, err := types.NewDocumentBlockFromFile("reports/q3.pdf")
+//	...
+//	err = client.QueryWithContent(ctx, []types.ContentBlock{
+//	    doc,
+//	    types.TextBlock{Type: "text", Text: "Summarize this report."},
+//	})
+func NewDocumentBlockFromFile(path string) (DocumentBlock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return DocumentBlock{}, fmt.Errorf("read document: %w", err)
+	}
+
+	var block DocumentBlock
+	contentType := http.DetectContentType(data)
+	switch {
+	case contentType == "application/pdf" || strings.EqualFold(filepath.Ext(path), ".pdf"):
+		block = NewPDFDocumentBlock(data)
+	case utf8.Valid(data):
+		block = NewTextDocumentBlock(string(data))
+	default:
+		return DocumentBlock{}, fmt.Errorf("document %s is neither a PDF nor UTF-8 text (detected %s)", path, contentType)
+	}
+	block.Title = filepath.Base(path)
+	return block, nil
+}
@@ -0,0 +1,92 @@
+package types
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDocumentBlockJSON tests encoding and decoding document blocks.
+func TestDocumentBlockJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		block DocumentBlock
+		want  string
+	}{
+		{
+			name:  "pdf",
+			block: NewPDFDocumentBlock([]byte("%PDF-1.4")),
+			want:  `{"type":"document","source":{"type":"base64","media_type":"application/pdf","data":"JVBERi0xLjQ="}}`,
+		},
+		{
+			name:  "text",
+			block: NewTextDocumentBlock("Hello"),
+			want:  `{"type":"document","source":{"type":"text","media_type":"text/plain","data":"Hello"}}`,
+		},
+		{
+			name: "url with citations",
+			block: func() DocumentBlock {
+				block := NewURLDocumentBlock("https://example.com/paper.pdf")
+				block.Title = "Paper"
+				block.Citations = &DocumentCitations{Enabled: true}
+				return block
+			}(),
+			want: `{"type":"document","source":{"type":"url","url":"https://example.com/paper.pdf"},"title":"Paper","citations":{"enabled":true}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.block)
+			if err != nil {
+				t.Fatalf("Marshal() error: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got %s, want %s", data, tt.want)
+			}
+			block, err := UnmarshalContentBlock(data)
+			if err != nil {
+				t.Fatalf("UnmarshalContentBlock() error: %v", err)
+			}
+			doc, ok := block.(*DocumentBlock)
+			if !ok || doc.Source != tt.block.Source || doc.Title != tt.block.Title {
+				t.Errorf("round trip got %+v", block)
+			}
+		})
+	}
+}
+
+// TestNewDocumentBlockFromFile tests building document blocks from files.
+func TestNewDocumentBlockFromFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	pdf, err := NewDocumentBlockFromFile(write("report.pdf", []byte("%PDF-1.7\n")))
+	if err != nil {
+		t.Fatalf("NewDocumentBlockFromFile() error: %v", err)
+	}
+	if pdf.Source.Type != DocumentSourceBase64 || pdf.Source.MediaType != "application/pdf" || pdf.Title != "report.pdf" {
+		t.Errorf("unexpected pdf block: %+v", pdf)
+	}
+
+	text, err := NewDocumentBlockFromFile(write("notes.md", []byte("# Notes\n")))
+	if err != nil {
+		t.Fatalf("NewDocumentBlockFromFile() error: %v", err)
+	}
+	if text.Source.Type != DocumentSourceText || text.Source.Data != "# Notes\n" || text.Title != "notes.md" {
+		t.Errorf("unexpected text block: %+v", text)
+	}
+
+	if _, err := NewDocumentBlockFromFile(write("image.bin", []byte{0xff, 0xfe, 0x00, 0x80})); err == nil {
+		t.Error("expected an error for a binary file")
+	}
+	if _, err := NewDocumentBlockFromFile(filepath.Join(dir, "missing.pdf")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,196 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDryRunTools lists the tools a dry run intercepts by default.
+var DefaultDryRunTools = []string{"Write", "Edit", "MultiEdit", "NotebookEdit", "Bash"}
+
+// PlannedChange is a tool call that a dry run intercepted instead of running.
+type PlannedChange struct {
+	Time      time.Time              `json:"time"`
+	ToolUseID string                 `json:"tool_use_id,omitempty"`
+	ToolName  string                 `json:"tool_name"`
+	Input     map[string]interface{} `json:"input"`
+	FilePath  string                 `json:"file_path,omitempty"` // File the call would change
+	Command   string                 `json:"command,omitempty"`   // Bash command the call would run
+	Summary   string                 `json:"summary"`
+}
+
+// DryRun previews an agent run without changing anything. A PreToolUse hook
+// intercepts the tools matching Tools, records what each call would have
+// done, and tells Claude the call was simulated so it carries on as if it
+// succeeded. Read-only tools run normally, so Claude still sees real files.
+//
+// Example:
+//
+//	opts := types.NewClaudeAgentOptions().
+//	    WithDryRun(true).
+//	    WithDryRunTools("mcp__github__create_issue")
+//
+//	// After the run:
+//	fmt.Print(opts.DryRun.Report())
+type DryRun struct {
+	// Tools lists the tool patterns to intercept, such as "Bash" or "mcp__github__*".
+	Tools []string
+
+	mu      sync.Mutex
+	changes []PlannedChange
+}
+
+// NewDryRun creates a DryRun that intercepts DefaultDryRunTools plus tools.
+func NewDryRun(tools ...string) *DryRun {
+	return &DryRun{Tools: append(append([]string{}, DefaultDryRunTools...), tools...)}
+}
+
+// Intercepts reports whether the dry run intercepts a tool call.
+func (d *DryRun) Intercepts(toolName string, input map[string]interface{}) bool {
+	for _, rule := range d.Tools {
+		if matchToolRule(rule, toolName, input) {
+			return true
+		}
+	}
+	return false
+}
+
+// Record adds a tool call to the change plan and returns the planned change.
+func (d *DryRun) Record(toolUseID, toolName string, input map[string]interface{}) PlannedChange {
+	change := PlannedChange{
+		Time:      time.Now(),
+		ToolUseID: toolUseID,
+		ToolName:  toolName,
+		Input:     input,
+		Summary:   summarizeToolCall(toolName, input),
+	}
+	if toolName == "Bash" {
+		change.Command, _ = input["command"].(string)
+	} else {
+		for _, key := range []string{"file_path", "notebook_path"} {
+			if path, ok := input[key].(string); ok {
+				change.FilePath = path
+				break
+			}
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.changes = append(d.changes, change)
+	return change
+}
+
+// Changes returns the intercepted tool calls in the order they were made.
+func (d *DryRun) Changes() []PlannedChange {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]PlannedChange(nil), d.changes...)
+}
+
+// Files returns the files the run would have changed, in first-change order.
+func (d *DryRun) Files() []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, change := range d.Changes() {
+		if change.FilePath != "" && !seen[change.FilePath] {
+			seen[change.FilePath] = true
+			files = append(files, change.FilePath)
+		}
+	}
+	return files
+}
+
+// Report returns the change plan as numbered lines of text.
+func (d *DryRun) Report() string {
+	changes := d.Changes()
+	if len(changes) == 0 {
+		return "Dry run: no changes planned.\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dry run: %d planned change(s)", len(changes))
+	if files := d.Files(); len(files) > 0 {
+		fmt.Fprintf(&b, " to %d file(s)", len(files))
+	}
+	b.WriteString("\n")
+	for i, change := range changes {
+		fmt.Fprintf(&b, "%3d. %s\n", i+1, change.Summary)
+	}
+	return b.String()
+}
+
+// Hook returns a PreToolUse hook matcher that intercepts tool calls.
+// WithDryRun installs it automatically.
+func (d *DryRun) Hook() HookMatcher {
+	return HookMatcher{
+		Hooks: []HookCallbackFunc{func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			raw, _ := input.(map[string]interface{})
+			toolName, _ := raw["tool_name"].(string)
+			toolInput, _ := raw["tool_input"].(map[string]interface{})
+			if !d.Intercepts(toolName, toolInput) {
+				return map[string]interface{}{}, nil
+			}
+
+			id := ""
+			if toolUseID != nil {
+				id = *toolUseID
+			}
+			change := d.Record(id, toolName, toolInput)
+			return DenyToolUse(fmt.Sprintf("Dry run: simulated success. %s was not performed because this session is a dry run; continue as if it succeeded.", change.Summary)), nil
+		}},
+	}
+}
+
+// summarizeToolCall describes what a tool call would do.
+func summarizeToolCall(toolName string, input map[string]interface{}) string {
+	path, _ := input["file_path"].(string)
+	switch toolName {
+	case "Write":
+		content, _ := input["content"].(string)
+		return fmt.Sprintf("Write %s (%d lines, %d bytes)", path, countLines(content), len(content))
+	case "Edit":
+		oldString, _ := input["old_string"].(string)
+		newString, _ := input["new_string"].(string)
+		summary := fmt.Sprintf("Edit %s (replace %d lines with %d lines)", path, countLines(oldString), countLines(newString))
+		if replaceAll, _ := input["replace_all"].(bool); replaceAll {
+			summary += ", all occurrences"
+		}
+		return summary
+	case "MultiEdit":
+		edits, _ := input["edits"].([]interface{})
+		return fmt.Sprintf("MultiEdit %s (%d edits)", path, len(edits))
+	case "NotebookEdit":
+		notebook, _ := input["notebook_path"].(string)
+		mode, _ := input["edit_mode"].(string)
+		if mode == "" {
+			mode = "replace"
+		}
+		return fmt.Sprintf("NotebookEdit %s (%s cell)", notebook, mode)
+	case "Bash":
+		command, _ := input["command"].(string)
+		return "Run: " + command
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "Call " + toolName
+	}
+	return fmt.Sprintf("Call %s %s", toolName, data)
+}
+
+// countLines returns the number of lines in s, counting a final line without a newline.
+func countLines(s string) int {
+	if s == "" {
+		return 0
+	}
+	n := strings.Count(s, "\n")
+	if !strings.HasSuffix(s, "\n") {
+		n++
+	}
+	return n
+}
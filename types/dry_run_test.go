@@ -0,0 +1,80 @@
+package types
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestDryRunHook tests that state-changing tools are intercepted and recorded.
+func TestDryRunHook(t *testing.T) {
+	opts := NewClaudeAgentOptions().WithDryRun(true).WithDryRunTools("mcp__github__create_*")
+	hook := opts.DryRun.Hook().Hooks[0]
+	id := "toolu_1"
+
+	calls := []map[string]interface{}{
+		{"tool_name": "Write", "tool_input": map[string]interface{}{"file_path": "/repo/a.go", "content": "package a\n\nfunc A() {}\n"}},
+		{"tool_name": "Edit", "tool_input": map[string]interface{}{"file_path": "/repo/b.go", "old_string": "x", "new_string": "y\nz", "replace_all": true}},
+		{"tool_name": "Bash", "tool_input": map[string]interface{}{"command": "rm -rf build"}},
+		{"tool_name": "mcp__github__create_issue", "tool_input": map[string]interface{}{"title": "Bug"}},
+	}
+	for _, call := range calls {
+		output, err := hook(context.Background(), call, &id, HookContext{})
+		if err != nil {
+			t.Fatalf("hook failed: %v", err)
+		}
+		specific := hookSpecific(t, output.(*SyncHookJSONOutput))
+		reason, _ := specific["permissionDecisionReason"].(string)
+		if specific["permissionDecision"] != "deny" || !strings.HasPrefix(reason, "Dry run: simulated success.") {
+			t.Errorf("expected %v to be intercepted, got %v", call["tool_name"], specific)
+		}
+	}
+
+	for _, toolName := range []string{"Read", "mcp__github__list_issues"} {
+		output, _ := hook(context.Background(), map[string]interface{}{"tool_name": toolName, "tool_input": map[string]interface{}{}}, &id, HookContext{})
+		if normalized, _ := NormalizeHookOutput(output); len(normalized) != 0 {
+			t.Errorf("expected %s to run normally, got %v", toolName, normalized)
+		}
+	}
+
+	changes := opts.DryRun.Changes()
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 planned changes, got %d", len(changes))
+	}
+	want := []string{
+		"Write /repo/a.go (3 lines, 23 bytes)",
+		"Edit /repo/b.go (replace 1 lines with 2 lines), all occurrences",
+		"Run: rm -rf build",
+		`Call mcp__github__create_issue {"title":"Bug"}`,
+	}
+	for i, change := range changes {
+		if change.Summary != want[i] {
+			t.Errorf("change %d summary = %q, want %q", i, change.Summary, want[i])
+		}
+	}
+	if changes[2].Command != "rm -rf build" || changes[0].ToolUseID != "toolu_1" {
+		t.Errorf("unexpected change details: %+v", changes)
+	}
+	if files := opts.DryRun.Files(); len(files) != 2 || files[0] != "/repo/a.go" {
+		t.Errorf("unexpected files: %v", files)
+	}
+
+	report := opts.DryRun.Report()
+	if !strings.HasPrefix(report, "Dry run: 4 planned change(s) to 2 file(s)\n") || !strings.Contains(report, "  3. Run: rm -rf build\n") {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+}
+
+// TestWithDryRun tests enabling and disabling the dry run.
+func TestWithDryRun(t *testing.T) {
+	opts := NewClaudeAgentOptions().WithDryRun(true)
+	if opts.DryRun == nil || len(opts.DryRun.Tools) != len(DefaultDryRunTools) {
+		t.Fatalf("expected default dry run tools, got %+v", opts.DryRun)
+	}
+	if opts.WithDryRun(false).DryRun != nil {
+		t.Error("expected WithDryRun(false) to disable the dry run")
+	}
+	if report := NewDryRun().Report(); report != "Dry run: no changes planned.\n" {
+		t.Errorf("unexpected empty report: %q", report)
+	}
+}
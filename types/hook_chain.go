@@ -0,0 +1,256 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultHookPriority is the priority used by HookChain.Add.
+const DefaultHookPriority = 100
+
+// chainedHook is a hook callback registered in a HookChain.
+type chainedHook struct {
+	priority int
+	hook     HookCallbackFunc
+}
+
+// HookChain composes hooks for the same event into an ordered middleware chain.
+//
+// Registering several callbacks on a HookMatcher lets the CLI run them
+// independently, so the last writer wins when their outputs disagree. A
+// HookChain is registered as a single callback instead and runs its hooks
+// serially in ascending priority order (ties keep registration order):
+//
+//   - Each hook sees the tool input as rewritten by earlier hooks.
+//   - A hook that denies the tool use, returns decision "block", or sets
+//     continue=false short-circuits the chain; remaining hooks are skipped.
+//   - A hook error aborts the chain and is returned to the CLI.
+//
+// Outputs are merged deterministically:
+//
+//   - continue is false if any hook set it to false; suppressOutput is true if any hook set it.
+//   - stopReason, decision and reason keep the value from the earliest hook that set them.
+//   - systemMessage and additionalContext are joined in execution order.
+//   - permissionDecision keeps the most restrictive decision (deny > ask > allow),
+//     together with that hook's permissionDecisionReason.
+//   - updatedInput is the input produced by the last hook that rewrote it.
+//
+// Example:
+//
+//	chain := types.NewHookChain().
+//	    Match("Bash").
+//	    Use(10, auditHook).
+//	    Use(20, policyHook)
+//	opts.WithHookChain(types.HookEventPreToolUse, chain)
+type HookChain struct {
+	matcher *string
+	hooks   []chainedHook
+}
+
+// NewHookChain creates an empty hook chain that matches all tools.
+func NewHookChain() *HookChain {
+	return &HookChain{}
+}
+
+// Match sets the tool matcher pattern used when the chain is registered.
+func (c *HookChain) Match(pattern string) *HookChain {
+	c.matcher = &pattern
+	return c
+}
+
+// Use adds a hook with an explicit priority. Lower priorities run first.
+func (c *HookChain) Use(priority int, hook HookCallbackFunc) *HookChain {
+	c.hooks = append(c.hooks, chainedHook{priority: priority, hook: hook})
+	return c
+}
+
+// Add adds a hook with DefaultHookPriority.
+func (c *HookChain) Add(hook HookCallbackFunc) *HookChain {
+	return c.Use(DefaultHookPriority, hook)
+}
+
+// Len returns the number of hooks in the chain.
+func (c *HookChain) Len() int {
+	return len(c.hooks)
+}
+
+// Callback returns a single hook callback that runs the chain.
+// The chain is snapshotted; hooks added afterwards are not included.
+func (c *HookChain) Callback() HookCallbackFunc {
+	hooks := make([]chainedHook, len(c.hooks))
+	copy(hooks, c.hooks)
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return hooks[i].priority < hooks[j].priority
+	})
+
+	callbacks := make([]HookCallbackFunc, len(hooks))
+	for i, h := range hooks {
+		callbacks[i] = h.hook
+	}
+	return ComposeHooks(HookExecutionSerial, callbacks)
+}
+
+// Matcher returns a HookMatcher that registers the chain as a single callback.
+func (c *HookChain) Matcher() HookMatcher {
+	return HookMatcher{
+		Matcher: c.matcher,
+		Hooks:   []HookCallbackFunc{c.Callback()},
+	}
+}
+
+// permissionDecisionRank orders permission decisions from least to most restrictive.
+var permissionDecisionRank = map[string]int{
+	"allow": 1,
+	"ask":   2,
+	"deny":  3,
+}
+
+// hookOutputShortCircuits reports whether an output stops the remaining hooks in a chain.
+func hookOutputShortCircuits(output map[string]interface{}) bool {
+	if cont, ok := output["continue"].(bool); ok && !cont {
+		return true
+	}
+	if decision, _ := output["decision"].(string); decision == "block" {
+		return true
+	}
+	if specific, ok := output["hookSpecificOutput"].(map[string]interface{}); ok {
+		if decision, _ := specific["permissionDecision"].(string); decision == "deny" {
+			return true
+		}
+	}
+	return false
+}
+
+// updatedToolInput returns the rewritten tool input from a hook output, if any.
+func updatedToolInput(output map[string]interface{}) map[string]interface{} {
+	specific, ok := output["hookSpecificOutput"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	updated, _ := specific["updatedInput"].(map[string]interface{})
+	return updated
+}
+
+// withToolInput returns a copy of a hook input with its tool input replaced.
+// Inputs that do not carry a tool input are returned unchanged.
+func withToolInput(input interface{}, toolInput map[string]interface{}) interface{} {
+	switch in := input.(type) {
+	case map[string]interface{}:
+		if _, ok := in["tool_input"]; !ok {
+			return input
+		}
+		clone := make(map[string]interface{}, len(in))
+		for k, v := range in {
+			clone[k] = v
+		}
+		clone["tool_input"] = toolInput
+		return clone
+	case *PreToolUseHookInput:
+		clone := *in
+		clone.ToolInput = toolInput
+		return &clone
+	case PreToolUseHookInput:
+		in.ToolInput = toolInput
+		return in
+	default:
+		return input
+	}
+}
+
+// hookOutputMerger accumulates hook outputs according to the HookChain merge rules.
+type hookOutputMerger struct {
+	output       map[string]interface{}
+	specific     map[string]interface{}
+	messages     []string
+	contexts     []string
+	decisionRank int
+}
+
+func newHookOutputMerger() *hookOutputMerger {
+	return &hookOutputMerger{
+		output:   make(map[string]interface{}),
+		specific: make(map[string]interface{}),
+	}
+}
+
+// merge folds a single hook output into the accumulated result.
+func (m *hookOutputMerger) merge(output map[string]interface{}) {
+	for key, value := range output {
+		switch key {
+		case "continue":
+			if cont, ok := value.(bool); ok {
+				if existing, set := m.output[key].(bool); !set || existing {
+					m.output[key] = cont
+				}
+			}
+		case "suppressOutput":
+			if suppress, ok := value.(bool); ok && suppress {
+				m.output[key] = true
+			} else if _, set := m.output[key]; !set {
+				m.output[key] = value
+			}
+		case "systemMessage":
+			if msg, ok := value.(string); ok && msg != "" {
+				m.messages = append(m.messages, msg)
+			}
+		case "hookSpecificOutput":
+			if specific, ok := value.(map[string]interface{}); ok {
+				m.mergeSpecific(specific)
+			}
+		default:
+			if _, set := m.output[key]; !set {
+				m.output[key] = value
+			}
+		}
+	}
+}
+
+// mergeSpecific folds a hookSpecificOutput object into the accumulated result.
+func (m *hookOutputMerger) mergeSpecific(specific map[string]interface{}) {
+	for key, value := range specific {
+		switch key {
+		case "permissionDecision":
+			decision, _ := value.(string)
+			if rank := permissionDecisionRank[decision]; rank > m.decisionRank {
+				m.decisionRank = rank
+				m.specific[key] = decision
+				if reason, ok := specific["permissionDecisionReason"]; ok {
+					m.specific["permissionDecisionReason"] = reason
+				} else {
+					delete(m.specific, "permissionDecisionReason")
+				}
+			}
+		case "permissionDecisionReason":
+			// Handled together with permissionDecision
+			if _, hasDecision := specific["permissionDecision"]; !hasDecision {
+				if _, set := m.specific[key]; !set {
+					m.specific[key] = value
+				}
+			}
+		case "updatedInput":
+			m.specific[key] = value
+		case "additionalContext":
+			if text, ok := value.(string); ok && text != "" {
+				m.contexts = append(m.contexts, text)
+			}
+		default:
+			if _, set := m.specific[key]; !set {
+				m.specific[key] = value
+			}
+		}
+	}
+}
+
+// result returns the merged hook output.
+func (m *hookOutputMerger) result() map[string]interface{} {
+	if len(m.messages) > 0 {
+		m.output["systemMessage"] = strings.Join(m.messages, "\n")
+	}
+	if len(m.contexts) > 0 {
+		m.specific["additionalContext"] = strings.Join(m.contexts, "\n")
+	}
+	if len(m.specific) > 0 {
+		m.output["hookSpecificOutput"] = m.specific
+	}
+	return m.output
+}
@@ -0,0 +1,169 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestHookChainOrdering tests that hooks run in priority order with ties in registration order.
+func TestHookChainOrdering(t *testing.T) {
+	var order []string
+	record := func(name string) HookCallbackFunc {
+		return func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			order = append(order, name)
+			return nil, nil
+		}
+	}
+
+	chain := NewHookChain().
+		Use(20, record("late")).
+		Add(record("default-a")).
+		Use(10, record("early")).
+		Add(record("default-b"))
+
+	if _, err := chain.Callback()(context.Background(), map[string]interface{}{}, nil, HookContext{}); err != nil {
+		t.Fatalf("chain failed: %v", err)
+	}
+
+	expected := []string{"early", "late", "default-a", "default-b"}
+	if len(order) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, order)
+			break
+		}
+	}
+}
+
+// TestHookChainShortCircuit tests that a deny decision skips the remaining hooks.
+func TestHookChainShortCircuit(t *testing.T) {
+	ranLast := false
+	chain := NewHookChain().
+		Use(1, func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			return map[string]interface{}{
+				"hookSpecificOutput": map[string]interface{}{
+					"hookEventName":            "PreToolUse",
+					"permissionDecision":       "deny",
+					"permissionDecisionReason": "blocked",
+				},
+			}, nil
+		}).
+		Use(2, func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			ranLast = true
+			return nil, nil
+		})
+
+	output, err := chain.Callback()(context.Background(), map[string]interface{}{}, nil, HookContext{})
+	if err != nil {
+		t.Fatalf("chain failed: %v", err)
+	}
+	if ranLast {
+		t.Error("expected deny to short-circuit the chain")
+	}
+
+	specific := output.(map[string]interface{})["hookSpecificOutput"].(map[string]interface{})
+	if specific["permissionDecision"] != "deny" || specific["permissionDecisionReason"] != "blocked" {
+		t.Errorf("unexpected hookSpecificOutput: %v", specific)
+	}
+}
+
+// TestHookChainMerge tests deterministic merging of outputs from several hooks.
+func TestHookChainMerge(t *testing.T) {
+	allow := "allow"
+	ask := "ask"
+	chain := NewHookChain().
+		Use(1, func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			cont := true
+			msg := "first"
+			return &SyncHookJSONOutput{
+				Continue:      &cont,
+				SystemMessage: &msg,
+				HookSpecificOutput: &PreToolUseHookSpecificOutput{
+					HookEventName:      "PreToolUse",
+					PermissionDecision: &ask,
+				},
+			}, nil
+		}).
+		Use(2, func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			msg := "second"
+			return &SyncHookJSONOutput{
+				SystemMessage: &msg,
+				HookSpecificOutput: &PreToolUseHookSpecificOutput{
+					HookEventName:      "PreToolUse",
+					PermissionDecision: &allow,
+				},
+			}, nil
+		})
+
+	output, err := chain.Callback()(context.Background(), map[string]interface{}{}, nil, HookContext{})
+	if err != nil {
+		t.Fatalf("chain failed: %v", err)
+	}
+
+	merged := output.(map[string]interface{})
+	if merged["continue"] != true {
+		t.Errorf("expected continue true, got %v", merged["continue"])
+	}
+	if merged["systemMessage"] != "first\nsecond" {
+		t.Errorf("expected joined system messages, got %q", merged["systemMessage"])
+	}
+	specific := merged["hookSpecificOutput"].(map[string]interface{})
+	if specific["permissionDecision"] != "ask" {
+		t.Errorf("expected most restrictive decision ask, got %v", specific["permissionDecision"])
+	}
+}
+
+// TestHookChainUpdatedInput tests that rewritten tool input is passed to later hooks.
+func TestHookChainUpdatedInput(t *testing.T) {
+	var seen interface{}
+	chain := NewHookChain().
+		Use(1, func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			return map[string]interface{}{
+				"hookSpecificOutput": map[string]interface{}{
+					"hookEventName": "PreToolUse",
+					"updatedInput":  map[string]interface{}{"command": "ls -la"},
+				},
+			}, nil
+		}).
+		Use(2, func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			seen = input.(map[string]interface{})["tool_input"].(map[string]interface{})["command"]
+			return nil, nil
+		})
+
+	input := map[string]interface{}{
+		"tool_name":  "Bash",
+		"tool_input": map[string]interface{}{"command": "ls"},
+	}
+	if _, err := chain.Callback()(context.Background(), input, nil, HookContext{}); err != nil {
+		t.Fatalf("chain failed: %v", err)
+	}
+	if seen != "ls -la" {
+		t.Errorf("expected later hook to see updated input, got %v", seen)
+	}
+	if input["tool_input"].(map[string]interface{})["command"] != "ls" {
+		t.Error("expected original input to be left unchanged")
+	}
+}
+
+// TestHookChainError tests that a hook error aborts the chain.
+func TestHookChainError(t *testing.T) {
+	ranLast := false
+	chain := NewHookChain().
+		Use(1, func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			return nil, errors.New("hook failed")
+		}).
+		Use(2, func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			ranLast = true
+			return nil, nil
+		})
+
+	if _, err := chain.Callback()(context.Background(), map[string]interface{}{}, nil, HookContext{}); err == nil {
+		t.Error("expected error from chain")
+	}
+	if ranLast {
+		t.Error("expected error to abort the chain")
+	}
+}
@@ -0,0 +1,202 @@
+package types
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// HookExecutionMode controls how the hooks of several matchers for the same
+// event are run.
+type HookExecutionMode string
+
+const (
+	// HookExecutionDefault registers each hook with the CLI separately.
+	// The CLI runs them independently and the last writer wins on conflicts.
+	HookExecutionDefault HookExecutionMode = ""
+
+	// HookExecutionSerial runs the matching hooks one after another in
+	// registration order, with HookChain semantics: each hook sees the tool
+	// input or prompt rewritten by earlier hooks, and a deny, block or
+	// continue=false skips the remaining hooks.
+	HookExecutionSerial HookExecutionMode = "serial"
+
+	// HookExecutionParallel runs the matching hooks concurrently, each on its
+	// own deep copy of the input. All hooks run to completion. Changes to the
+	// tool input, returned as updatedInput or made in place, are merged key
+	// by key in registration order, so later hooks win on conflicting keys.
+	HookExecutionParallel HookExecutionMode = "parallel"
+)
+
+// IsValid reports whether the mode is a known execution mode.
+func (m HookExecutionMode) IsValid() bool {
+	switch m {
+	case HookExecutionDefault, HookExecutionSerial, HookExecutionParallel:
+		return true
+	}
+	return false
+}
+
+// ComposeHooks combines hooks into a single callback that runs them according
+// to mode. In both modes the outputs are merged with the HookChain rules, in
+// registration order, so conflicting permission decisions resolve to the most
+// restrictive one (deny > ask > allow). A hook error fails the combined
+// callback; in parallel mode the error of the earliest registered hook is
+// returned. Hooks returning nil contribute nothing.
+//
+// HookExecutionDefault has no combined form and is treated as serial.
+func ComposeHooks(mode HookExecutionMode, hooks []HookCallbackFunc) HookCallbackFunc {
+	hooks = append([]HookCallbackFunc(nil), hooks...)
+	run := runHooksSerial
+	if mode == HookExecutionParallel {
+		run = runHooksParallel
+	}
+
+	return func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+		output, err := run(ctx, hooks, input, toolUseID, hookCtx)
+		if err != nil {
+			return nil, err
+		}
+		return output, nil
+	}
+}
+
+// runHooksSerial runs hooks in order, threading rewritten inputs and stopping
+// at the first short-circuiting output.
+func runHooksSerial(ctx context.Context, hooks []HookCallbackFunc, input interface{}, toolUseID *string, hookCtx HookContext) (map[string]interface{}, error) {
+	merger := newHookOutputMerger()
+
+	for _, hook := range hooks {
+		output, err := hook(ctx, input, toolUseID, hookCtx)
+		if err != nil {
+			return nil, err
+		}
+		if output == nil {
+			continue
+		}
+
+		normalized, err := NormalizeHookOutput(output)
+		if err != nil {
+			return nil, err
+		}
+
+		merger.merge(normalized)
+		if updated := updatedToolInput(normalized); updated != nil {
+			input = withToolInput(input, updated)
+		}
+
+		if hookOutputShortCircuits(normalized) {
+			break
+		}
+	}
+
+	return merger.result(), nil
+}
+
+// runHooksParallel runs all hooks concurrently, each on its own copy of the
+// input, and merges their outputs in registration order.
+func runHooksParallel(ctx context.Context, hooks []HookCallbackFunc, input interface{}, toolUseID *string, hookCtx HookContext) (map[string]interface{}, error) {
+	type hookResult struct {
+		output map[string]interface{}
+		err    error
+	}
+
+	original := hookInputToolInput(input)
+	results := make([]hookResult, len(hooks))
+	var wg sync.WaitGroup
+	for i, hook := range hooks {
+		hookInput := cloneHookInput(input)
+		wg.Add(1)
+		go func(i int, hook HookCallbackFunc) {
+			defer wg.Done()
+			output, err := hook(ctx, hookInput, toolUseID, hookCtx)
+			if err != nil {
+				results[i] = hookResult{err: err}
+				return
+			}
+			var normalized map[string]interface{}
+			if output != nil {
+				if normalized, err = NormalizeHookOutput(output); err != nil {
+					results[i] = hookResult{err: err}
+					return
+				}
+			}
+			if original != nil {
+				normalized = PropagateUpdatedInput(normalized, original, hookInputToolInput(hookInput))
+			}
+			results[i] = hookResult{output: normalized}
+		}(i, hook)
+	}
+	wg.Wait()
+
+	merger := newHookOutputMerger()
+	var updates []map[string]interface{}
+	for _, result := range results {
+		if result.err != nil {
+			return nil, result.err
+		}
+		if result.output != nil {
+			merger.merge(result.output)
+			if updated := updatedToolInput(result.output); updated != nil {
+				updates = append(updates, updated)
+			}
+		}
+	}
+	if len(updates) > 0 {
+		merger.specific["updatedInput"] = mergeUpdatedInputs(original, updates)
+	}
+	return merger.result(), nil
+}
+
+// cloneHookInput returns a deep copy of a hook input, so that a hook editing
+// it in place cannot affect the hooks running alongside it.
+func cloneHookInput(input interface{}) interface{} {
+	if input == nil {
+		return nil
+	}
+	v := reflect.ValueOf(input)
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+		copied := reflect.New(v.Elem().Type())
+		copied.Elem().Set(deepCopy(v.Elem()))
+		return copied.Interface()
+	}
+	return deepCopy(v).Interface()
+}
+
+// hookInputToolInput returns the tool input carried by a hook input, if any.
+func hookInputToolInput(input interface{}) map[string]interface{} {
+	switch in := input.(type) {
+	case map[string]interface{}:
+		toolInput, _ := in["tool_input"].(map[string]interface{})
+		return toolInput
+	case *PreToolUseHookInput:
+		if in != nil {
+			return in.ToolInput
+		}
+	case PreToolUseHookInput:
+		return in.ToolInput
+	}
+	return nil
+}
+
+// mergeUpdatedInputs applies the keys each updated tool input adds, changes
+// or removes relative to original, in order, to a copy of original.
+func mergeUpdatedInputs(original map[string]interface{}, updates []map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(original))
+	for key, value := range original {
+		merged[key] = value
+	}
+	for _, updated := range updates {
+		for key, value := range updated {
+			if old, ok := original[key]; !ok || !reflect.DeepEqual(old, value) {
+				merged[key] = value
+			}
+		}
+		for key := range original {
+			if _, ok := updated[key]; !ok {
+				delete(merged, key)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,151 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestComposeHooksSerial tests that serial hooks see earlier rewrites and short-circuit.
+func TestComposeHooksSerial(t *testing.T) {
+	var secondSaw string
+	var thirdRan bool
+	hook := ComposeHooks(HookExecutionSerial, []HookCallbackFunc{
+		func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			return UpdateToolInput(map[string]interface{}{"command": "ls -la"}), nil
+		},
+		func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			secondSaw, _ = input.(map[string]interface{})["tool_input"].(map[string]interface{})["command"].(string)
+			return PreToolDeny("no"), nil
+		},
+		func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			thirdRan = true
+			return PreToolAllow(), nil
+		},
+	})
+
+	output, err := hook(context.Background(), map[string]interface{}{"tool_input": map[string]interface{}{"command": "ls"}}, nil, HookContext{})
+	if err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+	if secondSaw != "ls -la" {
+		t.Errorf("expected second hook to see rewritten input, got %q", secondSaw)
+	}
+	if thirdRan {
+		t.Error("expected deny to skip the remaining hooks")
+	}
+	specific := output.(map[string]interface{})["hookSpecificOutput"].(map[string]interface{})
+	if specific["permissionDecision"] != "deny" {
+		t.Errorf("expected deny decision, got %v", specific)
+	}
+}
+
+// TestComposeHooksParallel tests that parallel hooks all run and merge to the most restrictive decision.
+func TestComposeHooksParallel(t *testing.T) {
+	var running, maxRunning int32
+	track := func(output interface{}) HookCallbackFunc {
+		return func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return output, nil
+		}
+	}
+
+	hook := ComposeHooks(HookExecutionParallel, []HookCallbackFunc{
+		track(PreToolDeny("policy")),
+		track(PreToolAllow().WithSystemMessage("allowed")),
+		track(nil),
+	})
+
+	output, err := hook(context.Background(), map[string]interface{}{}, nil, HookContext{})
+	if err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+	if maxRunning < 2 {
+		t.Errorf("expected hooks to run concurrently, max running was %d", maxRunning)
+	}
+
+	merged := output.(map[string]interface{})
+	specific := merged["hookSpecificOutput"].(map[string]interface{})
+	if specific["permissionDecision"] != "deny" || specific["permissionDecisionReason"] != "policy" {
+		t.Errorf("expected most restrictive decision to win, got %v", specific)
+	}
+	if merged["systemMessage"] != "allowed" {
+		t.Errorf("expected outputs of all hooks to be merged, got %v", merged)
+	}
+
+	errFirst := errors.New("first")
+	hook = ComposeHooks(HookExecutionParallel, []HookCallbackFunc{
+		func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			time.Sleep(10 * time.Millisecond)
+			return nil, errFirst
+		},
+		func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			return nil, errors.New("second")
+		},
+	})
+	if output, err := hook(context.Background(), nil, nil, HookContext{}); err != errFirst || output != nil {
+		t.Errorf("expected error of the earliest hook, got %v, %v", output, err)
+	}
+}
+
+// TestComposeHooksParallelToolInput tests that parallel hooks get their own
+// copy of the input and that their tool input changes are merged.
+func TestComposeHooksParallelToolInput(t *testing.T) {
+	edit := func(key string, value interface{}) HookCallbackFunc {
+		return func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			toolInput := input.(map[string]interface{})["tool_input"].(map[string]interface{})
+			for i := 0; i < 100; i++ {
+				toolInput[key] = value
+			}
+			return nil, nil
+		}
+	}
+	hook := ComposeHooks(HookExecutionParallel, []HookCallbackFunc{
+		edit("command", "ls -la"),
+		edit("timeout", 5000),
+		func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			return UpdateToolInput(map[string]interface{}{"command": "ls", "description": "list"}), nil
+		},
+	})
+
+	original := map[string]interface{}{"command": "ls"}
+	output, err := hook(context.Background(), map[string]interface{}{
+		"hook_event_name": "PreToolUse",
+		"tool_input":      original,
+	}, nil, HookContext{})
+	if err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+	if len(original) != 1 || original["command"] != "ls" {
+		t.Errorf("expected the caller's input to be unchanged, got %v", original)
+	}
+
+	specific := output.(map[string]interface{})["hookSpecificOutput"].(map[string]interface{})
+	want := map[string]interface{}{"command": "ls -la", "timeout": 5000, "description": "list"}
+	if !reflect.DeepEqual(specific["updatedInput"], want) {
+		t.Errorf("expected merged updatedInput %v, got %v", want, specific["updatedInput"])
+	}
+}
+
+// TestHookExecutionModeIsValid tests execution mode validation.
+func TestHookExecutionModeIsValid(t *testing.T) {
+	for _, mode := range []HookExecutionMode{HookExecutionDefault, HookExecutionSerial, HookExecutionParallel} {
+		if !mode.IsValid() {
+			t.Errorf("expected %q to be valid", mode)
+		}
+	}
+	if HookExecutionMode("concurrent").IsValid() {
+		t.Error("expected unknown mode to be invalid")
+	}
+}
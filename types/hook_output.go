@@ -0,0 +1,183 @@
+package types
+
+import "reflect"
+
+// Hook output builders produce correctly shaped SyncHookJSONOutput values for
+// common hook responses. Builders can be refined with the With* methods:
+//
+//	return types.DenyToolUse("rm -rf is not allowed").
+//	    WithSystemMessage("Blocked a destructive command"), nil
+
+// AllowToolUse returns a PreToolUse output that allows the tool use, bypassing the permission prompt.
+func AllowToolUse(reason string) *SyncHookJSONOutput {
+	return permissionDecisionOutput("allow", reason, nil)
+}
+
+// AllowToolUseWithInput returns a PreToolUse output that allows the tool use with a rewritten input.
+func AllowToolUseWithInput(newInput map[string]interface{}) *SyncHookJSONOutput {
+	return permissionDecisionOutput("allow", "", newInput)
+}
+
+// DenyToolUse returns a PreToolUse output that prevents the tool from running.
+// The reason is shown to Claude.
+func DenyToolUse(reason string) *SyncHookJSONOutput {
+	return permissionDecisionOutput("deny", reason, nil)
+}
+
+// AskToolUse returns a PreToolUse output that asks the user to confirm the tool use.
+func AskToolUse(reason string) *SyncHookJSONOutput {
+	return permissionDecisionOutput("ask", reason, nil)
+}
+
+// PreToolAllow is AllowToolUse without a reason. Chain WithUpdatedInput to
+// run the tool with a rewritten input. Changes a hook makes to the tool_input
+// map of its input are propagated automatically.
+func PreToolAllow() *SyncHookJSONOutput {
+	return AllowToolUse("")
+}
+
+// PreToolDeny is an alias for DenyToolUse.
+func PreToolDeny(reason string) *SyncHookJSONOutput {
+	return DenyToolUse(reason)
+}
+
+// PreToolAsk is an alias for AskToolUse.
+func PreToolAsk(reason string) *SyncHookJSONOutput {
+	return AskToolUse(reason)
+}
+
+// UpdateToolInput returns a PreToolUse output that rewrites the tool input
+// without making a permission decision.
+func UpdateToolInput(newInput map[string]interface{}) *SyncHookJSONOutput {
+	return permissionDecisionOutput("", "", newInput)
+}
+
+// AddContext returns an output that adds context for Claude on the given event,
+// such as PostToolUse or UserPromptSubmit. Events without an additionalContext
+// field, such as Stop, are rejected by ValidateHookOutput.
+func AddContext(event HookEvent, text string) *SyncHookJSONOutput {
+	var specific HookSpecificOutput
+	name := string(event)
+
+	switch event {
+	case HookEventPostToolUse:
+		specific = &PostToolUseHookSpecificOutput{HookEventName: name, AdditionalContext: &text}
+	case HookEventUserPromptSubmit:
+		specific = &UserPromptSubmitHookSpecificOutput{HookEventName: name, AdditionalContext: &text}
+	case HookEventSubagentStart:
+		specific = &SubagentStartHookSpecificOutput{HookEventName: name, AdditionalContext: &text}
+	case HookEventPrePrompt:
+		specific = &PrePromptHookSpecificOutput{HookEventName: name, AdditionalContext: &text}
+	case HookEventPostPrompt:
+		specific = &PostPromptHookSpecificOutput{HookEventName: name, AdditionalContext: &text}
+	case HookEventPostResponse:
+		specific = &PostResponseHookSpecificOutput{HookEventName: name, AdditionalContext: &text}
+	case HookEventPostCompact:
+		specific = &PostCompactHookSpecificOutput{HookEventName: name, AdditionalContext: &text}
+	case HookEventOnError:
+		specific = &OnErrorHookSpecificOutput{HookEventName: name, AdditionalContext: &text}
+	default:
+		return &SyncHookJSONOutput{
+			HookSpecificOutput: map[string]interface{}{
+				"hookEventName":     name,
+				"additionalContext": text,
+			},
+		}
+	}
+
+	return &SyncHookJSONOutput{HookSpecificOutput: specific}
+}
+
+// Block returns an output with decision "block", which stops the action the
+// event refers to (for example a prompt submission or stopping) and shows the
+// reason to Claude.
+func Block(reason string) *SyncHookJSONOutput {
+	decision := "block"
+	output := &SyncHookJSONOutput{Decision: &decision}
+	if reason != "" {
+		output.Reason = &reason
+	}
+	return output
+}
+
+// StopExecution returns an output that sets continue=false, ending the session
+// after the hook. The stop reason is shown to the user.
+func StopExecution(stopReason string) *SyncHookJSONOutput {
+	cont := false
+	output := &SyncHookJSONOutput{Continue: &cont}
+	if stopReason != "" {
+		output.StopReason = &stopReason
+	}
+	return output
+}
+
+// WithSystemMessage sets a warning message shown to the user.
+func (o *SyncHookJSONOutput) WithSystemMessage(message string) *SyncHookJSONOutput {
+	o.SystemMessage = &message
+	return o
+}
+
+// WithSuppressOutput hides the hook's stdout from the transcript.
+func (o *SyncHookJSONOutput) WithSuppressOutput() *SyncHookJSONOutput {
+	suppress := true
+	o.SuppressOutput = &suppress
+	return o
+}
+
+// WithUpdatedInput sets the tool input the tool runs with. It applies to
+// PreToolUse outputs and creates the hookSpecificOutput if needed.
+func (o *SyncHookJSONOutput) WithUpdatedInput(input map[string]interface{}) *SyncHookJSONOutput {
+	specific, ok := o.HookSpecificOutput.(*PreToolUseHookSpecificOutput)
+	if !ok {
+		specific = &PreToolUseHookSpecificOutput{HookEventName: string(HookEventPreToolUse)}
+		o.HookSpecificOutput = specific
+	}
+	specific.UpdatedInput = &input
+	return o
+}
+
+// PropagateUpdatedInput adds the tool input as updatedInput to a normalized
+// PreToolUse output when a hook modified the tool_input map of its input in
+// place rather than returning the new input. original is the tool input
+// before the hook ran. Outputs that already set updatedInput or deny the tool
+// use are returned unchanged.
+func PropagateUpdatedInput(output map[string]interface{}, original, current map[string]interface{}) map[string]interface{} {
+	if current == nil || reflect.DeepEqual(original, current) {
+		return output
+	}
+
+	specific, _ := output["hookSpecificOutput"].(map[string]interface{})
+	if specific == nil {
+		specific = map[string]interface{}{"hookEventName": string(HookEventPreToolUse)}
+	} else if name, _ := specific["hookEventName"].(string); name != string(HookEventPreToolUse) {
+		return output
+	}
+	if _, set := specific["updatedInput"]; set {
+		return output
+	}
+	if decision, _ := specific["permissionDecision"].(string); decision == "deny" {
+		return output
+	}
+
+	specific["updatedInput"] = current
+	if output == nil {
+		output = make(map[string]interface{})
+	}
+	output["hookSpecificOutput"] = specific
+	return output
+}
+
+// permissionDecisionOutput builds a PreToolUse output. Empty values are omitted.
+func permissionDecisionOutput(decision, reason string, updatedInput map[string]interface{}) *SyncHookJSONOutput {
+	specific := &PreToolUseHookSpecificOutput{HookEventName: string(HookEventPreToolUse)}
+	if decision != "" {
+		specific.PermissionDecision = &decision
+	}
+	if reason != "" {
+		specific.PermissionDecisionReason = &reason
+	}
+	if updatedInput != nil {
+		specific.UpdatedInput = &updatedInput
+	}
+	return &SyncHookJSONOutput{HookSpecificOutput: specific}
+}
@@ -0,0 +1,152 @@
+package types
+
+import "testing"
+
+// hookSpecific normalizes an output and returns its hookSpecificOutput.
+func hookSpecific(t *testing.T, output *SyncHookJSONOutput) map[string]interface{} {
+	t.Helper()
+	normalized, err := NormalizeHookOutput(output)
+	if err != nil {
+		t.Fatalf("NormalizeHookOutput failed: %v", err)
+	}
+	specific, ok := normalized["hookSpecificOutput"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected hookSpecificOutput, got %v", normalized)
+	}
+	return specific
+}
+
+// TestPermissionDecisionBuilders tests the PreToolUse output builders.
+func TestPermissionDecisionBuilders(t *testing.T) {
+	specific := hookSpecific(t, DenyToolUse("not allowed"))
+	if specific["hookEventName"] != "PreToolUse" || specific["permissionDecision"] != "deny" || specific["permissionDecisionReason"] != "not allowed" {
+		t.Errorf("unexpected deny output: %v", specific)
+	}
+
+	specific = hookSpecific(t, AskToolUse(""))
+	if specific["permissionDecision"] != "ask" {
+		t.Errorf("expected ask decision, got %v", specific["permissionDecision"])
+	}
+	if _, ok := specific["permissionDecisionReason"]; ok {
+		t.Error("expected empty reason to be omitted")
+	}
+
+	specific = hookSpecific(t, AllowToolUseWithInput(map[string]interface{}{"command": "ls -la"}))
+	updated, _ := specific["updatedInput"].(map[string]interface{})
+	if specific["permissionDecision"] != "allow" || updated["command"] != "ls -la" {
+		t.Errorf("unexpected allow-with-input output: %v", specific)
+	}
+
+	specific = hookSpecific(t, UpdateToolInput(map[string]interface{}{"command": "ls"}))
+	if _, ok := specific["permissionDecision"]; ok {
+		t.Error("expected UpdateToolInput not to make a permission decision")
+	}
+}
+
+// TestAddContext tests that AddContext produces event-specific outputs.
+func TestAddContext(t *testing.T) {
+	for _, event := range []HookEvent{HookEventPostToolUse, HookEventUserPromptSubmit, HookEventSubagentStart, HookEventPostCompact} {
+		specific := hookSpecific(t, AddContext(event, "extra context"))
+		if specific["hookEventName"] != string(event) || specific["additionalContext"] != "extra context" {
+			t.Errorf("unexpected output for %s: %v", event, specific)
+		}
+	}
+}
+
+// TestControlOutputBuilders tests Block, StopExecution and the With* modifiers.
+func TestControlOutputBuilders(t *testing.T) {
+	output, err := NormalizeHookOutput(Block("needs review").WithSystemMessage("warning"))
+	if err != nil {
+		t.Fatalf("NormalizeHookOutput failed: %v", err)
+	}
+	if output["decision"] != "block" || output["reason"] != "needs review" || output["systemMessage"] != "warning" {
+		t.Errorf("unexpected block output: %v", output)
+	}
+
+	output, err = NormalizeHookOutput(StopExecution("done").WithSuppressOutput())
+	if err != nil {
+		t.Fatalf("NormalizeHookOutput failed: %v", err)
+	}
+	if output["continue"] != false || output["stopReason"] != "done" || output["suppressOutput"] != true {
+		t.Errorf("unexpected stop output: %v", output)
+	}
+}
+
+// TestUpdatedPromptRejected tests that UserPromptSubmit hooks cannot return
+// updatedPrompt, which the CLI ignores.
+func TestUpdatedPromptRejected(t *testing.T) {
+	output := map[string]interface{}{
+		"hookSpecificOutput": map[string]interface{}{
+			"hookEventName": "UserPromptSubmit",
+			"updatedPrompt": "summarize README.md",
+		},
+	}
+	if err := ValidateHookOutput(HookEventUserPromptSubmit, output); err == nil {
+		t.Error("expected updatedPrompt to be rejected")
+	}
+}
+
+// TestPreToolBuilders tests PreToolAllow, PreToolDeny, PreToolAsk and WithUpdatedInput.
+func TestPreToolBuilders(t *testing.T) {
+	specific := hookSpecific(t, PreToolAllow())
+	if specific["hookEventName"] != "PreToolUse" || specific["permissionDecision"] != "allow" {
+		t.Errorf("unexpected allow output: %v", specific)
+	}
+
+	specific = hookSpecific(t, PreToolDeny("blocked"))
+	if specific["permissionDecision"] != "deny" || specific["permissionDecisionReason"] != "blocked" {
+		t.Errorf("unexpected deny output: %v", specific)
+	}
+
+	specific = hookSpecific(t, PreToolAsk("confirm"))
+	if specific["permissionDecision"] != "ask" || specific["permissionDecisionReason"] != "confirm" {
+		t.Errorf("unexpected ask output: %v", specific)
+	}
+
+	specific = hookSpecific(t, PreToolAllow().WithUpdatedInput(map[string]interface{}{"command": "ls"}))
+	updated, _ := specific["updatedInput"].(map[string]interface{})
+	if specific["permissionDecision"] != "allow" || updated["command"] != "ls" {
+		t.Errorf("unexpected allow-with-input output: %v", specific)
+	}
+
+	specific = hookSpecific(t, (&SyncHookJSONOutput{}).WithUpdatedInput(map[string]interface{}{"command": "ls"}))
+	if specific["hookEventName"] != "PreToolUse" || specific["updatedInput"] == nil {
+		t.Errorf("expected WithUpdatedInput to create a PreToolUse output, got %v", specific)
+	}
+}
+
+// TestPropagateUpdatedInput tests that in-place tool input edits become updatedInput.
+func TestPropagateUpdatedInput(t *testing.T) {
+	original := map[string]interface{}{"command": "rm -rf /tmp/x"}
+	current := map[string]interface{}{"command": "rm -ri /tmp/x"}
+
+	output := PropagateUpdatedInput(map[string]interface{}{}, original, current)
+	specific, _ := output["hookSpecificOutput"].(map[string]interface{})
+	if specific["hookEventName"] != "PreToolUse" || specific["updatedInput"] == nil {
+		t.Errorf("expected edited input to be propagated, got %v", output)
+	}
+
+	unchanged := PropagateUpdatedInput(map[string]interface{}{}, original, original)
+	if _, ok := unchanged["hookSpecificOutput"]; ok {
+		t.Errorf("expected unchanged input not to be propagated, got %v", unchanged)
+	}
+
+	explicit := map[string]interface{}{
+		"hookSpecificOutput": map[string]interface{}{
+			"hookEventName": "PreToolUse",
+			"updatedInput":  map[string]interface{}{"command": "ls"},
+		},
+	}
+	output = PropagateUpdatedInput(explicit, original, current)
+	specific, _ = output["hookSpecificOutput"].(map[string]interface{})
+	if updated, _ := specific["updatedInput"].(map[string]interface{}); updated["command"] != "ls" {
+		t.Errorf("expected explicit updatedInput to win, got %v", specific)
+	}
+
+	denied, _ := NormalizeHookOutput(PreToolDeny("no"))
+	output = PropagateUpdatedInput(denied, original, current)
+	specific, _ = output["hookSpecificOutput"].(map[string]interface{})
+	if _, ok := specific["updatedInput"]; ok {
+		t.Errorf("expected denied output not to carry updatedInput, got %v", specific)
+	}
+}
@@ -0,0 +1,174 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// hookFieldKind is the JSON type expected for a hook output field.
+type hookFieldKind string
+
+const (
+	hookFieldBool   hookFieldKind = "boolean"
+	hookFieldString hookFieldKind = "string"
+	hookFieldNumber hookFieldKind = "number"
+	hookFieldObject hookFieldKind = "object"
+	hookFieldArray  hookFieldKind = "array"
+)
+
+// hookOutputFields lists the top-level fields of a hook output.
+var hookOutputFields = map[string]hookFieldKind{
+	"continue":           hookFieldBool,
+	"suppressOutput":     hookFieldBool,
+	"stopReason":         hookFieldString,
+	"decision":           hookFieldString,
+	"systemMessage":      hookFieldString,
+	"reason":             hookFieldString,
+	"hookSpecificOutput": hookFieldObject,
+	"async":              hookFieldBool,
+	"asyncTimeout":       hookFieldNumber,
+}
+
+// hookSpecificFields lists the hookSpecificOutput fields accepted for each event.
+var hookSpecificFields = map[HookEvent]map[string]hookFieldKind{
+	HookEventPreToolUse: {
+		"permissionDecision":       hookFieldString,
+		"permissionDecisionReason": hookFieldString,
+		"updatedInput":             hookFieldObject,
+	},
+	HookEventPostToolUse:      {"additionalContext": hookFieldString},
+	HookEventUserPromptSubmit: {"additionalContext": hookFieldString},
+	HookEventSubagentStart:    {"additionalContext": hookFieldString},
+	HookEventPrePrompt: {
+		"modifiedMessages":  hookFieldArray,
+		"additionalContext": hookFieldString,
+	},
+	HookEventPostPrompt:   {"additionalContext": hookFieldString},
+	HookEventPreResponse:  {"modifiedResponse": hookFieldObject},
+	HookEventPostResponse: {"additionalContext": hookFieldString},
+	HookEventPostCompact:  {"additionalContext": hookFieldString},
+	HookEventOnError: {
+		"recoveryAction":    hookFieldString,
+		"additionalContext": hookFieldString,
+	},
+}
+
+// hookEnumFields lists the allowed values of enumerated string fields.
+var hookEnumFields = map[string][]string{
+	"decision":           {"approve", "block"},
+	"permissionDecision": {"allow", "deny", "ask"},
+	"recoveryAction":     {"retry", "skip", "abort"},
+}
+
+// ValidateHookOutput checks a normalized hook output against the control
+// protocol before it is sent to the CLI, so that mistakes such as a misspelled
+// field or a hookSpecificOutput for the wrong event are reported instead of
+// being silently ignored by the CLI. An empty event skips event-specific checks.
+func ValidateHookOutput(event HookEvent, output map[string]interface{}) error {
+	// Validate the JSON form, which is what the CLI receives
+	data, err := json.Marshal(output)
+	if err != nil {
+		return NewControlProtocolErrorWithCause("invalid hook output: not JSON-serializable", err)
+	}
+	output = nil
+	if err := json.Unmarshal(data, &output); err != nil {
+		return NewControlProtocolErrorWithCause("invalid hook output: not a JSON object", err)
+	}
+
+	if err := validateHookFields("", output, hookOutputFields); err != nil {
+		return err
+	}
+
+	raw, ok := output["hookSpecificOutput"]
+	if !ok || raw == nil {
+		return nil
+	}
+	specific := raw.(map[string]interface{})
+
+	name, ok := specific["hookEventName"].(string)
+	if !ok || name == "" {
+		return hookOutputError("hookSpecificOutput.hookEventName is required")
+	}
+	if event != "" && name != string(event) {
+		return hookOutputError(fmt.Sprintf("hookSpecificOutput.hookEventName is %q but the hook is registered for %s", name, event))
+	}
+
+	fields, known := hookSpecificFields[HookEvent(name)]
+	if !known {
+		if event != "" {
+			return hookOutputError(fmt.Sprintf("%s hooks do not accept hookSpecificOutput", name))
+		}
+		return nil
+	}
+
+	withName := make(map[string]hookFieldKind, len(fields)+1)
+	for field, kind := range fields {
+		withName[field] = kind
+	}
+	withName["hookEventName"] = hookFieldString
+	return validateHookFields("hookSpecificOutput.", specific, withName)
+}
+
+// validateHookFields checks that every field is known and has the expected type.
+func validateHookFields(prefix string, values map[string]interface{}, fields map[string]hookFieldKind) error {
+	// Check in sorted order so the reported error is deterministic
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := values[key]
+		kind, ok := fields[key]
+		if !ok {
+			return hookOutputError(fmt.Sprintf("unknown field %s%s (expected one of %s)", prefix, key, strings.Join(sortedFieldNames(fields), ", ")))
+		}
+		if value == nil {
+			continue
+		}
+		if !hookValueHasKind(value, kind) {
+			return hookOutputError(fmt.Sprintf("%s%s must be a %s, got %T", prefix, key, kind, value))
+		}
+		if allowed, ok := hookEnumFields[key]; ok && !slices.Contains(allowed, value.(string)) {
+			return hookOutputError(fmt.Sprintf("%s%s must be one of %s, got %q", prefix, key, strings.Join(allowed, ", "), value))
+		}
+	}
+	return nil
+}
+
+// hookValueHasKind reports whether a decoded JSON value has the expected kind.
+func hookValueHasKind(value interface{}, kind hookFieldKind) bool {
+	var ok bool
+	switch kind {
+	case hookFieldBool:
+		_, ok = value.(bool)
+	case hookFieldString:
+		_, ok = value.(string)
+	case hookFieldNumber:
+		_, ok = value.(float64)
+	case hookFieldObject:
+		_, ok = value.(map[string]interface{})
+	case hookFieldArray:
+		_, ok = value.([]interface{})
+	}
+	return ok
+}
+
+// sortedFieldNames returns the field names of a schema in sorted order.
+func sortedFieldNames(fields map[string]hookFieldKind) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hookOutputError wraps a validation failure in a ControlProtocolError.
+func hookOutputError(message string) error {
+	return NewControlProtocolError("invalid hook output: " + message)
+}
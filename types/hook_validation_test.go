@@ -0,0 +1,65 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestValidateHookOutput tests validation of hook outputs against the control protocol.
+func TestValidateHookOutput(t *testing.T) {
+	valid := []struct {
+		event  HookEvent
+		output interface{}
+	}{
+		{HookEventPreToolUse, map[string]interface{}{}},
+		{HookEventPreToolUse, DenyToolUse("blocked")},
+		{HookEventPreToolUse, AllowToolUseWithInput(map[string]interface{}{"command": "ls"})},
+		{HookEventPostToolUse, AddContext(HookEventPostToolUse, "note")},
+		{HookEventStop, Block("keep going").WithSystemMessage("warning")},
+		{"", map[string]interface{}{"async": true, "asyncTimeout": 1000}},
+	}
+	for _, tt := range valid {
+		output, err := NormalizeHookOutput(tt.output)
+		if err != nil {
+			t.Fatalf("NormalizeHookOutput failed: %v", err)
+		}
+		if err := ValidateHookOutput(tt.event, output); err != nil {
+			t.Errorf("expected %v to be valid for %q, got %v", output, tt.event, err)
+		}
+	}
+
+	invalid := []struct {
+		event   HookEvent
+		output  map[string]interface{}
+		message string
+	}{
+		{HookEventPreToolUse, map[string]interface{}{"contine": true}, "unknown field contine"},
+		{HookEventPreToolUse, map[string]interface{}{"continue": "yes"}, "continue must be a boolean"},
+		{HookEventPreToolUse, map[string]interface{}{"decision": "deny"}, "decision must be one of approve, block"},
+		{HookEventPreToolUse, map[string]interface{}{
+			"hookSpecificOutput": map[string]interface{}{"permissionDecision": "deny"},
+		}, "hookEventName is required"},
+		{HookEventPreToolUse, map[string]interface{}{
+			"hookSpecificOutput": map[string]interface{}{"hookEventName": "PostToolUse", "additionalContext": "x"},
+		}, "registered for PreToolUse"},
+		{HookEventPreToolUse, map[string]interface{}{
+			"hookSpecificOutput": map[string]interface{}{"hookEventName": "PreToolUse", "permissionDecision": "block"},
+		}, "permissionDecision must be one of allow, deny, ask"},
+		{HookEventPostToolUse, map[string]interface{}{
+			"hookSpecificOutput": map[string]interface{}{"hookEventName": "PostToolUse", "updatedInput": map[string]interface{}{}},
+		}, "unknown field hookSpecificOutput.updatedInput"},
+		{HookEventStop, map[string]interface{}{
+			"hookSpecificOutput": map[string]interface{}{"hookEventName": "Stop"},
+		}, "Stop hooks do not accept hookSpecificOutput"},
+	}
+	for _, tt := range invalid {
+		err := ValidateHookOutput(tt.event, tt.output)
+		if !IsControlProtocolError(err) {
+			t.Errorf("expected ControlProtocolError for %v, got %v", tt.output, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("expected error containing %q, got %q", tt.message, err.Error())
+		}
+	}
+}
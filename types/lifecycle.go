@@ -0,0 +1,80 @@
+package types
+
+// LifecycleEvent is an event in the life of the CLI subprocess, reported to
+// the LifecycleListener of the options. It is one of *CLISpawnedEvent,
+// *ConnectedEvent, *InitReceivedEvent, *ParseErrorSkippedEvent,
+// *StderrErrorEvent and *ProcessExitedEvent. Their CorrelationID is that of
+// the query running when they happened, see ContextWithCorrelationID.
+type LifecycleEvent interface {
+	lifecycleEvent()
+}
+
+// CLISpawnedEvent reports that the CLI subprocess started.
+type CLISpawnedEvent struct {
+	PID           int
+	Path          string // The CLI executable
+	CorrelationID string
+}
+
+// ConnectedEvent reports that the SDK is connected to the CLI and can send
+// queries.
+type ConnectedEvent struct {
+	CorrelationID string
+}
+
+// InitReceivedEvent reports the init system message the CLI sends when a
+// session starts.
+type InitReceivedEvent struct {
+	SessionID     string
+	Data          map[string]interface{} // The message, with the tools, MCP servers and model of the session
+	CorrelationID string
+}
+
+// ParseErrorSkippedEvent reports a line from the CLI that could not be
+// parsed and was skipped. With strict parsing the stream ends instead.
+type ParseErrorSkippedEvent struct {
+	Err           error
+	CorrelationID string
+}
+
+// StderrErrorEvent reports an error the CLI wrote to stderr.
+type StderrErrorEvent struct {
+	Line          string // Redacted of credentials
+	Err           error  // The typed error, such as *SessionNotFoundError, or nil
+	CorrelationID string
+}
+
+// ProcessExitedEvent reports that the CLI subprocess exited.
+type ProcessExitedEvent struct {
+	PID      int
+	ExitCode int   // -1 if the process was killed by a signal
+	Err      error // Why the process exited unsuccessfully, or nil
+	Closed   bool  // The SDK closed the connection, rather than the CLI exiting on its own
+
+	CorrelationID string
+}
+
+func (*CLISpawnedEvent) lifecycleEvent()        {}
+func (*ConnectedEvent) lifecycleEvent()         {}
+func (*InitReceivedEvent) lifecycleEvent()      {}
+func (*ParseErrorSkippedEvent) lifecycleEvent() {}
+func (*StderrErrorEvent) lifecycleEvent()       {}
+func (*ProcessExitedEvent) lifecycleEvent()     {}
+
+// LifecycleListener receives the lifecycle events of the CLI subprocess. It
+// is called from the SDK's goroutines and must not block or panic.
+type LifecycleListener func(event LifecycleEvent)
+
+// Notify reports an event to the listener, if not nil.
+func (l LifecycleListener) Notify(event LifecycleEvent) {
+	if l != nil {
+		l(event)
+	}
+}
+
+// NotifyLifecycle reports an event to the LifecycleListener, if any.
+func (o *ClaudeAgentOptions) NotifyLifecycle(event LifecycleEvent) {
+	if o != nil {
+		o.LifecycleListener.Notify(event)
+	}
+}
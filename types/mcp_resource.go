@@ -0,0 +1,94 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ResourceReadFunc returns the contents of the resource at uri.
+type ResourceReadFunc func(ctx context.Context, uri string) ([]byte, error)
+
+// McpResource is application data that an SDK MCP server lets Claude read,
+// such as a configuration file or a database record.
+type McpResource struct {
+	URI         string           `json:"uri"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	MimeType    string           `json:"mimeType,omitempty"`
+	Read        ResourceReadFunc `json:"-"`
+}
+
+// NewMcpResource creates a resource named after the last element of its URI.
+func NewMcpResource(uri, mimeType string, read ResourceReadFunc) McpResource {
+	name := path.Base(strings.TrimRight(uri, "/"))
+	if name == "." || name == "/" {
+		name = uri
+	}
+	return McpResource{URI: uri, Name: name, MimeType: mimeType, Read: read}
+}
+
+// Validate checks that the resource has a URI and a read function.
+func (r McpResource) Validate() error {
+	if r.URI == "" {
+		return fmt.Errorf("resource URI is required")
+	}
+	if r.Read == nil {
+		return fmt.Errorf("resource %s: read function is required", r.URI)
+	}
+	return nil
+}
+
+// IsText reports whether the resource contents are sent as text rather than
+// base64, based on its MIME type.
+func (r McpResource) IsText() bool {
+	mimeType, _, _ := strings.Cut(r.MimeType, ";")
+	mimeType = strings.TrimSpace(mimeType)
+	switch {
+	case mimeType == "", strings.HasPrefix(mimeType, "text/"):
+		return true
+	case strings.HasSuffix(mimeType, "+json"), strings.HasSuffix(mimeType, "+xml"):
+		return true
+	}
+	switch mimeType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml",
+		"application/toml", "application/javascript", "application/sql":
+		return true
+	}
+	return false
+}
+
+// resourceAdder is an MCP server that serves resources.
+type resourceAdder interface {
+	AddResource(resource McpResource) error
+}
+
+// AddResource adds a resource to the server, read with read when Claude
+// requests it. The server lists it under resources/list and serves it with
+// resources/read. It returns an error if the URI is already registered.
+//
+// Example:
+//
+//	server := types.CreateToolServer("app", "1.0.0", tools)
+//	err := server.AddResource("config://app/settings.json", "application/json",
+//	    func(ctx context.Context, uri string) ([]byte, error) {
+//	        return json.Marshal(settings)
+//	    })
+func (c *ToolServerConfig) AddResource(uri, mimeType string, read ResourceReadFunc) error {
+	resource := NewMcpResource(uri, mimeType, read)
+	if err := resource.Validate(); err != nil {
+		return err
+	}
+	// Once a session has started the server, add to it directly.
+	if server, ok := c.Instance.(resourceAdder); ok {
+		return server.AddResource(resource)
+	}
+	for _, existing := range c.Resources {
+		if existing.URI == uri {
+			return fmt.Errorf("resource already exists: %s", uri)
+		}
+	}
+	c.Resources = append(c.Resources, resource)
+	return nil
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"context"
+	"testing"
+)
+
+// TestMcpResource tests resource names, validation and text detection.
+func TestMcpResource(t *testing.T) {
+	read := func(ctx context.Context, uri string) ([]byte, error) { return nil, nil }
+
+	if r := NewMcpResource("config://app/settings.json", "application/json", read); r.Name != "settings.json" {
+		t.Errorf("expected name settings.json, got %q", r.Name)
+	}
+	if r := NewMcpResource("db://users/", "", read); r.Name != "users" {
+		t.Errorf("expected name users, got %q", r.Name)
+	}
+
+	if err := NewMcpResource("", "", read).Validate(); err == nil {
+		t.Error("expected error for an empty URI")
+	}
+	if err := NewMcpResource("db://users", "", nil).Validate(); err == nil {
+		t.Error("expected error for a missing read function")
+	}
+
+	tests := map[string]bool{
+		"":                         true,
+		"text/plain":               true,
+		"text/csv; charset=utf-8":  true,
+		"application/json":         true,
+		"application/vnd.api+json": true,
+		"application/yaml":         true,
+		"image/png":                false,
+		"application/pdf":          false,
+		"application/octet-stream": false,
+	}
+	for mimeType, want := range tests {
+		if got := (McpResource{MimeType: mimeType}).IsText(); got != want {
+			t.Errorf("IsText(%q) = %v, want %v", mimeType, got, want)
+		}
+	}
+}
+
+// TestToolServerConfigAddResource tests adding resources to a server configuration.
+func TestToolServerConfigAddResource(t *testing.T) {
+	read := func(ctx context.Context, uri string) ([]byte, error) { return []byte("data"), nil }
+	server := CreateToolServer("app", "1.0.0", nil)
+
+	if err := server.AddResource("db://records/1", "application/json", read); err != nil {
+		t.Fatalf("AddResource failed: %v", err)
+	}
+	if err := server.AddResource("db://records/1", "application/json", read); err == nil {
+		t.Error("expected error for a duplicate URI")
+	}
+	if err := server.AddResource("db://records/2", "application/json", nil); err == nil {
+		t.Error("expected error for a missing read function")
+	}
+	if len(server.Resources) != 1 || server.Resources[0].URI != "db://records/1" {
+		t.Errorf("unexpected resources: %+v", server.Resources)
+	}
+}
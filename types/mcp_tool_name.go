@@ -0,0 +1,150 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// McpToolPrefix starts the name Claude uses for an MCP tool.
+const McpToolPrefix = "mcp__"
+
+// McpToolName returns the name Claude uses for tool on an MCP server
+// registered under server: "mcp__<server>__<tool>".
+func McpToolName(server, tool string) string {
+	return McpToolPrefix + server + "__" + tool
+}
+
+// ParseMcpToolName splits a name such as "mcp__calc__add" into its server
+// and tool. It reports false for names that are not MCP tool names.
+func ParseMcpToolName(name string) (server, tool string, ok bool) {
+	rest, ok := strings.CutPrefix(name, McpToolPrefix)
+	if !ok {
+		return "", "", false
+	}
+	server, tool, ok = strings.Cut(rest, "__")
+	if !ok || server == "" || tool == "" {
+		return "", "", false
+	}
+	return server, tool, true
+}
+
+// ValidateMcpToolName checks that a server and tool name form an MCP tool
+// name Claude can call: both use only letters, digits, "_" and "-", and the
+// server name has no "__", which would make the name ambiguous.
+func ValidateMcpToolName(server, tool string) error {
+	if err := validateMcpNamePart("server", server); err != nil {
+		return err
+	}
+	if strings.Contains(server, "__") {
+		return fmt.Errorf("MCP server name %q must not contain \"__\"", server)
+	}
+	return validateMcpNamePart("tool", tool)
+}
+
+// validateMcpNamePart checks the characters of a server or tool name.
+func validateMcpNamePart(kind, name string) error {
+	if name == "" {
+		return fmt.Errorf("MCP %s name is empty", kind)
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return fmt.Errorf("MCP %s name %q contains invalid character %q", kind, name, r)
+		}
+	}
+	return nil
+}
+
+// toolLister is an MCP server that lists its tools.
+type toolLister interface {
+	Tools() []McpTool
+}
+
+// Tools returns the tools the server serves.
+func (c *ToolServerConfig) Tools() []McpTool {
+	switch instance := c.Instance.(type) {
+	case []McpTool:
+		return instance
+	case toolLister:
+		return instance.Tools()
+	}
+	return nil
+}
+
+// AllowedToolNames returns the names Claude uses for the server's tools, for
+// WithAllowedTools. The names assume the server is registered under its
+// Name; WithAutoAllowSdkTools uses the registered names instead.
+func (c *ToolServerConfig) AllowedToolNames() []string {
+	return mcpToolNames(c.Name, c.Tools())
+}
+
+// mcpToolNames returns the MCP tool names of tools on server.
+func mcpToolNames(server string, tools []McpTool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, McpToolName(server, tool.Name()))
+	}
+	return names
+}
+
+// SdkToolNames returns the MCP tool names of every tool on the SDK MCP
+// servers in McpServers, using the names the servers are registered under.
+func (o *ClaudeAgentOptions) SdkToolNames() []string {
+	servers, ok := o.McpServers.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(servers))
+	for key := range servers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var names []string
+	for _, key := range keys {
+		if config, ok := servers[key].(*ToolServerConfig); ok {
+			names = append(names, mcpToolNames(key, config.Tools())...)
+		}
+	}
+	return names
+}
+
+// ValidateSdkToolNames checks the MCP tool names of the SDK MCP servers in McpServers.
+func (o *ClaudeAgentOptions) ValidateSdkToolNames() error {
+	servers, _ := o.McpServers.(map[string]interface{})
+	for key, server := range servers {
+		config, ok := server.(*ToolServerConfig)
+		if !ok {
+			continue
+		}
+		for _, tool := range config.Tools() {
+			if err := ValidateMcpToolName(key, tool.Name()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// EffectiveAllowedTools returns AllowedTools, plus the SDK MCP tools when
+// AutoAllowSdkTools is set. It returns nil for nil options.
+func (o *ClaudeAgentOptions) EffectiveAllowedTools() []string {
+	if o == nil {
+		return nil
+	}
+	if !o.AutoAllowSdkTools {
+		return o.AllowedTools
+	}
+	allowed := append([]string{}, o.AllowedTools...)
+	seen := make(map[string]bool, len(allowed))
+	for _, rule := range allowed {
+		seen[rule] = true
+	}
+	for _, name := range o.SdkToolNames() {
+		if !seen[name] {
+			seen[name] = true
+			allowed = append(allowed, name)
+		}
+	}
+	return allowed
+}
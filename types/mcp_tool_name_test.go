@@ -0,0 +1,69 @@
+package types
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestMcpToolName tests building, parsing and validating MCP tool names.
+func TestMcpToolName(t *testing.T) {
+	if got := McpToolName("calc", "add"); got != "mcp__calc__add" {
+		t.Errorf("expected mcp__calc__add, got %q", got)
+	}
+
+	server, tool, ok := ParseMcpToolName("mcp__calc__add_numbers")
+	if !ok || server != "calc" || tool != "add_numbers" {
+		t.Errorf("unexpected parse result: %q %q %v", server, tool, ok)
+	}
+	for _, name := range []string{"Bash", "mcp__calc", "mcp____add", "mcp__calc__"} {
+		if _, _, ok := ParseMcpToolName(name); ok {
+			t.Errorf("expected %q not to parse", name)
+		}
+	}
+
+	if err := ValidateMcpToolName("my-tools", "get_weather"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	invalid := [][2]string{{"", "add"}, {"calc", ""}, {"my tools", "add"}, {"calc", "add.v2"}, {"my__tools", "add"}}
+	for _, names := range invalid {
+		if err := ValidateMcpToolName(names[0], names[1]); err == nil {
+			t.Errorf("expected error for server %q tool %q", names[0], names[1])
+		}
+	}
+}
+
+// TestAutoAllowSdkTools tests that SDK MCP tools are added to the allowed tools.
+func TestAutoAllowSdkTools(t *testing.T) {
+	handler := func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+		return NewMcpToolResult(), nil
+	}
+	add, _ := NewTool("add").Description("Add").Handler(handler).Build()
+	sub, _ := NewTool("sub").Description("Subtract").Handler(handler).Build()
+	server := CreateToolServer("calculator", "1.0.0", []McpTool{add, sub})
+
+	if got, want := server.AllowedToolNames(), []string{"mcp__calculator__add", "mcp__calculator__sub"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AllowedToolNames() = %v, want %v", got, want)
+	}
+
+	opts := NewClaudeAgentOptions().
+		WithMcpServers(map[string]interface{}{"calc": server}).
+		WithAllowedTools("Read", "mcp__calc__add")
+	if got := opts.EffectiveAllowedTools(); !reflect.DeepEqual(got, []string{"Read", "mcp__calc__add"}) {
+		t.Errorf("expected allowed tools unchanged without auto-allow, got %v", got)
+	}
+
+	opts.WithAutoAllowSdkTools(true)
+	want := []string{"Read", "mcp__calc__add", "mcp__calc__sub"}
+	if got := opts.EffectiveAllowedTools(); !reflect.DeepEqual(got, want) {
+		t.Errorf("EffectiveAllowedTools() = %v, want %v", got, want)
+	}
+	if len(opts.AllowedTools) != 2 {
+		t.Errorf("expected AllowedTools unchanged, got %v", opts.AllowedTools)
+	}
+
+	opts.WithMcpServers(map[string]interface{}{"my calc": server})
+	if err := opts.ValidateSdkToolNames(); err == nil {
+		t.Error("expected error for an invalid server name")
+	}
+}
@@ -0,0 +1,119 @@
+package types
+
+import "strings"
+
+// contentBlocks returns the content blocks of a user or assistant message.
+// The string content of a user message becomes a text block.
+func contentBlocks(msg Message) []ContentBlock {
+	switch m := msg.(type) {
+	case *AssistantMessage:
+		return m.Content
+	case *UserMessage:
+		switch content := m.Content.(type) {
+		case string:
+			return []ContentBlock{&TextBlock{Type: "text", Text: content}}
+		case []ContentBlock:
+			return content
+		}
+	}
+	return nil
+}
+
+// TextOf returns the text of a user or assistant message, joining its text
+// blocks with newlines. It returns "" for other messages.
+//
+// Example:
+//
+//	for msg := range messages {
+//	    if text := types.TextOf(msg); text != "" {
+//	        fmt.Println(text)
+//	    }
+//	}
+func TextOf(msg Message) string {
+	var texts []string
+	for _, block := range contentBlocks(msg) {
+		switch b := block.(type) {
+		case *TextBlock:
+			texts = append(texts, b.Text)
+		case TextBlock:
+			texts = append(texts, b.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// AllText returns the text Claude wrote in msgs: the text of the assistant
+// messages, joined with newlines. Messages of subagents, which have a
+// ParentToolUseID, are left out.
+//
+// Example:
+//
+//	messages, err := claude.Query(ctx, "Summarize README.md", opts)
+//	...
+//	var all []types.Message
+//	for msg := range messages {
+//	    all = append(all, msg)
+//	}
+//	fmt.Println(types.AllText(all))
+func AllText(msgs []Message) string {
+	var texts []string
+	for _, msg := range msgs {
+		if m, ok := msg.(*AssistantMessage); ok && m.ParentToolUseID == nil {
+			if text := TextOf(m); text != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// ThinkingOf returns the thinking of an assistant message, joining its
+// thinking blocks with newlines. It returns "" for other messages.
+func ThinkingOf(msg Message) string {
+	var thoughts []string
+	for _, block := range contentBlocks(msg) {
+		switch b := block.(type) {
+		case *ThinkingBlock:
+			thoughts = append(thoughts, b.Thinking)
+		case ThinkingBlock:
+			thoughts = append(thoughts, b.Thinking)
+		}
+	}
+	return strings.Join(thoughts, "\n")
+}
+
+// ToolUses returns the tool calls of an assistant message, in order. It
+// returns nil for other messages.
+//
+// Example:
+//
+//	for _, use := range types.ToolUses(msg) {
+//	    fmt.Printf("%s(%v)\n", use.Name, use.Input)
+//	}
+func ToolUses(msg Message) []*ToolUseBlock {
+	var uses []*ToolUseBlock
+	for _, block := range contentBlocks(msg) {
+		switch b := block.(type) {
+		case *ToolUseBlock:
+			uses = append(uses, b)
+		case ToolUseBlock:
+			uses = append(uses, &b)
+		}
+	}
+	return uses
+}
+
+// ToolResults returns the tool results of a user message, in order. It
+// returns nil for other messages.
+func ToolResults(msg Message) []*ToolResultBlock {
+	var results []*ToolResultBlock
+	for _, block := range contentBlocks(msg) {
+		switch b := block.(type) {
+		case *ToolResultBlock:
+			results = append(results, b)
+		case ToolResultBlock:
+			results = append(results, &b)
+		}
+	}
+	return results
+}
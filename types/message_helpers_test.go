@@ -0,0 +1,53 @@
+package types
+
+import "testing"
+
+// TestMessageHelpers tests extracting text, thinking and tool calls from messages.
+func TestMessageHelpers(t *testing.T) {
+	parent := "toolu_task"
+	assistant := &AssistantMessage{
+		Type: "assistant",
+		Content: []ContentBlock{
+			&ThinkingBlock{Type: "thinking", Thinking: "Check the files."},
+			&TextBlock{Type: "text", Text: "Let me look."},
+			&ToolUseBlock{Type: "tool_use", ID: "toolu_1", Name: "Bash", Input: map[string]interface{}{"command": "ls"}},
+			TextBlock{Type: "text", Text: "Done."},
+		},
+	}
+	user := &UserMessage{
+		Type:    "user",
+		Content: []ContentBlock{&ToolResultBlock{Type: "tool_result", ToolUseID: "toolu_1", Content: "a.go"}},
+	}
+	subagent := &AssistantMessage{Type: "assistant", ParentToolUseID: &parent, Content: []ContentBlock{&TextBlock{Type: "text", Text: "Subagent work."}}}
+	result := &ResultMessage{Type: "result"}
+
+	if got := TextOf(assistant); got != "Let me look.\nDone." {
+		t.Errorf("TextOf(assistant) = %q", got)
+	}
+	if got := TextOf(&UserMessage{Type: "user", Content: "Hello"}); got != "Hello" {
+		t.Errorf("TextOf(user) = %q", got)
+	}
+	if got := TextOf(result); got != "" {
+		t.Errorf("TextOf(result) = %q", got)
+	}
+	if got := ThinkingOf(assistant); got != "Check the files." {
+		t.Errorf("ThinkingOf() = %q", got)
+	}
+
+	uses := ToolUses(assistant)
+	if len(uses) != 1 || uses[0].Name != "Bash" || uses[0].Input["command"] != "ls" {
+		t.Errorf("ToolUses() = %+v", uses)
+	}
+	if uses := ToolUses(user); uses != nil {
+		t.Errorf("ToolUses(user) = %+v", uses)
+	}
+	results := ToolResults(user)
+	if len(results) != 1 || results[0].ToolUseID != "toolu_1" {
+		t.Errorf("ToolResults() = %+v", results)
+	}
+
+	all := AllText([]Message{&UserMessage{Type: "user", Content: "Hi"}, assistant, user, subagent, &AssistantMessage{Type: "assistant", Content: []ContentBlock{&TextBlock{Type: "text", Text: "Two files."}}}, result})
+	if all != "Let me look.\nDone.\nTwo files." {
+		t.Errorf("AllText() = %q", all)
+	}
+}
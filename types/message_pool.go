@@ -0,0 +1,55 @@
+package types
+
+import (
+	"bytes"
+	"sync"
+)
+
+// streamEventPool holds released stream events for UnmarshalMessagePooled.
+var streamEventPool = sync.Pool{
+	New: func() interface{} { return new(StreamEvent) },
+}
+
+// streamEventType marks the JSON of a stream event.
+var streamEventType = []byte(`"stream_event"`)
+
+// UnmarshalMessagePooled is like UnmarshalMessage, but takes the
+// *StreamEvent it returns from a pool, reusing its Event map. This cuts
+// garbage collection for sessions with WithIncludePartialMessages, which
+// receive an event per streamed token. Call Release on each stream event
+// once done with it, after which neither the event nor its Event map may be
+// used; events that are not released are collected as usual. Other messages
+// are decoded by UnmarshalMessage.
+func UnmarshalMessagePooled(data []byte) (Message, error) {
+	if !bytes.Contains(data, streamEventType) {
+		return UnmarshalMessage(data)
+	}
+
+	event := streamEventPool.Get().(*StreamEvent)
+	if err := jsonUnmarshal(data, event); err != nil || event.Type != "stream_event" {
+		// Not a stream event after all, such as a message quoting one.
+		event.reset()
+		streamEventPool.Put(event)
+		return UnmarshalMessage(data)
+	}
+	event.pooled = true
+	return event, nil
+}
+
+// Release returns a stream event decoded by UnmarshalMessagePooled to the
+// pool. The event and its Event map must not be used afterwards. It does
+// nothing for other events and when called again.
+func (m *StreamEvent) Release() {
+	if m == nil || !m.pooled {
+		return
+	}
+	m.reset()
+	streamEventPool.Put(m)
+}
+
+// reset clears the event for reuse, keeping the Event map.
+func (m *StreamEvent) reset() {
+	event := m.Event
+	clear(event)
+	*m = StreamEvent{Event: event}
+}
@@ -0,0 +1,39 @@
+package types
+
+import "testing"
+
+// TestUnmarshalMessagePooled tests that released stream events are reused cleanly
+func TestUnmarshalMessagePooled(t *testing.T) {
+	first, err := UnmarshalMessagePooled([]byte(`{"type":"stream_event","uuid":"u1","session_id":"s","event":{"type":"content_block_delta","index":0},"parent_tool_use_id":"t1"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalMessagePooled() error = %v", err)
+	}
+	event, ok := first.(*StreamEvent)
+	if !ok || event.UUID != "u1" || event.Event["index"] != float64(0) || event.ParentToolUseID == nil {
+		t.Fatalf("UnmarshalMessagePooled() = %#v, want decoded stream event", first)
+	}
+	event.Release()
+	event.Release() // A second release does nothing
+
+	second, err := UnmarshalMessagePooled([]byte(`{"type":"stream_event","uuid":"u2","session_id":"s","event":{"type":"message_stop"}}`))
+	if err != nil {
+		t.Fatalf("UnmarshalMessagePooled() error = %v", err)
+	}
+	event = second.(*StreamEvent)
+	if event.UUID != "u2" || event.ParentToolUseID != nil || len(event.Event) != 1 || event.Event["type"] != "message_stop" {
+		t.Errorf("reused event = %#v, want no data of the released one", event)
+	}
+	event.Release()
+
+	// Messages that merely contain the stream_event string are decoded as usual.
+	msg, err := UnmarshalMessagePooled([]byte(`{"type":"user","content":[{"type":"tool_result","tool_use_id":"t","content":[{"type":"text","text":"x"}]}],"uuid":"stream_event"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalMessagePooled() error = %v", err)
+	}
+	if _, ok := msg.(*UserMessage); !ok {
+		t.Errorf("UnmarshalMessagePooled() = %T, want *UserMessage", msg)
+	}
+
+	// Release is safe on events that were not pooled.
+	(&StreamEvent{Type: "stream_event"}).Release()
+}
@@ -3,6 +3,9 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+
+	"github.com/M1n9X/claude-agent-sdk-go/schema"
 )
 
 // SystemMessageSubtype constants for common system message subtypes
@@ -17,7 +20,8 @@ const (
 )
 
 // ContentBlock is an interface for all content block types.
-// Content blocks can be text, thinking, tool use, or tool result blocks.
+// Content blocks can be text, thinking, tool use, tool result, image,
+// document, or server tool blocks.
 type ContentBlock interface {
 	GetType() string
 	isContentBlock()
@@ -63,6 +67,21 @@ func (t ThinkingBlock) GetType() string {
 
 func (t ThinkingBlock) isContentBlock() {}
 
+// RedactedThinkingBlock is reasoning that was flagged by safety systems
+// and encrypted. It has no readable text; Data must be passed back
+// unchanged in later turns.
+type RedactedThinkingBlock struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+// GetType returns the type of the content block.
+func (t RedactedThinkingBlock) GetType() string {
+	return t.Type
+}
+
+func (t RedactedThinkingBlock) isContentBlock() {}
+
 // ToolUseBlock represents a tool use request from Claude.
 type ToolUseBlock struct {
 	Type  string                 `json:"type"`
@@ -93,41 +112,150 @@ func (t ToolResultBlock) GetType() string {
 
 func (t ToolResultBlock) isContentBlock() {}
 
+// AsText returns the text of the result. When Content is a list of blocks,
+// it joins the text of its text blocks with newlines. It returns false when
+// the result holds no text.
+func (t ToolResultBlock) AsText() (string, bool) {
+	if text, ok := t.Content.(string); ok {
+		return text, true
+	}
+	blocks, ok := t.AsBlocks()
+	if !ok {
+		return "", false
+	}
+	var texts []string
+	for _, block := range blocks {
+		if text, ok := block.(*TextBlock); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	if len(texts) == 0 {
+		return "", false
+	}
+	return strings.Join(texts, "\n"), true
+}
+
+// AsBlocks returns the result's content as blocks. String content becomes
+// a single text block, and blocks of types UnmarshalContentBlock does not
+// know are kept as a RawContentBlock. It returns false when Content is nil
+// or of an unexpected form.
+func (t ToolResultBlock) AsBlocks() ([]ContentBlock, bool) {
+	switch content := t.Content.(type) {
+	case string:
+		return []ContentBlock{&TextBlock{Type: "text", Text: content}}, true
+	case []ContentBlock:
+		return content, true
+	case []interface{}:
+		blocks := make([]ContentBlock, 0, len(content))
+		for _, item := range content {
+			raw, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			blocks = append(blocks, toolResultContentBlock(raw))
+		}
+		return blocks, true
+	case []map[string]interface{}:
+		blocks := make([]ContentBlock, 0, len(content))
+		for _, raw := range content {
+			blocks = append(blocks, toolResultContentBlock(raw))
+		}
+		return blocks, true
+	default:
+		return nil, false
+	}
+}
+
+// toolResultContentBlock converts a decoded block of a tool result to its
+// typed form.
+func toolResultContentBlock(raw map[string]interface{}) ContentBlock {
+	data, err := jsonMarshal(raw)
+	if err != nil {
+		return RawContentBlock(raw)
+	}
+	block, err := UnmarshalContentBlock(data)
+	if err != nil {
+		return RawContentBlock(raw)
+	}
+	return block
+}
+
 // UnmarshalContentBlock unmarshals a JSON content block into the appropriate type.
 func UnmarshalContentBlock(data []byte) (ContentBlock, error) {
 	var typeCheck struct {
 		Type string `json:"type"`
 	}
-	if err := json.Unmarshal(data, &typeCheck); err != nil {
+	if err := jsonUnmarshal(data, &typeCheck); err != nil {
 		return nil, NewCLIJSONDecodeErrorWithCause("failed to determine content block type", string(data), err)
 	}
 
 	switch typeCheck.Type {
 	case "text":
 		var block TextBlock
-		if err := json.Unmarshal(data, &block); err != nil {
+		if err := jsonUnmarshal(data, &block); err != nil {
 			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal text block", string(data), err)
 		}
 		return &block, nil
 	case "thinking":
 		var block ThinkingBlock
-		if err := json.Unmarshal(data, &block); err != nil {
+		if err := jsonUnmarshal(data, &block); err != nil {
 			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal thinking block", string(data), err)
 		}
 		return &block, nil
+	case "redacted_thinking":
+		var block RedactedThinkingBlock
+		if err := jsonUnmarshal(data, &block); err != nil {
+			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal redacted_thinking block", string(data), err)
+		}
+		return &block, nil
 	case "tool_use":
 		var block ToolUseBlock
-		if err := json.Unmarshal(data, &block); err != nil {
+		if err := jsonUnmarshal(data, &block); err != nil {
 			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal tool_use block", string(data), err)
 		}
 		return &block, nil
 	case "tool_result":
 		var block ToolResultBlock
-		if err := json.Unmarshal(data, &block); err != nil {
+		if err := jsonUnmarshal(data, &block); err != nil {
 			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal tool_result block", string(data), err)
 		}
 		return &block, nil
+	case "image":
+		var block ImageBlock
+		if err := jsonUnmarshal(data, &block); err != nil {
+			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal image block", string(data), err)
+		}
+		return &block, nil
+	case "document":
+		var block DocumentBlock
+		if err := jsonUnmarshal(data, &block); err != nil {
+			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal document block", string(data), err)
+		}
+		return &block, nil
+	case "server_tool_use", "mcp_tool_use":
+		var block ServerToolUseBlock
+		if err := jsonUnmarshal(data, &block); err != nil {
+			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal "+typeCheck.Type+" block", string(data), err)
+		}
+		return &block, nil
 	default:
+		if fn, ok := registeredBlockType(typeCheck.Type); ok {
+			block, err := fn(data)
+			if err != nil {
+				return nil, NewMessageParseErrorWithCause("failed to unmarshal registered content block type", typeCheck.Type, err)
+			}
+			if block == nil {
+				return nil, NewMessageParseErrorWithType("registered unmarshaler returned no content block", typeCheck.Type)
+			}
+			return block, nil
+		}
+		if isServerToolResult(typeCheck.Type) {
+			var block ServerToolResultBlock
+			if err := jsonUnmarshal(data, &block); err != nil {
+				return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal "+typeCheck.Type+" block", string(data), err)
+			}
+			return &block, nil
+		}
 		return nil, NewMessageParseErrorWithType("unknown content block type", typeCheck.Type)
 	}
 }
@@ -233,6 +361,34 @@ func (m *JSONMessage) MarshalJSON() ([]byte, error) {
 	return m.Data, nil
 }
 
+// MarshalJSON encodes the message in the CLI's format, with the content
+// nested in a "message" object, so it can be parsed back or sent to the CLI.
+func (m *UserMessage) MarshalJSON() ([]byte, error) {
+	type apiMessage struct {
+		Role    string      `json:"role"`
+		Content interface{} `json:"content"`
+	}
+	return jsonMarshal(&struct {
+		Type            string     `json:"type"`
+		Message         apiMessage `json:"message"`
+		ParentToolUseID *string    `json:"parent_tool_use_id"`
+		UUID            *string    `json:"uuid,omitempty"`
+	}{
+		Type:            messageType(m.Type, "user"),
+		Message:         apiMessage{Role: "user", Content: m.Content},
+		ParentToolUseID: m.ParentToolUseID,
+		UUID:            m.UUID,
+	})
+}
+
+// messageType returns t, or fallback for a message built without its type.
+func messageType(t, fallback string) string {
+	if t == "" {
+		return fallback
+	}
+	return t
+}
+
 // UnmarshalJSON implements custom unmarshaling for UserMessage to handle content union type.
 func (m *UserMessage) UnmarshalJSON(data []byte) error {
 	type Alias UserMessage
@@ -245,7 +401,7 @@ func (m *UserMessage) UnmarshalJSON(data []byte) error {
 		Alias: (*Alias)(m),
 	}
 
-	if err := json.Unmarshal(data, &aux); err != nil {
+	if err := jsonUnmarshal(data, &aux); err != nil {
 		return err
 	}
 
@@ -264,14 +420,14 @@ func (m *UserMessage) UnmarshalJSON(data []byte) error {
 		// Also extract parent_tool_use_id from nested message if present
 		if parentToolUseID, ok := aux.Message["parent_tool_use_id"]; ok {
 			var id string
-			if err := json.Unmarshal(parentToolUseID, &id); err == nil {
+			if err := jsonUnmarshal(parentToolUseID, &id); err == nil {
 				m.ParentToolUseID = &id
 			}
 		}
 		// Extract uuid from nested message if present
 		if uuidRaw, ok := aux.Message["uuid"]; ok {
 			var id string
-			if err := json.Unmarshal(uuidRaw, &id); err == nil {
+			if err := jsonUnmarshal(uuidRaw, &id); err == nil {
 				m.UUID = &id
 			}
 		}
@@ -289,14 +445,14 @@ func (m *UserMessage) UnmarshalJSON(data []byte) error {
 
 	// Try to unmarshal as string first
 	var contentStr string
-	if err := json.Unmarshal(contentRaw, &contentStr); err == nil {
+	if err := jsonUnmarshal(contentRaw, &contentStr); err == nil {
 		m.Content = contentStr
 		return nil
 	}
 
 	// Try to unmarshal as array of content blocks
 	var contentArr []json.RawMessage
-	if err := json.Unmarshal(contentRaw, &contentArr); err == nil {
+	if err := jsonUnmarshal(contentRaw, &contentArr); err == nil {
 		blocks := make([]ContentBlock, len(contentArr))
 		for i, rawBlock := range contentArr {
 			block, err := UnmarshalContentBlock(rawBlock)
@@ -319,6 +475,9 @@ type AssistantMessage struct {
 	Model           string                 `json:"model"`
 	ParentToolUseID *string                `json:"parent_tool_use_id,omitempty"`
 	Error           *AssistantMessageError `json:"error,omitempty"`
+	UUID            *string                `json:"uuid,omitempty"`
+	MessageID       string                 `json:"id,omitempty"`    // API message ID, shared by the messages of one turn
+	Usage           *TokenUsage            `json:"usage,omitempty"` // Tokens of the turn so far
 }
 
 // GetMessageType returns the type of the message.
@@ -344,7 +503,7 @@ func (m *AssistantMessage) UnmarshalJSON(data []byte) error {
 		Alias: (*Alias)(m),
 	}
 
-	if err := json.Unmarshal(data, &aux); err != nil {
+	if err := jsonUnmarshal(data, &aux); err != nil {
 		return err
 	}
 
@@ -354,21 +513,33 @@ func (m *AssistantMessage) UnmarshalJSON(data []byte) error {
 	if aux.Message != nil {
 		if contentRaw, ok := aux.Message["content"]; ok {
 			var nested []json.RawMessage
-			if err := json.Unmarshal(contentRaw, &nested); err == nil {
+			if err := jsonUnmarshal(contentRaw, &nested); err == nil {
 				contentBlocks = nested
 			}
 		}
 		// Also extract model from nested message if present
 		if modelRaw, ok := aux.Message["model"]; ok {
 			var model string
-			if err := json.Unmarshal(modelRaw, &model); err == nil {
+			if err := jsonUnmarshal(modelRaw, &model); err == nil {
 				m.Model = model
 			}
 		}
+		if idRaw, ok := aux.Message["id"]; ok {
+			var id string
+			if err := jsonUnmarshal(idRaw, &id); err == nil {
+				m.MessageID = id
+			}
+		}
+		if usageRaw, ok := aux.Message["usage"]; ok {
+			var usage TokenUsage
+			if err := jsonUnmarshal(usageRaw, &usage); err == nil {
+				m.Usage = &usage
+			}
+		}
 		// Extract error field for rate limit/other errors
 		if errRaw, ok := aux.Message["error"]; ok {
 			var errCode string
-			if err := json.Unmarshal(errRaw, &errCode); err == nil && errCode != "" {
+			if err := jsonUnmarshal(errRaw, &errCode); err == nil && errCode != "" {
 				code := AssistantMessageError(errCode)
 				m.Error = &code
 			}
@@ -393,13 +564,31 @@ func (m *AssistantMessage) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// MarshalJSON implements custom marshaling for AssistantMessage to handle content blocks.
+// MarshalJSON encodes the message in the CLI's format, with the content
+// nested in a "message" object, so it can be parsed back or replayed.
 func (m *AssistantMessage) MarshalJSON() ([]byte, error) {
-	type Alias AssistantMessage
-	return json.Marshal(&struct {
-		*Alias
+	type apiMessage struct {
+		ID      string                 `json:"id,omitempty"`
+		Role    string                 `json:"role"`
+		Model   string                 `json:"model,omitempty"`
+		Content []ContentBlock         `json:"content"`
+		Usage   *TokenUsage            `json:"usage,omitempty"`
+		Error   *AssistantMessageError `json:"error,omitempty"`
+	}
+	content := m.Content
+	if content == nil {
+		content = []ContentBlock{}
+	}
+	return jsonMarshal(&struct {
+		Type            string     `json:"type"`
+		Message         apiMessage `json:"message"`
+		ParentToolUseID *string    `json:"parent_tool_use_id"`
+		UUID            *string    `json:"uuid,omitempty"`
 	}{
-		Alias: (*Alias)(m),
+		Type:            messageType(m.Type, "assistant"),
+		Message:         apiMessage{ID: m.MessageID, Role: "assistant", Model: m.Model, Content: content, Usage: m.Usage, Error: m.Error},
+		ParentToolUseID: m.ParentToolUseID,
+		UUID:            m.UUID,
 	})
 }
 
@@ -464,6 +653,20 @@ type ResultMessage struct {
 	Usage            map[string]interface{} `json:"usage,omitempty"`
 	Result           *string                `json:"result,omitempty"`
 	StructuredOutput interface{}            `json:"structured_output,omitempty"`
+	ModelUsage       map[string]ModelUsage  `json:"modelUsage,omitempty"` // Tokens and cost of the session by model
+
+	// StructuredOutputErrors lists where StructuredOutput does not match the
+	// JSON schema of the output format, as found by the SDK. It is empty when
+	// the output matches or no schema was set.
+	StructuredOutputErrors schema.ValidationErrors `json:"-"`
+
+	// Timings is the latency breakdown of the query, measured by the SDK.
+	// It is nil for results the SDK did not see the prompt of.
+	Timings *Timings `json:"-"`
+
+	// CorrelationID is the correlation ID of the query, see
+	// ContextWithCorrelationID.
+	CorrelationID string `json:"-"`
 }
 
 // GetMessageType returns the type of the message.
@@ -478,6 +681,66 @@ func (m *ResultMessage) ShouldDisplayToUser() bool {
 
 func (m *ResultMessage) isMessage() {}
 
+// ResultMessage subtypes reported by the CLI.
+const (
+	ResultSubtypeSuccess                         = "success"
+	ResultSubtypeErrorMaxTurns                   = "error_max_turns"
+	ResultSubtypeErrorMaxBudgetUSD               = "error_max_budget_usd"
+	ResultSubtypeErrorDuringExecution            = "error_during_execution"
+	ResultSubtypeErrorMaxStructuredOutputRetries = "error_max_structured_output_retries"
+)
+
+// StopReason is why a query ended, as returned by ResultMessage.StopReason.
+type StopReason string
+
+const (
+	StopReasonCompleted      StopReason = "completed"       // Claude finished its response
+	StopReasonMaxTurns       StopReason = "max_turns"       // The query reached WithMaxTurns
+	StopReasonBudgetExceeded StopReason = "budget_exceeded" // The query reached WithMaxBudgetUSD
+	StopReasonInterrupted    StopReason = "interrupted"     // The query was interrupted, such as by Client.Interrupt
+	StopReasonError          StopReason = "error"           // The query failed
+)
+
+// StopReason returns why the query ended, derived from the result's subtype
+// and fields.
+//
+// Example:
+//
+//	if result.StopReason() == types.StopReasonBudgetExceeded {
+//	    fmt.Printf("Stopped after spending $%.4f\n", *result.TotalCostUSD)
+//	}
+func (m *ResultMessage) StopReason() StopReason {
+	switch {
+	case m.Subtype == ResultSubtypeErrorMaxTurns:
+		return StopReasonMaxTurns
+	case m.Subtype == ResultSubtypeErrorMaxBudgetUSD:
+		return StopReasonBudgetExceeded
+	case strings.Contains(m.Subtype, "interrupt"),
+		m.Result != nil && strings.Contains(strings.ToLower(*m.Result), "interrupted by user"):
+		return StopReasonInterrupted
+	case m.IsError || strings.HasPrefix(m.Subtype, "error"):
+		return StopReasonError
+	default:
+		return StopReasonCompleted
+	}
+}
+
+// DecodeStructuredOutput decodes the structured output into v. Unlike the
+// function DecodeStructuredOutput, it does not validate the output.
+func (m *ResultMessage) DecodeStructuredOutput(v interface{}) error {
+	if m.StructuredOutput == nil {
+		return fmt.Errorf("result has no structured output")
+	}
+	data, err := jsonMarshal(m.StructuredOutput)
+	if err != nil {
+		return fmt.Errorf("encode structured output: %w", err)
+	}
+	if err := jsonUnmarshal(data, v); err != nil {
+		return fmt.Errorf("decode structured output: %w", err)
+	}
+	return nil
+}
+
 // StreamEvent represents a stream event for partial message updates during streaming.
 type StreamEvent struct {
 	Type            string                 `json:"type"`
@@ -485,6 +748,8 @@ type StreamEvent struct {
 	SessionID       string                 `json:"session_id"`
 	Event           map[string]interface{} `json:"event"` // The raw Anthropic API stream event
 	ParentToolUseID *string                `json:"parent_tool_use_id,omitempty"`
+
+	pooled bool // Decoded by UnmarshalMessagePooled and not yet released
 }
 
 // GetMessageType returns the type of the message.
@@ -502,45 +767,69 @@ func (m *StreamEvent) isMessage() {}
 // UnmarshalMessage unmarshals a JSON message into the appropriate message type.
 func UnmarshalMessage(data []byte) (Message, error) {
 	var typeCheck struct {
-		Type string `json:"type"`
+		Type    string `json:"type"`
+		Subtype string `json:"subtype"`
 	}
-	if err := json.Unmarshal(data, &typeCheck); err != nil {
+	if err := jsonUnmarshal(data, &typeCheck); err != nil {
 		return nil, NewCLIJSONDecodeErrorWithCause("failed to determine message type", string(data), err)
 	}
 
 	switch typeCheck.Type {
 	case "user":
 		var msg UserMessage
-		if err := json.Unmarshal(data, &msg); err != nil {
+		if err := jsonUnmarshal(data, &msg); err != nil {
 			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal user message", string(data), err)
 		}
 		return &msg, nil
 	case "assistant":
 		var msg AssistantMessage
-		if err := json.Unmarshal(data, &msg); err != nil {
+		if err := jsonUnmarshal(data, &msg); err != nil {
 			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal assistant message", string(data), err)
 		}
 		return &msg, nil
 	case "system", "control_request", "control_response":
+		if typeCheck.Type == "system" && typeCheck.Subtype == SystemSubtypeCompactBoundary {
+			var msg CompactionEvent
+			if err := jsonUnmarshal(data, &msg); err != nil {
+				return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal compaction event", string(data), err)
+			}
+			return &msg, nil
+		}
 		// system, control_request, and control_response are all SystemMessage types
 		var msg SystemMessage
-		if err := json.Unmarshal(data, &msg); err != nil {
+		if err := jsonUnmarshal(data, &msg); err != nil {
 			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal system message", string(data), err)
 		}
 		return &msg, nil
 	case "result":
 		var msg ResultMessage
-		if err := json.Unmarshal(data, &msg); err != nil {
+		if err := jsonUnmarshal(data, &msg); err != nil {
 			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal result message", string(data), err)
 		}
 		return &msg, nil
+	case "assistant_error":
+		var msg AssistantErrorEvent
+		if err := jsonUnmarshal(data, &msg); err != nil {
+			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal assistant error event", string(data), err)
+		}
+		return &msg, nil
 	case "stream_event":
 		var msg StreamEvent
-		if err := json.Unmarshal(data, &msg); err != nil {
+		if err := jsonUnmarshal(data, &msg); err != nil {
 			return nil, NewCLIJSONDecodeErrorWithCause("failed to unmarshal stream event", string(data), err)
 		}
 		return &msg, nil
 	default:
+		if fn, ok := registeredMessageType(typeCheck.Type); ok {
+			msg, err := fn(data)
+			if err != nil {
+				return nil, NewMessageParseErrorWithCause("failed to unmarshal registered message type", typeCheck.Type, err)
+			}
+			if msg == nil {
+				return nil, NewMessageParseErrorWithType("registered unmarshaler returned no message", typeCheck.Type)
+			}
+			return msg, nil
+		}
 		return nil, NewMessageParseErrorWithType("unknown message type", typeCheck.Type)
 	}
 }
@@ -2,6 +2,7 @@ package types
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -79,6 +80,16 @@ func TestUnmarshalContentBlock(t *testing.T) {
 			json:     `{"type":"tool_result","tool_use_id":"123"}`,
 			wantType: "tool_result",
 		},
+		{
+			name:     "redacted_thinking block",
+			json:     `{"type":"redacted_thinking","data":"EmwKAhgBEgy3va3pzix"}`,
+			wantType: "redacted_thinking",
+		},
+		{
+			name:     "image block",
+			json:     `{"type":"image","source":{"type":"base64","media_type":"image/png","data":"iVBORw0KGgo="}}`,
+			wantType: "image",
+		},
 	}
 
 	for _, tt := range tests {
@@ -94,6 +105,72 @@ func TestUnmarshalContentBlock(t *testing.T) {
 	}
 }
 
+// TestToolResultBlockContent tests the typed accessors of tool result content.
+func TestToolResultBlockContent(t *testing.T) {
+	parse := func(content string) ToolResultBlock {
+		t.Helper()
+		block, err := UnmarshalContentBlock([]byte(`{"type":"tool_result","tool_use_id":"1","content":` + content + `}`))
+		if err != nil {
+			t.Fatalf("UnmarshalContentBlock failed: %v", err)
+		}
+		return *block.(*ToolResultBlock)
+	}
+
+	tests := []struct {
+		name       string
+		block      ToolResultBlock
+		wantText   string
+		wantTextOK bool
+		wantTypes  []string
+	}{
+		{
+			name:       "string",
+			block:      parse(`"file1\nfile2"`),
+			wantText:   "file1\nfile2",
+			wantTextOK: true,
+			wantTypes:  []string{"text"},
+		},
+		{
+			name:       "blocks",
+			block:      parse(`[{"type":"text","text":"a"},{"type":"image","source":{"type":"url","url":"https://example.com/a.png"}},{"type":"text","text":"b"}]`),
+			wantText:   "a\nb",
+			wantTextOK: true,
+			wantTypes:  []string{"text", "image", "text"},
+		},
+		{
+			name:      "unknown block type",
+			block:     parse(`[{"type":"search_result","source":"web"}]`),
+			wantTypes: []string{"search_result"},
+		},
+		{
+			name:      "typed blocks",
+			block:     ToolResultBlock{Content: []ContentBlock{NewURLImageBlock("https://example.com/a.png")}},
+			wantTypes: []string{"image"},
+		},
+		{
+			name:  "no content",
+			block: parse(`null`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, ok := tt.block.AsText()
+			if text != tt.wantText || ok != tt.wantTextOK {
+				t.Errorf("AsText() = %q, %v; want %q, %v", text, ok, tt.wantText, tt.wantTextOK)
+			}
+			blocks, ok := tt.block.AsBlocks()
+			if ok != (tt.wantTypes != nil) || len(blocks) != len(tt.wantTypes) {
+				t.Fatalf("AsBlocks() = %v, %v; want types %v", blocks, ok, tt.wantTypes)
+			}
+			for i, block := range blocks {
+				if block.GetType() != tt.wantTypes[i] {
+					t.Errorf("block %d has type %s, want %s", i, block.GetType(), tt.wantTypes[i])
+				}
+			}
+		})
+	}
+}
+
 // TestUserMessageMarshaling tests JSON marshaling/unmarshaling of UserMessage.
 func TestUserMessageMarshaling(t *testing.T) {
 	t.Run("string content", func(t *testing.T) {
@@ -163,6 +240,38 @@ func TestUserMessageMarshaling(t *testing.T) {
 		}
 	})
 
+	t.Run("image content", func(t *testing.T) {
+		jsonData := `{
+			"type": "user",
+			"message": {
+				"role": "user",
+				"content": [
+					{"type": "text", "text": "What's in these?"},
+					{"type": "image", "source": {"type": "base64", "media_type": "image/png", "data": "iVBORw0KGgo="}},
+					{"type": "image", "source": {"type": "url", "url": "https://example.com/chart.png"}}
+				]
+			}
+		}`
+
+		var decoded UserMessage
+		if err := json.Unmarshal([]byte(jsonData), &decoded); err != nil {
+			t.Fatalf("failed to unmarshal UserMessage with images: %v", err)
+		}
+
+		blocks, ok := decoded.Content.([]ContentBlock)
+		if !ok || len(blocks) != 3 {
+			t.Fatalf("expected 3 content blocks, got %v", decoded.Content)
+		}
+		image, ok := blocks[1].(*ImageBlock)
+		if !ok || image.Source == nil || image.Source.MediaType != "image/png" || image.Source.Data != "iVBORw0KGgo=" {
+			t.Errorf("unexpected base64 image: %+v", blocks[1])
+		}
+		image, ok = blocks[2].(*ImageBlock)
+		if !ok || image.Source == nil || image.Source.Type != ImageSourceURL || image.Source.URL != "https://example.com/chart.png" {
+			t.Errorf("unexpected url image: %+v", blocks[2])
+		}
+	})
+
 	t.Run("top-level content array", func(t *testing.T) {
 		// Standard format with content at top level
 		jsonData := `{
@@ -261,3 +370,90 @@ func TestResultMessageMarshaling(t *testing.T) {
 		t.Fatalf("structured_output should be preserved")
 	}
 }
+
+// TestMessageMarshalRoundTrip tests that every message type marshals to JSON
+// that parses back into the same message.
+func TestMessageMarshalRoundTrip(t *testing.T) {
+	uuid := "user-uuid"
+	parent := "toolu_task"
+	isError := true
+	rateLimit := AssistantMessageErrorRateLimit
+	cost := 0.05
+	result := "Done"
+	messages := []Message{
+		&UserMessage{Type: "user", Content: "Hello", UUID: &uuid},
+		&UserMessage{Type: "user", ParentToolUseID: &parent, Content: []ContentBlock{
+			&TextBlock{Type: "text", Text: "Look at these"},
+			&ToolResultBlock{Type: "tool_result", ToolUseID: "toolu_1", Content: "output", IsError: &isError},
+			&ImageBlock{Type: "image", Source: &ImageSource{Type: ImageSourceURL, URL: "https://example.com/a.png"}},
+			&DocumentBlock{Type: "document", Source: DocumentSource{Type: DocumentSourceText, MediaType: "text/plain", Data: "notes"}, Title: "notes.txt"},
+		}},
+		&AssistantMessage{Type: "assistant", Model: "claude-sonnet-4-5", ParentToolUseID: &parent, Content: []ContentBlock{
+			&ThinkingBlock{Type: "thinking", Thinking: "Hmm", Signature: "sig"},
+			&TextBlock{Type: "text", Text: "Running it."},
+			&ToolUseBlock{Type: "tool_use", ID: "toolu_2", Name: "Bash", Input: map[string]interface{}{"command": "ls"}},
+		}},
+		&AssistantMessage{Type: "assistant", Content: []ContentBlock{}, Error: &rateLimit},
+		&SystemMessage{Type: "system", Subtype: "warning", Data: map[string]interface{}{"message": "careful"}},
+		&ResultMessage{Type: "result", Subtype: "success", DurationMs: 10, NumTurns: 1, SessionID: "s", TotalCostUSD: &cost, Result: &result, Usage: map[string]interface{}{"input_tokens": float64(5)}},
+		&StreamEvent{Type: "stream_event", UUID: "u", SessionID: "s", Event: map[string]interface{}{"type": "message_stop"}, ParentToolUseID: &parent},
+	}
+
+	for _, original := range messages {
+		t.Run(original.GetMessageType(), func(t *testing.T) {
+			data, err := json.Marshal(original)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			decoded, err := UnmarshalMessage(data)
+			if err != nil {
+				t.Fatalf("UnmarshalMessage failed: %v\n%s", err, data)
+			}
+			if !reflect.DeepEqual(decoded, original) {
+				t.Errorf("round trip changed the message:\ngot  %#v\nwant %#v\njson %s", decoded, original, data)
+			}
+		})
+	}
+}
+
+// TestMessageMarshalCLIFormat tests that user and assistant messages marshal
+// in the format the CLI reads and writes.
+func TestMessageMarshalCLIFormat(t *testing.T) {
+	data, err := json.Marshal(&UserMessage{Content: "Hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"type":"user","message":{"role":"user","content":"Hi"},"parent_tool_use_id":null}`; string(data) != want {
+		t.Errorf("user message: got %s, want %s", data, want)
+	}
+
+	data, err = json.Marshal(&AssistantMessage{Model: "claude-sonnet-4-5", Content: []ContentBlock{TextBlock{Type: "text", Text: "Hello"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"Hello"}]},"parent_tool_use_id":null}`; string(data) != want {
+		t.Errorf("assistant message: got %s, want %s", data, want)
+	}
+}
+
+// TestResultMessageStopReason tests deriving the stop reason of a result.
+func TestResultMessageStopReason(t *testing.T) {
+	interrupted := "[Request interrupted by user]"
+	tests := []struct {
+		result ResultMessage
+		want   StopReason
+	}{
+		{ResultMessage{Subtype: ResultSubtypeSuccess}, StopReasonCompleted},
+		{ResultMessage{Subtype: ResultSubtypeErrorMaxTurns, IsError: true}, StopReasonMaxTurns},
+		{ResultMessage{Subtype: ResultSubtypeErrorMaxBudgetUSD, IsError: true}, StopReasonBudgetExceeded},
+		{ResultMessage{Subtype: ResultSubtypeErrorDuringExecution, IsError: true, Result: &interrupted}, StopReasonInterrupted},
+		{ResultMessage{Subtype: ResultSubtypeErrorDuringExecution, IsError: true}, StopReasonError},
+		{ResultMessage{Subtype: ResultSubtypeErrorMaxStructuredOutputRetries}, StopReasonError},
+		{ResultMessage{Subtype: "success", IsError: true}, StopReasonError},
+	}
+	for _, tt := range tests {
+		if got := tt.result.StopReason(); got != tt.want {
+			t.Errorf("StopReason() of %s (is_error %v) = %s, want %s", tt.result.Subtype, tt.result.IsError, got, tt.want)
+		}
+	}
+}
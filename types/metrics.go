@@ -0,0 +1,87 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Metrics receives measurements of the SDK's work, for fleet observability
+// without wrapping every call site. Set it with WithMetrics; the metrics
+// package has a Prometheus implementation.
+//
+// The methods are called from the SDK's goroutines, so implementations must
+// be safe for concurrent use and return quickly. Embed NopMetrics to
+// implement only some of them, and to keep compiling when methods are
+// added.
+type Metrics interface {
+	// QueryCompleted is called for each ResultMessage.
+	QueryCompleted(m QueryMetrics)
+	// ToolCalled is called for each tool use Claude requests, whether or
+	// not it is allowed.
+	ToolCalled(tool string)
+	// SDKToolCompleted is called after each call to a tool of an SDK MCP
+	// server, with err set when the call failed or its result is an error.
+	SDKToolCompleted(tool string, duration time.Duration, err error)
+	// TransportError is called for each error reading from or writing to
+	// the CLI, including messages that cannot be parsed.
+	TransportError(err error)
+}
+
+// QueryMetrics are the measurements of a completed query.
+type QueryMetrics struct {
+	SessionID   string
+	Duration    time.Duration
+	APIDuration time.Duration // Time spent waiting for the API
+	Turns       int
+	IsError     bool
+	StopReason  StopReason
+	Usage       TokenUsage // Tokens of the query
+	// CostUSD is the cost of the query: the increase of the session's total
+	// cost since the previous result, so that costs add up over queries
+	CostUSD float64
+	// CorrelationID is the correlation ID of the query, see
+	// ContextWithCorrelationID. It identifies a single query, so do not
+	// use it as a label of aggregated metrics
+	CorrelationID string
+}
+
+// NewQueryMetrics returns the measurements of a result. previousCostUSD is
+// the total cost reported by the previous result of the session, or 0.
+func NewQueryMetrics(result *ResultMessage, previousCostUSD float64) QueryMetrics {
+	m := QueryMetrics{
+		SessionID:   result.SessionID,
+		Duration:    time.Duration(result.DurationMs) * time.Millisecond,
+		APIDuration: time.Duration(result.DurationAPIMs) * time.Millisecond,
+		Turns:       result.NumTurns,
+		IsError:     result.IsError,
+		StopReason:  result.StopReason(),
+	}
+	if result.Usage != nil {
+		if data, err := json.Marshal(result.Usage); err == nil {
+			_ = json.Unmarshal(data, &m.Usage)
+		}
+	}
+	if result.TotalCostUSD != nil {
+		m.CostUSD = *result.TotalCostUSD
+		if m.CostUSD >= previousCostUSD {
+			m.CostUSD -= previousCostUSD
+		}
+	}
+	return m
+}
+
+// NopMetrics is a Metrics that does nothing, for embedding in partial
+// implementations.
+type NopMetrics struct{}
+
+// QueryCompleted does nothing.
+func (NopMetrics) QueryCompleted(QueryMetrics) {}
+
+// ToolCalled does nothing.
+func (NopMetrics) ToolCalled(string) {}
+
+// SDKToolCompleted does nothing.
+func (NopMetrics) SDKToolCompleted(string, time.Duration, error) {}
+
+// TransportError does nothing.
+func (NopMetrics) TransportError(error) {}
@@ -2,6 +2,12 @@ package types
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/schema"
 )
 
 // SettingSource represents where settings are loaded from.
@@ -19,6 +25,9 @@ type SdkBeta string
 const (
 	// SdkBetaContext1M enables extended context window.
 	SdkBetaContext1M SdkBeta = "context-1m-2025-08-07"
+
+	// SdkBetaInterleavedThinking lets Claude think between tool calls.
+	SdkBetaInterleavedThinking SdkBeta = "interleaved-thinking-2025-05-14"
 )
 
 // SystemPromptPreset represents a preset system prompt configuration.
@@ -87,10 +96,38 @@ type HookCallbackFunc func(ctx context.Context, input interface{}, toolUseID *st
 
 // HookMatcher represents a hook matcher configuration.
 type HookMatcher struct {
-	Matcher *string            `json:"matcher,omitempty"` // Regex pattern for matching (e.g., "Bash", "Write|Edit")
-	Hooks   []HookCallbackFunc `json:"-"`                 // List of hook callback functions (not marshaled)
+	// Matcher selects tools by regex (e.g., "Bash", "Write|Edit") or by tool
+	// pattern (e.g., "mcp__calc__*", "Bash(git *)"); see ToolPattern.
+	Matcher *string            `json:"matcher,omitempty"`
+	Hooks   []HookCallbackFunc `json:"-"` // List of hook callback functions (not marshaled)
+
+	// Async runs the hooks in the background. The SDK acknowledges the CLI
+	// immediately with an AsyncHookJSONOutput, so the hooks cannot affect the
+	// session: their outputs are only validated and reported to HookMetrics.
+	// A hook still running after AsyncTimeout is reported as timed out.
+	Async        bool `json:"-"`
+	AsyncTimeout *int `json:"-"` // Milliseconds; defaults to DefaultAsyncHookTimeoutMs
+
+	// Command runs an external program as an additional hook, like Claude Code
+	// settings hooks. The hook input is sent as JSON on stdin and a JSON object
+	// on stdout is used as the output; exit status 2 blocks the action with
+	// stderr as the reason. The command runs through the shell in the session's
+	// working directory with a minimal environment (PATH, HOME, TMPDIR, LANG,
+	// CLAUDE_PROJECT_DIR) plus CommandEnv.
+	Command        string            `json:"-"`
+	TimeoutSeconds int               `json:"-"` // Defaults to DefaultHookCommandTimeoutSeconds
+	CommandEnv     map[string]string `json:"-"`
 }
 
+// DefaultHookCommandTimeoutSeconds is the default time limit for HookMatcher.Command.
+const DefaultHookCommandTimeoutSeconds = 60
+
+// HookMetricsFunc receives a record of each hook callback execution.
+// It may be called concurrently, from the goroutines handling hook callbacks
+// and running asynchronous hooks, so it must be safe for concurrent use. It
+// should return quickly.
+type HookMetricsFunc func(execution HookExecution)
+
 // StderrCallbackFunc is a callback function for stderr output from the CLI.
 type StderrCallbackFunc func(line string)
 
@@ -101,8 +138,9 @@ type ClaudeAgentOptions struct {
 	Tools interface{} `json:"tools,omitempty"`
 
 	// Tool configuration
-	AllowedTools    []string `json:"allowed_tools,omitempty"`
-	DisallowedTools []string `json:"disallowed_tools,omitempty"`
+	AllowedTools      []string `json:"allowed_tools,omitempty"`
+	DisallowedTools   []string `json:"disallowed_tools,omitempty"`
+	AutoAllowSdkTools bool     `json:"-"` // Also allow every tool of the SDK MCP servers
 
 	// System prompt - can be string or SystemPromptPreset
 	SystemPrompt interface{} `json:"system_prompt,omitempty"`
@@ -128,6 +166,7 @@ type ClaudeAgentOptions struct {
 	ContinueConversation bool    `json:"continue_conversation,omitempty"`
 	Resume               *string `json:"resume,omitempty"`
 	ForkSession          bool    `json:"fork_session,omitempty"`
+	SessionID            *string `json:"session_id,omitempty"` // UUID for the new session (--session-id)
 
 	// Model and execution limits
 	Model             *string   `json:"model,omitempty"`
@@ -136,14 +175,36 @@ type ClaudeAgentOptions struct {
 	MaxThinkingTokens *int      `json:"max_thinking_tokens,omitempty"` // Maximum tokens for extended thinking
 	MaxBudgetUSD      *float64  `json:"max_budget_usd,omitempty"`      // Maximum budget in USD for this query
 	Betas             []SdkBeta `json:"betas,omitempty"`               // Beta feature flags
+	Budget            Budget    `json:"-"`                             // Spending ceilings shared across queries and clients
+
+	// Context compaction
+	AutoCompactThreshold *int `json:"-"` // Percent of the context window at which the CLI compacts
+	DisableAutoCompact   bool `json:"-"` // Only compact when asked with Client.Compact
+
+	// Token alerts, called when the session's tokens reach thresholds
+	TokenAlerts []TokenAlert `json:"-"`
 
 	// API configuration
-	BaseURL *string `json:"base_url,omitempty"` // Custom Anthropic API base URL (ANTHROPIC_BASE_URL)
+	BaseURL  *string         `json:"base_url,omitempty"` // Custom Anthropic API base URL (ANTHROPIC_BASE_URL)
+	Proxy    *string         `json:"proxy,omitempty"`    // HTTP(S) proxy URL for the CLI (HTTP_PROXY and HTTPS_PROXY)
+	Provider *ProviderConfig `json:"-"`                  // Amazon Bedrock or Google Vertex AI instead of the Anthropic API
+
+	// Credentials, passed to the CLI in its environment and never encoded,
+	// logged or included in errors
+	APIKey     *string `json:"-"` // ANTHROPIC_API_KEY
+	OAuthToken *string `json:"-"` // CLAUDE_CODE_OAUTH_TOKEN
 
 	// Working directory and CLI path
 	CWD     *string `json:"cwd,omitempty"`
 	CLIPath *string `json:"cli_path,omitempty"`
 
+	// Oldest CLI version to run with, such as "2.x" or "2.0.45"
+	MinCLIVersion *string `json:"-"`
+
+	// Where to look for the CLI when CLIPath is not set, in order, instead of
+	// the default locations
+	CLISearchPaths []string `json:"-"`
+
 	// Settings
 	Settings       *string         `json:"settings,omitempty"`
 	SettingSources []SettingSource `json:"setting_sources,omitempty"`
@@ -156,18 +217,23 @@ type ClaudeAgentOptions struct {
 	// Buffer configuration
 	MaxBufferSize          *int `json:"max_buffer_size,omitempty"`          // Max bytes when buffering CLI stdout
 	MessageChannelCapacity *int `json:"message_channel_capacity,omitempty"` // Capacity for message channels
+	MessagePooling         bool `json:"-"`                                  // Reuse StreamEvents; see WithMessagePooling
 
 	// Streaming configuration
 	IncludePartialMessages bool `json:"include_partial_messages,omitempty"`
 
 	// Output format for structured outputs (e.g., JSON schema)
 	OutputFormat map[string]interface{} `json:"output_format,omitempty"`
+	// Turns asking Claude to fix structured output that does not match the
+	// schema; see WithStructuredOutputRetries
+	StructuredOutputRetries int `json:"-"`
 
 	// User identifier
 	User *string `json:"user,omitempty"`
 
 	// Agent definitions
-	Agents map[string]AgentDefinition `json:"agents,omitempty"`
+	Agents           map[string]AgentDefinition  `json:"agents,omitempty"`
+	AgentPermissions map[string]AgentPermissions `json:"-"` // Tool restrictions per agent name, enforced by the SDK
 
 	// Plugin configurations
 	Plugins []SdkPluginConfig `json:"plugins,omitempty"`
@@ -175,13 +241,45 @@ type ClaudeAgentOptions struct {
 	// File checkpointing
 	EnableFileCheckpointing bool `json:"enable_file_checkpointing,omitempty"`
 
+	// Permission telemetry
+	PermissionStatsInUsage bool `json:"-"` // Add the session's permission counts to ResultMessage.Usage
+
 	// Debug and diagnostics
-	Verbose bool `json:"-"` // Enable verbose debug logging
+	Verbose       bool           `json:"-"` // Enable verbose debug logging
+	Logger        *slog.Logger   `json:"-"` // Receives the SDK's logs instead of stderr
+	Metrics       Metrics        `json:"-"` // Receives measurements of queries, tools and transport errors
+	Tracer        Tracer         `json:"-"` // Creates the SDK's spans; nil records none
+	StrictParsing bool           `json:"-"` // End the message stream at the first message with a ParseIssue
+	OnParseIssue  ParseIssueFunc `json:"-"` // Receives data in CLI messages that the SDK does not understand
+
+	// Lifecycle events of the CLI subprocess, for alerting on failures
+	LifecycleListener LifecycleListener `json:"-"`
+
+	// Known problems the CLI reports on stderr, such as auth failures
+	DiagnosticHandler DiagnosticHandler `json:"-"`
+
+	// File recording every line sent to and received from the CLI
+	WireDump string `json:"-"`
+
+	// Wire dump replayed instead of running the CLI
+	Replay *Replay `json:"-"`
 
 	// Callbacks (not marshaled to JSON)
-	CanUseTool CanUseToolFunc              `json:"-"`
-	Hooks      map[HookEvent][]HookMatcher `json:"-"`
-	Stderr     StderrCallbackFunc          `json:"-"`
+	CanUseTool      CanUseToolFunc                  `json:"-"`
+	Hooks           map[HookEvent][]HookMatcher     `json:"-"`
+	HookMetrics     HookMetricsFunc                 `json:"-"` // Called after each hook callback runs
+	HookExecution   map[HookEvent]HookExecutionMode `json:"-"` // Serial or parallel hook execution per event
+	PermissionAudit PermissionAuditSink             `json:"-"` // Receives every permission decision
+	ToolRateLimiter *ToolRateLimiter                `json:"-"` // Limits tool calls per time window
+	DryRun          *DryRun                         `json:"-"` // Intercepts state-changing tools and records a change plan
+	Redactor        *Redactor                       `json:"-"` // Redacts secrets from tool inputs and SDK MCP tool results
+	ToolProgress    ToolProgressFunc                `json:"-"` // Receives progress reported by SDK MCP tools
+	ToolPanic       ToolPanicFunc                   `json:"-"` // Receives panics recovered from SDK MCP tool handlers
+	Caller          interface{}                     `json:"-"` // Identity passed to SDK MCP tool handlers, see CallerFrom
+	OnPlanReady     PlanReadyFunc                   `json:"-"` // Reviews plans presented in plan mode
+	PlanExecution   PermissionMode                  `json:"-"` // Permission mode after a plan is approved
+	RetryPolicy     *RetryPolicy                    `json:"-"` // Advice in AssistantErrorEvents; defaults to DefaultRetryPolicy
+	Stderr          StderrCallbackFunc              `json:"-"`
 }
 
 // NewClaudeAgentOptions creates a new ClaudeAgentOptions with sensible defaults.
@@ -197,6 +295,64 @@ func NewClaudeAgentOptions() *ClaudeAgentOptions {
 	}
 }
 
+// Validate reports the first invalid option: a malformed tool rule in
+// AllowedTools, DisallowedTools, DryRun or AgentPermissions, an invalid rate
+// limit, SDK MCP tool name, proxy, session ID, thinking, provider,
+// compaction, minimum CLI version or token alert setting, or plan mode
+// without a CanUseTool callback for the execution mode. NewClient and Query
+// call it before starting the CLI.
+func (o *ClaudeAgentOptions) Validate() error {
+	if err := ValidateToolRules(o.AllowedTools); err != nil {
+		return fmt.Errorf("invalid allowed tools: %w", err)
+	}
+	if err := ValidateToolRules(o.DisallowedTools); err != nil {
+		return fmt.Errorf("invalid disallowed tools: %w", err)
+	}
+	if o.DryRun != nil {
+		if err := ValidateToolRules(o.DryRun.Tools); err != nil {
+			return fmt.Errorf("invalid dry run tools: %w", err)
+		}
+	}
+	if err := o.ToolRateLimiter.Validate(); err != nil {
+		return fmt.Errorf("invalid tool rate limit: %w", err)
+	}
+	if o.AutoAllowSdkTools {
+		if err := o.ValidateSdkToolNames(); err != nil {
+			return fmt.Errorf("invalid SDK MCP tool name: %w", err)
+		}
+	}
+	for _, validate := range []func() error{
+		o.ValidateProxy,
+		o.ValidateSessionID,
+		o.ValidateThinking,
+		o.ValidateProvider,
+		o.ValidateCompaction,
+		o.ValidateMinCLIVersion,
+		o.ValidateTokenAlerts,
+	} {
+		if err := validate(); err != nil {
+			return err
+		}
+	}
+	for name, permissions := range o.AgentPermissions {
+		if err := permissions.Validate(); err != nil {
+			return fmt.Errorf("invalid permissions for agent %s: %w", name, err)
+		}
+	}
+	if o.OnPlanReady != nil && o.CanUseTool == nil && o.PlanExecution != PermissionModeBypassPermissions {
+		return fmt.Errorf("plan mode with execution mode %q requires a CanUseTool callback for the permission requests after the plan is approved", planExecutionMode(o.PlanExecution))
+	}
+	return nil
+}
+
+// WithAutoAllowSdkTools pre-approves every tool of the SDK MCP servers in
+// McpServers, so their "mcp__<server>__<tool>" names need not be listed in
+// WithAllowedTools.
+func (o *ClaudeAgentOptions) WithAutoAllowSdkTools(enabled bool) *ClaudeAgentOptions {
+	o.AutoAllowSdkTools = enabled
+	return o
+}
+
 // WithAllowedTools sets the allowed tools.
 func (o *ClaudeAgentOptions) WithAllowedTools(tools ...string) *ClaudeAgentOptions {
 	o.AllowedTools = tools
@@ -276,6 +432,16 @@ func (o *ClaudeAgentOptions) WithForkSession(fork bool) *ClaudeAgentOptions {
 	return o
 }
 
+// WithSessionID sets the ID of the new session, which must be a UUID, so
+// that callers can choose IDs that correlate with their own storage instead
+// of reading them from the init message. With WithResume or
+// WithContinueConversation, it requires WithForkSession(true) and becomes
+// the ID of the fork.
+func (o *ClaudeAgentOptions) WithSessionID(sessionID string) *ClaudeAgentOptions {
+	o.SessionID = &sessionID
+	return o
+}
+
 // WithModel sets the model to use.
 func (o *ClaudeAgentOptions) WithModel(model string) *ClaudeAgentOptions {
 	o.Model = &model
@@ -307,6 +473,37 @@ func (o *ClaudeAgentOptions) WithMaxThinkingTokens(maxTokens int) *ClaudeAgentOp
 	return o
 }
 
+// WithThinking enables extended thinking with a budget of budgetTokens,
+// at least MinThinkingBudgetTokens, or disables it. It sets
+// MaxThinkingTokens, which is 0 when thinking is disabled. NewClient and
+// Query check that the model and fallback model support thinking; add
+// SdkBetaInterleavedThinking with WithBetas to let Claude also think between
+// tool calls.
+func (o *ClaudeAgentOptions) WithThinking(enabled bool, budgetTokens int) *ClaudeAgentOptions {
+	if !enabled {
+		budgetTokens = 0
+	}
+	o.MaxThinkingTokens = &budgetTokens
+	return o
+}
+
+// WithAutoCompact makes the CLI compact the conversation context once it
+// fills thresholdPercent (1 to 100) of the model's context window, instead
+// of at the CLI's default threshold. A lower threshold keeps long sessions
+// further from the limit at the cost of compacting more often.
+func (o *ClaudeAgentOptions) WithAutoCompact(thresholdPercent int) *ClaudeAgentOptions {
+	o.AutoCompactThreshold = &thresholdPercent
+	return o
+}
+
+// WithDisableAutoCompact stops the CLI from compacting the conversation
+// context on its own, leaving it to the application to call Client.Compact.
+// A session that fills the context window fails instead.
+func (o *ClaudeAgentOptions) WithDisableAutoCompact() *ClaudeAgentOptions {
+	o.DisableAutoCompact = true
+	return o
+}
+
 // WithMaxBudgetUSD sets the maximum budget in USD for this query.
 // This helps prevent unexpectedly high API costs by stopping execution when the limit is reached.
 func (o *ClaudeAgentOptions) WithMaxBudgetUSD(maxBudget float64) *ClaudeAgentOptions {
@@ -314,12 +511,80 @@ func (o *ClaudeAgentOptions) WithMaxBudgetUSD(maxBudget float64) *ClaudeAgentOpt
 	return o
 }
 
+// WithBudget refuses queries when budget does, such as when a user or
+// tenant has reached a spending ceiling, and charges it the cost of each
+// query. Unlike WithMaxBudgetUSD, which stops one query, a budget.Manager
+// accumulates costs across queries, sessions and clients.
+func (o *ClaudeAgentOptions) WithBudget(budget Budget) *ClaudeAgentOptions {
+	o.Budget = budget
+	return o
+}
+
+// WithTokenAlert calls fn once when the tokens used by the session reach
+// threshold, counting input, output and cache tokens over its assistant
+// turns, so that the application can warn the user or call Client.Compact
+// before the context fills up. It can be called several times for several
+// thresholds. fn is called from the SDK's message loop and must return
+// quickly.
+func (o *ClaudeAgentOptions) WithTokenAlert(threshold int, fn TokenAlertFunc) *ClaudeAgentOptions {
+	o.TokenAlerts = append(o.TokenAlerts, TokenAlert{Threshold: threshold, Func: fn})
+	return o
+}
+
 // WithBaseURL sets the custom Anthropic API base URL.
 func (o *ClaudeAgentOptions) WithBaseURL(baseURL string) *ClaudeAgentOptions {
 	o.BaseURL = &baseURL
 	return o
 }
 
+// WithAPIKey sets the Anthropic API key of the CLI, passed as
+// ANTHROPIC_API_KEY, instead of the one in the environment. The SDK redacts
+// the key from the CLI's stderr, the messages and fields it logs to stderr
+// or the slog.Logger of WithLogger, and its errors.
+func (o *ClaudeAgentOptions) WithAPIKey(key string) *ClaudeAgentOptions {
+	o.APIKey = &key
+	return o
+}
+
+// WithOAuthToken sets the Claude OAuth token of the CLI, passed as
+// CLAUDE_CODE_OAUTH_TOKEN, such as one created with "claude setup-token".
+// It is redacted like the key of WithAPIKey.
+func (o *ClaudeAgentOptions) WithOAuthToken(token string) *ClaudeAgentOptions {
+	o.OAuthToken = &token
+	return o
+}
+
+// WithBedrock serves Claude through Amazon Bedrock in an AWS region, such
+// as "us-east-1", setting CLAUDE_CODE_USE_BEDROCK and AWS_REGION for the
+// CLI. AWS credentials are passed through from the environment, such as
+// AWS_PROFILE or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or can be set
+// with WithEnvVar.
+func (o *ClaudeAgentOptions) WithBedrock(region string) *ClaudeAgentOptions {
+	o.Provider = &ProviderConfig{Provider: ProviderBedrock, Region: region}
+	return o
+}
+
+// WithVertex serves Claude through Google Cloud Vertex AI in a project and
+// region, such as "us-east5", setting CLAUDE_CODE_USE_VERTEX,
+// ANTHROPIC_VERTEX_PROJECT_ID and CLOUD_ML_REGION for the CLI. Google
+// credentials are passed through from the environment, such as
+// GOOGLE_APPLICATION_CREDENTIALS, or come from gcloud.
+func (o *ClaudeAgentOptions) WithVertex(projectID, region string) *ClaudeAgentOptions {
+	o.Provider = &ProviderConfig{Provider: ProviderVertex, Region: region, ProjectID: projectID}
+	return o
+}
+
+// WithProxy routes the CLI's HTTP and HTTPS traffic, including to the API
+// and to HTTP and SSE MCP servers, through a proxy such as
+// "http://proxy.corp.example:3128", by setting HTTP_PROXY and HTTPS_PROXY
+// for the subprocess. Env entries for these variables take precedence. Pass
+// the same URL as mcpclient.HTTPOptions.Proxy for MCP servers the SDK
+// connects to itself.
+func (o *ClaudeAgentOptions) WithProxy(proxyURL string) *ClaudeAgentOptions {
+	o.Proxy = &proxyURL
+	return o
+}
+
 // WithCWD sets the working directory.
 func (o *ClaudeAgentOptions) WithCWD(cwd string) *ClaudeAgentOptions {
 	o.CWD = &cwd
@@ -332,6 +597,24 @@ func (o *ClaudeAgentOptions) WithCLIPath(cliPath string) *ClaudeAgentOptions {
 	return o
 }
 
+// WithCLISearchPaths sets where to look for the CLI when no CLI path is set,
+// in order, replacing the default locations and CLAUDE_AGENT_SDK_CLI_PATHS.
+// Each path is the CLI binary, a directory holding a "claude" binary, or
+// "$PATH" for the directories of the PATH environment variable; a leading
+// "~" is the home directory. The first CLI found is used.
+func (o *ClaudeAgentOptions) WithCLISearchPaths(paths ...string) *ClaudeAgentOptions {
+	o.CLISearchPaths = paths
+	return o
+}
+
+// WithMinCLIVersion makes Connect fail with an *UnsupportedFeatureError if
+// the installed CLI is older than minVersion, such as "2.x" or "2.0.45",
+// instead of failing later on flags or messages the CLI does not know.
+func (o *ClaudeAgentOptions) WithMinCLIVersion(minVersion string) *ClaudeAgentOptions {
+	o.MinCLIVersion = &minVersion
+	return o
+}
+
 // WithSettings sets the settings file path.
 func (o *ClaudeAgentOptions) WithSettings(settings string) *ClaudeAgentOptions {
 	o.Settings = &settings
@@ -386,6 +669,16 @@ func (o *ClaudeAgentOptions) WithMaxBufferSize(size int) *ClaudeAgentOptions {
 	return o
 }
 
+// WithMessagePooling decodes stream events into pooled structs, cutting
+// garbage collection when partial messages stream at a high rate. Each
+// received *StreamEvent must then be released with Release once handled, and
+// not used afterwards; events that are not released are only collected as
+// usual.
+func (o *ClaudeAgentOptions) WithMessagePooling(enabled bool) *ClaudeAgentOptions {
+	o.MessagePooling = enabled
+	return o
+}
+
 // WithOutputFormat sets the output format for structured outputs.
 func (o *ClaudeAgentOptions) WithOutputFormat(format map[string]interface{}) *ClaudeAgentOptions {
 	o.OutputFormat = format
@@ -401,6 +694,23 @@ func (o *ClaudeAgentOptions) WithJSONSchemaOutput(schema interface{}) *ClaudeAge
 	return o
 }
 
+// WithStructuredOutput sets output_format to the JSON schema generated from
+// the type of v, usually a zero struct. Decode the result into the same type
+// with DecodeStructuredOutput.
+func (o *ClaudeAgentOptions) WithStructuredOutput(v interface{}) *ClaudeAgentOptions {
+	return o.WithJSONSchemaOutput(schema.FromType(reflect.TypeOf(v)))
+}
+
+// WithStructuredOutputRetries makes the SDK answer a result whose
+// structured output does not match the JSON schema of the output format
+// with a turn asking Claude to fix it, up to retries times per query. The
+// rejected results are not delivered; the last result carries the remaining
+// problems in StructuredOutputErrors. It requires a connected Client.
+func (o *ClaudeAgentOptions) WithStructuredOutputRetries(retries int) *ClaudeAgentOptions {
+	o.StructuredOutputRetries = retries
+	return o
+}
+
 // WithMessageChannelCapacity sets the capacity for message channels.
 func (o *ClaudeAgentOptions) WithMessageChannelCapacity(capacity int) *ClaudeAgentOptions {
 	o.MessageChannelCapacity = &capacity
@@ -434,12 +744,78 @@ func (o *ClaudeAgentOptions) WithAgent(name string, agent AgentDefinition) *Clau
 	return o
 }
 
+// WithAgentPermissions restricts the tools the subagent called name may use.
+// The SDK denies the agent's disallowed tool calls in a PreToolUse hook and
+// routes its permission requests to permissions.CanUseTool when set. See
+// AgentScopedCanUseTool. Subagents without permissions are not restricted
+// beyond the session's own permission settings.
+func (o *ClaudeAgentOptions) WithAgentPermissions(name string, permissions AgentPermissions) *ClaudeAgentOptions {
+	if o.AgentPermissions == nil {
+		o.AgentPermissions = make(map[string]AgentPermissions)
+	}
+	o.AgentPermissions[name] = permissions
+	return o
+}
+
 // WithCanUseTool sets the tool permission callback.
 func (o *ClaudeAgentOptions) WithCanUseTool(callback CanUseToolFunc) *ClaudeAgentOptions {
 	o.CanUseTool = callback
 	return o
 }
 
+// WithPermissionHandler sets a typed permission callback. It replaces any
+// CanUseTool callback.
+func (o *ClaudeAgentOptions) WithPermissionHandler(handler PermissionHandlerFunc) *ClaudeAgentOptions {
+	o.CanUseTool = handler.CanUseTool()
+	return o
+}
+
+// WithDryRun previews a run without changing anything: Write, Edit,
+// MultiEdit, NotebookEdit and Bash calls are intercepted and recorded in
+// DryRun instead of running. Passing false turns the dry run off.
+func (o *ClaudeAgentOptions) WithDryRun(enabled bool) *ClaudeAgentOptions {
+	if !enabled {
+		o.DryRun = nil
+		return o
+	}
+	if o.DryRun == nil {
+		o.DryRun = NewDryRun()
+	}
+	return o
+}
+
+// WithDryRunTools adds tool patterns, such as MCP tools with side effects,
+// to those a dry run intercepts. It enables the dry run.
+func (o *ClaudeAgentOptions) WithDryRunTools(tools ...string) *ClaudeAgentOptions {
+	o.WithDryRun(true)
+	o.DryRun.Tools = append(o.DryRun.Tools, tools...)
+	return o
+}
+
+// WithRedaction redacts secrets from tool inputs before permission callbacks
+// see them and from SDK MCP tool results before the model sees them, using
+// detectors or DefaultSecretDetectors. Redactor.Report lists what was redacted.
+func (o *ClaudeAgentOptions) WithRedaction(detectors ...SecretDetector) *ClaudeAgentOptions {
+	o.Redactor = NewRedactor(detectors...)
+	return o
+}
+
+// WithPlanMode starts the session in plan mode. When Claude presents its plan
+// with the ExitPlanMode tool, onPlanReady decides whether to approve it. An
+// approved plan switches the session to executionMode and Claude starts
+// executing; a rejected plan keeps Claude planning. Other permission requests
+// still go to the CanUseTool callback, which Validate requires unless
+// executionMode is PermissionModeBypassPermissions: in the other modes the
+// CLI keeps asking for permission after the plan is approved. See
+// PlanModeCanUseTool.
+func (o *ClaudeAgentOptions) WithPlanMode(onPlanReady PlanReadyFunc, executionMode PermissionMode) *ClaudeAgentOptions {
+	plan := PermissionModePlan
+	o.PermissionMode = &plan
+	o.OnPlanReady = onPlanReady
+	o.PlanExecution = executionMode
+	return o
+}
+
 // WithHooks sets the hook configurations.
 func (o *ClaudeAgentOptions) WithHooks(hooks map[HookEvent][]HookMatcher) *ClaudeAgentOptions {
 	o.Hooks = hooks
@@ -455,6 +831,90 @@ func (o *ClaudeAgentOptions) WithHook(event HookEvent, matcher HookMatcher) *Cla
 	return o
 }
 
+// WithHookChain registers a hook chain for a specific event as a single callback.
+func (o *ClaudeAgentOptions) WithHookChain(event HookEvent, chain *HookChain) *ClaudeAgentOptions {
+	return o.WithHook(event, chain.Matcher())
+}
+
+// WithHookMetrics sets a callback that receives timing, error and decision
+// details for every hook callback execution. The callback may be called
+// concurrently; see HookMetricsFunc.
+func (o *ClaudeAgentOptions) WithHookMetrics(callback HookMetricsFunc) *ClaudeAgentOptions {
+	o.HookMetrics = callback
+	return o
+}
+
+// WithHookExecution sets whether the hooks registered for an event run
+// serially or in parallel. See HookExecutionMode.
+func (o *ClaudeAgentOptions) WithHookExecution(event HookEvent, mode HookExecutionMode) *ClaudeAgentOptions {
+	if o.HookExecution == nil {
+		o.HookExecution = make(map[HookEvent]HookExecutionMode)
+	}
+	o.HookExecution[event] = mode
+	return o
+}
+
+// WithPermissionAudit records every permission request and decision made by
+// the CanUseTool callback or a PreToolUse hook to sink.
+func (o *ClaudeAgentOptions) WithPermissionAudit(sink PermissionAuditSink) *ClaudeAgentOptions {
+	o.PermissionAudit = sink
+	return o
+}
+
+// WithToolRateLimit allows at most limit calls of the tools matching pattern
+// (a tool name or pattern such as "mcp__github__*") per window. Calls past
+// the limit are denied, or delayed with WithToolRateLimitAction. The
+// counters are available from ToolRateLimiter.Stats.
+func (o *ClaudeAgentOptions) WithToolRateLimit(pattern string, limit int, window time.Duration) *ClaudeAgentOptions {
+	if o.ToolRateLimiter == nil {
+		o.ToolRateLimiter = NewToolRateLimiter()
+	}
+	o.ToolRateLimiter.SetLimit(pattern, limit, window)
+	return o
+}
+
+// WithToolRateLimitAction sets whether tool calls past a rate limit are
+// denied or delayed.
+func (o *ClaudeAgentOptions) WithToolRateLimitAction(action RateLimitAction) *ClaudeAgentOptions {
+	if o.ToolRateLimiter == nil {
+		o.ToolRateLimiter = NewToolRateLimiter()
+	}
+	o.ToolRateLimiter.Action = action
+	return o
+}
+
+// WithPermissionStatsInUsage adds the session's permission decision counts
+// to the usage map of each ResultMessage, under PermissionUsageKey.
+func (o *ClaudeAgentOptions) WithPermissionStatsInUsage(enabled bool) *ClaudeAgentOptions {
+	o.PermissionStatsInUsage = enabled
+	return o
+}
+
+// WithToolProgress sets a callback that receives the progress and partial
+// content SDK MCP tools report while they run. The updates are also sent to
+// the CLI as MCP notifications.
+func (o *ClaudeAgentOptions) WithToolProgress(callback ToolProgressFunc) *ClaudeAgentOptions {
+	o.ToolProgress = callback
+	return o
+}
+
+// WithToolPanicHandler sets a callback that receives the value and stack
+// trace of panics in SDK MCP tool handlers. A panicking call returns an
+// error result to Claude instead of crashing the program.
+func (o *ClaudeAgentOptions) WithToolPanicHandler(callback ToolPanicFunc) *ClaudeAgentOptions {
+	o.ToolPanic = callback
+	return o
+}
+
+// WithCaller attaches an application-level identity, such as a user ID or
+// token claims, to the session. SDK MCP tool handlers read it with
+// CallerFrom or CallerAs, so a multi-tenant service can authorize each
+// call for the user the session acts for.
+func (o *ClaudeAgentOptions) WithCaller(caller interface{}) *ClaudeAgentOptions {
+	o.Caller = caller
+	return o
+}
+
 // WithStderr sets the stderr callback.
 func (o *ClaudeAgentOptions) WithStderr(callback StderrCallbackFunc) *ClaudeAgentOptions {
 	o.Stderr = callback
@@ -467,6 +927,115 @@ func (o *ClaudeAgentOptions) WithVerbose(enabled bool) *ClaudeAgentOptions {
 	return o
 }
 
+// WithMetrics reports queries, tool calls, tokens, costs and transport
+// errors to m, such as a metrics.Prometheus.
+func (o *ClaudeAgentOptions) WithMetrics(m Metrics) *ClaudeAgentOptions {
+	o.Metrics = m
+	return o
+}
+
+// WithTracer creates the SDK's spans with t, such as an oteltracing.Tracer:
+// a claude.query span per query, with child spans for its tool uses,
+// control requests, hook callbacks and SDK MCP tool calls. The handlers of
+// SDK MCP tools, hooks and CanUseTool receive a context with their span, so
+// that the calls they make join the trace.
+func (o *ClaudeAgentOptions) WithTracer(t Tracer) *ClaudeAgentOptions {
+	o.Tracer = t
+	return o
+}
+
+// WithLogger sends the SDK's logs to l instead of stderr, with the fields
+// session_id, request_id, tool and hook where they apply. The handler of l
+// decides which levels are logged, so Verbose does not apply: enable
+// slog.LevelDebug for the messages Verbose would print.
+func (o *ClaudeAgentOptions) WithLogger(l *slog.Logger) *ClaudeAgentOptions {
+	o.Logger = l
+	return o
+}
+
+// WithRetryPolicy sets the policy advising whether and when to retry after
+// assistant errors such as rate limits, reported in AssistantErrorEvents.
+func (o *ClaudeAgentOptions) WithRetryPolicy(policy RetryPolicy) *ClaudeAgentOptions {
+	o.RetryPolicy = &policy
+	return o
+}
+
+// WithStrictParsing makes data in CLI messages that the SDK does not
+// understand, such as unknown message types, content block types and fields,
+// an error instead of silently dropping it. The first such message is
+// logged and ends the message stream, so that a CLI newer than the SDK is
+// noticed early. Combine it with WithParseIssueHandler to learn what was
+// not understood.
+func (o *ClaudeAgentOptions) WithStrictParsing(strict bool) *ClaudeAgentOptions {
+	o.StrictParsing = strict
+	return o
+}
+
+// WithParseIssueHandler sets a callback receiving each piece of data in CLI
+// messages that the SDK does not understand. Without WithStrictParsing the
+// messages are still delivered, so the callback can warn about version skew
+// without affecting the session.
+func (o *ClaudeAgentOptions) WithParseIssueHandler(callback ParseIssueFunc) *ClaudeAgentOptions {
+	o.OnParseIssue = callback
+	return o
+}
+
+// WithLifecycleListener sets a listener receiving the lifecycle events of
+// the CLI subprocess: its start, the connection, the session's init
+// message, skipped unparseable lines, errors written to stderr and its
+// exit. Failures that otherwise only show in verbose logs can be alerted
+// on:
+//
+//	opts.WithLifecycleListener(func(event types.LifecycleEvent) {
+//	    if exited, ok := event.(*types.ProcessExitedEvent); ok && !exited.Closed {
+//	        alert("claude CLI exited with code %d", exited.ExitCode)
+//	    }
+//	})
+func (o *ClaudeAgentOptions) WithLifecycleListener(listener LifecycleListener) *ClaudeAgentOptions {
+	o.LifecycleListener = listener
+	return o
+}
+
+// WithDiagnosticHandler sets a handler receiving the known problems the
+// CLI writes to stderr, parsed into typed diagnostics with a severity:
+// authentication failures, version warnings, Node.js errors, MCP servers
+// failing to start, sessions not found, and other lines starting with
+// "error" or "warning".
+//
+//	opts.WithDiagnosticHandler(func(d types.Diagnostic) {
+//	    if d.Kind == types.DiagnosticAuthFailure {
+//	        alert("claude CLI credentials: %s", d.Line)
+//	    }
+//	})
+func (o *ClaudeAgentOptions) WithDiagnosticHandler(handler DiagnosticHandler) *ClaudeAgentOptions {
+	o.DiagnosticHandler = handler
+	return o
+}
+
+// WithWireDump appends every JSON line sent to and received from the CLI to
+// the file at path, one WireRecord per line with its time and direction,
+// for debugging protocol issues. Secrets are redacted with the detectors of
+// WithRedaction, or the DefaultSecretDetectors, and the values of the
+// credential environment variables are replaced. The redactions are not
+// added to the Redactor's Report. The file is created with
+// mode 0600.
+func (o *ClaudeAgentOptions) WithWireDump(path string) *ClaudeAgentOptions {
+	o.WireDump = path
+	return o
+}
+
+// WithReplay replays a wire dump recorded with WithWireDump instead of
+// running the CLI, to reproduce a session deterministically or develop
+// against it offline. The recorded CLI messages are delivered in order,
+// with their original delays or as fast as they are read; each line the
+// SDK sent in the recording waits for the SDK to send its counterpart, such
+// as the next prompt. The SDK's control requests are answered with the
+// recorded responses.
+func (o *ClaudeAgentOptions) WithReplay(path string, timing ReplayTiming) *ClaudeAgentOptions {
+	o.Replay = &Replay{Path: path, Timing: timing}
+	return o
+}
+
 // WithDangerouslySkipPermissions bypasses all permission checks.
 // This is DANGEROUS and should only be used in sandboxed environments.
 // Requires AllowDangerouslySkipPermissions to be enabled first.
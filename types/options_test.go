@@ -1,6 +1,7 @@
 package types
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -167,6 +168,37 @@ func TestWithJSONSchemaOutput(t *testing.T) {
 	}
 }
 
+// TestWithStructuredOutput tests the schema generated from a struct and decoding the result into it.
+func TestWithStructuredOutput(t *testing.T) {
+	type answer struct {
+		Value  string `json:"value" desc:"The answer"`
+		Source string `json:"source,omitempty"`
+	}
+
+	opts := NewClaudeAgentOptions().WithStructuredOutput(answer{})
+	if opts.OutputFormat["type"] != "json_schema" {
+		t.Fatalf("expected type to be json_schema, got %v", opts.OutputFormat["type"])
+	}
+	schema, _ := opts.OutputFormat["schema"].(map[string]interface{})
+	props, _ := schema["properties"].(map[string]interface{})
+	if _, ok := props["value"]; !ok {
+		t.Fatalf("expected value property, got %v", schema)
+	}
+
+	result := &ResultMessage{StructuredOutput: map[string]interface{}{"value": "42"}}
+	var got answer
+	if err := result.DecodeStructuredOutput(&got); err != nil {
+		t.Fatalf("DecodeStructuredOutput failed: %v", err)
+	}
+	if got.Value != "42" {
+		t.Errorf("expected value 42, got %q", got.Value)
+	}
+
+	if err := (&ResultMessage{}).DecodeStructuredOutput(&got); err == nil {
+		t.Error("expected error without structured output")
+	}
+}
+
 // TestWithEnableFileCheckpointing toggles the flag.
 func TestWithEnableFileCheckpointing(t *testing.T) {
 	opts := NewClaudeAgentOptions().WithEnableFileCheckpointing(true)
@@ -174,3 +206,38 @@ func TestWithEnableFileCheckpointing(t *testing.T) {
 		t.Fatalf("expected EnableFileCheckpointing to be true")
 	}
 }
+
+// TestStructuredOutputFormat tests that the format generated from a type parameter matches WithStructuredOutput.
+func TestStructuredOutputFormat(t *testing.T) {
+	type answer struct {
+		Value string `json:"value"`
+	}
+
+	format := StructuredOutputFormat[answer]()
+	want := NewClaudeAgentOptions().WithStructuredOutput(answer{}).OutputFormat
+	if !reflect.DeepEqual(format, want) {
+		t.Errorf("StructuredOutputFormat() = %v, want %v", format, want)
+	}
+	if pointer := StructuredOutputFormat[*answer](); !reflect.DeepEqual(pointer, want) {
+		t.Errorf("StructuredOutputFormat[*answer]() = %v, want %v", pointer, want)
+	}
+}
+
+// TestValidate tests that Validate reports invalid options.
+func TestValidate(t *testing.T) {
+	if err := NewClaudeAgentOptions().Validate(); err != nil {
+		t.Errorf("expected default options to be valid, got %v", err)
+	}
+
+	tests := map[string]*ClaudeAgentOptions{
+		"allowed tools":     NewClaudeAgentOptions().WithAllowedTools("Bash(git *"),
+		"disallowed tools":  NewClaudeAgentOptions().WithDisallowedTools("Bash)"),
+		"agent permissions": NewClaudeAgentOptions().WithAgentPermissions("tester", AgentPermissions{DisallowedTools: []string{"Read("}}),
+		"token alerts":      NewClaudeAgentOptions().WithTokenAlert(-1, func(TokenUsage) {}),
+	}
+	for name, opts := range tests {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("expected invalid %s to be reported", name)
+		}
+	}
+}
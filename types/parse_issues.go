@@ -0,0 +1,210 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ParseIssueKind classifies a ParseIssue.
+type ParseIssueKind string
+
+const (
+	ParseIssueUnknownMessageType ParseIssueKind = "unknown_message_type"
+	ParseIssueUnknownBlockType   ParseIssueKind = "unknown_block_type"
+	ParseIssueUnknownField       ParseIssueKind = "unknown_field"
+)
+
+// ParseIssue is data in a CLI message that the SDK does not understand and
+// would drop, usually a sign that the CLI is newer than the SDK.
+type ParseIssue struct {
+	Kind        ParseIssueKind `json:"kind"`
+	MessageType string         `json:"message_type"` // Type of the message holding the data
+	Path        string         `json:"path"`         // Location in the message, such as "message.content[1].citations"
+	Name        string         `json:"name"`         // The unknown type or field
+}
+
+func (i ParseIssue) String() string {
+	switch i.Kind {
+	case ParseIssueUnknownMessageType:
+		return fmt.Sprintf("unknown message type %q", i.Name)
+	case ParseIssueUnknownBlockType:
+		return fmt.Sprintf("unknown content block type %q at %s", i.Name, i.Path)
+	default:
+		return fmt.Sprintf("unknown field %q at %s", i.Name, i.Path)
+	}
+}
+
+// ParseIssueFunc receives the parse issues found by WithStrictParsing or
+// WithParseIssueHandler.
+type ParseIssueFunc func(issue ParseIssue)
+
+// ParseIssues lists every issue found in a message. It is the cause of the
+// MessageParseError returned by UnmarshalMessageStrict.
+type ParseIssues []ParseIssue
+
+func (e ParseIssues) Error() string {
+	messages := make([]string, len(e))
+	for i, issue := range e {
+		messages[i] = issue.String()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Fields of messages that UnmarshalMessage reads or knowingly ignores, by
+// message type. System and control messages keep all their data and are not
+// checked.
+var knownMessageFields = map[string][]string{
+	"user":            {"type", "message", "content", "parent_tool_use_id", "uuid", "session_id", "tool_use_result"},
+	"assistant":       {"type", "message", "content", "model", "parent_tool_use_id", "uuid", "session_id", "error"},
+	"result":          append(jsonFields(ResultMessage{}), "uuid", "permission_denials", "errors"),
+	"stream_event":    jsonFields(StreamEvent{}),
+	"assistant_error": jsonFields(AssistantErrorEvent{}), // Added by the SDK, as when reading a transcript
+}
+
+// Fields of the API message nested in user and assistant messages.
+var knownAPIMessageFields = map[string][]string{
+	"user":      {"role", "content", "parent_tool_use_id", "uuid"},
+	"assistant": {"id", "type", "role", "model", "content", "stop_reason", "stop_sequence", "usage", "error", "container", "context_management"},
+}
+
+// knownBlockFields returns the fields UnmarshalContentBlock reads for a
+// content block type, or false for an unknown type.
+func knownBlockFields(blockType string) ([]string, bool) {
+	switch blockType {
+	case "text":
+		return jsonFields(TextBlock{}), true
+	case "thinking":
+		return jsonFields(ThinkingBlock{}), true
+	case "redacted_thinking":
+		return jsonFields(RedactedThinkingBlock{}), true
+	case "tool_use":
+		return jsonFields(ToolUseBlock{}), true
+	case "tool_result":
+		return jsonFields(ToolResultBlock{}), true
+	case "image":
+		return jsonFields(ImageBlock{}), true
+	case "document":
+		return jsonFields(DocumentBlock{}), true
+	case "server_tool_use", "mcp_tool_use":
+		return jsonFields(ServerToolUseBlock{}), true
+	}
+	if isServerToolResult(blockType) {
+		return jsonFields(ServerToolResultBlock{}), true
+	}
+	return nil, false
+}
+
+// jsonFields returns the JSON field names of a struct.
+func jsonFields(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	fields := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// FindParseIssues returns the data in a CLI message that UnmarshalMessage
+// does not understand: an unknown message type, unknown content block types,
+// and fields of messages and content blocks that the SDK does not know.
+// Messages and content blocks of registered types are not checked. It
+// returns nil for a message without issues and for invalid JSON, which
+// UnmarshalMessage reports itself.
+func FindParseIssues(data []byte) ParseIssues {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+	var msgType string
+	_ = json.Unmarshal(raw["type"], &msgType)
+
+	var issues ParseIssues
+	switch msgType {
+	case "system", "control_request", "control_response":
+		return nil
+	case "user", "assistant":
+		issues = checkFields(issues, msgType, "", raw, knownMessageFields[msgType])
+		content, contentPath := raw["content"], "content"
+		var nested map[string]json.RawMessage
+		if json.Unmarshal(raw["message"], &nested) == nil && nested != nil {
+			issues = checkFields(issues, msgType, "message.", nested, knownAPIMessageFields[msgType])
+			if c, ok := nested["content"]; ok {
+				content, contentPath = c, "message.content"
+			}
+		}
+		issues = checkBlocks(issues, msgType, contentPath, content)
+	case "result", "stream_event", "assistant_error":
+		issues = checkFields(issues, msgType, "", raw, knownMessageFields[msgType])
+	default:
+		if _, ok := registeredMessageType(msgType); ok {
+			return nil
+		}
+		issues = append(issues, ParseIssue{Kind: ParseIssueUnknownMessageType, MessageType: msgType, Path: "type", Name: msgType})
+	}
+	return issues
+}
+
+// checkFields appends an issue for each field of object not in known.
+func checkFields(issues ParseIssues, msgType, prefix string, object map[string]json.RawMessage, known []string) ParseIssues {
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !containsString(known, name) {
+			issues = append(issues, ParseIssue{Kind: ParseIssueUnknownField, MessageType: msgType, Path: prefix + name, Name: name})
+		}
+	}
+	return issues
+}
+
+// checkBlocks appends the issues of the content blocks in content, which may
+// also be a string.
+func checkBlocks(issues ParseIssues, msgType, path string, content json.RawMessage) ParseIssues {
+	var blocks []map[string]json.RawMessage
+	if json.Unmarshal(content, &blocks) != nil {
+		return issues
+	}
+	for i, block := range blocks {
+		blockPath := fmt.Sprintf("%s[%d]", path, i)
+		var blockType string
+		_ = json.Unmarshal(block["type"], &blockType)
+		if _, ok := registeredBlockType(blockType); ok {
+			continue
+		}
+		known, ok := knownBlockFields(blockType)
+		if !ok {
+			issues = append(issues, ParseIssue{Kind: ParseIssueUnknownBlockType, MessageType: msgType, Path: blockPath, Name: blockType})
+			continue
+		}
+		issues = checkFields(issues, msgType, blockPath+".", block, known)
+	}
+	return issues
+}
+
+// containsString reports whether values contains s.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalMessageStrict is like UnmarshalMessage but also fails for a
+// message with data the SDK would drop. The error is then a
+// MessageParseError wrapping the ParseIssues found.
+func UnmarshalMessageStrict(data []byte) (Message, error) {
+	if issues := FindParseIssues(data); len(issues) > 0 {
+		return nil, NewMessageParseErrorWithCause("unexpected data in message", issues[0].MessageType, issues)
+	}
+	return UnmarshalMessage(data)
+}
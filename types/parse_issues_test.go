@@ -0,0 +1,78 @@
+package types
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestFindParseIssues tests detection of data the SDK does not understand
+func TestFindParseIssues(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want ParseIssues
+	}{
+		{
+			name: "known CLI assistant message",
+			data: `{"type":"assistant","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"hi"},{"type":"tool_use","id":"t1","name":"Bash","input":{}}],"stop_reason":null,"stop_sequence":null,"usage":{}},"parent_tool_use_id":null,"session_id":"s","uuid":"u"}`,
+		},
+		{
+			name: "known result message",
+			data: `{"type":"result","subtype":"success","is_error":false,"duration_ms":1,"duration_api_ms":1,"num_turns":1,"result":"ok","session_id":"s","total_cost_usd":0.1,"usage":{},"modelUsage":{},"permission_denials":[],"uuid":"u"}`,
+		},
+		{
+			name: "system messages are not checked",
+			data: `{"type":"system","subtype":"init","anything":1}`,
+		},
+		{
+			name: "unknown message type",
+			data: `{"type":"progress"}`,
+			want: ParseIssues{{Kind: ParseIssueUnknownMessageType, MessageType: "progress", Path: "type", Name: "progress"}},
+		},
+		{
+			name: "unknown fields and block type",
+			data: `{"type":"assistant","priority":1,"message":{"role":"assistant","content":[{"type":"text","text":"hi","citations":[]},{"type":"hologram"}],"mood":"happy"}}`,
+			want: ParseIssues{
+				{Kind: ParseIssueUnknownField, MessageType: "assistant", Path: "priority", Name: "priority"},
+				{Kind: ParseIssueUnknownField, MessageType: "assistant", Path: "message.mood", Name: "mood"},
+				{Kind: ParseIssueUnknownField, MessageType: "assistant", Path: "message.content[0].citations", Name: "citations"},
+				{Kind: ParseIssueUnknownBlockType, MessageType: "assistant", Path: "message.content[1]", Name: "hologram"},
+			},
+		},
+		{
+			name: "flat user message",
+			data: `{"type":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok","cache_hit":true}]}`,
+			want: ParseIssues{{Kind: ParseIssueUnknownField, MessageType: "user", Path: "content[0].cache_hit", Name: "cache_hit"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindParseIssues([]byte(tt.data))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindParseIssues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestUnmarshalMessageStrict tests that strict unmarshaling fails on parse issues
+func TestUnmarshalMessageStrict(t *testing.T) {
+	msg, err := UnmarshalMessageStrict([]byte(`{"type":"user","message":{"role":"user","content":"hello"},"session_id":"s"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalMessageStrict() error = %v", err)
+	}
+	if user, ok := msg.(*UserMessage); !ok || user.Content != "hello" {
+		t.Errorf("UnmarshalMessageStrict() = %#v, want user message with content hello", msg)
+	}
+
+	_, err = UnmarshalMessageStrict([]byte(`{"type":"stream_event","event":{},"sequence":3}`))
+	if !IsMessageParseError(err) {
+		t.Fatalf("UnmarshalMessageStrict() error = %v, want MessageParseError", err)
+	}
+	var issues ParseIssues
+	if !errors.As(err, &issues) || len(issues) != 1 || issues[0].Name != "sequence" {
+		t.Errorf("UnmarshalMessageStrict() issues = %v, want unknown field sequence", issues)
+	}
+}
@@ -0,0 +1,120 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// PermissionDecisionSource identifies what made a permission decision.
+type PermissionDecisionSource string
+
+const (
+	// PermissionSourceCallback is a decision returned by the CanUseTool callback.
+	PermissionSourceCallback PermissionDecisionSource = "callback"
+	// PermissionSourceHook is a permissionDecision returned by a PreToolUse hook.
+	PermissionSourceHook PermissionDecisionSource = "hook"
+	// PermissionSourceMode is a decision implied by the permission mode.
+	PermissionSourceMode PermissionDecisionSource = "mode"
+	// PermissionSourceRule is a decision made by an SDK-side permission rule.
+	PermissionSourceRule PermissionDecisionSource = "rule"
+	// PermissionSourceMemory is a decision the user made earlier for the same tool use.
+	PermissionSourceMemory PermissionDecisionSource = "memory"
+)
+
+// PermissionAuditRecord describes a single permission request and its outcome.
+type PermissionAuditRecord struct {
+	Time      time.Time                `json:"time"`
+	ToolName  string                   `json:"tool_name"`
+	ToolUseID string                   `json:"tool_use_id,omitempty"`
+	InputHash string                   `json:"input_hash"` // See HashToolInput
+	Decision  string                   `json:"decision"`   // "allow", "deny", "ask" or "error"
+	Source    PermissionDecisionSource `json:"source"`
+	Message   string                   `json:"message,omitempty"` // Deny message or decision reason
+	Interrupt bool                     `json:"interrupt,omitempty"`
+
+	// InputUpdated is true when the decision replaced the tool input.
+	InputUpdated       bool               `json:"input_updated,omitempty"`
+	UpdatedPermissions []PermissionUpdate `json:"updated_permissions,omitempty"`
+	Duration           time.Duration      `json:"duration_ns"`
+	Error              string             `json:"error,omitempty"`
+}
+
+// PermissionAuditSink receives permission audit records.
+//
+// RecordPermission is called synchronously while the CLI waits for the
+// decision, so implementations should be fast. Errors are logged and do not
+// affect the decision. Implement this interface to store records elsewhere,
+// for example in a database.
+type PermissionAuditSink interface {
+	RecordPermission(record PermissionAuditRecord) error
+}
+
+// PermissionAuditFunc adapts a function to a PermissionAuditSink.
+type PermissionAuditFunc func(record PermissionAuditRecord) error
+
+// RecordPermission calls f(record).
+func (f PermissionAuditFunc) RecordPermission(record PermissionAuditRecord) error {
+	return f(record)
+}
+
+// JSONLPermissionAuditSink writes each audit record as one JSON line.
+// It is safe for concurrent use.
+type JSONLPermissionAuditSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewJSONLPermissionAuditSink creates a sink that writes JSON lines to w.
+func NewJSONLPermissionAuditSink(w io.Writer) *JSONLPermissionAuditSink {
+	return &JSONLPermissionAuditSink{w: w}
+}
+
+// OpenJSONLPermissionAuditFile creates a sink that appends JSON lines to the
+// file at path, creating it if needed. Close the sink when done.
+func OpenJSONLPermissionAuditFile(path string) (*JSONLPermissionAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLPermissionAuditSink{w: file, closer: file}, nil
+}
+
+// RecordPermission writes the record as a single line.
+func (s *JSONLPermissionAuditSink) RecordPermission(record PermissionAuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(data)
+	return err
+}
+
+// Close closes the underlying file if the sink opened it.
+func (s *JSONLPermissionAuditSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// HashToolInput returns the hex-encoded SHA-256 of the tool input's JSON
+// encoding. Map keys are sorted, so equal inputs hash equally. Recording the
+// hash lets audits correlate requests without storing inputs that may hold secrets.
+func HashToolInput(input map[string]interface{}) string {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestJSONLPermissionAuditSink tests that audit records are appended as JSON lines.
+func TestJSONLPermissionAuditSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for i := 0; i < 2; i++ {
+		sink, err := OpenJSONLPermissionAuditFile(path)
+		if err != nil {
+			t.Fatalf("OpenJSONLPermissionAuditFile failed: %v", err)
+		}
+		if err := sink.RecordPermission(PermissionAuditRecord{ToolName: "Bash", Decision: "deny", Source: PermissionSourceCallback}); err != nil {
+			t.Fatalf("RecordPermission failed: %v", err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open audit file: %v", err)
+	}
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		if record["tool_name"] != "Bash" || record["decision"] != "deny" || record["source"] != "callback" {
+			t.Errorf("unexpected record: %v", record)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("expected 2 lines, got %d", lines)
+	}
+}
+
+// TestHashToolInput tests that input hashes are stable and distinguish inputs.
+func TestHashToolInput(t *testing.T) {
+	a := HashToolInput(map[string]interface{}{"command": "ls", "timeout": 5})
+	b := HashToolInput(map[string]interface{}{"timeout": 5, "command": "ls"})
+	c := HashToolInput(map[string]interface{}{"command": "ls -la", "timeout": 5})
+
+	if a != b {
+		t.Error("expected equal inputs to hash equally")
+	}
+	if a == c {
+		t.Error("expected different inputs to hash differently")
+	}
+	if len(a) != 64 {
+		t.Errorf("expected hex SHA-256, got %q", a)
+	}
+}
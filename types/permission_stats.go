@@ -0,0 +1,129 @@
+package types
+
+import "sync"
+
+// PermissionUsageKey is the ResultMessage usage key that holds permission
+// counts when WithPermissionStatsInUsage is enabled.
+const PermissionUsageKey = "permissions"
+
+// PermissionCounts counts permission decisions by outcome.
+type PermissionCounts struct {
+	Allowed int `json:"allowed"`
+	Denied  int `json:"denied"`
+	Asked   int `json:"asked"`  // PreToolUse hooks that deferred to the user
+	Errors  int `json:"errors"` // Permission callbacks that failed
+}
+
+// Total returns the number of decisions counted.
+func (c PermissionCounts) Total() int {
+	return c.Allowed + c.Denied + c.Asked + c.Errors
+}
+
+// add counts one decision.
+func (c *PermissionCounts) add(decision string) {
+	switch decision {
+	case "allow":
+		c.Allowed++
+	case "deny":
+		c.Denied++
+	case "ask":
+		c.Asked++
+	default:
+		c.Errors++
+	}
+}
+
+// PermissionStats summarizes the permission decisions of a session, made by
+// the CanUseTool callback and by PreToolUse hooks.
+type PermissionStats struct {
+	PermissionCounts
+	ByTool   map[string]PermissionCounts                   `json:"by_tool"`
+	BySource map[PermissionDecisionSource]PermissionCounts `json:"by_source"`
+}
+
+// UsageMap returns the stats in the form added to the ResultMessage usage map.
+func (s PermissionStats) UsageMap() map[string]interface{} {
+	byTool := make(map[string]interface{}, len(s.ByTool))
+	for tool, counts := range s.ByTool {
+		byTool[tool] = counts.usageMap()
+	}
+	bySource := make(map[string]interface{}, len(s.BySource))
+	for source, counts := range s.BySource {
+		bySource[string(source)] = counts.usageMap()
+	}
+	usage := s.PermissionCounts.usageMap()
+	usage["by_tool"] = byTool
+	usage["by_source"] = bySource
+	return usage
+}
+
+// usageMap returns the counts as a map.
+func (c PermissionCounts) usageMap() map[string]interface{} {
+	return map[string]interface{}{
+		"allowed": c.Allowed,
+		"denied":  c.Denied,
+		"asked":   c.Asked,
+		"errors":  c.Errors,
+	}
+}
+
+// PermissionStatsRecorder counts permission decisions. It implements
+// PermissionAuditSink, so it can also be used with WithPermissionAudit.
+//
+// It is safe for concurrent use.
+type PermissionStatsRecorder struct {
+	mu    sync.Mutex
+	stats PermissionStats
+}
+
+// NewPermissionStatsRecorder creates a recorder with no decisions.
+func NewPermissionStatsRecorder() *PermissionStatsRecorder {
+	r := &PermissionStatsRecorder{}
+	r.Reset()
+	return r
+}
+
+// RecordPermission counts a permission decision.
+func (r *PermissionStatsRecorder) RecordPermission(record PermissionAuditRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stats.PermissionCounts.add(record.Decision)
+
+	tool := r.stats.ByTool[record.ToolName]
+	tool.add(record.Decision)
+	r.stats.ByTool[record.ToolName] = tool
+
+	source := r.stats.BySource[record.Source]
+	source.add(record.Decision)
+	r.stats.BySource[record.Source] = source
+	return nil
+}
+
+// Stats returns a copy of the counts so far.
+func (r *PermissionStatsRecorder) Stats() PermissionStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := PermissionStats{
+		PermissionCounts: r.stats.PermissionCounts,
+		ByTool:           make(map[string]PermissionCounts, len(r.stats.ByTool)),
+		BySource:         make(map[PermissionDecisionSource]PermissionCounts, len(r.stats.BySource)),
+	}
+	for tool, counts := range r.stats.ByTool {
+		stats.ByTool[tool] = counts
+	}
+	for source, counts := range r.stats.BySource {
+		stats.BySource[source] = counts
+	}
+	return stats
+}
+
+// Reset clears the counts.
+func (r *PermissionStatsRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats = PermissionStats{
+		ByTool:   make(map[string]PermissionCounts),
+		BySource: make(map[PermissionDecisionSource]PermissionCounts),
+	}
+}
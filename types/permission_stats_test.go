@@ -0,0 +1,46 @@
+package types
+
+import "testing"
+
+// TestPermissionStatsRecorder tests counting decisions by tool and source.
+func TestPermissionStatsRecorder(t *testing.T) {
+	recorder := NewPermissionStatsRecorder()
+	records := []PermissionAuditRecord{
+		{ToolName: "Bash", Decision: "allow", Source: PermissionSourceCallback},
+		{ToolName: "Bash", Decision: "deny", Source: PermissionSourceHook},
+		{ToolName: "Write", Decision: "ask", Source: PermissionSourceHook},
+		{ToolName: "Write", Decision: "error", Source: PermissionSourceCallback},
+	}
+	for _, record := range records {
+		if err := recorder.RecordPermission(record); err != nil {
+			t.Fatalf("RecordPermission failed: %v", err)
+		}
+	}
+
+	stats := recorder.Stats()
+	if stats.Allowed != 1 || stats.Denied != 1 || stats.Asked != 1 || stats.Errors != 1 || stats.Total() != 4 {
+		t.Errorf("unexpected totals: %+v", stats.PermissionCounts)
+	}
+	if bash := stats.ByTool["Bash"]; bash.Allowed != 1 || bash.Denied != 1 {
+		t.Errorf("unexpected Bash counts: %+v", bash)
+	}
+	if hook := stats.BySource[PermissionSourceHook]; hook.Denied != 1 || hook.Asked != 1 {
+		t.Errorf("unexpected hook counts: %+v", hook)
+	}
+
+	usage := stats.UsageMap()
+	byTool, _ := usage["by_tool"].(map[string]interface{})
+	write, _ := byTool["Write"].(map[string]interface{})
+	if usage["allowed"] != 1 || write["asked"] != 1 {
+		t.Errorf("unexpected usage map: %v", usage)
+	}
+
+	stats.ByTool["Bash"] = PermissionCounts{}
+	if recorder.Stats().ByTool["Bash"].Allowed != 1 {
+		t.Error("expected Stats to return a copy")
+	}
+	recorder.Reset()
+	if recorder.Stats().Total() != 0 {
+		t.Error("expected Reset to clear the counts")
+	}
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"net/url"
+	"strings"
+)
+
+// AddRulesUpdate returns a PermissionUpdate that adds rules with the given
+// behavior to destination.
+func AddRulesUpdate(destination PermissionUpdateDestination, behavior PermissionBehavior, rules ...PermissionRuleValue) PermissionUpdate {
+	return PermissionUpdate{
+		Type:        "addRules",
+		Rules:       rules,
+		Behavior:    &behavior,
+		Destination: &destination,
+	}
+}
+
+// SetModeUpdate returns a PermissionUpdate that switches the permission mode
+// in destination.
+func SetModeUpdate(destination PermissionUpdateDestination, mode PermissionMode) PermissionUpdate {
+	return PermissionUpdate{
+		Type:        "setMode",
+		Mode:        &mode,
+		Destination: &destination,
+	}
+}
+
+// AddDirectoriesUpdate returns a PermissionUpdate that adds directories the tools may access.
+func AddDirectoriesUpdate(destination PermissionUpdateDestination, dirs ...string) PermissionUpdate {
+	return PermissionUpdate{
+		Type:        "addDirectories",
+		Directories: dirs,
+		Destination: &destination,
+	}
+}
+
+// PermissionRuleFor returns the narrowest rule covering a tool use:
+//
+//   - Bash: the exact command, as in Bash(npm test)
+//   - Read, Write, Edit, MultiEdit, NotebookEdit: the file path
+//   - WebFetch: the URL's domain, as in WebFetch(domain:example.com)
+//
+// Other tools get a rule without content, which covers every use of the tool.
+func PermissionRuleFor(toolName string, input map[string]interface{}) PermissionRuleValue {
+	rule := PermissionRuleValue{ToolName: toolName}
+
+	var content string
+	switch toolName {
+	case "Bash":
+		command, _ := input["command"].(string)
+		content = strings.TrimSpace(command)
+	case "Read", "Write", "Edit", "MultiEdit", "NotebookEdit":
+		for _, key := range []string{"file_path", "notebook_path"} {
+			if path, ok := input[key].(string); ok && path != "" {
+				content = path
+				break
+			}
+		}
+	case "WebFetch":
+		if raw, ok := input["url"].(string); ok {
+			if parsed, err := url.Parse(raw); err == nil && parsed.Hostname() != "" {
+				content = "domain:" + parsed.Hostname()
+			}
+		}
+	}
+
+	if content != "" {
+		rule.RuleContent = &content
+	}
+	return rule
+}
+
+// String returns the rule in settings syntax, such as "Bash(npm test)" or "Read".
+func (r PermissionRuleValue) String() string {
+	if r.RuleContent == nil {
+		return r.ToolName
+	}
+	return r.ToolName + "(" + *r.RuleContent + ")"
+}
+
+// AllowAndRemember allows the tool use and asks the CLI to save the approval
+// to destination, so similar tool uses are allowed without asking again.
+//
+// The CLI's permission suggestions are used when it sent any, with their
+// destination replaced. Otherwise the result adds an allow rule from
+// PermissionRuleFor, plus the blocked directory when the request was
+// triggered by a path outside the allowed directories.
+//
+// Example:
+//
+//	opts.WithPermissionHandler(func(ctx context.Context, req types.ToolPermissionRequest) (types.PermissionResult, error) {
+//	    if req.ToolName == "Bash" && strings.HasPrefix(req.Input["command"].(string), "go test") {
+//	        return req.AllowAndRemember(types.DestinationProjectSettings), nil
+//	    }
+//	    return types.PermissionResultDeny{Behavior: "deny"}, nil
+//	})
+func (r ToolPermissionRequest) AllowAndRemember(destination PermissionUpdateDestination) PermissionResultAllow {
+	return PermissionResultAllow{
+		Behavior:           "allow",
+		UpdatedPermissions: r.rememberUpdates(destination),
+	}
+}
+
+// rememberUpdates returns the PermissionUpdates that persist an approval of the request.
+func (r ToolPermissionRequest) rememberUpdates(destination PermissionUpdateDestination) []PermissionUpdate {
+	if len(r.Suggestions) > 0 {
+		updates := make([]PermissionUpdate, len(r.Suggestions))
+		for i, suggestion := range r.Suggestions {
+			suggestion.Destination = &destination
+			updates[i] = suggestion
+		}
+		return updates
+	}
+
+	updates := []PermissionUpdate{
+		AddRulesUpdate(destination, PermissionBehaviorAllow, PermissionRuleFor(r.ToolName, r.Input)),
+	}
+	if r.BlockedPath != nil {
+		updates = append(updates, AddDirectoriesUpdate(destination, blockedDirectory(*r.BlockedPath)))
+	}
+	return updates
+}
+
+// blockedDirectory returns the directory to allow for a blocked path.
+func blockedDirectory(path string) string {
+	if i := strings.LastIndexAny(path, `/\`); i > 0 {
+		return path[:i]
+	}
+	return path
+}
@@ -0,0 +1,64 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestPermissionRuleFor tests the rules derived from tool uses.
+func TestPermissionRuleFor(t *testing.T) {
+	tests := []struct {
+		toolName string
+		input    map[string]interface{}
+		want     string
+	}{
+		{"Bash", map[string]interface{}{"command": "  npm test "}, "Bash(npm test)"},
+		{"Edit", map[string]interface{}{"file_path": "/repo/main.go"}, "Edit(/repo/main.go)"},
+		{"NotebookEdit", map[string]interface{}{"notebook_path": "/repo/a.ipynb"}, "NotebookEdit(/repo/a.ipynb)"},
+		{"WebFetch", map[string]interface{}{"url": "https://docs.example.com/a?b=c"}, "WebFetch(domain:docs.example.com)"},
+		{"WebFetch", map[string]interface{}{"url": "not a url"}, "WebFetch"},
+		{"mcp__db__query", map[string]interface{}{"sql": "select 1"}, "mcp__db__query"},
+	}
+
+	for _, tt := range tests {
+		if got := PermissionRuleFor(tt.toolName, tt.input).String(); got != tt.want {
+			t.Errorf("PermissionRuleFor(%s, %v) = %q, want %q", tt.toolName, tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestAllowAndRemember tests the updates attached to a remembered approval.
+func TestAllowAndRemember(t *testing.T) {
+	request := ToolPermissionRequest{ToolName: "Bash", Input: map[string]interface{}{"command": "go test ./..."}}
+	result := request.AllowAndRemember(DestinationProjectSettings)
+
+	if result.Behavior != "allow" {
+		t.Errorf("expected allow, got %q", result.Behavior)
+	}
+	data, err := json.Marshal(result.UpdatedPermissions)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `[{"type":"addRules","rules":[{"toolName":"Bash","ruleContent":"go test ./..."}],"behavior":"allow","destination":"projectSettings"}]`
+	if string(data) != want {
+		t.Errorf("unexpected updates:\n got %s\nwant %s", data, want)
+	}
+
+	blocked := "/outside/notes.txt"
+	request = ToolPermissionRequest{ToolName: "Read", Input: map[string]interface{}{"file_path": blocked}, BlockedPath: &blocked}
+	updates := request.AllowAndRemember(DestinationSession).UpdatedPermissions
+	if len(updates) != 2 || updates[1].Type != "addDirectories" || updates[1].Directories[0] != "/outside" {
+		t.Errorf("expected the blocked directory to be added, got %+v", updates)
+	}
+
+	local := DestinationLocalSettings
+	suggestion := SetModeUpdate(local, PermissionModeAcceptEdits)
+	request = ToolPermissionRequest{ToolName: "Edit", Suggestions: []PermissionUpdate{suggestion}}
+	updates = request.AllowAndRemember(DestinationUserSettings).UpdatedPermissions
+	if len(updates) != 1 || updates[0].Type != "setMode" || *updates[0].Destination != DestinationUserSettings {
+		t.Errorf("expected the suggestion with a new destination, got %+v", updates)
+	}
+	if *request.Suggestions[0].Destination != DestinationLocalSettings {
+		t.Error("expected the request's suggestions not to be modified")
+	}
+}
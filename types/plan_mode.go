@@ -0,0 +1,74 @@
+package types
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExitPlanModeToolName is the tool Claude calls in plan mode to present its plan.
+const ExitPlanModeToolName = "ExitPlanMode"
+
+// ExitPlanModeInput is the input of the ExitPlanMode tool.
+type ExitPlanModeInput struct {
+	Plan string `json:"plan"`
+}
+
+// ParseExitPlanModeInput extracts the plan from an ExitPlanMode tool input.
+func ParseExitPlanModeInput(input map[string]interface{}) (ExitPlanModeInput, bool) {
+	plan, ok := input["plan"].(string)
+	return ExitPlanModeInput{Plan: plan}, ok
+}
+
+// ExitPlanMode returns the plan if the block is an ExitPlanMode tool use.
+func (t ToolUseBlock) ExitPlanMode() (ExitPlanModeInput, bool) {
+	if t.Name != ExitPlanModeToolName {
+		return ExitPlanModeInput{}, false
+	}
+	return ParseExitPlanModeInput(t.Input)
+}
+
+// planExecutionMode returns the mode an approved plan switches to:
+// executionMode, or PermissionModeDefault when it is empty.
+func planExecutionMode(executionMode PermissionMode) PermissionMode {
+	if executionMode == "" {
+		return PermissionModeDefault
+	}
+	return executionMode
+}
+
+// PlanReadyFunc reviews a plan presented by Claude in plan mode and reports
+// whether it is approved for execution.
+type PlanReadyFunc func(ctx context.Context, plan string) (approve bool)
+
+// PlanModeCanUseTool returns a permission callback that sends ExitPlanMode
+// requests to onPlanReady and all other tools to next.
+//
+// An approved plan is allowed with a session PermissionUpdate that switches
+// the CLI to executionMode (PermissionModeDefault when empty), so Claude
+// leaves plan mode and starts executing. A rejected plan is denied and
+// Claude stays in plan mode to revise it. When next is nil, other tools
+// that need permission are denied, so pass a next callback unless
+// executionMode is PermissionModeBypassPermissions.
+func PlanModeCanUseTool(onPlanReady PlanReadyFunc, executionMode PermissionMode, next CanUseToolFunc) CanUseToolFunc {
+	executionMode = planExecutionMode(executionMode)
+	return func(ctx context.Context, toolName string, input map[string]interface{}, permCtx ToolPermissionContext) (interface{}, error) {
+		if toolName != ExitPlanModeToolName {
+			if next == nil {
+				return PermissionResultDeny{Behavior: "deny", Message: fmt.Sprintf("%s requires permission", toolName)}, nil
+			}
+			return next(ctx, toolName, input, permCtx)
+		}
+
+		planInput, _ := ParseExitPlanModeInput(input)
+		if !onPlanReady(ctx, planInput.Plan) {
+			return PermissionResultDeny{
+				Behavior: "deny",
+				Message:  "The user did not approve the plan. Stay in plan mode and revise it.",
+			}, nil
+		}
+		return PermissionResultAllow{
+			Behavior:           "allow",
+			UpdatedPermissions: []PermissionUpdate{SetModeUpdate(DestinationSession, executionMode)},
+		}, nil
+	}
+}
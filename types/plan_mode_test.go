@@ -0,0 +1,83 @@
+package types
+
+import (
+	"context"
+	"testing"
+)
+
+// TestPlanModeCanUseTool tests plan approval, rejection and delegation of other tools.
+func TestPlanModeCanUseTool(t *testing.T) {
+	var reviewed string
+	approve := true
+	onPlanReady := func(ctx context.Context, plan string) bool {
+		reviewed = plan
+		return approve
+	}
+	var delegated string
+	next := func(ctx context.Context, toolName string, input map[string]interface{}, permCtx ToolPermissionContext) (interface{}, error) {
+		delegated = toolName
+		return PermissionResultAllow{Behavior: "allow"}, nil
+	}
+	canUseTool := PlanModeCanUseTool(onPlanReady, PermissionModeAcceptEdits, next)
+	input := map[string]interface{}{"plan": "1. Add tests\n2. Fix bug"}
+
+	result, err := canUseTool(context.Background(), ExitPlanModeToolName, input, ToolPermissionContext{})
+	if err != nil {
+		t.Fatalf("canUseTool failed: %v", err)
+	}
+	allow, ok := result.(PermissionResultAllow)
+	if !ok || len(allow.UpdatedPermissions) != 1 {
+		t.Fatalf("expected allow with a mode update, got %#v", result)
+	}
+	if update := allow.UpdatedPermissions[0]; update.Type != "setMode" || *update.Mode != PermissionModeAcceptEdits || *update.Destination != DestinationSession {
+		t.Errorf("unexpected update: %+v", update)
+	}
+	if reviewed != "1. Add tests\n2. Fix bug" {
+		t.Errorf("unexpected plan: %q", reviewed)
+	}
+
+	approve = false
+	result, _ = canUseTool(context.Background(), ExitPlanModeToolName, input, ToolPermissionContext{})
+	if _, ok := result.(PermissionResultDeny); !ok {
+		t.Errorf("expected rejected plan to be denied, got %#v", result)
+	}
+
+	if _, err := canUseTool(context.Background(), "Bash", map[string]interface{}{}, ToolPermissionContext{}); err != nil || delegated != "Bash" {
+		t.Errorf("expected Bash to be delegated, got %q, %v", delegated, err)
+	}
+
+	result, _ = PlanModeCanUseTool(onPlanReady, "", nil)(context.Background(), "Bash", map[string]interface{}{}, ToolPermissionContext{})
+	if _, ok := result.(PermissionResultDeny); !ok {
+		t.Errorf("expected other tools to be denied without next, got %#v", result)
+	}
+}
+
+// TestToolUseBlockExitPlanMode tests typed access to ExitPlanMode tool uses.
+func TestToolUseBlockExitPlanMode(t *testing.T) {
+	block := ToolUseBlock{Type: "tool_use", Name: ExitPlanModeToolName, Input: map[string]interface{}{"plan": "do it"}}
+	if input, ok := block.ExitPlanMode(); !ok || input.Plan != "do it" {
+		t.Errorf("unexpected ExitPlanMode input: %+v, %v", input, ok)
+	}
+	if _, ok := (ToolUseBlock{Name: "Bash"}).ExitPlanMode(); ok {
+		t.Error("expected Bash tool use not to be ExitPlanMode")
+	}
+
+	opts := NewClaudeAgentOptions().WithPlanMode(func(ctx context.Context, plan string) bool { return true }, PermissionModeAcceptEdits)
+	if opts.PermissionMode == nil || *opts.PermissionMode != PermissionModePlan || opts.PlanExecution != PermissionModeAcceptEdits {
+		t.Errorf("unexpected plan mode options: %+v", opts)
+	}
+
+	if err := opts.Validate(); err == nil {
+		t.Error("expected plan mode executing in acceptEdits without CanUseTool to be invalid")
+	}
+	opts.WithCanUseTool(func(ctx context.Context, toolName string, input map[string]interface{}, permCtx ToolPermissionContext) (interface{}, error) {
+		return PermissionResultAllow{Behavior: "allow"}, nil
+	})
+	if err := opts.Validate(); err != nil {
+		t.Errorf("expected plan mode with CanUseTool to be valid, got %v", err)
+	}
+	bypass := NewClaudeAgentOptions().WithPlanMode(func(ctx context.Context, plan string) bool { return true }, PermissionModeBypassPermissions)
+	if err := bypass.Validate(); err != nil {
+		t.Errorf("expected plan mode executing in bypassPermissions to need no CanUseTool, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Merge returns the options of o overlaid by other, leaving both unchanged.
+// A field set in other overrides the same field of o:
+//
+//   - Pointers, functions and interfaces override when not nil. Two
+//     McpServers configuration maps are merged like maps.
+//   - Strings and numbers override when not zero, and booleans when true,
+//     so an overlay cannot switch a boolean off.
+//   - Slices override when not empty.
+//   - Maps are merged key by key, with the entries of other winning; Hooks
+//     are merged by event, so an overlay replaces the matchers of the
+//     events it sets.
+//
+// Merge with a nil other returns a copy of o.
+//
+// Example:
+//
+//	base := types.NewClaudeAgentOptions().WithModel("claude-sonnet-4-5").WithMaxTurns(20)
+//	prod := base.Merge(types.NewClaudeAgentOptions().WithMaxBudgetUSD(5))
+func (o *ClaudeAgentOptions) Merge(other *ClaudeAgentOptions) *ClaudeAgentOptions {
+	merged := &ClaudeAgentOptions{}
+	if o != nil {
+		mergeFields(reflect.ValueOf(merged).Elem(), reflect.ValueOf(o).Elem())
+	}
+	if other != nil {
+		mergeFields(reflect.ValueOf(merged).Elem(), reflect.ValueOf(other).Elem())
+	}
+	return merged
+}
+
+// mergeFields overlays the set fields of src onto the struct dst.
+func mergeFields(dst, src reflect.Value) {
+	for i := 0; i < src.NumField(); i++ {
+		from, to := src.Field(i), dst.Field(i)
+		switch from.Kind() {
+		case reflect.Map:
+			if from.IsNil() {
+				continue
+			}
+			// Always a new map, so the options merged from keep theirs
+			merged := reflect.MakeMapWithSize(from.Type(), to.Len()+from.Len())
+			copyMapEntries(merged, to)
+			copyMapEntries(merged, from)
+			to.Set(merged)
+		case reflect.Slice:
+			if from.Len() > 0 || to.IsNil() {
+				to.Set(from)
+			}
+		case reflect.Interface:
+			if from.IsNil() {
+				continue
+			}
+			fromServers, ok1 := from.Interface().(map[string]interface{})
+			toServers, ok2 := to.Interface().(map[string]interface{})
+			if ok1 && ok2 {
+				servers := make(map[string]interface{}, len(toServers)+len(fromServers))
+				for name, server := range toServers {
+					servers[name] = server
+				}
+				for name, server := range fromServers {
+					servers[name] = server
+				}
+				to.Set(reflect.ValueOf(servers))
+				continue
+			}
+			to.Set(from)
+		default:
+			if !from.IsZero() {
+				to.Set(from)
+			}
+		}
+	}
+}
+
+// copyMapEntries sets the entries of the map src in the map dst.
+func copyMapEntries(dst, src reflect.Value) {
+	iter := src.MapRange()
+	for iter.Next() {
+		dst.SetMapIndex(iter.Key(), iter.Value())
+	}
+}
+
+// Profiles are named option sets, such as "dev", "staging" and "prod", each
+// a base option set overlaid by the profile's overrides with Merge, so that
+// the same agent code can run in several environments.
+//
+// Example:
+//
+//	profiles := types.NewProfiles(base).
+//	    Add("dev", types.NewClaudeAgentOptions().WithVerbose(true)).
+//	    Add("prod", types.NewClaudeAgentOptions().WithMaxBudgetUSD(5))
+//	opts, err := profiles.Get(os.Getenv("AGENT_PROFILE"))
+type Profiles struct {
+	base      *ClaudeAgentOptions
+	overrides map[string][]*ClaudeAgentOptions
+}
+
+// NewProfiles returns profiles sharing the base options.
+func NewProfiles(base *ClaudeAgentOptions) *Profiles {
+	return &Profiles{base: base, overrides: make(map[string][]*ClaudeAgentOptions)}
+}
+
+// Add defines a profile as the base options overlaid by each of overrides
+// in order, replacing any profile of the same name.
+func (p *Profiles) Add(name string, overrides ...*ClaudeAgentOptions) *Profiles {
+	p.overrides[name] = overrides
+	return p
+}
+
+// Get returns the options of a profile. The empty name selects the base
+// options. The result is a new options value, so it can be changed without
+// affecting other profiles.
+func (p *Profiles) Get(name string) (*ClaudeAgentOptions, error) {
+	opts := p.base.Merge(nil)
+	if name == "" {
+		return opts.Clone(), nil
+	}
+	overrides, ok := p.overrides[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown options profile %q (have %v)", name, p.Names())
+	}
+	for _, override := range overrides {
+		opts = opts.Merge(override)
+	}
+	return opts.Clone(), nil
+}
+
+// Names returns the names of the profiles, sorted.
+func (p *Profiles) Names() []string {
+	names := make([]string, 0, len(p.overrides))
+	for name := range p.overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestMerge tests the overlay rules of ClaudeAgentOptions.Merge
+func TestMerge(t *testing.T) {
+	base := NewClaudeAgentOptions().
+		WithModel("base-model").
+		WithMaxTurns(20).
+		WithAllowedTools("Read", "Grep").
+		WithEnv(map[string]string{"STAGE": "base", "REGION": "eu"}).
+		WithMcpServers(map[string]interface{}{"files": McpStdioServerConfig{Command: "files"}}).
+		WithVerbose(true)
+	overlay := NewClaudeAgentOptions().
+		WithMaxBudgetUSD(5).
+		WithEnv(map[string]string{"STAGE": "prod"}).
+		WithMcpServers(map[string]interface{}{"db": McpStdioServerConfig{Command: "db"}})
+
+	merged := base.Merge(overlay)
+	if *merged.Model != "base-model" || *merged.MaxTurns != 20 || *merged.MaxBudgetUSD != 5 || !merged.Verbose {
+		t.Errorf("Merge() = model %v, turns %v, budget %v, verbose %v", merged.Model, merged.MaxTurns, merged.MaxBudgetUSD, merged.Verbose)
+	}
+	if strings.Join(merged.AllowedTools, ",") != "Read,Grep" {
+		t.Errorf("AllowedTools = %v, want the base tools kept by an empty overlay", merged.AllowedTools)
+	}
+	if merged.Env["STAGE"] != "prod" || merged.Env["REGION"] != "eu" {
+		t.Errorf("Env = %v, want STAGE overridden and REGION kept", merged.Env)
+	}
+	if servers := merged.McpServers.(map[string]interface{}); len(servers) != 2 {
+		t.Errorf("McpServers = %v, want both servers", servers)
+	}
+	if base.Env["STAGE"] != "base" || base.MaxBudgetUSD != nil || len(base.McpServers.(map[string]interface{})) != 1 {
+		t.Error("Merge() changed the base options")
+	}
+}
+
+// TestProfiles tests selecting layered option profiles by name
+func TestProfiles(t *testing.T) {
+	base := NewClaudeAgentOptions().WithModel("base-model").WithMaxTurns(20)
+	profiles := NewProfiles(base).
+		Add("dev", NewClaudeAgentOptions().WithVerbose(true)).
+		Add("prod", NewClaudeAgentOptions().WithMaxTurns(5), NewClaudeAgentOptions().WithModel("prod-model"))
+
+	prod, err := profiles.Get("prod")
+	if err != nil {
+		t.Fatalf("Get(prod) error = %v", err)
+	}
+	if *prod.Model != "prod-model" || *prod.MaxTurns != 5 || prod.Verbose {
+		t.Errorf("Get(prod) = model %s, turns %d, verbose %v", *prod.Model, *prod.MaxTurns, prod.Verbose)
+	}
+	if opts, err := profiles.Get(""); err != nil || opts == base || *opts.MaxTurns != 20 {
+		t.Errorf("Get(\"\") = %v, %v, want a copy of the base", opts, err)
+	}
+	if _, err := profiles.Get("staging"); err == nil || !strings.Contains(err.Error(), "[dev prod]") {
+		t.Errorf("Get(staging) error = %v, want unknown profile naming dev and prod", err)
+	}
+}
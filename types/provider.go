@@ -0,0 +1,82 @@
+package types
+
+import (
+	"fmt"
+	"os"
+)
+
+// CloudProvider is a cloud platform serving Claude models instead of the
+// Anthropic API.
+type CloudProvider string
+
+const (
+	ProviderBedrock CloudProvider = "bedrock" // Amazon Bedrock
+	ProviderVertex  CloudProvider = "vertex"  // Google Cloud Vertex AI
+)
+
+// ProviderConfig selects the cloud provider of the CLI, as set by
+// WithBedrock or WithVertex.
+type ProviderConfig struct {
+	Provider  CloudProvider
+	Region    string // AWS region or Vertex AI region, such as "us-east5"
+	ProjectID string // Google Cloud project; Vertex AI only
+}
+
+// Env returns the environment variables selecting the provider for the CLI.
+func (p ProviderConfig) Env() map[string]string {
+	switch p.Provider {
+	case ProviderBedrock:
+		return map[string]string{
+			"CLAUDE_CODE_USE_BEDROCK": "1",
+			"AWS_REGION":              p.Region,
+		}
+	case ProviderVertex:
+		return map[string]string{
+			"CLAUDE_CODE_USE_VERTEX":      "1",
+			"CLOUD_ML_REGION":             p.Region,
+			"ANTHROPIC_VERTEX_PROJECT_ID": p.ProjectID,
+		}
+	}
+	return nil
+}
+
+// ValidateProvider checks the provider set with WithBedrock or WithVertex,
+// if any: the region, and the project for Vertex AI, must be set, and
+// credentials given in the environment, through Env or the SDK's own
+// environment, must be complete. Credentials that are not in the
+// environment, such as those of an EC2 instance role or gcloud, are left to
+// the CLI.
+func (o *ClaudeAgentOptions) ValidateProvider() error {
+	if o.Provider == nil {
+		return nil
+	}
+	p := o.Provider
+	getenv := func(key string) string {
+		if value, ok := o.Env[key]; ok {
+			return value
+		}
+		return os.Getenv(key)
+	}
+
+	switch p.Provider {
+	case ProviderBedrock:
+		if p.Region == "" {
+			return fmt.Errorf("bedrock: region is required")
+		}
+		if (getenv("AWS_ACCESS_KEY_ID") == "") != (getenv("AWS_SECRET_ACCESS_KEY") == "") {
+			return fmt.Errorf("bedrock: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set together")
+		}
+	case ProviderVertex:
+		if p.Region == "" || p.ProjectID == "" {
+			return fmt.Errorf("vertex: project and region are required")
+		}
+		if path := getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("vertex: GOOGLE_APPLICATION_CREDENTIALS: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown cloud provider %q", p.Provider)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package types
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestProviderEnv tests the environment selecting Bedrock and Vertex AI
+func TestProviderEnv(t *testing.T) {
+	bedrock := NewClaudeAgentOptions().WithBedrock("us-east-1").Provider.Env()
+	if bedrock["CLAUDE_CODE_USE_BEDROCK"] != "1" || bedrock["AWS_REGION"] != "us-east-1" {
+		t.Errorf("Bedrock Env() = %v", bedrock)
+	}
+	vertex := NewClaudeAgentOptions().WithVertex("my-project", "us-east5").Provider.Env()
+	if vertex["CLAUDE_CODE_USE_VERTEX"] != "1" || vertex["CLOUD_ML_REGION"] != "us-east5" || vertex["ANTHROPIC_VERTEX_PROJECT_ID"] != "my-project" {
+		t.Errorf("Vertex Env() = %v", vertex)
+	}
+}
+
+// TestValidateProvider tests the checks of provider settings and credentials
+func TestValidateProvider(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+
+	tests := []struct {
+		name    string
+		opts    *ClaudeAgentOptions
+		wantErr bool
+	}{
+		{"unset", NewClaudeAgentOptions(), false},
+		{"bedrock", NewClaudeAgentOptions().WithBedrock("us-east-1"), false},
+		{"bedrock without region", NewClaudeAgentOptions().WithBedrock(""), true},
+		{"bedrock with keys", NewClaudeAgentOptions().WithBedrock("us-east-1").
+			WithEnvVar("AWS_ACCESS_KEY_ID", "AKIA").WithEnvVar("AWS_SECRET_ACCESS_KEY", "secret"), false},
+		{"bedrock with half the keys", NewClaudeAgentOptions().WithBedrock("us-east-1").WithEnvVar("AWS_ACCESS_KEY_ID", "AKIA"), true},
+		{"vertex", NewClaudeAgentOptions().WithVertex("my-project", "us-east5"), false},
+		{"vertex without project", NewClaudeAgentOptions().WithVertex("", "us-east5"), true},
+		{"vertex with missing credentials file", NewClaudeAgentOptions().WithVertex("my-project", "us-east5").
+			WithEnvVar("GOOGLE_APPLICATION_CREDENTIALS", filepath.Join(t.TempDir(), "missing.json")), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.ValidateProvider(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ParseProxyURL parses a proxy URL as set with WithProxy. The scheme must be
+// http, https or socks5, and the URL must name a host.
+func ParseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: scheme must be http, https or socks5", proxyURL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: no host", proxyURL)
+	}
+	return u, nil
+}
+
+// ValidateProxy checks the proxy URL set with WithProxy, if any.
+func (o *ClaudeAgentOptions) ValidateProxy() error {
+	if o.Proxy == nil {
+		return nil
+	}
+	_, err := ParseProxyURL(*o.Proxy)
+	return err
+}
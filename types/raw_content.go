@@ -0,0 +1,14 @@
+package types
+
+// RawContentBlock is an MCP content block kept in its JSON form, such as an
+// image or embedded resource returned by an external MCP server. It is
+// marshaled unchanged, so tools can pass it through to Claude.
+type RawContentBlock map[string]interface{}
+
+// GetType returns the type of the content block.
+func (b RawContentBlock) GetType() string {
+	t, _ := b["type"].(string)
+	return t
+}
+
+func (b RawContentBlock) isContentBlock() {}
@@ -0,0 +1,392 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedactionLocation names where a value was redacted.
+type RedactionLocation string
+
+const (
+	// RedactionToolInput is a tool input shown to a permission callback.
+	RedactionToolInput RedactionLocation = "tool_input"
+	// RedactionToolResult is an SDK MCP tool result returned to the model.
+	RedactionToolResult RedactionLocation = "tool_result"
+	// RedactionTranscript is a message in an exported transcript.
+	RedactionTranscript RedactionLocation = "transcript"
+)
+
+// SecretDetector finds secrets in text.
+type SecretDetector interface {
+	// Name identifies the detector in replacements and reports.
+	Name() string
+	// Find returns the [start, end) byte offsets of the secrets in s.
+	Find(s string) [][2]int
+}
+
+// detectorFunc adapts a function to SecretDetector.
+type detectorFunc struct {
+	name string
+	find func(s string) [][2]int
+}
+
+func (d detectorFunc) Name() string           { return d.name }
+func (d detectorFunc) Find(s string) [][2]int { return d.find(s) }
+
+// NewDetectorFunc returns a custom SecretDetector that reports the spans found by find.
+func NewDetectorFunc(name string, find func(s string) [][2]int) SecretDetector {
+	return detectorFunc{name: name, find: find}
+}
+
+// NewRegexDetector returns a SecretDetector for the matches of pattern. If
+// the pattern has a capturing group, only the first group is redacted, so
+// "password=(\S+)" keeps the "password=" prefix.
+func NewRegexDetector(name, pattern string) (SecretDetector, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern for detector %s: %w", name, err)
+	}
+	return NewDetectorFunc(name, func(s string) [][2]int {
+		var spans [][2]int
+		for _, match := range re.FindAllStringSubmatchIndex(s, -1) {
+			start, end := match[0], match[1]
+			if len(match) >= 4 && match[2] >= 0 {
+				start, end = match[2], match[3]
+			}
+			spans = append(spans, [2]int{start, end})
+		}
+		return spans
+	}), nil
+}
+
+// MustRegexDetector is like NewRegexDetector but panics on an invalid pattern.
+func MustRegexDetector(name, pattern string) SecretDetector {
+	detector, err := NewRegexDetector(name, pattern)
+	if err != nil {
+		panic(err)
+	}
+	return detector
+}
+
+// tokenPattern matches the candidate tokens checked by the entropy detector.
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+/=_\-]+`)
+
+// NewEntropyDetector returns a SecretDetector for random-looking tokens: runs
+// of at least minLength letters, digits and +/=_- whose Shannon entropy is at
+// least minBits per character. 20 characters and 4.0 bits catch most API
+// keys; raise the thresholds if hashes or IDs are redacted too often.
+func NewEntropyDetector(minLength int, minBits float64) SecretDetector {
+	return NewDetectorFunc("high_entropy", func(s string) [][2]int {
+		var spans [][2]int
+		for _, loc := range tokenPattern.FindAllStringIndex(s, -1) {
+			if loc[1]-loc[0] >= minLength && shannonEntropy(s[loc[0]:loc[1]]) >= minBits {
+				spans = append(spans, [2]int{loc[0], loc[1]})
+			}
+		}
+		return spans
+	})
+}
+
+// shannonEntropy returns the entropy of s in bits per byte.
+func shannonEntropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		if count > 0 {
+			p := float64(count) / float64(len(s))
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// RedactionPlaceholder returns the text that replaces a secret found by the
+// named detector, "[REDACTED:<name>]". All of the SDK's redaction uses this
+// form; see also RedactedCredential.
+func RedactionPlaceholder(name string) string {
+	return "[REDACTED:" + name + "]"
+}
+
+// DefaultSecretDetectors returns detectors for common credential formats:
+// AWS access keys, GitHub, Anthropic, OpenAI and Slack tokens, bearer
+// tokens, JWTs, PEM private keys, and password or secret assignments.
+func DefaultSecretDetectors() []SecretDetector {
+	return []SecretDetector{
+		MustRegexDetector("private_key", `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+		MustRegexDetector("aws_access_key", `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+		MustRegexDetector("github_token", `\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`),
+		MustRegexDetector("anthropic_api_key", `\bsk-ant-[A-Za-z0-9_\-]{20,}`),
+		MustRegexDetector("openai_api_key", `\bsk-(?:proj-)?[A-Za-z0-9]{20,}\b`),
+		MustRegexDetector("slack_token", `\bxox[abposr]-[A-Za-z0-9\-]{10,}`),
+		MustRegexDetector("bearer_token", `(?i)\bbearer\s+([A-Za-z0-9\-._~+/]{20,}=*)`),
+		MustRegexDetector("jwt", `\beyJ[A-Za-z0-9_\-]{8,}\.eyJ[A-Za-z0-9_\-]{8,}\.[A-Za-z0-9_\-]{8,}`),
+		MustRegexDetector("password", `(?i)\b(?:password|passwd|secret|api_?key|token)\s*[=:]\s*["']?([^\s"']{4,})`),
+	}
+}
+
+// RedactionEntry describes a redacted secret. The secret itself is never
+// stored; Fingerprint lets the same secret be recognized across entries.
+// Redactions of the same secret by the same detector at the same location
+// and path share an entry, counted in Count.
+type RedactionEntry struct {
+	Time        time.Time         `json:"time"` // Latest redaction
+	Location    RedactionLocation `json:"location"`
+	Detector    string            `json:"detector"`
+	Path        string            `json:"path"` // JSON path of the value, such as "command" or "content[0].text"
+	Length      int               `json:"length"`
+	Fingerprint string            `json:"fingerprint"` // First 12 hex digits of the secret's SHA-256
+	Count       int               `json:"count"`
+}
+
+// MaxRedactionEntries is the number of entries a Redactor keeps. Further
+// distinct redactions are still counted in the report totals, and in
+// RedactionReport.Dropped.
+const MaxRedactionEntries = 1000
+
+// RedactionReport summarizes what a Redactor redacted.
+type RedactionReport struct {
+	Total      int                       `json:"total"`
+	ByDetector map[string]int            `json:"by_detector"`
+	ByLocation map[RedactionLocation]int `json:"by_location"`
+	Entries    []RedactionEntry          `json:"entries"`
+	Dropped    int                       `json:"dropped,omitempty"` // Redactions without an entry, past MaxRedactionEntries
+}
+
+// redactionKey identifies the entry of a redaction.
+type redactionKey struct {
+	location    RedactionLocation
+	detector    string
+	path        string
+	fingerprint string
+}
+
+// Redactor replaces secrets found by its detectors with their
+// RedactionPlaceholder.
+//
+// With WithRedaction it is applied to tool inputs before permission
+// callbacks see them, and to SDK MCP tool results before they reach the
+// model. Results of built-in tools are produced by the CLI and are not
+// redacted. The CLI still runs tools with their original input, but a
+// callback that returns UpdatedInput built from the redacted input sends the
+// placeholders to the tool. ExportTranscript redacts messages before
+// writing them, and WithWireDump the lines it records.
+//
+// It is safe for concurrent use.
+type Redactor struct {
+	Detectors []SecretDetector
+
+	mu         sync.Mutex
+	entries    []RedactionEntry
+	index      map[redactionKey]int // Position of each entry in entries
+	total      int
+	byDetector map[string]int
+	byLocation map[RedactionLocation]int
+	dropped    int
+}
+
+// NewRedactor creates a Redactor using detectors, or DefaultSecretDetectors when none are given.
+func NewRedactor(detectors ...SecretDetector) *Redactor {
+	if len(detectors) == 0 {
+		detectors = DefaultSecretDetectors()
+	}
+	return &Redactor{Detectors: detectors}
+}
+
+// RedactString returns s with its secrets replaced, recording them under location.
+func (r *Redactor) RedactString(location RedactionLocation, s string) string {
+	return r.redactString(location, "", s)
+}
+
+// RedactValue returns a copy of a JSON-like value (maps, slices and scalars)
+// with the secrets in its strings replaced. Map keys are not redacted.
+func (r *Redactor) RedactValue(location RedactionLocation, value interface{}) interface{} {
+	return r.redactValue(location, "", value)
+}
+
+// RedactToolInput returns a redacted copy of a tool input.
+func (r *Redactor) RedactToolInput(input map[string]interface{}) map[string]interface{} {
+	redacted, _ := r.RedactValue(RedactionToolInput, input).(map[string]interface{})
+	return redacted
+}
+
+// ExportTranscript writes messages to w as JSON lines with their secrets redacted.
+func (r *Redactor) ExportTranscript(w io.Writer, messages []Message) error {
+	encoder := json.NewEncoder(w)
+	for i, msg := range messages {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("encode message %d: %w", i, err)
+		}
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("decode message %d: %w", i, err)
+		}
+		if err := encoder.Encode(r.redactValue(RedactionTranscript, "["+strconv.Itoa(i)+"]", value)); err != nil {
+			return fmt.Errorf("write message %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Report returns what has been redacted so far.
+func (r *Redactor) Report() RedactionReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := RedactionReport{
+		Total:      r.total,
+		ByDetector: make(map[string]int, len(r.byDetector)),
+		ByLocation: make(map[RedactionLocation]int, len(r.byLocation)),
+		Entries:    append([]RedactionEntry(nil), r.entries...),
+		Dropped:    r.dropped,
+	}
+	for detector, n := range r.byDetector {
+		report.ByDetector[detector] = n
+	}
+	for location, n := range r.byLocation {
+		report.ByLocation[location] = n
+	}
+	return report
+}
+
+// record adds a redaction to the report.
+func (r *Redactor) record(entry RedactionEntry) {
+	r.total++
+	if r.byDetector == nil {
+		r.byDetector = make(map[string]int)
+		r.byLocation = make(map[RedactionLocation]int)
+		r.index = make(map[redactionKey]int)
+	}
+	r.byDetector[entry.Detector]++
+	r.byLocation[entry.Location]++
+
+	key := redactionKey{entry.Location, entry.Detector, entry.Path, entry.Fingerprint}
+	if i, ok := r.index[key]; ok {
+		r.entries[i].Time = entry.Time
+		r.entries[i].Count++
+		return
+	}
+	if len(r.entries) >= MaxRedactionEntries {
+		r.dropped++
+		return
+	}
+	entry.Count = 1
+	r.index[key] = len(r.entries)
+	r.entries = append(r.entries, entry)
+}
+
+// redactValue redacts value, whose JSON path is path.
+func (r *Redactor) redactValue(location RedactionLocation, path string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.redactString(location, path, v)
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if isBinaryContent(v, key) {
+				redacted[key] = item
+				continue
+			}
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			redacted[key] = r.redactValue(location, childPath, item)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = r.redactValue(location, path+"["+strconv.Itoa(i)+"]", item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// isBinaryContent reports whether key holds base64 data in an MCP content
+// block: image or audio data, or a resource blob. Random-looking base64 would
+// otherwise be mistaken for secrets and corrupted.
+func isBinaryContent(block map[string]interface{}, key string) bool {
+	switch key {
+	case "blob":
+		return true
+	case "data":
+		return block["type"] == "image" || block["type"] == "audio"
+	}
+	return false
+}
+
+// detectedSpan is a secret found by a detector.
+type detectedSpan struct {
+	start, end int
+	detector   string
+}
+
+// redactString replaces the secrets in s, recording them under location and path.
+func (r *Redactor) redactString(location RedactionLocation, path, s string) string {
+	var spans []detectedSpan
+	for _, detector := range r.Detectors {
+		for _, span := range detector.Find(s) {
+			if span[0] >= 0 && span[0] < span[1] && span[1] <= len(s) {
+				spans = append(spans, detectedSpan{span[0], span[1], detector.Name()})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return s
+	}
+
+	// Earlier detectors win ties; overlapping spans are merged into the first.
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	merged := spans[:1]
+	for _, span := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if span.start < last.end {
+			last.end = max(last.end, span.end)
+			continue
+		}
+		merged = append(merged, span)
+	}
+
+	now := time.Now()
+	var out []byte
+	previous := 0
+	entries := make([]RedactionEntry, 0, len(merged))
+	for _, span := range merged {
+		secret := s[span.start:span.end]
+		sum := sha256.Sum256([]byte(secret))
+		entries = append(entries, RedactionEntry{
+			Time:        now,
+			Location:    location,
+			Detector:    span.detector,
+			Path:        path,
+			Length:      len(secret),
+			Fingerprint: hex.EncodeToString(sum[:6]),
+		})
+		out = append(out, s[previous:span.start]...)
+		out = append(out, RedactionPlaceholder(span.detector)...)
+		previous = span.end
+	}
+	out = append(out, s[previous:]...)
+
+	r.mu.Lock()
+	for _, entry := range entries {
+		r.record(entry)
+	}
+	r.mu.Unlock()
+	return string(out)
+}
@@ -0,0 +1,111 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MessageUnmarshaler decodes the JSON of a message of a registered type.
+type MessageUnmarshaler func(data []byte) (Message, error)
+
+// ContentBlockUnmarshaler decodes the JSON of a content block of a
+// registered type.
+type ContentBlockUnmarshaler func(data []byte) (ContentBlock, error)
+
+// CustomMessage is embedded by message types defined outside this package,
+// so that they implement Message.
+type CustomMessage struct{}
+
+func (CustomMessage) isMessage() {}
+
+// CustomContentBlock is embedded by content block types defined outside
+// this package, so that they implement ContentBlock.
+type CustomContentBlock struct{}
+
+func (CustomContentBlock) isContentBlock() {}
+
+// builtinMessageTypes are the message types UnmarshalMessage decodes itself.
+var builtinMessageTypes = map[string]bool{
+	"user": true, "assistant": true, "system": true, "control_request": true,
+	"control_response": true, "result": true, "stream_event": true, "assistant_error": true,
+}
+
+// builtinBlockTypes are the content block types UnmarshalContentBlock
+// decodes itself, besides the server tool results.
+var builtinBlockTypes = map[string]bool{
+	"text": true, "thinking": true, "redacted_thinking": true, "tool_use": true, "tool_result": true,
+	"image": true, "document": true, "server_tool_use": true, "mcp_tool_use": true,
+}
+
+var (
+	registryMu   sync.RWMutex
+	messageTypes = make(map[string]MessageUnmarshaler)
+	blockTypes   = make(map[string]ContentBlockUnmarshaler)
+)
+
+// RegisterMessageType makes UnmarshalMessage decode messages of a type it
+// does not know with fn, so that experimental CLI messages can be handled
+// without changing the SDK. Registering a type again replaces its
+// unmarshaler. It panics if messageType is empty or built in, or fn is nil.
+//
+// Example:
+//
+//	type Progress struct {
+//	    types.CustomMessage
+//	    Type    string `json:"type"`
+//	    Percent int    `json:"percent"`
+//	}
+//
+//	func (p *Progress) GetMessageType() string    { return p.Type }
+//	func (p *Progress) ShouldDisplayToUser() bool { return true }
+//
+//	types.RegisterMessageType("progress", func(data []byte) (types.Message, error) {
+//	    var p Progress
+//	    err := json.Unmarshal(data, &p)
+//	    return &p, err
+//	})
+func RegisterMessageType(messageType string, fn MessageUnmarshaler) {
+	if messageType == "" || builtinMessageTypes[messageType] {
+		panic(fmt.Sprintf("types: cannot register message type %q", messageType))
+	}
+	if fn == nil {
+		panic("types: RegisterMessageType with nil unmarshaler")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	messageTypes[messageType] = fn
+}
+
+// RegisterContentBlockType makes UnmarshalContentBlock decode content
+// blocks of a type it does not know with fn. Registering a type again
+// replaces its unmarshaler. It panics if blockType is empty or built in, or
+// fn is nil.
+func RegisterContentBlockType(blockType string, fn ContentBlockUnmarshaler) {
+	if blockType == "" || builtinBlockTypes[blockType] {
+		panic(fmt.Sprintf("types: cannot register content block type %q", blockType))
+	}
+	if fn == nil {
+		panic("types: RegisterContentBlockType with nil unmarshaler")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	blockTypes[blockType] = fn
+}
+
+// registeredMessageType returns the unmarshaler registered for a message
+// type.
+func registeredMessageType(messageType string) (MessageUnmarshaler, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	fn, ok := messageTypes[messageType]
+	return fn, ok
+}
+
+// registeredBlockType returns the unmarshaler registered for a content
+// block type.
+func registeredBlockType(blockType string) (ContentBlockUnmarshaler, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	fn, ok := blockTypes[blockType]
+	return fn, ok
+}
@@ -0,0 +1,81 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// testProgress is a custom message type as defined outside this package.
+type testProgress struct {
+	CustomMessage
+	Type    string `json:"type"`
+	Percent int    `json:"percent"`
+}
+
+func (p *testProgress) GetMessageType() string    { return p.Type }
+func (p *testProgress) ShouldDisplayToUser() bool { return false }
+
+// testCitation is a custom content block type as defined outside this package.
+type testCitation struct {
+	CustomContentBlock
+	Type   string `json:"type"`
+	Source string `json:"source"`
+}
+
+func (c *testCitation) GetType() string { return c.Type }
+
+// TestRegisterMessageType tests decoding messages and blocks of registered types
+func TestRegisterMessageType(t *testing.T) {
+	RegisterMessageType("x_test_progress", func(data []byte) (Message, error) {
+		var p testProgress
+		err := json.Unmarshal(data, &p)
+		return &p, err
+	})
+	RegisterContentBlockType("x_test_citation", func(data []byte) (ContentBlock, error) {
+		var c testCitation
+		err := json.Unmarshal(data, &c)
+		return &c, err
+	})
+
+	msg, err := UnmarshalMessage([]byte(`{"type":"x_test_progress","percent":40}`))
+	if err != nil {
+		t.Fatalf("UnmarshalMessage() error = %v", err)
+	}
+	if p, ok := msg.(*testProgress); !ok || p.Percent != 40 {
+		t.Errorf("UnmarshalMessage() = %#v, want progress of 40%%", msg)
+	}
+	if _, err := UnmarshalMessage([]byte(`{"type":"x_test_progress","percent":"forty"}`)); !IsMessageParseError(err) {
+		t.Errorf("UnmarshalMessage() of invalid message error = %v, want MessageParseError", err)
+	}
+
+	data := []byte(`{"type":"assistant","message":{"role":"assistant","model":"m","content":[{"type":"x_test_citation","source":"docs"}]}}`)
+	msg, err = UnmarshalMessage(data)
+	if err != nil {
+		t.Fatalf("UnmarshalMessage() error = %v", err)
+	}
+	if c, ok := msg.(*AssistantMessage).Content[0].(*testCitation); !ok || c.Source != "docs" {
+		t.Errorf("Content[0] = %#v, want registered citation block", msg.(*AssistantMessage).Content[0])
+	}
+	if issues := FindParseIssues(data); len(issues) != 0 {
+		t.Errorf("FindParseIssues() = %v, want none for registered types", issues)
+	}
+}
+
+// TestRegisterBuiltinTypePanics tests that built-in types cannot be replaced
+func TestRegisterBuiltinTypePanics(t *testing.T) {
+	for name, register := range map[string]func(){
+		"message":   func() { RegisterMessageType("assistant", func([]byte) (Message, error) { return nil, nil }) },
+		"block":     func() { RegisterContentBlockType("text", func([]byte) (ContentBlock, error) { return nil, nil }) },
+		"nil func":  func() { RegisterMessageType("x_test_nil", nil) },
+		"empty key": func() { RegisterContentBlockType("", func([]byte) (ContentBlock, error) { return nil, nil }) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
@@ -0,0 +1,115 @@
+package types
+
+import "strings"
+
+// ServerToolUseBlock is a call of a tool the API runs itself, such as
+// web_search or code_execution. Calls of tools on MCP servers the API
+// connects to have type "mcp_tool_use" and set ServerName.
+type ServerToolUseBlock struct {
+	Type       string                 `json:"type"` // "server_tool_use" or "mcp_tool_use"
+	ID         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	ServerName string                 `json:"server_name,omitempty"`
+	Input      map[string]interface{} `json:"input"`
+}
+
+// GetType returns the type of the content block.
+func (b ServerToolUseBlock) GetType() string {
+	return b.Type
+}
+
+func (b ServerToolUseBlock) isContentBlock() {}
+
+// ServerToolResultBlock is the result of a ServerToolUseBlock, with a type
+// naming the tool, such as "web_search_tool_result" or
+// "code_execution_tool_result". Content holds the result as decoded from
+// JSON; the accessors return it in typed form.
+type ServerToolResultBlock struct {
+	Type      string      `json:"type"`
+	ToolUseID string      `json:"tool_use_id"`
+	Content   interface{} `json:"content"`
+	IsError   *bool       `json:"is_error,omitempty"` // Set for mcp_tool_result
+}
+
+// GetType returns the type of the content block.
+func (b ServerToolResultBlock) GetType() string {
+	return b.Type
+}
+
+func (b ServerToolResultBlock) isContentBlock() {}
+
+// isServerToolResult reports whether a content block type is a server tool
+// result type.
+func isServerToolResult(blockType string) bool {
+	return blockType != "tool_result" && strings.HasSuffix(blockType, "_tool_result")
+}
+
+// WebSearchResult is a page found by the web_search tool.
+type WebSearchResult struct {
+	Type             string `json:"type"` // "web_search_result"
+	URL              string `json:"url"`
+	Title            string `json:"title"`
+	EncryptedContent string `json:"encrypted_content,omitempty"`
+	PageAge          string `json:"page_age,omitempty"`
+}
+
+// CodeExecutionResult is the output of the code_execution tool.
+type CodeExecutionResult struct {
+	Type       string                   `json:"type"` // Such as "code_execution_result"
+	Stdout     string                   `json:"stdout"`
+	Stderr     string                   `json:"stderr"`
+	ReturnCode int                      `json:"return_code"`
+	Content    []map[string]interface{} `json:"content,omitempty"` // Files the code created
+}
+
+// WebSearchResults returns the pages of a web_search_tool_result. It
+// returns false for other blocks and for a failed search.
+func (b ServerToolResultBlock) WebSearchResults() ([]WebSearchResult, bool) {
+	if b.Type != "web_search_tool_result" {
+		return nil, false
+	}
+	items, ok := b.Content.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	var results []WebSearchResult
+	if err := remarshal(items, &results); err != nil {
+		return nil, false
+	}
+	return results, true
+}
+
+// CodeExecutionResult returns the output of a code execution result, such
+// as a code_execution_tool_result or bash_code_execution_tool_result. It
+// returns false for other blocks and for a failed execution.
+func (b ServerToolResultBlock) CodeExecutionResult() (*CodeExecutionResult, bool) {
+	if !strings.Contains(b.Type, "code_execution") {
+		return nil, false
+	}
+	content, ok := b.Content.(map[string]interface{})
+	if !ok || !strings.HasSuffix(stringField(content, "type"), "_result") {
+		return nil, false
+	}
+	var result CodeExecutionResult
+	if err := remarshal(content, &result); err != nil {
+		return nil, false
+	}
+	return &result, true
+}
+
+// ErrorCode returns the error code of a failed server tool call, such as
+// "max_uses_exceeded" or "unavailable".
+func (b ServerToolResultBlock) ErrorCode() (string, bool) {
+	content, ok := b.Content.(map[string]interface{})
+	if !ok || !strings.HasSuffix(stringField(content, "type"), "_error") {
+		return "", false
+	}
+	code := stringField(content, "error_code")
+	return code, code != ""
+}
+
+// stringField returns a string field of a decoded JSON object.
+func stringField(object map[string]interface{}, key string) string {
+	s, _ := object[key].(string)
+	return s
+}
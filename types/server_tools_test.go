@@ -0,0 +1,69 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestServerToolBlocks tests parsing server tool use and result blocks.
+func TestServerToolBlocks(t *testing.T) {
+	raw := `{"type":"assistant","message":{"model":"claude-sonnet-4-5","content":[
+		{"type":"server_tool_use","id":"srvtoolu_1","name":"web_search","input":{"query":"go generics"}},
+		{"type":"web_search_tool_result","tool_use_id":"srvtoolu_1","content":[{"type":"web_search_result","url":"https://go.dev/doc/tutorial/generics","title":"Tutorial","encrypted_content":"abc","page_age":"2 days"}]},
+		{"type":"server_tool_use","id":"srvtoolu_2","name":"code_execution","input":{"code":"print(1)"}},
+		{"type":"code_execution_tool_result","tool_use_id":"srvtoolu_2","content":{"type":"code_execution_result","stdout":"1\n","stderr":"","return_code":0,"content":[]}},
+		{"type":"web_search_tool_result","tool_use_id":"srvtoolu_3","content":{"type":"web_search_tool_result_error","error_code":"max_uses_exceeded"}},
+		{"type":"mcp_tool_use","id":"mcptoolu_1","name":"lookup","server_name":"crm","input":{}},
+		{"type":"mcp_tool_result","tool_use_id":"mcptoolu_1","is_error":false,"content":[{"type":"text","text":"found"}]}
+	]}}`
+	msg, err := UnmarshalMessage([]byte(raw))
+	if err != nil {
+		t.Fatalf("UnmarshalMessage failed: %v", err)
+	}
+	content := msg.(*AssistantMessage).Content
+	if len(content) != 7 {
+		t.Fatalf("expected 7 blocks, got %d", len(content))
+	}
+
+	use, ok := content[0].(*ServerToolUseBlock)
+	if !ok || use.Name != "web_search" || use.Input["query"] != "go generics" {
+		t.Errorf("unexpected server tool use: %+v", content[0])
+	}
+
+	search := content[1].(*ServerToolResultBlock)
+	pages, ok := search.WebSearchResults()
+	if !ok || len(pages) != 1 || pages[0].URL != "https://go.dev/doc/tutorial/generics" || pages[0].PageAge != "2 days" {
+		t.Errorf("WebSearchResults() = %+v, %v", pages, ok)
+	}
+	if _, ok := search.CodeExecutionResult(); ok {
+		t.Error("expected no code execution result for a web search")
+	}
+
+	execution, ok := content[3].(*ServerToolResultBlock).CodeExecutionResult()
+	if !ok || execution.Stdout != "1\n" || execution.ReturnCode != 0 {
+		t.Errorf("CodeExecutionResult() = %+v, %v", execution, ok)
+	}
+
+	failed := content[4].(*ServerToolResultBlock)
+	if code, ok := failed.ErrorCode(); !ok || code != "max_uses_exceeded" {
+		t.Errorf("ErrorCode() = %q, %v", code, ok)
+	}
+	if _, ok := failed.WebSearchResults(); ok {
+		t.Error("expected no results for a failed search")
+	}
+
+	if mcp, ok := content[5].(*ServerToolUseBlock); !ok || mcp.ServerName != "crm" {
+		t.Errorf("unexpected mcp tool use: %+v", content[5])
+	}
+	if result, ok := content[6].(*ServerToolResultBlock); !ok || result.IsError == nil || *result.IsError {
+		t.Errorf("unexpected mcp tool result: %+v", content[6])
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if _, err := UnmarshalMessage(data); err != nil {
+		t.Errorf("round trip failed: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ValidateSessionID checks the session ID set with WithSessionID, if any: it
+// must be a UUID, and resuming or continuing a session with it requires
+// forking, since the resumed session keeps its ID.
+func (o *ClaudeAgentOptions) ValidateSessionID() error {
+	if o.SessionID == nil {
+		return nil
+	}
+	if _, err := uuid.Parse(*o.SessionID); err != nil {
+		return fmt.Errorf("invalid session ID %q: must be a UUID", *o.SessionID)
+	}
+	resuming := o.ContinueConversation || (o.Resume != nil && *o.Resume != "")
+	if resuming && !o.ForkSession {
+		return fmt.Errorf("session ID %s requires WithForkSession(true) when resuming or continuing a session", *o.SessionID)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package types
+
+import "testing"
+
+// TestValidateSessionID tests the checks of session IDs set with WithSessionID
+func TestValidateSessionID(t *testing.T) {
+	const id = "0b9f3c1e-6a2d-4c4f-9a51-2f1d7e3b8c44"
+	tests := []struct {
+		name    string
+		opts    *ClaudeAgentOptions
+		wantErr bool
+	}{
+		{"unset", NewClaudeAgentOptions(), false},
+		{"uuid", NewClaudeAgentOptions().WithSessionID(id), false},
+		{"not a uuid", NewClaudeAgentOptions().WithSessionID("my-session"), true},
+		{"resume without fork", NewClaudeAgentOptions().WithSessionID(id).WithResume("old"), true},
+		{"continue without fork", NewClaudeAgentOptions().WithSessionID(id).WithContinueConversation(true), true},
+		{"resume with fork", NewClaudeAgentOptions().WithSessionID(id).WithResume("old").WithForkSession(true), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.ValidateSessionID(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSessionID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package types
+
+import "fmt"
+
+// Stream event types of the Anthropic Messages API, as carried in
+// StreamEvent.Event["type"].
+const (
+	StreamEventMessageStart      = "message_start"
+	StreamEventContentBlockStart = "content_block_start"
+	StreamEventContentBlockDelta = "content_block_delta"
+	StreamEventContentBlockStop  = "content_block_stop"
+	StreamEventMessageDelta      = "message_delta"
+	StreamEventMessageStop       = "message_stop"
+	StreamEventPing              = "ping"
+	StreamEventError             = "error"
+)
+
+// Delta types of a content_block_delta event.
+const (
+	DeltaTypeText      = "text_delta"
+	DeltaTypeInputJSON = "input_json_delta"
+	DeltaTypeThinking  = "thinking_delta"
+	DeltaTypeSignature = "signature_delta"
+)
+
+// TypedStreamEvent is a decoded Anthropic API stream event, returned by
+// StreamEvent.Decode. Switch on the concrete type:
+//
+//	event, err := streamEvent.Decode()
+//	...
+//	switch e := event.(type) {
+//	case *types.ContentBlockDeltaEvent:
+//	    if delta, ok := e.Delta.(*types.TextDelta); ok {
+//	        fmt.Print(delta.Text)
+//	    }
+//	case *types.MessageStopEvent:
+//	    fmt.Println()
+//	}
+type TypedStreamEvent interface {
+	// StreamEventType returns the event type, such as "message_start".
+	StreamEventType() string
+}
+
+// StreamMessage is the message a message_start event opens. Its content is
+// empty; it arrives through the content block events.
+type StreamMessage struct {
+	ID           string                 `json:"id"`
+	Type         string                 `json:"type"`
+	Role         string                 `json:"role"`
+	Model        string                 `json:"model"`
+	StopReason   *string                `json:"stop_reason,omitempty"`
+	StopSequence *string                `json:"stop_sequence,omitempty"`
+	Usage        map[string]interface{} `json:"usage,omitempty"`
+}
+
+// MessageStartEvent starts a message.
+type MessageStartEvent struct {
+	Type    string        `json:"type"`
+	Message StreamMessage `json:"message"`
+}
+
+// StreamContentBlock is the content block a content_block_start event
+// opens. Text, thinking and tool input arrive through the deltas that follow.
+type StreamContentBlock struct {
+	Type      string                 `json:"type"` // text, thinking, tool_use, server_tool_use, ...
+	Text      string                 `json:"text,omitempty"`
+	Thinking  string                 `json:"thinking,omitempty"`
+	Signature string                 `json:"signature,omitempty"`
+	Data      string                 `json:"data,omitempty"`  // Encrypted reasoning of a redacted_thinking block
+	ID        string                 `json:"id,omitempty"`    // Tool use ID
+	Name      string                 `json:"name,omitempty"`  // Tool name
+	Input     map[string]interface{} `json:"input,omitempty"` // Tool input; usually empty until the deltas arrive
+}
+
+// ContentBlockStartEvent starts the content block at Index.
+type ContentBlockStartEvent struct {
+	Type         string             `json:"type"`
+	Index        int                `json:"index"`
+	ContentBlock StreamContentBlock `json:"content_block"`
+}
+
+// ContentBlockDeltaEvent adds to the content block at Index. Delta is one
+// of *TextDelta, *InputJSONDelta, *ThinkingDelta, *SignatureDelta or
+// *UnknownDelta.
+type ContentBlockDeltaEvent struct {
+	Type  string      `json:"type"`
+	Index int         `json:"index"`
+	Delta StreamDelta `json:"delta"`
+}
+
+// ContentBlockStopEvent ends the content block at Index.
+type ContentBlockStopEvent struct {
+	Type  string `json:"type"`
+	Index int    `json:"index"`
+}
+
+// MessageDelta holds the message fields a message_delta event changes.
+type MessageDelta struct {
+	StopReason   *string `json:"stop_reason,omitempty"`
+	StopSequence *string `json:"stop_sequence,omitempty"`
+}
+
+// MessageDeltaEvent updates the message's stop reason and usage.
+type MessageDeltaEvent struct {
+	Type  string                 `json:"type"`
+	Delta MessageDelta           `json:"delta"`
+	Usage map[string]interface{} `json:"usage,omitempty"` // Cumulative token counts
+}
+
+// MessageStopEvent ends the message.
+type MessageStopEvent struct {
+	Type string `json:"type"`
+}
+
+// PingEvent keeps the stream alive.
+type PingEvent struct {
+	Type string `json:"type"`
+}
+
+// StreamError describes an error reported in the stream.
+type StreamError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// StreamErrorEvent reports an error, such as an overloaded API.
+type StreamErrorEvent struct {
+	Type  string      `json:"type"`
+	Error StreamError `json:"error"`
+}
+
+// UnknownStreamEvent is an event of a type this SDK does not decode. Raw
+// holds the event as received.
+type UnknownStreamEvent struct {
+	Type string
+	Raw  map[string]interface{}
+}
+
+func (e *MessageStartEvent) StreamEventType() string      { return e.Type }
+func (e *ContentBlockStartEvent) StreamEventType() string { return e.Type }
+func (e *ContentBlockDeltaEvent) StreamEventType() string { return e.Type }
+func (e *ContentBlockStopEvent) StreamEventType() string  { return e.Type }
+func (e *MessageDeltaEvent) StreamEventType() string      { return e.Type }
+func (e *MessageStopEvent) StreamEventType() string       { return e.Type }
+func (e *PingEvent) StreamEventType() string              { return e.Type }
+func (e *StreamErrorEvent) StreamEventType() string       { return e.Type }
+func (e *UnknownStreamEvent) StreamEventType() string     { return e.Type }
+
+// StreamDelta is the change carried by a content_block_delta event.
+type StreamDelta interface {
+	// DeltaType returns the delta type, such as "text_delta".
+	DeltaType() string
+}
+
+// TextDelta appends text to a text block.
+type TextDelta struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// InputJSONDelta appends a fragment of a tool call's JSON input. The
+// fragments of a block concatenate to the complete input.
+type InputJSONDelta struct {
+	Type        string `json:"type"`
+	PartialJSON string `json:"partial_json"`
+}
+
+// ThinkingDelta appends text to a thinking block.
+type ThinkingDelta struct {
+	Type     string `json:"type"`
+	Thinking string `json:"thinking"`
+}
+
+// SignatureDelta sets the signature of a thinking block.
+type SignatureDelta struct {
+	Type      string `json:"type"`
+	Signature string `json:"signature"`
+}
+
+// UnknownDelta is a delta of a type this SDK does not decode, such as
+// citations_delta. Raw holds the delta as received.
+type UnknownDelta struct {
+	Type string
+	Raw  map[string]interface{}
+}
+
+func (d *TextDelta) DeltaType() string      { return d.Type }
+func (d *InputJSONDelta) DeltaType() string { return d.Type }
+func (d *ThinkingDelta) DeltaType() string  { return d.Type }
+func (d *SignatureDelta) DeltaType() string { return d.Type }
+func (d *UnknownDelta) DeltaType() string   { return d.Type }
+
+// UnmarshalJSON decodes the event, including its delta.
+func (e *ContentBlockDeltaEvent) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type  string                 `json:"type"`
+		Index int                    `json:"index"`
+		Delta map[string]interface{} `json:"delta"`
+	}
+	if err := jsonUnmarshal(data, &raw); err != nil {
+		return err
+	}
+	e.Type = raw.Type
+	e.Index = raw.Index
+	delta, err := decodeDelta(raw.Delta)
+	if err != nil {
+		return err
+	}
+	e.Delta = delta
+	return nil
+}
+
+// decodeDelta converts a raw delta to its typed form.
+func decodeDelta(raw map[string]interface{}) (StreamDelta, error) {
+	deltaType, _ := raw["type"].(string)
+	var delta StreamDelta
+	switch deltaType {
+	case DeltaTypeText:
+		delta = &TextDelta{}
+	case DeltaTypeInputJSON:
+		delta = &InputJSONDelta{}
+	case DeltaTypeThinking:
+		delta = &ThinkingDelta{}
+	case DeltaTypeSignature:
+		delta = &SignatureDelta{}
+	default:
+		return &UnknownDelta{Type: deltaType, Raw: raw}, nil
+	}
+	if err := remarshal(raw, delta); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", deltaType, err)
+	}
+	return delta, nil
+}
+
+// Decode returns the typed form of the event. Events of types this SDK
+// does not know are returned as *UnknownStreamEvent.
+func (m *StreamEvent) Decode() (TypedStreamEvent, error) {
+	eventType, _ := m.Event["type"].(string)
+	var event TypedStreamEvent
+	switch eventType {
+	case StreamEventMessageStart:
+		event = &MessageStartEvent{}
+	case StreamEventContentBlockStart:
+		event = &ContentBlockStartEvent{}
+	case StreamEventContentBlockDelta:
+		event = &ContentBlockDeltaEvent{}
+	case StreamEventContentBlockStop:
+		event = &ContentBlockStopEvent{}
+	case StreamEventMessageDelta:
+		event = &MessageDeltaEvent{}
+	case StreamEventMessageStop:
+		event = &MessageStopEvent{}
+	case StreamEventPing:
+		event = &PingEvent{}
+	case StreamEventError:
+		event = &StreamErrorEvent{}
+	default:
+		return &UnknownStreamEvent{Type: eventType, Raw: m.Event}, nil
+	}
+	if err := remarshal(m.Event, event); err != nil {
+		return nil, NewMessageParseErrorWithCause("failed to decode stream event", eventType, err)
+	}
+	return event, nil
+}
+
+// remarshal decodes the JSON form of v into target.
+func remarshal(v interface{}, target interface{}) error {
+	data, err := jsonMarshal(v)
+	if err != nil {
+		return err
+	}
+	return jsonUnmarshal(data, target)
+}
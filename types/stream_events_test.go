@@ -0,0 +1,113 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// streamEvent parses the JSON of a stream_event message.
+func streamEvent(t *testing.T, event string) *StreamEvent {
+	t.Helper()
+	msg, err := UnmarshalMessage([]byte(`{"type":"stream_event","uuid":"u1","session_id":"s1","event":` + event + `}`))
+	if err != nil {
+		t.Fatalf("UnmarshalMessage failed: %v", err)
+	}
+	return msg.(*StreamEvent)
+}
+
+// TestStreamEventDecode tests decoding each stream event type.
+func TestStreamEventDecode(t *testing.T) {
+	event, err := streamEvent(t, `{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[],"usage":{"input_tokens":12}}}`).Decode()
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if start, ok := event.(*MessageStartEvent); !ok || start.Message.ID != "msg_1" || start.Message.Usage["input_tokens"] != 12.0 {
+		t.Errorf("unexpected message_start: %+v", event)
+	}
+
+	event, _ = streamEvent(t, `{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"Bash","input":{}}}`).Decode()
+	if start, ok := event.(*ContentBlockStartEvent); !ok || start.Index != 1 || start.ContentBlock.Name != "Bash" || start.ContentBlock.ID != "toolu_1" {
+		t.Errorf("unexpected content_block_start: %+v", event)
+	}
+
+	deltas := map[string]func(StreamDelta) bool{
+		`{"type":"text_delta","text":"Hello"}`: func(d StreamDelta) bool {
+			text, ok := d.(*TextDelta)
+			return ok && text.Text == "Hello"
+		},
+		`{"type":"input_json_delta","partial_json":"{\"command\":"}`: func(d StreamDelta) bool {
+			input, ok := d.(*InputJSONDelta)
+			return ok && input.PartialJSON == `{"command":`
+		},
+		`{"type":"thinking_delta","thinking":"Let me see"}`: func(d StreamDelta) bool {
+			thinking, ok := d.(*ThinkingDelta)
+			return ok && thinking.Thinking == "Let me see"
+		},
+		`{"type":"signature_delta","signature":"sig"}`: func(d StreamDelta) bool {
+			signature, ok := d.(*SignatureDelta)
+			return ok && signature.Signature == "sig"
+		},
+		`{"type":"citations_delta","citation":{}}`: func(d StreamDelta) bool {
+			unknown, ok := d.(*UnknownDelta)
+			return ok && unknown.Type == "citations_delta" && unknown.Raw["citation"] != nil
+		},
+	}
+	for raw, check := range deltas {
+		event, err := streamEvent(t, `{"type":"content_block_delta","index":2,"delta":`+raw+`}`).Decode()
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		delta, ok := event.(*ContentBlockDeltaEvent)
+		if !ok || delta.Index != 2 || !check(delta.Delta) {
+			t.Errorf("unexpected delta for %s: %+v", raw, event)
+		}
+	}
+
+	event, _ = streamEvent(t, `{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":42}}`).Decode()
+	if delta, ok := event.(*MessageDeltaEvent); !ok || delta.Delta.StopReason == nil || *delta.Delta.StopReason != "tool_use" {
+		t.Errorf("unexpected message_delta: %+v", event)
+	}
+
+	event, _ = streamEvent(t, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`).Decode()
+	if e, ok := event.(*StreamErrorEvent); !ok || e.Error.Type != "overloaded_error" {
+		t.Errorf("unexpected error event: %+v", event)
+	}
+
+	for raw, want := range map[string]string{
+		`{"type":"content_block_stop","index":0}`: StreamEventContentBlockStop,
+		`{"type":"message_stop"}`:                 StreamEventMessageStop,
+		`{"type":"ping"}`:                         StreamEventPing,
+		`{"type":"future_event","data":1}`:        "future_event",
+	} {
+		event, err := streamEvent(t, raw).Decode()
+		if err != nil || event.StreamEventType() != want {
+			t.Errorf("expected %s, got %+v, %v", want, event, err)
+		}
+	}
+	if unknown, ok := mustDecode(t, `{"type":"future_event","data":1}`).(*UnknownStreamEvent); !ok || unknown.Raw["data"] != 1.0 {
+		t.Errorf("expected an unknown event with the raw data, got %+v", unknown)
+	}
+}
+
+// mustDecode decodes a stream event.
+func mustDecode(t *testing.T, raw string) TypedStreamEvent {
+	t.Helper()
+	event, err := streamEvent(t, raw).Decode()
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	return event
+}
+
+// TestStreamEventDecodeInvalid tests that malformed events return an error.
+func TestStreamEventDecodeInvalid(t *testing.T) {
+	_, err := streamEvent(t, `{"type":"content_block_delta","index":"first","delta":{"type":"text_delta","text":"x"}}`).Decode()
+	var parseErr *MessageParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("expected a MessageParseError, got %v", err)
+	}
+	if _, err := json.Marshal(&ContentBlockDeltaEvent{Type: "content_block_delta", Delta: &TextDelta{Type: "text_delta", Text: "x"}}); err != nil {
+		t.Errorf("expected the typed event to encode, got %v", err)
+	}
+}
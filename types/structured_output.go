@@ -0,0 +1,144 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/M1n9X/claude-agent-sdk-go/schema"
+)
+
+// DecodeStructuredOutput returns the structured output of result as a T,
+// the type passed to ClaudeAgentOptions.WithStructuredOutput. The output is
+// first validated against the JSON schema of T; when it does not conform,
+// the error wraps a schema.ValidationErrors naming each offending field.
+//
+// Example:
+//
+//	opts := types.NewClaudeAgentOptions().WithStructuredOutput(Review{})
+//	...
+//	if result, ok := msg.(*types.ResultMessage); ok {
+//	    review, err := types.DecodeStructuredOutput[Review](result)
+//	    ...
+//	}
+func DecodeStructuredOutput[T any](result *ResultMessage) (T, error) {
+	var zero T
+	return decodeStructuredOutput[T](result, schema.FromType(reflect.TypeOf(zero)))
+}
+
+// StructuredOutputFormat returns the output format requesting structured
+// output of type T, with the JSON schema generated from T, for use with
+// WithOutputFormat. It is the type-parameter form of WithStructuredOutput
+// and pairs with DecodeStructuredOutput[T].
+//
+// Example:
+//
+//	opts := types.NewClaudeAgentOptions().WithOutputFormat(types.StructuredOutputFormat[Review]())
+func StructuredOutputFormat[T any]() map[string]interface{} {
+	return map[string]interface{}{
+		"type":   "json_schema",
+		"schema": schema.FromType(reflect.TypeOf((*T)(nil)).Elem()),
+	}
+}
+
+// DecodeStructuredOutputWithSchema is DecodeStructuredOutput for output
+// requested with a hand-written schema, as set by WithJSONSchemaOutput. The
+// output is validated against outputSchema.
+func DecodeStructuredOutputWithSchema[T any](result *ResultMessage, outputSchema map[string]interface{}) (T, error) {
+	return decodeStructuredOutput[T](result, outputSchema)
+}
+
+// decodeStructuredOutput validates the structured output of result against
+// outputSchema and decodes it into a T.
+func decodeStructuredOutput[T any](result *ResultMessage, outputSchema map[string]interface{}) (T, error) {
+	var value T
+	if result == nil {
+		return value, fmt.Errorf("no result message")
+	}
+	if result.StructuredOutput == nil {
+		if result.IsError {
+			return value, fmt.Errorf("result has no structured output: query ended with %s", result.Subtype)
+		}
+		return value, fmt.Errorf("result has no structured output; request it with WithStructuredOutput or WithJSONSchemaOutput")
+	}
+
+	data, err := json.Marshal(result.StructuredOutput)
+	if err != nil {
+		return value, fmt.Errorf("encode structured output: %w", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return value, fmt.Errorf("decode structured output: %w", err)
+	}
+	if err := schema.Validate(outputSchema, decoded); err != nil {
+		return value, fmt.Errorf("structured output does not match schema: %w", err)
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("decode structured output into %T: %w", value, err)
+	}
+	return value, nil
+}
+
+// OutputSchema returns the JSON schema of a json_schema output format, as
+// set by WithJSONSchemaOutput or WithStructuredOutput, or nil if none is set.
+func (o *ClaudeAgentOptions) OutputSchema() map[string]interface{} {
+	if o == nil {
+		return nil
+	}
+	if formatType, _ := o.OutputFormat["type"].(string); formatType != "json_schema" {
+		return nil
+	}
+	switch s := o.OutputFormat["schema"].(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		return s
+	default:
+		// A schema given as a struct or raw JSON
+		data, err := json.Marshal(s)
+		if err != nil {
+			return nil
+		}
+		var decoded map[string]interface{}
+		if json.Unmarshal(data, &decoded) != nil {
+			return nil
+		}
+		return decoded
+	}
+}
+
+// ValidateStructuredOutput validates the structured output of result
+// against outputSchema and records the problems found in the result's
+// StructuredOutputErrors, which it returns. A result without structured
+// output has no problems.
+func ValidateStructuredOutput(result *ResultMessage, outputSchema map[string]interface{}) schema.ValidationErrors {
+	result.StructuredOutputErrors = nil
+	if result.StructuredOutput == nil || outputSchema == nil {
+		return nil
+	}
+	data, err := json.Marshal(result.StructuredOutput)
+	if err != nil {
+		return nil
+	}
+	var decoded interface{}
+	if json.Unmarshal(data, &decoded) != nil {
+		return nil
+	}
+	if errs, ok := schema.Validate(outputSchema, decoded).(schema.ValidationErrors); ok {
+		result.StructuredOutputErrors = errs
+	}
+	return result.StructuredOutputErrors
+}
+
+// StructuredOutputFixPrompt returns the prompt asking Claude to correct
+// structured output with the given problems.
+func StructuredOutputFixPrompt(errs schema.ValidationErrors) string {
+	var b strings.Builder
+	b.WriteString("Your structured output does not match the required JSON schema:\n")
+	for _, err := range errs {
+		fmt.Fprintf(&b, "- %s\n", err)
+	}
+	b.WriteString("Respond again with structured output that fixes these problems.")
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/M1n9X/claude-agent-sdk-go/schema"
+)
+
+type testReview struct {
+	Verdict  string   `json:"verdict" enum:"approve,reject"`
+	Score    int      `json:"score"`
+	Comments []string `json:"comments,omitempty"`
+}
+
+// TestDecodeStructuredOutput tests decoding and validating structured output.
+func TestDecodeStructuredOutput(t *testing.T) {
+	parse := func(output string) *ResultMessage {
+		t.Helper()
+		msg, err := UnmarshalMessage([]byte(`{"type":"result","subtype":"success","structured_output":` + output + `}`))
+		if err != nil {
+			t.Fatalf("UnmarshalMessage failed: %v", err)
+		}
+		return msg.(*ResultMessage)
+	}
+
+	review, err := DecodeStructuredOutput[testReview](parse(`{"verdict":"approve","score":4,"comments":["nice"]}`))
+	if err != nil {
+		t.Fatalf("DecodeStructuredOutput failed: %v", err)
+	}
+	if review.Verdict != "approve" || review.Score != 4 || len(review.Comments) != 1 {
+		t.Errorf("unexpected review: %+v", review)
+	}
+
+	_, err = DecodeStructuredOutput[testReview](parse(`{"verdict":"maybe","score":4.5,"comments":["ok",3]}`))
+	var validation schema.ValidationErrors
+	if !errors.As(err, &validation) || len(validation) != 3 {
+		t.Fatalf("expected 3 validation errors, got %v", err)
+	}
+	for _, want := range []string{`$.verdict: must be one of ["approve","reject"]`, "$.score: expected integer, got number", "$.comments[1]: expected string, got integer"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %v", want, err)
+		}
+	}
+
+	if _, err := DecodeStructuredOutput[testReview](parse(`{"score":1}`)); err == nil || !strings.Contains(err.Error(), `missing required property "verdict"`) {
+		t.Errorf("expected a missing property error, got %v", err)
+	}
+	if _, err := DecodeStructuredOutput[testReview](&ResultMessage{IsError: true, Subtype: "error_max_structured_output_retries"}); err == nil || !strings.Contains(err.Error(), "error_max_structured_output_retries") {
+		t.Errorf("expected an error naming the subtype, got %v", err)
+	}
+
+	custom := map[string]interface{}{
+		"type":                 "object",
+		"properties":           map[string]interface{}{"count": map[string]interface{}{"type": "integer", "minimum": 0}},
+		"required":             []string{"count"},
+		"additionalProperties": false,
+	}
+	counts, err := DecodeStructuredOutputWithSchema[map[string]int](parse(`{"count":3}`), custom)
+	if err != nil || counts["count"] != 3 {
+		t.Errorf("DecodeStructuredOutputWithSchema() = %v, %v", counts, err)
+	}
+	if _, err := DecodeStructuredOutputWithSchema[map[string]int](parse(`{"count":-1,"extra":1}`), custom); err == nil || !strings.Contains(err.Error(), "$.extra: is not an allowed property") {
+		t.Errorf("expected minimum and additionalProperties errors, got %v", err)
+	}
+}
+
+// TestValidateStructuredOutput tests that validation problems are recorded on the result
+func TestValidateStructuredOutput(t *testing.T) {
+	opts := NewClaudeAgentOptions().WithJSONSchemaOutput(json.RawMessage(`{"type":"object","required":["answer"]}`))
+	outputSchema := opts.OutputSchema()
+	if outputSchema["type"] != "object" {
+		t.Fatalf("OutputSchema() = %v, want the decoded schema", outputSchema)
+	}
+
+	result := &ResultMessage{StructuredOutput: map[string]interface{}{}}
+	errs := ValidateStructuredOutput(result, outputSchema)
+	if len(errs) != 1 || len(result.StructuredOutputErrors) != 1 {
+		t.Fatalf("ValidateStructuredOutput() = %v, want the missing answer", errs)
+	}
+	if prompt := StructuredOutputFixPrompt(errs); !strings.Contains(prompt, errs[0].Error()) {
+		t.Errorf("StructuredOutputFixPrompt() = %q, want it to name %q", prompt, errs[0])
+	}
+
+	result.StructuredOutput = map[string]interface{}{"answer": "42"}
+	if errs := ValidateStructuredOutput(result, outputSchema); errs != nil || result.StructuredOutputErrors != nil {
+		t.Errorf("ValidateStructuredOutput() = %v for conforming output", errs)
+	}
+	if NewClaudeAgentOptions().OutputSchema() != nil {
+		t.Error("OutputSchema() without output format is not nil")
+	}
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MinThinkingBudgetTokens is the smallest thinking budget the API accepts.
+const MinThinkingBudgetTokens = 1024
+
+// noThinkingModels are the prefixes of the models without extended
+// thinking. Claude 3.7 Sonnet and later models support it.
+var noThinkingModels = []string{
+	"claude-instant",
+	"claude-2",
+	"claude-3-haiku",
+	"claude-3-sonnet",
+	"claude-3-opus",
+	"claude-3-5-",
+}
+
+// ModelSupportsThinking reports whether a model supports extended thinking.
+// Aliases such as "sonnet" and models it does not know are assumed to.
+func ModelSupportsThinking(model string) bool {
+	model = strings.ToLower(model)
+	for _, prefix := range noThinkingModels {
+		if strings.HasPrefix(model, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// ThinkingEnabled reports whether extended thinking is enabled with
+// WithThinking or WithMaxThinkingTokens.
+func (o *ClaudeAgentOptions) ThinkingEnabled() bool {
+	return o.MaxThinkingTokens != nil && *o.MaxThinkingTokens > 0
+}
+
+// ValidateThinking checks the thinking budget, and that the model and
+// fallback model support thinking when it is enabled.
+func (o *ClaudeAgentOptions) ValidateThinking() error {
+	if o.MaxThinkingTokens == nil {
+		return nil
+	}
+	budget := *o.MaxThinkingTokens
+	if budget < 0 {
+		return fmt.Errorf("invalid thinking budget %d: must not be negative", budget)
+	}
+	if budget == 0 {
+		return nil
+	}
+	if budget < MinThinkingBudgetTokens {
+		return fmt.Errorf("invalid thinking budget %d: must be at least %d tokens", budget, MinThinkingBudgetTokens)
+	}
+	for _, model := range []*string{o.Model, o.FallbackModel} {
+		if model != nil && !ModelSupportsThinking(*model) {
+			return fmt.Errorf("model %s does not support extended thinking; use Claude 3.7 Sonnet or later, or disable thinking", *model)
+		}
+	}
+	return nil
+}
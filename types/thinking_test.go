@@ -0,0 +1,41 @@
+package types
+
+import "testing"
+
+// TestWithThinking tests enabling and disabling extended thinking
+func TestWithThinking(t *testing.T) {
+	opts := NewClaudeAgentOptions().WithThinking(true, 4096)
+	if !opts.ThinkingEnabled() || *opts.MaxThinkingTokens != 4096 {
+		t.Errorf("WithThinking(true, 4096) set MaxThinkingTokens = %v", opts.MaxThinkingTokens)
+	}
+	opts.WithThinking(false, 4096)
+	if opts.ThinkingEnabled() || *opts.MaxThinkingTokens != 0 {
+		t.Errorf("WithThinking(false, 4096) set MaxThinkingTokens = %v", *opts.MaxThinkingTokens)
+	}
+}
+
+// TestValidateThinking tests the checks of thinking budgets and models
+func TestValidateThinking(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *ClaudeAgentOptions
+		wantErr bool
+	}{
+		{"unset", NewClaudeAgentOptions(), false},
+		{"disabled on old model", NewClaudeAgentOptions().WithModel("claude-3-5-haiku-latest").WithThinking(false, 0), false},
+		{"enabled", NewClaudeAgentOptions().WithModel("claude-sonnet-4-5").WithThinking(true, 2048), false},
+		{"alias", NewClaudeAgentOptions().WithModel("opus").WithThinking(true, 2048), false},
+		{"3.7 sonnet", NewClaudeAgentOptions().WithModel("claude-3-7-sonnet-latest").WithThinking(true, 2048), false},
+		{"budget too small", NewClaudeAgentOptions().WithThinking(true, 512), true},
+		{"negative budget", NewClaudeAgentOptions().WithMaxThinkingTokens(-1), true},
+		{"old model", NewClaudeAgentOptions().WithModel("claude-3-5-sonnet-20241022").WithThinking(true, 2048), true},
+		{"old fallback model", NewClaudeAgentOptions().WithFallbackModel("claude-3-haiku-20240307").WithThinking(true, 2048), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.ValidateThinking(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateThinking() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
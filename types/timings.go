@@ -0,0 +1,37 @@
+package types
+
+import "time"
+
+// Timings is the latency breakdown of a query, measured by the SDK from
+// the messages it exchanges with the CLI. It is set on the ResultMessage of
+// each query sent with Query or Client.Query.
+type Timings struct {
+	// Spawn is from starting the CLI process to its first output. It is
+	// only set on the first result of a session.
+	Spawn time.Duration
+
+	// FirstToken is from sending the prompt to the first streamed text
+	// delta, or the first assistant message without partial messages.
+	FirstToken time.Duration
+
+	// Total is from sending the prompt to the result.
+	Total time.Duration
+
+	// Model is the time spent waiting for Claude, and Tools the time spent
+	// running tools, over all turns. Subagents count as tool time.
+	Model time.Duration
+	Tools time.Duration
+
+	// Hooks is the time spent in the SDK's hook callbacks, which the CLI
+	// waits for. Async hooks are not counted.
+	Hooks time.Duration
+
+	// Turns is the breakdown per turn, the last one ending with the result.
+	Turns []TurnTimings
+}
+
+// TurnTimings is the latency breakdown of a turn of a query.
+type TurnTimings struct {
+	Model time.Duration // From the prompt or the previous tool results to Claude's tool uses
+	Tools time.Duration // From the tool uses to the last of their results
+}
@@ -0,0 +1,143 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Args wraps a tool input with typed accessors that return descriptive
+// errors instead of panicking when Claude sends a missing or mistyped
+// argument. Report the error to Claude as an error result so it can
+// correct the call.
+//
+// Example:
+//
+//	Handler(func(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+//	    args := types.Args(input)
+//	    a, err := args.GetFloat("a")
+//	    if err != nil {
+//	        return types.NewErrorMcpToolResult(err.Error()), nil
+//	    }
+//	    ...
+//	})
+type Args map[string]interface{}
+
+// ArgError reports a tool argument that is missing or has the wrong type.
+type ArgError struct {
+	Name     string      // Argument name
+	Expected string      // Expected type, such as "a string"
+	Value    interface{} // Value received; nil when missing
+}
+
+func (e *ArgError) Error() string {
+	if e.Value == nil {
+		return fmt.Sprintf("argument %q is required", e.Name)
+	}
+	return fmt.Sprintf("argument %q must be %s, got %s", e.Name, e.Expected, describeArg(e.Value))
+}
+
+// describeArg describes a received argument value for an error message.
+func describeArg(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case float64, json.Number:
+		return fmt.Sprintf("number %v", v)
+	case bool:
+		return fmt.Sprintf("boolean %v", v)
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// Has reports whether the argument is present and not null.
+func (a Args) Has(name string) bool {
+	return a[name] != nil
+}
+
+// GetString returns a string argument.
+func (a Args) GetString(name string) (string, error) {
+	if s, ok := a[name].(string); ok {
+		return s, nil
+	}
+	return "", &ArgError{Name: name, Expected: "a string", Value: a[name]}
+}
+
+// GetFloat returns a number argument. Numbers sent as strings, such as
+// "3.5", are accepted.
+func (a Args) GetFloat(name string) (float64, error) {
+	switch v := a[name].(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f, nil
+		}
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f, nil
+		}
+	}
+	return 0, &ArgError{Name: name, Expected: "a number", Value: a[name]}
+}
+
+// GetInt returns a whole number argument.
+func (a Args) GetInt(name string) (int, error) {
+	f, err := a.GetFloat(name)
+	if err != nil || f != math.Trunc(f) || f > math.MaxInt || f < math.MinInt {
+		return 0, &ArgError{Name: name, Expected: "an integer", Value: a[name]}
+	}
+	return int(f), nil
+}
+
+// GetBool returns a boolean argument. The strings "true" and "false" are
+// accepted.
+func (a Args) GetBool(name string) (bool, error) {
+	switch v := a[name].(type) {
+	case bool:
+		return v, nil
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, nil
+		}
+	}
+	return false, &ArgError{Name: name, Expected: "a boolean", Value: a[name]}
+}
+
+// GetStringSlice returns an array of strings argument.
+func (a Args) GetStringSlice(name string) ([]string, error) {
+	switch v := a[name].(type) {
+	case []string:
+		return v, nil
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, &ArgError{Name: fmt.Sprintf("%s[%d]", name, i), Expected: "a string", Value: item}
+			}
+			values[i] = s
+		}
+		return values, nil
+	}
+	return nil, &ArgError{Name: name, Expected: "an array of strings", Value: a[name]}
+}
+
+// Decode decodes the arguments into a struct through its json tags.
+func (a Args) Decode(v interface{}) error {
+	if err := decodeToolInput(a, v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestArgs tests the typed accessors and their errors.
+func TestArgs(t *testing.T) {
+	args := Args{
+		"name":  "Alice",
+		"age":   float64(30),
+		"score": "4.5",
+		"half":  2.5,
+		"admin": true,
+		"tags":  []interface{}{"a", "b"},
+		"mixed": []interface{}{"a", 1.0},
+		"none":  nil,
+	}
+
+	if s, err := args.GetString("name"); err != nil || s != "Alice" {
+		t.Errorf("GetString() = %q, %v", s, err)
+	}
+	if n, err := args.GetInt("age"); err != nil || n != 30 {
+		t.Errorf("GetInt() = %d, %v", n, err)
+	}
+	if f, err := args.GetFloat("score"); err != nil || f != 4.5 {
+		t.Errorf("GetFloat() = %v, %v", f, err)
+	}
+	if b, err := args.GetBool("admin"); err != nil || !b {
+		t.Errorf("GetBool() = %v, %v", b, err)
+	}
+	if tags, err := args.GetStringSlice("tags"); err != nil || len(tags) != 2 || tags[1] != "b" {
+		t.Errorf("GetStringSlice() = %v, %v", tags, err)
+	}
+	if args.Has("none") || args.Has("missing") || !args.Has("name") {
+		t.Error("unexpected Has result")
+	}
+
+	errorTests := []struct {
+		get  func() error
+		want string
+	}{
+		{func() error { _, err := args.GetString("missing"); return err }, `argument "missing" is required`},
+		{func() error { _, err := args.GetString("age"); return err }, `argument "age" must be a string, got number 30`},
+		{func() error { _, err := args.GetFloat("name"); return err }, `argument "name" must be a number, got string "Alice"`},
+		{func() error { _, err := args.GetInt("half"); return err }, `argument "half" must be an integer, got number 2.5`},
+		{func() error { _, err := args.GetBool("tags"); return err }, `argument "tags" must be a boolean, got an array`},
+		{func() error { _, err := args.GetStringSlice("mixed"); return err }, `argument "mixed[1]" must be a string, got number 1`},
+		{func() error { _, err := args.GetInt("none"); return err }, `argument "none" is required`},
+	}
+	for _, tt := range errorTests {
+		err := tt.get()
+		var argErr *ArgError
+		if !errors.As(err, &argErr) || err.Error() != tt.want {
+			t.Errorf("expected %q, got %v", tt.want, err)
+		}
+	}
+
+	var profile struct {
+		Name string   `json:"name"`
+		Age  int      `json:"age"`
+		Tags []string `json:"tags"`
+	}
+	if err := args.Decode(&profile); err != nil || profile.Name != "Alice" || profile.Age != 30 || len(profile.Tags) != 2 {
+		t.Errorf("Decode() = %+v, %v", profile, err)
+	}
+	if err := (Args{"age": "old"}).Decode(&profile); err == nil {
+		t.Error("expected error for a mistyped field")
+	}
+}
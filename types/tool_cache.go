@@ -0,0 +1,137 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Cache memoizes successful results of the tool for ttl, keyed by its input,
+// so an identical call made again by Claude returns the earlier result
+// without running the handler. Use it for idempotent tools such as API
+// lookups; calls that fail or return an error result are not cached.
+//
+// The cache belongs to the built tool, so it is shared by every session
+// that uses the tool. Results are also keyed by the caller identity of the
+// call, see ContextWithCaller, so one caller never receives a result
+// computed for another.
+func (b *ToolBuilder) Cache(ttl time.Duration) *ToolBuilder {
+	b.cacheTTL = ttl
+	return b
+}
+
+// CacheKey sets the function that maps an input to its cache key, for
+// example to ignore fields that do not affect the result. Inputs with the
+// same key share a result, within the calls of one caller. By default the key is the input encoded as JSON,
+// whose object keys are sorted.
+func (b *ToolBuilder) CacheKey(key func(input map[string]interface{}) string) *ToolBuilder {
+	b.cacheKey = key
+	return b
+}
+
+// toolCache holds the results of a tool built with Cache.
+type toolCache struct {
+	ttl time.Duration
+	key func(input map[string]interface{}) string
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]toolCacheEntry
+}
+
+// toolCacheEntry is a cached result and when it expires.
+type toolCacheEntry struct {
+	result  *ToolResult
+	expires time.Time
+}
+
+// newToolCache returns a cache for results kept for ttl, or nil when ttl is not positive.
+func newToolCache(ttl time.Duration, key func(map[string]interface{}) string) *toolCache {
+	if ttl <= 0 {
+		return nil
+	}
+	if key == nil {
+		key = defaultCacheKey
+	}
+	return &toolCache{ttl: ttl, key: key, now: time.Now, entries: make(map[string]toolCacheEntry)}
+}
+
+// defaultCacheKey returns the input encoded as JSON. encoding/json sorts map
+// keys, so equal inputs give equal keys.
+func defaultCacheKey(input map[string]interface{}) string {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// callerCacheKey returns the caller identity of ctx as part of a cache key,
+// or an empty string without a caller. The Go syntax representation
+// includes the caller's type and unexported fields, which a JSON encoding
+// would drop.
+func callerCacheKey(ctx context.Context) string {
+	caller, ok := CallerFrom(ctx)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%#v", caller)
+}
+
+// get returns the cached result for key, if it has not expired.
+func (c *toolCache) get(key string) (*ToolResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return copyToolResult(entry.result), true
+}
+
+// put caches result under key and drops expired entries.
+func (c *toolCache) put(key string, result *ToolResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = toolCacheEntry{result: copyToolResult(result), expires: now.Add(c.ttl)}
+}
+
+// execute returns the cached result for the caller in ctx and input, or
+// runs handler and caches its result.
+func (c *toolCache) execute(ctx context.Context, input map[string]interface{}, handler func() (*ToolResult, error)) (*ToolResult, error) {
+	key := c.key(input)
+	if key == "" {
+		return handler()
+	}
+	key = callerCacheKey(ctx) + "\x00" + key
+	if result, ok := c.get(key); ok {
+		return result, nil
+	}
+
+	result, err := handler()
+	if err == nil && result != nil && !result.IsError {
+		c.put(key, result)
+	}
+	return result, err
+}
+
+// copyToolResult returns a copy of result that does not share its content slice.
+func copyToolResult(result *ToolResult) *ToolResult {
+	copied := *result
+	copied.Content = append([]ContentBlock(nil), result.Content...)
+	return &copied
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestToolCache tests that results are reused for equal inputs until they expire.
+func TestToolCache(t *testing.T) {
+	calls := 0
+	lookup, err := NewTool("lookup").
+		Description("Look up a user").
+		StringParam("user", "User name", true).
+		StringParam("trace", "Trace ID", false).
+		Cache(time.Minute).
+		Handler(func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			calls++
+			return NewMcpToolResult(TextBlock{Type: "text", Text: input["user"].(string)}), nil
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build tool: %v", err)
+	}
+	cache := lookup.(*tool).cache
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		result, err := lookup.Execute(context.Background(), map[string]interface{}{"user": "alice"})
+		if err != nil || result.Content[0].(TextBlock).Text != "alice" {
+			t.Fatalf("unexpected result: %v, %v", result, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the handler to run once, ran %d times", calls)
+	}
+
+	if _, err := lookup.Execute(context.Background(), map[string]interface{}{"user": "bob"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected a different input to run the handler, ran %d times", calls)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := lookup.Execute(context.Background(), map[string]interface{}{"user": "alice"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected an expired result to run the handler, ran %d times", calls)
+	}
+}
+
+// TestToolCacheKey tests custom cache keys and that failures are not cached.
+func TestToolCacheKey(t *testing.T) {
+	calls := 0
+	fail := true
+	tool, err := NewTool("lookup").
+		Description("Look up a user").
+		StringParam("user", "User name", true).
+		StringParam("trace", "Trace ID", false).
+		Cache(time.Minute).
+		CacheKey(func(input map[string]interface{}) string {
+			user, _ := input["user"].(string)
+			return user
+		}).
+		Handler(func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			calls++
+			if fail {
+				return nil, errors.New("backend unavailable")
+			}
+			return NewMcpToolResult(TextBlock{Type: "text", Text: "ok"}), nil
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build tool: %v", err)
+	}
+
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"user": "alice", "trace": "1"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	fail = false
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"user": "alice", "trace": "2"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"user": "alice", "trace": "3"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the failed call to be retried and the next one cached, ran %d times", calls)
+	}
+}
+
+// TestToolCacheCaller tests that cached results are not shared between callers.
+func TestToolCacheCaller(t *testing.T) {
+	calls := 0
+	profile, err := NewTool("profile").
+		Description("Show the caller's profile").
+		Cache(time.Minute).
+		Handler(func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			calls++
+			user, _ := CallerAs[string](ctx)
+			return NewMcpToolResult(TextBlock{Type: "text", Text: user}), nil
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build tool: %v", err)
+	}
+
+	for _, user := range []string{"alice", "bob", "alice"} {
+		result, err := profile.Execute(ContextWithCaller(context.Background(), user), map[string]interface{}{})
+		if err != nil || result.Content[0].(TextBlock).Text != user {
+			t.Fatalf("expected the profile of %s, got %v, %v", user, result, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected one handler run per caller, ran %d times", calls)
+	}
+
+	type claims struct{ subject string }
+	for _, subject := range []string{"alice", "bob"} {
+		if _, err := profile.Execute(ContextWithCaller(context.Background(), &claims{subject: subject}), map[string]interface{}{}); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+	}
+	if calls != 4 {
+		t.Errorf("expected callers differing in unexported fields not to share results, ran %d times", calls)
+	}
+}
@@ -0,0 +1,204 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrToolBusy is returned by ConcurrencyLimiter.Acquire when a call gets no
+// slot, because the queue is full or the call waited longer than the
+// limit's QueueTimeout.
+var ErrToolBusy = errors.New("too many concurrent tool calls")
+
+// ConcurrencyLimit bounds how many tool calls run at once, so a burst of
+// parallel tool calls from Claude does not overwhelm a downstream service.
+// Calls past the limit wait for a slot in arrival order.
+type ConcurrencyLimit struct {
+	// MaxConcurrent is the number of calls allowed to run at once.
+	MaxConcurrent int
+	// MaxQueued is the number of calls allowed to wait for a slot; calls
+	// past it are rejected. Zero allows any number, and a negative value
+	// rejects calls that cannot start at once.
+	MaxQueued int
+	// QueueTimeout caps how long a call waits for a slot. Zero waits until
+	// the call's context ends.
+	QueueTimeout time.Duration
+}
+
+// Validate checks the limit's values.
+func (l ConcurrencyLimit) Validate() error {
+	if l.MaxConcurrent <= 0 {
+		return fmt.Errorf("concurrency limit must allow at least one call, got %d", l.MaxConcurrent)
+	}
+	if l.QueueTimeout < 0 {
+		return fmt.Errorf("concurrency queue timeout must not be negative, got %v", l.QueueTimeout)
+	}
+	return nil
+}
+
+// ConcurrencyStats holds the counters of a ConcurrencyLimiter.
+type ConcurrencyStats struct {
+	MaxConcurrent int   `json:"max_concurrent"`
+	Running       int   `json:"running"`   // Calls holding a slot
+	Queued        int   `json:"queued"`    // Calls waiting for a slot
+	Started       int64 `json:"started"`   // Calls that got a slot
+	Rejected      int64 `json:"rejected"`  // Calls rejected because the queue was full
+	TimedOut      int64 `json:"timed_out"` // Calls that waited longer than QueueTimeout
+}
+
+// ConcurrencyLimiter enforces a ConcurrencyLimit. Calls sharing a limiter
+// share its slots, so one limiter can bound a single tool, a whole server,
+// or several servers that use the same backend.
+//
+// It is safe for concurrent use.
+type ConcurrencyLimiter struct {
+	limit ConcurrencyLimit
+	slots chan struct{}
+
+	mu    sync.Mutex
+	stats ConcurrencyStats
+}
+
+// NewConcurrencyLimiter creates a limiter enforcing limit.
+func NewConcurrencyLimiter(limit ConcurrencyLimit) (*ConcurrencyLimiter, error) {
+	if err := limit.Validate(); err != nil {
+		return nil, err
+	}
+	return &ConcurrencyLimiter{
+		limit: limit,
+		slots: make(chan struct{}, limit.MaxConcurrent),
+		stats: ConcurrencyStats{MaxConcurrent: limit.MaxConcurrent},
+	}, nil
+}
+
+// Acquire waits for a slot and returns the function that releases it. It
+// returns ErrToolBusy when the call is rejected or times out waiting, and
+// ctx's error when ctx ends first.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context) (release func(), err error) {
+	select {
+	case l.slots <- struct{}{}:
+		return l.started(), nil
+	default:
+	}
+
+	l.mu.Lock()
+	if l.limit.MaxQueued < 0 || (l.limit.MaxQueued > 0 && l.stats.Queued >= l.limit.MaxQueued) {
+		l.stats.Rejected++
+		l.mu.Unlock()
+		return nil, ErrToolBusy
+	}
+	l.stats.Queued++
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.stats.Queued--
+		l.mu.Unlock()
+	}()
+
+	var timeout <-chan time.Time
+	if l.limit.QueueTimeout > 0 {
+		timer := time.NewTimer(l.limit.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return l.started(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timeout:
+		l.mu.Lock()
+		l.stats.TimedOut++
+		l.mu.Unlock()
+		return nil, ErrToolBusy
+	}
+}
+
+// started counts a call that got a slot and returns its release function.
+func (l *ConcurrencyLimiter) started() func() {
+	l.mu.Lock()
+	l.stats.Running++
+	l.stats.Started++
+	l.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.stats.Running--
+			l.mu.Unlock()
+			<-l.slots
+		})
+	}
+}
+
+// Stats returns the limiter's counters.
+func (l *ConcurrencyLimiter) Stats() ConcurrencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// Middleware returns middleware that runs every call it wraps under the
+// limiter. A call that gets no slot does not run the handler; Claude
+// receives an error result asking it to retry later.
+func (l *ConcurrencyLimiter) Middleware() ToolMiddleware {
+	return func(next ToolFunc) ToolFunc {
+		return func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			release, err := l.Acquire(ctx)
+			if errors.Is(err, ErrToolBusy) {
+				call, _ := ToolCallFromContext(ctx)
+				return l.busyResult(call.Tool), nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+			return next(ctx, input)
+		}
+	}
+}
+
+// busyResult is the result returned to Claude when a call gets no slot.
+func (l *ConcurrencyLimiter) busyResult(toolName string) *ToolResult {
+	if toolName == "" {
+		toolName = "this tool"
+	}
+	return NewErrorMcpToolResult(fmt.Sprintf(
+		"Tool %s is busy: at most %d calls can run at once. Wait for running calls to finish before calling it again, or continue without it.",
+		toolName, l.limit.MaxConcurrent))
+}
+
+// LimitConcurrency returns middleware that bounds the calls it wraps with a
+// single limiter, for use with ToolServerConfig.Use or ToolManager.Use to
+// limit a whole server. A limit that fails Validate leaves calls unchanged.
+//
+// Example:
+//
+//	server.Use(types.LimitConcurrency(types.ConcurrencyLimit{
+//	    MaxConcurrent: 4,
+//	    QueueTimeout:  10 * time.Second,
+//	}))
+func LimitConcurrency(limit ConcurrencyLimit) ToolMiddleware {
+	limiter, err := NewConcurrencyLimiter(limit)
+	if err != nil {
+		return func(next ToolFunc) ToolFunc { return next }
+	}
+	return limiter.Middleware()
+}
+
+// MaxConcurrency bounds how many calls of the tool run at once. Calls past
+// the limit wait for a slot as configured by limit; a call that gets none
+// returns an error ToolResult asking Claude to retry later.
+//
+// Example:
+//
+//	types.NewTool("query_warehouse").
+//	    MaxConcurrency(types.ConcurrencyLimit{MaxConcurrent: 2, MaxQueued: 8})
+func (b *ToolBuilder) MaxConcurrency(limit ConcurrencyLimit) *ToolBuilder {
+	b.concurrency = &limit
+	return b
+}
@@ -0,0 +1,181 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrencyLimiter tests slot accounting, queue limits and timeouts.
+func TestConcurrencyLimiter(t *testing.T) {
+	if _, err := NewConcurrencyLimiter(ConcurrencyLimit{}); err == nil {
+		t.Error("expected error for a zero limit")
+	}
+	if _, err := NewConcurrencyLimiter(ConcurrencyLimit{MaxConcurrent: 1, QueueTimeout: -time.Second}); err == nil {
+		t.Error("expected error for a negative queue timeout")
+	}
+
+	limiter, err := NewConcurrencyLimiter(ConcurrencyLimit{MaxConcurrent: 1, MaxQueued: 1, QueueTimeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewConcurrencyLimiter failed: %v", err)
+	}
+	ctx := context.Background()
+	release, err := limiter.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	// One call may wait; it times out while the slot is held.
+	waited := make(chan error, 1)
+	go func() {
+		_, err := limiter.Acquire(ctx)
+		waited <- err
+	}()
+	for limiter.Stats().Queued == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := limiter.Acquire(ctx); !errors.Is(err, ErrToolBusy) {
+		t.Errorf("expected a full queue to reject the call, got %v", err)
+	}
+	if err := <-waited; !errors.Is(err, ErrToolBusy) {
+		t.Errorf("expected the queued call to time out, got %v", err)
+	}
+
+	release()
+	release() // Releasing twice frees the slot once
+	release, err = limiter.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire after release failed: %v", err)
+	}
+	release()
+
+	stats := limiter.Stats()
+	if stats.Running != 0 || stats.Queued != 0 || stats.Started != 2 || stats.Rejected != 1 || stats.TimedOut != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+// TestConcurrencyLimiterContext tests that a queued call ends with its context.
+func TestConcurrencyLimiterContext(t *testing.T) {
+	limiter, _ := NewConcurrencyLimiter(ConcurrencyLimit{MaxConcurrent: 1})
+	release, _ := limiter.Acquire(context.Background())
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context error, got %v", err)
+	}
+}
+
+// TestToolBuilderMaxConcurrency tests that a tool never runs more calls at
+// once than its limit and rejects calls it cannot queue.
+func TestToolBuilderMaxConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	unblock := make(chan struct{})
+	tool, err := NewTool("slow").
+		Description("Slow tool").
+		MaxConcurrency(ConcurrencyLimit{MaxConcurrent: 2, MaxQueued: -1}).
+		Handler(func(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+			<-unblock
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return NewMcpToolResult(TextBlock{Type: "text", Text: "done"}), nil
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan *ToolResult, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, _ := tool.Execute(context.Background(), map[string]interface{}{})
+			results <- result
+		}()
+	}
+	for {
+		mu.Lock()
+		n := running
+		mu.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	busy, err := tool.Execute(context.Background(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !busy.IsError || !strings.Contains(busy.Content[0].(TextBlock).Text, "Tool slow is busy") {
+		t.Errorf("expected a busy error result, got %+v", busy)
+	}
+
+	close(unblock)
+	wg.Wait()
+	close(results)
+	for result := range results {
+		if result.IsError {
+			t.Errorf("expected running calls to succeed, got %+v", result)
+		}
+	}
+	if peak != 2 {
+		t.Errorf("expected at most 2 concurrent calls, got %d", peak)
+	}
+
+	if _, err := NewTool("bad").Description("Bad").MaxConcurrency(ConcurrencyLimit{}).Handler(func(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+		return nil, nil
+	}).Build(); err == nil {
+		t.Error("expected Build to reject an invalid concurrency limit")
+	}
+}
+
+// TestLimitConcurrencyMiddleware tests that the middleware shares one
+// limiter across every call it wraps.
+func TestLimitConcurrencyMiddleware(t *testing.T) {
+	middleware := LimitConcurrency(ConcurrencyLimit{MaxConcurrent: 1, MaxQueued: -1})
+	unblock := make(chan struct{})
+	started := make(chan struct{})
+	slow := middleware(func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+		close(started)
+		<-unblock
+		return NewMcpToolResult(), nil
+	})
+	fast := middleware(func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+		return NewMcpToolResult(), nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = slow(context.Background(), nil)
+	}()
+	<-started
+
+	ctx := ContextWithToolCall(context.Background(), ToolCallInfo{Server: "db", Tool: "query"})
+	result, err := fast(ctx, nil)
+	if err != nil || !result.IsError || !strings.Contains(result.Content[0].(TextBlock).Text, "Tool query is busy") {
+		t.Errorf("expected a busy result for another tool of the server, got %+v, %v", result, err)
+	}
+	close(unblock)
+	<-done
+
+	if result, _ := fast(ctx, nil); result.IsError {
+		t.Errorf("expected the call to run once the slot is free, got %+v", result)
+	}
+}
@@ -0,0 +1,152 @@
+package types
+
+import (
+	"encoding/base64"
+	"net/http"
+)
+
+// ImageBlock is an image. It has two forms: an image returned by a tool,
+// such as a screenshot or chart, sets Data and MimeType as in MCP; an image
+// in a conversation message sets Source as in the Anthropic API.
+type ImageBlock struct {
+	Type     string       `json:"type"`               // Always "image"
+	Source   *ImageSource `json:"source,omitempty"`   // Image of a message
+	Data     string       `json:"data,omitempty"`     // Base64-encoded image data of a tool result
+	MimeType string       `json:"mimeType,omitempty"` // Such as "image/png"
+}
+
+// Image source types of an ImageSource.
+const (
+	ImageSourceBase64 = "base64"
+	ImageSourceURL    = "url"
+)
+
+// ImageSource holds the image of a message: either base64-encoded data with
+// its media type, or a URL the API fetches.
+type ImageSource struct {
+	Type      string `json:"type"`                 // ImageSourceBase64 or ImageSourceURL
+	MediaType string `json:"media_type,omitempty"` // Such as "image/png"; set for base64 images
+	Data      string `json:"data,omitempty"`       // Base64-encoded image data
+	URL       string `json:"url,omitempty"`
+}
+
+// NewImageBlock returns an image block for raw image data. When mimeType is
+// empty it is detected from the data.
+//
+// Example:
+//
+//	png, err := chart.Render()
+//	...
+//	return types.NewMcpToolResult(types.NewImageBlock(png, "image/png")), nil
+func NewImageBlock(data []byte, mimeType string) ImageBlock {
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return ImageBlock{
+		Type:     "image",
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	}
+}
+
+// NewBase64ImageBlock returns an image block for a message, holding raw
+// image data. When mediaType is empty it is detected from the data.
+//
+// Example:
+//
+//	err := client.QueryWithContent(ctx, []types.ContentBlock{
+//	    types.TextBlock{Type: "text", Text: "What's in this screenshot?"},
+//	    types.NewBase64ImageBlock(png, "image/png"),
+//	})
+func NewBase64ImageBlock(data []byte, mediaType string) ImageBlock {
+	if mediaType == "" {
+		mediaType = http.DetectContentType(data)
+	}
+	return ImageBlock{
+		Type: "image",
+		Source: &ImageSource{
+			Type:      ImageSourceBase64,
+			MediaType: mediaType,
+			Data:      base64.StdEncoding.EncodeToString(data),
+		},
+	}
+}
+
+// NewURLImageBlock returns an image block for a message, referring to the
+// image at url.
+func NewURLImageBlock(url string) ImageBlock {
+	return ImageBlock{
+		Type:   "image",
+		Source: &ImageSource{Type: ImageSourceURL, URL: url},
+	}
+}
+
+// GetType returns the type of the content block.
+func (b ImageBlock) GetType() string {
+	return b.Type
+}
+
+func (b ImageBlock) isContentBlock() {}
+
+// ResourceLinkBlock points Claude to a resource it can read, such as a file
+// a tool created, without embedding its contents.
+type ResourceLinkBlock struct {
+	Type        string `json:"type"` // Always "resource_link"
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// NewResourceLinkBlock returns a link to the resource at uri.
+func NewResourceLinkBlock(uri, name, mimeType string) ResourceLinkBlock {
+	return ResourceLinkBlock{
+		Type:     "resource_link",
+		URI:      uri,
+		Name:     name,
+		MimeType: mimeType,
+	}
+}
+
+// GetType returns the type of the content block.
+func (b ResourceLinkBlock) GetType() string {
+	return b.Type
+}
+
+func (b ResourceLinkBlock) isContentBlock() {}
+
+// EmbeddedResource is the contents of a resource embedded in a tool result.
+// Exactly one of Text and Blob is set.
+type EmbeddedResource struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"` // Base64-encoded binary contents
+}
+
+// EmbeddedResourceBlock embeds a resource's contents in a tool result, such
+// as a generated PDF or CSV file.
+type EmbeddedResourceBlock struct {
+	Type     string           `json:"type"` // Always "resource"
+	Resource EmbeddedResource `json:"resource"`
+}
+
+// NewEmbeddedResourceBlock returns a block embedding data as the contents
+// of the resource at uri. Text MIME types, as reported by McpResource.IsText,
+// are embedded as text and anything else as a base64 blob.
+func NewEmbeddedResourceBlock(uri, mimeType string, data []byte) EmbeddedResourceBlock {
+	resource := EmbeddedResource{URI: uri, MimeType: mimeType}
+	if (McpResource{MimeType: mimeType}).IsText() {
+		resource.Text = string(data)
+	} else {
+		resource.Blob = base64.StdEncoding.EncodeToString(data)
+	}
+	return EmbeddedResourceBlock{Type: "resource", Resource: resource}
+}
+
+// GetType returns the type of the content block.
+func (b EmbeddedResourceBlock) GetType() string {
+	return b.Type
+}
+
+func (b EmbeddedResourceBlock) isContentBlock() {}
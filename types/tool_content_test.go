@@ -0,0 +1,88 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+// TestToolContentBlocks tests the MCP JSON encoding of image and resource blocks.
+func TestToolContentBlocks(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	tests := []struct {
+		name  string
+		block ContentBlock
+		want  string
+	}{
+		{
+			name:  "image with detected type",
+			block: NewImageBlock(png, ""),
+			want:  `{"type":"image","data":"` + base64.StdEncoding.EncodeToString(png) + `","mimeType":"image/png"}`,
+		},
+		{
+			name:  "resource link",
+			block: NewResourceLinkBlock("file:///tmp/report.pdf", "report.pdf", "application/pdf"),
+			want:  `{"type":"resource_link","uri":"file:///tmp/report.pdf","name":"report.pdf","mimeType":"application/pdf"}`,
+		},
+		{
+			name:  "text resource",
+			block: NewEmbeddedResourceBlock("file:///tmp/data.csv", "text/csv", []byte("a,b")),
+			want:  `{"type":"resource","resource":{"uri":"file:///tmp/data.csv","mimeType":"text/csv","text":"a,b"}}`,
+		},
+		{
+			name:  "binary resource",
+			block: NewEmbeddedResourceBlock("file:///tmp/data.bin", "application/octet-stream", []byte{0, 1, 2}),
+			want:  `{"type":"resource","resource":{"uri":"file:///tmp/data.bin","mimeType":"application/octet-stream","blob":"AAEC"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(NewMcpToolResult(tt.block))
+			if err != nil {
+				t.Fatalf("Marshal() error: %v", err)
+			}
+			if want := `{"content":[` + tt.want + `]}`; string(data) != want {
+				t.Errorf("got %s, want %s", data, want)
+			}
+		})
+	}
+}
+
+// TestMessageImageBlocks tests the API JSON encoding of message images.
+func TestMessageImageBlocks(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	tests := []struct {
+		name  string
+		block ImageBlock
+		want  string
+	}{
+		{
+			name:  "base64 with detected type",
+			block: NewBase64ImageBlock(png, ""),
+			want:  `{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + base64.StdEncoding.EncodeToString(png) + `"}}`,
+		},
+		{
+			name:  "url",
+			block: NewURLImageBlock("https://example.com/chart.png"),
+			want:  `{"type":"image","source":{"type":"url","url":"https://example.com/chart.png"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.block)
+			if err != nil {
+				t.Fatalf("Marshal() error: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got %s, want %s", data, tt.want)
+			}
+			block, err := UnmarshalContentBlock(data)
+			if err != nil {
+				t.Fatalf("UnmarshalContentBlock() error: %v", err)
+			}
+			if image, ok := block.(*ImageBlock); !ok || *image.Source != *tt.block.Source {
+				t.Errorf("round trip got %+v", block)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"time"
 )
 
 // SimpleTool provides a decorator-style API for defining tools,
@@ -32,6 +33,7 @@ type SimpleTool struct {
 	Description string
 	Parameters  map[string]SimpleParam
 	Handler     ToolFunc
+	Timeout     time.Duration // Limits each call when positive
 }
 
 // SimpleParam represents a simplified parameter definition.
@@ -84,6 +86,7 @@ func (s *SimpleTool) Build() (McpTool, error) {
 		description: s.Description,
 		inputSchema: schema,
 		handler:     s.Handler,
+		timeout:     s.Timeout,
 	}, nil
 }
 
@@ -146,6 +149,7 @@ type ToolDecorator struct {
 	description string
 	params      map[string]SimpleParam
 	handler     ToolFunc
+	timeout     time.Duration
 }
 
 // Tool creates a new tool decorator with the given name and description.
@@ -203,6 +207,12 @@ func (d *ToolDecorator) ObjectParam(name, description string, required bool, pro
 	return d
 }
 
+// Timeout limits each call of the tool to d.
+func (d *ToolDecorator) Timeout(timeout time.Duration) *ToolDecorator {
+	d.timeout = timeout
+	return d
+}
+
 // Handle sets the handler function and builds the tool.
 func (d *ToolDecorator) Handle(handler ToolFunc) (McpTool, error) {
 	d.handler = handler
@@ -212,6 +222,7 @@ func (d *ToolDecorator) Handle(handler ToolFunc) (McpTool, error) {
 		Description: d.description,
 		Parameters:  d.params,
 		Handler:     handler,
+		Timeout:     d.timeout,
 	}
 
 	return simpleTool.Build()
@@ -0,0 +1,99 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/M1n9X/claude-agent-sdk-go/schema"
+)
+
+// ToolFromFunc builds a tool from a typed Go function.
+//
+// Args must be a struct. Its exported fields become the tool parameters:
+// the json tag names a parameter, the desc tag describes it, the enum tag
+// lists its allowed values, and fields tagged omitempty or declared as
+// pointers are optional. See package schema for the full mapping. The input
+// is decoded into Args before fn runs.
+//
+// The result is converted to a ToolResult: a *ToolResult is returned as is,
+// a string or ContentBlock becomes the content, and any other value is
+// encoded as indented JSON text.
+//
+// Example:
+//
+//	type WeatherArgs struct {
+//	    City  string `json:"city" desc:"City name"`
+//	    Units string `json:"units,omitempty" desc:"celsius or fahrenheit"`
+//	}
+//
+//	weather, err := types.ToolFromFunc("get_weather", "Get the current weather",
+//	    func(ctx context.Context, args WeatherArgs) (string, error) {
+//	        return fmt.Sprintf("Sunny in %s", args.City), nil
+//	    })
+func ToolFromFunc[Args any, Out any](name, description string, fn func(ctx context.Context, args Args) (Out, error)) (McpTool, error) {
+	if fn == nil {
+		return nil, fmt.Errorf("tool handler is required")
+	}
+	argsType := reflect.TypeOf((*Args)(nil)).Elem()
+	if argsType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("tool %s: arguments must be a struct, got %s", name, argsType)
+	}
+
+	return NewTool(name).
+		Description(description).
+		Handler(func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			var args Args
+			if err := decodeToolInput(input, &args); err != nil {
+				return nil, fmt.Errorf("decode arguments for %s: %w", name, err)
+			}
+			out, err := fn(ctx, args)
+			if err != nil {
+				return nil, err
+			}
+			return toToolResult(out)
+		}).
+		withSchema(schema.FromType(argsType)).
+		Build()
+}
+
+// withSchema sets an input schema that replaces the one built from parameters.
+func (b *ToolBuilder) withSchema(inputSchema map[string]interface{}) *ToolBuilder {
+	b.schema = inputSchema
+	return b
+}
+
+// decodeToolInput decodes a tool input into a struct through JSON.
+func decodeToolInput(input map[string]interface{}, target interface{}) error {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// toToolResult converts a tool function's return value to a ToolResult.
+func toToolResult(out interface{}) (*ToolResult, error) {
+	switch v := out.(type) {
+	case *ToolResult:
+		if v == nil {
+			return NewMcpToolResult(), nil
+		}
+		return v, nil
+	case ToolResult:
+		return &v, nil
+	case string:
+		return NewMcpToolResult(TextBlock{Type: "text", Text: v}), nil
+	case ContentBlock:
+		return NewMcpToolResult(v), nil
+	case []ContentBlock:
+		return NewMcpToolResult(v...), nil
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode tool result: %w", err)
+	}
+	return NewMcpToolResult(TextBlock{Type: "text", Text: string(data)}), nil
+}
@@ -0,0 +1,120 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type weatherArgs struct {
+	City    string          `json:"city" desc:"City name"`
+	Days    int             `json:"days,omitempty" desc:"Forecast days"`
+	Units   *string         `json:"units" desc:"celsius or fahrenheit"`
+	Tags    []string        `json:"tags,omitempty"`
+	Extra   map[string]int  `json:"extra,omitempty"`
+	Where   weatherLocation `json:"where"`
+	Ignored string          `json:"-"`
+}
+
+type weatherLocation struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type weatherReport struct {
+	City string  `json:"city"`
+	Temp float64 `json:"temp"`
+}
+
+// TestToolFromFuncSchema tests the input schema generated from the argument struct.
+func TestToolFromFuncSchema(t *testing.T) {
+	tool, err := ToolFromFunc("weather", "Get the weather", func(ctx context.Context, args weatherArgs) (string, error) {
+		return "", nil
+	})
+	if err != nil {
+		t.Fatalf("ToolFromFunc failed: %v", err)
+	}
+
+	schema := tool.InputSchema()
+	if required := schema["required"].([]string); !reflect.DeepEqual(required, []string{"city", "where"}) {
+		t.Errorf("unexpected required fields: %v", required)
+	}
+	properties := schema["properties"].(map[string]interface{})
+	if len(properties) != 6 {
+		t.Errorf("expected 6 properties, got %v", properties)
+	}
+	city := properties["city"].(map[string]interface{})
+	if city["type"] != "string" || city["description"] != "City name" {
+		t.Errorf("unexpected city schema: %v", city)
+	}
+	if days := properties["days"].(map[string]interface{}); days["type"] != "integer" {
+		t.Errorf("unexpected days schema: %v", days)
+	}
+	tags := properties["tags"].(map[string]interface{})
+	if items := tags["items"].(map[string]interface{}); tags["type"] != "array" || items["type"] != "string" {
+		t.Errorf("unexpected tags schema: %v", tags)
+	}
+	where := properties["where"].(map[string]interface{})
+	if where["type"] != "object" || len(where["properties"].(map[string]interface{})) != 2 {
+		t.Errorf("unexpected nested schema: %v", where)
+	}
+}
+
+// TestToolFromFuncExecute tests decoding arguments and converting results.
+func TestToolFromFuncExecute(t *testing.T) {
+	tool, err := ToolFromFunc("weather", "Get the weather", func(ctx context.Context, args weatherArgs) (weatherReport, error) {
+		if args.City == "" {
+			return weatherReport{}, errors.New("city is required")
+		}
+		temp := float64(args.Days) + args.Where.Lat
+		return weatherReport{City: args.City, Temp: temp}, nil
+	})
+	if err != nil {
+		t.Fatalf("ToolFromFunc failed: %v", err)
+	}
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{
+		"city":  "Paris",
+		"days":  float64(3),
+		"where": map[string]interface{}{"lat": 48.5, "lon": 2.3},
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	text := result.Content[0].(TextBlock).Text
+	if !strings.Contains(text, `"city": "Paris"`) || !strings.Contains(text, `"temp": 51.5`) {
+		t.Errorf("unexpected result: %s", text)
+	}
+
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"city": "", "where": map[string]interface{}{"lat": 0.0, "lon": 0.0}}); err == nil {
+		t.Error("expected handler error to be returned")
+	}
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"city": "Paris"}); err == nil {
+		t.Error("expected missing required field to fail validation")
+	}
+}
+
+// TestToolFromFuncResults tests the supported result types and invalid arguments.
+func TestToolFromFuncResults(t *testing.T) {
+	type noArgs struct{}
+
+	stringTool, _ := ToolFromFunc("s", "string", func(ctx context.Context, args noArgs) (string, error) { return "hi", nil })
+	result, _ := stringTool.Execute(context.Background(), map[string]interface{}{})
+	if result.Content[0].(TextBlock).Text != "hi" {
+		t.Errorf("unexpected string result: %+v", result)
+	}
+
+	resultTool, _ := ToolFromFunc("r", "result", func(ctx context.Context, args noArgs) (*ToolResult, error) {
+		return NewErrorMcpToolResult("failed"), nil
+	})
+	result, _ = resultTool.Execute(context.Background(), map[string]interface{}{})
+	if !result.IsError {
+		t.Errorf("expected ToolResult to be returned as is, got %+v", result)
+	}
+
+	if _, err := ToolFromFunc("bad", "not a struct", func(ctx context.Context, args string) (string, error) { return "", nil }); err == nil {
+		t.Error("expected non-struct arguments to fail")
+	}
+}
@@ -0,0 +1,101 @@
+package types
+
+import "context"
+
+// ToolMiddleware wraps a tool handler to add behavior around every call,
+// such as logging, authorization or metrics.
+//
+// Example:
+//
+//	logging := func(next types.ToolFunc) types.ToolFunc {
+//	    return func(ctx context.Context, input map[string]interface{}) (*types.ToolResult, error) {
+//	        call, _ := types.ToolCallFromContext(ctx)
+//	        start := time.Now()
+//	        result, err := next(ctx, input)
+//	        log.Printf("%s took %v", call.Tool, time.Since(start))
+//	        return result, err
+//	    }
+//	}
+//
+//	manager.Use(logging)
+type ToolMiddleware func(next ToolFunc) ToolFunc
+
+// ChainToolMiddleware combines middleware into one. The first middleware is
+// the outermost: it runs first on the way in and last on the way out.
+func ChainToolMiddleware(middleware ...ToolMiddleware) ToolMiddleware {
+	return func(next ToolFunc) ToolFunc {
+		for i := len(middleware) - 1; i >= 0; i-- {
+			if middleware[i] != nil {
+				next = middleware[i](next)
+			}
+		}
+		return next
+	}
+}
+
+// ToolCallInfo identifies the tool call a handler or middleware is running for.
+type ToolCallInfo struct {
+	Server string // SDK MCP server name
+	Tool   string // Tool name
+}
+
+// toolCallKey is the context key of the ToolCallInfo of a tool call.
+type toolCallKey struct{}
+
+// ContextWithToolCall returns a context that carries info. SDK MCP servers
+// set it before running the middleware and the tool.
+func ContextWithToolCall(ctx context.Context, info ToolCallInfo) context.Context {
+	return context.WithValue(ctx, toolCallKey{}, info)
+}
+
+// ToolCallFromContext returns the tool call a context was created for.
+func ToolCallFromContext(ctx context.Context) (ToolCallInfo, bool) {
+	info, ok := ctx.Value(toolCallKey{}).(ToolCallInfo)
+	return info, ok
+}
+
+// WrapTool returns a tool that runs its Execute through middleware. The
+// name, description and input schema are unchanged.
+func WrapTool(tool McpTool, middleware ...ToolMiddleware) McpTool {
+	if len(middleware) == 0 {
+		return tool
+	}
+	return &wrappedTool{
+		McpTool: tool,
+		execute: ChainToolMiddleware(middleware...)(tool.Execute),
+	}
+}
+
+// wrappedTool is an McpTool whose Execute runs through middleware.
+type wrappedTool struct {
+	McpTool
+	execute ToolFunc
+}
+
+func (t *wrappedTool) Execute(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+	return t.execute(ctx, input)
+}
+
+// toolMiddlewareUser is an MCP server that accepts tool middleware.
+type toolMiddlewareUser interface {
+	Use(middleware ...ToolMiddleware)
+}
+
+// Use adds middleware that wraps every tool call of the server. Middleware
+// added before the session starts applies from the first call; after that
+// it is added to the running server.
+func (c *ToolServerConfig) Use(middleware ...ToolMiddleware) {
+	if server, ok := c.Instance.(toolMiddlewareUser); ok {
+		server.Use(middleware...)
+		return
+	}
+	c.Middleware = append(c.Middleware, middleware...)
+}
+
+// Use adds middleware that wraps every tool of the servers created by
+// CreateServer.
+func (m *ToolManager) Use(middleware ...ToolMiddleware) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.middleware = append(m.middleware, middleware...)
+}
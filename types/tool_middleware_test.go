@@ -0,0 +1,97 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// recordingMiddleware returns middleware that appends its name to calls before and after next.
+func recordingMiddleware(name string, calls *[]string) ToolMiddleware {
+	return func(next ToolFunc) ToolFunc {
+		return func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			*calls = append(*calls, name+" in")
+			result, err := next(ctx, input)
+			*calls = append(*calls, name+" out")
+			return result, err
+		}
+	}
+}
+
+// TestChainToolMiddleware tests that the first middleware is the outermost.
+func TestChainToolMiddleware(t *testing.T) {
+	var calls []string
+	handler := func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+		calls = append(calls, "handler")
+		return NewMcpToolResult(), nil
+	}
+
+	chained := ChainToolMiddleware(recordingMiddleware("a", &calls), nil, recordingMiddleware("b", &calls))(handler)
+	if _, err := chained(context.Background(), nil); err != nil {
+		t.Fatalf("chained handler failed: %v", err)
+	}
+
+	want := []string{"a in", "b in", "handler", "b out", "a out"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected calls %v, got %v", want, calls)
+	}
+}
+
+// TestWrapTool tests wrapping a tool with middleware that can reject calls.
+func TestWrapTool(t *testing.T) {
+	tool, err := NewTool("greet").
+		Description("Greet a user").
+		StringParam("name", "User's name", true).
+		Handler(func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			return NewMcpToolResult(TextBlock{Type: "text", Text: "hello"}), nil
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build tool: %v", err)
+	}
+	if WrapTool(tool) != tool {
+		t.Error("expected a tool without middleware to be returned as is")
+	}
+
+	errUnauthorized := errors.New("unauthorized")
+	auth := func(next ToolFunc) ToolFunc {
+		return func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			if input["name"] == "mallory" {
+				return nil, errUnauthorized
+			}
+			return next(ctx, input)
+		}
+	}
+	wrapped := WrapTool(tool, auth)
+	if wrapped.Name() != "greet" || wrapped.Description() != "Greet a user" || wrapped.InputSchema() == nil {
+		t.Errorf("expected the tool definition to be unchanged")
+	}
+	if _, err := wrapped.Execute(context.Background(), map[string]interface{}{"name": "mallory"}); !errors.Is(err, errUnauthorized) {
+		t.Errorf("expected the middleware to reject the call, got %v", err)
+	}
+	if result, err := wrapped.Execute(context.Background(), map[string]interface{}{"name": "alice"}); err != nil || len(result.Content) != 1 {
+		t.Errorf("expected the call to reach the tool, got %v, %v", result, err)
+	}
+}
+
+// TestToolMiddlewareRegistration tests adding middleware to managers and server configurations.
+func TestToolMiddlewareRegistration(t *testing.T) {
+	var calls []string
+	manager := NewToolManager()
+	manager.Use(recordingMiddleware("manager", &calls))
+
+	server := manager.CreateServer("tools", "1.0.0")
+	server.Use(recordingMiddleware("server", &calls))
+	if len(server.Middleware) != 2 {
+		t.Errorf("expected 2 middleware, got %d", len(server.Middleware))
+	}
+
+	ctx := ContextWithToolCall(context.Background(), ToolCallInfo{Server: "tools", Tool: "greet"})
+	if info, ok := ToolCallFromContext(ctx); !ok || info.Tool != "greet" || info.Server != "tools" {
+		t.Errorf("unexpected tool call info: %+v", info)
+	}
+	if _, ok := ToolCallFromContext(context.Background()); ok {
+		t.Error("expected no tool call info outside a call")
+	}
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// ToolPanic describes a panic in an SDK MCP tool handler. Claude receives
+// only a generic error result; the panic value and stack stay on the Go side.
+type ToolPanic struct {
+	Time   time.Time   `json:"time"`
+	Server string      `json:"server"`
+	Tool   string      `json:"tool"`
+	Value  interface{} `json:"value"`
+	Stack  []byte      `json:"stack"`
+}
+
+// ToolPanicFunc receives panics recovered from SDK MCP tool handlers.
+type ToolPanicFunc func(p ToolPanic)
+
+// ToolPanicError is the error of a tool call whose handler panicked.
+type ToolPanicError struct {
+	Tool  string
+	Value interface{}
+	Stack []byte
+}
+
+// NewToolPanicError records a recovered panic value with the current stack.
+// Call it from the deferred function that recovered the panic, so the stack
+// includes the panicking code.
+func NewToolPanicError(tool string, value interface{}) *ToolPanicError {
+	return &ToolPanicError{Tool: tool, Value: value, Stack: debug.Stack()}
+}
+
+func (e *ToolPanicError) Error() string {
+	return fmt.Sprintf("tool %s panicked: %v", e.Tool, e.Value)
+}
+
+// ToolPanicResult is the result Claude receives for a call whose handler panicked.
+func ToolPanicResult(tool string) *ToolResult {
+	return NewErrorMcpToolResult(fmt.Sprintf("Tool %s failed with an internal error.", tool))
+}
@@ -0,0 +1,307 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ToolPattern is a parsed tool rule such as "Bash", "mcp__calc__*" or "Bash(git *)".
+//
+// Tool is a glob over the tool name, where * matches any sequence of characters.
+// Specifier, when present, is a glob over the tool's primary argument: the
+// command for Bash, the file path for file tools, and the URL for WebFetch.
+// A specifier ending in ":*" is a prefix match, as in "Bash(git:*)".
+type ToolPattern struct {
+	Tool      string
+	Specifier *string
+
+	// Compiled forms of Tool and Specifier, set by ParseToolPattern
+	toolRe      *regexp.Regexp
+	specifierRe *regexp.Regexp
+}
+
+// toolNamePattern matches the characters allowed in the tool part of a rule.
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z0-9_\-*]+$`)
+
+// toolSpecifierKeys lists the input parameters matched by a specifier, per tool.
+var toolSpecifierKeys = map[string][]string{
+	"Bash":         {"command"},
+	"Read":         {"file_path"},
+	"Write":        {"file_path"},
+	"Edit":         {"file_path"},
+	"MultiEdit":    {"file_path"},
+	"NotebookEdit": {"notebook_path"},
+	"Glob":         {"pattern", "path"},
+	"Grep":         {"pattern", "path"},
+	"WebFetch":     {"url"},
+	"WebSearch":    {"query"},
+}
+
+// ParseToolPattern parses a single tool rule.
+func ParseToolPattern(rule string) (*ToolPattern, error) {
+	rule = strings.TrimSpace(rule)
+	if rule == "" {
+		return nil, fmt.Errorf("tool pattern cannot be empty")
+	}
+
+	tool := rule
+	var specifier *string
+	if open := strings.Index(rule, "("); open >= 0 {
+		if !strings.HasSuffix(rule, ")") {
+			return nil, fmt.Errorf("tool pattern %q: missing closing parenthesis", rule)
+		}
+		tool = rule[:open]
+		spec := rule[open+1 : len(rule)-1]
+		if spec == "" {
+			return nil, fmt.Errorf("tool pattern %q: empty specifier", rule)
+		}
+		specifier = &spec
+	} else if strings.Contains(rule, ")") {
+		return nil, fmt.Errorf("tool pattern %q: unexpected closing parenthesis", rule)
+	}
+
+	if !toolNamePattern.MatchString(tool) {
+		return nil, fmt.Errorf("tool pattern %q: invalid tool name %q", rule, tool)
+	}
+
+	pattern := &ToolPattern{Tool: tool, Specifier: specifier}
+	pattern.toolRe, pattern.specifierRe = pattern.compile()
+	return pattern, nil
+}
+
+// compile returns the regular expressions matching the tool name and the
+// specifier, which is nil without a specifier.
+func (p *ToolPattern) compile() (tool, specifier *regexp.Regexp) {
+	tool = globToRegexp(p.Tool)
+	if p.Specifier == nil {
+		return tool, nil
+	}
+	if prefix, ok := strings.CutSuffix(*p.Specifier, ":*"); ok {
+		return tool, regexp.MustCompile(`^(?s)` + regexp.QuoteMeta(prefix))
+	}
+	return tool, globToRegexp(*p.Specifier)
+}
+
+// regexps returns the compiled regular expressions of the pattern, compiling
+// them for patterns not created by ParseToolPattern.
+func (p *ToolPattern) regexps() (tool, specifier *regexp.Regexp) {
+	if p.toolRe != nil {
+		return p.toolRe, p.specifierRe
+	}
+	return p.compile()
+}
+
+// toolRuleCache holds the patterns of the tool rules matched so far, by rule.
+var toolRuleCache sync.Map
+
+// matchToolRule reports whether a tool use matches a tool rule, parsing each
+// distinct rule once. A rule without pattern syntax that ParseToolPattern
+// rejects matches the tool of that exact name, as in the CLI; other
+// malformed rules never match.
+func matchToolRule(rule, toolName string, input map[string]interface{}) bool {
+	if cached, ok := toolRuleCache.Load(rule); ok {
+		pattern, _ := cached.(*ToolPattern)
+		return pattern != nil && pattern.Matches(toolName, input)
+	}
+
+	pattern, err := ParseToolPattern(rule)
+	if err != nil && isLiteralToolRule(rule) {
+		pattern = &ToolPattern{Tool: rule, toolRe: regexp.MustCompile(`^` + regexp.QuoteMeta(rule) + `$`)}
+	}
+	toolRuleCache.Store(rule, pattern)
+	return pattern != nil && pattern.Matches(toolName, input)
+}
+
+// isLiteralToolRule reports whether a rule is a plain tool name, without the
+// globs or specifiers of the ToolPattern syntax.
+func isLiteralToolRule(rule string) bool {
+	return !strings.ContainsAny(rule, "*()")
+}
+
+// String returns the rule in its textual form.
+func (p *ToolPattern) String() string {
+	if p.Specifier == nil {
+		return p.Tool
+	}
+	return p.Tool + "(" + *p.Specifier + ")"
+}
+
+// MatchesTool reports whether the tool name matches the pattern, ignoring the specifier.
+func (p *ToolPattern) MatchesTool(toolName string) bool {
+	tool, _ := p.regexps()
+	return tool.MatchString(toolName)
+}
+
+// Matches reports whether a tool use matches the pattern, including the specifier.
+func (p *ToolPattern) Matches(toolName string, input map[string]interface{}) bool {
+	tool, re := p.regexps()
+	if !tool.MatchString(toolName) {
+		return false
+	}
+	if re == nil {
+		return true
+	}
+
+	keys, known := toolSpecifierKeys[toolName]
+	for key, value := range input {
+		if known && !slices.Contains(keys, key) {
+			continue
+		}
+		if s, ok := value.(string); ok && re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWildcard reports whether the pattern needs SDK-side matching, i.e. it has
+// a glob in the tool name or a specifier.
+func (p *ToolPattern) IsWildcard() bool {
+	return p.Specifier != nil || strings.Contains(p.Tool, "*")
+}
+
+// ToolMatcher is a set of tool patterns separated by "|", as used in HookMatcher.Matcher.
+type ToolMatcher struct {
+	Patterns []*ToolPattern
+}
+
+// ParseToolMatcher parses a "|"-separated list of tool patterns.
+func ParseToolMatcher(matcher string) (*ToolMatcher, error) {
+	segments := splitToolMatcher(matcher)
+	patterns := make([]*ToolPattern, 0, len(segments))
+	for _, segment := range segments {
+		pattern, err := ParseToolPattern(segment)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return &ToolMatcher{Patterns: patterns}, nil
+}
+
+// IsToolPattern reports whether a HookMatcher.Matcher string uses tool pattern
+// syntax (globs or specifiers) rather than a plain name or regular expression.
+func IsToolPattern(matcher string) bool {
+	wildcard := false
+	for _, segment := range splitToolMatcher(matcher) {
+		pattern, err := ParseToolPattern(segment)
+		if err != nil {
+			return false
+		}
+		wildcard = wildcard || pattern.IsWildcard()
+	}
+	return wildcard
+}
+
+// Regex returns an anchored regular expression over tool names, suitable as a CLI hook matcher.
+func (m *ToolMatcher) Regex() string {
+	alternatives := make([]string, len(m.Patterns))
+	for i, pattern := range m.Patterns {
+		alternatives[i] = globToRegexSource(pattern.Tool)
+	}
+	return "^(?:" + strings.Join(alternatives, "|") + ")$"
+}
+
+// HasSpecifiers reports whether any pattern restricts the tool input.
+func (m *ToolMatcher) HasSpecifiers() bool {
+	for _, pattern := range m.Patterns {
+		if pattern.Specifier != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether a tool use matches any of the patterns.
+func (m *ToolMatcher) Matches(toolName string, input map[string]interface{}) bool {
+	for _, pattern := range m.Patterns {
+		if pattern.Matches(toolName, input) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesHookInput reports whether a tool hook input matches any of the patterns.
+// Inputs without a tool name, such as Stop events, always match.
+func (m *ToolMatcher) MatchesHookInput(input interface{}) bool {
+	parsed, err := ParseHookInput(input)
+	if err != nil {
+		return true
+	}
+	switch in := parsed.(type) {
+	case *PreToolUseHookInput:
+		return m.Matches(in.ToolName, in.ToolInput)
+	case *PostToolUseHookInput:
+		return m.Matches(in.ToolName, in.ToolInput)
+	default:
+		return true
+	}
+}
+
+// ValidateToolRules checks that every entry of an allowed or disallowed tools
+// list that uses globs or specifiers parses. Plain tool names are passed to
+// the CLI as they are and always accepted.
+func ValidateToolRules(rules []string) error {
+	for _, rule := range rules {
+		if isLiteralToolRule(rule) {
+			continue
+		}
+		if _, err := ParseToolPattern(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CLIToolRule rewrites a tool rule into the form understood by the CLI.
+// Server-wide MCP globs such as "mcp__calc__*" become "mcp__calc"; other rules
+// are returned unchanged.
+func CLIToolRule(rule string) string {
+	server, ok := strings.CutSuffix(rule, "__*")
+	if !ok || !strings.HasPrefix(server, "mcp__") {
+		return rule
+	}
+	name := strings.TrimPrefix(server, "mcp__")
+	if name == "" || strings.ContainsAny(name, "*()") || strings.Contains(name, "__") {
+		return rule
+	}
+	return server
+}
+
+// splitToolMatcher splits a matcher on "|" outside of parentheses.
+func splitToolMatcher(matcher string) []string {
+	var segments []string
+	depth, start := 0, 0
+	for i, r := range matcher {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '|':
+			if depth == 0 {
+				segments = append(segments, matcher[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(segments, matcher[start:])
+}
+
+// globToRegexSource converts a glob with * wildcards into regular expression source.
+func globToRegexSource(glob string) string {
+	parts := strings.Split(glob, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return strings.Join(parts, ".*")
+}
+
+// globToRegexp compiles a glob with * wildcards into an anchored regular expression.
+func globToRegexp(glob string) *regexp.Regexp {
+	return regexp.MustCompile(`^(?s)` + globToRegexSource(glob) + `$`)
+}
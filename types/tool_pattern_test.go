@@ -0,0 +1,120 @@
+package types
+
+import "testing"
+
+// TestParseToolPattern tests parsing of tool rules.
+func TestParseToolPattern(t *testing.T) {
+	pattern, err := ParseToolPattern("Bash(git *)")
+	if err != nil {
+		t.Fatalf("ParseToolPattern failed: %v", err)
+	}
+	if pattern.Tool != "Bash" || pattern.Specifier == nil || *pattern.Specifier != "git *" {
+		t.Errorf("unexpected pattern: %+v", pattern)
+	}
+	if pattern.String() != "Bash(git *)" {
+		t.Errorf("expected round-trip string, got %q", pattern.String())
+	}
+
+	for _, invalid := range []string{"", "Bash(git *", "Bash()", "Bash)", "mcp.calc"} {
+		if _, err := ParseToolPattern(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+// TestToolPatternMatches tests tool name globs and specifiers.
+func TestToolPatternMatches(t *testing.T) {
+	tests := []struct {
+		rule  string
+		tool  string
+		input map[string]interface{}
+		want  bool
+	}{
+		{"mcp__calc__*", "mcp__calc__add", nil, true},
+		{"mcp__calc__*", "mcp__other__add", nil, false},
+		{"Bash", "Bash", map[string]interface{}{"command": "rm -rf /"}, true},
+		{"Bash(git *)", "Bash", map[string]interface{}{"command": "git status"}, true},
+		{"Bash(git *)", "Bash", map[string]interface{}{"command": "rm -rf /"}, false},
+		{"Bash(git:*)", "Bash", map[string]interface{}{"command": "git push"}, true},
+		{"Bash(git *)", "Bash", map[string]interface{}{"description": "git status"}, false},
+		{"Write(/tmp/*)", "Write", map[string]interface{}{"file_path": "/tmp/a/b.txt"}, true},
+		{"Write(/tmp/*)", "Write", map[string]interface{}{"file_path": "/etc/passwd"}, false},
+	}
+
+	for _, tt := range tests {
+		pattern, err := ParseToolPattern(tt.rule)
+		if err != nil {
+			t.Fatalf("ParseToolPattern(%q) failed: %v", tt.rule, err)
+		}
+		if got := pattern.Matches(tt.tool, tt.input); got != tt.want {
+			t.Errorf("%q.Matches(%q, %v) = %v, want %v", tt.rule, tt.tool, tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestToolMatcher tests "|"-separated matchers and their CLI regex.
+func TestToolMatcher(t *testing.T) {
+	if IsToolPattern("Write|Edit") || IsToolPattern("mcp__.*") {
+		t.Error("expected plain names and regular expressions not to be tool patterns")
+	}
+	if !IsToolPattern("mcp__calc__*|Bash(git *)") {
+		t.Error("expected globs and specifiers to be tool patterns")
+	}
+
+	matcher, err := ParseToolMatcher("mcp__calc__*|Bash(git *)")
+	if err != nil {
+		t.Fatalf("ParseToolMatcher failed: %v", err)
+	}
+	if matcher.Regex() != "^(?:mcp__calc__.*|Bash)$" {
+		t.Errorf("unexpected regex: %s", matcher.Regex())
+	}
+	if !matcher.HasSpecifiers() {
+		t.Error("expected matcher to have specifiers")
+	}
+
+	input := map[string]interface{}{
+		"hook_event_name": "PreToolUse",
+		"tool_name":       "Bash",
+		"tool_input":      map[string]interface{}{"command": "ls"},
+	}
+	if matcher.MatchesHookInput(input) {
+		t.Error("expected Bash(ls) not to match")
+	}
+	input["tool_name"] = "mcp__calc__add"
+	if !matcher.MatchesHookInput(input) {
+		t.Error("expected mcp__calc__add to match")
+	}
+}
+
+// TestCLIToolRule tests rewriting of tool rules for the CLI.
+func TestCLIToolRule(t *testing.T) {
+	tests := map[string]string{
+		"mcp__calc__*":  "mcp__calc",
+		"mcp__calc__a*": "mcp__calc__a*",
+		"Bash(git *)":   "Bash(git *)",
+		"Read":          "Read",
+	}
+	for rule, want := range tests {
+		if got := CLIToolRule(rule); got != want {
+			t.Errorf("CLIToolRule(%q) = %q, want %q", rule, got, want)
+		}
+	}
+
+	if err := ValidateToolRules([]string{"Read", "Bash(git *)"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateToolRules([]string{"Bash(git *"}); err == nil {
+		t.Error("expected error for malformed rule")
+	}
+	if err := ValidateToolRules([]string{"mcp__my.server__lookup"}); err != nil {
+		t.Errorf("expected a plain tool name to be accepted, got %v", err)
+	}
+
+	permissions := AgentPermissions{AllowedTools: []string{"mcp__my.server__lookup"}}
+	if reason := permissions.Check("tester", "mcp__my.server__lookup", nil); reason != "" {
+		t.Errorf("expected a plain tool name rule to match the tool, got %q", reason)
+	}
+	if reason := permissions.Check("tester", "mcp__myXserver__lookup", nil); reason == "" {
+		t.Error("expected a plain tool name rule to match only that tool")
+	}
+}
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ToolProgress is an update reported by an SDK MCP tool while it runs.
+type ToolProgress struct {
+	Time     time.Time      `json:"time"`
+	Server   string         `json:"server"`
+	Tool     string         `json:"tool"`
+	Token    interface{}    `json:"progress_token,omitempty"` // MCP progress token sent by the CLI, if any
+	Progress float64        `json:"progress"`
+	Total    float64        `json:"total,omitempty"` // Zero when the total is unknown
+	Message  string         `json:"message,omitempty"`
+	Content  []ContentBlock `json:"content,omitempty"` // Partial content, set by ReportToolContent
+}
+
+// ToolProgressFunc receives progress updates from SDK MCP tools.
+type ToolProgressFunc func(progress ToolProgress)
+
+// toolProgressKey is the context key of the progress reporter of a tool call.
+type toolProgressKey struct{}
+
+// toolProgressState is the reporter of a tool call and its last progress.
+type toolProgressState struct {
+	report   func(ToolProgress)
+	mu       sync.Mutex
+	progress float64
+	total    float64
+}
+
+// ContextWithToolProgress returns a context whose tool progress is sent to
+// report. SDK MCP servers set it before calling a tool handler.
+func ContextWithToolProgress(ctx context.Context, report func(ToolProgress)) context.Context {
+	return context.WithValue(ctx, toolProgressKey{}, &toolProgressState{report: report})
+}
+
+// ReportToolProgress reports how far a running tool has got, for example
+// ReportToolProgress(ctx, 3, 10, "Compiled 3 of 10 packages"). Pass a zero
+// total when it is unknown. It does nothing outside an SDK MCP tool call.
+//
+// Progress should increase with each report, as the MCP specification requires.
+func ReportToolProgress(ctx context.Context, progress, total float64, message string) {
+	state, ok := ctx.Value(toolProgressKey{}).(*toolProgressState)
+	if !ok || state.report == nil {
+		return
+	}
+	state.mu.Lock()
+	state.progress, state.total = progress, total
+	state.mu.Unlock()
+	state.report(ToolProgress{Time: time.Now(), Progress: progress, Total: total, Message: message})
+}
+
+// ReportToolContent sends partial content from a running tool, such as
+// build output, before the tool returns its result. It does nothing
+// outside an SDK MCP tool call.
+func ReportToolContent(ctx context.Context, content ...ContentBlock) {
+	state, ok := ctx.Value(toolProgressKey{}).(*toolProgressState)
+	if !ok || state.report == nil || len(content) == 0 {
+		return
+	}
+	state.mu.Lock()
+	progress, total := state.progress, state.total
+	state.mu.Unlock()
+	state.report(ToolProgress{Time: time.Now(), Progress: progress, Total: total, Content: content})
+}
@@ -0,0 +1,34 @@
+package types
+
+import (
+	"context"
+	"testing"
+)
+
+// TestReportToolProgress tests reporting progress and partial content from a tool call context.
+func TestReportToolProgress(t *testing.T) {
+	// Outside a tool call, reports are ignored.
+	ReportToolProgress(context.Background(), 1, 2, "ignored")
+	ReportToolContent(context.Background(), TextBlock{Type: "text", Text: "ignored"})
+
+	var updates []ToolProgress
+	ctx := ContextWithToolProgress(context.Background(), func(p ToolProgress) {
+		updates = append(updates, p)
+	})
+	ReportToolProgress(ctx, 3, 10, "Compiled 3 of 10 packages")
+	ReportToolContent(ctx)
+	ReportToolContent(ctx, TextBlock{Type: "text", Text: "ok pkg/a"})
+
+	if len(updates) != 2 {
+		t.Fatalf("expected 2 updates, got %d", len(updates))
+	}
+	if updates[0].Progress != 3 || updates[0].Total != 10 || updates[0].Message != "Compiled 3 of 10 packages" {
+		t.Errorf("unexpected progress update: %+v", updates[0])
+	}
+	if updates[1].Progress != 3 || updates[1].Total != 10 || len(updates[1].Content) != 1 {
+		t.Errorf("expected content at the last progress, got %+v", updates[1])
+	}
+	if updates[0].Time.IsZero() {
+		t.Error("expected update time to be set")
+	}
+}
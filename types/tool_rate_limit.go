@@ -0,0 +1,260 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimitAction selects what happens to a tool call past its rate limit.
+type RateLimitAction string
+
+const (
+	// RateLimitDeny denies the tool call with a message naming the limit.
+	RateLimitDeny RateLimitAction = "deny"
+	// RateLimitDelay holds the tool call until the window has room for it.
+	// Calls that would wait longer than the limiter's MaxDelay are denied.
+	RateLimitDelay RateLimitAction = "delay"
+)
+
+// DefaultRateLimitMaxDelay caps how long RateLimitDelay holds a tool call.
+// It stays below the CLI's hook timeout.
+const DefaultRateLimitMaxDelay = 30 * time.Second
+
+// ToolRateStats holds the counters of one tool rate limit.
+type ToolRateStats struct {
+	Pattern  string          `json:"pattern"`
+	Limit    int             `json:"limit"`
+	Window   time.Duration   `json:"window"`
+	Action   RateLimitAction `json:"action"`
+	InWindow int             `json:"in_window"` // Calls counted in the current window
+	Allowed  int64           `json:"allowed"`   // Calls allowed without waiting
+	Delayed  int64           `json:"delayed"`   // Calls allowed after waiting
+	Denied   int64           `json:"denied"`    // Calls denied
+}
+
+// toolRateLimit is a sliding-window limit for the tools matching a pattern.
+type toolRateLimit struct {
+	pattern *ToolPattern // Nil when the limit is invalid
+	err     error
+	stats   ToolRateStats
+	calls   []time.Time // Start times of calls in the window, oldest first
+}
+
+// prune drops calls that left the window.
+func (l *toolRateLimit) prune(now time.Time) {
+	cutoff := now.Add(-l.stats.Window)
+	i := 0
+	for i < len(l.calls) && !l.calls[i].After(cutoff) {
+		i++
+	}
+	l.calls = l.calls[i:]
+	l.stats.InWindow = len(l.calls)
+}
+
+// wait returns how long a call at now must wait for room in the window.
+func (l *toolRateLimit) wait(now time.Time) time.Duration {
+	l.prune(now)
+	if len(l.calls) < l.stats.Limit {
+		return 0
+	}
+	return l.calls[len(l.calls)-l.stats.Limit].Add(l.stats.Window).Sub(now)
+}
+
+// ToolRateLimiter limits how often tools may be called, for example to
+// protect an external system behind an MCP tool. Each limit counts the calls
+// of the tools matching its pattern in a sliding window. A call is counted by
+// every limit it matches.
+//
+// Example:
+//
+//	opts := types.NewClaudeAgentOptions().
+//	    WithToolRateLimit("Bash", 10, time.Minute).
+//	    WithToolRateLimit("mcp__github__*", 30, time.Minute)
+//
+//	// Later:
+//	for _, stats := range opts.ToolRateLimiter.Stats() {
+//	    fmt.Printf("%s: %d allowed, %d denied\n", stats.Pattern, stats.Allowed, stats.Denied)
+//	}
+type ToolRateLimiter struct {
+	// Action applies to calls past a limit. Defaults to RateLimitDeny.
+	Action RateLimitAction
+	// MaxDelay caps how long RateLimitDelay holds a call. Defaults to DefaultRateLimitMaxDelay.
+	MaxDelay time.Duration
+
+	mu     sync.Mutex
+	limits []*toolRateLimit
+	now    func() time.Time
+}
+
+// NewToolRateLimiter creates a ToolRateLimiter without limits.
+func NewToolRateLimiter() *ToolRateLimiter {
+	return &ToolRateLimiter{now: time.Now}
+}
+
+// SetLimit allows at most limit calls of the tools matching pattern per
+// window. Setting a pattern again replaces its limit and resets its counters.
+// Invalid limits are reported by Validate and never match.
+func (l *ToolRateLimiter) SetLimit(pattern string, limit int, window time.Duration) *ToolRateLimiter {
+	entry := &toolRateLimit{
+		stats: ToolRateStats{Pattern: pattern, Limit: limit, Window: window},
+	}
+	switch parsed, err := ParseToolPattern(pattern); {
+	case err != nil:
+		entry.err = err
+	case limit <= 0:
+		entry.err = fmt.Errorf("rate limit for %q must be positive, got %d", pattern, limit)
+	case window <= 0:
+		entry.err = fmt.Errorf("rate limit window for %q must be positive, got %v", pattern, window)
+	default:
+		entry.pattern = parsed
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry.stats.Action = l.action()
+	for i, existing := range l.limits {
+		if existing.stats.Pattern == pattern {
+			l.limits[i] = entry
+			return l
+		}
+	}
+	l.limits = append(l.limits, entry)
+	return l
+}
+
+// Validate reports the first invalid limit. A nil limiter is valid.
+func (l *ToolRateLimiter) Validate() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.Action != "" && l.Action != RateLimitDeny && l.Action != RateLimitDelay {
+		return fmt.Errorf("invalid rate limit action %q", l.Action)
+	}
+	for _, limit := range l.limits {
+		if limit.err != nil {
+			return limit.err
+		}
+	}
+	return nil
+}
+
+// Acquire counts a call of toolName against the matching limits. It returns
+// an empty reason when the call may run, or the reason it is denied. With
+// RateLimitDelay it waits for room in the window; an error is returned only
+// when ctx ends while waiting.
+func (l *ToolRateLimiter) Acquire(ctx context.Context, toolName string, input map[string]interface{}) (string, error) {
+	delayed := false
+	for {
+		l.mu.Lock()
+		now := l.now()
+		matched := l.matching(toolName, input)
+
+		var wait time.Duration
+		var blocking *toolRateLimit
+		for _, limit := range matched {
+			if w := limit.wait(now); w > wait {
+				wait, blocking = w, limit
+			}
+		}
+
+		if blocking == nil {
+			for _, limit := range matched {
+				limit.calls = append(limit.calls, now)
+				limit.stats.InWindow = len(limit.calls)
+				if delayed {
+					limit.stats.Delayed++
+				} else {
+					limit.stats.Allowed++
+				}
+			}
+			l.mu.Unlock()
+			return "", nil
+		}
+
+		if l.action() != RateLimitDelay || wait > l.maxDelay() {
+			for _, limit := range matched {
+				limit.stats.Denied++
+			}
+			reason := fmt.Sprintf("%s exceeded its rate limit of %d calls per %v; retry in %v",
+				toolName, blocking.stats.Limit, blocking.stats.Window, wait.Round(time.Second))
+			l.mu.Unlock()
+			return reason, nil
+		}
+		l.mu.Unlock()
+
+		delayed = true
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Stats returns the counters of every limit, in the order they were set.
+func (l *ToolRateLimiter) Stats() []ToolRateStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	stats := make([]ToolRateStats, len(l.limits))
+	for i, limit := range l.limits {
+		limit.prune(now)
+		limit.stats.Action = l.action()
+		stats[i] = limit.stats
+	}
+	return stats
+}
+
+// Hook returns a PreToolUse hook matcher that enforces the limits.
+// WithToolRateLimit installs it automatically.
+func (l *ToolRateLimiter) Hook() HookMatcher {
+	return HookMatcher{
+		Hooks: []HookCallbackFunc{func(ctx context.Context, input interface{}, toolUseID *string, hookCtx HookContext) (interface{}, error) {
+			raw, _ := input.(map[string]interface{})
+			toolName, _ := raw["tool_name"].(string)
+			toolInput, _ := raw["tool_input"].(map[string]interface{})
+
+			reason, err := l.Acquire(ctx, toolName, toolInput)
+			if err != nil {
+				return nil, err
+			}
+			if reason != "" {
+				return DenyToolUse(reason), nil
+			}
+			return map[string]interface{}{}, nil
+		}},
+	}
+}
+
+// matching returns the limits that apply to a tool call. The caller holds l.mu.
+func (l *ToolRateLimiter) matching(toolName string, input map[string]interface{}) []*toolRateLimit {
+	var matched []*toolRateLimit
+	for _, limit := range l.limits {
+		if limit.pattern != nil && limit.pattern.Matches(toolName, input) {
+			matched = append(matched, limit)
+		}
+	}
+	return matched
+}
+
+// action returns the configured action or the default.
+func (l *ToolRateLimiter) action() RateLimitAction {
+	if l.Action == "" {
+		return RateLimitDeny
+	}
+	return l.Action
+}
+
+// maxDelay returns the configured maximum delay or the default.
+func (l *ToolRateLimiter) maxDelay() time.Duration {
+	if l.MaxDelay <= 0 {
+		return DefaultRateLimitMaxDelay
+	}
+	return l.MaxDelay
+}
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRateLimiter returns a limiter whose clock is advanced by the returned function.
+func newTestRateLimiter() (*ToolRateLimiter, func(time.Duration)) {
+	now := time.Unix(1700000000, 0)
+	limiter := NewToolRateLimiter()
+	limiter.now = func() time.Time { return now }
+	return limiter, func(d time.Duration) { now = now.Add(d) }
+}
+
+// TestToolRateLimiterDeny tests that calls past a limit are denied until the window moves on.
+func TestToolRateLimiterDeny(t *testing.T) {
+	limiter, advance := newTestRateLimiter()
+	limiter.SetLimit("Bash", 2, time.Minute).SetLimit("mcp__github__*", 1, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if reason, err := limiter.Acquire(ctx, "Bash", nil); reason != "" || err != nil {
+			t.Fatalf("call %d: unexpected denial %q, %v", i, reason, err)
+		}
+		advance(10 * time.Second)
+	}
+	reason, _ := limiter.Acquire(ctx, "Bash", nil)
+	if !strings.Contains(reason, "rate limit of 2 calls per 1m0s") || !strings.Contains(reason, "retry in 40s") {
+		t.Errorf("unexpected denial reason: %q", reason)
+	}
+
+	if reason, _ := limiter.Acquire(ctx, "Read", nil); reason != "" {
+		t.Errorf("expected unlimited tool to be allowed, got %q", reason)
+	}
+	if reason, _ := limiter.Acquire(ctx, "mcp__github__search", nil); reason != "" {
+		t.Errorf("expected first MCP call to be allowed, got %q", reason)
+	}
+
+	advance(41 * time.Second)
+	if reason, _ := limiter.Acquire(ctx, "Bash", nil); reason != "" {
+		t.Errorf("expected call after the window to be allowed, got %q", reason)
+	}
+
+	stats := limiter.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 limits, got %d", len(stats))
+	}
+	if bash := stats[0]; bash.Allowed != 3 || bash.Denied != 1 || bash.InWindow != 2 || bash.Action != RateLimitDeny {
+		t.Errorf("unexpected Bash stats: %+v", bash)
+	}
+	if github := stats[1]; github.Pattern != "mcp__github__*" || github.Allowed != 1 {
+		t.Errorf("unexpected MCP stats: %+v", github)
+	}
+}
+
+// TestToolRateLimiterDelay tests that RateLimitDelay waits for room in the window.
+func TestToolRateLimiterDelay(t *testing.T) {
+	limiter := NewToolRateLimiter().SetLimit("Bash", 1, 50*time.Millisecond)
+	limiter.Action = RateLimitDelay
+	ctx := context.Background()
+
+	if reason, err := limiter.Acquire(ctx, "Bash", nil); reason != "" || err != nil {
+		t.Fatalf("unexpected denial %q, %v", reason, err)
+	}
+	started := time.Now()
+	if reason, err := limiter.Acquire(ctx, "Bash", nil); reason != "" || err != nil {
+		t.Fatalf("expected delayed call to be allowed, got %q, %v", reason, err)
+	}
+	if elapsed := time.Since(started); elapsed < 40*time.Millisecond {
+		t.Errorf("expected the call to wait for the window, waited %v", elapsed)
+	}
+	if stats := limiter.Stats()[0]; stats.Allowed != 1 || stats.Delayed != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	limiter.MaxDelay = time.Millisecond
+	if reason, _ := limiter.Acquire(ctx, "Bash", nil); reason == "" {
+		t.Error("expected a call waiting longer than MaxDelay to be denied")
+	}
+
+	limiter.MaxDelay = time.Minute
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := limiter.Acquire(cancelled, "Bash", nil); err == nil {
+		t.Error("expected an error when the context ends while waiting")
+	}
+}
+
+// TestToolRateLimiterValidate tests that invalid limits are reported.
+func TestToolRateLimiterValidate(t *testing.T) {
+	var nilLimiter *ToolRateLimiter
+	if err := nilLimiter.Validate(); err != nil {
+		t.Errorf("expected nil limiter to be valid, got %v", err)
+	}
+
+	if err := NewClaudeAgentOptions().WithToolRateLimit("Bash", 10, time.Minute).ToolRateLimiter.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, opts := range []*ClaudeAgentOptions{
+		NewClaudeAgentOptions().WithToolRateLimit("Bash", 0, time.Minute),
+		NewClaudeAgentOptions().WithToolRateLimit("Bash", 1, 0),
+		NewClaudeAgentOptions().WithToolRateLimit("Bash(", 1, time.Minute),
+		NewClaudeAgentOptions().WithToolRateLimit("Bash", 1, time.Minute).WithToolRateLimitAction("queue"),
+	} {
+		if err := opts.ToolRateLimiter.Validate(); err == nil {
+			t.Errorf("expected error for %+v", opts.ToolRateLimiter.Stats())
+		}
+	}
+}
+
+// TestToolRateLimiterHook tests that the hook denies calls past the limit.
+func TestToolRateLimiterHook(t *testing.T) {
+	limiter := NewToolRateLimiter().SetLimit("Bash", 1, time.Minute)
+	hook := limiter.Hook().Hooks[0]
+	input := map[string]interface{}{"tool_name": "Bash", "tool_input": map[string]interface{}{"command": "ls"}}
+
+	output, err := hook(context.Background(), input, nil, HookContext{})
+	if err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+	if normalized, _ := NormalizeHookOutput(output); len(normalized) != 0 {
+		t.Errorf("expected first call to pass through, got %v", normalized)
+	}
+
+	output, _ = hook(context.Background(), input, nil, HookContext{})
+	specific := hookSpecific(t, output.(*SyncHookJSONOutput))
+	if specific["permissionDecision"] != "deny" {
+		t.Errorf("expected second call to be denied, got %v", specific)
+	}
+}
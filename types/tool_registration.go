@@ -0,0 +1,78 @@
+package types
+
+import "fmt"
+
+// toolRegistry is an MCP server whose tools can change at runtime.
+type toolRegistry interface {
+	AddTool(tool McpTool) error
+	RemoveTool(name string) error
+}
+
+// resourceRemover is an MCP server whose resources can be removed.
+type resourceRemover interface {
+	RemoveResource(uri string) error
+}
+
+// AddTool adds a tool to the server. Once a session has started the
+// server, the tool is added to the running server, which notifies Claude
+// with notifications/tools/list_changed so it can call the tool in the
+// same conversation. It returns an error if the name is already registered.
+//
+// Example:
+//
+//	server := types.CreateToolServer("plugins", "1.0.0", nil)
+//	opts := types.NewClaudeAgentOptions().
+//	    WithMcpServers(map[string]interface{}{"plugins": server})
+//	client, err := claude.NewClient(ctx, opts)
+//	...
+//	// Later, while the conversation is running:
+//	err = server.AddTool(pluginTool)
+func (c *ToolServerConfig) AddTool(tool McpTool) error {
+	if tool == nil {
+		return fmt.Errorf("tool is required")
+	}
+	if server, ok := c.Instance.(toolRegistry); ok {
+		return server.AddTool(tool)
+	}
+	tools, _ := c.Instance.([]McpTool)
+	for _, existing := range tools {
+		if existing.Name() == tool.Name() {
+			return fmt.Errorf("tool already exists: %s", tool.Name())
+		}
+	}
+	c.Instance = append(tools[:len(tools):len(tools)], tool)
+	return nil
+}
+
+// RemoveTool removes a tool from the server. Once a session has started
+// the server, Claude is notified that the tool list changed; calls already
+// running finish normally. It returns an error if the tool is not registered.
+func (c *ToolServerConfig) RemoveTool(name string) error {
+	if server, ok := c.Instance.(toolRegistry); ok {
+		return server.RemoveTool(name)
+	}
+	tools, _ := c.Instance.([]McpTool)
+	for i, tool := range tools {
+		if tool.Name() == name {
+			c.Instance = append(tools[:i:i], tools[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("tool not found: %s", name)
+}
+
+// RemoveResource removes a resource from the server. Once a session has
+// started the server, Claude is notified that the resource list changed.
+// It returns an error if the URI is not registered.
+func (c *ToolServerConfig) RemoveResource(uri string) error {
+	if server, ok := c.Instance.(resourceRemover); ok {
+		return server.RemoveResource(uri)
+	}
+	for i, resource := range c.Resources {
+		if resource.URI == uri {
+			c.Resources = append(c.Resources[:i:i], c.Resources[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("resource not found: %s", uri)
+}
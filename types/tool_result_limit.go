@@ -0,0 +1,199 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// ResultLimitStrategy selects how a tool result over its size limit is cut
+// down.
+type ResultLimitStrategy string
+
+const (
+	// TruncateTail keeps the beginning of the output. It is the default.
+	TruncateTail ResultLimitStrategy = "truncate_tail"
+
+	// TruncateHead keeps the end of the output, such as the last lines of a log.
+	TruncateHead ResultLimitStrategy = "truncate_head"
+
+	// TruncateMiddle keeps the beginning and the end of the output.
+	TruncateMiddle ResultLimitStrategy = "truncate_middle"
+
+	// SpillToFile saves the full output to a file and returns its beginning
+	// with the file's path, so Claude can read the rest with its file tools.
+	SpillToFile ResultLimitStrategy = "spill_to_file"
+)
+
+// ResultLimit caps the text a tool returns, so a tool that produces
+// megabytes of output cannot flood the context or the transport. The
+// limit applies to the combined text blocks of a result; a result over it
+// has its text blocks replaced by one block holding MaxBytes of the text
+// and a marker saying how much was omitted. Other blocks, such as images,
+// are kept.
+type ResultLimit struct {
+	MaxBytes int                 // Maximum text size in bytes
+	Strategy ResultLimitStrategy // Defaults to TruncateTail
+	SpillDir string              // Directory for SpillToFile; defaults to os.TempDir()
+}
+
+// MaxResultSize limits the size of the tool's results.
+//
+// Example:
+//
+//	types.NewTool("read_logs").
+//	    MaxResultSize(types.ResultLimit{MaxBytes: 64 << 10, Strategy: types.TruncateHead})
+func (b *ToolBuilder) MaxResultSize(limit ResultLimit) *ToolBuilder {
+	b.resultLimit = &limit
+	return b
+}
+
+// LimitResultSize returns middleware that limits the size of every result,
+// for use with ToolServerConfig.Use or ToolManager.Use to limit a whole
+// server. A limit that fails Validate leaves results unchanged.
+func LimitResultSize(limit ResultLimit) ToolMiddleware {
+	return func(next ToolFunc) ToolFunc {
+		if limit.Validate() != nil {
+			return next
+		}
+		return func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			result, err := next(ctx, input)
+			if err != nil {
+				return result, err
+			}
+			call, _ := ToolCallFromContext(ctx)
+			return limit.Apply(call.Tool, result), nil
+		}
+	}
+}
+
+// Validate checks the limit's size and strategy.
+func (l ResultLimit) Validate() error {
+	if l.MaxBytes <= 0 {
+		return fmt.Errorf("result size limit must be positive, got %d", l.MaxBytes)
+	}
+	switch l.Strategy {
+	case "", TruncateTail, TruncateHead, TruncateMiddle, SpillToFile:
+		return nil
+	}
+	return fmt.Errorf("unknown result limit strategy %q", l.Strategy)
+}
+
+// Apply returns result cut down to the limit, or result itself when it
+// fits. tool names the spill file.
+func (l ResultLimit) Apply(tool string, result *ToolResult) *ToolResult {
+	if result == nil || l.MaxBytes <= 0 {
+		return result
+	}
+	var texts []string
+	size := 0
+	for _, block := range result.Content {
+		if text, ok := textOf(block); ok {
+			texts = append(texts, text)
+			size += len(text)
+		}
+	}
+	if size <= l.MaxBytes {
+		return result
+	}
+
+	text := l.limitText(tool, strings.Join(texts, "\n"))
+	limited := &ToolResult{IsError: result.IsError}
+	replaced := false
+	for _, block := range result.Content {
+		if _, ok := textOf(block); !ok {
+			limited.Content = append(limited.Content, block)
+		} else if !replaced {
+			limited.Content = append(limited.Content, TextBlock{Type: "text", Text: text})
+			replaced = true
+		}
+	}
+	return limited
+}
+
+// limitText cuts text down to the limit using the limit's strategy.
+func (l ResultLimit) limitText(tool, text string) string {
+	omitted := fmt.Sprintf("[... %d bytes omitted: the output was %d bytes, over the %d byte limit ...]",
+		len(text)-l.MaxBytes, len(text), l.MaxBytes)
+
+	switch l.Strategy {
+	case TruncateHead:
+		return omitted + "\n" + suffixBytes(text, l.MaxBytes)
+	case TruncateMiddle:
+		head := prefixBytes(text, l.MaxBytes/2)
+		tail := suffixBytes(text, l.MaxBytes-len(head))
+		return head + "\n" + omitted + "\n" + tail
+	case SpillToFile:
+		if path, err := l.spill(tool, text); err == nil {
+			return prefixBytes(text, l.MaxBytes) + fmt.Sprintf(
+				"\n[... the output was %d bytes, over the %d byte limit. The full output is saved at %s; read it from there if you need the rest ...]",
+				len(text), l.MaxBytes, path)
+		}
+	}
+	return prefixBytes(text, l.MaxBytes) + "\n" + omitted
+}
+
+// spill writes text to a new file and returns its path.
+func (l ResultLimit) spill(tool, text string) (string, error) {
+	dir := l.SpillDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, tool)
+	file, err := os.CreateTemp(dir, "tool-"+name+"-*.txt")
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// textOf returns the text of a text block.
+func textOf(block ContentBlock) (string, bool) {
+	switch b := block.(type) {
+	case TextBlock:
+		return b.Text, true
+	case *TextBlock:
+		return b.Text, true
+	}
+	return "", false
+}
+
+// prefixBytes returns at most n bytes from the start of s, without
+// splitting a UTF-8 character.
+func prefixBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// suffixBytes returns at most n bytes from the end of s, without splitting
+// a UTF-8 character.
+func suffixBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	start := len(s) - n
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return s[start:]
+}
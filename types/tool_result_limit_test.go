@@ -0,0 +1,102 @@
+package types
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestResultLimitStrategies tests each way of cutting down an oversized result.
+func TestResultLimitStrategies(t *testing.T) {
+	text := strings.Repeat("a", 50) + strings.Repeat("z", 50)
+	image := NewImageBlock([]byte("png"), "image/png")
+	result := NewMcpToolResult(TextBlock{Type: "text", Text: text}, image)
+
+	if got := (ResultLimit{MaxBytes: 100}).Apply("t", result); got != result {
+		t.Error("expected a result within the limit to be unchanged")
+	}
+
+	tests := []struct {
+		strategy ResultLimitStrategy
+		prefix   string
+		suffix   string
+	}{
+		{TruncateTail, strings.Repeat("a", 10) + "\n[... 90 bytes omitted", "limit ...]"},
+		{TruncateHead, "[... 90 bytes omitted", "limit ...]\n" + strings.Repeat("z", 10)},
+		{TruncateMiddle, "aaaaa\n[... 90 bytes omitted", "limit ...]\nzzzzz"},
+	}
+	for _, tt := range tests {
+		limited := (ResultLimit{MaxBytes: 10, Strategy: tt.strategy}).Apply("t", result)
+		if len(limited.Content) != 2 || limited.Content[1] != image {
+			t.Fatalf("%s: expected the text and the image, got %+v", tt.strategy, limited.Content)
+		}
+		got := limited.Content[0].(TextBlock).Text
+		if !strings.HasPrefix(got, tt.prefix) || !strings.HasSuffix(got, tt.suffix) {
+			t.Errorf("%s: unexpected text %q", tt.strategy, got)
+		}
+	}
+
+	// Truncation never splits a UTF-8 character.
+	limited := (ResultLimit{MaxBytes: 2}).Apply("t", NewMcpToolResult(TextBlock{Type: "text", Text: "héllo wörld"}))
+	if got := limited.Content[0].(TextBlock).Text; !strings.HasPrefix(got, "h\n") {
+		t.Errorf("unexpected text %q", got)
+	}
+}
+
+// TestResultLimitSpillToFile tests saving oversized output to a file.
+func TestResultLimitSpillToFile(t *testing.T) {
+	dir := t.TempDir()
+	text := strings.Repeat("line\n", 100)
+	limit := ResultLimit{MaxBytes: 20, Strategy: SpillToFile, SpillDir: dir}
+	limited := limit.Apply("logs/tail", NewMcpToolResult(TextBlock{Type: "text", Text: text}))
+
+	got := limited.Content[0].(TextBlock).Text
+	match := regexp.MustCompile(`saved at (\S+);`).FindStringSubmatch(got)
+	if !strings.HasPrefix(got, text[:20]) || match == nil {
+		t.Fatalf("unexpected text %q", got)
+	}
+	if !strings.HasPrefix(match[1], dir) || !strings.Contains(match[1], "tool-logs_tail-") {
+		t.Errorf("unexpected path %q", match[1])
+	}
+	saved, err := os.ReadFile(match[1])
+	if err != nil || string(saved) != text {
+		t.Errorf("expected the full output in the file, got %d bytes, %v", len(saved), err)
+	}
+
+	// A spill that fails falls back to truncation.
+	limit.SpillDir = dir + "/missing"
+	got = limit.Apply("t", NewMcpToolResult(TextBlock{Type: "text", Text: text})).Content[0].(TextBlock).Text
+	if !strings.Contains(got, "bytes omitted") {
+		t.Errorf("expected truncation, got %q", got)
+	}
+}
+
+// TestMaxResultSize tests limits on a tool and on a server through middleware.
+func TestMaxResultSize(t *testing.T) {
+	handler := func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+		return NewMcpToolResult(TextBlock{Type: "text", Text: strings.Repeat("x", 1000)}), nil
+	}
+
+	tool, err := NewTool("big").Description("Big output").Handler(handler).MaxResultSize(ResultLimit{MaxBytes: 100}).Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	result, _ := tool.Execute(context.Background(), map[string]interface{}{})
+	if got := result.Content[0].(TextBlock).Text; !strings.HasPrefix(got, strings.Repeat("x", 100)+"\n[...") {
+		t.Errorf("unexpected text %q", got)
+	}
+
+	limited := ChainToolMiddleware(LimitResultSize(ResultLimit{MaxBytes: 10, Strategy: TruncateHead}))(handler)
+	result, _ = limited(context.Background(), nil)
+	if got := result.Content[0].(TextBlock).Text; !strings.HasSuffix(got, "\n"+strings.Repeat("x", 10)) {
+		t.Errorf("unexpected text %q", got)
+	}
+
+	for _, limit := range []ResultLimit{{MaxBytes: 0}, {MaxBytes: 10, Strategy: "compress"}} {
+		if _, err := NewTool("big").Description("Big output").Handler(handler).MaxResultSize(limit).Build(); err == nil {
+			t.Errorf("expected error for %+v", limit)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package types
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ToolCallRecord describes one completed SDK MCP tool call.
+type ToolCallRecord struct {
+	Time     time.Time     // When the call started
+	Server   string        // Name of the SDK MCP server
+	Tool     string        // Name of the tool
+	Duration time.Duration // Time spent in middleware and the handler
+	Err      error         // Error of the call; a *ToolPanicError if the handler panicked
+	IsError  bool          // The tool returned a result with IsError set
+}
+
+// Failed reports whether the call returned an error or an error result.
+func (r ToolCallRecord) Failed() bool {
+	return r.Err != nil || r.IsError
+}
+
+// ToolCallObserver receives a record of each tool call an SDK MCP server
+// completes, for example to export it to OpenTelemetry.
+type ToolCallObserver func(record ToolCallRecord)
+
+// ToolStats aggregates the calls of a single tool.
+type ToolStats struct {
+	Server        string        `json:"server"`
+	Tool          string        `json:"tool"`
+	Calls         int           `json:"calls"`
+	Errors        int           `json:"errors"` // Calls that failed, including panics
+	Panics        int           `json:"panics"`
+	TotalDuration time.Duration `json:"total_duration"`
+	MaxDuration   time.Duration `json:"max_duration"`
+	LastCall      time.Time     `json:"last_call"`
+}
+
+// AverageDuration returns the mean call duration.
+func (s ToolStats) AverageDuration() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Calls)
+}
+
+// ErrorRate returns the fraction of calls that failed.
+func (s ToolStats) ErrorRate() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Calls)
+}
+
+// ToolStatsRecorder aggregates tool call records into per-tool statistics.
+// Every SDK MCP server keeps one, read with ToolServerConfig.Stats; its
+// Record method can also be registered with OnToolCall to aggregate the
+// calls of several servers.
+//
+// It is safe for concurrent use.
+type ToolStatsRecorder struct {
+	mu    sync.Mutex
+	stats map[[2]string]*ToolStats // {server, tool} -> stats
+}
+
+// NewToolStatsRecorder creates a recorder with no calls.
+func NewToolStatsRecorder() *ToolStatsRecorder {
+	return &ToolStatsRecorder{stats: make(map[[2]string]*ToolStats)}
+}
+
+// Record counts a tool call.
+func (r *ToolStatsRecorder) Record(record ToolCallRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := [2]string{record.Server, record.Tool}
+	stats, ok := r.stats[key]
+	if !ok {
+		stats = &ToolStats{Server: record.Server, Tool: record.Tool}
+		r.stats[key] = stats
+	}
+
+	stats.Calls++
+	if record.Failed() {
+		stats.Errors++
+	}
+	var panicErr *ToolPanicError
+	if errors.As(record.Err, &panicErr) {
+		stats.Panics++
+	}
+	stats.TotalDuration += record.Duration
+	if record.Duration > stats.MaxDuration {
+		stats.MaxDuration = record.Duration
+	}
+	if record.Time.After(stats.LastCall) {
+		stats.LastCall = record.Time
+	}
+}
+
+// Stats returns the statistics of every tool called so far, sorted by
+// server and tool name.
+func (r *ToolStatsRecorder) Stats() []ToolStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]ToolStats, 0, len(r.stats))
+	for _, s := range r.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Server != stats[j].Server {
+			return stats[i].Server < stats[j].Server
+		}
+		return stats[i].Tool < stats[j].Tool
+	})
+	return stats
+}
+
+// Reset clears the statistics.
+func (r *ToolStatsRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats = make(map[[2]string]*ToolStats)
+}
+
+// toolStatsReporter is an MCP server that records tool call statistics.
+type toolStatsReporter interface {
+	Stats() []ToolStats
+	ResetStats()
+	OnToolCall(observer ToolCallObserver)
+}
+
+// Stats returns the call statistics of the server's tools, sorted by tool
+// name. It returns nil before a session has started the server.
+func (c *ToolServerConfig) Stats() []ToolStats {
+	if server, ok := c.Instance.(toolStatsReporter); ok {
+		return server.Stats()
+	}
+	return nil
+}
+
+// ResetStats clears the server's call statistics.
+func (c *ToolServerConfig) ResetStats() {
+	if server, ok := c.Instance.(toolStatsReporter); ok {
+		server.ResetStats()
+	}
+}
+
+// OnToolCall registers observer to receive a record of each tool call the
+// server completes. Use it to export calls to a metrics system such as
+// OpenTelemetry:
+//
+//	server.OnToolCall(func(r types.ToolCallRecord) {
+//	    attrs := metric.WithAttributes(attribute.String("tool", r.Tool))
+//	    latency.Record(ctx, r.Duration.Seconds(), attrs)
+//	})
+//
+// Observers run synchronously after each call, so they should not block.
+func (c *ToolServerConfig) OnToolCall(observer ToolCallObserver) {
+	if server, ok := c.Instance.(toolStatsReporter); ok {
+		server.OnToolCall(observer)
+		return
+	}
+	c.Observers = append(c.Observers, observer)
+}
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestToolStatsRecorder tests that calls are aggregated per server and tool.
+func TestToolStatsRecorder(t *testing.T) {
+	recorder := NewToolStatsRecorder()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	recorder.Record(ToolCallRecord{Time: start, Server: "b", Tool: "search", Duration: 100 * time.Millisecond})
+	recorder.Record(ToolCallRecord{Time: start.Add(time.Second), Server: "b", Tool: "search", Duration: 300 * time.Millisecond, IsError: true})
+	recorder.Record(ToolCallRecord{Time: start, Server: "b", Tool: "fetch", Duration: time.Second, Err: errors.New("timeout")})
+	recorder.Record(ToolCallRecord{Time: start, Server: "a", Tool: "crash", Err: &ToolPanicError{Tool: "crash", Value: "boom"}})
+
+	stats := recorder.Stats()
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 tools, got %d", len(stats))
+	}
+	if stats[0].Tool != "crash" || stats[1].Tool != "fetch" || stats[2].Tool != "search" {
+		t.Errorf("expected stats sorted by server and tool, got %+v", stats)
+	}
+	if stats[0].Panics != 1 || stats[0].Errors != 1 {
+		t.Errorf("expected a panic counted as an error, got %+v", stats[0])
+	}
+	search := stats[2]
+	if search.Calls != 2 || search.Errors != 1 || search.ErrorRate() != 0.5 {
+		t.Errorf("unexpected counts: %+v", search)
+	}
+	if search.AverageDuration() != 200*time.Millisecond || search.MaxDuration != 300*time.Millisecond {
+		t.Errorf("unexpected durations: avg %v, max %v", search.AverageDuration(), search.MaxDuration)
+	}
+	if !search.LastCall.Equal(start.Add(time.Second)) {
+		t.Errorf("expected the latest call time, got %v", search.LastCall)
+	}
+
+	recorder.Reset()
+	if stats := recorder.Stats(); len(stats) != 0 {
+		t.Errorf("expected no stats after reset, got %+v", stats)
+	}
+}
+
+// TestToolServerConfigStats tests that observers registered before the
+// session starts are kept until the server is built.
+func TestToolServerConfigStats(t *testing.T) {
+	server := CreateToolServer("local", "1.0.0", nil)
+	if stats := server.Stats(); stats != nil {
+		t.Errorf("expected no stats before the server starts, got %+v", stats)
+	}
+	server.OnToolCall(func(ToolCallRecord) {})
+	if len(server.Observers) != 1 {
+		t.Errorf("expected the observer to be kept, got %d", len(server.Observers))
+	}
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Timeout limits each call of the tool to d. The handler's context is
+// canceled when the time is up, and the call fails even if the handler
+// ignores the cancellation and keeps running.
+func (b *ToolBuilder) Timeout(d time.Duration) *ToolBuilder {
+	b.timeout = d
+	return b
+}
+
+// runWithTimeout runs handler with a context that is canceled after timeout,
+// returning as soon as it is canceled.
+func runWithTimeout(ctx context.Context, name string, timeout time.Duration, handler ToolFunc, input map[string]interface{}) (*ToolResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		result *ToolResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		// The caller cannot recover a panic in this goroutine, so pass it on as an error.
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{nil, NewToolPanicError(name, r)}
+			}
+		}()
+		result, err := handler(ctx, input)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.result, out.err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("tool %s timed out after %v: %w", name, timeout, ctx.Err())
+		}
+		return nil, fmt.Errorf("tool %s canceled: %w", name, ctx.Err())
+	}
+}
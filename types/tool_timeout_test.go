@@ -0,0 +1,92 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestToolTimeout tests that a tool call fails when its timeout expires,
+// even if the handler ignores the cancellation.
+func TestToolTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	stuck, err := NewTool("stuck").
+		Description("Never returns").
+		Timeout(20 * time.Millisecond).
+		Handler(func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			<-release
+			return NewMcpToolResult(), nil
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build tool: %v", err)
+	}
+
+	start := time.Now()
+	_, err = stuck.Execute(context.Background(), map[string]interface{}{})
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "stuck timed out after 20ms") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the call to return at the timeout, took %v", elapsed)
+	}
+}
+
+// TestToolTimeoutCancelsContext tests that the handler's context is canceled
+// at the timeout and that fast calls are unaffected.
+func TestToolTimeoutCancelsContext(t *testing.T) {
+	canceled := make(chan error, 1)
+	simple := SimpleTool{
+		Name:        "wait",
+		Description: "Waits for cancellation unless fast is set",
+		Parameters: map[string]SimpleParam{
+			"fast": {Type: "boolean", Description: "Return immediately"},
+		},
+		Timeout: 20 * time.Millisecond,
+		Handler: func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			if fast, _ := input["fast"].(bool); fast {
+				return NewMcpToolResult(TextBlock{Type: "text", Text: "done"}), nil
+			}
+			<-ctx.Done()
+			canceled <- ctx.Err()
+			return nil, ctx.Err()
+		},
+	}
+	tool, err := simple.Build()
+	if err != nil {
+		t.Fatalf("failed to build tool: %v", err)
+	}
+
+	if result, err := tool.Execute(context.Background(), map[string]interface{}{"fast": true}); err != nil || len(result.Content) != 1 {
+		t.Errorf("expected a fast call to succeed, got %v, %v", result, err)
+	}
+
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{}); err == nil {
+		t.Error("expected a timeout error")
+	}
+	select {
+	case err := <-canceled:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected the handler context to hit its deadline, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the handler context to be canceled")
+	}
+
+	decorated, err := Tool("slow", "Slow tool").
+		Timeout(10 * time.Millisecond).
+		Handle(func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	if err != nil {
+		t.Fatalf("failed to build tool: %v", err)
+	}
+	if _, err := decorated.Execute(context.Background(), map[string]interface{}{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a decorated tool to time out, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit throttles the tool to n calls per interval using a token bucket:
+// bursts of up to n calls are allowed, and capacity refills evenly over
+// interval. A call over the limit does not run the handler; it returns an
+// error ToolResult asking Claude to wait before retrying.
+//
+// Cached results (see Cache) do not count against the limit.
+func (b *ToolBuilder) RateLimit(n int, interval time.Duration) *ToolBuilder {
+	b.rateLimit = &tokenBucket{capacity: n, interval: interval}
+	return b
+}
+
+// tokenBucket limits calls to capacity per interval.
+type tokenBucket struct {
+	capacity int
+	interval time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// validate checks the bucket's limit and prepares it for use.
+func (b *tokenBucket) validate() error {
+	if b.capacity <= 0 {
+		return fmt.Errorf("rate limit must allow at least one call, got %d", b.capacity)
+	}
+	if b.interval <= 0 {
+		return fmt.Errorf("rate limit interval must be positive, got %v", b.interval)
+	}
+	if b.now == nil {
+		b.now = time.Now
+	}
+	b.tokens = float64(b.capacity)
+	return nil
+}
+
+// take consumes a token, or returns how long until one is available.
+func (b *tokenBucket) take() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	rate := float64(b.capacity) / float64(b.interval) // tokens per nanosecond
+	if !b.last.IsZero() {
+		b.tokens = math.Min(float64(b.capacity), b.tokens+float64(now.Sub(b.last))*rate)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration(math.Ceil((1 - b.tokens) / rate))
+}
+
+// rateLimitedResult is the result returned to Claude when a call is throttled.
+func (b *tokenBucket) rateLimitedResult(toolName string, wait time.Duration) *ToolResult {
+	return NewErrorMcpToolResult(fmt.Sprintf(
+		"Rate limit exceeded for tool %s: at most %d calls per %v. Wait %v before calling it again, or continue without it.",
+		toolName, b.capacity, b.interval, time.Duration(math.Ceil(wait.Seconds()))*time.Second))
+}
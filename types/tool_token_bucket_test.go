@@ -0,0 +1,65 @@
+package types
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestToolRateLimit tests that calls over the limit return an error result until tokens refill.
+func TestToolRateLimit(t *testing.T) {
+	calls := 0
+	api, err := NewTool("search").
+		Description("Search an external API").
+		RateLimit(2, time.Minute).
+		Handler(func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			calls++
+			return NewMcpToolResult(TextBlock{Type: "text", Text: "results"}), nil
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build tool: %v", err)
+	}
+	bucket := api.(*tool).rateLimit
+	now := time.Now()
+	bucket.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if result, err := api.Execute(context.Background(), map[string]interface{}{}); err != nil || result.IsError {
+			t.Fatalf("call %d: expected success, got %v, %v", i+1, result, err)
+		}
+	}
+
+	result, err := api.Execute(context.Background(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("expected a rate limited call to return a result, got error %v", err)
+	}
+	text := result.Content[0].(TextBlock).Text
+	if !result.IsError || !strings.Contains(text, "Rate limit exceeded for tool search") || !strings.Contains(text, "Wait 30s") {
+		t.Errorf("unexpected rate limited result: %+v", result)
+	}
+	if calls != 2 {
+		t.Errorf("expected the handler to run twice, ran %d times", calls)
+	}
+
+	// Half the interval refills one of the two tokens.
+	now = now.Add(30 * time.Second)
+	if result, _ := api.Execute(context.Background(), map[string]interface{}{}); result.IsError {
+		t.Errorf("expected a refilled token to allow the call, got %+v", result)
+	}
+	if result, _ := api.Execute(context.Background(), map[string]interface{}{}); !result.IsError {
+		t.Error("expected the next call to be limited again")
+	}
+}
+
+// TestToolRateLimitValidation tests that invalid limits fail the build.
+func TestToolRateLimitValidation(t *testing.T) {
+	handler := func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) { return NewMcpToolResult(), nil }
+	if _, err := NewTool("a").Description("a").Handler(handler).RateLimit(0, time.Second).Build(); err == nil {
+		t.Error("expected error for a zero limit")
+	}
+	if _, err := NewTool("a").Description("a").Handler(handler).RateLimit(1, 0).Build(); err == nil {
+		t.Error("expected error for a zero interval")
+	}
+}
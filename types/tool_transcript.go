@@ -0,0 +1,298 @@
+package types
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ToolTranscriptEntry records one tool call: its input, and the result or
+// error it returned.
+type ToolTranscriptEntry struct {
+	Time     time.Time              `json:"time"`
+	Server   string                 `json:"server"`
+	Tool     string                 `json:"tool"`
+	Input    map[string]interface{} `json:"input"`
+	Result   *ToolResult            `json:"result,omitempty"`
+	Error    string                 `json:"error,omitempty"` // Error returned instead of a result
+	Duration time.Duration          `json:"duration"`
+}
+
+// UnmarshalJSON decodes an entry, including the content blocks of its result.
+func (e *ToolTranscriptEntry) UnmarshalJSON(data []byte) error {
+	type plain ToolTranscriptEntry
+	var raw struct {
+		plain
+		Result *struct {
+			Content []map[string]interface{} `json:"content"`
+			IsError bool                     `json:"isError"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*e = ToolTranscriptEntry(raw.plain)
+	if raw.Result != nil {
+		e.Result = &ToolResult{IsError: raw.Result.IsError}
+		for _, block := range raw.Result.Content {
+			e.Result.Content = append(e.Result.Content, transcriptBlock(block))
+		}
+	}
+	return nil
+}
+
+// transcriptBlock converts a decoded content block back to the type tools
+// return. Blocks other than text are kept as a RawContentBlock, which
+// encodes the same way.
+func transcriptBlock(block map[string]interface{}) ContentBlock {
+	if block["type"] == "text" {
+		if text, ok := block["text"].(string); ok && len(block) == 2 {
+			return TextBlock{Type: "text", Text: text}
+		}
+	}
+	return RawContentBlock(block)
+}
+
+// ToolTranscriptStore stores recorded tool calls.
+type ToolTranscriptStore interface {
+	// Append adds an entry to the transcript.
+	Append(entry ToolTranscriptEntry) error
+	// Entries returns the recorded entries in the order they were appended.
+	Entries() ([]ToolTranscriptEntry, error)
+}
+
+// MemoryTranscript is a ToolTranscriptStore that keeps entries in memory.
+// It is safe for concurrent use.
+type MemoryTranscript struct {
+	mu      sync.Mutex
+	entries []ToolTranscriptEntry
+}
+
+// NewMemoryTranscript creates an empty in-memory transcript.
+func NewMemoryTranscript() *MemoryTranscript {
+	return &MemoryTranscript{}
+}
+
+// Append adds an entry to the transcript.
+func (t *MemoryTranscript) Append(entry ToolTranscriptEntry) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = append(t.entries, entry)
+	return nil
+}
+
+// Entries returns a copy of the recorded entries.
+func (t *MemoryTranscript) Entries() ([]ToolTranscriptEntry, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]ToolTranscriptEntry(nil), t.entries...), nil
+}
+
+// FileTranscript is a ToolTranscriptStore that appends entries to a file as
+// JSON lines, so a session can be replayed by a later run. It is safe for
+// concurrent use within one process.
+type FileTranscript struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileTranscript returns a transcript stored at path. The file is
+// created on the first Append.
+func NewFileTranscript(path string) *FileTranscript {
+	return &FileTranscript{path: path}
+}
+
+// Append writes an entry as one line at the end of the file.
+func (t *FileTranscript) Append(entry ToolTranscriptEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode transcript entry: %w", err)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f, err := os.OpenFile(t.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open transcript: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write transcript: %w", err)
+	}
+	return f.Close()
+}
+
+// Entries reads the entries in the file.
+func (t *FileTranscript) Entries() ([]ToolTranscriptEntry, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f, err := os.Open(t.path)
+	if err != nil {
+		return nil, fmt.Errorf("open transcript: %w", err)
+	}
+	defer f.Close()
+
+	var entries []ToolTranscriptEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry ToolTranscriptEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("transcript line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read transcript: %w", err)
+	}
+	return entries, nil
+}
+
+// RecordToolCalls returns middleware that appends every call it wraps to
+// store, for use with ToolServerConfig.Use or ToolManager.Use. A failure to
+// store an entry does not fail the call.
+//
+// Example:
+//
+//	transcript := types.NewFileTranscript("session-tools.jsonl")
+//	server.Use(types.RecordToolCalls(transcript))
+func RecordToolCalls(store ToolTranscriptStore) ToolMiddleware {
+	return func(next ToolFunc) ToolFunc {
+		return func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			start := time.Now()
+			result, err := next(ctx, input)
+
+			call, _ := ToolCallFromContext(ctx)
+			entry := ToolTranscriptEntry{
+				Time:     start,
+				Server:   call.Server,
+				Tool:     call.Tool,
+				Input:    copyInput(input),
+				Result:   result,
+				Duration: time.Since(start),
+			}
+			if err != nil {
+				entry.Error = err.Error()
+			}
+			_ = store.Append(entry)
+			return result, err
+		}
+	}
+}
+
+// copyInput returns a shallow copy of a tool input.
+func copyInput(input map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(input))
+	for key, value := range input {
+		copied[key] = value
+	}
+	return copied
+}
+
+// ToolReplayer returns recorded results instead of running tool handlers,
+// so an agent session can be re-run deterministically for debugging. A call
+// is matched to a recorded call of the same server and tool with the same
+// input; repeated calls with the same input replay their results in the
+// order they were recorded.
+//
+// It is safe for concurrent use.
+type ToolReplayer struct {
+	// Passthrough runs the handler of a call that matches no recorded call.
+	// When false, such a call returns an error result. Set it before use.
+	Passthrough bool
+
+	mu      sync.Mutex
+	pending map[string][]ToolTranscriptEntry // call key -> entries not yet replayed
+}
+
+// NewToolReplayer creates a replayer for the calls recorded in store.
+//
+// Example:
+//
+//	replayer, err := types.NewToolReplayer(types.NewFileTranscript("session-tools.jsonl"))
+//	...
+//	server.Use(replayer.Middleware())
+func NewToolReplayer(store ToolTranscriptStore) (*ToolReplayer, error) {
+	entries, err := store.Entries()
+	if err != nil {
+		return nil, err
+	}
+	r := &ToolReplayer{pending: make(map[string][]ToolTranscriptEntry)}
+	for _, entry := range entries {
+		key, err := replayKey(entry.Server, entry.Tool, entry.Input)
+		if err != nil {
+			return nil, err
+		}
+		r.pending[key] = append(r.pending[key], entry)
+	}
+	return r, nil
+}
+
+// Middleware returns middleware that replays recorded calls. Add it before
+// other middleware, so recorded calls skip them as well as the handler.
+func (r *ToolReplayer) Middleware() ToolMiddleware {
+	return func(next ToolFunc) ToolFunc {
+		return func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+			call, _ := ToolCallFromContext(ctx)
+			entry, ok := r.next(call.Server, call.Tool, input)
+			switch {
+			case ok && entry.Error != "":
+				return nil, errors.New(entry.Error)
+			case ok:
+				return entry.Result, nil
+			case r.Passthrough:
+				return next(ctx, input)
+			default:
+				return NewErrorMcpToolResult(fmt.Sprintf("No recorded call of tool %s matches this input.", call.Tool)), nil
+			}
+		}
+	}
+}
+
+// Remaining returns the number of recorded calls not replayed yet. A
+// replayed session that diverged from the recording leaves some behind.
+func (r *ToolReplayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, entries := range r.pending {
+		n += len(entries)
+	}
+	return n
+}
+
+// next removes and returns the first recorded call matching a call.
+func (r *ToolReplayer) next(server, tool string, input map[string]interface{}) (ToolTranscriptEntry, bool) {
+	key, err := replayKey(server, tool, input)
+	if err != nil {
+		return ToolTranscriptEntry{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := r.pending[key]
+	if len(entries) == 0 {
+		return ToolTranscriptEntry{}, false
+	}
+	r.pending[key] = entries[1:]
+	return entries[0], true
+}
+
+// replayKey identifies calls with the same server, tool and input. Inputs
+// are compared in their JSON form, so recorded numbers match live ones.
+func replayKey(server, tool string, input map[string]interface{}) (string, error) {
+	if input == nil {
+		input = map[string]interface{}{}
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("encode input of tool %s: %w", tool, err)
+	}
+	return server + "\x00" + tool + "\x00" + string(data), nil
+}
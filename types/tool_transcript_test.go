@@ -0,0 +1,84 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestToolTranscriptRecordReplay tests that recorded calls are replayed
+// from a file without running the handler.
+func TestToolTranscriptRecordReplay(t *testing.T) {
+	calls := 0
+	handler := func(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+		calls++
+		if input["city"] == "Atlantis" {
+			return nil, errors.New("unknown city")
+		}
+		return NewMcpToolResult(TextBlock{Type: "text", Text: input["city"].(string) + ": sunny"}), nil
+	}
+	ctx := ContextWithToolCall(context.Background(), ToolCallInfo{Server: "weather", Tool: "forecast"})
+
+	transcript := NewFileTranscript(filepath.Join(t.TempDir(), "tools.jsonl"))
+	record := RecordToolCalls(transcript)(handler)
+	for _, city := range []string{"Paris", "Atlantis", "Paris"} {
+		_, _ = record(ctx, map[string]interface{}{"city": city, "days": 3})
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 handler calls while recording, got %d", calls)
+	}
+
+	entries, err := transcript.Entries()
+	if err != nil {
+		t.Fatalf("Entries failed: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Server != "weather" || entries[0].Tool != "forecast" || entries[1].Error != "unknown city" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if text := entries[0].Result.Content[0].(TextBlock).Text; text != "Paris: sunny" {
+		t.Errorf("expected the decoded text block, got %q", text)
+	}
+
+	replayer, err := NewToolReplayer(transcript)
+	if err != nil {
+		t.Fatalf("NewToolReplayer failed: %v", err)
+	}
+	replay := replayer.Middleware()(handler)
+	calls = 0
+
+	result, err := replay(ctx, map[string]interface{}{"days": 3.0, "city": "Paris"})
+	if err != nil || result.Content[0].(TextBlock).Text != "Paris: sunny" {
+		t.Errorf("expected the recorded result, got %+v, %v", result, err)
+	}
+	if _, err := replay(ctx, map[string]interface{}{"city": "Atlantis", "days": 3}); err == nil || err.Error() != "unknown city" {
+		t.Errorf("expected the recorded error, got %v", err)
+	}
+	if replayer.Remaining() != 1 {
+		t.Errorf("expected 1 call left to replay, got %d", replayer.Remaining())
+	}
+
+	result, _ = replay(ctx, map[string]interface{}{"city": "Rome", "days": 3})
+	if !result.IsError {
+		t.Errorf("expected an error result for an unrecorded call, got %+v", result)
+	}
+	if calls != 0 {
+		t.Errorf("expected no handler calls while replaying, got %d", calls)
+	}
+
+	replayer.Passthrough = true
+	if result, _ := replay(ctx, map[string]interface{}{"city": "Rome", "days": 3}); result.IsError || calls != 1 {
+		t.Errorf("expected an unrecorded call to run with Passthrough, got %+v", result)
+	}
+}
+
+// TestMemoryTranscript tests that the in-memory store returns a copy.
+func TestMemoryTranscript(t *testing.T) {
+	transcript := NewMemoryTranscript()
+	_ = transcript.Append(ToolTranscriptEntry{Tool: "a"})
+	entries, _ := transcript.Entries()
+	entries[0].Tool = "changed"
+	if again, _ := transcript.Entries(); len(again) != 1 || again[0].Tool != "a" {
+		t.Errorf("expected the stored entries to be unchanged, got %+v", again)
+	}
+}
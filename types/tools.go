@@ -3,11 +3,13 @@ package types
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 )
 
 // McpTool represents a tool that can be executed by Claude.
@@ -56,6 +58,13 @@ type ToolBuilder struct {
 	handler     ToolFunc
 	validator   func(map[string]interface{}) error
 	enums       map[string][]interface{}
+	schema      map[string]interface{} // Replaces the schema built from params when set
+	timeout     time.Duration          // Limits each call when positive
+	cacheTTL    time.Duration          // Caches results when positive
+	cacheKey    func(map[string]interface{}) string
+	rateLimit   *tokenBucket      // Throttles calls when set
+	resultLimit *ResultLimit      // Caps result size when set
+	concurrency *ConcurrencyLimit // Bounds concurrent calls when set
 }
 
 // ToolParam represents a parameter definition for a tool.
@@ -231,7 +240,28 @@ func (b *ToolBuilder) Build() (McpTool, error) {
 		return nil, fmt.Errorf("tool handler is required")
 	}
 
-	schema := b.buildJSONSchema()
+	if b.rateLimit != nil {
+		if err := b.rateLimit.validate(); err != nil {
+			return nil, fmt.Errorf("tool %s: %w", b.name, err)
+		}
+	}
+	if b.resultLimit != nil {
+		if err := b.resultLimit.Validate(); err != nil {
+			return nil, fmt.Errorf("tool %s: %w", b.name, err)
+		}
+	}
+	var limiter *ConcurrencyLimiter
+	if b.concurrency != nil {
+		var err error
+		if limiter, err = NewConcurrencyLimiter(*b.concurrency); err != nil {
+			return nil, fmt.Errorf("tool %s: %w", b.name, err)
+		}
+	}
+
+	schema := b.schema
+	if schema == nil {
+		schema = b.buildJSONSchema()
+	}
 
 	return &tool{
 		name:        b.name,
@@ -239,6 +269,11 @@ func (b *ToolBuilder) Build() (McpTool, error) {
 		inputSchema: schema,
 		handler:     b.handler,
 		validator:   b.validator,
+		timeout:     b.timeout,
+		cache:       newToolCache(b.cacheTTL, b.cacheKey),
+		rateLimit:   b.rateLimit,
+		resultLimit: b.resultLimit,
+		concurrency: limiter,
 	}, nil
 }
 
@@ -313,6 +348,11 @@ type tool struct {
 	inputSchema map[string]interface{}
 	handler     ToolFunc
 	validator   func(map[string]interface{}) error
+	timeout     time.Duration
+	cache       *toolCache
+	rateLimit   *tokenBucket
+	resultLimit *ResultLimit
+	concurrency *ConcurrencyLimiter
 }
 
 func (t *tool) Name() string {
@@ -340,7 +380,43 @@ func (t *tool) Execute(ctx context.Context, input map[string]interface{}) (*Tool
 		}
 	}
 
-	return t.handler(ctx, input)
+	if t.cache != nil {
+		return t.cache.execute(ctx, input, func() (*ToolResult, error) {
+			return t.run(ctx, input)
+		})
+	}
+	return t.run(ctx, input)
+}
+
+// run calls the handler, limited by the tool's rate limit, concurrency
+// limit, timeout and result size limit.
+func (t *tool) run(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+	if t.rateLimit != nil {
+		if ok, wait := t.rateLimit.take(); !ok {
+			return t.rateLimit.rateLimitedResult(t.name, wait), nil
+		}
+	}
+	if t.concurrency != nil {
+		release, err := t.concurrency.Acquire(ctx)
+		if errors.Is(err, ErrToolBusy) {
+			return t.concurrency.busyResult(t.name), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+	var result *ToolResult
+	var err error
+	if t.timeout > 0 {
+		result, err = runWithTimeout(ctx, t.name, t.timeout, t.handler, input)
+	} else {
+		result, err = t.handler(ctx, input)
+	}
+	if err != nil || t.resultLimit == nil {
+		return result, err
+	}
+	return t.resultLimit.Apply(t.name, result), nil
 }
 
 // validateInput validates input against JSON schema.
@@ -469,6 +545,10 @@ func NewErrorMcpToolResult(message string) *ToolResult {
 
 // NewFileReadTool creates a file reading tool.
 // Reads content from a file at the specified path.
+//
+// Deprecated: Its ".." check does not stop absolute paths or symbolic links
+// from reaching any file. Use package toolkits/fs, which confines paths to a
+// root directory.
 func NewFileReadTool() (McpTool, error) {
 	return NewTool("read_file").
 		Description("Read content from a file").
@@ -500,6 +580,10 @@ func NewFileReadTool() (McpTool, error) {
 
 // NewFileWriteTool creates a file writing tool.
 // Writes content to a file at the specified path.
+//
+// Deprecated: Its ".." check does not stop absolute paths or symbolic links
+// from reaching any file. Use package toolkits/fs, which confines paths to a
+// root directory.
 func NewFileWriteTool() (McpTool, error) {
 	return NewTool("write_file").
 		Description("Write content to a file").
@@ -681,8 +765,9 @@ func NewCalculatorToolkit() ([]McpTool, error) {
 
 // ToolManager manages a collection of tools and can create MCP servers.
 type ToolManager struct {
-	tools map[string]McpTool
-	mu    sync.RWMutex
+	tools      map[string]McpTool
+	middleware []ToolMiddleware
+	mu         sync.RWMutex
 }
 
 // NewToolManager creates a new tool manager.
@@ -778,15 +863,22 @@ func (m *ToolManager) Unregister(name string) error {
 // This is a convenience method for creating servers from the tool manager.
 func (m *ToolManager) CreateServer(name, version string) *ToolServerConfig {
 	tools := m.List()
-	return CreateToolServer(name, version, tools)
+	server := CreateToolServer(name, version, tools)
+	m.mu.RLock()
+	server.Middleware = append(server.Middleware, m.middleware...)
+	m.mu.RUnlock()
+	return server
 }
 
 // ToolServerConfig is the configuration for an SDK MCP server.
 type ToolServerConfig struct {
-	Type     string      `json:"type"`
-	Name     string      `json:"name"`
-	Version  string      `json:"version,omitempty"`
-	Instance interface{} `json:"instance"`
+	Type       string             `json:"type"`
+	Name       string             `json:"name"`
+	Version    string             `json:"version,omitempty"`
+	Instance   interface{}        `json:"instance"`
+	Resources  []McpResource      `json:"-"` // Resources served with the tools
+	Middleware []ToolMiddleware   `json:"-"` // Wraps every tool call, see Use
+	Observers  []ToolCallObserver `json:"-"` // Receive every tool call, see OnToolCall
 }
 
 // CreateToolServer creates an SDK MCP server configuration.
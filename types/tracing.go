@@ -0,0 +1,52 @@
+package types
+
+import "context"
+
+// Tracer creates the SDK's spans: a claude.query span per query, with child
+// spans for its tool uses, control requests, hook callbacks and SDK MCP tool
+// calls. Set it with WithTracer; the oteltracing module adapts an
+// OpenTelemetry TracerProvider to it.
+//
+// The methods are called from the SDK's goroutines, so implementations must
+// be safe for concurrent use and return quickly.
+type Tracer interface {
+	// Start starts a span as a child of the span in ctx, if any, and returns
+	// ctx with the span.
+	Start(ctx context.Context, name string, attrs ...SpanAttribute) (context.Context, Span)
+	// ContextWithSpan returns ctx with span, so that the spans started from
+	// it are children of span.
+	ContextWithSpan(ctx context.Context, span Span) context.Context
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...SpanAttribute)
+	// End ends the span, as failed with err if it is not nil.
+	End(err error)
+}
+
+// SpanAttribute is an attribute of a span. Value is a string, int, float64
+// or bool.
+type SpanAttribute struct {
+	Key   string
+	Value interface{}
+}
+
+// NopTracer is a Tracer that records nothing, used when no tracer is set.
+type NopTracer struct{}
+
+// Start returns ctx and a span that records nothing.
+func (NopTracer) Start(ctx context.Context, name string, attrs ...SpanAttribute) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+// ContextWithSpan returns ctx.
+func (NopTracer) ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return ctx
+}
+
+// nopSpan is the span of NopTracer.
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(...SpanAttribute) {}
+func (nopSpan) End(error)                      {}
@@ -0,0 +1,231 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TokenUsage counts the tokens of one or more API calls.
+type TokenUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// Total returns the number of tokens counted, input and output.
+func (u TokenUsage) Total() int {
+	return u.InputTokens + u.OutputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+}
+
+// add adds the tokens of o.
+func (u *TokenUsage) add(o TokenUsage) {
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.CacheCreationInputTokens += o.CacheCreationInputTokens
+	u.CacheReadInputTokens += o.CacheReadInputTokens
+}
+
+// TokenAlertFunc is called when the tokens used by a session reach a
+// threshold, with the tokens used so far.
+type TokenAlertFunc func(used TokenUsage)
+
+// TokenAlert calls Func once when the tokens used by a session, counted by
+// TokenUsage.Total over its assistant turns, reach Threshold.
+type TokenAlert struct {
+	Threshold int
+	Func      TokenAlertFunc
+}
+
+// ValidateTokenAlerts checks that the token alerts have a positive
+// threshold and a function.
+func (o *ClaudeAgentOptions) ValidateTokenAlerts() error {
+	for _, alert := range o.TokenAlerts {
+		if alert.Threshold <= 0 {
+			return fmt.Errorf("invalid token alert threshold %d: must be positive", alert.Threshold)
+		}
+		if alert.Func == nil {
+			return fmt.Errorf("token alert at %d tokens has no function", alert.Threshold)
+		}
+	}
+	return nil
+}
+
+// ModelUsage is the usage of one model as reported in
+// ResultMessage.ModelUsage.
+type ModelUsage struct {
+	InputTokens              int     `json:"inputTokens"`
+	OutputTokens             int     `json:"outputTokens"`
+	CacheReadInputTokens     int     `json:"cacheReadInputTokens"`
+	CacheCreationInputTokens int     `json:"cacheCreationInputTokens"`
+	WebSearchRequests        int     `json:"webSearchRequests"`
+	CostUSD                  float64 `json:"costUSD"`
+	ContextWindow            int     `json:"contextWindow,omitempty"`
+}
+
+// TurnUsage is the usage of one assistant turn, a single API call that may
+// span several AssistantMessages sharing a message ID.
+type TurnUsage struct {
+	Turn            int        `json:"turn"` // Position in the session, from 1
+	MessageID       string     `json:"message_id,omitempty"`
+	Model           string     `json:"model"`
+	ParentToolUseID string     `json:"parent_tool_use_id,omitempty"` // Set for the turns of subagents
+	Usage           TokenUsage `json:"usage"`
+	ToolCalls       []string   `json:"tool_calls,omitempty"` // Names of the tools the turn called
+}
+
+// ModelUsageSummary is the usage of one model in a UsageReport.
+type ModelUsageSummary struct {
+	TokenUsage
+	Turns   int     `json:"turns"`
+	CostUSD float64 `json:"cost_usd"` // As reported by the latest result; 0 before the first
+}
+
+// ToolUsage is the usage attributed to one tool in a UsageReport.
+type ToolUsage struct {
+	Calls int `json:"calls"`
+	// OutputTokens are the output tokens of the turns that called the tool,
+	// shared evenly among each turn's tool calls. They estimate what writing
+	// the tool inputs cost.
+	OutputTokens int `json:"output_tokens"`
+}
+
+// UsageReport breaks down the token usage and cost of a session by turn,
+// model and tool.
+type UsageReport struct {
+	SessionID    string                       `json:"session_id,omitempty"` // From the latest result
+	Total        TokenUsage                   `json:"total"`
+	TotalCostUSD float64                      `json:"total_cost_usd"` // As reported by the latest result
+	Turns        []TurnUsage                  `json:"turns"`
+	ByModel      map[string]ModelUsageSummary `json:"by_model"`
+	ByTool       map[string]ToolUsage         `json:"by_tool"`
+}
+
+// UsageRecorder builds a UsageReport from the messages of a session. Pass it
+// every message received; messages without usage are ignored.
+//
+// It is safe for concurrent use.
+type UsageRecorder struct {
+	mu         sync.Mutex
+	sessionID  string
+	turns      []TurnUsage
+	byID       map[string]int // Index in turns by message ID
+	costUSD    float64
+	modelUsage map[string]ModelUsage
+}
+
+// NewUsageRecorder creates a recorder with no usage.
+func NewUsageRecorder() *UsageRecorder {
+	r := &UsageRecorder{}
+	r.Reset()
+	return r
+}
+
+// Record adds the usage of a message. The CLI sends each content block of a
+// turn as its own AssistantMessage with the turn's usage so far; these are
+// combined into one turn by message ID.
+func (r *UsageRecorder) Record(msg Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch m := msg.(type) {
+	case *AssistantMessage:
+		r.recordAssistant(m)
+	case *ResultMessage:
+		if m.SessionID != "" {
+			r.sessionID = m.SessionID
+		}
+		if m.TotalCostUSD != nil {
+			r.costUSD = *m.TotalCostUSD
+		}
+		if m.ModelUsage != nil {
+			r.modelUsage = m.ModelUsage
+		}
+	}
+}
+
+// recordAssistant adds the usage and tool calls of an assistant message.
+func (r *UsageRecorder) recordAssistant(m *AssistantMessage) {
+	var turn *TurnUsage
+	if i, ok := r.byID[m.MessageID]; ok && m.MessageID != "" {
+		turn = &r.turns[i]
+	} else {
+		r.turns = append(r.turns, TurnUsage{Turn: len(r.turns) + 1, MessageID: m.MessageID, Model: m.Model})
+		turn = &r.turns[len(r.turns)-1]
+		if m.MessageID != "" {
+			r.byID[m.MessageID] = len(r.turns) - 1
+		}
+		if m.ParentToolUseID != nil {
+			turn.ParentToolUseID = *m.ParentToolUseID
+		}
+	}
+	if m.Usage != nil {
+		turn.Usage = *m.Usage
+	}
+	for _, block := range ToolUses(m) {
+		turn.ToolCalls = append(turn.ToolCalls, block.Name)
+	}
+}
+
+// Report returns the usage recorded so far.
+func (r *UsageRecorder) Report() UsageReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := UsageReport{
+		SessionID:    r.sessionID,
+		TotalCostUSD: r.costUSD,
+		Turns:        make([]TurnUsage, len(r.turns)),
+		ByModel:      make(map[string]ModelUsageSummary),
+		ByTool:       make(map[string]ToolUsage),
+	}
+	for i, turn := range r.turns {
+		turn.ToolCalls = append([]string(nil), turn.ToolCalls...)
+		report.Turns[i] = turn
+		report.Total.add(turn.Usage)
+
+		model := report.ByModel[turn.Model]
+		model.add(turn.Usage)
+		model.Turns++
+		report.ByModel[turn.Model] = model
+
+		for j, name := range turn.ToolCalls {
+			tool := report.ByTool[name]
+			tool.Calls++
+			// Share the output tokens so that they add up to the turn's.
+			n := len(turn.ToolCalls)
+			tool.OutputTokens += turn.Usage.OutputTokens*(j+1)/n - turn.Usage.OutputTokens*j/n
+			report.ByTool[name] = tool
+		}
+	}
+	for name, usage := range r.modelUsage {
+		model := report.ByModel[name]
+		model.CostUSD = usage.CostUSD
+		report.ByModel[name] = model
+	}
+	return report
+}
+
+// Total returns the tokens used by the turns recorded so far, as
+// Report().Total does.
+func (r *UsageRecorder) Total() TokenUsage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var total TokenUsage
+	for _, turn := range r.turns {
+		total.add(turn.Usage)
+	}
+	return total
+}
+
+// Reset clears the recorded usage.
+func (r *UsageRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessionID = ""
+	r.turns = nil
+	r.byID = make(map[string]int)
+	r.costUSD = 0
+	r.modelUsage = nil
+}
@@ -0,0 +1,77 @@
+package types
+
+import "testing"
+
+// TestUsageRecorder tests the usage breakdown by turn, model and tool
+func TestUsageRecorder(t *testing.T) {
+	lines := []string{
+		// One turn sent as two messages, the second with the final usage.
+		`{"type":"assistant","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"Looking"}],"usage":{"input_tokens":100,"output_tokens":5,"cache_read_input_tokens":50}}}`,
+		`{"type":"assistant","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"tool_use","id":"t1","name":"Read","input":{}},{"type":"tool_use","id":"t2","name":"Grep","input":{}}],"usage":{"input_tokens":100,"output_tokens":31,"cache_read_input_tokens":50}}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}`,
+		`{"type":"assistant","message":{"id":"msg_2","role":"assistant","model":"claude-haiku-4-5","content":[{"type":"tool_use","id":"t3","name":"Read","input":{}}],"usage":{"input_tokens":20,"output_tokens":10}},"parent_tool_use_id":"t2"}`,
+		`{"type":"result","subtype":"success","session_id":"s1","total_cost_usd":0.25,"modelUsage":{"claude-sonnet-4-5":{"inputTokens":100,"outputTokens":31,"costUSD":0.2},"claude-haiku-4-5":{"inputTokens":20,"outputTokens":10,"costUSD":0.05}}}`,
+	}
+	recorder := NewUsageRecorder()
+	for _, line := range lines {
+		msg, err := UnmarshalMessage([]byte(line))
+		if err != nil {
+			t.Fatalf("UnmarshalMessage(%s) error = %v", line, err)
+		}
+		recorder.Record(msg)
+	}
+
+	report := recorder.Report()
+	if len(report.Turns) != 2 {
+		t.Fatalf("Turns = %+v, want 2 turns", report.Turns)
+	}
+	first := report.Turns[0]
+	if first.Turn != 1 || first.MessageID != "msg_1" || first.Usage.OutputTokens != 31 || len(first.ToolCalls) != 2 {
+		t.Errorf("Turns[0] = %+v, want msg_1 with 31 output tokens and 2 tool calls", first)
+	}
+	if second := report.Turns[1]; second.ParentToolUseID != "t2" || second.Model != "claude-haiku-4-5" {
+		t.Errorf("Turns[1] = %+v, want subagent turn of claude-haiku-4-5", second)
+	}
+	if want := (TokenUsage{InputTokens: 120, OutputTokens: 41, CacheReadInputTokens: 50}); report.Total != want {
+		t.Errorf("Total = %+v, want %+v", report.Total, want)
+	}
+	if report.SessionID != "s1" {
+		t.Errorf("SessionID = %q, want s1", report.SessionID)
+	}
+	if total := recorder.Total(); total != report.Total {
+		t.Errorf("Total() = %+v, want %+v", total, report.Total)
+	}
+	if report.TotalCostUSD != 0.25 {
+		t.Errorf("TotalCostUSD = %v, want 0.25", report.TotalCostUSD)
+	}
+	if sonnet := report.ByModel["claude-sonnet-4-5"]; sonnet.Turns != 1 || sonnet.InputTokens != 100 || sonnet.CostUSD != 0.2 {
+		t.Errorf("ByModel[sonnet] = %+v", sonnet)
+	}
+	// Read: half of turn 1's 31 output tokens (15) plus all 10 of turn 2.
+	if read := report.ByTool["Read"]; read.Calls != 2 || read.OutputTokens != 25 {
+		t.Errorf("ByTool[Read] = %+v, want 2 calls and 25 output tokens", read)
+	}
+	if grep := report.ByTool["Grep"]; grep.Calls != 1 || grep.OutputTokens != 16 {
+		t.Errorf("ByTool[Grep] = %+v, want 1 call and 16 output tokens", grep)
+	}
+
+	recorder.Reset()
+	if report := recorder.Report(); len(report.Turns) != 0 || report.TotalCostUSD != 0 {
+		t.Errorf("Report() after Reset = %+v, want empty", report)
+	}
+}
+
+// TestValidateTokenAlerts tests that token alerts need a positive threshold
+// and a function.
+func TestValidateTokenAlerts(t *testing.T) {
+	alert := func(TokenUsage) {}
+	if err := NewClaudeAgentOptions().WithTokenAlert(1000, alert).ValidateTokenAlerts(); err != nil {
+		t.Errorf("ValidateTokenAlerts() = %v, want nil", err)
+	}
+	if err := NewClaudeAgentOptions().WithTokenAlert(0, alert).ValidateTokenAlerts(); err == nil {
+		t.Error("ValidateTokenAlerts() = nil, want an error for a zero threshold")
+	}
+	if err := NewClaudeAgentOptions().WithTokenAlert(1000, nil).ValidateTokenAlerts(); err == nil {
+		t.Error("ValidateTokenAlerts() = nil, want an error for a nil function")
+	}
+}
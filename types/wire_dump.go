@@ -0,0 +1,39 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WireDirection tells whether a line was sent to or received from the CLI.
+type WireDirection string
+
+const (
+	WireOutbound WireDirection = "outbound" // Sent to the CLI's stdin
+	WireInbound  WireDirection = "inbound"  // Received from the CLI's stdout
+)
+
+// RedactionWireDump is a line written to a wire dump.
+const RedactionWireDump RedactionLocation = "wire_dump"
+
+// WireRecord is a line of a wire dump, see WithWireDump.
+type WireRecord struct {
+	Time      time.Time       `json:"time"`
+	Direction WireDirection   `json:"direction"`
+	Message   json.RawMessage `json:"message,omitempty"` // The JSON line, with its secrets redacted
+	Raw       string          `json:"raw,omitempty"`     // A line that is not JSON, with its secrets redacted
+}
+
+// ReplayTiming is how fast a wire dump is replayed.
+type ReplayTiming string
+
+const (
+	ReplayOriginalTiming ReplayTiming = "original" // With the delays between the lines of the recording
+	ReplayFast           ReplayTiming = "fast"     // As fast as the SDK reads them
+)
+
+// Replay drives the SDK from a wire dump instead of the CLI, see WithReplay.
+type Replay struct {
+	Path   string
+	Timing ReplayTiming
+}
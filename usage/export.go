@@ -0,0 +1,213 @@
+// Package usage exports the token usage and cost of sessions as records
+// for chargeback, in CSV or JSON Lines.
+//
+// A Record is built from the UsageReport of a session, attributed to a user
+// and tenant, and written by an Exporter to a file, an object store or any
+// Writer:
+//
+//	exporter := usage.NewExporter(usage.CSV, usage.NewFileWriter("usage.csv"))
+//
+//	record := usage.NewRecord(client.UsageReport())
+//	record.User, record.Tenant = "bob", "acme"
+//	if err := exporter.Export(ctx, record); err != nil {
+//	    log.Printf("usage export: %v", err)
+//	}
+//
+// Exporters and the writers of this package are safe for concurrent use.
+package usage
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// Format is the encoding of exported records.
+type Format string
+
+const (
+	CSV  Format = "csv"  // One row per model of each record, with a header
+	JSON Format = "json" // One record per line
+)
+
+// Record is the usage of a session, for chargeback.
+type Record struct {
+	Time      time.Time        `json:"time"` // When the record was made
+	User      string           `json:"user,omitempty"`
+	Tenant    string           `json:"tenant,omitempty"`
+	SessionID string           `json:"session_id,omitempty"`
+	Turns     int              `json:"turns"`
+	Tokens    types.TokenUsage `json:"tokens"`
+	CostUSD   float64          `json:"cost_usd"`   // As reported by the latest result of the session
+	Models    []ModelRecord    `json:"models"`     // Sorted by model
+	ToolCalls map[string]int   `json:"tool_calls"` // Calls by tool
+}
+
+// ModelRecord is the usage of one model in a Record.
+type ModelRecord struct {
+	Model     string           `json:"model"`
+	Turns     int              `json:"turns"`
+	Tokens    types.TokenUsage `json:"tokens"`
+	CostUSD   float64          `json:"cost_usd"`
+	ToolCalls map[string]int   `json:"tool_calls"` // Calls by tool, in the turns of this model
+}
+
+// NewRecord returns the record of a session's usage report, such as
+// Client.UsageReport, made now. Set its User and Tenant to attribute it.
+func NewRecord(report types.UsageReport) Record {
+	record := Record{
+		Time:      time.Now().UTC(),
+		SessionID: report.SessionID,
+		Turns:     len(report.Turns),
+		Tokens:    report.Total,
+		CostUSD:   report.TotalCostUSD,
+		ToolCalls: make(map[string]int),
+	}
+
+	toolCalls := make(map[string]map[string]int) // By model, then tool
+	for _, turn := range report.Turns {
+		for _, name := range turn.ToolCalls {
+			if toolCalls[turn.Model] == nil {
+				toolCalls[turn.Model] = make(map[string]int)
+			}
+			toolCalls[turn.Model][name]++
+			record.ToolCalls[name]++
+		}
+	}
+	for model, summary := range report.ByModel {
+		calls := toolCalls[model]
+		if calls == nil {
+			calls = make(map[string]int)
+		}
+		record.Models = append(record.Models, ModelRecord{
+			Model:     model,
+			Turns:     summary.Turns,
+			Tokens:    summary.TokenUsage,
+			CostUSD:   summary.CostUSD,
+			ToolCalls: calls,
+		})
+	}
+	sort.Slice(record.Models, func(i, j int) bool { return record.Models[i].Model < record.Models[j].Model })
+	return record
+}
+
+// csvHeader is the header row of the CSV format.
+var csvHeader = []string{
+	"time", "user", "tenant", "session_id", "model", "turns",
+	"input_tokens", "output_tokens", "cache_creation_input_tokens", "cache_read_input_tokens",
+	"cost_usd", "tool_calls",
+}
+
+// Encode writes records to w in format. The CSV header is written only if
+// header is true, so that batches can be appended to a file; records
+// without models are written as one row without a model.
+func Encode(w io.Writer, format Format, records []Record, header bool) error {
+	switch format {
+	case CSV:
+		return encodeCSV(w, records, header)
+	case JSON:
+		encoder := json.NewEncoder(w)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown usage format %q", format)
+	}
+}
+
+// encodeCSV writes records as CSV rows, one per model.
+func encodeCSV(w io.Writer, records []Record, header bool) error {
+	writer := csv.NewWriter(w)
+	if header {
+		if err := writer.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	for _, record := range records {
+		models := record.Models
+		if len(models) == 0 {
+			models = []ModelRecord{{Turns: record.Turns, Tokens: record.Tokens, CostUSD: record.CostUSD, ToolCalls: record.ToolCalls}}
+		}
+		for _, model := range models {
+			row := []string{
+				record.Time.Format(time.RFC3339),
+				record.User,
+				record.Tenant,
+				record.SessionID,
+				model.Model,
+				strconv.Itoa(model.Turns),
+				strconv.Itoa(model.Tokens.InputTokens),
+				strconv.Itoa(model.Tokens.OutputTokens),
+				strconv.Itoa(model.Tokens.CacheCreationInputTokens),
+				strconv.Itoa(model.Tokens.CacheReadInputTokens),
+				strconv.FormatFloat(model.CostUSD, 'f', -1, 64),
+				formatToolCalls(model.ToolCalls),
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatToolCalls formats calls by tool as "Bash=3;Read=1", sorted by tool.
+func formatToolCalls(calls map[string]int) string {
+	names := make([]string, 0, len(calls))
+	for name := range calls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + strconv.Itoa(calls[name])
+	}
+	return strings.Join(parts, ";")
+}
+
+// Writer stores batches of exported records.
+type Writer interface {
+	WriteRecords(ctx context.Context, format Format, records []Record) error
+}
+
+// WriterFunc adapts a function to a Writer.
+type WriterFunc func(ctx context.Context, format Format, records []Record) error
+
+// WriteRecords calls f.
+func (f WriterFunc) WriteRecords(ctx context.Context, format Format, records []Record) error {
+	return f(ctx, format, records)
+}
+
+// Exporter writes records in a format to a Writer.
+type Exporter struct {
+	format Format
+	writer Writer
+}
+
+// NewExporter creates an exporter writing records in format to writer.
+func NewExporter(format Format, writer Writer) *Exporter {
+	return &Exporter{format: format, writer: writer}
+}
+
+// Export writes records as one batch. Exporting no records does nothing.
+func (e *Exporter) Export(ctx context.Context, records ...Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if e.format != CSV && e.format != JSON {
+		return fmt.Errorf("unknown usage format %q", e.format)
+	}
+	return e.writer.WriteRecords(ctx, e.format, records)
+}
@@ -0,0 +1,179 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/M1n9X/claude-agent-sdk-go/types"
+)
+
+// testReport returns the usage report of a session with two models.
+func testReport(t *testing.T) types.UsageReport {
+	t.Helper()
+	lines := []string{
+		`{"type":"assistant","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"tool_use","id":"t1","name":"Read","input":{}},{"type":"tool_use","id":"t2","name":"Task","input":{}}],"usage":{"input_tokens":100,"output_tokens":30}}}`,
+		`{"type":"assistant","message":{"id":"msg_2","role":"assistant","model":"claude-haiku-4-5","content":[{"type":"tool_use","id":"t3","name":"Read","input":{}}],"usage":{"input_tokens":20,"output_tokens":10}},"parent_tool_use_id":"t2"}`,
+		`{"type":"result","subtype":"success","session_id":"s1","total_cost_usd":0.25,"modelUsage":{"claude-sonnet-4-5":{"costUSD":0.2},"claude-haiku-4-5":{"costUSD":0.05}}}`,
+	}
+	recorder := types.NewUsageRecorder()
+	for _, line := range lines {
+		msg, err := types.UnmarshalMessage([]byte(line))
+		if err != nil {
+			t.Fatalf("UnmarshalMessage(%s) error = %v", line, err)
+		}
+		recorder.Record(msg)
+	}
+	return recorder.Report()
+}
+
+// TestNewRecord tests the record of a usage report, with its model
+// breakdown and tool counts
+func TestNewRecord(t *testing.T) {
+	record := NewRecord(testReport(t))
+
+	if record.SessionID != "s1" || record.Turns != 2 || record.CostUSD != 0.25 || record.Tokens.OutputTokens != 40 {
+		t.Errorf("record = %+v, want session s1 with 2 turns, 40 output tokens and $0.25", record)
+	}
+	if record.ToolCalls["Read"] != 2 || record.ToolCalls["Task"] != 1 {
+		t.Errorf("ToolCalls = %v, want 2 Read and 1 Task", record.ToolCalls)
+	}
+	if len(record.Models) != 2 {
+		t.Fatalf("Models = %+v, want 2 models", record.Models)
+	}
+	haiku, sonnet := record.Models[0], record.Models[1]
+	if haiku.Model != "claude-haiku-4-5" || haiku.Turns != 1 || haiku.CostUSD != 0.05 || haiku.ToolCalls["Read"] != 1 {
+		t.Errorf("Models[0] = %+v, want claude-haiku-4-5 with 1 Read and $0.05", haiku)
+	}
+	if sonnet.Model != "claude-sonnet-4-5" || sonnet.Tokens.InputTokens != 100 || sonnet.ToolCalls["Task"] != 1 {
+		t.Errorf("Models[1] = %+v, want claude-sonnet-4-5 with 100 input tokens and 1 Task", sonnet)
+	}
+}
+
+// TestEncode tests the CSV and JSON formats
+func TestEncode(t *testing.T) {
+	record := NewRecord(testReport(t))
+	record.Time = time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	record.User, record.Tenant = "bob", "acme"
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, CSV, []Record{record}, true); err != nil {
+		t.Fatalf("Encode(CSV) error = %v", err)
+	}
+	want := "time,user,tenant,session_id,model,turns,input_tokens,output_tokens,cache_creation_input_tokens,cache_read_input_tokens,cost_usd,tool_calls\n" +
+		"2026-10-17T09:30:00Z,bob,acme,s1,claude-haiku-4-5,1,20,10,0,0,0.05,Read=1\n" +
+		"2026-10-17T09:30:00Z,bob,acme,s1,claude-sonnet-4-5,1,100,30,0,0,0.2,Read=1;Task=1\n"
+	if buf.String() != want {
+		t.Errorf("Encode(CSV) =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := Encode(&buf, JSON, []Record{record, record}, true); err != nil {
+		t.Fatalf("Encode(JSON) error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Encode(JSON) wrote %d lines, want 2", len(lines))
+	}
+	var decoded Record
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if decoded.User != "bob" || decoded.SessionID != "s1" || len(decoded.Models) != 2 || decoded.ToolCalls["Read"] != 2 {
+		t.Errorf("decoded = %+v, want the record", decoded)
+	}
+
+	if err := Encode(&buf, Format("xml"), []Record{record}, true); err == nil {
+		t.Error("Encode(xml) error = nil, want an unknown format error")
+	}
+}
+
+// TestFileWriter tests that batches are appended with one CSV header
+func TestFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.csv")
+	exporter := NewExporter(CSV, NewFileWriter(path))
+	record := NewRecord(types.UsageReport{SessionID: "s1", TotalCostUSD: 0.1})
+
+	for i := 0; i < 2; i++ {
+		if err := exporter.Export(context.Background(), record); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "time,") || strings.HasPrefix(lines[2], "time,") {
+		t.Errorf("file =\n%s\nwant a header and 2 rows", data)
+	}
+	if !strings.HasSuffix(lines[1], ",s1,,0,0,0,0,0,0.1,") {
+		t.Errorf("row = %q, want the session without a model", lines[1])
+	}
+}
+
+// objectStore records the objects put in it.
+type objectStore struct {
+	keys         []string
+	contentTypes []string
+	bodies       []string
+}
+
+func (s *objectStore) PutObject(ctx context.Context, key string, body io.Reader, contentType string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.keys = append(s.keys, key)
+	s.contentTypes = append(s.contentTypes, contentType)
+	s.bodies = append(s.bodies, string(data))
+	return nil
+}
+
+// TestObjectWriter tests that each batch is put as an object keyed by time
+func TestObjectWriter(t *testing.T) {
+	store := &objectStore{}
+	writer := NewObjectWriter(store, "usage/")
+	writer.now = func() time.Time { return time.Date(2026, 10, 17, 9, 30, 0, 5, time.UTC) }
+	exporter := NewExporter(JSON, writer)
+
+	if err := exporter.Export(context.Background()); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(store.keys) != 0 {
+		t.Errorf("Export() of no records put %v, want nothing", store.keys)
+	}
+
+	record := NewRecord(testReport(t))
+	if err := exporter.Export(context.Background(), record, record); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(store.keys) != 1 || store.keys[0] != "usage/2026/10/17/093000.000000005.jsonl" || store.contentTypes[0] != "application/x-ndjson" {
+		t.Fatalf("objects = %v %v, want one JSON Lines object keyed by time", store.keys, store.contentTypes)
+	}
+	if n := strings.Count(store.bodies[0], "\n"); n != 2 {
+		t.Errorf("object has %d lines, want 2", n)
+	}
+}
+
+// TestExporterUnknownFormat tests that an unknown format is refused before
+// writing
+func TestExporterUnknownFormat(t *testing.T) {
+	called := false
+	exporter := NewExporter(Format("xml"), WriterFunc(func(ctx context.Context, format Format, records []Record) error {
+		called = true
+		return nil
+	}))
+	if err := exporter.Export(context.Background(), Record{}); err == nil {
+		t.Error("Export() error = nil, want an unknown format error")
+	}
+	if called {
+		t.Error("Export() called the writer with an unknown format")
+	}
+}
@@ -0,0 +1,91 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileWriter appends batches of records to a file, writing the CSV header
+// when the file is empty.
+type FileWriter struct {
+	path string
+
+	mu sync.Mutex
+}
+
+// NewFileWriter creates a writer appending to the file at path, which is
+// created if needed.
+func NewFileWriter(path string) *FileWriter {
+	return &FileWriter{path: path}
+}
+
+// WriteRecords appends records to the file.
+func (w *FileWriter) WriteRecords(ctx context.Context, format Format, records []Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	// Encode first, so that a failure does not leave half a batch
+	var buf bytes.Buffer
+	if err := Encode(&buf, format, records, info.Size() == 0); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ObjectStore is the part of an S3-style object store that ObjectWriter
+// uses. Adapt the client of your store to it, such as PutObject of the AWS
+// SDK.
+type ObjectStore interface {
+	PutObject(ctx context.Context, key string, body io.Reader, contentType string) error
+}
+
+// ObjectWriter puts each batch of records in an object store, as an object
+// keyed by prefix and the time of the batch, such as
+// "usage/2026/10/17/093000.123456789.csv".
+type ObjectWriter struct {
+	store  ObjectStore
+	prefix string
+	now    func() time.Time
+}
+
+// NewObjectWriter creates a writer putting batches in store, under prefix.
+func NewObjectWriter(store ObjectStore, prefix string) *ObjectWriter {
+	return &ObjectWriter{store: store, prefix: prefix, now: time.Now}
+}
+
+// WriteRecords puts records in the store as one object, with a CSV header.
+func (w *ObjectWriter) WriteRecords(ctx context.Context, format Format, records []Record) error {
+	var buf bytes.Buffer
+	if err := Encode(&buf, format, records, true); err != nil {
+		return err
+	}
+
+	key := w.prefix + w.now().UTC().Format("2006/01/02/150405.000000000")
+	contentType := "text/csv"
+	if format == JSON {
+		key += ".jsonl"
+		contentType = "application/x-ndjson"
+	} else {
+		key += ".csv"
+	}
+	return w.store.PutObject(ctx, key, &buf, contentType)
+}